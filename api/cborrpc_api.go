@@ -5,6 +5,8 @@ package api
 
 import (
 	"context"
+	"fmt"
+	"math/big"
 
 	"github.com/ldclabs/ldvm/chain"
 	"github.com/ldclabs/ldvm/ids"
@@ -80,6 +82,9 @@ func (api *API) ServeRPC(ctx context.Context, req *cborrpc.Request) *cborrpc.Res
 	case "getLedger":
 		return api.getLedger(ctx, req)
 
+	case "getTokenInfo":
+		return api.getTokenInfo(ctx, req)
+
 	case "getModel":
 		return api.getModel(ctx, req)
 
@@ -209,6 +214,43 @@ func (api *API) getLedger(ctx context.Context, req *cborrpc.Request) *cborrpc.Re
 	return req.ResultRaw(raw)
 }
 
+type TokenInfoResult struct {
+	Token          ids.TokenSymbol `cbor:"t" json:"token"`
+	MaxTotalSupply *big.Int        `cbor:"mts" json:"maxTotalSupply"`
+	TotalSupply    *big.Int        `cbor:"ts" json:"totalSupply"`
+	SupplyCap      *big.Int        `cbor:"sc,omitempty" json:"supplyCap,omitempty"`
+	BurnedSupply   *big.Int        `cbor:"bs,omitempty" json:"burnedSupply,omitempty"`
+	Info           *ld.TokenInfo   `cbor:"ti,omitempty" json:"info,omitempty"`
+}
+
+func (api *API) getTokenInfo(ctx context.Context, req *cborrpc.Request) *cborrpc.Response {
+	var token ids.TokenSymbol
+	if err := req.DecodeParams(&token); err != nil {
+		return req.Error(err)
+	}
+
+	acc, err := api.bc.LoadAccount(ctx, ids.Address(token))
+	if err != nil {
+		return req.Error(&cborrpc.Error{
+			Code:    cborrpc.CodeServerError,
+			Message: err.Error()})
+	}
+	if acc.Type != ld.TokenAccount {
+		return req.Error(&cborrpc.Error{
+			Code:    cborrpc.CodeServerError,
+			Message: fmt.Sprintf("token %s not found", token.GoString())})
+	}
+
+	return req.Result(&TokenInfoResult{
+		Token:          token,
+		MaxTotalSupply: acc.MaxTotalSupply,
+		TotalSupply:    acc.TotalSupply(),
+		SupplyCap:      acc.SupplyCap,
+		BurnedSupply:   acc.BurnedSupply,
+		Info:           acc.TokenInfo,
+	})
+}
+
 func (api *API) getModel(ctx context.Context, req *cborrpc.Request) *cborrpc.Response {
 	var id ids.ModelID
 	if err := req.DecodeParams(&id); err != nil {
@@ -253,6 +295,10 @@ func (api *API) getPrevData(ctx context.Context, req *cborrpc.Request) *cborrpc.
 
 	raw, err := api.bc.LoadRawData(ctx, "prevdata", params.ID.VersionKey(params.Version))
 	if err != nil {
+		if pruned := api.bc.PrunedVersion(ctx, params.ID); params.Version <= pruned {
+			err = fmt.Errorf("version %d of %s has been pruned, versions <= %d are pruned",
+				params.Version, params.ID, pruned)
+		}
 		return req.Error(&cborrpc.Error{
 			Code:    cborrpc.CodeServerError,
 			Message: err.Error()})
@@ -0,0 +1,107 @@
+// (c) 2022-2022, LDC Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package acct
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/ldclabs/ldvm/ids"
+	"github.com/ldclabs/ldvm/ld"
+	"github.com/ldclabs/ldvm/util/erring"
+)
+
+// ApproveAllowance sets the amount of token that the spender can transfer from the account,
+// amount 0 revokes the allowance.
+func (a *Account) ApproveAllowance(
+	spender ids.Address,
+	token ids.TokenSymbol,
+	amount *big.Int,
+	expire uint64,
+) error {
+	errp := erring.ErrPrefix(fmt.Sprintf("acct.Account(%s).ApproveAllowance: ", a.ld.ID.String()))
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	switch {
+	case a.ledger == nil:
+		return errp.Errorf("invalid ledger")
+
+	case spender == a.ld.ID:
+		return errp.Errorf("invalid spender")
+
+	case amount == nil || amount.Sign() < 0:
+		return errp.Errorf("invalid amount %v", amount)
+
+	case expire > 0 && expire <= a.ld.Timestamp:
+		return errp.Errorf("invalid expire, expected > %d, got %d", a.ld.Timestamp, expire)
+	}
+
+	key := ld.AllowanceKey(spender, token)
+	if amount.Sign() == 0 {
+		delete(a.ledger.Allowance, key)
+		return nil
+	}
+
+	a.ledger.Allowance[key] = &ld.AllowanceEntry{
+		Amount: new(big.Int).Set(amount),
+		Expire: expire,
+	}
+	return nil
+}
+
+// Allowance returns the remaining amount of token that the spender can transfer from the account.
+func (a *Account) Allowance(spender ids.Address, token ids.TokenSymbol) *big.Int {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+
+	amount := new(big.Int)
+	if a.ledger != nil {
+		if e := a.ledger.Allowance[ld.AllowanceKey(spender, token)]; e != nil &&
+			(e.Expire == 0 || e.Expire >= a.ld.Timestamp) {
+			amount.Set(e.Amount)
+		}
+	}
+	return amount
+}
+
+// SpendAllowance subtracts the amount of token from the account's balance and the spender's allowance.
+func (a *Account) SpendAllowance(spender ids.Address, token ids.TokenSymbol, amount *big.Int) error {
+	errp := erring.ErrPrefix(fmt.Sprintf("acct.Account(%s).SpendAllowance: ", a.ld.ID.String()))
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if a.ledger == nil {
+		return errp.Errorf("invalid ledger")
+	}
+
+	key := ld.AllowanceKey(spender, token)
+	e := a.ledger.Allowance[key]
+	switch {
+	case e == nil:
+		return errp.Errorf("%s has no allowance for %s", spender, token.GoString())
+
+	case e.Expire > 0 && e.Expire < a.ld.Timestamp:
+		return errp.Errorf("allowance expired at %d", e.Expire)
+
+	case amount == nil || amount.Sign() <= 0:
+		return errp.Errorf("invalid amount %v", amount)
+
+	case amount.Cmp(e.Amount) > 0:
+		return errp.Errorf("insufficient allowance, expected %v, got %v", amount, e.Amount)
+	}
+
+	if err := a.checkBalance(token, amount, true); err != nil {
+		return errp.ErrorIf(err)
+	}
+
+	a.subNoCheck(token, amount)
+	e.Amount.Sub(e.Amount, amount)
+	if e.Amount.Sign() == 0 {
+		delete(a.ledger.Allowance, key)
+	}
+	return nil
+}
@@ -0,0 +1,78 @@
+// (c) 2022-2022, LDC Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package acct
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ldclabs/ldvm/ids"
+	"github.com/ldclabs/ldvm/ld"
+	"github.com/ldclabs/ldvm/signer"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAllowance(t *testing.T) {
+	assert := assert.New(t)
+
+	spender := signer.Signer2.Key().Address()
+	token := ld.MustNewToken("$TEST")
+	na := NewAccount(signer.Signer1.Key().Address()).Init(big.NewInt(0), big.NewInt(0), 10, 100)
+
+	assert.ErrorContains(na.ApproveAllowance(spender, token, big.NewInt(1000), 0), "invalid ledger")
+	assert.ErrorContains(na.SpendAllowance(spender, token, big.NewInt(100)), "invalid ledger")
+	assert.Equal(uint64(0), na.Allowance(spender, token).Uint64())
+
+	assert.NoError(na.LoadLedger(false, func() ([]byte, error) { return nil, nil }))
+	assert.ErrorContains(na.ApproveAllowance(na.ID(), token, big.NewInt(1000), 0), "invalid spender")
+	assert.ErrorContains(na.ApproveAllowance(spender, token, nil, 0), "invalid amount <nil>")
+	assert.ErrorContains(na.ApproveAllowance(spender, token, big.NewInt(1000), 100),
+		"invalid expire, expected > 100, got 100")
+	assert.ErrorContains(na.SpendAllowance(spender, token, big.NewInt(100)),
+		"0x44171C37Ff5D7B7bb8Dcad5C81f16284A229E641 has no allowance for $TEST")
+
+	assert.NoError(na.ApproveAllowance(spender, token, big.NewInt(1000), 200))
+	assert.NoError(na.ApproveAllowance(spender, ids.NativeToken, big.NewInt(500), 0))
+	assert.Equal(uint64(1000), na.Allowance(spender, token).Uint64())
+	assert.Equal(uint64(500), na.Allowance(spender, ids.NativeToken).Uint64())
+	assert.Equal(uint64(0), na.Allowance(na.ID(), token).Uint64())
+
+	assert.ErrorContains(na.SpendAllowance(spender, token, big.NewInt(0)), "invalid amount 0")
+	assert.ErrorContains(na.SpendAllowance(spender, token, big.NewInt(1001)),
+		"insufficient allowance, expected 1001, got 1000")
+	assert.ErrorContains(na.SpendAllowance(spender, token, big.NewInt(100)),
+		"insufficient transferable $TEST balance, expected 100, got 0")
+
+	assert.NoError(na.Add(token, big.NewInt(2000)))
+	assert.NoError(na.SpendAllowance(spender, token, big.NewInt(600)))
+	assert.Equal(uint64(1400), na.BalanceOf(token).Uint64())
+	assert.Equal(uint64(400), na.Allowance(spender, token).Uint64())
+
+	// Marshal
+	_, ledger, err := na.Marshal()
+	require.NoError(t, err)
+	lg := &ld.AccountLedger{}
+	assert.NoError(lg.Unmarshal(ledger))
+	assert.NoError(lg.SyntacticVerify())
+	assert.Equal(ledger, lg.Bytes())
+	assert.Equal(2, len(lg.Allowance))
+
+	assert.NoError(na.SpendAllowance(spender, token, big.NewInt(400)))
+	assert.Equal(uint64(1000), na.BalanceOf(token).Uint64())
+	assert.Equal(1, len(na.ledger.Allowance))
+	assert.ErrorContains(na.SpendAllowance(spender, token, big.NewInt(100)), "has no allowance")
+
+	// expire
+	assert.NoError(na.ApproveAllowance(spender, token, big.NewInt(1000), 200))
+	na.ld.Timestamp = 201
+	assert.Equal(uint64(0), na.Allowance(spender, token).Uint64())
+	assert.ErrorContains(na.SpendAllowance(spender, token, big.NewInt(100)),
+		"allowance expired at 200")
+
+	// revoke
+	assert.NoError(na.ApproveAllowance(spender, token, big.NewInt(0), 0))
+	assert.NoError(na.ApproveAllowance(spender, ids.NativeToken, big.NewInt(0), 0))
+	assert.Equal(0, len(na.ledger.Allowance))
+}
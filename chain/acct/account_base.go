@@ -25,6 +25,7 @@ type Account struct {
 	pledge     *big.Int // token account and stake account should have pledge
 	ldHash     *ids.ID32
 	ledgerHash *ids.ID32
+	closed     bool // closed by CloseAccount, should be deleted from the state
 }
 
 func NewAccount(id ids.Address) *Account {
@@ -126,6 +127,13 @@ func (a *Account) Keepers() signer.Keys {
 	return a.ld.Keepers
 }
 
+func (a *Account) Weights() []uint16 {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+
+	return a.ld.Weights
+}
+
 func (a *Account) LD() *ld.Account {
 	return a.ld
 }
@@ -294,20 +302,24 @@ func (a *Account) Add(token ids.TokenSymbol, amount *big.Int) error {
 		return errp.Errorf("invalid amount %v", amount)
 
 	case amount.Sign() > 0:
-		switch token {
-		case ids.NativeToken:
-			a.ld.Balance.Add(a.ld.Balance, amount)
+		a.addNoCheck(token, amount)
+	}
+	return nil
+}
 
-		default:
-			v := a.ld.Tokens[token.AsKey()]
-			if v == nil {
-				v = new(big.Int)
-				a.ld.Tokens[token.AsKey()] = v
-			}
-			v.Add(v, amount)
+func (a *Account) addNoCheck(token ids.TokenSymbol, amount *big.Int) {
+	switch token {
+	case ids.NativeToken:
+		a.ld.Balance.Add(a.ld.Balance, amount)
+
+	default:
+		v := a.ld.Tokens[token.AsKey()]
+		if v == nil {
+			v = new(big.Int)
+			a.ld.Tokens[token.AsKey()] = v
 		}
+		v.Add(v, amount)
 	}
-	return nil
 }
 
 func (a *Account) Sub(token ids.TokenSymbol, amount *big.Int) error {
@@ -355,22 +367,29 @@ func (a *Account) SubGasByNonce(token ids.TokenSymbol, nonce uint64, amount *big
 	return nil
 }
 
+// SubGas subtracts the gas cost from the account as a fee payer of other's tx,
+// the tx is replay protected by the sender's nonce.
+func (a *Account) SubGas(amount *big.Int) error {
+	errp := erring.ErrPrefix(fmt.Sprintf("acct.Account(%s).SubGas: ", a.ld.ID.String()))
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if err := a.checkBalance(ids.NativeToken, amount, false); err != nil {
+		return errp.ErrorIf(err)
+	}
+
+	a.subNoCheck(ids.NativeToken, amount)
+	return nil
+}
+
 func (a *Account) SubByNonceTable(token ids.TokenSymbol, expire, nonce uint64, amount *big.Int) error {
 	errp := erring.ErrPrefix(fmt.Sprintf("acct.Account(%s).SubByNonceTable: ", a.ld.ID.String()))
 
 	a.mu.Lock()
 	defer a.mu.Unlock()
 
-	uu, ok := a.ld.NonceTable[expire]
-	i := -1
-	if ok {
-		for j, u := range uu {
-			if u == nonce {
-				i = j
-				break
-			}
-		}
-	}
+	i := a.findNonce(expire, nonce)
 	if i == -1 {
 		return errp.Errorf("nonce %d not exists at %d", nonce, expire)
 	}
@@ -379,6 +398,23 @@ func (a *Account) SubByNonceTable(token ids.TokenSymbol, expire, nonce uint64, a
 		return errp.ErrorIf(err)
 	}
 
+	a.removeNonce(expire, i)
+	a.subNoCheck(token, amount)
+	return nil
+}
+
+// findNonce returns the index of the nonce in the NonceTable group, or -1 if not exists.
+func (a *Account) findNonce(expire, nonce uint64) int {
+	for i, u := range a.ld.NonceTable[expire] {
+		if u == nonce {
+			return i
+		}
+	}
+	return -1
+}
+
+func (a *Account) removeNonce(expire uint64, i int) {
+	uu := a.ld.NonceTable[expire]
 	copy(uu[i:], uu[i+1:])
 	uu = uu[:len(uu)-1]
 	if len(uu) == 0 {
@@ -386,8 +422,6 @@ func (a *Account) SubByNonceTable(token ids.TokenSymbol, expire, nonce uint64, a
 	} else {
 		a.ld.NonceTable[expire] = uu
 	}
-	a.subNoCheck(token, amount)
-	return nil
 }
 
 func (a *Account) UpdateNonceTable(expire uint64, ns []uint64) error {
@@ -422,6 +456,7 @@ func (a *Account) UpdateNonceTable(expire uint64, ns []uint64) error {
 func (a *Account) UpdateKeepers(
 	threshold *uint16,
 	keepers *signer.Keys,
+	weights *[]uint16,
 	approver *signer.Key,
 	approveList *ld.TxTypes,
 ) error {
@@ -444,6 +479,10 @@ func (a *Account) UpdateKeepers(
 	if threshold != nil && keepers != nil {
 		a.ld.Threshold = *threshold
 		a.ld.Keepers = *keepers
+		a.ld.Weights = nil
+		if weights != nil && len(*weights) > 0 {
+			a.ld.Weights = *weights
+		}
 	}
 	return nil
 }
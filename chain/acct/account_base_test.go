@@ -13,6 +13,7 @@ import (
 	"github.com/ldclabs/ldvm/ids"
 	"github.com/ldclabs/ldvm/ld"
 	"github.com/ldclabs/ldvm/signer"
+	"github.com/ldclabs/ldvm/util/encoding"
 )
 
 func TestNativeAccount(t *testing.T) {
@@ -51,7 +52,21 @@ func TestNativeAccount(t *testing.T) {
 	// UpdateKeepers
 	assert.Equal(uint16(0), acc.Threshold())
 	assert.Equal(signer.Keys{}, acc.Keepers())
-	assert.NoError(acc.UpdateKeepers(ld.Uint16Ptr(1), &signer.Keys{signer.Signer1.Key(), signer.Signer2.Key()}, nil, nil))
+	assert.NoError(acc.UpdateKeepers(ld.Uint16Ptr(3), &signer.Keys{signer.Signer1.Key(), signer.Signer2.Key()},
+		&[]uint16{3, 1}, nil, nil))
+	assert.Equal([]uint16{3, 1}, acc.Weights())
+	msg := encoding.Sum256([]byte("LDC Labs"))
+	sig1, err := signer.Signer1.SignHash(msg)
+	require.NoError(t, err)
+	sig2, err := signer.Signer2.SignHash(msg)
+	require.NoError(t, err)
+	assert.True(acc.Verify(msg, signer.Sigs{sig1}, nil))
+	assert.False(acc.Verify(msg, signer.Sigs{sig2}, nil))
+	assert.False(acc.VerifyPlus(msg, signer.Sigs{sig1}, nil))
+	assert.True(acc.VerifyPlus(msg, signer.Sigs{sig1, sig2}, nil))
+	assert.NoError(acc.UpdateKeepers(ld.Uint16Ptr(1), &signer.Keys{signer.Signer1.Key(), signer.Signer2.Key()}, nil, nil, nil))
+	assert.Nil(acc.Weights())
+	assert.True(acc.Verify(msg, signer.Sigs{sig2}, nil))
 
 	// Add
 	assert.ErrorContains(acc.Add(ids.NativeToken, nil), "invalid amount <nil>")
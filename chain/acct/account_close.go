@@ -0,0 +1,130 @@
+// (c) 2022-2022, LDC Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package acct
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/ldclabs/ldvm/ids"
+	"github.com/ldclabs/ldvm/ld"
+	"github.com/ldclabs/ldvm/util/erring"
+)
+
+// CloseAccount sweeps all the balances and tokens to the beneficiary,
+// and resets the account to a tombstone, an empty account that keeps the last nonce,
+// so the txs signed before closing can't be replayed when the address is funded again.
+// The ledger should be deleted from the state.
+// Token and stake accounts should be destroyed before closing,
+// and the open entries on the ledger should be settled or revoked before closing.
+func (a *Account) CloseAccount(beneficiary *Account) error {
+	errp := erring.ErrPrefix(fmt.Sprintf("acct.Account(%s).CloseAccount: ", a.ld.ID.String()))
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if a.ledger != nil {
+		a.dropExpiredProposals()
+	}
+
+	switch {
+	case a.ledger == nil:
+		return errp.Errorf("invalid ledger")
+
+	case a.ld.ID == ids.LDCAccount || a.ld.ID == ids.GenesisAccount:
+		return errp.Errorf("can't close %s", a.ld.ID)
+
+	case a.ld.Type != ld.NativeAccount:
+		return errp.Errorf("can't close %s, should be destroyed first", a.ld.Type)
+
+	case a.ld.Lending != nil || len(a.ledger.Lending) > 0:
+		return errp.Errorf("please close lending before close")
+
+	case len(a.ledger.Stake) > 0:
+		return errp.Errorf("stake ledger not empty")
+
+	case len(a.ledger.Vesting) > 0:
+		return errp.Errorf("vesting ledger not empty")
+
+	case len(a.ledger.HTLC) > 0:
+		return errp.Errorf("HTLC ledger not empty")
+
+	case len(a.ledger.Escrow) > 0:
+		return errp.Errorf("escrow ledger not empty")
+
+	case len(a.ledger.Order) > 0:
+		return errp.Errorf("order ledger not empty, please cancel all before close")
+
+	case len(a.ledger.Allowance) > 0:
+		return errp.Errorf("allowance ledger not empty, please revoke all before close")
+
+	case len(a.ledger.Subscription) > 0:
+		return errp.Errorf("subscription ledger not empty, please revoke all before close")
+
+	case len(a.ledger.Proposal) > 0:
+		return errp.Errorf("proposal ledger not empty")
+
+	case len(a.ledger.Schedule) > 0:
+		return errp.Errorf("schedule ledger not empty")
+
+	case a.ledger.Recovery != nil && a.ledger.Recovery.Pending != nil:
+		return errp.Errorf("pending recovery exists, please cancel it before close")
+	}
+
+	if err := beneficiary.Add(ids.NativeToken, a.ld.Balance); err != nil {
+		return errp.ErrorIf(err)
+	}
+	for k, v := range a.ld.Tokens {
+		token, err := ids.TokenFromStr(string(k))
+		if err == nil {
+			err = beneficiary.Add(token, v)
+		}
+		if err != nil {
+			return errp.ErrorIf(err)
+		}
+	}
+
+	na := tombstone(a.ld.ID, a.ld.Nonce)
+	na.ld.Height = a.ld.Height
+	na.ld.Timestamp = a.ld.Timestamp
+	a.ld = na.ld
+	a.ledger = &ld.AccountLedger{}
+	if err := a.ledger.SyntacticVerify(); err != nil {
+		return errp.ErrorIf(err)
+	}
+	a.closed = true
+	return nil
+}
+
+// IsClosed returns true if the account was closed by CloseAccount
+// and has not been used again after that, the account is a tombstone with empty ledger.
+func (a *Account) IsClosed() bool {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if !a.closed || a.ledger == nil {
+		return false
+	}
+
+	if a.ld.SyntacticVerify() != nil || a.ledger.SyntacticVerify() != nil {
+		return false
+	}
+
+	el := &ld.AccountLedger{}
+	if err := el.SyntacticVerify(); err != nil {
+		return false
+	}
+	return bytes.Equal(a.ld.Bytes(), tombstone(a.ld.ID, a.ld.Nonce).ld.Bytes()) &&
+		bytes.Equal(a.ledger.Bytes(), el.Bytes())
+}
+
+// tombstone returns an empty account with the nonce.
+func tombstone(id ids.Address, nonce uint64) *Account {
+	a := NewAccount(id)
+	a.ld.Nonce = nonce
+	if err := a.ld.SyntacticVerify(); err != nil {
+		panic(err)
+	}
+	return a
+}
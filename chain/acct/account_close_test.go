@@ -0,0 +1,115 @@
+// (c) 2022-2022, LDC Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package acct
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/fxamacker/cbor/v2"
+	"github.com/ldclabs/ldvm/ids"
+	"github.com/ldclabs/ldvm/ld"
+	"github.com/ldclabs/ldvm/signer"
+	"github.com/ldclabs/ldvm/unit"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCloseAccount(t *testing.T) {
+	assert := assert.New(t)
+
+	token := ld.MustNewToken("$TEST")
+	addr0 := signer.NewSigner().Key().Address()
+	na := NewAccount(signer.Signer1.Key().Address()).Init(big.NewInt(0), big.NewInt(0), 10, 100)
+	beneficiary := NewAccount(signer.Signer2.Key().Address()).Init(big.NewInt(0), big.NewInt(0), 10, 100)
+
+	assert.NoError(na.UpdateKeepers(ld.Uint16Ptr(1), &signer.Keys{signer.Signer1.Key()}, nil, nil, nil))
+	assert.NoError(na.Add(ids.NativeToken, new(big.Int).SetUint64(unit.LDC)))
+	assert.NoError(na.Add(token, new(big.Int).SetUint64(unit.LDC*2)))
+	assert.NoError(na.SubGasByNonce(ids.NativeToken, 0, big.NewInt(0)))
+	assert.False(na.IsEmpty())
+	assert.False(na.IsClosed())
+
+	assert.ErrorContains(na.CloseAccount(beneficiary), "invalid ledger")
+	assert.NoError(na.LoadLedger(false, func() ([]byte, error) { return nil, nil }))
+
+	ldc := NewAccount(ids.LDCAccount).Init(big.NewInt(0), big.NewInt(0), 10, 100)
+	assert.NoError(ldc.LoadLedger(false, func() ([]byte, error) { return nil, nil }))
+	assert.ErrorContains(ldc.CloseAccount(beneficiary), "can't close 0x0000000000000000000000000000000000000000")
+
+	assert.NoError(na.OpenLending(&ld.LendingConfig{
+		DailyInterest:   10_000,
+		OverdueInterest: 10_000,
+		MinAmount:       new(big.Int).SetUint64(unit.LDC),
+		MaxAmount:       new(big.Int).SetUint64(unit.LDC * 10),
+	}))
+	assert.ErrorContains(na.CloseAccount(beneficiary), "please close lending before close")
+	assert.NoError(na.CloseLending())
+
+	na.ledger.Stake[addr0.AsKey()] = &ld.StakeEntry{Amount: big.NewInt(1)}
+	assert.ErrorContains(na.CloseAccount(beneficiary), "stake ledger not empty")
+	delete(na.ledger.Stake, addr0.AsKey())
+
+	na.ledger.Order[cbor.ByteString(ids.ID32{1}.Bytes())] = &ld.OrderEntry{Remaining: big.NewInt(1), Expire: 1000}
+	assert.ErrorContains(na.CloseAccount(beneficiary),
+		"order ledger not empty, please cancel all before close")
+	delete(na.ledger.Order, cbor.ByteString(ids.ID32{1}.Bytes()))
+
+	assert.NoError(na.ApproveAllowance(addr0, token, big.NewInt(1), 0))
+	assert.ErrorContains(na.CloseAccount(beneficiary),
+		"allowance ledger not empty, please revoke all before close")
+	assert.NoError(na.ApproveAllowance(addr0, token, big.NewInt(0), 0))
+
+	na.ledger.Subscription = map[cbor.ByteString]*ld.SubscriptionEntry{
+		cbor.ByteString(ids.ID32{1}.Bytes()): {}}
+	assert.ErrorContains(na.CloseAccount(beneficiary),
+		"subscription ledger not empty, please revoke all before close")
+	na.ledger.Subscription = nil
+
+	// the expired proposals are dropped, but the executed one is kept until expired
+	na.ledger.Proposal = map[cbor.ByteString]*ld.ProposalEntry{
+		cbor.ByteString(ids.ID32{1}.Bytes()): {Tx: []byte{0x80}, Expire: 99},
+		cbor.ByteString(ids.ID32{2}.Bytes()): {Expire: 100}}
+	assert.ErrorContains(na.CloseAccount(beneficiary), "proposal ledger not empty")
+	assert.Equal(1, len(na.ledger.Proposal))
+	na.ledger.Proposal = nil
+
+	na.ledger.Schedule = map[cbor.ByteString]*ld.ScheduleEntry{
+		cbor.ByteString(ids.ID32{1}.Bytes()): {}}
+	assert.ErrorContains(na.CloseAccount(beneficiary), "schedule ledger not empty")
+	na.ledger.Schedule = nil
+
+	assert.NoError(na.SetRecovery(&ld.TxRecovery{
+		Threshold: 1,
+		Guardians: signer.Keys{signer.Signer2.Key()},
+		Delay:     3600,
+	}))
+	assert.NoError(na.StartRecovery(1, signer.Keys{signer.Signer3.Key()}))
+	assert.ErrorContains(na.CloseAccount(beneficiary),
+		"pending recovery exists, please cancel it before close")
+	assert.NoError(na.CancelRecovery())
+
+	assert.NoError(na.CloseAccount(beneficiary))
+	assert.True(na.IsEmpty())
+	assert.True(na.IsClosed())
+	// the nonce is kept to prevent replaying
+	assert.Equal(uint64(1), na.Nonce())
+	assert.Equal(uint64(0), na.BalanceOfAll(ids.NativeToken).Uint64())
+	assert.Equal(uint64(0), na.BalanceOfAll(token).Uint64())
+	assert.Equal(uint64(0), na.Allowance(addr0, token).Uint64())
+	assert.Equal(unit.LDC, beneficiary.BalanceOfAll(ids.NativeToken).Uint64())
+	assert.Equal(unit.LDC*2, beneficiary.BalanceOfAll(token).Uint64())
+
+	data, ledger, err := na.Marshal()
+	require.NoError(t, err)
+	assert.NotEqual(NewAccount(na.ID()).LD().Bytes(), data)
+	assert.Equal(tombstone(na.ID(), 1).LD().Bytes(), data)
+	assert.NotNil(ledger)
+
+	// used again after closing
+	assert.NoError(na.Add(ids.NativeToken, big.NewInt(1)))
+	assert.False(na.IsClosed())
+	assert.NoError(na.Sub(ids.NativeToken, big.NewInt(1)))
+	assert.True(na.IsClosed())
+}
@@ -0,0 +1,109 @@
+// (c) 2022-2022, LDC Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package acct
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/fxamacker/cbor/v2"
+	"github.com/ldclabs/ldvm/ids"
+	"github.com/ldclabs/ldvm/ld"
+	"github.com/ldclabs/ldvm/util/erring"
+)
+
+// CreateEscrow locks the amount of token from the account's balance to the payee,
+// the escrow can be settled with 2-of-3 signatures from the account, the payee and the arbiter.
+func (a *Account) CreateEscrow(
+	id ids.ID32,
+	token ids.TokenSymbol,
+	payee ids.Address,
+	input *ld.TxEscrow,
+) error {
+	errp := erring.ErrPrefix(fmt.Sprintf("acct.Account(%s).CreateEscrow: ", a.ld.ID.String()))
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if a.ledger == nil {
+		return errp.Errorf("invalid ledger")
+	}
+
+	if err := input.SyntacticVerify(); err != nil {
+		return errp.ErrorIf(err)
+	}
+
+	switch {
+	case payee == a.ld.ID:
+		return errp.Errorf("invalid payee")
+
+	case input.Arbiter == a.ld.ID || input.Arbiter == payee:
+		return errp.Errorf("invalid arbiter")
+	}
+
+	key := cbor.ByteString(id[:])
+	if _, ok := a.ledger.Escrow[key]; ok {
+		return errp.Errorf("escrow %s exists", id)
+	}
+
+	if err := a.checkBalance(token, input.Amount, true); err != nil {
+		return errp.ErrorIf(err)
+	}
+
+	a.subNoCheck(token, input.Amount)
+	a.ledger.Escrow[key] = &ld.EscrowEntry{
+		Payee:   payee,
+		Arbiter: input.Arbiter,
+		Token:   token,
+		Amount:  new(big.Int).Set(input.Amount),
+	}
+	return nil
+}
+
+// Escrow returns the escrow entry with the given id.
+func (a *Account) Escrow(id ids.ID32) (*ld.EscrowEntry, error) {
+	errp := erring.ErrPrefix(fmt.Sprintf("acct.Account(%s).Escrow: ", a.ld.ID.String()))
+
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+
+	if a.ledger == nil {
+		return nil, errp.Errorf("invalid ledger")
+	}
+
+	e := a.ledger.Escrow[cbor.ByteString(id[:])]
+	if e == nil {
+		return nil, errp.Errorf("escrow %s not found", id)
+	}
+	return e, nil
+}
+
+// SettleEscrow removes the escrow and refunds the amount not released to the account's balance,
+// the caller should add the released amount to the payee.
+func (a *Account) SettleEscrow(id ids.ID32, release *big.Int) (*ld.EscrowEntry, error) {
+	errp := erring.ErrPrefix(fmt.Sprintf("acct.Account(%s).SettleEscrow: ", a.ld.ID.String()))
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if a.ledger == nil {
+		return nil, errp.Errorf("invalid ledger")
+	}
+
+	key := cbor.ByteString(id[:])
+	e := a.ledger.Escrow[key]
+	switch {
+	case e == nil:
+		return nil, errp.Errorf("escrow %s not found", id)
+
+	case release == nil || release.Sign() < 0 || release.Cmp(e.Amount) > 0:
+		return nil, errp.Errorf("invalid release, expected <= %v, got %v", e.Amount, release)
+	}
+
+	delete(a.ledger.Escrow, key)
+	if refund := new(big.Int).Sub(e.Amount, release); refund.Sign() > 0 {
+		a.addNoCheck(e.Token, refund)
+	}
+	return e, nil
+}
@@ -0,0 +1,80 @@
+// (c) 2022-2022, LDC Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package acct
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ldclabs/ldvm/ids"
+	"github.com/ldclabs/ldvm/ld"
+	"github.com/ldclabs/ldvm/signer"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEscrow(t *testing.T) {
+	assert := assert.New(t)
+
+	payee := signer.Signer2.Key().Address()
+	arbiter := ids.GenesisAccount
+	token := ld.MustNewToken("$TEST")
+	id1 := ids.ID32{1, 2, 3}
+	id2 := ids.ID32{4, 5, 6}
+	na := NewAccount(signer.Signer1.Key().Address()).Init(big.NewInt(0), big.NewInt(0), 10, 100)
+
+	input := &ld.TxEscrow{Arbiter: arbiter, Amount: big.NewInt(1000)}
+	assert.ErrorContains(na.CreateEscrow(id1, token, payee, input), "invalid ledger")
+	_, err := na.Escrow(id1)
+	assert.ErrorContains(err, "invalid ledger")
+	_, err = na.SettleEscrow(id1, big.NewInt(0))
+	assert.ErrorContains(err, "invalid ledger")
+
+	assert.NoError(na.LoadLedger(false, func() ([]byte, error) { return nil, nil }))
+	assert.ErrorContains(na.CreateEscrow(id1, token, payee, &ld.TxEscrow{}), "invalid arbiter")
+	assert.ErrorContains(na.CreateEscrow(id1, token, na.ID(), input), "invalid payee")
+	assert.ErrorContains(na.CreateEscrow(id1, token, payee,
+		&ld.TxEscrow{Arbiter: payee, Amount: big.NewInt(1000)}), "invalid arbiter")
+	assert.ErrorContains(na.CreateEscrow(id1, token, payee, input),
+		"insufficient transferable $TEST balance, expected 1000, got 0")
+
+	assert.NoError(na.Add(token, big.NewInt(2000)))
+	assert.NoError(na.CreateEscrow(id1, token, payee, input))
+	assert.ErrorContains(na.CreateEscrow(id1, token, payee, input),
+		"escrow "+id1.String()+" exists")
+	assert.NoError(na.CreateEscrow(id2, token, payee, input))
+	assert.Equal(uint64(0), na.BalanceOf(token).Uint64())
+
+	e, err := na.Escrow(id1)
+	require.NoError(t, err)
+	assert.Equal(payee, e.Payee)
+	assert.Equal(arbiter, e.Arbiter)
+	assert.Equal(token, e.Token)
+	assert.Equal(uint64(1000), e.Amount.Uint64())
+
+	// Marshal
+	_, ledger, err := na.Marshal()
+	require.NoError(t, err)
+	lg := &ld.AccountLedger{}
+	assert.NoError(lg.Unmarshal(ledger))
+	assert.NoError(lg.SyntacticVerify())
+	assert.Equal(ledger, lg.Bytes())
+	assert.Equal(2, len(lg.Escrow))
+
+	// split
+	_, err = na.SettleEscrow(id1, big.NewInt(1001))
+	assert.ErrorContains(err, "invalid release, expected <= 1000, got 1001")
+	e, err = na.SettleEscrow(id1, big.NewInt(600))
+	require.NoError(t, err)
+	assert.Equal(uint64(1000), e.Amount.Uint64())
+	assert.Equal(uint64(400), na.BalanceOf(token).Uint64())
+	_, err = na.Escrow(id1)
+	assert.ErrorContains(err, "escrow "+id1.String()+" not found")
+
+	// refund
+	_, err = na.SettleEscrow(id2, big.NewInt(0))
+	require.NoError(t, err)
+	assert.Equal(uint64(1400), na.BalanceOf(token).Uint64())
+	assert.Equal(0, len(na.ledger.Escrow))
+}
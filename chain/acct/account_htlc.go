@@ -0,0 +1,118 @@
+// (c) 2022-2022, LDC Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package acct
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/fxamacker/cbor/v2"
+	"github.com/ldclabs/ldvm/ids"
+	"github.com/ldclabs/ldvm/ld"
+	"github.com/ldclabs/ldvm/util/erring"
+)
+
+// CreateHTLC locks the amount of token from the account's balance to the recipient,
+// behind the hashlock until expired.
+func (a *Account) CreateHTLC(token ids.TokenSymbol, recipient ids.Address, input *ld.TxHTLC) error {
+	errp := erring.ErrPrefix(fmt.Sprintf("acct.Account(%s).CreateHTLC: ", a.ld.ID.String()))
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if a.ledger == nil {
+		return errp.Errorf("invalid ledger")
+	}
+
+	if err := input.SyntacticVerify(); err != nil {
+		return errp.ErrorIf(err)
+	}
+
+	if input.Expire <= a.ld.Timestamp {
+		return errp.Errorf("invalid expire, expected > %d, got %d", a.ld.Timestamp, input.Expire)
+	}
+
+	key := cbor.ByteString(input.HashLock[:])
+	if _, ok := a.ledger.HTLC[key]; ok {
+		return errp.Errorf("HTLC %s exists", input.HashLock)
+	}
+
+	if err := a.checkBalance(token, input.Amount, true); err != nil {
+		return errp.ErrorIf(err)
+	}
+
+	a.subNoCheck(token, input.Amount)
+	a.ledger.HTLC[key] = &ld.HTLCEntry{
+		Recipient: recipient,
+		Token:     token,
+		Amount:    new(big.Int).Set(input.Amount),
+		HashAlgo:  input.HashAlgo,
+		Expire:    input.Expire,
+	}
+	return nil
+}
+
+// ClaimHTLC removes the HTLC unlocked by the preimage from the account's ledger,
+// the caller should add the returned entry's amount to the recipient.
+func (a *Account) ClaimHTLC(recipient ids.Address, preimage []byte) (*ld.HTLCEntry, error) {
+	errp := erring.ErrPrefix(fmt.Sprintf("acct.Account(%s).ClaimHTLC: ", a.ld.ID.String()))
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if a.ledger == nil {
+		return nil, errp.Errorf("invalid ledger")
+	}
+
+	var key cbor.ByteString
+	var e *ld.HTLCEntry
+	for _, algo := range []ld.HashAlgo{ld.HashSHA3, ld.HashSHA256} {
+		hashLock := algo.Sum(preimage)
+		key = cbor.ByteString(hashLock[:])
+		if e = a.ledger.HTLC[key]; e != nil && e.HashAlgo == algo {
+			break
+		}
+		e = nil
+	}
+
+	switch {
+	case e == nil:
+		return nil, errp.Errorf("HTLC not found")
+
+	case e.Recipient != recipient:
+		return nil, errp.Errorf("invalid recipient, expected %s, got %s", e.Recipient, recipient)
+
+	case e.Expire < a.ld.Timestamp:
+		return nil, errp.Errorf("HTLC expired at %d", e.Expire)
+	}
+
+	delete(a.ledger.HTLC, key)
+	return e, nil
+}
+
+// RefundHTLC refunds the expired HTLC to the account's balance.
+func (a *Account) RefundHTLC(hashLock ids.ID32) (*ld.HTLCEntry, error) {
+	errp := erring.ErrPrefix(fmt.Sprintf("acct.Account(%s).RefundHTLC: ", a.ld.ID.String()))
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if a.ledger == nil {
+		return nil, errp.Errorf("invalid ledger")
+	}
+
+	key := cbor.ByteString(hashLock[:])
+	e := a.ledger.HTLC[key]
+	switch {
+	case e == nil:
+		return nil, errp.Errorf("HTLC %s not found", hashLock)
+
+	case e.Expire >= a.ld.Timestamp:
+		return nil, errp.Errorf("HTLC not expired, expected > %d, got %d", e.Expire, a.ld.Timestamp)
+	}
+
+	delete(a.ledger.HTLC, key)
+	a.addNoCheck(e.Token, e.Amount)
+	return e, nil
+}
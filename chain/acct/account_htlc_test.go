@@ -0,0 +1,97 @@
+// (c) 2022-2022, LDC Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package acct
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ldclabs/ldvm/ld"
+	"github.com/ldclabs/ldvm/signer"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHTLC(t *testing.T) {
+	assert := assert.New(t)
+
+	recipient := signer.Signer2.Key().Address()
+	token := ld.MustNewToken("$TEST")
+	preimage := []byte("secret")
+	na := NewAccount(signer.Signer1.Key().Address()).Init(big.NewInt(0), big.NewInt(0), 10, 100)
+
+	input := &ld.TxHTLC{
+		HashAlgo: ld.HashSHA256,
+		HashLock: ld.HashSHA256.Sum(preimage),
+		Amount:   big.NewInt(1000),
+		Expire:   200,
+	}
+	assert.ErrorContains(na.CreateHTLC(token, recipient, input), "invalid ledger")
+	_, err := na.ClaimHTLC(recipient, preimage)
+	assert.ErrorContains(err, "invalid ledger")
+	_, err = na.RefundHTLC(input.HashLock)
+	assert.ErrorContains(err, "invalid ledger")
+
+	assert.NoError(na.LoadLedger(false, func() ([]byte, error) { return nil, nil }))
+	assert.ErrorContains(na.CreateHTLC(token, recipient, &ld.TxHTLC{}), "invalid hashLock")
+	assert.ErrorContains(na.CreateHTLC(token, recipient, &ld.TxHTLC{
+		HashLock: input.HashLock,
+		Amount:   big.NewInt(1000),
+		Expire:   100,
+	}), "invalid expire, expected > 100, got 100")
+	assert.ErrorContains(na.CreateHTLC(token, recipient, input),
+		"insufficient transferable $TEST balance, expected 1000, got 0")
+
+	assert.NoError(na.Add(token, big.NewInt(1500)))
+	assert.NoError(na.CreateHTLC(token, recipient, input))
+	assert.Equal(uint64(500), na.BalanceOf(token).Uint64())
+	assert.ErrorContains(na.CreateHTLC(token, recipient, input),
+		"HTLC "+input.HashLock.String()+" exists")
+
+	input2 := &ld.TxHTLC{
+		HashAlgo: ld.HashSHA3,
+		HashLock: ld.HashSHA3.Sum(preimage),
+		Amount:   big.NewInt(500),
+		Expire:   200,
+	}
+	assert.NoError(na.CreateHTLC(token, recipient, input2))
+	assert.Equal(uint64(0), na.BalanceOf(token).Uint64())
+	assert.Equal(2, len(na.ledger.HTLC))
+
+	// Marshal
+	_, ledger, err := na.Marshal()
+	require.NoError(t, err)
+	lg := &ld.AccountLedger{}
+	assert.NoError(lg.Unmarshal(ledger))
+	assert.NoError(lg.SyntacticVerify())
+	assert.Equal(ledger, lg.Bytes())
+	assert.Equal(2, len(lg.HTLC))
+
+	// claim
+	_, err = na.ClaimHTLC(recipient, []byte("wrong"))
+	assert.ErrorContains(err, "HTLC not found")
+	_, err = na.ClaimHTLC(na.ID(), preimage)
+	assert.ErrorContains(err, "invalid recipient")
+	_, err = na.RefundHTLC(input.HashLock)
+	assert.ErrorContains(err, "HTLC not expired, expected > 200, got 100")
+
+	e, err := na.ClaimHTLC(recipient, preimage)
+	require.NoError(t, err)
+	assert.Equal(ld.HashSHA3, e.HashAlgo)
+	assert.Equal(uint64(500), e.Amount.Uint64())
+	assert.Equal(1, len(na.ledger.HTLC))
+
+	// refund
+	na.ld.Timestamp = 201
+	_, err = na.ClaimHTLC(recipient, preimage)
+	assert.ErrorContains(err, "HTLC expired at 200")
+	_, err = na.RefundHTLC(input2.HashLock)
+	assert.ErrorContains(err, "not found")
+
+	e, err = na.RefundHTLC(input.HashLock)
+	require.NoError(t, err)
+	assert.Equal(uint64(1000), e.Amount.Uint64())
+	assert.Equal(uint64(1000), na.BalanceOf(token).Uint64())
+	assert.Equal(0, len(na.ledger.HTLC))
+}
@@ -0,0 +1,126 @@
+// (c) 2022-2022, LDC Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package acct
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/fxamacker/cbor/v2"
+	"github.com/ldclabs/ldvm/ids"
+	"github.com/ldclabs/ldvm/ld"
+	"github.com/ldclabs/ldvm/util/erring"
+)
+
+// FillOrder sells the quantity of token from the partial exchange order identified by id.
+// The first fill consumes the order's nonce from the NonceTable and opens the order
+// with the full quota on the ledger, the order is removed when the quota is used up.
+// Expired orders are removed from the ledger on filling.
+func (a *Account) FillOrder(id ids.ID32, order *ld.TxExchanger, quantity *big.Int) error {
+	errp := erring.ErrPrefix(fmt.Sprintf("acct.Account(%s).FillOrder: ", a.ld.ID.String()))
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	switch {
+	case a.ledger == nil:
+		return errp.Errorf("invalid ledger")
+
+	case order == nil || !order.Partial:
+		return errp.Errorf("invalid order, should be partial")
+
+	case quantity == nil || quantity.Sign() <= 0:
+		return errp.Errorf("invalid quantity %v", quantity)
+
+	case order.Expire < a.ld.Timestamp:
+		return errp.Errorf("order expired at %d", order.Expire)
+	}
+
+	key := cbor.ByteString(id[:])
+	e := a.ledger.Order[key]
+	i := -1
+	if e == nil {
+		if i = a.findNonce(order.Expire, order.Nonce); i == -1 {
+			return errp.Errorf("nonce %d not exists at %d", order.Nonce, order.Expire)
+		}
+		e = &ld.OrderEntry{Remaining: new(big.Int).Set(order.Quota), Expire: order.Expire}
+	}
+
+	if quantity.Cmp(e.Remaining) > 0 {
+		return errp.Errorf("insufficient order quota, expected %v, got %v", quantity, e.Remaining)
+	}
+
+	if err := a.checkBalance(order.Sell, quantity, true); err != nil {
+		return errp.ErrorIf(err)
+	}
+
+	if i >= 0 {
+		a.removeNonce(order.Expire, i)
+	}
+	a.dropExpiredOrders()
+	a.subNoCheck(order.Sell, quantity)
+	e.Remaining.Sub(e.Remaining, quantity)
+	if e.Remaining.Sign() == 0 {
+		delete(a.ledger.Order, key)
+	} else {
+		a.ledger.Order[key] = e
+	}
+	return nil
+}
+
+// CancelOrder cancels the exchange order identified by id, the remaining quota is dropped,
+// an unfilled order is cancelled by consuming its nonce from the NonceTable.
+// Expired orders are removed from the ledger on cancelling.
+func (a *Account) CancelOrder(id ids.ID32, order *ld.TxExchanger) error {
+	errp := erring.ErrPrefix(fmt.Sprintf("acct.Account(%s).CancelOrder: ", a.ld.ID.String()))
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	switch {
+	case a.ledger == nil:
+		return errp.Errorf("invalid ledger")
+
+	case order == nil:
+		return errp.Errorf("nil order")
+	}
+
+	key := cbor.ByteString(id[:])
+	if _, ok := a.ledger.Order[key]; ok {
+		delete(a.ledger.Order, key)
+		a.dropExpiredOrders()
+		return nil
+	}
+
+	i := a.findNonce(order.Expire, order.Nonce)
+	if i == -1 {
+		return errp.Errorf("order %s not exists", id)
+	}
+	a.removeNonce(order.Expire, i)
+	a.dropExpiredOrders()
+	return nil
+}
+
+// dropExpiredOrders removes the partially filled orders that expired,
+// they can not be filled anymore.
+func (a *Account) dropExpiredOrders() {
+	for k, e := range a.ledger.Order {
+		if e.Expire < a.ld.Timestamp {
+			delete(a.ledger.Order, k)
+		}
+	}
+}
+
+// Order returns the open exchange order identified by id, or nil if not exists.
+func (a *Account) Order(id ids.ID32) *ld.OrderEntry {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+
+	if a.ledger != nil {
+		if e := a.ledger.Order[cbor.ByteString(id[:])]; e != nil {
+			return &ld.OrderEntry{Remaining: new(big.Int).Set(e.Remaining), Expire: e.Expire}
+		}
+	}
+	return nil
+}
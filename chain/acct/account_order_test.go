@@ -0,0 +1,131 @@
+// (c) 2022-2022, LDC Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package acct
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ldclabs/ldvm/ids"
+	"github.com/ldclabs/ldvm/ld"
+	"github.com/ldclabs/ldvm/signer"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOrder(t *testing.T) {
+	assert := assert.New(t)
+
+	token := ld.MustNewToken("$TEST")
+	na := NewAccount(signer.Signer1.Key().Address()).Init(big.NewInt(0), big.NewInt(0), 10, 100)
+
+	order := &ld.TxExchanger{
+		Nonce:   1,
+		Sell:    token,
+		Receive: ids.NativeToken,
+		Quota:   big.NewInt(1000),
+		Minimum: big.NewInt(100),
+		Price:   big.NewInt(1000),
+		Expire:  200,
+		Payee:   na.ID(),
+		Partial: true,
+	}
+	require.NoError(t, order.SyntacticVerify())
+	id := ids.ID32FromData(order.Bytes())
+
+	assert.ErrorContains(na.FillOrder(id, order, big.NewInt(100)), "invalid ledger")
+	assert.ErrorContains(na.CancelOrder(id, order), "invalid ledger")
+	assert.Nil(na.Order(id))
+
+	assert.NoError(na.LoadLedger(false, func() ([]byte, error) { return nil, nil }))
+	assert.ErrorContains(na.FillOrder(id, &ld.TxExchanger{}, big.NewInt(100)),
+		"invalid order, should be partial")
+	assert.ErrorContains(na.FillOrder(id, order, big.NewInt(0)), "invalid quantity 0")
+	assert.ErrorContains(na.FillOrder(id, order, big.NewInt(100)), "nonce 1 not exists at 200")
+	assert.ErrorContains(na.CancelOrder(id, order), "order "+id.String()+" not exists")
+
+	assert.NoError(na.UpdateNonceTable(200, []uint64{1, 2}))
+	assert.ErrorContains(na.FillOrder(id, order, big.NewInt(1001)),
+		"insufficient order quota, expected 1001, got 1000")
+	assert.ErrorContains(na.FillOrder(id, order, big.NewInt(100)),
+		"insufficient transferable $TEST balance, expected 100, got 0")
+	assert.Equal([]uint64{1, 2}, na.LD().NonceTable[200])
+
+	assert.NoError(na.Add(token, big.NewInt(2000)))
+	assert.NoError(na.FillOrder(id, order, big.NewInt(400)))
+	assert.Equal([]uint64{2}, na.LD().NonceTable[200])
+	assert.Equal(uint64(600), na.Order(id).Remaining.Uint64())
+	assert.Equal(uint64(200), na.Order(id).Expire)
+	assert.Equal(uint64(1600), na.BalanceOf(token).Uint64())
+
+	assert.ErrorContains(na.FillOrder(id, order, big.NewInt(601)),
+		"insufficient order quota, expected 601, got 600")
+	assert.NoError(na.FillOrder(id, order, big.NewInt(600)))
+	assert.Nil(na.Order(id))
+	assert.Equal(uint64(1000), na.BalanceOf(token).Uint64())
+	assert.ErrorContains(na.FillOrder(id, order, big.NewInt(100)), "nonce 1 not exists at 200")
+
+	// cancel a partially filled order
+	order.Nonce = 2
+	require.NoError(t, order.SyntacticVerify())
+	id = ids.ID32FromData(order.Bytes())
+	assert.NoError(na.FillOrder(id, order, big.NewInt(100)))
+	assert.Equal(uint64(900), na.Order(id).Remaining.Uint64())
+
+	// Marshal
+	_, ledger, err := na.Marshal()
+	require.NoError(t, err)
+	lg := &ld.AccountLedger{}
+	assert.NoError(lg.Unmarshal(ledger))
+	assert.NoError(lg.SyntacticVerify())
+	assert.Equal(ledger, lg.Bytes())
+	assert.Equal(1, len(lg.Order))
+
+	assert.NoError(na.CancelOrder(id, order))
+	assert.Nil(na.Order(id))
+	assert.ErrorContains(na.CancelOrder(id, order), "order "+id.String()+" not exists")
+
+	na.ld.Timestamp = 201
+	assert.ErrorContains(na.FillOrder(id, order, big.NewInt(100)), "order expired at 200")
+
+	// expired partially filled orders are dropped on filling other orders
+	na.ld.Timestamp = 100
+	assert.NoError(na.UpdateNonceTable(200, []uint64{3, 5}))
+	assert.NoError(na.UpdateNonceTable(300, []uint64{4}))
+	order.Nonce = 3
+	require.NoError(t, order.SyntacticVerify())
+	expiredID := ids.ID32FromData(order.Bytes())
+	assert.NoError(na.FillOrder(expiredID, order, big.NewInt(100)))
+	assert.NotNil(na.Order(expiredID))
+
+	order2 := &ld.TxExchanger{
+		Nonce:   4,
+		Sell:    order.Sell,
+		Receive: order.Receive,
+		Quota:   order.Quota,
+		Minimum: order.Minimum,
+		Price:   order.Price,
+		Expire:  300,
+		Payee:   na.ID(),
+		Partial: true,
+	}
+	require.NoError(t, order2.SyntacticVerify())
+	id2 := ids.ID32FromData(order2.Bytes())
+	na.ld.Timestamp = 201
+	assert.NoError(na.FillOrder(id2, order2, big.NewInt(100)))
+	assert.Nil(na.Order(expiredID))
+	assert.NotNil(na.Order(id2))
+
+	// and on cancelling
+	na.ld.Timestamp = 100
+	order.Nonce = 5
+	require.NoError(t, order.SyntacticVerify())
+	expiredID = ids.ID32FromData(order.Bytes())
+	assert.NoError(na.FillOrder(expiredID, order, big.NewInt(100)))
+	assert.NotNil(na.Order(expiredID))
+	na.ld.Timestamp = 201
+	assert.NoError(na.CancelOrder(id2, order2))
+	assert.Nil(na.Order(expiredID))
+	assert.Nil(na.Order(id2))
+}
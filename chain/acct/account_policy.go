@@ -0,0 +1,150 @@
+// (c) 2022-2022, LDC Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package acct
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/fxamacker/cbor/v2"
+	"github.com/ldclabs/ldvm/ids"
+	"github.com/ldclabs/ldvm/ld"
+	"github.com/ldclabs/ldvm/util/erring"
+)
+
+// UpdatePolicy updates the account's outflow policy, an empty policy removes it.
+// The first policy takes effect at once, later changes (removing included)
+// take effect after the active policy's Delay.
+func (a *Account) UpdatePolicy(policy *ld.AccountPolicy) error {
+	errp := erring.ErrPrefix(fmt.Sprintf("acct.Account(%s).UpdatePolicy: ", a.ld.ID.String()))
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if err := policy.SyntacticVerify(); err != nil {
+		return errp.ErrorIf(err)
+	}
+
+	a.promotePolicy()
+	s := a.ld.Policy
+	switch {
+	case s == nil || s.Active == nil:
+		if policy.IsEmpty() {
+			a.ld.Policy = nil
+		} else {
+			a.ld.Policy = &ld.PolicyState{Active: policy.Clone()}
+		}
+
+	default:
+		s.Pending = policy.Clone()
+		s.EffectiveAt = a.ld.Timestamp + s.Active.Delay
+	}
+	return nil
+}
+
+// CheckPolicyTxType checks that the account can send the txType while the policy is in effect.
+func (a *Account) CheckPolicyTxType(txType ld.TxType) error {
+	errp := erring.ErrPrefix(fmt.Sprintf("acct.Account(%s).CheckPolicyTxType: ", a.ld.ID.String()))
+
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+
+	if a.ld.Policy == nil || a.ld.Policy.Policy(a.ld.Timestamp) == nil {
+		return nil
+	}
+	if !ld.PolicyTxTypes.Has(txType) {
+		return errp.Errorf("%s is not allowed while the policy is in effect", txType)
+	}
+	return nil
+}
+
+// CheckPolicy checks the outflow of amount token to the recipient against
+// the policy in effect.
+func (a *Account) CheckPolicy(to *ids.Address, token ids.TokenSymbol, amount *big.Int) error {
+	errp := erring.ErrPrefix(fmt.Sprintf("acct.Account(%s).CheckPolicy: ", a.ld.ID.String()))
+
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+
+	if a.ld.Policy == nil || amount == nil || amount.Sign() <= 0 {
+		return nil
+	}
+
+	p, spent := a.ld.Policy.Effective(a.ld.Timestamp)
+	if p == nil {
+		return nil
+	}
+
+	if !p.Allowed(to) {
+		recipient := "nil recipient"
+		if to != nil {
+			recipient = "recipient " + to.String()
+		}
+		return errp.Errorf("%s is not in the allowlist", recipient)
+	}
+	return errp.ErrorIf(checkPolicyLimit(p, spent, token, amount))
+}
+
+// SpendByPolicy records the outflow of amount token in the rolling window of the policy in effect.
+func (a *Account) SpendByPolicy(token ids.TokenSymbol, amount *big.Int) error {
+	errp := erring.ErrPrefix(fmt.Sprintf("acct.Account(%s).SpendByPolicy: ", a.ld.ID.String()))
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if a.ld.Policy == nil || amount == nil || amount.Sign() <= 0 {
+		return nil
+	}
+
+	a.promotePolicy()
+	s := a.ld.Policy
+	if s == nil || s.Active == nil {
+		return nil
+	}
+
+	p, spent := s.Effective(a.ld.Timestamp)
+	if err := checkPolicyLimit(p, spent, token, amount); err != nil {
+		return errp.ErrorIf(err)
+	}
+	s.Spend(a.ld.Timestamp, token, amount)
+	return nil
+}
+
+// promotePolicy makes the pending policy active if it is in effect.
+func (a *Account) promotePolicy() {
+	s := a.ld.Policy
+	if s == nil || s.Pending == nil || a.ld.Timestamp < s.EffectiveAt {
+		return
+	}
+
+	if s.Pending.IsEmpty() {
+		a.ld.Policy = nil
+		return
+	}
+	s.Active = s.Pending
+	s.Pending = nil
+	s.EffectiveAt = 0
+}
+
+func checkPolicyLimit(
+	p *ld.AccountPolicy,
+	spent map[cbor.ByteString]*big.Int,
+	token ids.TokenSymbol,
+	amount *big.Int,
+) error {
+	limit := p.Limits[token.AsKey()]
+	if limit == nil {
+		return nil
+	}
+
+	total := new(big.Int).Set(amount)
+	if v := spent[token.AsKey()]; v != nil {
+		total.Add(total, v)
+	}
+	if total.Cmp(limit) > 0 {
+		return fmt.Errorf("%s outflow exceeds the policy limit, expected <= %v, got %v",
+			token.GoString(), limit, total)
+	}
+	return nil
+}
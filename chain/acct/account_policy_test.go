@@ -0,0 +1,136 @@
+// (c) 2022-2022, LDC Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package acct
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/fxamacker/cbor/v2"
+	"github.com/ldclabs/ldvm/ids"
+	"github.com/ldclabs/ldvm/ld"
+	"github.com/ldclabs/ldvm/signer"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPolicy(t *testing.T) {
+	assert := assert.New(t)
+
+	token := ld.MustNewToken("$TEST")
+	to := ids.Address{1, 2, 3}
+	na := NewAccount(signer.Signer1.Key().Address()).Init(big.NewInt(0), big.NewInt(0), 10, 100)
+
+	// no policy
+	assert.NoError(na.CheckPolicy(nil, ids.NativeToken, big.NewInt(1000)))
+	assert.NoError(na.SpendByPolicy(ids.NativeToken, big.NewInt(1000)))
+	assert.Nil(na.LD().Policy)
+
+	assert.ErrorContains(na.UpdatePolicy(&ld.AccountPolicy{Allowlist: []ids.Address{to, to}}),
+		"duplicate recipient")
+	assert.NoError(na.UpdatePolicy(&ld.AccountPolicy{}))
+	assert.Nil(na.LD().Policy)
+
+	p := &ld.AccountPolicy{
+		Window:    100,
+		Limits:    map[cbor.ByteString]*big.Int{ids.NativeToken.AsKey(): big.NewInt(1000)},
+		Allowlist: []ids.Address{to},
+		Delay:     3600,
+	}
+	// the first policy takes effect at once
+	assert.NoError(na.UpdatePolicy(p))
+	require.NotNil(t, na.LD().Policy)
+	assert.Equal(p, na.LD().Policy.Active)
+	p.Limits[ids.NativeToken.AsKey()].SetUint64(1)
+	assert.Equal(uint64(1000), na.LD().Policy.Active.Limits[ids.NativeToken.AsKey()].Uint64())
+
+	assert.ErrorContains(na.CheckPolicy(nil, ids.NativeToken, big.NewInt(100)),
+		"nil recipient is not in the allowlist")
+	assert.ErrorContains(na.CheckPolicy(ids.Address{1}.Ptr(), ids.NativeToken, big.NewInt(100)),
+		"recipient "+ids.Address{1}.String()+" is not in the allowlist")
+	assert.NoError(na.CheckPolicy(ids.Address{1}.Ptr(), ids.NativeToken, big.NewInt(0)))
+	assert.ErrorContains(na.CheckPolicy(to.Ptr(), ids.NativeToken, big.NewInt(1001)),
+		"NativeLDC outflow exceeds the policy limit, expected <= 1000, got 1001")
+	assert.NoError(na.CheckPolicy(to.Ptr(), ids.NativeToken, big.NewInt(1000)))
+	assert.NoError(na.CheckPolicy(to.Ptr(), token, big.NewInt(10000)))
+
+	assert.NoError(na.SpendByPolicy(ids.NativeToken, big.NewInt(600)))
+	assert.NoError(na.SpendByPolicy(token, big.NewInt(10000)))
+	assert.Equal([]*ld.PolicyOutflow{{Slot: 100, Token: ids.NativeToken, Amount: big.NewInt(600)}},
+		na.LD().Policy.Outflows)
+	assert.ErrorContains(na.CheckPolicy(to.Ptr(), ids.NativeToken, big.NewInt(401)),
+		"NativeLDC outflow exceeds the policy limit, expected <= 1000, got 1001")
+	assert.ErrorContains(na.SpendByPolicy(ids.NativeToken, big.NewInt(401)),
+		"NativeLDC outflow exceeds the policy limit, expected <= 1000, got 1001")
+	na.ld.Timestamp = 150
+	assert.NoError(na.SpendByPolicy(ids.NativeToken, big.NewInt(400)))
+	assert.Equal(2, len(na.LD().Policy.Outflows))
+
+	// the window rolls over, the outflow at 100 is out of the window
+	na.ld.Timestamp = 205
+	assert.ErrorContains(na.CheckPolicy(to.Ptr(), ids.NativeToken, big.NewInt(601)),
+		"NativeLDC outflow exceeds the policy limit, expected <= 1000, got 1001")
+	assert.NoError(na.CheckPolicy(to.Ptr(), ids.NativeToken, big.NewInt(600)))
+	assert.NoError(na.SpendByPolicy(ids.NativeToken, big.NewInt(100)))
+	assert.Equal([]*ld.PolicyOutflow{
+		{Slot: 150, Token: ids.NativeToken, Amount: big.NewInt(400)},
+		{Slot: 205, Token: ids.NativeToken, Amount: big.NewInt(100)},
+	}, na.LD().Policy.Outflows)
+
+	// it is not reset after the first outflow's window
+	na.ld.Timestamp = 254
+	assert.ErrorContains(na.CheckPolicy(to.Ptr(), ids.NativeToken, big.NewInt(501)),
+		"NativeLDC outflow exceeds the policy limit, expected <= 1000, got 1001")
+	na.ld.Timestamp = 255
+	assert.NoError(na.CheckPolicy(to.Ptr(), ids.NativeToken, big.NewInt(900)))
+
+	// only Tx.Amount outflows are allowed
+	assert.ErrorContains(na.CheckPolicyTxType(ld.TypeCloseAccount),
+		"TypeCloseAccount is not allowed while the policy is in effect")
+	assert.ErrorContains(na.CheckPolicyTxType(ld.TypeCreateHTLC),
+		"TypeCreateHTLC is not allowed while the policy is in effect")
+	assert.NoError(na.CheckPolicyTxType(ld.TypeTransfer))
+	assert.NoError(na.CheckPolicyTxType(ld.TypeUpdateAccountInfo))
+
+	// changes take effect after the delay
+	assert.ErrorContains(na.UpdatePolicy(&ld.AccountPolicy{
+		Window: 100,
+		Limits: map[cbor.ByteString]*big.Int{ids.NativeToken.AsKey(): big.NewInt(2000)},
+	}), "invalid delay, should be > 0")
+	assert.NoError(na.UpdatePolicy(&ld.AccountPolicy{
+		Window: 100,
+		Limits: map[cbor.ByteString]*big.Int{ids.NativeToken.AsKey(): big.NewInt(2000)},
+		Delay:  100,
+	}))
+	assert.NotNil(na.LD().Policy.Pending)
+	assert.Equal(uint64(3855), na.LD().Policy.EffectiveAt)
+	assert.ErrorContains(na.CheckPolicy(ids.Address{1}.Ptr(), ids.NativeToken, big.NewInt(100)),
+		"is not in the allowlist")
+	assert.ErrorContains(na.CheckPolicy(to.Ptr(), ids.NativeToken, big.NewInt(1000)),
+		"NativeLDC outflow exceeds the policy limit, expected <= 1000, got 1100")
+
+	na.ld.Timestamp = 3855
+	assert.NoError(na.CheckPolicy(ids.Address{1}.Ptr(), ids.NativeToken, big.NewInt(2000)))
+	assert.NoError(na.SpendByPolicy(ids.NativeToken, big.NewInt(2000)))
+	assert.Nil(na.LD().Policy.Pending)
+	assert.Equal(uint64(0), na.LD().Policy.EffectiveAt)
+	assert.Equal(uint64(100), na.LD().Policy.Active.Delay)
+	assert.Nil(na.LD().Policy.Active.Allowlist)
+	assert.NoError(na.LD().SyntacticVerify())
+
+	// removing is delayed too
+	assert.NoError(na.UpdatePolicy(&ld.AccountPolicy{Allowlist: []ids.Address{to}, Delay: 100}))
+	assert.Equal(uint64(3955), na.LD().Policy.EffectiveAt)
+	assert.NoError(na.UpdatePolicy(&ld.AccountPolicy{}))
+	assert.Equal(uint64(3955), na.LD().Policy.EffectiveAt)
+	assert.True(na.LD().Policy.Pending.IsEmpty())
+	assert.ErrorContains(na.CheckPolicy(to.Ptr(), ids.NativeToken, big.NewInt(1)),
+		"NativeLDC outflow exceeds the policy limit, expected <= 2000, got 2001")
+	na.ld.Timestamp = 3955
+	assert.NoError(na.CheckPolicy(nil, ids.NativeToken, big.NewInt(1)))
+	assert.NotNil(na.LD().Policy)
+	assert.NoError(na.SpendByPolicy(ids.NativeToken, big.NewInt(1)))
+	assert.Nil(na.LD().Policy)
+	assert.NoError(na.CheckPolicyTxType(ld.TypeCloseAccount))
+}
@@ -0,0 +1,173 @@
+// (c) 2022-2022, LDC Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package acct
+
+import (
+	"fmt"
+
+	"github.com/fxamacker/cbor/v2"
+	"github.com/ldclabs/ldvm/ids"
+	"github.com/ldclabs/ldvm/ld"
+	"github.com/ldclabs/ldvm/signer"
+	"github.com/ldclabs/ldvm/util/erring"
+)
+
+// CreateProposal adds the proposal of the inner tx identified by id (the inner tx's SigHash)
+// to the account's ledger, the given signatures are taken as the first approvals.
+// Expired proposals are removed before adding.
+func (a *Account) CreateProposal(id ids.ID32, tx []byte, expire uint64, sigs signer.Sigs) error {
+	errp := erring.ErrPrefix(fmt.Sprintf("acct.Account(%s).CreateProposal: ", a.ld.ID.String()))
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	switch {
+	case a.ledger == nil:
+		return errp.Errorf("invalid ledger")
+
+	case len(a.ld.Keepers) == 0:
+		return errp.Errorf("no keepers")
+
+	case expire <= a.ld.Timestamp:
+		return errp.Errorf("invalid expire, expected > %d, got %d", a.ld.Timestamp, expire)
+	}
+
+	a.dropExpiredProposals()
+	key := cbor.ByteString(id[:])
+	if _, ok := a.ledger.Proposal[key]; ok {
+		return errp.Errorf("proposal %s exists", id)
+	}
+
+	if len(a.ledger.Proposal) >= ld.MaxProposals {
+		return errp.Errorf("too many proposals, expected <= %d", ld.MaxProposals)
+	}
+
+	if err := a.checkProposalSigs(id, nil, sigs); err != nil {
+		return errp.ErrorIf(err)
+	}
+
+	a.ledger.Proposal[key] = &ld.ProposalEntry{
+		Tx:     tx,
+		Expire: expire,
+		Sigs:   sigs,
+	}
+	return nil
+}
+
+// ApproveProposal adds the signatures to the proposal identified by id,
+// each of them should be signed by a keeper or the approver who hasn't approved yet.
+func (a *Account) ApproveProposal(id ids.ID32, sigs signer.Sigs) error {
+	errp := erring.ErrPrefix(fmt.Sprintf("acct.Account(%s).ApproveProposal: ", a.ld.ID.String()))
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if a.ledger == nil {
+		return errp.Errorf("invalid ledger")
+	}
+
+	a.dropExpiredProposals()
+	e := a.ledger.Proposal[cbor.ByteString(id[:])]
+	switch {
+	case e == nil:
+		return errp.Errorf("proposal %s not found", id)
+
+	case len(e.Tx) == 0:
+		return errp.Errorf("proposal %s executed", id)
+
+	case len(e.Sigs)+len(sigs) > ld.MaxKeepers:
+		return errp.Errorf("too many sigs, expected <= %d", ld.MaxKeepers)
+	}
+
+	if err := a.checkProposalSigs(id, e.Sigs, sigs); err != nil {
+		return errp.ErrorIf(err)
+	}
+
+	e.Sigs = append(e.Sigs, sigs...)
+	return nil
+}
+
+// Proposal returns the pending proposal identified by id, or nil if not exists.
+func (a *Account) Proposal(id ids.ID32) *ld.ProposalEntry {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+
+	if a.ledger != nil {
+		if e := a.ledger.Proposal[cbor.ByteString(id[:])]; e != nil && len(e.Tx) > 0 {
+			return &ld.ProposalEntry{
+				Tx:     e.Tx,
+				Expire: e.Expire,
+				Sigs:   append(signer.Sigs{}, e.Sigs...),
+			}
+		}
+	}
+	return nil
+}
+
+// TakeProposal takes the pending proposal identified by id from the account's ledger to execute.
+// The proposal is kept without Tx and Sigs until it expires, so it can't be executed again.
+func (a *Account) TakeProposal(id ids.ID32) (*ld.ProposalEntry, error) {
+	errp := erring.ErrPrefix(fmt.Sprintf("acct.Account(%s).TakeProposal: ", a.ld.ID.String()))
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if a.ledger == nil {
+		return nil, errp.Errorf("invalid ledger")
+	}
+
+	key := cbor.ByteString(id[:])
+	e := a.ledger.Proposal[key]
+	switch {
+	case e == nil:
+		return nil, errp.Errorf("proposal %s not found", id)
+
+	case len(e.Tx) == 0:
+		return nil, errp.Errorf("proposal %s executed", id)
+	}
+
+	a.ledger.Proposal[key] = &ld.ProposalEntry{Expire: e.Expire}
+	return e, nil
+}
+
+// dropExpiredProposals removes the expired proposals, both pending and executed ones.
+// An executed proposal can't be proposed again after it expires, since its ID commits to its expire.
+func (a *Account) dropExpiredProposals() {
+	for k, e := range a.ledger.Proposal {
+		if e.Expire < a.ld.Timestamp {
+			delete(a.ledger.Proposal, k)
+		}
+	}
+}
+
+// checkProposalSigs checks that every new signature is signed over id by a keeper
+// or the approver, and no one approves twice.
+func (a *Account) checkProposalSigs(id ids.ID32, approved, sigs signer.Sigs) error {
+	keys := make(signer.Keys, 0, len(a.ld.Keepers)+1)
+	keys = append(keys, a.ld.Keepers...)
+	if a.ld.Approver != nil {
+		keys = append(keys, a.ld.Approver)
+	}
+
+	signed := make(map[cbor.ByteString]struct{}, len(approved)+len(sigs))
+	for _, sig := range approved {
+		if i := sig.FindKey(id[:], keys...); i >= 0 {
+			signed[keys[i].AsKey()] = struct{}{}
+		}
+	}
+
+	for _, sig := range sigs {
+		i := sig.FindKey(id[:], keys...)
+		if i < 0 {
+			return fmt.Errorf("invalid signature %s, not signed by keepers or approver", sig)
+		}
+
+		k := keys[i].AsKey()
+		if _, ok := signed[k]; ok {
+			return fmt.Errorf("%s has approved", keys[i])
+		}
+		signed[k] = struct{}{}
+	}
+	return nil
+}
@@ -0,0 +1,108 @@
+// (c) 2022-2022, LDC Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package acct
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ldclabs/ldvm/ids"
+	"github.com/ldclabs/ldvm/ld"
+	"github.com/ldclabs/ldvm/signer"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProposal(t *testing.T) {
+	assert := assert.New(t)
+
+	id := ids.ID32FromData([]byte("proposal"))
+	sig1, err := signer.Signer1.SignHash(id[:])
+	require.NoError(t, err)
+	sig2, err := signer.Signer2.SignHash(id[:])
+	require.NoError(t, err)
+	sig3, err := signer.Signer3.SignHash(id[:])
+	require.NoError(t, err)
+
+	na := NewAccount(ids.GenesisAccount).Init(big.NewInt(0), big.NewInt(0), 10, 100)
+	assert.ErrorContains(na.CreateProposal(id, []byte{0x80}, 200, signer.Sigs{sig1}),
+		"invalid ledger")
+	assert.ErrorContains(na.ApproveProposal(id, signer.Sigs{sig2}), "invalid ledger")
+	_, err = na.TakeProposal(id)
+	assert.ErrorContains(err, "invalid ledger")
+	assert.Nil(na.Proposal(id))
+
+	assert.NoError(na.LoadLedger(false, func() ([]byte, error) { return nil, nil }))
+	assert.ErrorContains(na.CreateProposal(id, []byte{0x80}, 200, signer.Sigs{sig1}),
+		"no keepers")
+
+	assert.NoError(na.UpdateKeepers(ld.Uint16Ptr(2),
+		&signer.Keys{signer.Signer1.Key(), signer.Signer2.Key()}, nil, nil, nil))
+	assert.ErrorContains(na.CreateProposal(id, []byte{0x80}, 100, signer.Sigs{sig1}),
+		"invalid expire, expected > 100, got 100")
+	assert.ErrorContains(na.CreateProposal(id, []byte{0x80}, 200, signer.Sigs{sig3}),
+		"invalid signature "+sig3.String()+", not signed by keepers or approver")
+	assert.ErrorContains(na.CreateProposal(id, []byte{0x80}, 200, signer.Sigs{sig1, sig1}),
+		signer.Signer1.Key().String()+" has approved")
+
+	assert.NoError(na.CreateProposal(id, []byte{0x80}, 200, signer.Sigs{sig1}))
+	assert.ErrorContains(na.CreateProposal(id, []byte{0x80}, 200, signer.Sigs{sig1}),
+		"proposal "+id.String()+" exists")
+	e := na.Proposal(id)
+	require.NotNil(t, e)
+	assert.Equal(uint64(200), e.Expire)
+	assert.Equal(signer.Sigs{sig1}, e.Sigs)
+
+	assert.ErrorContains(na.ApproveProposal(ids.ID32{1}, signer.Sigs{sig2}),
+		"proposal "+ids.ID32{1}.String()+" not found")
+	assert.ErrorContains(na.ApproveProposal(id, signer.Sigs{sig1}),
+		signer.Signer1.Key().String()+" has approved")
+	assert.ErrorContains(na.ApproveProposal(id, signer.Sigs{sig3}),
+		"invalid signature "+sig3.String()+", not signed by keepers or approver")
+
+	// the approver can approve too
+	approver := signer.Signer3.Key()
+	assert.NoError(na.UpdateKeepers(nil, nil, nil, &approver, nil))
+	assert.NoError(na.ApproveProposal(id, signer.Sigs{sig2, sig3}))
+	assert.Equal(signer.Sigs{sig1, sig2, sig3}, na.Proposal(id).Sigs)
+	assert.Equal(signer.Sigs{sig1}, e.Sigs)
+
+	_, err = na.TakeProposal(ids.ID32{1})
+	assert.ErrorContains(err, "proposal "+ids.ID32{1}.String()+" not found")
+	e, err = na.TakeProposal(id)
+	require.NoError(t, err)
+	assert.Equal(signer.Sigs{sig1, sig2, sig3}, e.Sigs)
+	assert.Nil(na.Proposal(id))
+
+	// the executed proposal is kept until expired
+	_, err = na.TakeProposal(id)
+	assert.ErrorContains(err, "proposal "+id.String()+" executed")
+	assert.ErrorContains(na.ApproveProposal(id, signer.Sigs{sig2}),
+		"proposal "+id.String()+" executed")
+	assert.ErrorContains(na.CreateProposal(id, []byte{0x80}, 200, signer.Sigs{sig1}),
+		"proposal "+id.String()+" exists")
+
+	id2 := ids.ID32FromData([]byte("proposal2"))
+	sig1, err = signer.Signer1.SignHash(id2[:])
+	require.NoError(t, err)
+	sig2, err = signer.Signer2.SignHash(id2[:])
+	require.NoError(t, err)
+	assert.NoError(na.CreateProposal(id2, []byte{0x80}, 200, signer.Sigs{sig1}))
+
+	// expired proposals are pruned
+	na.ld.Timestamp = 201
+	assert.ErrorContains(na.ApproveProposal(id2, signer.Sigs{sig2}),
+		"proposal "+id2.String()+" not found")
+	assert.Equal(0, len(na.ledger.Proposal))
+
+	for i := 0; i < ld.MaxProposals-1; i++ {
+		assert.NoError(na.CreateProposal(ids.ID32{1, byte(i)}, []byte{0x80}, 300, nil))
+	}
+	assert.NoError(na.CreateProposal(ids.ID32{2}, []byte{0x80}, 300, nil))
+	assert.ErrorContains(na.CreateProposal(ids.ID32{3}, []byte{0x80}, 300, nil),
+		"too many proposals, expected <= 16")
+	na.ld.Timestamp = 301
+	assert.NoError(na.CreateProposal(ids.ID32{3}, []byte{0x80}, 400, nil))
+	assert.Equal(1, len(na.ledger.Proposal))
+}
@@ -0,0 +1,145 @@
+// (c) 2022-2022, LDC Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package acct
+
+import (
+	"fmt"
+
+	"github.com/ldclabs/ldvm/ld"
+	"github.com/ldclabs/ldvm/signer"
+	"github.com/ldclabs/ldvm/util/erring"
+)
+
+// SetRecovery sets the account's guardians for social recovery,
+// empty guardians removes the recovery config. Any pending recovery is dropped.
+func (a *Account) SetRecovery(input *ld.TxRecovery) error {
+	errp := erring.ErrPrefix(fmt.Sprintf("acct.Account(%s).SetRecovery: ", a.ld.ID.String()))
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if a.ledger == nil {
+		return errp.Errorf("invalid ledger")
+	}
+
+	if err := input.SyntacticVerify(); err != nil {
+		return errp.ErrorIf(err)
+	}
+
+	if len(input.Guardians) == 0 {
+		if a.ledger.Recovery == nil {
+			return errp.Errorf("no recovery config to remove")
+		}
+		a.ledger.Recovery = nil
+		return nil
+	}
+
+	if input.Guardians.HasAddress(a.ld.ID) {
+		return errp.Errorf("invalid guardians, should not include the account itself")
+	}
+
+	a.ledger.Recovery = &ld.RecoveryEntry{
+		Threshold: input.Threshold,
+		Guardians: input.Guardians.Clone(),
+		Delay:     input.Delay,
+	}
+	return nil
+}
+
+// Recovery returns the account's recovery config.
+func (a *Account) Recovery() (*ld.RecoveryEntry, error) {
+	errp := erring.ErrPrefix(fmt.Sprintf("acct.Account(%s).Recovery: ", a.ld.ID.String()))
+
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+
+	switch {
+	case a.ledger == nil:
+		return nil, errp.Errorf("invalid ledger")
+
+	case a.ledger.Recovery == nil:
+		return nil, errp.Errorf("no recovery config")
+	}
+	return a.ledger.Recovery, nil
+}
+
+// StartRecovery proposes new keepers for the account,
+// they can be applied by ExecuteRecovery after the recovery delay.
+func (a *Account) StartRecovery(threshold uint16, keepers signer.Keys) error {
+	errp := erring.ErrPrefix(fmt.Sprintf("acct.Account(%s).StartRecovery: ", a.ld.ID.String()))
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	switch {
+	case a.ledger == nil:
+		return errp.Errorf("invalid ledger")
+
+	case a.ledger.Recovery == nil:
+		return errp.Errorf("no recovery config")
+
+	case a.ledger.Recovery.Pending != nil:
+		return errp.Errorf("recovery is pending, execute at %d",
+			a.ledger.Recovery.Pending.ExecuteAt)
+	}
+
+	a.ledger.Recovery.Pending = &ld.PendingRecovery{
+		Threshold: threshold,
+		Keepers:   keepers.Clone(),
+		ExecuteAt: a.ld.Timestamp + a.ledger.Recovery.Delay,
+	}
+	return nil
+}
+
+// CancelRecovery drops the pending recovery.
+func (a *Account) CancelRecovery() error {
+	errp := erring.ErrPrefix(fmt.Sprintf("acct.Account(%s).CancelRecovery: ", a.ld.ID.String()))
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	switch {
+	case a.ledger == nil:
+		return errp.Errorf("invalid ledger")
+
+	case a.ledger.Recovery == nil || a.ledger.Recovery.Pending == nil:
+		return errp.Errorf("no pending recovery")
+	}
+
+	a.ledger.Recovery.Pending = nil
+	return nil
+}
+
+// ExecuteRecovery replaces the account's keepers with the pending recovery's
+// after the recovery delay. The session keys, approver and approve list
+// may be controlled by the lost keys, so they are cleared too.
+func (a *Account) ExecuteRecovery() error {
+	errp := erring.ErrPrefix(fmt.Sprintf("acct.Account(%s).ExecuteRecovery: ", a.ld.ID.String()))
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	switch {
+	case a.ledger == nil:
+		return errp.Errorf("invalid ledger")
+
+	case a.ledger.Recovery == nil || a.ledger.Recovery.Pending == nil:
+		return errp.Errorf("no pending recovery")
+	}
+
+	p := a.ledger.Recovery.Pending
+	if a.ld.Timestamp < p.ExecuteAt {
+		return errp.Errorf("recovery is locked until %d, current time %d",
+			p.ExecuteAt, a.ld.Timestamp)
+	}
+
+	a.ld.Threshold = p.Threshold
+	a.ld.Keepers = p.Keepers
+	a.ld.Weights = nil
+	a.ld.Approver = nil
+	a.ld.ApproveList = nil
+	a.ld.SessionKeys = nil
+	a.ledger.Recovery.Pending = nil
+	return nil
+}
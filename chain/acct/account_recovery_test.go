@@ -0,0 +1,113 @@
+// (c) 2022-2022, LDC Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package acct
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ldclabs/ldvm/ld"
+	"github.com/ldclabs/ldvm/signer"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRecovery(t *testing.T) {
+	assert := assert.New(t)
+
+	na := NewAccount(signer.Signer1.Key().Address()).Init(big.NewInt(0), big.NewInt(0), 10, 100)
+	assert.NoError(na.UpdateKeepers(ld.Uint16Ptr(1), &signer.Keys{signer.Signer1.Key()}, nil, nil, nil))
+
+	input := &ld.TxRecovery{
+		Threshold: 1,
+		Guardians: signer.Keys{signer.Signer2.Key(), signer.Signer3.Key()},
+		Delay:     100,
+	}
+	newKeepers := signer.Keys{signer.Signer4.Key()}
+
+	assert.ErrorContains(na.SetRecovery(input), "invalid ledger")
+	_, err := na.Recovery()
+	assert.ErrorContains(err, "invalid ledger")
+	assert.ErrorContains(na.StartRecovery(1, newKeepers), "invalid ledger")
+	assert.ErrorContains(na.CancelRecovery(), "invalid ledger")
+	assert.ErrorContains(na.ExecuteRecovery(), "invalid ledger")
+
+	assert.NoError(na.LoadLedger(false, func() ([]byte, error) { return nil, nil }))
+	_, err = na.Recovery()
+	assert.ErrorContains(err, "no recovery config")
+	assert.ErrorContains(na.StartRecovery(1, newKeepers), "no recovery config")
+	assert.ErrorContains(na.CancelRecovery(), "no pending recovery")
+	assert.ErrorContains(na.ExecuteRecovery(), "no pending recovery")
+	assert.ErrorContains(na.SetRecovery(&ld.TxRecovery{Guardians: signer.Keys{}}),
+		"no recovery config to remove")
+	assert.ErrorContains(na.SetRecovery(&ld.TxRecovery{}), "invalid guardians")
+	assert.ErrorContains(na.SetRecovery(&ld.TxRecovery{
+		Threshold: 1,
+		Guardians: signer.Keys{signer.Signer1.Key()},
+		Delay:     100,
+	}), "invalid guardians, should not include the account itself")
+
+	assert.NoError(na.SetRecovery(input))
+	entry, err := na.Recovery()
+	require.NoError(t, err)
+	assert.Equal(uint16(1), entry.Threshold)
+	assert.Equal(input.Guardians, entry.Guardians)
+	assert.Equal(uint64(100), entry.Delay)
+	assert.Nil(entry.Pending)
+
+	assert.NoError(na.StartRecovery(1, newKeepers))
+	assert.ErrorContains(na.StartRecovery(1, newKeepers), "recovery is pending, execute at 200")
+	assert.ErrorContains(na.ExecuteRecovery(), "recovery is locked until 200, current time 100")
+
+	// cancel by keepers
+	assert.NoError(na.CancelRecovery())
+	assert.ErrorContains(na.CancelRecovery(), "no pending recovery")
+	assert.ErrorContains(na.ExecuteRecovery(), "no pending recovery")
+
+	assert.NoError(na.StartRecovery(1, newKeepers))
+
+	// Marshal
+	_, ledger, err := na.Marshal()
+	require.NoError(t, err)
+	lg := &ld.AccountLedger{}
+	assert.NoError(lg.Unmarshal(ledger))
+	assert.NoError(lg.SyntacticVerify())
+	assert.Equal(ledger, lg.Bytes())
+	require.NotNil(t, lg.Recovery)
+	require.NotNil(t, lg.Recovery.Pending)
+	assert.Equal(uint64(200), lg.Recovery.Pending.ExecuteAt)
+
+	na.ld.Timestamp = 199
+	assert.ErrorContains(na.ExecuteRecovery(), "recovery is locked until 200, current time 199")
+	// the lost keys may have set the session keys and the approver
+	na.ld.Approver = signer.Signer4.Key()
+	na.ld.ApproveList = ld.TxTypes{ld.TypeTransfer}
+	na.ld.SessionKeys = ld.SessionKeys{{
+		Key:     signer.Signer4.Key(),
+		TxTypes: ld.TxTypes{ld.TypeTransfer},
+		Expire:  1000,
+	}}
+	na.ld.Timestamp = 200
+	assert.NoError(na.ExecuteRecovery())
+	assert.Equal(uint16(1), na.Threshold())
+	assert.Equal(newKeepers, na.Keepers())
+	assert.Nil(na.ld.Approver)
+	assert.Nil(na.ld.ApproveList)
+	assert.Nil(na.ld.SessionKeys)
+	entry, err = na.Recovery()
+	require.NoError(t, err)
+	assert.Nil(entry.Pending)
+
+	// a new config drops the pending recovery
+	assert.NoError(na.StartRecovery(1, signer.Keys{signer.Signer1.Key()}))
+	assert.NoError(na.SetRecovery(input))
+	entry, err = na.Recovery()
+	require.NoError(t, err)
+	assert.Nil(entry.Pending)
+
+	// remove
+	assert.NoError(na.SetRecovery(&ld.TxRecovery{Guardians: signer.Keys{}}))
+	_, err = na.Recovery()
+	assert.ErrorContains(err, "no recovery config")
+}
@@ -0,0 +1,98 @@
+// (c) 2022-2022, LDC Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package acct
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/fxamacker/cbor/v2"
+	"github.com/ldclabs/ldvm/ids"
+	"github.com/ldclabs/ldvm/ld"
+	"github.com/ldclabs/ldvm/util/erring"
+)
+
+// ScheduleTx adds the scheduled tx identified by id to the sender's ledger.
+// An account can hold at most MaxScheduledTxsPerAccount pending scheduled txs,
+// and they should be due within MaxScheduleHeightDelay and MaxScheduleDelay.
+// The chain's due queue only indexes the scheduled txs by their schedule.
+func (a *Account) ScheduleTx(id ids.ID32, entry *ld.ScheduleEntry) error {
+	errp := erring.ErrPrefix(fmt.Sprintf("acct.Account(%s).ScheduleTx: ", a.ld.ID.String()))
+	return errp.ErrorIf(a.scheduleTx(id, entry, true))
+}
+
+// ScheduleProposalTx adds the approved proposal's inner tx identified by id to the sender's ledger.
+// It is not limited by MaxScheduledTxsPerAccount, the approved proposals are limited by
+// MaxProposals already, so that an approved proposal can always be executed.
+func (a *Account) ScheduleProposalTx(id ids.ID32, entry *ld.ScheduleEntry) error {
+	errp := erring.ErrPrefix(fmt.Sprintf("acct.Account(%s).ScheduleProposalTx: ", a.ld.ID.String()))
+	return errp.ErrorIf(a.scheduleTx(id, entry, false))
+}
+
+func (a *Account) scheduleTx(id ids.ID32, entry *ld.ScheduleEntry, capped bool) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	switch {
+	case a.ledger == nil:
+		return fmt.Errorf("invalid ledger")
+
+	case entry == nil:
+		return fmt.Errorf("nil entry")
+	}
+
+	if err := entry.SyntacticVerify(); err != nil {
+		return err
+	}
+
+	switch {
+	case entry.Sender != a.ld.ID:
+		return fmt.Errorf("invalid sender, expected %s, got %s", a.ld.ID, entry.Sender)
+
+	case entry.Height > a.ld.Height+ld.MaxScheduleHeightDelay:
+		return fmt.Errorf("invalid height, expected <= %d, got %d",
+			a.ld.Height+ld.MaxScheduleHeightDelay, entry.Height)
+
+	case entry.Timestamp > a.ld.Timestamp+ld.MaxScheduleDelay:
+		return fmt.Errorf("invalid timestamp, expected <= %d, got %d",
+			a.ld.Timestamp+ld.MaxScheduleDelay, entry.Timestamp)
+	}
+
+	key := cbor.ByteString(id[:])
+	if _, ok := a.ledger.Schedule[key]; ok {
+		return fmt.Errorf("scheduled tx %s exists", id)
+	}
+	if capped && len(a.ledger.Schedule) >= ld.MaxScheduledTxsPerAccount {
+		return fmt.Errorf("too many scheduled txs, expected <= %d", ld.MaxScheduledTxsPerAccount)
+	}
+
+	a.ledger.Schedule[key] = &ld.ScheduleEntry{
+		Sender:    entry.Sender,
+		Height:    entry.Height,
+		Timestamp: entry.Timestamp,
+		Reserve:   new(big.Int).Set(entry.Reserve),
+		Tx:        entry.Tx,
+	}
+	return nil
+}
+
+// TakeScheduledTx removes the scheduled tx identified by id from the account's ledger and returns it.
+func (a *Account) TakeScheduledTx(id ids.ID32) (*ld.ScheduleEntry, error) {
+	errp := erring.ErrPrefix(fmt.Sprintf("acct.Account(%s).TakeScheduledTx: ", a.ld.ID.String()))
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if a.ledger == nil {
+		return nil, errp.Errorf("invalid ledger")
+	}
+
+	key := cbor.ByteString(id[:])
+	e := a.ledger.Schedule[key]
+	if e == nil {
+		return nil, errp.Errorf("scheduled tx %s not found", id)
+	}
+	delete(a.ledger.Schedule, key)
+	return e, nil
+}
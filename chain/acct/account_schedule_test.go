@@ -0,0 +1,81 @@
+// (c) 2022-2022, LDC Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package acct
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ldclabs/ldvm/ids"
+	"github.com/ldclabs/ldvm/ld"
+	"github.com/ldclabs/ldvm/signer"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestScheduleTx(t *testing.T) {
+	assert := assert.New(t)
+
+	sender := signer.Signer1.Key().Address()
+	na := NewAccount(sender).Init(big.NewInt(0), big.NewInt(0), 10, 100)
+
+	entry := &ld.ScheduleEntry{
+		Sender:    sender,
+		Height:    11,
+		Timestamp: 0,
+		Reserve:   big.NewInt(1000),
+		Tx:        []byte{0x80},
+	}
+	assert.ErrorContains(na.ScheduleTx(ids.ID32{1}, entry), "invalid ledger")
+	_, err := na.TakeScheduledTx(ids.ID32{1})
+	assert.ErrorContains(err, "invalid ledger")
+
+	assert.NoError(na.LoadLedger(false, func() ([]byte, error) { return nil, nil }))
+	assert.ErrorContains(na.ScheduleTx(ids.ID32{1}, nil), "nil entry")
+	assert.ErrorContains(na.ScheduleTx(ids.ID32{1}, &ld.ScheduleEntry{Sender: sender}),
+		"invalid schedule")
+	assert.ErrorContains(na.ScheduleTx(ids.ID32{1}, &ld.ScheduleEntry{
+		Sender:  signer.Signer2.Key().Address(),
+		Height:  11,
+		Reserve: big.NewInt(0),
+		Tx:      []byte{0x80},
+	}), "invalid sender")
+	assert.ErrorContains(na.ScheduleTx(ids.ID32{1}, &ld.ScheduleEntry{
+		Sender:  sender,
+		Height:  11 + ld.MaxScheduleHeightDelay,
+		Reserve: big.NewInt(0),
+		Tx:      []byte{0x80},
+	}), "invalid height, expected <= 1000010, got 1000011")
+	assert.ErrorContains(na.ScheduleTx(ids.ID32{1}, &ld.ScheduleEntry{
+		Sender:    sender,
+		Timestamp: 101 + ld.MaxScheduleDelay,
+		Reserve:   big.NewInt(0),
+		Tx:        []byte{0x80},
+	}), "invalid timestamp, expected <= 2592100, got 2592101")
+
+	assert.NoError(na.ScheduleTx(ids.ID32{3}, entry))
+	assert.ErrorContains(na.ScheduleTx(ids.ID32{3}, entry),
+		"scheduled tx "+ids.ID32{3}.String()+" exists")
+	assert.NoError(na.ScheduleTx(ids.ID32{2}, entry))
+
+	_, err = na.TakeScheduledTx(ids.ID32{4})
+	assert.ErrorContains(err, "scheduled tx "+ids.ID32{4}.String()+" not found")
+	e, err := na.TakeScheduledTx(ids.ID32{2})
+	require.NoError(t, err)
+	assert.Equal(entry.Sender, e.Sender)
+	assert.Equal(entry.Reserve, e.Reserve)
+	assert.Equal(1, len(na.ledger.Schedule))
+
+	for i := 1; i < ld.MaxScheduledTxsPerAccount; i++ {
+		assert.NoError(na.ScheduleTx(ids.ID32{1, byte(i)}, entry))
+	}
+	assert.ErrorContains(na.ScheduleTx(ids.ID32{2}, entry),
+		"too many scheduled txs, expected <= 16")
+
+	// the approved proposals are not limited by the cap
+	assert.NoError(na.ScheduleProposalTx(ids.ID32{2}, entry))
+	assert.Equal(ld.MaxScheduledTxsPerAccount+1, len(na.ledger.Schedule))
+	assert.ErrorContains(na.ScheduleProposalTx(ids.ID32{2}, entry),
+		"acct.Account(0x8db97c7cECe249C2b98bdc0226cc4C2A57bF52fc).ScheduleProposalTx: scheduled tx "+ids.ID32{2}.String()+" exists")
+}
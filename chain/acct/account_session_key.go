@@ -0,0 +1,130 @@
+// (c) 2022-2022, LDC Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package acct
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/ldclabs/ldvm/ids"
+	"github.com/ldclabs/ldvm/ld"
+	"github.com/ldclabs/ldvm/signer"
+	"github.com/ldclabs/ldvm/util/erring"
+)
+
+// UpdateSessionKeys replaces the account's session keys, empty keys removes all of them.
+func (a *Account) UpdateSessionKeys(keys ld.SessionKeys) error {
+	errp := erring.ErrPrefix(fmt.Sprintf("acct.Account(%s).UpdateSessionKeys: ", a.ld.ID.String()))
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if err := keys.SyntacticVerify(); err != nil {
+		return errp.ErrorIf(err)
+	}
+
+	for _, s := range keys {
+		switch {
+		case a.ld.Keepers.Has(s.Key):
+			return errp.Errorf("invalid session key %s, it is a keeper", s.Key)
+
+		case s.Expire < a.ld.Timestamp:
+			return errp.Errorf("invalid expire for session key %s, expected >= %d, got %d",
+				s.Key, a.ld.Timestamp, s.Expire)
+		}
+	}
+
+	if len(keys) == 0 {
+		a.ld.SessionKeys = nil
+		return nil
+	}
+	a.ld.SessionKeys = keys.Clone()
+	return nil
+}
+
+// VerifySessionKey returns the session key that signed the digestHash,
+// the tx should be in the key's scope: allowed TxType, not expired and within the spending caps.
+func (a *Account) VerifySessionKey(
+	txType ld.TxType,
+	digestHash []byte,
+	sigs signer.Sigs,
+	token ids.TokenSymbol,
+	amount, cost *big.Int,
+) (signer.Key, error) {
+	errp := erring.ErrPrefix(fmt.Sprintf("acct.Account(%s).VerifySessionKey: ", a.ld.ID.String()))
+
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+
+	s := a.ld.SessionKeys.Find(digestHash, sigs)
+	switch {
+	case s == nil:
+		return nil, errp.Errorf("no session key signed")
+
+	case s.Expire < a.ld.Timestamp:
+		return nil, errp.Errorf("session key %s expired at %d", s.Key, s.Expire)
+
+	case !s.TxTypes.Has(txType) || !ld.SessionKeyTxTypes.Has(txType):
+		return nil, errp.Errorf("session key %s can't send %s", s.Key, txType)
+	}
+
+	for k, v := range sessionSpending(token, amount, cost) {
+		if limit := s.MaxAmounts[k.AsKey()]; limit == nil || v.Cmp(limit) > 0 {
+			return nil, errp.Errorf("insufficient %s cap of session key %s, expected %v, got %v",
+				k.GoString(), s.Key, v, limit)
+		}
+	}
+	return s.Key, nil
+}
+
+// SpendBySessionKey subtracts the tx's spending from the session key's caps.
+func (a *Account) SpendBySessionKey(
+	key signer.Key,
+	token ids.TokenSymbol,
+	amount, cost *big.Int,
+) error {
+	errp := erring.ErrPrefix(fmt.Sprintf("acct.Account(%s).SpendBySessionKey: ", a.ld.ID.String()))
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	var s *ld.SessionKey
+	for _, sk := range a.ld.SessionKeys {
+		if sk.Key.Equal(key) {
+			s = sk
+			break
+		}
+	}
+	if s == nil {
+		return errp.Errorf("session key %s not found", key)
+	}
+
+	spending := sessionSpending(token, amount, cost)
+	for k, v := range spending {
+		if limit := s.MaxAmounts[k.AsKey()]; limit == nil || v.Cmp(limit) > 0 {
+			return errp.Errorf("insufficient %s cap of session key %s, expected %v, got %v",
+				k.GoString(), s.Key, v, limit)
+		}
+	}
+	for k, v := range spending {
+		limit := s.MaxAmounts[k.AsKey()]
+		limit.Sub(limit, v)
+	}
+	return nil
+}
+
+func sessionSpending(token ids.TokenSymbol, amount, cost *big.Int) map[ids.TokenSymbol]*big.Int {
+	spending := make(map[ids.TokenSymbol]*big.Int, 2)
+	if cost != nil && cost.Sign() > 0 {
+		spending[ids.NativeToken] = new(big.Int).Set(cost)
+	}
+	if amount != nil && amount.Sign() > 0 {
+		if v := spending[token]; v != nil {
+			v.Add(v, amount)
+		} else {
+			spending[token] = new(big.Int).Set(amount)
+		}
+	}
+	return spending
+}
@@ -0,0 +1,117 @@
+// (c) 2022-2022, LDC Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package acct
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/fxamacker/cbor/v2"
+	"github.com/ldclabs/ldvm/ids"
+	"github.com/ldclabs/ldvm/ld"
+	"github.com/ldclabs/ldvm/signer"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSessionKey(t *testing.T) {
+	assert := assert.New(t)
+
+	token := ld.MustNewToken("$TEST")
+	na := NewAccount(signer.Signer1.Key().Address()).Init(big.NewInt(0), big.NewInt(0), 10, 100)
+	assert.NoError(na.UpdateKeepers(ld.Uint16Ptr(1), &signer.Keys{signer.Signer1.Key()}, nil, nil, nil))
+
+	sk := &ld.SessionKey{
+		Key:     signer.Signer2.Key(),
+		TxTypes: ld.TxTypes{ld.TypeUpdateData, ld.TypeTransfer},
+		MaxAmounts: map[cbor.ByteString]*big.Int{
+			ids.NativeToken.AsKey(): big.NewInt(1000),
+			token.AsKey():           big.NewInt(100),
+		},
+		Expire: 200,
+	}
+
+	assert.ErrorContains(na.UpdateSessionKeys(ld.SessionKeys{{}}), "empty txTypes")
+	assert.ErrorContains(na.UpdateSessionKeys(ld.SessionKeys{{
+		Key:        signer.Signer1.Key(),
+		TxTypes:    ld.TxTypes{ld.TypeUpdateData},
+		MaxAmounts: map[cbor.ByteString]*big.Int{},
+		Expire:     200,
+	}}), "invalid session key jbl8fOziScK5i9wCJsxMKle_UvwKxwPH, it is a keeper")
+	assert.ErrorContains(na.UpdateSessionKeys(ld.SessionKeys{{
+		Key:        signer.Signer2.Key(),
+		TxTypes:    ld.TxTypes{ld.TypeUpdateData},
+		MaxAmounts: map[cbor.ByteString]*big.Int{},
+		Expire:     99,
+	}}), "invalid expire for session key RBccN_9de3u43K1cgfFihKIp5kE1lmGG, expected >= 100, got 99")
+
+	assert.NoError(na.UpdateSessionKeys(ld.SessionKeys{sk}))
+	require.Equal(t, 1, len(na.LD().SessionKeys))
+	assert.Equal(sk, na.LD().SessionKeys[0])
+
+	digest := ids.ID32FromData([]byte("session key")).Bytes()
+	sig1, err := signer.Signer1.SignHash(digest)
+	require.NoError(t, err)
+	sig2, err := signer.Signer2.SignHash(digest)
+	require.NoError(t, err)
+
+	_, err = na.VerifySessionKey(ld.TypeUpdateData, digest, signer.Sigs{sig1},
+		ids.NativeToken, big.NewInt(0), big.NewInt(100))
+	assert.ErrorContains(err, "no session key signed")
+
+	_, err = na.VerifySessionKey(ld.TypeCreateData, digest, signer.Sigs{sig2},
+		ids.NativeToken, big.NewInt(0), big.NewInt(100))
+	assert.ErrorContains(err,
+		"session key RBccN_9de3u43K1cgfFihKIp5kE1lmGG can't send TypeCreateData")
+
+	_, err = na.VerifySessionKey(ld.TypeTransfer, digest, signer.Sigs{sig2},
+		ids.NativeToken, big.NewInt(901), big.NewInt(100))
+	assert.ErrorContains(err,
+		"insufficient NativeLDC cap of session key RBccN_9de3u43K1cgfFihKIp5kE1lmGG, expected 1001, got 1000")
+
+	_, err = na.VerifySessionKey(ld.TypeTransfer, digest, signer.Sigs{sig2},
+		ld.MustNewToken("$ABC"), big.NewInt(1), big.NewInt(100))
+	assert.ErrorContains(err,
+		"insufficient $ABC cap of session key RBccN_9de3u43K1cgfFihKIp5kE1lmGG, expected 1, got <nil>")
+
+	key, err := na.VerifySessionKey(ld.TypeTransfer, digest, signer.Sigs{sig2},
+		token, big.NewInt(100), big.NewInt(100))
+	require.NoError(t, err)
+	assert.Equal(signer.Signer2.Key(), key)
+
+	assert.ErrorContains(na.SpendBySessionKey(signer.Signer3.Key(),
+		token, big.NewInt(100), big.NewInt(100)), "session key OVlX-75gy0DuaRuz2k5QnlFVSuKOJezRd4CQdkIjkn5pYt0F not found")
+	assert.NoError(na.SpendBySessionKey(key, token, big.NewInt(100), big.NewInt(100)))
+	assert.Equal(uint64(900), na.LD().SessionKeys[0].MaxAmounts[ids.NativeToken.AsKey()].Uint64())
+	assert.Equal(uint64(0), na.LD().SessionKeys[0].MaxAmounts[token.AsKey()].Uint64())
+	assert.ErrorContains(na.SpendBySessionKey(key, token, big.NewInt(1), big.NewInt(100)),
+		"insufficient $TEST cap of session key RBccN_9de3u43K1cgfFihKIp5kE1lmGG, expected 1, got 0")
+
+	// the session key can be used until Expire
+	na.ld.Timestamp = 200
+	_, err = na.VerifySessionKey(ld.TypeUpdateData, digest, signer.Sigs{sig2},
+		ids.NativeToken, big.NewInt(0), big.NewInt(100))
+	assert.NoError(err)
+
+	na.ld.Timestamp = 201
+	_, err = na.VerifySessionKey(ld.TypeUpdateData, digest, signer.Sigs{sig2},
+		ids.NativeToken, big.NewInt(0), big.NewInt(100))
+	assert.ErrorContains(err, "session key RBccN_9de3u43K1cgfFihKIp5kE1lmGG expired at 200")
+
+	// Marshal
+	data, _, err := na.Marshal()
+	require.NoError(t, err)
+	na2, err := ParseAccount(na.ID(), data)
+	require.NoError(t, err)
+	require.Equal(t, 1, len(na2.LD().SessionKeys))
+	assert.Equal(sk.Key, na2.LD().SessionKeys[0].Key)
+	assert.Equal(sk.TxTypes, na2.LD().SessionKeys[0].TxTypes)
+	assert.Equal(uint64(900), na2.LD().SessionKeys[0].MaxAmounts[ids.NativeToken.AsKey()].Uint64())
+	assert.Equal(uint64(0), na2.LD().SessionKeys[0].MaxAmounts[token.AsKey()].Uint64())
+	assert.Equal(uint64(200), na2.LD().SessionKeys[0].Expire)
+
+	// remove
+	assert.NoError(na.UpdateSessionKeys(ld.SessionKeys{}))
+	assert.Nil(na.LD().SessionKeys)
+}
@@ -43,6 +43,9 @@ func (a *Account) CreateStake(
 	a.ld.Type = ld.StakeAccount
 	a.ld.Threshold = *acc.Threshold
 	a.ld.Keepers = *acc.Keepers
+	if acc.Weights != nil && len(*acc.Weights) > 0 {
+		a.ld.Weights = *acc.Weights
+	}
 
 	if acc.Approver != nil && acc.Approver.Valid() == nil {
 		a.ld.Approver = *acc.Approver
@@ -166,6 +169,7 @@ func (a *Account) DestroyStake(recipient *Account) error {
 	a.ld.Type = 0
 	a.ld.Threshold = 0
 	a.ld.Keepers = a.ld.Keepers[:0]
+	a.ld.Weights = nil
 	a.ld.NonceTable = make(map[uint64][]uint64)
 	a.ld.Approver = nil
 	a.ld.ApproveList = nil
@@ -0,0 +1,131 @@
+// (c) 2022-2022, LDC Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package acct
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/ldclabs/ldvm/ids"
+	"github.com/ldclabs/ldvm/ld"
+	"github.com/ldclabs/ldvm/util/erring"
+)
+
+// CreateSubscription approves the payee to pull up to the limit of token from the account in each period.
+func (a *Account) CreateSubscription(payee ids.Address, token ids.TokenSymbol, input *ld.TxSubscription) error {
+	errp := erring.ErrPrefix(fmt.Sprintf("acct.Account(%s).CreateSubscription: ", a.ld.ID.String()))
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if a.ledger == nil {
+		return errp.Errorf("invalid ledger")
+	}
+
+	if err := input.SyntacticVerify(); err != nil {
+		return errp.ErrorIf(err)
+	}
+
+	switch {
+	case payee == a.ld.ID:
+		return errp.Errorf("invalid payee")
+
+	case input.Expire > 0 && input.Expire <= a.ld.Timestamp:
+		return errp.Errorf("invalid expire, expected > %d, got %d", a.ld.Timestamp, input.Expire)
+	}
+
+	key := ld.AllowanceKey(payee, token)
+	if _, ok := a.ledger.Subscription[key]; ok {
+		return errp.Errorf("subscription for %s with %s exists", payee, token.GoString())
+	}
+
+	a.ledger.Subscription[key] = &ld.SubscriptionEntry{
+		Limit:       new(big.Int).Set(input.Limit),
+		Period:      input.Period,
+		Expire:      input.Expire,
+		PeriodStart: input.Period.Start(a.ld.Timestamp),
+		Spent:       new(big.Int),
+	}
+	return nil
+}
+
+// Subscription returns the payee's subscription of token, or nil if not exists.
+func (a *Account) Subscription(payee ids.Address, token ids.TokenSymbol) *ld.SubscriptionEntry {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+
+	if a.ledger != nil {
+		if e := a.ledger.Subscription[ld.AllowanceKey(payee, token)]; e != nil {
+			return &ld.SubscriptionEntry{
+				Limit:       new(big.Int).Set(e.Limit),
+				Period:      e.Period,
+				Expire:      e.Expire,
+				PeriodStart: e.PeriodStart,
+				Spent:       new(big.Int).Set(e.Spent),
+			}
+		}
+	}
+	return nil
+}
+
+// PullSubscription subtracts the amount of token from the account's balance by the payee,
+// the total amount pulled in a period should not exceed the subscription's limit.
+func (a *Account) PullSubscription(payee ids.Address, token ids.TokenSymbol, amount *big.Int) error {
+	errp := erring.ErrPrefix(fmt.Sprintf("acct.Account(%s).PullSubscription: ", a.ld.ID.String()))
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if a.ledger == nil {
+		return errp.Errorf("invalid ledger")
+	}
+
+	e := a.ledger.Subscription[ld.AllowanceKey(payee, token)]
+	switch {
+	case e == nil:
+		return errp.Errorf("%s has no subscription for %s", payee, token.GoString())
+
+	case e.Expire > 0 && e.Expire < a.ld.Timestamp:
+		return errp.Errorf("subscription expired at %d", e.Expire)
+
+	case amount == nil || amount.Sign() <= 0:
+		return errp.Errorf("invalid amount %v", amount)
+	}
+
+	if remaining := e.Remaining(a.ld.Timestamp); amount.Cmp(remaining) > 0 {
+		return errp.Errorf("insufficient subscription limit in period, expected %v, got %v",
+			amount, remaining)
+	}
+
+	if err := a.checkBalance(token, amount, true); err != nil {
+		return errp.ErrorIf(err)
+	}
+
+	a.subNoCheck(token, amount)
+	if start := e.Period.Start(a.ld.Timestamp); start != e.PeriodStart {
+		e.PeriodStart = start
+		e.Spent.SetUint64(0)
+	}
+	e.Spent.Add(e.Spent, amount)
+	return nil
+}
+
+// RevokeSubscription removes the payee's subscription of token.
+func (a *Account) RevokeSubscription(payee ids.Address, token ids.TokenSymbol) error {
+	errp := erring.ErrPrefix(fmt.Sprintf("acct.Account(%s).RevokeSubscription: ", a.ld.ID.String()))
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if a.ledger == nil {
+		return errp.Errorf("invalid ledger")
+	}
+
+	key := ld.AllowanceKey(payee, token)
+	if _, ok := a.ledger.Subscription[key]; !ok {
+		return errp.Errorf("%s has no subscription for %s", payee, token.GoString())
+	}
+	delete(a.ledger.Subscription, key)
+	return nil
+}
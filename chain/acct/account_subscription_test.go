@@ -0,0 +1,93 @@
+// (c) 2022-2022, LDC Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package acct
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ldclabs/ldvm/ld"
+	"github.com/ldclabs/ldvm/signer"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSubscription(t *testing.T) {
+	assert := assert.New(t)
+
+	payee := signer.Signer2.Key().Address()
+	token := ld.MustNewToken("$TEST")
+	// 2022-03-15T10:00:00Z
+	na := NewAccount(signer.Signer1.Key().Address()).Init(big.NewInt(0), big.NewInt(0), 10, 1647338400)
+
+	input := &ld.TxSubscription{Limit: big.NewInt(1000), Period: ld.PeriodDaily}
+	assert.ErrorContains(na.CreateSubscription(payee, token, input), "invalid ledger")
+	assert.ErrorContains(na.PullSubscription(payee, token, big.NewInt(100)), "invalid ledger")
+	assert.ErrorContains(na.RevokeSubscription(payee, token), "invalid ledger")
+	assert.Nil(na.Subscription(payee, token))
+
+	assert.NoError(na.LoadLedger(false, func() ([]byte, error) { return nil, nil }))
+	assert.ErrorContains(na.CreateSubscription(payee, token, &ld.TxSubscription{}), "invalid limit")
+	assert.ErrorContains(na.CreateSubscription(na.ID(), token, input), "invalid payee")
+	assert.ErrorContains(na.CreateSubscription(payee, token,
+		&ld.TxSubscription{Limit: big.NewInt(1000), Expire: 1647338400}),
+		"invalid expire, expected > 1647338400, got 1647338400")
+	assert.ErrorContains(na.PullSubscription(payee, token, big.NewInt(100)),
+		"0x44171C37Ff5D7B7bb8Dcad5C81f16284A229E641 has no subscription for $TEST")
+	assert.ErrorContains(na.RevokeSubscription(payee, token),
+		"0x44171C37Ff5D7B7bb8Dcad5C81f16284A229E641 has no subscription for $TEST")
+
+	assert.NoError(na.CreateSubscription(payee, token, input))
+	assert.ErrorContains(na.CreateSubscription(payee, token, input),
+		"subscription for 0x44171C37Ff5D7B7bb8Dcad5C81f16284A229E641 with $TEST exists")
+	e := na.Subscription(payee, token)
+	require.NotNil(t, e)
+	assert.Equal(uint64(1647302400), e.PeriodStart)
+	assert.Equal(uint64(0), e.Spent.Uint64())
+
+	assert.ErrorContains(na.PullSubscription(payee, token, big.NewInt(0)), "invalid amount 0")
+	assert.ErrorContains(na.PullSubscription(payee, token, big.NewInt(1001)),
+		"insufficient subscription limit in period, expected 1001, got 1000")
+	assert.ErrorContains(na.PullSubscription(payee, token, big.NewInt(100)),
+		"insufficient transferable $TEST balance, expected 100, got 0")
+
+	assert.NoError(na.Add(token, big.NewInt(3000)))
+	assert.NoError(na.PullSubscription(payee, token, big.NewInt(600)))
+	assert.NoError(na.PullSubscription(payee, token, big.NewInt(400)))
+	assert.Equal(uint64(2000), na.BalanceOf(token).Uint64())
+	assert.ErrorContains(na.PullSubscription(payee, token, big.NewInt(1)),
+		"insufficient subscription limit in period, expected 1, got 0")
+
+	// Marshal
+	_, ledger, err := na.Marshal()
+	require.NoError(t, err)
+	lg := &ld.AccountLedger{}
+	assert.NoError(lg.Unmarshal(ledger))
+	assert.NoError(lg.SyntacticVerify())
+	assert.Equal(ledger, lg.Bytes())
+	assert.Equal(1, len(lg.Subscription))
+
+	// next period
+	na.ld.Timestamp = 1647388800 // 2022-03-16T00:00:00Z
+	assert.NoError(na.PullSubscription(payee, token, big.NewInt(300)))
+	e = na.Subscription(payee, token)
+	assert.Equal(uint64(1647388800), e.PeriodStart)
+	assert.Equal(uint64(300), e.Spent.Uint64())
+	assert.Equal(uint64(1700), na.BalanceOf(token).Uint64())
+
+	assert.NoError(na.RevokeSubscription(payee, token))
+	assert.Nil(na.Subscription(payee, token))
+	assert.ErrorContains(na.PullSubscription(payee, token, big.NewInt(1)), "has no subscription")
+
+	// monthly with expire
+	input = &ld.TxSubscription{Limit: big.NewInt(1000), Period: ld.PeriodMonthly, Expire: 1648000000}
+	assert.NoError(na.CreateSubscription(payee, token, input))
+	assert.NoError(na.PullSubscription(payee, token, big.NewInt(1000)))
+	na.ld.Timestamp = 1647999999
+	assert.ErrorContains(na.PullSubscription(payee, token, big.NewInt(1)),
+		"insufficient subscription limit in period, expected 1, got 0")
+	na.ld.Timestamp = 1648000001
+	assert.ErrorContains(na.PullSubscription(payee, token, big.NewInt(1)),
+		"subscription expired at 1648000000")
+}
@@ -22,9 +22,23 @@ func (a *Account) TotalSupply() *big.Int {
 	}
 
 	total.Set(a.ld.MaxTotalSupply)
+	if a.ld.BurnedSupply != nil {
+		total.Sub(total, a.ld.BurnedSupply)
+	}
 	return total.Sub(total, a.balanceOf(ids.TokenSymbol(a.ld.ID), false))
 }
 
+func (a *Account) BurnedSupply() *big.Int {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+
+	total := new(big.Int)
+	if a.ld.Type == ld.TokenAccount && a.ld.BurnedSupply != nil {
+		total.Set(a.ld.BurnedSupply)
+	}
+	return total
+}
+
 func (a *Account) CreateToken(data *ld.TxAccounter) error {
 	errp := erring.ErrPrefix(fmt.Sprintf("acct.Account(%s).CreateToken: ", a.ld.ID.String()))
 
@@ -42,12 +56,28 @@ func (a *Account) CreateToken(data *ld.TxAccounter) error {
 
 	a.ld.Type = ld.TokenAccount
 	a.ld.MaxTotalSupply = new(big.Int).Set(data.Amount)
+	if data.SupplyCap != nil {
+		if data.SupplyCap.Cmp(data.Amount) < 0 {
+			return errp.Errorf("invalid supplyCap, expected >= %v, got %v",
+				data.Amount, data.SupplyCap)
+		}
+		a.ld.SupplyCap = new(big.Int).Set(data.SupplyCap)
+	}
+	if data.TokenInfo != nil {
+		a.ld.TokenInfo = &ld.TokenInfo{}
+		if err := ld.Copy(a.ld.TokenInfo, data.TokenInfo); err != nil {
+			return errp.ErrorIf(err)
+		}
+	}
 	switch token {
 	case ids.NativeToken: // NativeToken created by genesis tx
 		a.ld.Balance.Set(data.Amount)
 	default:
 		a.ld.Threshold = *data.Threshold
 		a.ld.Keepers = *data.Keepers
+		if data.Weights != nil && len(*data.Weights) > 0 {
+			a.ld.Weights = *data.Weights
+		}
 
 		if data.Approver != nil && data.Approver.Valid() == nil {
 			a.ld.Approver = *data.Approver
@@ -72,11 +102,15 @@ func (a *Account) DestroyToken(recipient *Account) error {
 	}
 
 	tk := a.ld.Tokens[token.AsKey()]
+	unused := new(big.Int).Set(a.ld.MaxTotalSupply)
+	if a.ld.BurnedSupply != nil {
+		unused.Sub(unused, a.ld.BurnedSupply)
+	}
 	if tk == nil {
 		return errp.Errorf("invalid token %s", token.GoString())
-	} else if tk.Cmp(a.ld.MaxTotalSupply) != 0 {
+	} else if tk.Cmp(unused) != 0 {
 		return errp.Errorf("some token in the use, maxTotalSupply expected %v, got %v",
-			a.ld.MaxTotalSupply, tk)
+			unused, tk)
 	}
 
 	if err := a.closeLending(true); err != nil {
@@ -88,10 +122,94 @@ func (a *Account) DestroyToken(recipient *Account) error {
 	a.ld.Balance.SetUint64(0)
 	a.ld.Threshold = 0
 	a.ld.Keepers = a.ld.Keepers[:0]
+	a.ld.Weights = nil
 	a.ld.NonceTable = make(map[uint64][]uint64)
 	a.ld.Approver = nil
 	a.ld.ApproveList = nil
 	a.ld.MaxTotalSupply = nil
+	a.ld.SupplyCap = nil
+	a.ld.BurnedSupply = nil
+	a.ld.TokenInfo = nil
 	delete(a.ld.Tokens, token.AsKey())
 	return nil
 }
+
+func (a *Account) UpdateTokenInfo(info *ld.TokenInfo) error {
+	errp := erring.ErrPrefix(fmt.Sprintf("acct.Account(%s).UpdateTokenInfo: ", a.ld.ID.String()))
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	token := ids.TokenSymbol(a.ld.ID)
+	if !a.valid(ld.TokenAccount) {
+		return errp.Errorf("invalid token account %s", token.GoString())
+	}
+
+	ti := &ld.TokenInfo{}
+	if err := ld.Copy(ti, info); err != nil {
+		return errp.ErrorIf(err)
+	}
+	a.ld.TokenInfo = ti
+	return nil
+}
+
+// MintToken issues new token to the recipient, the MaxTotalSupply should not exceed the SupplyCap.
+func (a *Account) MintToken(recipient *Account, amount *big.Int) error {
+	errp := erring.ErrPrefix(fmt.Sprintf("acct.Account(%s).MintToken: ", a.ld.ID.String()))
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	token := ids.TokenSymbol(a.ld.ID)
+	switch {
+	case !a.valid(ld.TokenAccount):
+		return errp.Errorf("invalid token account %s", token.GoString())
+
+	case a.ld.SupplyCap == nil:
+		return errp.Errorf("token %s is not mintable", token.GoString())
+
+	case amount == nil || amount.Sign() <= 0:
+		return errp.Errorf("invalid amount %v", amount)
+	}
+
+	total := new(big.Int).Add(a.ld.MaxTotalSupply, amount)
+	if total.Cmp(a.ld.SupplyCap) > 0 {
+		return errp.Errorf("exceeded supplyCap, expected <= %v, got %v",
+			a.ld.SupplyCap, total)
+	}
+
+	if err := recipient.Add(token, amount); err != nil {
+		return errp.ErrorIf(err)
+	}
+	a.ld.MaxTotalSupply = total
+	return nil
+}
+
+// BurnToken burns the token that the token account received.
+func (a *Account) BurnToken(amount *big.Int) error {
+	errp := erring.ErrPrefix(fmt.Sprintf("acct.Account(%s).BurnToken: ", a.ld.ID.String()))
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	token := ids.TokenSymbol(a.ld.ID)
+	switch {
+	case a.ld.Type != ld.TokenAccount || a.ld.MaxTotalSupply == nil:
+		return errp.Errorf("invalid token account %s", token.GoString())
+
+	case amount == nil || amount.Sign() <= 0:
+		return errp.Errorf("invalid amount %v", amount)
+	}
+
+	if ba := a.balanceOfAll(token); amount.Cmp(ba) > 0 {
+		return errp.Errorf("insufficient %s balance, expected %v, got %v",
+			token.GoString(), amount, ba)
+	}
+
+	a.subNoCheck(token, amount)
+	if a.ld.BurnedSupply == nil {
+		a.ld.BurnedSupply = new(big.Int)
+	}
+	a.ld.BurnedSupply.Add(a.ld.BurnedSupply, amount)
+	return nil
+}
@@ -161,3 +161,156 @@ func TestTokenAccount(t *testing.T) {
 	testToken.Add(ids.NativeToken, big.NewInt(100))
 	assert.Equal(true, testToken.valid(ld.TokenAccount))
 }
+
+func TestTokenMintAndBurn(t *testing.T) {
+	assert := assert.New(t)
+
+	acc := NewAccount(signer.Signer1.Key().Address())
+	acc.Init(big.NewInt(0), big.NewInt(0), 0, 0)
+	token := ld.MustNewToken("$TEST")
+	testToken := NewAccount(ids.Address(token))
+	testToken.Init(big.NewInt(0), big.NewInt(100), 0, 0)
+
+	assert.ErrorContains(testToken.MintToken(acc, big.NewInt(100)),
+		"invalid token account $TEST")
+	assert.ErrorContains(testToken.BurnToken(big.NewInt(100)),
+		"invalid token account $TEST")
+	assert.ErrorContains(testToken.CreateToken(&ld.TxAccounter{
+		Threshold: ld.Uint16Ptr(1),
+		Keepers:   &signer.Keys{signer.Signer1.Key()},
+		Amount:    big.NewInt(1000),
+		SupplyCap: big.NewInt(999),
+	}), "invalid supplyCap, expected >= 1000, got 999")
+
+	testToken = NewAccount(ids.Address(token))
+	testToken.Init(big.NewInt(0), big.NewInt(100), 0, 0)
+	assert.NoError(testToken.CreateToken(&ld.TxAccounter{
+		Threshold: ld.Uint16Ptr(1),
+		Keepers:   &signer.Keys{signer.Signer1.Key()},
+		Amount:    big.NewInt(1000),
+	}))
+	testToken.Add(ids.NativeToken, big.NewInt(100))
+	assert.ErrorContains(testToken.MintToken(acc, big.NewInt(100)),
+		"token $TEST is not mintable")
+
+	testToken = NewAccount(ids.Address(token))
+	testToken.Init(big.NewInt(0), big.NewInt(100), 0, 0)
+	assert.NoError(testToken.CreateToken(&ld.TxAccounter{
+		Threshold: ld.Uint16Ptr(1),
+		Keepers:   &signer.Keys{signer.Signer1.Key()},
+		Amount:    big.NewInt(1000),
+		SupplyCap: big.NewInt(2000),
+	}))
+	testToken.Add(ids.NativeToken, big.NewInt(100))
+	assert.Equal(uint64(2000), testToken.ld.SupplyCap.Uint64())
+	assert.Equal(uint64(0), testToken.TotalSupply().Uint64())
+	assert.Equal(uint64(0), testToken.BurnedSupply().Uint64())
+
+	assert.ErrorContains(testToken.MintToken(acc, big.NewInt(0)),
+		"invalid amount 0")
+	assert.ErrorContains(testToken.MintToken(acc, big.NewInt(1001)),
+		"exceeded supplyCap, expected <= 2000, got 2001")
+	assert.NoError(testToken.MintToken(acc, big.NewInt(600)))
+	assert.Equal(uint64(1600), testToken.ld.MaxTotalSupply.Uint64())
+	assert.Equal(uint64(600), acc.BalanceOf(token).Uint64())
+	assert.Equal(uint64(600), testToken.TotalSupply().Uint64())
+
+	// holder burns token by sending it to the token account
+	assert.NoError(acc.Sub(token, big.NewInt(200)))
+	assert.NoError(testToken.Add(token, big.NewInt(200)))
+	assert.Equal(uint64(400), testToken.TotalSupply().Uint64())
+	assert.ErrorContains(testToken.BurnToken(big.NewInt(0)), "invalid amount 0")
+	assert.ErrorContains(testToken.BurnToken(big.NewInt(1201)),
+		"insufficient $TEST balance, expected 1201, got 1200")
+	assert.NoError(testToken.BurnToken(big.NewInt(200)))
+	assert.Equal(uint64(400), testToken.TotalSupply().Uint64())
+	assert.Equal(uint64(200), testToken.BurnedSupply().Uint64())
+	assert.Equal(uint64(1000), testToken.BalanceOf(token).Uint64())
+	assert.Equal(uint64(1600), testToken.ld.MaxTotalSupply.Uint64())
+	assert.Equal(uint64(400), testToken.ld.TotalSupply().Uint64())
+
+	// burned token can not be minted again
+	assert.ErrorContains(testToken.MintToken(acc, big.NewInt(401)),
+		"exceeded supplyCap, expected <= 2000, got 2001")
+	assert.NoError(testToken.MintToken(acc, big.NewInt(400)))
+	assert.Equal(uint64(800), testToken.TotalSupply().Uint64())
+
+	// Marshal
+	data, _, err := testToken.Marshal()
+	require.NoError(t, err)
+	acc2, err := ParseAccount(testToken.ID(), data)
+	require.NoError(t, err)
+	assert.Equal(testToken.ld.Bytes(), acc2.ld.Bytes())
+	assert.Equal(uint64(200), acc2.BurnedSupply().Uint64())
+
+	assert.NoError(testToken.LoadLedger(false, func() ([]byte, error) { return nil, nil }))
+	assert.ErrorContains(testToken.DestroyToken(acc),
+		"some token in the use, maxTotalSupply expected 1800, got 1000")
+	assert.NoError(acc.Sub(token, big.NewInt(800)))
+	assert.NoError(testToken.Add(token, big.NewInt(800)))
+	assert.NoError(testToken.DestroyToken(acc))
+	assert.Nil(testToken.ld.SupplyCap)
+	assert.Nil(testToken.ld.BurnedSupply)
+
+	// burn NativeToken
+	nativeToken := NewAccount(ids.LDCAccount)
+	assert.NoError(nativeToken.CreateToken(&ld.TxAccounter{
+		Amount: big.NewInt(1000),
+	}))
+	assert.ErrorContains(nativeToken.MintToken(acc, big.NewInt(100)),
+		"token NativeLDC is not mintable")
+	assert.NoError(nativeToken.Sub(ids.NativeToken, big.NewInt(500)))
+	assert.Equal(uint64(500), nativeToken.TotalSupply().Uint64())
+	assert.NoError(nativeToken.BurnToken(big.NewInt(100)))
+	assert.Equal(uint64(500), nativeToken.TotalSupply().Uint64())
+	assert.Equal(uint64(400), nativeToken.Balance().Uint64())
+	assert.Equal(uint64(100), nativeToken.BurnedSupply().Uint64())
+}
+
+func TestTokenInfo(t *testing.T) {
+	assert := assert.New(t)
+
+	token := ld.MustNewToken("$TEST")
+	testToken := NewAccount(ids.Address(token))
+	testToken.Init(big.NewInt(0), big.NewInt(100), 0, 0)
+
+	info := &ld.TokenInfo{Name: "Test Token", Decimals: 6}
+	assert.ErrorContains(testToken.UpdateTokenInfo(info),
+		"invalid token account $TEST")
+
+	assert.NoError(testToken.CreateToken(&ld.TxAccounter{
+		Threshold: ld.Uint16Ptr(1),
+		Keepers:   &signer.Keys{signer.Signer1.Key()},
+		Amount:    big.NewInt(1000),
+		TokenInfo: info,
+	}))
+	testToken.Add(ids.NativeToken, big.NewInt(100))
+	assert.Equal("Test Token", testToken.ld.TokenInfo.Name)
+	assert.Equal(uint8(6), testToken.ld.TokenInfo.Decimals)
+	info.Name = "Changed"
+	assert.Equal("Test Token", testToken.ld.TokenInfo.Name)
+
+	assert.ErrorContains(testToken.UpdateTokenInfo(&ld.TokenInfo{}),
+		"invalid name")
+	assert.NoError(testToken.UpdateTokenInfo(&ld.TokenInfo{
+		Name:     "Test Token",
+		Decimals: 9,
+		Icon:     "https://ldc.io/test.png",
+	}))
+	assert.Equal(uint8(9), testToken.ld.TokenInfo.Decimals)
+	assert.Equal("https://ldc.io/test.png", testToken.ld.TokenInfo.Icon)
+
+	// Marshal
+	data, _, err := testToken.Marshal()
+	require.NoError(t, err)
+	acc2, err := ParseAccount(testToken.ID(), data)
+	require.NoError(t, err)
+	assert.Equal(testToken.ld.Bytes(), acc2.ld.Bytes())
+	assert.Equal("https://ldc.io/test.png", acc2.ld.TokenInfo.Icon)
+
+	assert.NoError(testToken.LoadLedger(false, func() ([]byte, error) { return nil, nil }))
+	acc := NewAccount(signer.Signer1.Key().Address())
+	acc.Init(big.NewInt(0), big.NewInt(0), 0, 0)
+	assert.NoError(testToken.DestroyToken(acc))
+	assert.Nil(testToken.ld.TokenInfo)
+}
@@ -0,0 +1,119 @@
+// (c) 2022-2022, LDC Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package acct
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/fxamacker/cbor/v2"
+	"github.com/ldclabs/ldvm/ids"
+	"github.com/ldclabs/ldvm/ld"
+	"github.com/ldclabs/ldvm/util/erring"
+)
+
+// CreateVesting adds a vesting schedule to the beneficiary's ledger,
+// the locked amount should be subtracted from the grantor.
+func (a *Account) CreateVesting(
+	id ids.ID32,
+	token ids.TokenSymbol,
+	grantor ids.Address,
+	cfg *ld.VestingConfig,
+) error {
+	errp := erring.ErrPrefix(fmt.Sprintf("acct.Account(%s).CreateVesting: ", a.ld.ID.String()))
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if a.ledger == nil {
+		return errp.Errorf("invalid ledger")
+	}
+
+	if err := cfg.SyntacticVerify(); err != nil {
+		return errp.ErrorIf(err)
+	}
+
+	if cfg.EndTime <= a.ld.Timestamp {
+		return errp.Errorf("invalid endTime, expected > %d, got %d", a.ld.Timestamp, cfg.EndTime)
+	}
+
+	key := cbor.ByteString(id[:])
+	if _, ok := a.ledger.Vesting[key]; ok {
+		return errp.Errorf("vesting %s exists", id)
+	}
+
+	e := &ld.VestingEntry{
+		Grantor:   grantor,
+		Token:     token,
+		Amount:    new(big.Int).Set(cfg.Amount),
+		Claimed:   new(big.Int),
+		StartTime: a.ld.Timestamp,
+		CliffTime: cfg.CliffTime,
+		EndTime:   cfg.EndTime,
+	}
+	if e.CliffTime < e.StartTime {
+		e.CliffTime = e.StartTime
+	}
+
+	a.ledger.Vesting[key] = e
+	return nil
+}
+
+// ClaimVesting releases the claimable amount of the token from all vesting schedules
+// to the account's balance, returns the claimed amount.
+func (a *Account) ClaimVesting(token ids.TokenSymbol) (*big.Int, error) {
+	errp := erring.ErrPrefix(fmt.Sprintf("acct.Account(%s).ClaimVesting: ", a.ld.ID.String()))
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if a.ledger == nil {
+		return nil, errp.Errorf("invalid ledger")
+	}
+
+	total := new(big.Int)
+	has := false
+	for k, e := range a.ledger.Vesting {
+		if e.Token != token {
+			continue
+		}
+
+		has = true
+		if releasable := e.Releasable(a.ld.Timestamp); releasable.Sign() > 0 {
+			total.Add(total, releasable)
+			e.Claimed.Add(e.Claimed, releasable)
+			if e.Claimed.Cmp(e.Amount) >= 0 {
+				delete(a.ledger.Vesting, k)
+			}
+		}
+	}
+
+	switch {
+	case !has:
+		return nil, errp.Errorf("no %s vesting to claim", token.GoString())
+
+	case total.Sign() == 0:
+		return nil, errp.Errorf("no %s released to claim", token.GoString())
+	}
+
+	a.addNoCheck(token, total)
+	return total, nil
+}
+
+// VestingOf returns the locked amount of the token that has not been claimed.
+func (a *Account) VestingOf(token ids.TokenSymbol) *big.Int {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+
+	total := new(big.Int)
+	if a.ledger != nil {
+		for _, e := range a.ledger.Vesting {
+			if e.Token == token {
+				total.Add(total, e.Amount)
+				total.Sub(total, e.Claimed)
+			}
+		}
+	}
+	return total
+}
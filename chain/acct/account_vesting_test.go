@@ -0,0 +1,123 @@
+// (c) 2022-2022, LDC Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package acct
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ldclabs/ldvm/ids"
+	"github.com/ldclabs/ldvm/ld"
+	"github.com/ldclabs/ldvm/signer"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestVesting(t *testing.T) {
+	assert := assert.New(t)
+
+	grantor := signer.Signer2.Key().Address()
+	token := ld.MustNewToken("$TEST")
+	id1 := ids.ID32{1, 2, 3}
+	id2 := ids.ID32{4, 5, 6}
+	na := NewAccount(signer.Signer1.Key().Address()).Init(big.NewInt(0), big.NewInt(0), 10, 100)
+
+	cfg := &ld.VestingConfig{
+		Amount:    big.NewInt(1000),
+		CliffTime: 200,
+		EndTime:   1100,
+	}
+	assert.ErrorContains(na.CreateVesting(id1, ids.NativeToken, grantor, cfg),
+		"invalid ledger")
+	_, err := na.ClaimVesting(ids.NativeToken)
+	assert.ErrorContains(err, "invalid ledger")
+
+	assert.NoError(na.LoadLedger(false, func() ([]byte, error) { return nil, nil }))
+	_, err = na.ClaimVesting(ids.NativeToken)
+	assert.ErrorContains(err, "no NativeLDC vesting to claim")
+
+	assert.ErrorContains(na.CreateVesting(id1, ids.NativeToken, grantor, &ld.VestingConfig{
+		Amount:  big.NewInt(1000),
+		EndTime: 100,
+	}), "invalid endTime, expected > 100, got 100")
+	assert.ErrorContains(na.CreateVesting(id1, ids.NativeToken, grantor, &ld.VestingConfig{
+		Amount:    big.NewInt(1000),
+		CliffTime: 300,
+		EndTime:   200,
+	}), "invalid cliffTime, expected <= 200, got 300")
+
+	assert.NoError(na.CreateVesting(id1, ids.NativeToken, grantor, cfg))
+	assert.ErrorContains(na.CreateVesting(id1, ids.NativeToken, grantor, cfg),
+		"vesting AQIDAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAoWLSv exists")
+	assert.NoError(na.CreateVesting(id2, token, grantor, &ld.VestingConfig{
+		Amount:  big.NewInt(500),
+		EndTime: 600,
+	}))
+	assert.Equal(uint64(1000), na.VestingOf(ids.NativeToken).Uint64())
+	assert.Equal(uint64(500), na.VestingOf(token).Uint64())
+	assert.Equal(uint64(0), na.Balance().Uint64())
+
+	_, err = na.ClaimVesting(ids.NativeToken)
+	assert.ErrorContains(err, "no NativeLDC released to claim")
+	_, err = na.ClaimVesting(token)
+	assert.ErrorContains(err, "no $TEST released to claim")
+
+	// before the cliff
+	na.ld.Timestamp = 199
+	_, err = na.ClaimVesting(ids.NativeToken)
+	assert.ErrorContains(err, "no NativeLDC released to claim")
+
+	// at the cliff
+	na.ld.Timestamp = 200
+	am, err := na.ClaimVesting(ids.NativeToken)
+	require.NoError(t, err)
+	assert.Equal(uint64(100), am.Uint64())
+	assert.Equal(uint64(100), na.Balance().Uint64())
+	assert.Equal(uint64(900), na.VestingOf(ids.NativeToken).Uint64())
+	_, err = na.ClaimVesting(ids.NativeToken)
+	assert.ErrorContains(err, "no NativeLDC released to claim")
+
+	am, err = na.ClaimVesting(token)
+	require.NoError(t, err)
+	assert.Equal(uint64(100), am.Uint64())
+	assert.Equal(uint64(100), na.BalanceOf(token).Uint64())
+
+	// linear
+	na.ld.Timestamp = 600
+	am, err = na.ClaimVesting(ids.NativeToken)
+	require.NoError(t, err)
+	assert.Equal(uint64(400), am.Uint64())
+	assert.Equal(uint64(500), na.Balance().Uint64())
+
+	am, err = na.ClaimVesting(token)
+	require.NoError(t, err)
+	assert.Equal(uint64(400), am.Uint64())
+	assert.Equal(uint64(500), na.BalanceOf(token).Uint64())
+	assert.Equal(uint64(0), na.VestingOf(token).Uint64())
+	assert.Equal(1, len(na.ledger.Vesting))
+
+	// Marshal
+	data, ledger, err := na.Marshal()
+	require.NoError(t, err)
+	na2, err := ParseAccount(na.ld.ID, data)
+	require.NoError(t, err)
+	assert.Equal(na.ld.Bytes(), na2.ld.Bytes())
+
+	lg := &ld.AccountLedger{}
+	assert.NoError(lg.Unmarshal(ledger))
+	assert.NoError(lg.SyntacticVerify())
+	assert.Equal(ledger, lg.Bytes())
+	assert.Equal(1, len(lg.Vesting))
+
+	// after the end
+	na.ld.Timestamp = 2000
+	am, err = na.ClaimVesting(ids.NativeToken)
+	require.NoError(t, err)
+	assert.Equal(uint64(500), am.Uint64())
+	assert.Equal(uint64(1000), na.Balance().Uint64())
+	assert.Equal(0, len(na.ledger.Vesting))
+
+	_, err = na.ClaimVesting(ids.NativeToken)
+	assert.ErrorContains(err, "no NativeLDC vesting to claim")
+}
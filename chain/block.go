@@ -204,6 +204,142 @@ func (b *Block) tryBuildTxs(vbs BlockState, add bool, txs ...*ld.Transaction) (c
 	return choices.Processing, nil
 }
 
+// RunScheduledTxs executes the due scheduled txs at the start of the block,
+// sets the results to the block and returns the new state.
+func (b *Block) RunScheduledTxs(vbs BlockState) (BlockState, error) {
+	errp := erring.ErrPrefix("chain.Block.RunScheduledTxs: ")
+	nbs, results, gas, err := b.runScheduledTxs(vbs)
+	if err != nil {
+		return nil, errp.ErrorIf(err)
+	}
+
+	b.ld.Scheduled = results
+	b.ld.Gas += gas
+	return nbs, nil
+}
+
+// runScheduledTxs executes the due scheduled txs popped from the due queues.
+// The reserved gas is refunded to the sender before executing, a failed scheduled tx
+// is recorded with its error and its state changes are discarded, but its gas is
+// still charged from the reserve.
+func (b *Block) runScheduledTxs(vbs BlockState) (BlockState, []*ld.ScheduledResult, uint64, error) {
+	due, err := vbs.PopDueScheduledTxs(ld.MaxScheduledTxsPerBlock)
+	if err != nil {
+		return nil, nil, 0, err
+	}
+	if len(due) == 0 {
+		return vbs, nil, 0, nil
+	}
+
+	gas := uint64(0)
+	results := make([]*ld.ScheduledResult, 0, len(due))
+	for _, ref := range due {
+		sender, err := vbs.LoadAccount(ref.Sender)
+		if err != nil {
+			return nil, nil, 0, err
+		}
+		if err = vbs.LoadLedger(sender); err != nil {
+			return nil, nil, 0, err
+		}
+		entry, err := sender.TakeScheduledTx(ref.ID)
+		if err != nil {
+			return nil, nil, 0, err
+		}
+		if err = sender.Add(ids.NativeToken, entry.Reserve); err != nil {
+			return nil, nil, 0, err
+		}
+
+		rt := &ld.ScheduledResult{ID: ref.ID}
+		results = append(results, rt)
+		tx := &ld.Transaction{}
+		if err = tx.Unmarshal(entry.Tx); err == nil {
+			err = tx.SyntacticVerify()
+		}
+		if err != nil {
+			rt.Err = err.Error()
+			continue
+		}
+
+		nvbs, err := vbs.DeriveState()
+		if err != nil {
+			return nil, nil, 0, err
+		}
+
+		if err = b.applyScheduledTx(nvbs, tx); err == nil {
+			vbs = nvbs
+		} else {
+			rt.Err = err.Error()
+			if err = b.chargeScheduledTx(vbs, sender, tx, entry.Reserve); err != nil {
+				return nil, nil, 0, err
+			}
+		}
+		gas += tx.Gas()
+	}
+	return vbs, results, gas, nil
+}
+
+func (b *Block) applyScheduledTx(vbs BlockState, tx *ld.Transaction) error {
+	tx.Height = b.ld.Height
+	tx.Timestamp = b.ld.Timestamp
+	ntx, err := txn.NewScheduledTx(tx)
+	if err != nil {
+		return err
+	}
+	return ntx.Apply(b, vbs)
+}
+
+// chargeScheduledTx charges the sender of a failed scheduled tx for its gas,
+// at most the reserve. The fee is burned to the LDC account and the tip goes to the builder.
+func (b *Block) chargeScheduledTx(vbs BlockState, sender *acct.Account, tx *ld.Transaction, reserve *big.Int) error {
+	ldc, err := vbs.LoadAccount(ids.LDCAccount)
+	if err != nil {
+		return err
+	}
+	miner, err := vbs.LoadAccount(b.Builder())
+	if err != nil {
+		return err
+	}
+
+	gas := new(big.Int).SetUint64(tx.Gas())
+	fee := new(big.Int).Mul(gas, b.GasPrice())
+	if fee.Cmp(reserve) > 0 {
+		fee.Set(reserve)
+	}
+	tip := new(big.Int).Mul(gas, new(big.Int).SetUint64(tx.Tx.GasTip))
+	if rest := new(big.Int).Sub(reserve, fee); tip.Cmp(rest) > 0 {
+		tip = rest
+	}
+
+	if err = sender.SubGas(new(big.Int).Add(fee, tip)); err != nil {
+		return err
+	}
+	if err = miner.Add(ids.NativeToken, tip); err != nil {
+		return err
+	}
+	return ldc.Add(ids.NativeToken, fee)
+}
+
+func (b *Block) verifyScheduledTxs() (uint64, error) {
+	bs, results, gas, err := b.runScheduledTxs(b.bs)
+	if err != nil {
+		return 0, err
+	}
+
+	if len(results) != len(b.ld.Scheduled) {
+		return 0, fmt.Errorf("invalid scheduled txs, expected %d, got %d",
+			len(results), len(b.ld.Scheduled))
+	}
+	// the error message is informational, only the outcome is verified
+	for i, rt := range results {
+		if s := b.ld.Scheduled[i]; s.ID != rt.ID || (s.Err == "") != (rt.Err == "") {
+			return 0, fmt.Errorf("invalid scheduled tx at %d, expected %s, got %s",
+				i, rt.ID, s.ID)
+		}
+	}
+	b.bs = bs
+	return gas, nil
+}
+
 func (b *Block) SetBuilderFee(vbs BlockState) error {
 	errp := erring.ErrPrefix("chain.Block.SetBuilderFee: ")
 	shares := make([]*acct.Account, 0)
@@ -376,7 +512,15 @@ func (b *Block) verify(ctx context.Context) error {
 		return err
 	}
 
-	gas := uint64(0)
+	if err = b.bs.PurgeExpiredData(); err != nil {
+		return err
+	}
+
+	gas, err := b.verifyScheduledTxs()
+	if err != nil {
+		return err
+	}
+
 	for i := range txs {
 		tx := txs[i]
 		tx.Height = b.ld.Height
@@ -438,6 +582,10 @@ func (b *Block) Accept(ctx context.Context) error {
 		return errp.ErrorIf(err)
 	}
 
+	if p := b.ctx.Chain().Pruner(); p != nil {
+		p.Track(b.ld.Timestamp, b.bs.ChangedData())
+	}
+
 	b.status = choices.Accepted
 	return nil
 }
@@ -21,8 +21,9 @@ import (
 )
 
 const (
-	minTxsWhenBuild = 2
-	waitForMoreTxs  = 1 * time.Second
+	minTxsWhenBuild     = 2
+	waitForMoreTxs      = 1 * time.Second
+	waitForScheduledTxs = 3 * time.Second
 )
 
 // builderStatus denotes the current status of the VM in block production.
@@ -41,6 +42,7 @@ type BlockBuilder struct {
 	ctx             *Context
 	txPool          *TxPool
 	toEngine        chan<- common.Message
+	scheduleTimer   *time.Timer
 }
 
 func NewBlockBuilder(txPool *TxPool, toEngine chan<- common.Message) *BlockBuilder {
@@ -55,15 +57,54 @@ func NewBlockBuilder(txPool *TxPool, toEngine chan<- common.Message) *BlockBuild
 func (b *BlockBuilder) HandlePreferenceBlock(ctx context.Context, height uint64) {
 	size := b.txPool.SizeToBuild(ctx, height+1)
 
+	due := size == 0 && b.hasDueScheduledTxs()
+
 	b.mu.Lock()
 	defer b.mu.Unlock()
-	if size > 0 {
+	if size > 0 || due {
 		b.markBuilding()
 	} else {
 		b.status = dontBuild
+		b.watchScheduledTxs()
 	}
 }
 
+// watchScheduledTxs checks the due scheduled txs periodically when there is no tx
+// to build, so that the scheduled txs will be executed even if the mempool is empty.
+func (b *BlockBuilder) watchScheduledTxs() {
+	if b.scheduleTimer != nil {
+		b.scheduleTimer.Stop()
+	}
+	b.scheduleTimer = time.AfterFunc(waitForScheduledTxs, func() {
+		due := b.hasDueScheduledTxs()
+
+		b.mu.Lock()
+		defer b.mu.Unlock()
+		if b.status != dontBuild {
+			return
+		}
+		if due {
+			b.markBuilding()
+		} else {
+			b.watchScheduledTxs()
+		}
+	})
+}
+
+// hasDueScheduledTxs returns true if there are scheduled txs due at the next block.
+func (b *BlockBuilder) hasDueScheduledTxs() bool {
+	preferred := b.ctx.Chain().PreferredBlock()
+	if preferred == nil || preferred.State() == nil {
+		return false
+	}
+
+	ts := uint64(time.Now().UTC().Unix())
+	if pts := preferred.Timestamp2(); ts < pts {
+		ts = pts
+	}
+	return preferred.State().HasDueScheduledTxs(preferred.Height()+1, ts)
+}
+
 // SignalTxsReady should be called immediately when a new tx incoming
 func (b *BlockBuilder) SignalTxsReady() {
 	b.mu.Lock()
@@ -119,7 +160,9 @@ func (b *BlockBuilder) build(ctx context.Context, builder ids.Address) (*Block,
 		return nil, err
 	}
 
-	if size := txs.Size(); size == 0 || (ts == pts && size < minTxsWhenBuild) {
+	// build a block for the due scheduled txs even if there is no tx
+	scheduled := preferred.State().HasDueScheduledTxs(parentHeight+1, ts)
+	if size := txs.Size(); !scheduled && (size == 0 || (ts == pts && size < minTxsWhenBuild)) {
 		time.AfterFunc(waitForMoreTxs, b.SignalTxsReady)
 		return nil, fmt.Errorf("wait txs to build, expected >= %d, got %d", minTxsWhenBuild, size)
 	}
@@ -142,7 +185,17 @@ func (b *BlockBuilder) build(ctx context.Context, builder ids.Address) (*Block,
 		return nil, err
 	}
 
-	// 1. TryBuildTxs
+	// 0. PurgeExpiredData
+	if err = vbs.PurgeExpiredData(); err != nil {
+		return nil, err
+	}
+
+	// 1. RunScheduledTxs
+	if vbs, err = nblk.RunScheduledTxs(vbs); err != nil {
+		return nil, err
+	}
+
+	// 2. TryBuildTxs
 	var status choices.Status
 	tbs := &txpool.TxsBuildStatus{}
 	processingTxs := make(ld.Txs, 0, txs.Size())
@@ -174,16 +227,16 @@ func (b *BlockBuilder) build(ctx context.Context, builder ids.Address) (*Block,
 	}
 
 	go b.txPool.UpdateBuildStatus(ctx, blk.Height, tbs)
-	if len(blk.Txs) == 0 {
+	if len(blk.Txs) == 0 && len(blk.Scheduled) == 0 {
 		return nil, fmt.Errorf("no txs to build")
 	}
 
-	// 2. SetBuilderFee
+	// 3. SetBuilderFee
 	if err := nblk.SetBuilderFee(vbs); err != nil {
 		return nil, err
 	}
 
-	// 3. BuildState and Verify block
+	// 4. BuildState and Verify block
 	if err := nblk.BuildState(vbs); err != nil {
 		return nil, err
 	}
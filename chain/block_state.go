@@ -4,6 +4,9 @@
 package chain
 
 import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
 	"math/big"
 
 	"github.com/ava-labs/avalanchego/database"
@@ -41,6 +44,8 @@ type blockState struct {
 	prevDataDB        *db.PrefixDB
 	stateDB           *db.PrefixDB
 	nameDB            *db.PrefixDB
+	scheduleDB        *db.PrefixDB
+	expiryDB          *db.PrefixDB
 	accts             acct.ActiveAccounts
 }
 
@@ -50,6 +55,10 @@ type BlockState interface {
 	LoadValidatorAccountByNodeID(avaids.NodeID) (ids.StakeSymbol, *acct.Account)
 	GetBlockIDAtHeight(uint64) (ids.ID32, error)
 	SaveBlock(*ld.Block) error
+	PurgeExpiredData() error
+	PopDueScheduledTxs(limit int) ([]ScheduledTxRef, error)
+	HasDueScheduledTxs(height, timestamp uint64) bool
+	ChangedData() []ids.DataID
 	Commit() error
 	Free()
 
@@ -76,6 +85,8 @@ func newBlockState(ctx *Context, height, timestamp uint64, parentState ids.ID32,
 		prevDataDB:     pdb.With(prevDataDBPrefix),
 		stateDB:        pdb.With(stateDBPrefix),
 		nameDB:         pdb.With(nameDBPrefix),
+		scheduleDB:     pdb.With(scheduleDBPrefix),
+		expiryDB:       pdb.With(expiryDBPrefix),
 		accts:          make(acct.ActiveAccounts, 256),
 	}
 
@@ -119,12 +130,21 @@ func (bs *blockState) DeriveState() (BlockState, error) {
 		prevDataDB:     pdb.With(prevDataDBPrefix),
 		stateDB:        pdb.With(stateDBPrefix),
 		nameDB:         pdb.With(nameDBPrefix),
+		scheduleDB:     pdb.With(scheduleDBPrefix),
+		expiryDB:       pdb.With(expiryDBPrefix),
 		accts:          make(acct.ActiveAccounts, 256),
 	}
 
 	nbs.nameDB.SetHashKey(nameHashKey)
 
 	for _, a := range bs.accts {
+		if a.IsClosed() {
+			if err := nbs.saveClosedAccount(a); err != nil {
+				return nil, err
+			}
+			continue
+		}
+
 		data, ledger, err := a.Marshal()
 		if err == nil {
 			id := a.ID()
@@ -145,6 +165,25 @@ func (bs *blockState) DeriveState() (BlockState, error) {
 	return nbs, nil
 }
 
+// saveClosedAccount deletes the closed account's ledger, and keeps the account
+// as a tombstone with its last nonce. The tombstone is deliberate: the address
+// may be funded again, and its txs signed before closing must not be replayed.
+// Both changes are recorded in the state.
+func (bs *blockState) saveClosedAccount(a *acct.Account) error {
+	id := a.ID()
+	data, _, err := a.Marshal()
+	if err != nil {
+		return err
+	}
+
+	bs.ls.UpdateAccount(id, data)
+	bs.ls.DeleteLedger(id)
+	if err = bs.accountDB.Put(id[:], data); err != nil {
+		return err
+	}
+	return bs.ledgerDB.Delete(id[:])
+}
+
 func (bs *blockState) VersionDB() *versiondb.Database {
 	return bs.vdb
 }
@@ -217,7 +256,7 @@ func (bs *blockState) SaveName(ns *service.Name) error {
 	key := []byte(name)
 	ok, err := bs.nameDB.Has(key)
 	switch {
-	case ok:
+	case ok && !bs.isExpiredName(key):
 		return errp.Errorf("name %q is conflict", name)
 
 	case err != nil:
@@ -228,6 +267,21 @@ func (bs *blockState) SaveName(ns *service.Name) error {
 	}
 }
 
+// isExpiredName returns true if the name is held by an expired data,
+// the name can be taken over before the data is purged.
+func (bs *blockState) isExpiredName(key []byte) bool {
+	data, err := bs.nameDB.Get(key)
+	if err != nil {
+		return false
+	}
+	id, err := ids.ID32FromBytes(data)
+	if err != nil {
+		return false
+	}
+	di, err := bs.LoadData(ids.DataID(id))
+	return err == nil && di.IsExpired(bs.timestamp)
+}
+
 func (bs *blockState) DeleteName(ns *service.Name) error {
 	errp := erring.ErrPrefix("chain.BlockState.DeleteName: ")
 	if ns.DataID == ids.EmptyDataID {
@@ -295,6 +349,12 @@ func (bs *blockState) LoadData(id ids.DataID) (*ld.DataInfo, error) {
 		return nil, errp.ErrorIf(err)
 	}
 	di.ID = id
+	// expired data is treated as deleted until it is purged
+	if di.IsExpired(bs.timestamp) && di.Version > 0 {
+		if err := di.MarkDeleted(nil); err != nil {
+			return nil, errp.ErrorIf(err)
+		}
+	}
 	return di, nil
 }
 
@@ -344,6 +404,320 @@ func (bs *blockState) DeleteData(di *ld.DataInfo, message []byte) error {
 	return nil
 }
 
+// SetDataExpiry moves the data in the expiry index from prevExpiresAt to expiresAt,
+// 0 means the data is not indexed.
+func (bs *blockState) SetDataExpiry(id ids.DataID, prevExpiresAt, expiresAt uint64) error {
+	errp := erring.ErrPrefix("chain.BlockState.SetDataExpiry: ")
+	if prevExpiresAt > 0 {
+		if err := bs.expiryDB.Delete(dataExpiryKey(prevExpiresAt, id)); err != nil {
+			return errp.ErrorIf(err)
+		}
+	}
+	if expiresAt > 0 {
+		if err := bs.expiryDB.Put(dataExpiryKey(expiresAt, id), []byte{}); err != nil {
+			return errp.ErrorIf(err)
+		}
+	}
+	return nil
+}
+
+// PurgeExpiredData removes at most ld.MaxExpiredDataPerBlock expired data with their name index,
+// and at most ld.MaxPurgedVersionsPerBlock previous versions of the purged data. The purged data
+// with remaining versions is kept in the expiry index with a cursor, and its versions are deleted
+// in the next blocks. It should be called at the start of a block.
+func (bs *blockState) PurgeExpiredData() error {
+	errp := erring.ErrPrefix("chain.BlockState.PurgeExpiredData: ")
+	expired, err := bs.popExpiredData(ld.MaxExpiredDataPerBlock)
+	if err != nil {
+		return errp.ErrorIf(err)
+	}
+
+	budget := ld.MaxPurgedVersionsPerBlock
+	for _, e := range expired {
+		cursor := e.Cursor
+		if cursor == 0 {
+			if cursor, err = bs.purgeData(e.ID); err != nil {
+				return errp.Errorf("purge %s: %v", e.ID, err)
+			}
+		}
+
+		for ; cursor > 0 && budget > 0; cursor-- {
+			if err = bs.prevDataDB.Delete(e.ID.VersionKey(cursor)); err != nil {
+				return errp.Errorf("purge %s: %v", e.ID, err)
+			}
+			budget--
+		}
+
+		if cursor > 0 {
+			key := dataExpiryKey(e.ExpiresAt, e.ID)
+			if err = bs.expiryDB.Put(key, database.PackUInt64(cursor)); err != nil {
+				return errp.Errorf("purge %s: %v", e.ID, err)
+			}
+		}
+	}
+	return nil
+}
+
+// expiredData is an entry of the expiry index. Cursor is 0 if the data should be purged,
+// otherwise the data has been purged and its previous versions <= Cursor remain to be deleted.
+type expiredData struct {
+	ExpiresAt uint64
+	ID        ids.DataID
+	Cursor    uint64
+}
+
+// popExpiredData removes at most limit expired data from the expiry index and returns them
+// in the order of expiresAt and id.
+func (bs *blockState) popExpiredData(limit int) ([]expiredData, error) {
+	rt := make([]expiredData, 0)
+	keys := make([][]byte, 0)
+	var verr error
+	err := bs.iterateExpiryIndex(func(key, value []byte) bool {
+		e := expiredData{ExpiresAt: binary.BigEndian.Uint64(key[:8])}
+		copy(e.ID[:], key[8:])
+		switch len(value) {
+		case 0:
+		case 8:
+			e.Cursor = binary.BigEndian.Uint64(value)
+		default:
+			verr = fmt.Errorf("invalid expiry index cursor %x", value)
+			return false
+		}
+
+		rt = append(rt, e)
+		keys = append(keys, key)
+		return len(rt) < limit
+	})
+	switch {
+	case err != nil:
+		return nil, err
+	case verr != nil:
+		return nil, verr
+	}
+
+	for _, key := range keys {
+		if err = bs.expiryDB.Delete(key); err != nil {
+			return nil, err
+		}
+	}
+	return rt, nil
+}
+
+// iterateExpiryIndex calls fn with the copied keys and values of the expired data in the expiry index,
+// until fn returns false. The index should not be changed in fn.
+func (bs *blockState) iterateExpiryIndex(fn func(key, value []byte) bool) error {
+	it := bs.expiryDB.NewIterator(nil)
+	defer it.Release()
+
+	for it.Next() {
+		key := it.Key()
+		if len(key) != 8+len(ids.DataID{}) {
+			return fmt.Errorf("invalid expiry index entry %x", key)
+		}
+		if binary.BigEndian.Uint64(key[:8]) > bs.timestamp {
+			break
+		}
+		if !fn(append([]byte{}, key...), append([]byte{}, it.Value()...)) {
+			break
+		}
+	}
+	return it.Error()
+}
+
+func dataExpiryKey(expiresAt uint64, id ids.DataID) []byte {
+	key := make([]byte, 0, 8+len(id))
+	key = append(key, database.PackUInt64(expiresAt)...)
+	return append(key, id[:]...)
+}
+
+// purgeData removes the expired data and releases its name, the deletion is recorded in the state.
+// It returns the number of previous versions that remain to be deleted.
+func (bs *blockState) purgeData(id ids.DataID) (uint64, error) {
+	data, err := bs.dataDB.Get(id[:])
+	switch {
+	case err == database.ErrNotFound:
+		return 0, nil
+	case err != nil:
+		return 0, err
+	}
+
+	di := &ld.DataInfo{}
+	if err = di.Unmarshal(data); err != nil {
+		return 0, err
+	}
+	if !di.IsExpired(bs.timestamp) {
+		return 0, nil
+	}
+
+	// release the name only if it is still held by the data
+	if di.Version > 0 && bs.ctx.ChainConfig().IsNameService(di.ModelID) {
+		ns := &service.Name{}
+		if err = ns.Unmarshal(di.Payload); err != nil {
+			return 0, err
+		}
+		if err = ns.SyntacticVerify(); err != nil {
+			return 0, err
+		}
+		key := []byte(ns.ASCII())
+		if v, err := bs.nameDB.Get(key); err == nil && bytes.Equal(v, id[:]) {
+			if err = bs.nameDB.Delete(key); err != nil {
+				return 0, err
+			}
+		}
+	}
+
+	bs.ls.DeleteData(id)
+	return di.Version, bs.dataDB.Delete(id[:])
+}
+
+// ScheduledTxRef refers to a due scheduled tx held on the sender's ledger.
+type ScheduledTxRef struct {
+	ID     ids.ID32
+	Sender ids.Address
+}
+
+const (
+	scheduleHeightQueue = 'h' // 'h' + height + id => sender + timestamp
+	scheduleTimeQueue   = 't' // 't' + timestamp + id => sender
+)
+
+// QueueScheduledTx indexes the scheduled tx held on the sender's ledger in the due queues.
+// A scheduled tx with height is queued by height, and moved to the time queue
+// if its timestamp has not been reached when its height is reached.
+func (bs *blockState) QueueScheduledTx(id ids.ID32, sender ids.Address, height, timestamp uint64) error {
+	errp := erring.ErrPrefix("chain.BlockState.QueueScheduledTx: ")
+	if height == 0 && timestamp == 0 {
+		return errp.Errorf("invalid schedule")
+	}
+
+	if height == 0 {
+		return errp.ErrorIf(bs.scheduleDB.Put(scheduleQueueKey(scheduleTimeQueue, timestamp, id), sender[:]))
+	}
+	value := append(sender[:], database.PackUInt64(timestamp)...)
+	return errp.ErrorIf(bs.scheduleDB.Put(scheduleQueueKey(scheduleHeightQueue, height, id), value))
+}
+
+// PopDueScheduledTxs removes at most limit due scheduled txs from the due queues and returns them,
+// the height queue in the order of height and id first, then the time queue in the order of
+// timestamp and id. It scans a bounded number of entries so that the cost of a block is bounded.
+func (bs *blockState) PopDueScheduledTxs(limit int) ([]ScheduledTxRef, error) {
+	errp := erring.ErrPrefix("chain.BlockState.PopDueScheduledTxs: ")
+
+	rt := make([]ScheduledTxRef, 0)
+	popped := make([][]byte, 0)
+	moved := make([][]byte, 0)
+	scanned := 0
+	err := bs.iterateScheduleQueue(scheduleHeightQueue, bs.height, func(key, value []byte) bool {
+		scanned++
+		ref := scheduledTxRef(key, value)
+		if ts := binary.BigEndian.Uint64(value[20:]); ts > bs.timestamp {
+			popped = append(popped, key)
+			moved = append(moved, scheduleQueueKey(scheduleTimeQueue, ts, ref.ID), ref.Sender[:])
+		} else {
+			popped = append(popped, key)
+			rt = append(rt, ref)
+		}
+		return len(rt) < limit && scanned < 4*limit
+	})
+	if err != nil {
+		return nil, errp.ErrorIf(err)
+	}
+
+	if len(rt) < limit {
+		err = bs.iterateScheduleQueue(scheduleTimeQueue, bs.timestamp, func(key, value []byte) bool {
+			popped = append(popped, key)
+			rt = append(rt, scheduledTxRef(key, value))
+			return len(rt) < limit
+		})
+		if err != nil {
+			return nil, errp.ErrorIf(err)
+		}
+	}
+
+	for _, key := range popped {
+		if err = bs.scheduleDB.Delete(key); err != nil {
+			return nil, errp.ErrorIf(err)
+		}
+	}
+	for i := 0; i < len(moved); i += 2 {
+		if err = bs.scheduleDB.Put(moved[i], moved[i+1]); err != nil {
+			return nil, errp.ErrorIf(err)
+		}
+	}
+	return rt, nil
+}
+
+// HasDueScheduledTxs returns true if there are scheduled txs due at the given height and timestamp.
+func (bs *blockState) HasDueScheduledTxs(height, timestamp uint64) bool {
+	due := false
+	scanned := 0
+	err := bs.iterateScheduleQueue(scheduleHeightQueue, height, func(key, value []byte) bool {
+		scanned++
+		due = binary.BigEndian.Uint64(value[20:]) <= timestamp
+		return !due && scanned < 4*ld.MaxScheduledTxsPerBlock
+	})
+	if err == nil && !due {
+		err = bs.iterateScheduleQueue(scheduleTimeQueue, timestamp, func(key, value []byte) bool {
+			due = true
+			return false
+		})
+	}
+	return err == nil && due
+}
+
+// iterateScheduleQueue calls fn with the copied entries of the queue up to the due point,
+// until fn returns false. The queue should not be changed in fn.
+func (bs *blockState) iterateScheduleQueue(queue byte, due uint64, fn func(key, value []byte) bool) error {
+	it := bs.scheduleDB.NewIterator([]byte{queue})
+	defer it.Release()
+
+	valueLen := 20
+	if queue == scheduleHeightQueue {
+		valueLen += 8
+	}
+	for it.Next() {
+		key := it.Key()
+		if len(key) == 0 || key[0] != queue {
+			break
+		}
+		if len(key) != 41 || len(it.Value()) != valueLen {
+			return fmt.Errorf("invalid schedule queue entry %x", key)
+		}
+		if binary.BigEndian.Uint64(key[1:9]) > due {
+			break
+		}
+		if !fn(append([]byte{}, key...), append([]byte{}, it.Value()...)) {
+			break
+		}
+	}
+	return it.Error()
+}
+
+func scheduledTxRef(key, value []byte) ScheduledTxRef {
+	ref := ScheduledTxRef{}
+	copy(ref.ID[:], key[9:])
+	copy(ref.Sender[:], value)
+	return ref
+}
+
+func scheduleQueueKey(queue byte, due uint64, id ids.ID32) []byte {
+	key := make([]byte, 0, 41)
+	key = append(key, queue)
+	key = append(key, database.PackUInt64(due)...)
+	return append(key, id[:]...)
+}
+
+// ChangedData returns the ids of data changed in the block.
+func (bs *blockState) ChangedData() []ids.DataID {
+	rt := make([]ids.DataID, 0, len(bs.ls.Datas))
+	for k := range bs.ls.Datas {
+		var id ids.DataID
+		copy(id[:], k)
+		rt = append(rt, id)
+	}
+	return rt
+}
+
 func (bs *blockState) GetBlockIDAtHeight(height uint64) (ids.ID32, error) {
 	errp := erring.ErrPrefix("chain.BlockState.GetBlockIDAtHeight: ")
 	data, err := bs.heightDB.Get(database.PackUInt64(height))
@@ -356,6 +730,13 @@ func (bs *blockState) GetBlockIDAtHeight(height uint64) (ids.ID32, error) {
 func (bs *blockState) SaveBlock(blk *ld.Block) error {
 	errp := erring.ErrPrefix("chain.BlockState.SaveBlock: ")
 	for _, a := range bs.accts {
+		if a.IsClosed() {
+			if err := bs.saveClosedAccount(a); err != nil {
+				return errp.ErrorIf(err)
+			}
+			continue
+		}
+
 		data, ledger, err := a.Marshal()
 		if err == nil {
 			id := a.ID()
@@ -0,0 +1,181 @@
+// (c) 2022-2022, LDC Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package chain
+
+import (
+	"testing"
+
+	"github.com/ava-labs/avalanchego/database"
+	"github.com/ava-labs/avalanchego/database/memdb"
+	"github.com/fxamacker/cbor/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ldclabs/ldvm/db"
+	"github.com/ldclabs/ldvm/genesis"
+	"github.com/ldclabs/ldvm/ids"
+	"github.com/ldclabs/ldvm/ld"
+)
+
+func TestBlockStateScheduleQueue(t *testing.T) {
+	assert := assert.New(t)
+
+	pdb := db.NewPrefixDB(memdb.New(), dbPrefix, 512)
+	bs := &blockState{height: 10, timestamp: 1000, scheduleDB: pdb.With(scheduleDBPrefix)}
+	sender := ids.Address{1}
+
+	assert.ErrorContains(bs.QueueScheduledTx(ids.ID32{1}, sender, 0, 0), "invalid schedule")
+	assert.False(bs.HasDueScheduledTxs(10, 1000))
+	rt, err := bs.PopDueScheduledTxs(10)
+	require.NoError(t, err)
+	assert.Empty(rt)
+
+	assert.NoError(bs.QueueScheduledTx(ids.ID32{1}, sender, 11, 0))
+	assert.NoError(bs.QueueScheduledTx(ids.ID32{2}, sender, 10, 1001))
+	assert.NoError(bs.QueueScheduledTx(ids.ID32{3}, sender, 0, 1000))
+	assert.NoError(bs.QueueScheduledTx(ids.ID32{4}, ids.Address{2}, 9, 0))
+	assert.NoError(bs.QueueScheduledTx(ids.ID32{5}, sender, 0, 999))
+	assert.NoError(bs.QueueScheduledTx(ids.ID32{6}, sender, 0, 1001))
+	assert.True(bs.HasDueScheduledTxs(10, 1000))
+	assert.True(bs.HasDueScheduledTxs(9, 999))
+	assert.False(bs.HasDueScheduledTxs(8, 998))
+
+	rt, err = bs.PopDueScheduledTxs(2)
+	require.NoError(t, err)
+	assert.Equal([]ScheduledTxRef{
+		{ID: ids.ID32{4}, Sender: ids.Address{2}},
+		{ID: ids.ID32{5}, Sender: sender},
+	}, rt)
+
+	// {2} is moved to the time queue because its timestamp is not reached
+	rt, err = bs.PopDueScheduledTxs(10)
+	require.NoError(t, err)
+	assert.Equal([]ScheduledTxRef{{ID: ids.ID32{3}, Sender: sender}}, rt)
+	assert.False(bs.HasDueScheduledTxs(10, 1000))
+
+	bs.height, bs.timestamp = 11, 1001
+	assert.True(bs.HasDueScheduledTxs(11, 1001))
+	rt, err = bs.PopDueScheduledTxs(10)
+	require.NoError(t, err)
+	assert.Equal([]ScheduledTxRef{
+		{ID: ids.ID32{1}, Sender: sender},
+		{ID: ids.ID32{2}, Sender: sender},
+		{ID: ids.ID32{6}, Sender: sender},
+	}, rt)
+	assert.False(bs.HasDueScheduledTxs(100, 10000))
+}
+
+func TestBlockStateDataExpiry(t *testing.T) {
+	assert := assert.New(t)
+
+	pdb := db.NewPrefixDB(memdb.New(), dbPrefix, 512)
+	bs := &blockState{height: 10, timestamp: 1000, expiryDB: pdb.With(expiryDBPrefix)}
+
+	rt, err := bs.popExpiredData(10)
+	require.NoError(t, err)
+	assert.Empty(rt)
+
+	assert.NoError(bs.SetDataExpiry(ids.DataID{1}, 0, 1001))
+	assert.NoError(bs.SetDataExpiry(ids.DataID{2}, 0, 1000))
+	assert.NoError(bs.SetDataExpiry(ids.DataID{3}, 0, 999))
+	assert.NoError(bs.SetDataExpiry(ids.DataID{4}, 0, 1000))
+	assert.NoError(bs.SetDataExpiry(ids.DataID{5}, 0, 500))
+	// updated or cleared expiry is moved out of the index
+	assert.NoError(bs.SetDataExpiry(ids.DataID{5}, 500, 2000))
+	assert.NoError(bs.SetDataExpiry(ids.DataID{4}, 1000, 0))
+
+	rt, err = bs.popExpiredData(2)
+	require.NoError(t, err)
+	assert.Equal([]expiredData{{ExpiresAt: 999, ID: ids.DataID{3}}, {ExpiresAt: 1000, ID: ids.DataID{2}}}, rt)
+	rt, err = bs.popExpiredData(10)
+	require.NoError(t, err)
+	assert.Empty(rt)
+
+	bs.timestamp = 2000
+	rt, err = bs.popExpiredData(10)
+	require.NoError(t, err)
+	assert.Equal([]expiredData{{ExpiresAt: 1001, ID: ids.DataID{1}}, {ExpiresAt: 2000, ID: ids.DataID{5}}}, rt)
+	rt, err = bs.popExpiredData(10)
+	require.NoError(t, err)
+	assert.Empty(rt)
+
+	// the purged data with remaining versions is indexed with a cursor
+	require.NoError(t, bs.expiryDB.Put(dataExpiryKey(1500, ids.DataID{6}), database.PackUInt64(10)))
+	rt, err = bs.popExpiredData(10)
+	require.NoError(t, err)
+	assert.Equal([]expiredData{{ExpiresAt: 1500, ID: ids.DataID{6}, Cursor: 10}}, rt)
+
+	require.NoError(t, bs.expiryDB.Put(dataExpiryKey(1500, ids.DataID{6}), []byte{1}))
+	_, err = bs.popExpiredData(10)
+	assert.ErrorContains(err, "invalid expiry index cursor 01")
+}
+
+func TestBlockStatePurgeExpiredData(t *testing.T) {
+	assert := assert.New(t)
+
+	pdb := db.NewPrefixDB(memdb.New(), dbPrefix, 512)
+	bs := &blockState{
+		ctx:        &Context{genesis: &genesis.Genesis{}},
+		height:     10,
+		timestamp:  1000,
+		ls:         ld.NewState(ids.EmptyID32),
+		dataDB:     pdb.With(dataDBPrefix),
+		prevDataDB: pdb.With(prevDataDBPrefix),
+		nameDB:     pdb.With(nameDBPrefix),
+		expiryDB:   pdb.With(expiryDBPrefix),
+	}
+
+	versions := uint64(ld.MaxPurgedVersionsPerBlock + 100)
+	id1, id2 := ids.DataID{1}, ids.DataID{2}
+	for _, id := range []ids.DataID{id1, id2} {
+		di := &ld.DataInfo{ModelID: ld.JSONModelID, Version: versions, ExpiresAt: 1000}
+		require.NoError(t, bs.dataDB.Put(id[:], di.Bytes()))
+		for v := uint64(1); v <= versions; v++ {
+			require.NoError(t, bs.prevDataDB.Put(id.VersionKey(v), []byte{1}))
+		}
+		require.NoError(t, bs.SetDataExpiry(id, 0, 1000))
+	}
+	hasVersion := func(id ids.DataID, v uint64) bool {
+		ok, err := bs.prevDataDB.Has(id.VersionKey(v))
+		require.NoError(t, err)
+		return ok
+	}
+
+	assert.NoError(bs.PurgeExpiredData())
+	for _, id := range []ids.DataID{id1, id2} {
+		ok, err := bs.dataDB.Has(id[:])
+		require.NoError(t, err)
+		assert.False(ok)
+		assert.Equal(ids.EmptyID32, bs.ls.Datas[cbor.ByteString(id.Bytes())])
+	}
+	// the versions deleted in a block are bounded
+	assert.False(hasVersion(id1, 101))
+	assert.True(hasVersion(id1, 100))
+	assert.True(hasVersion(id2, 1))
+	assert.True(hasVersion(id2, versions))
+
+	rt, err := bs.popExpiredData(10)
+	require.NoError(t, err)
+	assert.Equal([]expiredData{
+		{ExpiresAt: 1000, ID: id1, Cursor: 100},
+		{ExpiresAt: 1000, ID: id2, Cursor: versions},
+	}, rt)
+	for _, e := range rt {
+		require.NoError(t, bs.expiryDB.Put(dataExpiryKey(e.ExpiresAt, e.ID), database.PackUInt64(e.Cursor)))
+	}
+
+	// the remaining versions are deleted in the next blocks
+	bs.height, bs.timestamp = 11, 1001
+	assert.NoError(bs.PurgeExpiredData())
+	assert.False(hasVersion(id1, 1))
+	assert.False(hasVersion(id2, 201))
+	assert.True(hasVersion(id2, 200))
+
+	bs.height, bs.timestamp = 12, 1002
+	assert.NoError(bs.PurgeExpiredData())
+	assert.False(hasVersion(id2, 1))
+	rt, err = bs.popExpiredData(10)
+	require.NoError(t, err)
+	assert.Empty(rt)
+}
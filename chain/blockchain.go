@@ -42,6 +42,9 @@ var (
 	prevDataDBPrefix     = []byte{'P'}
 	stateDBPrefix        = []byte{'S'}
 	nameDBPrefix         = []byte{'N'} // inverted index
+	scheduleDBPrefix     = []byte{'Q'} // scheduled txs due queue
+	expiryDBPrefix       = []byte{'E'} // data expiry index
+	prunedDBPrefix       = []byte{'R'} // node local pruning records
 
 	lastAcceptedKey = []byte("last_accepted_key")
 )
@@ -56,6 +59,7 @@ type BlockChain interface {
 	// global state
 	HealthCheck(context.Context) (any, error)
 	Bootstrap(context.Context) error
+	Close()
 	State() snow.State
 	SetState(context.Context, snow.State) error
 	TotalSupply(context.Context) *big.Int
@@ -83,6 +87,8 @@ type BlockChain interface {
 	LoadModel(context.Context, ids.ModelID) (*ld.ModelInfo, error)
 	LoadData(context.Context, ids.DataID) (*ld.DataInfo, error)
 	LoadPrevData(context.Context, ids.DataID, uint64) (*ld.DataInfo, error)
+	PrunedVersion(context.Context, ids.DataID) uint64
+	Pruner() *Pruner
 	LoadRawData(context.Context, string, []byte) ([]byte, error)
 }
 
@@ -117,6 +123,7 @@ type blockChain struct {
 	rpcTimeout time.Duration
 	bb         *BlockBuilder
 	txPool     *TxPool
+	pruner     *Pruner
 }
 
 func NewChain(
@@ -172,17 +179,34 @@ func NewChain(
 	s.recentData = db.NewCacher(1_000, 60*10, func() db.Objecter {
 		return new(ld.DataInfo)
 	})
+
+	if cfg.Pruning != nil {
+		// the fee configs' history is required by Bootstrap
+		s.pruner = NewPruner(cfg.Pruning, pdb, gs.Chain.FeeConfigID)
+		go s.pruner.Run()
+	}
 	return s
 }
 
 func (bc *blockChain) Info() map[string]any {
-	return map[string]any{
+	info := map[string]any{
 		"networkId": bc.ctx.NetworkID,
 		"subnetId":  bc.ctx.SubnetID.String(),
 		"nodeId":    bc.ctx.NodeID.String(),
 		"builderId": bc.ctx.Builder(),
 		"state":     bc.State().String(),
 	}
+	if bc.pruner != nil {
+		info["pruning"] = bc.pruner.Info()
+	}
+	return info
+}
+
+// Close stops the background jobs of the chain, it should be called before the database is closed.
+func (bc *blockChain) Close() {
+	if bc.pruner != nil {
+		bc.pruner.Close()
+	}
 }
 
 func (bc *blockChain) DB() database.Database {
@@ -312,11 +336,10 @@ func (bc *blockChain) HealthCheck(ctx context.Context) (any, error) {
 }
 
 func (bc *blockChain) TotalSupply(ctx context.Context) *big.Int {
-	t := new(big.Int)
 	if acc, err := bc.LoadAccount(ctx, ids.LDCAccount); err == nil {
-		t.Sub(acc.MaxTotalSupply, acc.Balance)
+		return acc.TotalSupply()
 	}
-	return t
+	return new(big.Int)
 }
 
 func (bc *blockChain) SetState(ctx context.Context, state snow.State) error {
@@ -703,6 +726,22 @@ func (bc *blockChain) LoadPrevData(ctx context.Context, id ids.DataID, version u
 		return nil, errp.Errorf("invalid version %d", version)
 	}
 
+	// previous versions are unavailable once the data is deleted or expired
+	di, err := bc.LastAcceptedBlock(ctx).State().LoadData(id)
+	switch {
+	case err != nil:
+		return nil, errp.ErrorIf(err)
+	case di.Version == 0:
+		return nil, errp.Errorf("data %s is deleted", id)
+	case version >= di.Version:
+		return nil, errp.Errorf("invalid version %d, current version is %d", version, di.Version)
+	}
+
+	if pruned := bc.PrunedVersion(ctx, id); version <= pruned {
+		return nil, errp.Errorf("version %d of %s has been pruned, versions <= %d are pruned",
+			version, id, pruned)
+	}
+
 	obj, err := bc.prevDataDB.LoadObject(id.VersionKey(version), bc.recentData)
 	if err != nil {
 		return nil, errp.ErrorIf(err)
@@ -712,6 +751,20 @@ func (bc *blockChain) LoadPrevData(ctx context.Context, id ids.DataID, version u
 	return rt, nil
 }
 
+// PrunedVersion returns the highest pruned version of the data by the node's pruner,
+// 0 means no version has been pruned.
+func (bc *blockChain) PrunedVersion(ctx context.Context, id ids.DataID) uint64 {
+	if bc.pruner == nil {
+		return 0
+	}
+	return bc.pruner.PrunedVersion(id)
+}
+
+// Pruner returns the node's pruner, nil if pruning is disabled.
+func (bc *blockChain) Pruner() *Pruner {
+	return bc.pruner
+}
+
 func (bc *blockChain) LoadRawData(ctx context.Context, rawType string, key []byte) ([]byte, error) {
 	errp := erring.ErrPrefix("chain.BlockChain.LoadRawData: ")
 	var pdb *db.PrefixDB
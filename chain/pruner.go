@@ -0,0 +1,420 @@
+// (c) 2022-2022, LDC Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package chain
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/ava-labs/avalanchego/database"
+	"go.uber.org/zap"
+
+	"github.com/ldclabs/ldvm/config"
+	"github.com/ldclabs/ldvm/db"
+	"github.com/ldclabs/ldvm/ids"
+	"github.com/ldclabs/ldvm/ld"
+	"github.com/ldclabs/ldvm/logging"
+	"github.com/ldclabs/ldvm/util/encoding"
+)
+
+const (
+	// maxPrunedVersionsPerRun is the maximum number of versions of a data pruned in a run,
+	// the rest will be pruned in the next runs.
+	maxPrunedVersionsPerRun = 10000
+	// maxRecentPrunedRanges is the maximum number of recent pruned ranges to report.
+	maxRecentPrunedRanges = 100
+)
+
+// PrunedRange is a range of historical versions of a data that have been pruned.
+type PrunedRange struct {
+	ID        ids.DataID `json:"id"`
+	From      uint64     `json:"from"`
+	To        uint64     `json:"to"`
+	Timestamp uint64     `json:"timestamp"`
+}
+
+// prunedRecord is the pruning progress of a data, it is node local.
+type prunedRecord struct {
+	// versions <= Pruned have been pruned
+	Pruned uint64 `cbor:"p"`
+	// the data's version when it was tracked last time
+	Latest uint64 `cbor:"l"`
+	// tracked previous versions that have not been pruned, older versions
+	// that were not tracked are treated as superseded at 0.
+	Versions []prevVersion `cbor:"v"`
+}
+
+type prevVersion struct {
+	_         struct{} `cbor:",toarray"`
+	Version   uint64
+	Timestamp uint64 // the timestamp of the block that superseded the version
+}
+
+// Pruner prunes the historical data versions from prevDataDB in the background
+// by the node's retention policies. Pruning is node local, it doesn't change the chain state.
+type Pruner struct {
+	cfg        *config.Pruning
+	protected  map[ids.DataID]struct{}
+	dataDB     *db.PrefixDB
+	prevDataDB *db.PrefixDB
+	prunedDB   *db.PrefixDB
+	closing    chan struct{}
+	closeOnce  sync.Once
+	running    sync.Mutex // serializes the pruning runs
+
+	// mu guards the fields below and the pruned records, it is never held
+	// while deleting versions so that Track is not blocked by pruning.
+	mu       sync.Mutex
+	now      uint64 // the timestamp of the last tracked block
+	seq      uint64 // the sequence of Track calls
+	pending  map[ids.DataID]uint64
+	ranges   []PrunedRange
+	versions uint64
+}
+
+// NewPruner creates a Pruner, the protected data will never be pruned.
+func NewPruner(cfg *config.Pruning, pdb *db.PrefixDB, protected ...ids.DataID) *Pruner {
+	p := &Pruner{
+		cfg:        cfg,
+		protected:  make(map[ids.DataID]struct{}, len(protected)),
+		dataDB:     pdb.With(dataDBPrefix),
+		prevDataDB: pdb.With(prevDataDBPrefix),
+		prunedDB:   pdb.With(prunedDBPrefix),
+		closing:    make(chan struct{}),
+		pending:    make(map[ids.DataID]uint64),
+		ranges:     make([]PrunedRange, 0, maxRecentPrunedRanges),
+	}
+	for _, id := range protected {
+		p.protected[id] = struct{}{}
+	}
+	if err := p.loadPending(); err != nil {
+		logging.Log.Warn("NewPruner", zap.Error(err))
+	}
+	return p
+}
+
+// Run prunes the tracked data every cfg.Interval seconds until the Pruner
+// or the database is closed.
+func (p *Pruner) Run() {
+	ticker := time.NewTicker(time.Duration(p.cfg.Interval) * time.Second)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-p.closing:
+			return
+		case <-ticker.C:
+			if _, err := p.Prune(); err == database.ErrClosed {
+				return
+			}
+		}
+	}
+}
+
+// Close stops the Pruner and waits for the running pruning to finish,
+// it should be called before the database is closed.
+func (p *Pruner) Close() {
+	p.closeOnce.Do(func() {
+		close(p.closing)
+	})
+	p.running.Lock()
+	defer p.running.Unlock()
+}
+
+// Track records the data versions superseded by an accepted block.
+func (p *Pruner) Track(timestamp uint64, dataIDs []ids.DataID) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.now = timestamp
+	p.seq++
+	for _, id := range dataIDs {
+		if err := p.track(timestamp, id); err != nil {
+			logging.Log.Warn("Pruner.Track",
+				zap.Stringer("id", id),
+				zap.Error(err))
+		}
+	}
+}
+
+// Prune prunes the tracked data by the retention policies,
+// returns the number of pruned versions.
+func (p *Pruner) Prune() (uint64, error) {
+	p.running.Lock()
+	defer p.running.Unlock()
+
+	p.mu.Lock()
+	pending := make(map[ids.DataID]uint64, len(p.pending))
+	for id, seq := range p.pending {
+		pending[id] = seq
+	}
+	p.mu.Unlock()
+
+	total := uint64(0)
+	for id, seq := range pending {
+		select {
+		case <-p.closing:
+			return total, nil
+		default:
+		}
+
+		n, done, err := p.prune(id)
+		if err != nil {
+			logging.Log.Warn("Pruner.Prune",
+				zap.Stringer("id", id),
+				zap.Error(err))
+			if err == database.ErrClosed {
+				return total, err
+			}
+			continue
+		}
+		total += n
+		if done {
+			p.mu.Lock()
+			// the data may be tracked again while pruning
+			if p.pending[id] == seq {
+				delete(p.pending, id)
+			}
+			p.mu.Unlock()
+		}
+	}
+	return total, nil
+}
+
+// PrunedVersion returns the highest pruned version of the data,
+// versions <= it have been pruned.
+func (p *Pruner) PrunedVersion(id ids.DataID) uint64 {
+	rec, err := p.loadRecord(id)
+	if err != nil {
+		return 0
+	}
+	return rec.Pruned
+}
+
+// Info returns the pruned versions and the recent pruned ranges.
+func (p *Pruner) Info() map[string]any {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	ranges := make([]PrunedRange, len(p.ranges))
+	copy(ranges, p.ranges)
+	return map[string]any{
+		"prunedVersions": p.versions,
+		"pendingData":    len(p.pending),
+		"recentRanges":   ranges,
+	}
+}
+
+func (p *Pruner) track(timestamp uint64, id ids.DataID) error {
+	if _, ok := p.protected[id]; ok {
+		return nil
+	}
+
+	di, err := p.loadData(id)
+	switch {
+	case err == database.ErrNotFound:
+		return p.prunedDB.Delete(id[:])
+	case err != nil:
+		return err
+	case di.Version == 0:
+		// the previous versions were removed when deleted
+		return p.prunedDB.Delete(id[:])
+	case p.cfg.Policy(di.ModelID) == nil:
+		return nil
+	}
+
+	rec, err := p.loadRecord(id)
+	if err != nil {
+		return err
+	}
+
+	from := rec.Latest
+	if from == 0 {
+		from = di.Version - 1
+	}
+	if from <= rec.Pruned {
+		from = rec.Pruned + 1
+	}
+	for v := from; v > 0 && v < di.Version; v++ {
+		rec.Versions = append(rec.Versions, prevVersion{Version: v, Timestamp: timestamp})
+	}
+	rec.Latest = di.Version
+	if err = p.saveRecord(id, rec); err != nil {
+		return err
+	}
+	if di.Version > rec.Pruned+1 {
+		p.pending[id] = p.seq
+	}
+	return nil
+}
+
+// loadPending rebuilds the pending data from the pruned records,
+// the data that may have unpruned versions will be checked again.
+func (p *Pruner) loadPending() error {
+	it := p.prunedDB.NewIterator(nil)
+	defer it.Release()
+
+	for it.Next() {
+		var id ids.DataID
+		if len(it.Key()) != len(id) {
+			return fmt.Errorf("invalid pruned record %x", it.Key())
+		}
+		copy(id[:], it.Key())
+
+		rec := &prunedRecord{}
+		if err := encoding.UnmarshalCBOR(it.Value(), rec); err != nil {
+			return fmt.Errorf("invalid pruned record %s, %v", id, err)
+		}
+		if rec.Latest > rec.Pruned+1 {
+			p.pending[id] = 0
+		}
+	}
+	return it.Error()
+}
+
+// prune prunes the previous versions of the data that are not retained,
+// done is true if no more version will be pruned until the data is updated again.
+// The versions are deleted without holding p.mu, the record is updated after that.
+func (p *Pruner) prune(id ids.DataID) (n uint64, done bool, err error) {
+	di, err := p.loadData(id)
+	switch {
+	case err == database.ErrNotFound:
+		return 0, true, p.deleteRecord(id)
+	case err != nil:
+		return 0, false, err
+	case di.Version == 0:
+		return 0, true, p.deleteRecord(id)
+	}
+
+	rp := p.cfg.Policy(di.ModelID)
+	if rp == nil {
+		return 0, true, nil
+	}
+
+	p.mu.Lock()
+	now := p.now
+	rec, err := p.loadRecord(id)
+	p.mu.Unlock()
+	if err != nil {
+		return 0, false, err
+	}
+
+	target := prunableVersion(rp, now, di.Version, rec)
+	upTo := target
+	if upTo > rec.Pruned+maxPrunedVersionsPerRun {
+		upTo = rec.Pruned + maxPrunedVersionsPerRun
+	}
+	// the versions retained only by KeepSeconds will become prunable later
+	byVersions := prunableVersion(&config.RetentionPolicy{KeepVersions: rp.KeepVersions}, now, di.Version, rec)
+	done = upTo == target && target >= byVersions
+
+	if upTo <= rec.Pruned {
+		return 0, done, nil
+	}
+
+	for v := rec.Pruned + 1; v <= upTo; v++ {
+		if err = p.prevDataDB.Delete(id.VersionKey(v)); err != nil {
+			return 0, false, err
+		}
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	// reload the record, it may be updated by Track while deleting
+	if rec, err = p.loadRecord(id); err != nil {
+		return 0, false, err
+	}
+	if upTo <= rec.Pruned {
+		return 0, done, nil
+	}
+
+	pr := PrunedRange{ID: id, From: rec.Pruned + 1, To: upTo, Timestamp: now}
+	n = upTo - rec.Pruned
+	rec.Pruned = upTo
+	i := 0
+	for i < len(rec.Versions) && rec.Versions[i].Version <= upTo {
+		i++
+	}
+	rec.Versions = rec.Versions[i:]
+	if err = p.saveRecord(id, rec); err != nil {
+		return 0, false, err
+	}
+
+	p.versions += n
+	if len(p.ranges) == maxRecentPrunedRanges {
+		copy(p.ranges, p.ranges[1:])
+		p.ranges = p.ranges[:len(p.ranges)-1]
+	}
+	p.ranges = append(p.ranges, pr)
+	logging.Log.Info("Pruner.Prune",
+		zap.Stringer("id", id),
+		zap.Uint64("from", pr.From),
+		zap.Uint64("to", pr.To))
+	return n, done, nil
+}
+
+// prunableVersion returns the highest version that is not retained by the policy at now.
+func prunableVersion(rp *config.RetentionPolicy, now, version uint64, rec *prunedRecord) uint64 {
+	upTo := version - 1
+	if rp.KeepVersions > 0 {
+		if rp.KeepVersions >= upTo {
+			return rec.Pruned
+		}
+		upTo -= rp.KeepVersions
+	}
+
+	if rp.KeepSeconds > 0 {
+		for _, pv := range rec.Versions {
+			if pv.Version <= upTo && pv.Timestamp+rp.KeepSeconds > now {
+				upTo = pv.Version - 1
+				break
+			}
+		}
+	}
+
+	if upTo < rec.Pruned {
+		return rec.Pruned
+	}
+	return upTo
+}
+
+func (p *Pruner) loadData(id ids.DataID) (*ld.DataInfo, error) {
+	data, err := p.dataDB.Get(id[:])
+	if err != nil {
+		return nil, err
+	}
+	di := &ld.DataInfo{}
+	if err = di.Unmarshal(data); err != nil {
+		return nil, err
+	}
+	return di, nil
+}
+
+func (p *Pruner) loadRecord(id ids.DataID) (*prunedRecord, error) {
+	rec := &prunedRecord{}
+	data, err := p.prunedDB.Get(id[:])
+	switch {
+	case err == database.ErrNotFound:
+		return rec, nil
+	case err != nil:
+		return nil, err
+	}
+	return rec, encoding.UnmarshalCBOR(data, rec)
+}
+
+func (p *Pruner) deleteRecord(id ids.DataID) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.prunedDB.Delete(id[:])
+}
+
+func (p *Pruner) saveRecord(id ids.DataID, rec *prunedRecord) error {
+	data, err := encoding.MarshalCBOR(rec)
+	if err != nil {
+		return err
+	}
+	return p.prunedDB.Put(id[:], data)
+}
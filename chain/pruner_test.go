@@ -0,0 +1,159 @@
+// (c) 2022-2022, LDC Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package chain
+
+import (
+	"testing"
+
+	"github.com/ava-labs/avalanchego/database/memdb"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ldclabs/ldvm/config"
+	"github.com/ldclabs/ldvm/db"
+	"github.com/ldclabs/ldvm/ids"
+	"github.com/ldclabs/ldvm/ld"
+)
+
+type prunerTester struct {
+	t          *testing.T
+	dataDB     *db.PrefixDB
+	prevDataDB *db.PrefixDB
+}
+
+// update saves the data with the version, and its previous version.
+func (pt *prunerTester) update(id ids.DataID, mid ids.ModelID, version uint64) {
+	di := &ld.DataInfo{ModelID: mid, Version: version, Payload: []byte{byte(version)}}
+	require.NoError(pt.t, di.SyntacticVerify())
+	require.NoError(pt.t, pt.dataDB.Put(id[:], di.Bytes()))
+	if version > 1 {
+		prev := &ld.DataInfo{ModelID: mid, Version: version - 1, Payload: []byte{byte(version - 1)}}
+		require.NoError(pt.t, prev.SyntacticVerify())
+		require.NoError(pt.t, pt.prevDataDB.Put(id.VersionKey(version-1), prev.Bytes()))
+	}
+}
+
+func (pt *prunerTester) has(id ids.DataID, version uint64) bool {
+	ok, err := pt.prevDataDB.Has(id.VersionKey(version))
+	require.NoError(pt.t, err)
+	return ok
+}
+
+func TestPruner(t *testing.T) {
+	assert := assert.New(t)
+
+	pdb := db.NewPrefixDB(memdb.New(), dbPrefix, 512)
+	pt := &prunerTester{t: t, dataDB: pdb.With(dataDBPrefix), prevDataDB: pdb.With(prevDataDBPrefix)}
+
+	mid := ids.ModelID{1, 2, 3}
+	cfg := &config.Pruning{
+		Default: &config.RetentionPolicy{KeepVersions: 2},
+		Models: map[ids.ModelID]*config.RetentionPolicy{
+			mid:            {KeepSeconds: 100},
+			ld.CBORModelID: {KeepVersions: 1, KeepSeconds: 100},
+			ld.JSONModelID: {KeepVersions: 1},
+			ld.RawModelID:  {},
+			{4, 5, 6}:      {KeepVersions: 3},
+		},
+	}
+	assert.NoError(cfg.Valid())
+	assert.Equal(uint64(60), cfg.Interval)
+	assert.Equal(&config.RetentionPolicy{KeepVersions: 2}, cfg.Policy(ids.ModelID{7}))
+
+	protected := ids.DataID{9}
+	p := NewPruner(cfg, pdb, protected)
+
+	// keep the latest 1 previous version
+	id1 := ids.DataID{1}
+	// keep the previous versions superseded within 100 seconds
+	id2 := ids.DataID{2}
+	// keep the latest 1 previous version, or superseded within 100 seconds
+	id3 := ids.DataID{3}
+	for v := uint64(1); v <= 5; v++ {
+		pt.update(id1, ld.JSONModelID, v)
+		pt.update(id2, mid, v)
+		pt.update(id3, ld.CBORModelID, v)
+		pt.update(protected, ld.RawModelID, v)
+		p.Track(1000+v*10, []ids.DataID{id1, id2, id3, protected})
+	}
+
+	n, err := p.Prune()
+	require.NoError(t, err)
+	assert.Equal(uint64(3), n)
+	assert.Equal(uint64(3), p.PrunedVersion(id1))
+	assert.False(pt.has(id1, 3))
+	assert.True(pt.has(id1, 4))
+	assert.Equal(uint64(0), p.PrunedVersion(id2))
+	assert.Equal(uint64(0), p.PrunedVersion(id3))
+	assert.Equal(uint64(0), p.PrunedVersion(protected))
+	assert.True(pt.has(protected, 1))
+
+	info := p.Info()
+	assert.Equal(uint64(3), info["prunedVersions"])
+	assert.Equal(2, info["pendingData"])
+	assert.Equal([]PrunedRange{{ID: id1, From: 1, To: 3, Timestamp: 1050}}, info["recentRanges"])
+
+	// versions 1, 2 were superseded at 1020, 1030, more than 100 seconds ago
+	pt.update(id1, ld.JSONModelID, 6)
+	p.Track(1135, []ids.DataID{id1})
+	n, err = p.Prune()
+	require.NoError(t, err)
+	assert.Equal(uint64(1+2+2), n)
+	assert.Equal(uint64(4), p.PrunedVersion(id1))
+	assert.Equal(uint64(2), p.PrunedVersion(id2))
+	assert.True(pt.has(id2, 3))
+	assert.Equal(uint64(2), p.PrunedVersion(id3))
+	assert.Equal(2, p.Info()["pendingData"])
+
+	// all previous versions of id2 were superseded before 1050
+	p.Track(1150, nil)
+	n, err = p.Prune()
+	require.NoError(t, err)
+	assert.Equal(uint64(2+1), n)
+	assert.Equal(uint64(4), p.PrunedVersion(id2))
+	assert.False(pt.has(id2, 4))
+	// the latest previous version is retained
+	assert.Equal(uint64(3), p.PrunedVersion(id3))
+	assert.True(pt.has(id3, 4))
+	assert.Equal(0, p.Info()["pendingData"])
+
+	// data without pruned record
+	id4 := ids.DataID{4}
+	for v := uint64(1); v <= 5; v++ {
+		pt.update(id4, ids.ModelID{4, 5, 6}, v)
+	}
+	p.Track(1200, []ids.DataID{id4})
+	n, err = p.Prune()
+	require.NoError(t, err)
+	assert.Equal(uint64(1), n)
+	assert.Equal(uint64(1), p.PrunedVersion(id4))
+
+	// deleted data
+	di := &ld.DataInfo{ModelID: ids.ModelID{4, 5, 6}}
+	require.NoError(t, di.SyntacticVerify())
+	require.NoError(t, pt.dataDB.Put(id4[:], di.Bytes()))
+	p.Track(1300, []ids.DataID{id4})
+	assert.Equal(uint64(0), p.PrunedVersion(id4))
+
+	// the pending data are rebuilt from the pruned records after restarting,
+	// id3 is checked again though its latest previous version is retained
+	for v := uint64(6); v <= 8; v++ {
+		pt.update(id1, ld.JSONModelID, v)
+	}
+	p.Track(1400, []ids.DataID{id1})
+	p.Close()
+	n, err = p.Prune()
+	require.NoError(t, err)
+	assert.Equal(uint64(0), n)
+	p.Run() // returns at once
+
+	p = NewPruner(cfg, pdb, protected)
+	assert.Equal(2, p.Info()["pendingData"])
+	p.Track(1500, nil)
+	n, err = p.Prune()
+	require.NoError(t, err)
+	assert.Equal(uint64(2), n)
+	assert.Equal(uint64(6), p.PrunedVersion(id1))
+	assert.Equal(0, p.Info()["pendingData"])
+}
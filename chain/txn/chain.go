@@ -33,4 +33,6 @@ type ChainState interface {
 	DeleteData(*ld.DataInfo, []byte) error
 	SaveName(*service.Name) error
 	DeleteName(*service.Name) error
+	QueueScheduledTx(id ids.ID32, sender ids.Address, height, timestamp uint64) error
+	SetDataExpiry(id ids.DataID, prevExpiresAt, expiresAt uint64) error
 }
@@ -69,6 +69,8 @@ func (m *MockChainContext) MockChainState() *MockChainState {
 		MC:  make(map[ids.ModelID][]byte),
 		DC:  make(map[ids.DataID][]byte),
 		PDC: make(map[ids.DataID][]byte),
+		SQ:  make(map[ids.ID32]*ld.ScheduleEntry),
+		EX:  make(map[ids.DataID]uint64),
 		ac:  make(map[ids.Address][]byte),
 		al:  make(map[ids.Address][]byte),
 	}
@@ -106,6 +108,8 @@ type MockChainState struct {
 	MC  map[ids.ModelID][]byte
 	DC  map[ids.DataID][]byte
 	PDC map[ids.DataID][]byte
+	SQ  map[ids.ID32]*ld.ScheduleEntry // scheduled txs due queue, without Reserve and Tx
+	EX  map[ids.DataID]uint64          // data expiry index
 	ac  map[ids.Address][]byte
 	al  map[ids.Address][]byte
 }
@@ -160,6 +164,11 @@ func (m *MockChainState) CommitAccounts() {
 			panic(err)
 		}
 		m.ac[id] = data
+		if acc.IsClosed() {
+			delete(m.al, id)
+			continue
+		}
+
 		if len(ledger) > 0 {
 			m.al[id] = ledger
 		}
@@ -210,9 +219,13 @@ func (m *MockChainState) SaveName(ns *service.Name) error {
 	}
 
 	name := ns.ASCII()
-	_, ok := m.NC[name]
+	id, ok := m.NC[name]
 	switch {
 	case ok:
+		if di, err := m.LoadData(id); err == nil && di.IsExpired(m.Timestamp()) {
+			m.NC[name] = ns.DataID
+			return nil
+		}
 		return fmt.Errorf("name %q is conflict", name)
 	default:
 		m.NC[name] = ns.DataID
@@ -277,6 +290,11 @@ func (m *MockChainState) LoadData(id ids.DataID) (*ld.DataInfo, error) {
 		return nil, err
 	}
 	di.ID = id
+	if di.IsExpired(m.Timestamp()) && di.Version > 0 {
+		if err := di.MarkDeleted(nil); err != nil {
+			return nil, err
+		}
+	}
 	return di, nil
 }
 
@@ -318,6 +336,30 @@ func (m *MockChainState) DeleteData(di *ld.DataInfo, message []byte) error {
 	return nil
 }
 
+func (m *MockChainState) QueueScheduledTx(id ids.ID32, sender ids.Address, height, timestamp uint64) error {
+	if height == 0 && timestamp == 0 {
+		return fmt.Errorf("MBS.QueueScheduledTx: invalid schedule")
+	}
+	if _, ok := m.SQ[id]; ok {
+		return fmt.Errorf("MBS.QueueScheduledTx: %s exists", id)
+	}
+	m.SQ[id] = &ld.ScheduleEntry{Sender: sender, Height: height, Timestamp: timestamp}
+	return nil
+}
+
+func (m *MockChainState) SetDataExpiry(id ids.DataID, prevExpiresAt, expiresAt uint64) error {
+	if m.EX[id] != prevExpiresAt {
+		return fmt.Errorf("MBS.SetDataExpiry: invalid prevExpiresAt, expected %d, got %d",
+			m.EX[id], prevExpiresAt)
+	}
+	if expiresAt == 0 {
+		delete(m.EX, id)
+	} else {
+		m.EX[id] = expiresAt
+	}
+	return nil
+}
+
 func (m *MockChainState) VerifyState() error {
 	for k, v := range m.AC {
 		data, ledger, err := v.Marshal()
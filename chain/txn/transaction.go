@@ -43,6 +43,22 @@ func NewTx(tx *ld.Transaction) (Transaction, error) {
 		tt = &TxTransferMultiple{TxBase: TxBase{ld: tx}}
 	case ld.TypeExchange:
 		tt = &TxExchange{TxBase: TxBase{ld: tx}}
+	case ld.TypeCreateHTLC:
+		tt = &TxCreateHTLC{TxBase: TxBase{ld: tx}}
+	case ld.TypeClaimHTLC:
+		tt = &TxClaimHTLC{TxBase: TxBase{ld: tx}}
+	case ld.TypeRefundHTLC:
+		tt = &TxRefundHTLC{TxBase: TxBase{ld: tx}}
+	case ld.TypeCreateEscrow:
+		tt = &TxCreateEscrow{TxBase: TxBase{ld: tx}}
+	case ld.TypeSettleEscrow:
+		tt = &TxSettleEscrow{TxBase: TxBase{ld: tx}}
+	case ld.TypeApproveAllowance:
+		tt = &TxApproveAllowance{TxBase: TxBase{ld: tx}}
+	case ld.TypeTransferFrom:
+		tt = &TxTransferFrom{TxBase: TxBase{ld: tx}}
+	case ld.TypeCancelExchange:
+		tt = &TxCancelExchange{TxBase: TxBase{ld: tx}}
 
 	case ld.TypeUpdateNonceTable:
 		tt = &TxUpdateNonceTable{TxBase: TxBase{ld: tx}}
@@ -72,11 +88,45 @@ func NewTx(tx *ld.Transaction) (Transaction, error) {
 		tt = &TxBorrow{TxBase: TxBase{ld: tx}}
 	case ld.TypeRepay:
 		tt = &TxRepay{TxBase: TxBase{ld: tx}}
+	case ld.TypeCreateVesting:
+		tt = &TxCreateVesting{TxBase: TxBase{ld: tx}}
+	case ld.TypeClaimVesting:
+		tt = &TxClaimVesting{TxBase: TxBase{ld: tx}}
+	case ld.TypeMintToken:
+		tt = &TxMintToken{TxBase: TxBase{ld: tx}}
+	case ld.TypeBurnToken:
+		tt = &TxBurnToken{TxBase: TxBase{ld: tx}}
+	case ld.TypeUpdateTokenInfo:
+		tt = &TxUpdateTokenInfo{TxBase: TxBase{ld: tx}}
+	case ld.TypeSetRecovery:
+		tt = &TxSetRecovery{TxBase: TxBase{ld: tx}}
+	case ld.TypeStartRecovery:
+		tt = &TxStartRecovery{TxBase: TxBase{ld: tx}}
+	case ld.TypeCancelRecovery:
+		tt = &TxCancelRecovery{TxBase: TxBase{ld: tx}}
+	case ld.TypeExecuteRecovery:
+		tt = &TxExecuteRecovery{TxBase: TxBase{ld: tx}}
+	case ld.TypeCloseAccount:
+		tt = &TxCloseAccount{TxBase: TxBase{ld: tx}}
+	case ld.TypeScheduleTx:
+		tt = &TxScheduleTx{TxBase: TxBase{ld: tx}}
+	case ld.TypeCreateSubscription:
+		tt = &TxCreateSubscription{TxBase: TxBase{ld: tx}}
+	case ld.TypePullSubscription:
+		tt = &TxPullSubscription{TxBase: TxBase{ld: tx}}
+	case ld.TypeRevokeSubscription:
+		tt = &TxRevokeSubscription{TxBase: TxBase{ld: tx}}
+	case ld.TypeCreateProposal:
+		tt = &TxCreateProposal{TxBase: TxBase{ld: tx}}
+	case ld.TypeApproveProposal:
+		tt = &TxApproveProposal{TxBase: TxBase{ld: tx}}
 
 	case ld.TypeCreateModel:
 		tt = &TxCreateModel{TxBase: TxBase{ld: tx}}
 	case ld.TypeUpdateModelInfo:
 		tt = &TxUpdateModelInfo{TxBase: TxBase{ld: tx}}
+	case ld.TypeUpgradeModel:
+		tt = &TxUpgradeModel{TxBase: TxBase{ld: tx}}
 
 	case ld.TypeCreateData:
 		tt = &TxCreateData{TxBase: TxBase{ld: tx}}
@@ -90,6 +140,10 @@ func NewTx(tx *ld.Transaction) (Transaction, error) {
 		tt = &TxUpdateDataInfoByAuth{TxBase: TxBase{ld: tx}}
 	case ld.TypeDeleteData:
 		tt = &TxDeleteData{TxBase: TxBase{ld: tx}}
+	case ld.TypeSellData:
+		tt = &TxSellData{TxBase: TxBase{ld: tx}}
+	case ld.TypeBuyData:
+		tt = &TxBuyData{TxBase: TxBase{ld: tx}}
 	case ld.TypePunish:
 		tt = &TxPunish{TxBase: TxBase{ld: tx}}
 	default:
@@ -102,6 +156,26 @@ func NewTx(tx *ld.Transaction) (Transaction, error) {
 	return tt, nil
 }
 
+type scheduledTx interface {
+	setScheduled()
+}
+
+// NewScheduledTx returns a stateful transaction from the inner tx of a TxScheduleTx,
+// its nonce has been consumed by the TxScheduleTx so it will not be checked again.
+func NewScheduledTx(tx *ld.Transaction) (Transaction, error) {
+	tt, err := NewTx(tx)
+	if err != nil {
+		return nil, err
+	}
+
+	st, ok := tt.(scheduledTx)
+	if !ok {
+		return nil, fmt.Errorf("NewScheduledTx: unsupport TxType: %s", tx.Tx.Type)
+	}
+	st.setScheduled()
+	return tt, nil
+}
+
 type GenesisTx interface {
 	ApplyGenesis(ctx ChainContext, cs ChainState) error
 }
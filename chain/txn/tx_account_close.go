@@ -0,0 +1,79 @@
+// (c) 2022-2022, LDC Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package txn
+
+import (
+	"github.com/ldclabs/ldvm/ld"
+	"github.com/ldclabs/ldvm/util/erring"
+)
+
+// TxCloseAccount sweeps all the sender's balances and tokens to the beneficiary (tx.To),
+// deletes the sender's ledger from the state and keeps the account as a tombstone with the nonce.
+// A token or stake account will be destroyed before closing.
+// It should be signed by the keepers with VerifyPlus, as it sweeps all.
+type TxCloseAccount struct {
+	TxBase
+}
+
+func (tx *TxCloseAccount) SyntacticVerify() error {
+	var err error
+	errp := erring.ErrPrefix("txn.TxCloseAccount.SyntacticVerify: ")
+
+	if err = tx.TxBase.SyntacticVerify(); err != nil {
+		return errp.ErrorIf(err)
+	}
+
+	switch {
+	case tx.ld.Tx.To == nil:
+		return errp.Errorf("nil to as beneficiary")
+
+	case tx.ld.Tx.Token != nil:
+		return errp.Errorf("invalid token, should be nil")
+
+	case tx.ld.Tx.Amount != nil:
+		return errp.Errorf("invalid amount, should be nil")
+
+	case tx.ld.Tx.Data != nil:
+		return errp.Errorf("invalid data, should be nil")
+	}
+	return nil
+}
+
+func (tx *TxCloseAccount) Apply(ctx ChainContext, cs ChainState) error {
+	var err error
+	errp := erring.ErrPrefix("txn.TxCloseAccount.Apply: ")
+
+	if err = tx.TxBase.verify(ctx, cs); err != nil {
+		return errp.ErrorIf(err)
+	}
+	if tx.sessionKey != nil {
+		return errp.Errorf("can't close account by session key")
+	}
+	if !tx.from.VerifyPlus(tx.ld.SigHash(), tx.ld.Signatures, tx.senderKey) {
+		return errp.Errorf("invalid signatures for keepers")
+	}
+
+	if err = cs.LoadLedger(tx.from); err != nil {
+		return errp.ErrorIf(err)
+	}
+
+	if err = tx.TxBase.accept(ctx, cs); err != nil {
+		return errp.ErrorIf(err)
+	}
+
+	// do it after TxBase.accept
+	switch tx.from.Type() {
+	case ld.TokenAccount:
+		tx.from.ResetPledge()
+		err = tx.from.DestroyToken(tx.to)
+
+	case ld.StakeAccount:
+		tx.from.ResetPledge()
+		err = tx.from.DestroyStake(tx.to)
+	}
+	if err != nil {
+		return errp.ErrorIf(err)
+	}
+	return errp.ErrorIf(tx.from.CloseAccount(tx.to))
+}
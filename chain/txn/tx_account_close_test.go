@@ -0,0 +1,210 @@
+// (c) 2022-2022, LDC Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package txn
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ldclabs/ldvm/chain/acct"
+	"github.com/ldclabs/ldvm/ids"
+	"github.com/ldclabs/ldvm/ld"
+	"github.com/ldclabs/ldvm/signer"
+	"github.com/ldclabs/ldvm/unit"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTxCloseAccount(t *testing.T) {
+	assert := assert.New(t)
+
+	// SyntacticVerify
+	tx := &TxCloseAccount{}
+	assert.ErrorContains(tx.SyntacticVerify(), "nil pointer")
+	_, err := tx.MarshalJSON()
+	require.NoError(t, err)
+
+	ctx := NewMockChainContext()
+	cs := ctx.MockChainState()
+	sender := signer.Signer1.Key().Address()
+	beneficiary := signer.Signer2.Key().Address()
+	token := ld.MustNewToken("$TEST")
+
+	ltx := &ld.Transaction{Tx: ld.TxData{
+		Type:      ld.TypeCloseAccount,
+		ChainID:   ctx.ChainConfig().ChainID,
+		Nonce:     0,
+		GasTip:    100,
+		GasFeeCap: ctx.Price,
+		From:      sender,
+	}}
+	assert.NoError(ltx.SignWith(signer.Signer1))
+	assert.NoError(ltx.SyntacticVerify())
+	_, err = NewTx(ltx)
+	assert.ErrorContains(err, "nil to as beneficiary")
+
+	ltx = &ld.Transaction{Tx: ld.TxData{
+		Type:      ld.TypeCloseAccount,
+		ChainID:   ctx.ChainConfig().ChainID,
+		Nonce:     0,
+		GasTip:    100,
+		GasFeeCap: ctx.Price,
+		From:      sender,
+		To:        beneficiary.Ptr(),
+		Token:     token.Ptr(),
+	}}
+	assert.NoError(ltx.SignWith(signer.Signer1))
+	assert.NoError(ltx.SyntacticVerify())
+	_, err = NewTx(ltx)
+	assert.ErrorContains(err, "invalid token, should be nil")
+
+	ltx = &ld.Transaction{Tx: ld.TxData{
+		Type:      ld.TypeCloseAccount,
+		ChainID:   ctx.ChainConfig().ChainID,
+		Nonce:     0,
+		GasTip:    100,
+		GasFeeCap: ctx.Price,
+		From:      sender,
+		To:        beneficiary.Ptr(),
+		Amount:    big.NewInt(1),
+	}}
+	assert.NoError(ltx.SignWith(signer.Signer1))
+	assert.NoError(ltx.SyntacticVerify())
+	_, err = NewTx(ltx)
+	assert.ErrorContains(err, "invalid amount, should be nil")
+
+	ltx = &ld.Transaction{Tx: ld.TxData{
+		Type:      ld.TypeCloseAccount,
+		ChainID:   ctx.ChainConfig().ChainID,
+		Nonce:     0,
+		GasTip:    100,
+		GasFeeCap: ctx.Price,
+		From:      sender,
+		To:        beneficiary.Ptr(),
+		Data:      []byte{0x80},
+	}}
+	assert.NoError(ltx.SignWith(signer.Signer1))
+	assert.NoError(ltx.SyntacticVerify())
+	_, err = NewTx(ltx)
+	assert.ErrorContains(err, "invalid data, should be nil")
+
+	ltx = &ld.Transaction{Tx: ld.TxData{
+		Type:      ld.TypeCloseAccount,
+		ChainID:   ctx.ChainConfig().ChainID,
+		Nonce:     0,
+		GasTip:    100,
+		GasFeeCap: ctx.Price,
+		From:      sender,
+		To:        beneficiary.Ptr(),
+	}}
+	assert.NoError(ltx.SignWith(signer.Signer1))
+	assert.NoError(ltx.SyntacticVerify())
+	itx, err := NewTx(ltx)
+	require.NoError(t, err)
+
+	cs.CommitAccounts()
+	assert.ErrorContains(itx.Apply(ctx, cs),
+		"insufficient NativeLDC balance, expected 1728100, got 0")
+	cs.CheckoutAccounts()
+
+	senderAcc := cs.MustAccount(sender)
+	senderAcc.Add(ids.NativeToken, new(big.Int).SetUint64(unit.LDC))
+	senderAcc.Add(token, new(big.Int).SetUint64(unit.LDC*2))
+	require.NoError(t, cs.LoadLedger(senderAcc))
+	assert.NoError(senderAcc.OpenLending(&ld.LendingConfig{
+		DailyInterest:   10_000,
+		OverdueInterest: 10_000,
+		MinAmount:       new(big.Int).SetUint64(unit.LDC),
+		MaxAmount:       new(big.Int).SetUint64(unit.LDC * 10),
+	}))
+	cs.CommitAccounts()
+	assert.ErrorContains(itx.Apply(ctx, cs), "please close lending before close")
+	cs.CheckoutAccounts()
+
+	assert.NoError(senderAcc.CloseLending())
+	assert.NoError(itx.Apply(ctx, cs))
+
+	senderGas := ltx.Gas()
+	beneficiaryAcc := cs.MustAccount(beneficiary)
+	assert.Equal(senderGas*ctx.Price,
+		itx.(*TxCloseAccount).ldc.Balance().Uint64())
+	assert.Equal(senderGas*100,
+		itx.(*TxCloseAccount).miner.Balance().Uint64())
+	assert.Equal(unit.LDC-senderGas*(ctx.Price+100),
+		beneficiaryAcc.BalanceOfAll(ids.NativeToken).Uint64())
+	assert.Equal(unit.LDC*2, beneficiaryAcc.BalanceOfAll(token).Uint64())
+	assert.Equal(uint64(0), senderAcc.BalanceOfAll(ids.NativeToken).Uint64())
+	assert.Equal(uint64(0), senderAcc.BalanceOfAll(token).Uint64())
+	assert.Equal(uint64(1), senderAcc.Nonce())
+	assert.True(senderAcc.IsEmpty())
+	assert.True(senderAcc.IsClosed())
+
+	jsondata, err := itx.MarshalJSON()
+	require.NoError(t, err)
+	assert.Contains(string(jsondata), `"type":"TypeCloseAccount"`)
+
+	cs.CommitAccounts()
+	data, ok := cs.ac[sender]
+	assert.True(ok)
+	tombstone, err := acct.ParseAccount(sender, data)
+	require.NoError(t, err)
+	assert.Equal(uint64(1), tombstone.Nonce())
+	_, ok = cs.al[sender]
+	assert.False(ok)
+
+	// the txs signed before closing can't be replayed when funded again
+	senderAcc.Add(ids.NativeToken, new(big.Int).SetUint64(unit.LDC))
+	assert.False(senderAcc.IsClosed())
+	itx, err = NewTx(ltx)
+	require.NoError(t, err)
+	assert.ErrorContains(itx.Apply(ctx, cs), "invalid nonce for sender, expected 1, got 0")
+	cs.CheckoutAccounts()
+	assert.True(senderAcc.IsClosed())
+
+	// close a token account
+	tokenAcc := acct.NewAccount(ids.Address(token))
+	tokenAcc.Init(big.NewInt(0), ctx.FeeConfig().MinTokenPledge, 0, 0)
+	assert.NoError(tokenAcc.CreateToken(&ld.TxAccounter{
+		Threshold: ld.Uint16Ptr(1),
+		Keepers:   &signer.Keys{signer.Signer1.Key(), signer.Signer2.Key()},
+		Amount:    new(big.Int).SetUint64(unit.LDC * 10),
+	}))
+	tokenBalance := new(big.Int).Add(ctx.FeeConfig().MinTokenPledge, new(big.Int).SetUint64(unit.LDC))
+	tokenAcc.Add(ids.NativeToken, tokenBalance)
+	cs.AC[tokenAcc.ID()] = tokenAcc
+
+	ltx = &ld.Transaction{Tx: ld.TxData{
+		Type:      ld.TypeCloseAccount,
+		ChainID:   ctx.ChainConfig().ChainID,
+		Nonce:     0,
+		GasTip:    100,
+		GasFeeCap: ctx.Price,
+		From:      tokenAcc.ID(),
+		To:        beneficiary.Ptr(),
+	}}
+	assert.NoError(ltx.SignWith(signer.Signer1))
+	assert.NoError(ltx.SyntacticVerify())
+	itx, err = NewTx(ltx)
+	require.NoError(t, err)
+
+	cs.CommitAccounts()
+	assert.ErrorContains(itx.Apply(ctx, cs), "invalid signatures for keepers")
+	cs.CheckoutAccounts()
+
+	assert.NoError(ltx.SignWith(signer.Signer1, signer.Signer2))
+	assert.NoError(ltx.SyntacticVerify())
+	itx, err = NewTx(ltx)
+	require.NoError(t, err)
+
+	nativeBalance := beneficiaryAcc.BalanceOfAll(ids.NativeToken).Uint64()
+	assert.NoError(itx.Apply(ctx, cs))
+	tokenGas := ltx.Gas()
+	assert.Equal(nativeBalance+tokenBalance.Uint64()-tokenGas*(ctx.Price+100),
+		beneficiaryAcc.BalanceOfAll(ids.NativeToken).Uint64())
+	assert.Equal(unit.LDC*2, beneficiaryAcc.BalanceOfAll(token).Uint64())
+	assert.Equal(ld.NativeAccount, tokenAcc.Type())
+	assert.True(tokenAcc.IsClosed())
+
+	assert.NoError(cs.VerifyState())
+}
@@ -126,6 +126,13 @@ func (tx *TxBorrow) Apply(ctx ChainContext, cs ChainState) error {
 		return errp.ErrorIf(err)
 	}
 
+	// the token is lent by the lender, so the lender's policy applies
+	if err = tx.to.CheckPolicy(&tx.ld.Tx.From, tx.token, tx.input.Amount); err != nil {
+		return errp.ErrorIf(err)
+	}
+	if err = tx.to.SpendByPolicy(tx.token, tx.input.Amount); err != nil {
+		return errp.ErrorIf(err)
+	}
 	if err = tx.to.Borrow(
 		tx.token, tx.ld.Tx.From, tx.input.Amount, tx.dueTime); err != nil {
 		return errp.ErrorIf(err)
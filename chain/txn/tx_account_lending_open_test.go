@@ -188,7 +188,7 @@ func TestTxOpenLending(t *testing.T) {
 		"lending exists")
 	cs.CheckoutAccounts()
 
-	assert.NoError(senderAcc.UpdateKeepers(nil, nil, signer.Signer2.Key().Ptr(), &ld.TxTypes{ld.TypeOpenLending}))
+	assert.NoError(senderAcc.UpdateKeepers(nil, nil, nil, signer.Signer2.Key().Ptr(), &ld.TxTypes{ld.TypeOpenLending}))
 	// close lending
 	ltx = &ld.Transaction{Tx: ld.TxData{
 		Type:      ld.TypeCloseLending,
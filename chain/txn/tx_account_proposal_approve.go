@@ -0,0 +1,90 @@
+// (c) 2022-2022, LDC Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package txn
+
+import (
+	"encoding/json"
+
+	"github.com/ldclabs/ldvm/ld"
+	"github.com/ldclabs/ldvm/util/erring"
+)
+
+// TxApproveProposal adds keepers' approvals to the proposal of the multisig account (tx.To).
+// Once the threshold is met, the proposed tx is scheduled to be executed
+// at the start of the next block.
+type TxApproveProposal struct {
+	TxBase
+	input *ld.TxProposalApproval
+}
+
+func (tx *TxApproveProposal) MarshalJSON() ([]byte, error) {
+	if tx == nil || tx.ld == nil {
+		return []byte("null"), nil
+	}
+
+	v := tx.ld.Copy()
+	errp := erring.ErrPrefix("txn.TxApproveProposal.MarshalJSON: ")
+	if tx.input == nil {
+		return nil, errp.Errorf("nil tx.input")
+	}
+	d, err := json.Marshal(tx.input)
+	if err != nil {
+		return nil, errp.ErrorIf(err)
+	}
+	v.Tx.Data = d
+	return errp.ErrorMap(json.Marshal(v))
+}
+
+func (tx *TxApproveProposal) SyntacticVerify() error {
+	var err error
+	errp := erring.ErrPrefix("txn.TxApproveProposal.SyntacticVerify: ")
+
+	if err = tx.TxBase.SyntacticVerify(); err != nil {
+		return errp.ErrorIf(err)
+	}
+
+	switch {
+	case tx.ld.Tx.To == nil:
+		return errp.Errorf("nil to as multisig account")
+
+	case tx.ld.Tx.Token != nil:
+		return errp.Errorf("invalid token, should be nil")
+
+	case tx.ld.Tx.Amount != nil:
+		return errp.Errorf("invalid amount, should be nil")
+
+	case len(tx.ld.Tx.Data) == 0:
+		return errp.Errorf("invalid data")
+	}
+
+	tx.input = &ld.TxProposalApproval{}
+	if err = tx.input.Unmarshal(tx.ld.Tx.Data); err != nil {
+		return errp.ErrorIf(err)
+	}
+	if err = tx.input.SyntacticVerify(); err != nil {
+		return errp.ErrorIf(err)
+	}
+	return nil
+}
+
+func (tx *TxApproveProposal) Apply(ctx ChainContext, cs ChainState) error {
+	var err error
+	errp := erring.ErrPrefix("txn.TxApproveProposal.Apply: ")
+
+	if err = tx.TxBase.verify(ctx, cs); err != nil {
+		return errp.ErrorIf(err)
+	}
+
+	if err = cs.LoadLedger(tx.to); err != nil {
+		return errp.ErrorIf(err)
+	}
+
+	if err = tx.to.ApproveProposal(tx.input.ID, tx.input.Sigs); err != nil {
+		return errp.ErrorIf(err)
+	}
+	if err = tx.TxBase.accept(ctx, cs); err != nil {
+		return errp.ErrorIf(err)
+	}
+	return errp.ErrorIf(queueProposal(cs, tx.to, tx.input.ID))
+}
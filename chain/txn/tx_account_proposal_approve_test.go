@@ -0,0 +1,283 @@
+// (c) 2022-2022, LDC Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package txn
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ldclabs/ldvm/ids"
+	"github.com/ldclabs/ldvm/ld"
+	"github.com/ldclabs/ldvm/signer"
+	"github.com/ldclabs/ldvm/unit"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTxApproveProposal(t *testing.T) {
+	assert := assert.New(t)
+
+	// SyntacticVerify
+	tx := &TxApproveProposal{}
+	assert.ErrorContains(tx.SyntacticVerify(), "nil pointer")
+	_, err := tx.MarshalJSON()
+	require.NoError(t, err)
+
+	ctx := NewMockChainContext()
+	cs := ctx.MockChainState()
+	sender := signer.Signer2.Key().Address()
+	multisig := ids.Address{1, 2, 3}
+	to := signer.Signer1.Key().Address()
+
+	ltx := &ld.Transaction{Tx: ld.TxData{
+		Type:      ld.TypeApproveProposal,
+		ChainID:   ctx.ChainConfig().ChainID,
+		Nonce:     0,
+		GasTip:    100,
+		GasFeeCap: ctx.Price,
+		From:      sender,
+	}}
+	assert.NoError(ltx.SignWith(signer.Signer2))
+	assert.NoError(ltx.SyntacticVerify())
+	_, err = NewTx(ltx)
+	assert.ErrorContains(err, "nil to as multisig account")
+
+	ltx = &ld.Transaction{Tx: ld.TxData{
+		Type:      ld.TypeApproveProposal,
+		ChainID:   ctx.ChainConfig().ChainID,
+		Nonce:     0,
+		GasTip:    100,
+		GasFeeCap: ctx.Price,
+		From:      sender,
+		To:        multisig.Ptr(),
+		Token:     ids.NativeToken.Ptr(),
+	}}
+	assert.NoError(ltx.SignWith(signer.Signer2))
+	assert.NoError(ltx.SyntacticVerify())
+	_, err = NewTx(ltx)
+	assert.ErrorContains(err, "invalid token, should be nil")
+
+	ltx = &ld.Transaction{Tx: ld.TxData{
+		Type:      ld.TypeApproveProposal,
+		ChainID:   ctx.ChainConfig().ChainID,
+		Nonce:     0,
+		GasTip:    100,
+		GasFeeCap: ctx.Price,
+		From:      sender,
+		To:        multisig.Ptr(),
+		Amount:    big.NewInt(1),
+	}}
+	assert.NoError(ltx.SignWith(signer.Signer2))
+	assert.NoError(ltx.SyntacticVerify())
+	_, err = NewTx(ltx)
+	assert.ErrorContains(err, "invalid amount, should be nil")
+
+	ltx = &ld.Transaction{Tx: ld.TxData{
+		Type:      ld.TypeApproveProposal,
+		ChainID:   ctx.ChainConfig().ChainID,
+		Nonce:     0,
+		GasTip:    100,
+		GasFeeCap: ctx.Price,
+		From:      sender,
+		To:        multisig.Ptr(),
+	}}
+	assert.NoError(ltx.SignWith(signer.Signer2))
+	assert.NoError(ltx.SyntacticVerify())
+	_, err = NewTx(ltx)
+	assert.ErrorContains(err, "invalid data")
+
+	ltx = &ld.Transaction{Tx: ld.TxData{
+		Type:      ld.TypeApproveProposal,
+		ChainID:   ctx.ChainConfig().ChainID,
+		Nonce:     0,
+		GasTip:    100,
+		GasFeeCap: ctx.Price,
+		From:      sender,
+		To:        multisig.Ptr(),
+		Data:      []byte("你好👋"),
+	}}
+	assert.NoError(ltx.SignWith(signer.Signer2))
+	assert.NoError(ltx.SyntacticVerify())
+	_, err = NewTx(ltx)
+	assert.ErrorContains(err, "ld.TxProposalApproval.Unmarshal")
+
+	ltx = &ld.Transaction{Tx: ld.TxData{
+		Type:      ld.TypeApproveProposal,
+		ChainID:   ctx.ChainConfig().ChainID,
+		Nonce:     0,
+		GasTip:    100,
+		GasFeeCap: ctx.Price,
+		From:      sender,
+		To:        multisig.Ptr(),
+		Data:      (&ld.TxProposalApproval{ID: ids.ID32{1}}).Bytes(),
+	}}
+	assert.NoError(ltx.SignWith(signer.Signer2))
+	assert.NoError(ltx.SyntacticVerify())
+	_, err = NewTx(ltx)
+	assert.ErrorContains(err, "empty sigs")
+
+	// propose by the first keeper
+	inner := &ld.Transaction{Tx: ld.TxData{
+		Type:      ld.TypeTransfer,
+		ChainID:   ctx.ChainConfig().ChainID,
+		Nonce:     2000,
+		GasTip:    100,
+		GasFeeCap: ctx.Price,
+		From:      multisig,
+		To:        to.Ptr(),
+		Amount:    new(big.Int).SetUint64(unit.LDC),
+	}}
+	assert.NoError(inner.SignForSchedule(signer.Signer1))
+	assert.NoError(inner.SyntacticVerify())
+	proposal := &ld.TxProposal{Expire: 2000, Tx: inner.Bytes()}
+	assert.NoError(proposal.SyntacticVerify())
+	id := proposal.ID()
+
+	multisigAcc := cs.MustAccount(multisig)
+	multisigAcc.Add(ids.NativeToken, new(big.Int).SetUint64(unit.LDC*3))
+	assert.NoError(multisigAcc.UpdateKeepers(ld.Uint16Ptr(2),
+		&signer.Keys{signer.Signer1.Key(), signer.Signer2.Key()}, nil, nil, nil))
+	assert.NoError(cs.LoadLedger(multisigAcc))
+	assert.NoError(multisigAcc.CreateProposal(id, proposal.Tx, proposal.Expire, inner.Signatures))
+
+	sig1, err := signer.Signer1.SignHash(id[:])
+	require.NoError(t, err)
+	sig2, err := signer.Signer2.SignHash(id[:])
+	require.NoError(t, err)
+
+	input := &ld.TxProposalApproval{ID: id, Sigs: signer.Sigs{sig1}}
+	assert.NoError(input.SyntacticVerify())
+	ltx = &ld.Transaction{Tx: ld.TxData{
+		Type:      ld.TypeApproveProposal,
+		ChainID:   ctx.ChainConfig().ChainID,
+		Nonce:     0,
+		GasTip:    100,
+		GasFeeCap: ctx.Price,
+		From:      sender,
+		To:        multisig.Ptr(),
+		Data:      input.Bytes(),
+	}}
+	assert.NoError(ltx.SignWith(signer.Signer2))
+	assert.NoError(ltx.SyntacticVerify())
+	itx, err := NewTx(ltx)
+	require.NoError(t, err)
+
+	cs.CommitAccounts()
+	assert.ErrorContains(itx.Apply(ctx, cs),
+		"insufficient NativeLDC balance, expected 1841400, got 0")
+	cs.CheckoutAccounts()
+
+	senderAcc := cs.MustAccount(sender)
+	senderAcc.Add(ids.NativeToken, new(big.Int).SetUint64(unit.LDC))
+	cs.CommitAccounts()
+	assert.ErrorContains(itx.Apply(ctx, cs),
+		signer.Signer1.Key().String()+" has approved")
+	cs.CheckoutAccounts()
+
+	input = &ld.TxProposalApproval{ID: ids.ID32{1}, Sigs: signer.Sigs{sig2}}
+	assert.NoError(input.SyntacticVerify())
+	ltx = &ld.Transaction{Tx: ld.TxData{
+		Type:      ld.TypeApproveProposal,
+		ChainID:   ctx.ChainConfig().ChainID,
+		Nonce:     0,
+		GasTip:    100,
+		GasFeeCap: ctx.Price,
+		From:      sender,
+		To:        multisig.Ptr(),
+		Data:      input.Bytes(),
+	}}
+	assert.NoError(ltx.SignWith(signer.Signer2))
+	assert.NoError(ltx.SyntacticVerify())
+	itx, err = NewTx(ltx)
+	require.NoError(t, err)
+
+	cs.CommitAccounts()
+	assert.ErrorContains(itx.Apply(ctx, cs),
+		"proposal "+ids.ID32{1}.String()+" not found")
+	cs.CheckoutAccounts()
+
+	input = &ld.TxProposalApproval{ID: id, Sigs: signer.Sigs{sig2}}
+	assert.NoError(input.SyntacticVerify())
+	ltx = &ld.Transaction{Tx: ld.TxData{
+		Type:      ld.TypeApproveProposal,
+		ChainID:   ctx.ChainConfig().ChainID,
+		Nonce:     0,
+		GasTip:    100,
+		GasFeeCap: ctx.Price,
+		From:      sender,
+		To:        multisig.Ptr(),
+		Data:      input.Bytes(),
+	}}
+	assert.NoError(ltx.SignWith(signer.Signer2))
+	assert.NoError(ltx.SyntacticVerify())
+	itx, err = NewTx(ltx)
+	require.NoError(t, err)
+
+	// the approved proposal is scheduled even if the schedule ledger is full
+	cs.CommitAccounts()
+	for i := 0; i < ld.MaxScheduledTxsPerAccount; i++ {
+		assert.NoError(multisigAcc.ScheduleTx(ids.ID32{1, byte(i)}, &ld.ScheduleEntry{
+			Sender:  multisig,
+			Height:  100,
+			Reserve: big.NewInt(0),
+			Tx:      []byte{0x80},
+		}))
+	}
+	assert.NoError(itx.Apply(ctx, cs))
+	assert.Nil(multisigAcc.Proposal(id))
+	assert.Equal(1, len(cs.SQ))
+	cs.CheckoutAccounts()
+	cs.SQ = make(map[ids.ID32]*ld.ScheduleEntry)
+
+	itx, err = NewTx(ltx)
+	require.NoError(t, err)
+	assert.NoError(itx.Apply(ctx, cs))
+
+	// the reserve is based on the inner tx with all approvals
+	full := &ld.Transaction{Tx: inner.Tx, Signatures: signer.Sigs{inner.Signatures[0], sig2}, Scheduled: true}
+	assert.NoError(full.SyntacticVerify())
+	senderGas := ltx.Gas()
+	reserve := full.Gas() * (ctx.Price + 100)
+	ldcAcc := itx.(*TxApproveProposal).ldc
+	assert.Equal(senderGas*ctx.Price, ldcAcc.Balance().Uint64())
+	assert.Equal(senderGas*100, itx.(*TxApproveProposal).miner.Balance().Uint64())
+	assert.Equal(unit.LDC-senderGas*(ctx.Price+100),
+		senderAcc.BalanceOfAll(ids.NativeToken).Uint64())
+	assert.Equal(uint64(1), senderAcc.Nonce())
+	assert.Nil(multisigAcc.Proposal(id))
+	assert.Equal(unit.LDC*3-reserve, multisigAcc.BalanceOfAll(ids.NativeToken).Uint64())
+	assert.Equal(uint64(0), multisigAcc.Nonce())
+
+	jsondata, err := itx.MarshalJSON()
+	require.NoError(t, err)
+	assert.Contains(string(jsondata), `"type":"TypeApproveProposal"`)
+	assert.Contains(string(jsondata), `"data":{"id":"`+id.String()+`"`)
+
+	cs.CommitAccounts()
+	assert.ErrorContains(itx.Apply(ctx, cs), "invalid nonce for sender, expected 1, got 0")
+	cs.CheckoutAccounts()
+
+	// execute the scheduled tx
+	assert.Equal(&ld.ScheduleEntry{Sender: multisig, Height: 2}, cs.SQ[full.ID])
+	ctx.height++
+	entry, err := multisigAcc.TakeScheduledTx(full.ID)
+	require.NoError(t, err)
+	assert.Equal(multisig, entry.Sender)
+	assert.Equal(reserve, entry.Reserve.Uint64())
+	assert.NoError(multisigAcc.Add(ids.NativeToken, entry.Reserve))
+
+	stx := &ld.Transaction{}
+	require.NoError(t, stx.Unmarshal(entry.Tx))
+	require.NoError(t, stx.SyntacticVerify())
+	assert.Equal(full.Signatures, stx.Signatures)
+	ntx, err := NewScheduledTx(stx)
+	require.NoError(t, err)
+	assert.NoError(ntx.Apply(ctx, cs))
+
+	assert.Equal(unit.LDC*2-reserve, multisigAcc.BalanceOfAll(ids.NativeToken).Uint64())
+	assert.Equal(unit.LDC, cs.MustAccount(to).BalanceOfAll(ids.NativeToken).Uint64())
+	assert.Equal(uint64(0), multisigAcc.Nonce())
+
+	assert.NoError(cs.VerifyState())
+}
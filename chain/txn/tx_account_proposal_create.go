@@ -0,0 +1,164 @@
+// (c) 2022-2022, LDC Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package txn
+
+import (
+	"encoding/json"
+	"math/big"
+
+	"github.com/ldclabs/ldvm/chain/acct"
+	"github.com/ldclabs/ldvm/ids"
+	"github.com/ldclabs/ldvm/ld"
+	"github.com/ldclabs/ldvm/util/erring"
+)
+
+// TxCreateProposal proposes an inner tx for the multisig account (tx.To).
+// The inner tx's signatures are taken as the first approvals, other keepers add
+// their approvals by TxApproveProposal. Once the threshold is met, the inner tx is
+// scheduled to be executed at the start of the next block.
+// The inner tx's nonce is the proposal's expire, it doesn't consume the account's nonce.
+type TxCreateProposal struct {
+	TxBase
+	input *ld.TxProposal
+}
+
+func (tx *TxCreateProposal) MarshalJSON() ([]byte, error) {
+	if tx == nil || tx.ld == nil {
+		return []byte("null"), nil
+	}
+
+	v := tx.ld.Copy()
+	errp := erring.ErrPrefix("txn.TxCreateProposal.MarshalJSON: ")
+	if tx.input == nil {
+		return nil, errp.Errorf("nil tx.input")
+	}
+	d, err := json.Marshal(tx.input)
+	if err != nil {
+		return nil, errp.ErrorIf(err)
+	}
+	v.Tx.Data = d
+	return errp.ErrorMap(json.Marshal(v))
+}
+
+func (tx *TxCreateProposal) SyntacticVerify() error {
+	var err error
+	errp := erring.ErrPrefix("txn.TxCreateProposal.SyntacticVerify: ")
+
+	if err = tx.TxBase.SyntacticVerify(); err != nil {
+		return errp.ErrorIf(err)
+	}
+
+	switch {
+	case tx.ld.Tx.To == nil:
+		return errp.Errorf("nil to as multisig account")
+
+	case tx.ld.Tx.Token != nil:
+		return errp.Errorf("invalid token, should be nil")
+
+	case tx.ld.Tx.Amount != nil:
+		return errp.Errorf("invalid amount, should be nil")
+
+	case len(tx.ld.Tx.Data) == 0:
+		return errp.Errorf("invalid data")
+	}
+
+	tx.input = &ld.TxProposal{}
+	if err = tx.input.Unmarshal(tx.ld.Tx.Data); err != nil {
+		return errp.ErrorIf(err)
+	}
+	if err = tx.input.SyntacticVerify(); err != nil {
+		return errp.ErrorIf(err)
+	}
+
+	inner := tx.input.Transaction()
+	switch {
+	case inner.Tx.From != *tx.ld.Tx.To:
+		return errp.Errorf("invalid sender of proposed tx, expected %s, got %s",
+			*tx.ld.Tx.To, inner.Tx.From)
+
+	case len(inner.Signatures) == 0:
+		return errp.Errorf("invalid proposed tx, no signatures")
+
+	case !inner.Scheduled:
+		return errp.Errorf("invalid proposed tx, should be signed for schedule")
+	}
+	return nil
+}
+
+func (tx *TxCreateProposal) Apply(ctx ChainContext, cs ChainState) error {
+	var err error
+	errp := erring.ErrPrefix("txn.TxCreateProposal.Apply: ")
+
+	if err = tx.TxBase.verify(ctx, cs); err != nil {
+		return errp.ErrorIf(err)
+	}
+
+	if err = cs.LoadLedger(tx.to); err != nil {
+		return errp.ErrorIf(err)
+	}
+
+	id := tx.input.ID()
+	if err = tx.to.CreateProposal(id, tx.input.Tx, tx.input.Expire,
+		tx.input.Transaction().Signatures); err != nil {
+		return errp.ErrorIf(err)
+	}
+	if err = tx.TxBase.accept(ctx, cs); err != nil {
+		return errp.ErrorIf(err)
+	}
+	return errp.ErrorIf(queueProposal(cs, tx.to, id))
+}
+
+// queueProposal schedules the proposal's inner tx to be executed at the start of
+// the next block if the proposal has been approved by the multisig account.
+// The executed proposal is kept until it expires, so the fully signed inner tx
+// can't be replayed, and its max gas cost is reserved as TxScheduleTx does.
+func queueProposal(cs ChainState, acc *acct.Account, id ids.ID32) error {
+	e := acc.Proposal(id)
+	if e == nil {
+		return nil
+	}
+
+	inner := &ld.Transaction{}
+	if err := inner.Unmarshal(e.Tx); err != nil {
+		return err
+	}
+	inner.Signatures = e.Sigs
+	if err := inner.SyntacticVerify(); err != nil {
+		return err
+	}
+
+	verify := acc.Verify
+	switch inner.Tx.Type {
+	case ld.TypeUpdateAccountInfo, ld.TypeCloseAccount, ld.TypeSetRecovery,
+		ld.TypeResetStake, ld.TypeDestroyStake, ld.TypeDestroyToken:
+		verify = acc.VerifyPlus
+	}
+
+	if !verify(inner.SigHash(), inner.Signatures, nil) ||
+		!inner.IsApproved(acc.LD().Approver, acc.LD().ApproveList, false) {
+		return nil
+	}
+
+	if _, err := acc.TakeProposal(id); err != nil {
+		return err
+	}
+
+	reserve := new(big.Int).SetUint64(inner.Tx.GasFeeCap)
+	reserve.Add(reserve, new(big.Int).SetUint64(inner.Tx.GasTip))
+	reserve.Mul(reserve, new(big.Int).SetUint64(inner.Gas()))
+	if err := acc.SubGas(reserve); err != nil {
+		return err
+	}
+
+	height := cs.Height() + 1
+	if err := acc.ScheduleProposalTx(inner.ID, &ld.ScheduleEntry{
+		Sender:  acc.ID(),
+		Height:  height,
+		Reserve: reserve,
+		Tx:      inner.Bytes(),
+	}); err != nil {
+		return err
+	}
+	return cs.QueueScheduledTx(inner.ID, acc.ID(), height, 0)
+}
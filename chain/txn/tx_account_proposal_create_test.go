@@ -0,0 +1,349 @@
+// (c) 2022-2022, LDC Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package txn
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ldclabs/ldvm/ids"
+	"github.com/ldclabs/ldvm/ld"
+	"github.com/ldclabs/ldvm/signer"
+	"github.com/ldclabs/ldvm/unit"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTxCreateProposal(t *testing.T) {
+	assert := assert.New(t)
+
+	// SyntacticVerify
+	tx := &TxCreateProposal{}
+	assert.ErrorContains(tx.SyntacticVerify(), "nil pointer")
+	_, err := tx.MarshalJSON()
+	require.NoError(t, err)
+
+	ctx := NewMockChainContext()
+	cs := ctx.MockChainState()
+	sender := signer.Signer1.Key().Address()
+	multisig := ids.Address{1, 2, 3}
+	to := signer.Signer2.Key().Address()
+
+	ltx := &ld.Transaction{Tx: ld.TxData{
+		Type:      ld.TypeCreateProposal,
+		ChainID:   ctx.ChainConfig().ChainID,
+		Nonce:     0,
+		GasTip:    100,
+		GasFeeCap: ctx.Price,
+		From:      sender,
+	}}
+	assert.NoError(ltx.SignWith(signer.Signer1))
+	assert.NoError(ltx.SyntacticVerify())
+	_, err = NewTx(ltx)
+	assert.ErrorContains(err, "nil to as multisig account")
+
+	ltx = &ld.Transaction{Tx: ld.TxData{
+		Type:      ld.TypeCreateProposal,
+		ChainID:   ctx.ChainConfig().ChainID,
+		Nonce:     0,
+		GasTip:    100,
+		GasFeeCap: ctx.Price,
+		From:      sender,
+		To:        multisig.Ptr(),
+		Token:     ids.NativeToken.Ptr(),
+	}}
+	assert.NoError(ltx.SignWith(signer.Signer1))
+	assert.NoError(ltx.SyntacticVerify())
+	_, err = NewTx(ltx)
+	assert.ErrorContains(err, "invalid token, should be nil")
+
+	ltx = &ld.Transaction{Tx: ld.TxData{
+		Type:      ld.TypeCreateProposal,
+		ChainID:   ctx.ChainConfig().ChainID,
+		Nonce:     0,
+		GasTip:    100,
+		GasFeeCap: ctx.Price,
+		From:      sender,
+		To:        multisig.Ptr(),
+		Amount:    big.NewInt(1),
+	}}
+	assert.NoError(ltx.SignWith(signer.Signer1))
+	assert.NoError(ltx.SyntacticVerify())
+	_, err = NewTx(ltx)
+	assert.ErrorContains(err, "invalid amount, should be nil")
+
+	ltx = &ld.Transaction{Tx: ld.TxData{
+		Type:      ld.TypeCreateProposal,
+		ChainID:   ctx.ChainConfig().ChainID,
+		Nonce:     0,
+		GasTip:    100,
+		GasFeeCap: ctx.Price,
+		From:      sender,
+		To:        multisig.Ptr(),
+	}}
+	assert.NoError(ltx.SignWith(signer.Signer1))
+	assert.NoError(ltx.SyntacticVerify())
+	_, err = NewTx(ltx)
+	assert.ErrorContains(err, "invalid data")
+
+	ltx = &ld.Transaction{Tx: ld.TxData{
+		Type:      ld.TypeCreateProposal,
+		ChainID:   ctx.ChainConfig().ChainID,
+		Nonce:     0,
+		GasTip:    100,
+		GasFeeCap: ctx.Price,
+		From:      sender,
+		To:        multisig.Ptr(),
+		Data:      []byte("你好👋"),
+	}}
+	assert.NoError(ltx.SignWith(signer.Signer1))
+	assert.NoError(ltx.SyntacticVerify())
+	_, err = NewTx(ltx)
+	assert.ErrorContains(err, "ld.TxProposal.Unmarshal")
+
+	inner := &ld.Transaction{Tx: ld.TxData{
+		Type:      ld.TypeTransfer,
+		ChainID:   ctx.ChainConfig().ChainID,
+		Nonce:     2000,
+		GasTip:    100,
+		GasFeeCap: ctx.Price,
+		From:      sender,
+		To:        to.Ptr(),
+		Amount:    new(big.Int).SetUint64(unit.LDC),
+	}}
+	assert.NoError(inner.SignWith(signer.Signer1))
+	assert.NoError(inner.SyntacticVerify())
+	input := &ld.TxProposal{Expire: 2000, Tx: inner.Bytes()}
+	assert.NoError(input.SyntacticVerify())
+	ltx = &ld.Transaction{Tx: ld.TxData{
+		Type:      ld.TypeCreateProposal,
+		ChainID:   ctx.ChainConfig().ChainID,
+		Nonce:     0,
+		GasTip:    100,
+		GasFeeCap: ctx.Price,
+		From:      sender,
+		To:        multisig.Ptr(),
+		Data:      input.Bytes(),
+	}}
+	assert.NoError(ltx.SignWith(signer.Signer1))
+	assert.NoError(ltx.SyntacticVerify())
+	_, err = NewTx(ltx)
+	assert.ErrorContains(err,
+		"invalid sender of proposed tx, expected "+multisig.String()+", got "+sender.String())
+
+	inner = &ld.Transaction{Tx: ld.TxData{
+		Type:      ld.TypeTransfer,
+		ChainID:   ctx.ChainConfig().ChainID,
+		Nonce:     2000,
+		GasTip:    100,
+		GasFeeCap: ctx.Price,
+		From:      multisig,
+		To:        to.Ptr(),
+		Amount:    new(big.Int).SetUint64(unit.LDC),
+	}}
+	assert.NoError(inner.SyntacticVerify())
+	input = &ld.TxProposal{Expire: 2000, Tx: inner.Bytes()}
+	assert.NoError(input.SyntacticVerify())
+	ltx = &ld.Transaction{Tx: ld.TxData{
+		Type:      ld.TypeCreateProposal,
+		ChainID:   ctx.ChainConfig().ChainID,
+		Nonce:     0,
+		GasTip:    100,
+		GasFeeCap: ctx.Price,
+		From:      sender,
+		To:        multisig.Ptr(),
+		Data:      input.Bytes(),
+	}}
+	assert.NoError(ltx.SignWith(signer.Signer1))
+	assert.NoError(ltx.SyntacticVerify())
+	_, err = NewTx(ltx)
+	assert.ErrorContains(err, "invalid proposed tx, no signatures")
+
+	assert.NoError(inner.SignWith(signer.Signer1))
+	assert.NoError(inner.SyntacticVerify())
+	input = &ld.TxProposal{Expire: 2000, Tx: inner.Bytes()}
+	assert.NoError(input.SyntacticVerify())
+	ltx = &ld.Transaction{Tx: ld.TxData{
+		Type:      ld.TypeCreateProposal,
+		ChainID:   ctx.ChainConfig().ChainID,
+		Nonce:     0,
+		GasTip:    100,
+		GasFeeCap: ctx.Price,
+		From:      sender,
+		To:        multisig.Ptr(),
+		Data:      input.Bytes(),
+	}}
+	assert.NoError(ltx.SignWith(signer.Signer1))
+	assert.NoError(ltx.SyntacticVerify())
+	_, err = NewTx(ltx)
+	assert.ErrorContains(err, "invalid proposed tx, should be signed for schedule")
+
+	assert.NoError(inner.SignForSchedule(signer.Signer1))
+	assert.NoError(inner.SyntacticVerify())
+	input = &ld.TxProposal{Expire: 2000, Tx: inner.Bytes()}
+	assert.NoError(input.SyntacticVerify())
+	ltx = &ld.Transaction{Tx: ld.TxData{
+		Type:      ld.TypeCreateProposal,
+		ChainID:   ctx.ChainConfig().ChainID,
+		Nonce:     0,
+		GasTip:    100,
+		GasFeeCap: ctx.Price,
+		From:      sender,
+		To:        multisig.Ptr(),
+		Data:      input.Bytes(),
+	}}
+	assert.NoError(ltx.SignWith(signer.Signer1))
+	assert.NoError(ltx.SyntacticVerify())
+	itx, err := NewTx(ltx)
+	require.NoError(t, err)
+
+	cs.CommitAccounts()
+	assert.ErrorContains(itx.Apply(ctx, cs),
+		"insufficient NativeLDC balance, expected 2252800, got 0")
+	cs.CheckoutAccounts()
+
+	senderAcc := cs.MustAccount(sender)
+	senderAcc.Add(ids.NativeToken, new(big.Int).SetUint64(unit.LDC))
+	cs.CommitAccounts()
+	assert.ErrorContains(itx.Apply(ctx, cs), "no keepers")
+	cs.CheckoutAccounts()
+
+	multisigAcc := cs.MustAccount(multisig)
+	multisigAcc.Add(ids.NativeToken, new(big.Int).SetUint64(unit.LDC*3))
+	assert.NoError(multisigAcc.UpdateKeepers(ld.Uint16Ptr(2),
+		&signer.Keys{signer.Signer1.Key(), signer.Signer2.Key()}, nil, nil, nil))
+	assert.NoError(itx.Apply(ctx, cs))
+
+	id := input.ID()
+	senderGas := ltx.Gas()
+	ldcAcc := itx.(*TxCreateProposal).ldc
+	assert.Equal(senderGas*ctx.Price, ldcAcc.Balance().Uint64())
+	assert.Equal(senderGas*100, itx.(*TxCreateProposal).miner.Balance().Uint64())
+	assert.Equal(unit.LDC-senderGas*(ctx.Price+100),
+		senderAcc.BalanceOfAll(ids.NativeToken).Uint64())
+	assert.Equal(uint64(1), senderAcc.Nonce())
+
+	// not approved yet
+	e := multisigAcc.Proposal(id)
+	require.NotNil(t, e)
+	assert.Equal(uint64(2000), e.Expire)
+	assert.Equal(inner.Signatures, e.Sigs)
+	assert.Equal(unit.LDC*3, multisigAcc.BalanceOfAll(ids.NativeToken).Uint64())
+	assert.Equal(uint64(0), multisigAcc.Nonce())
+	assert.Empty(cs.SQ)
+
+	jsondata, err := itx.MarshalJSON()
+	require.NoError(t, err)
+	assert.Contains(string(jsondata), `"type":"TypeCreateProposal"`)
+	assert.Contains(string(jsondata), `"data":{"tx":`)
+
+	ltx = &ld.Transaction{Tx: ld.TxData{
+		Type:      ld.TypeCreateProposal,
+		ChainID:   ctx.ChainConfig().ChainID,
+		Nonce:     1,
+		GasTip:    100,
+		GasFeeCap: ctx.Price,
+		From:      sender,
+		To:        multisig.Ptr(),
+		Data:      input.Bytes(),
+	}}
+	assert.NoError(ltx.SignWith(signer.Signer1))
+	assert.NoError(ltx.SyntacticVerify())
+	itx, err = NewTx(ltx)
+	require.NoError(t, err)
+
+	cs.CommitAccounts()
+	assert.ErrorContains(itx.Apply(ctx, cs), "proposal "+id.String()+" exists")
+	cs.CheckoutAccounts()
+
+	// the proposal signed by enough keepers is scheduled at once,
+	// other txs from the multisig account don't break it
+	multisigAcc.LD().Nonce = 5
+	inner = &ld.Transaction{Tx: ld.TxData{
+		Type:      ld.TypeTransfer,
+		ChainID:   ctx.ChainConfig().ChainID,
+		Nonce:     2000,
+		GasTip:    100,
+		GasFeeCap: ctx.Price,
+		From:      multisig,
+		To:        to.Ptr(),
+		Amount:    new(big.Int).SetUint64(unit.LDC),
+		Data:      []byte(`"hello"`),
+	}}
+	assert.NoError(inner.SignForSchedule(signer.Signer1, signer.Signer2))
+	assert.NoError(inner.SyntacticVerify())
+	input = &ld.TxProposal{Expire: 2000, Tx: inner.Bytes()}
+	assert.NoError(input.SyntacticVerify())
+	ltx = &ld.Transaction{Tx: ld.TxData{
+		Type:      ld.TypeCreateProposal,
+		ChainID:   ctx.ChainConfig().ChainID,
+		Nonce:     1,
+		GasTip:    100,
+		GasFeeCap: ctx.Price,
+		From:      sender,
+		To:        multisig.Ptr(),
+		Data:      input.Bytes(),
+	}}
+	assert.NoError(ltx.SignWith(signer.Signer1))
+	assert.NoError(ltx.SyntacticVerify())
+	itx, err = NewTx(ltx)
+	require.NoError(t, err)
+	assert.NoError(itx.Apply(ctx, cs))
+
+	reserve := inner.Gas() * (ctx.Price + 100)
+	assert.Nil(multisigAcc.Proposal(input.ID()))
+	assert.NotNil(multisigAcc.Proposal(id))
+	assert.Equal(unit.LDC*3-reserve, multisigAcc.BalanceOfAll(ids.NativeToken).Uint64())
+	assert.Equal(uint64(5), multisigAcc.Nonce())
+	assert.Equal(uint64(2), senderAcc.Nonce())
+
+	assert.Equal(&ld.ScheduleEntry{Sender: multisig, Height: 2}, cs.SQ[inner.ID])
+
+	// the fully signed inner tx can't be executed directly
+	ntx, err := NewTx(inner)
+	require.NoError(t, err)
+	cs.CommitAccounts()
+	assert.ErrorContains(ntx.Apply(ctx, cs), "invalid tx, scheduled tx can't be executed directly")
+	cs.CheckoutAccounts()
+
+	// execute the scheduled tx
+	ctx.height++
+	entry, err := multisigAcc.TakeScheduledTx(inner.ID)
+	require.NoError(t, err)
+	assert.Equal(multisig, entry.Sender)
+	assert.Equal(reserve, entry.Reserve.Uint64())
+	assert.NoError(multisigAcc.Add(ids.NativeToken, entry.Reserve))
+
+	stx := &ld.Transaction{}
+	require.NoError(t, stx.Unmarshal(entry.Tx))
+	require.NoError(t, stx.SyntacticVerify())
+	ntx, err = NewScheduledTx(stx)
+	require.NoError(t, err)
+	assert.NoError(ntx.Apply(ctx, cs))
+
+	assert.Equal(unit.LDC*2-reserve, multisigAcc.BalanceOfAll(ids.NativeToken).Uint64())
+	assert.Equal(unit.LDC, cs.MustAccount(to).BalanceOfAll(ids.NativeToken).Uint64())
+	assert.Equal(uint64(5), multisigAcc.Nonce())
+
+	// the executed proposal can't be proposed again before it expires
+	ltx = &ld.Transaction{Tx: ld.TxData{
+		Type:      ld.TypeCreateProposal,
+		ChainID:   ctx.ChainConfig().ChainID,
+		Nonce:     2,
+		GasTip:    100,
+		GasFeeCap: ctx.Price,
+		From:      sender,
+		To:        multisig.Ptr(),
+		Data:      input.Bytes(),
+	}}
+	assert.NoError(ltx.SignWith(signer.Signer1))
+	assert.NoError(ltx.SyntacticVerify())
+	itx, err = NewTx(ltx)
+	require.NoError(t, err)
+	cs.CommitAccounts()
+	assert.ErrorContains(itx.Apply(ctx, cs), "proposal "+input.ID().String()+" exists")
+	cs.CheckoutAccounts()
+
+	assert.NoError(cs.VerifyState())
+}
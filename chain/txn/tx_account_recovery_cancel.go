@@ -0,0 +1,54 @@
+// (c) 2022-2022, LDC Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package txn
+
+import (
+	"github.com/ldclabs/ldvm/util/erring"
+)
+
+type TxCancelRecovery struct {
+	TxBase
+}
+
+func (tx *TxCancelRecovery) SyntacticVerify() error {
+	var err error
+	errp := erring.ErrPrefix("txn.TxCancelRecovery.SyntacticVerify: ")
+
+	if err = tx.TxBase.SyntacticVerify(); err != nil {
+		return errp.ErrorIf(err)
+	}
+
+	switch {
+	case tx.ld.Tx.To != nil:
+		return errp.Errorf("invalid to, should be nil")
+
+	case tx.ld.Tx.Token != nil:
+		return errp.Errorf("invalid token, should be nil")
+
+	case tx.ld.Tx.Amount != nil:
+		return errp.Errorf("invalid amount, should be nil")
+
+	case tx.ld.Tx.Data != nil:
+		return errp.Errorf("invalid data, should be nil")
+	}
+	return nil
+}
+
+func (tx *TxCancelRecovery) Apply(ctx ChainContext, cs ChainState) error {
+	var err error
+	errp := erring.ErrPrefix("txn.TxCancelRecovery.Apply: ")
+
+	if err = tx.TxBase.verify(ctx, cs); err != nil {
+		return errp.ErrorIf(err)
+	}
+
+	if err = cs.LoadLedger(tx.from); err != nil {
+		return errp.ErrorIf(err)
+	}
+
+	if err = tx.from.CancelRecovery(); err != nil {
+		return errp.ErrorIf(err)
+	}
+	return errp.ErrorIf(tx.TxBase.accept(ctx, cs))
+}
@@ -0,0 +1,114 @@
+// (c) 2022-2022, LDC Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package txn
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ldclabs/ldvm/ids"
+	"github.com/ldclabs/ldvm/ld"
+	"github.com/ldclabs/ldvm/signer"
+	"github.com/ldclabs/ldvm/unit"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTxCancelRecovery(t *testing.T) {
+	assert := assert.New(t)
+
+	// SyntacticVerify
+	tx := &TxCancelRecovery{}
+	assert.ErrorContains(tx.SyntacticVerify(), "nil pointer")
+	_, err := tx.MarshalJSON()
+	require.NoError(t, err)
+
+	ctx := NewMockChainContext()
+	cs := ctx.MockChainState()
+	sender := signer.Signer1.Key().Address()
+
+	ltx := &ld.Transaction{Tx: ld.TxData{
+		Type:      ld.TypeCancelRecovery,
+		ChainID:   ctx.ChainConfig().ChainID,
+		Nonce:     0,
+		GasTip:    100,
+		GasFeeCap: ctx.Price,
+		From:      sender,
+		To:        ids.GenesisAccount.Ptr(),
+	}}
+	assert.NoError(ltx.SignWith(signer.Signer1))
+	assert.NoError(ltx.SyntacticVerify())
+	_, err = NewTx(ltx)
+	assert.ErrorContains(err, "invalid to, should be nil")
+
+	ltx = &ld.Transaction{Tx: ld.TxData{
+		Type:      ld.TypeCancelRecovery,
+		ChainID:   ctx.ChainConfig().ChainID,
+		Nonce:     0,
+		GasTip:    100,
+		GasFeeCap: ctx.Price,
+		From:      sender,
+		Data:      []byte{0x80},
+	}}
+	assert.NoError(ltx.SignWith(signer.Signer1))
+	assert.NoError(ltx.SyntacticVerify())
+	_, err = NewTx(ltx)
+	assert.ErrorContains(err, "invalid data, should be nil")
+
+	ltx = &ld.Transaction{Tx: ld.TxData{
+		Type:      ld.TypeCancelRecovery,
+		ChainID:   ctx.ChainConfig().ChainID,
+		Nonce:     0,
+		GasTip:    100,
+		GasFeeCap: ctx.Price,
+		From:      sender,
+	}}
+	assert.NoError(ltx.SignWith(signer.Signer1))
+	assert.NoError(ltx.SyntacticVerify())
+	itx, err := NewTx(ltx)
+	require.NoError(t, err)
+
+	cs.CommitAccounts()
+	assert.ErrorContains(itx.Apply(ctx, cs),
+		"insufficient NativeLDC balance, expected 720500, got 0")
+	cs.CheckoutAccounts()
+
+	senderAcc := cs.MustAccount(sender)
+	senderAcc.Add(ids.NativeToken, new(big.Int).SetUint64(unit.LDC))
+	cs.CommitAccounts()
+	assert.ErrorContains(itx.Apply(ctx, cs), "no pending recovery")
+	cs.CheckoutAccounts()
+
+	require.NoError(t, cs.LoadLedger(senderAcc))
+	assert.NoError(senderAcc.SetRecovery(&ld.TxRecovery{
+		Threshold: 1,
+		Guardians: signer.Keys{signer.Signer2.Key()},
+		Delay:     3600,
+	}))
+	cs.CommitAccounts()
+	assert.ErrorContains(itx.Apply(ctx, cs), "no pending recovery")
+	cs.CheckoutAccounts()
+
+	assert.NoError(senderAcc.StartRecovery(1, signer.Keys{signer.Signer3.Key()}))
+	assert.NoError(itx.Apply(ctx, cs))
+
+	senderGas := ltx.Gas()
+	assert.Equal(senderGas*ctx.Price,
+		itx.(*TxCancelRecovery).ldc.Balance().Uint64())
+	assert.Equal(senderGas*100,
+		itx.(*TxCancelRecovery).miner.Balance().Uint64())
+	assert.Equal(unit.LDC-senderGas*(ctx.Price+100),
+		senderAcc.BalanceOfAll(ids.NativeToken).Uint64())
+	assert.Equal(uint64(1), senderAcc.Nonce())
+
+	entry, err := senderAcc.Recovery()
+	require.NoError(t, err)
+	assert.Nil(entry.Pending)
+
+	jsondata, err := itx.MarshalJSON()
+	require.NoError(t, err)
+	assert.Contains(string(jsondata), `"type":"TypeCancelRecovery"`)
+
+	assert.NoError(cs.VerifyState())
+}
@@ -0,0 +1,55 @@
+// (c) 2022-2022, LDC Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package txn
+
+import (
+	"github.com/ldclabs/ldvm/util/erring"
+)
+
+type TxExecuteRecovery struct {
+	TxBase
+}
+
+// TxExecuteRecovery{To: account}, anyone can execute it after the recovery delay.
+func (tx *TxExecuteRecovery) SyntacticVerify() error {
+	var err error
+	errp := erring.ErrPrefix("txn.TxExecuteRecovery.SyntacticVerify: ")
+
+	if err = tx.TxBase.SyntacticVerify(); err != nil {
+		return errp.ErrorIf(err)
+	}
+
+	switch {
+	case tx.ld.Tx.To == nil:
+		return errp.Errorf("nil to as account")
+
+	case tx.ld.Tx.Token != nil:
+		return errp.Errorf("invalid token, should be nil")
+
+	case tx.ld.Tx.Amount != nil:
+		return errp.Errorf("invalid amount, should be nil")
+
+	case tx.ld.Tx.Data != nil:
+		return errp.Errorf("invalid data, should be nil")
+	}
+	return nil
+}
+
+func (tx *TxExecuteRecovery) Apply(ctx ChainContext, cs ChainState) error {
+	var err error
+	errp := erring.ErrPrefix("txn.TxExecuteRecovery.Apply: ")
+
+	if err = tx.TxBase.verify(ctx, cs); err != nil {
+		return errp.ErrorIf(err)
+	}
+
+	if err = cs.LoadLedger(tx.to); err != nil {
+		return errp.ErrorIf(err)
+	}
+
+	if err = tx.to.ExecuteRecovery(); err != nil {
+		return errp.ErrorIf(err)
+	}
+	return errp.ErrorIf(tx.TxBase.accept(ctx, cs))
+}
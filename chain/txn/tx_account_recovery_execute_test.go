@@ -0,0 +1,138 @@
+// (c) 2022-2022, LDC Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package txn
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ldclabs/ldvm/ids"
+	"github.com/ldclabs/ldvm/ld"
+	"github.com/ldclabs/ldvm/signer"
+	"github.com/ldclabs/ldvm/unit"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTxExecuteRecovery(t *testing.T) {
+	assert := assert.New(t)
+
+	// SyntacticVerify
+	tx := &TxExecuteRecovery{}
+	assert.ErrorContains(tx.SyntacticVerify(), "nil pointer")
+	_, err := tx.MarshalJSON()
+	require.NoError(t, err)
+
+	ctx := NewMockChainContext()
+	cs := ctx.MockChainState()
+	account := signer.Signer1.Key().Address()
+	sender := signer.Signer2.Key().Address()
+
+	ltx := &ld.Transaction{Tx: ld.TxData{
+		Type:      ld.TypeExecuteRecovery,
+		ChainID:   ctx.ChainConfig().ChainID,
+		Nonce:     0,
+		GasTip:    100,
+		GasFeeCap: ctx.Price,
+		From:      sender,
+	}}
+	assert.NoError(ltx.SignWith(signer.Signer2))
+	assert.NoError(ltx.SyntacticVerify())
+	_, err = NewTx(ltx)
+	assert.ErrorContains(err, "nil to as account")
+
+	ltx = &ld.Transaction{Tx: ld.TxData{
+		Type:      ld.TypeExecuteRecovery,
+		ChainID:   ctx.ChainConfig().ChainID,
+		Nonce:     0,
+		GasTip:    100,
+		GasFeeCap: ctx.Price,
+		From:      sender,
+		To:        &account,
+		Amount:    big.NewInt(1),
+	}}
+	assert.NoError(ltx.SignWith(signer.Signer2))
+	assert.NoError(ltx.SyntacticVerify())
+	_, err = NewTx(ltx)
+	assert.ErrorContains(err, "invalid amount, should be nil")
+
+	ltx = &ld.Transaction{Tx: ld.TxData{
+		Type:      ld.TypeExecuteRecovery,
+		ChainID:   ctx.ChainConfig().ChainID,
+		Nonce:     0,
+		GasTip:    100,
+		GasFeeCap: ctx.Price,
+		From:      sender,
+		To:        &account,
+		Data:      []byte{0x80},
+	}}
+	assert.NoError(ltx.SignWith(signer.Signer2))
+	assert.NoError(ltx.SyntacticVerify())
+	_, err = NewTx(ltx)
+	assert.ErrorContains(err, "invalid data, should be nil")
+
+	ltx = &ld.Transaction{Tx: ld.TxData{
+		Type:      ld.TypeExecuteRecovery,
+		ChainID:   ctx.ChainConfig().ChainID,
+		Nonce:     0,
+		GasTip:    100,
+		GasFeeCap: ctx.Price,
+		From:      sender,
+		To:        &account,
+	}}
+	assert.NoError(ltx.SignWith(signer.Signer2))
+	assert.NoError(ltx.SyntacticVerify())
+	itx, err := NewTx(ltx)
+	require.NoError(t, err)
+
+	cs.CommitAccounts()
+	assert.ErrorContains(itx.Apply(ctx, cs),
+		"insufficient NativeLDC balance, expected 848100, got 0")
+	cs.CheckoutAccounts()
+
+	senderAcc := cs.MustAccount(sender)
+	senderAcc.Add(ids.NativeToken, new(big.Int).SetUint64(unit.LDC))
+	cs.CommitAccounts()
+	assert.ErrorContains(itx.Apply(ctx, cs), "no pending recovery")
+	cs.CheckoutAccounts()
+
+	accountAcc := cs.MustAccount(account)
+	require.NoError(t, cs.LoadLedger(accountAcc))
+	assert.NoError(accountAcc.SetRecovery(&ld.TxRecovery{
+		Threshold: 1,
+		Guardians: signer.Keys{signer.Signer2.Key()},
+		Delay:     3600,
+	}))
+	assert.NoError(accountAcc.StartRecovery(1, signer.Keys{signer.Signer3.Key()}))
+	cs.CommitAccounts()
+	assert.ErrorContains(itx.Apply(ctx, cs),
+		"recovery is locked until 4600, current time 1000")
+	cs.CheckoutAccounts()
+
+	accountAcc.Init(ctx.cfg.FeeConfig.NonTransferableBalance, big.NewInt(0),
+		cs.Height(), cs.Timestamp()+3600)
+	assert.NoError(itx.Apply(ctx, cs))
+
+	senderGas := ltx.Gas()
+	assert.Equal(senderGas*ctx.Price,
+		itx.(*TxExecuteRecovery).ldc.Balance().Uint64())
+	assert.Equal(senderGas*100,
+		itx.(*TxExecuteRecovery).miner.Balance().Uint64())
+	assert.Equal(unit.LDC-senderGas*(ctx.Price+100),
+		senderAcc.BalanceOfAll(ids.NativeToken).Uint64())
+	assert.Equal(uint64(1), senderAcc.Nonce())
+	assert.Equal(uint64(0), accountAcc.Nonce())
+	assert.Equal(uint16(1), accountAcc.Threshold())
+	assert.Equal(signer.Keys{signer.Signer3.Key()}, accountAcc.Keepers())
+
+	entry, err := accountAcc.Recovery()
+	require.NoError(t, err)
+	assert.Nil(entry.Pending)
+
+	jsondata, err := itx.MarshalJSON()
+	require.NoError(t, err)
+	assert.Contains(string(jsondata), `"type":"TypeExecuteRecovery"`)
+
+	assert.NoError(cs.VerifyState())
+}
@@ -0,0 +1,89 @@
+// (c) 2022-2022, LDC Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package txn
+
+import (
+	"encoding/json"
+
+	"github.com/ldclabs/ldvm/ld"
+	"github.com/ldclabs/ldvm/util/erring"
+)
+
+type TxSetRecovery struct {
+	TxBase
+	input *ld.TxRecovery
+}
+
+func (tx *TxSetRecovery) MarshalJSON() ([]byte, error) {
+	if tx == nil || tx.ld == nil {
+		return []byte("null"), nil
+	}
+
+	v := tx.ld.Copy()
+	errp := erring.ErrPrefix("txn.TxSetRecovery.MarshalJSON: ")
+	if tx.input == nil {
+		return nil, errp.Errorf("nil tx.input")
+	}
+	d, err := json.Marshal(tx.input)
+	if err != nil {
+		return nil, errp.ErrorIf(err)
+	}
+	v.Tx.Data = d
+	return errp.ErrorMap(json.Marshal(v))
+}
+
+func (tx *TxSetRecovery) SyntacticVerify() error {
+	var err error
+	errp := erring.ErrPrefix("txn.TxSetRecovery.SyntacticVerify: ")
+
+	if err = tx.TxBase.SyntacticVerify(); err != nil {
+		return errp.ErrorIf(err)
+	}
+
+	switch {
+	case tx.ld.Tx.To != nil:
+		return errp.Errorf("invalid to, should be nil")
+
+	case tx.ld.Tx.Token != nil:
+		return errp.Errorf("invalid token, should be nil")
+
+	case tx.ld.Tx.Amount != nil:
+		return errp.Errorf("invalid amount, should be nil")
+
+	case len(tx.ld.Tx.Data) == 0:
+		return errp.Errorf("invalid data")
+	}
+
+	tx.input = &ld.TxRecovery{}
+	if err = tx.input.Unmarshal(tx.ld.Tx.Data); err != nil {
+		return errp.ErrorIf(err)
+	}
+	if err = tx.input.SyntacticVerify(); err != nil {
+		return errp.ErrorIf(err)
+	}
+	return nil
+}
+
+func (tx *TxSetRecovery) Apply(ctx ChainContext, cs ChainState) error {
+	var err error
+	errp := erring.ErrPrefix("txn.TxSetRecovery.Apply: ")
+
+	if err = tx.TxBase.verify(ctx, cs); err != nil {
+		return errp.ErrorIf(err)
+	}
+
+	// guardians can replace the keepers, so it requires the same signatures as updating keepers
+	if !tx.from.VerifyPlus(tx.ld.SigHash(), tx.ld.Signatures, tx.senderKey) {
+		return errp.Errorf("invalid signatures for keepers")
+	}
+
+	if err = cs.LoadLedger(tx.from); err != nil {
+		return errp.ErrorIf(err)
+	}
+
+	if err = tx.from.SetRecovery(tx.input); err != nil {
+		return errp.ErrorIf(err)
+	}
+	return errp.ErrorIf(tx.TxBase.accept(ctx, cs))
+}
@@ -0,0 +1,186 @@
+// (c) 2022-2022, LDC Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package txn
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ldclabs/ldvm/ids"
+	"github.com/ldclabs/ldvm/ld"
+	"github.com/ldclabs/ldvm/signer"
+	"github.com/ldclabs/ldvm/unit"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTxSetRecovery(t *testing.T) {
+	assert := assert.New(t)
+
+	// SyntacticVerify
+	tx := &TxSetRecovery{}
+	assert.ErrorContains(tx.SyntacticVerify(), "nil pointer")
+	_, err := tx.MarshalJSON()
+	require.NoError(t, err)
+
+	ctx := NewMockChainContext()
+	cs := ctx.MockChainState()
+	sender := signer.Signer1.Key().Address()
+
+	ltx := &ld.Transaction{Tx: ld.TxData{
+		Type:      ld.TypeSetRecovery,
+		ChainID:   ctx.ChainConfig().ChainID,
+		Nonce:     0,
+		GasTip:    100,
+		GasFeeCap: ctx.Price,
+		From:      sender,
+		To:        ids.GenesisAccount.Ptr(),
+	}}
+	assert.NoError(ltx.SignWith(signer.Signer1))
+	assert.NoError(ltx.SyntacticVerify())
+	_, err = NewTx(ltx)
+	assert.ErrorContains(err, "invalid to, should be nil")
+
+	ltx = &ld.Transaction{Tx: ld.TxData{
+		Type:      ld.TypeSetRecovery,
+		ChainID:   ctx.ChainConfig().ChainID,
+		Nonce:     0,
+		GasTip:    100,
+		GasFeeCap: ctx.Price,
+		From:      sender,
+		Token:     ld.MustNewToken("$LDC").Ptr(),
+	}}
+	assert.NoError(ltx.SignWith(signer.Signer1))
+	assert.NoError(ltx.SyntacticVerify())
+	_, err = NewTx(ltx)
+	assert.ErrorContains(err, "invalid token, should be nil")
+
+	ltx = &ld.Transaction{Tx: ld.TxData{
+		Type:      ld.TypeSetRecovery,
+		ChainID:   ctx.ChainConfig().ChainID,
+		Nonce:     0,
+		GasTip:    100,
+		GasFeeCap: ctx.Price,
+		From:      sender,
+	}}
+	assert.NoError(ltx.SignWith(signer.Signer1))
+	assert.NoError(ltx.SyntacticVerify())
+	_, err = NewTx(ltx)
+	assert.ErrorContains(err, "invalid data")
+
+	ltx = &ld.Transaction{Tx: ld.TxData{
+		Type:      ld.TypeSetRecovery,
+		ChainID:   ctx.ChainConfig().ChainID,
+		Nonce:     0,
+		GasTip:    100,
+		GasFeeCap: ctx.Price,
+		From:      sender,
+		Data:      []byte("d"),
+	}}
+	assert.NoError(ltx.SignWith(signer.Signer1))
+	assert.NoError(ltx.SyntacticVerify())
+	_, err = NewTx(ltx)
+	assert.ErrorContains(err, "unexpected EOF")
+
+	input := &ld.TxRecovery{Guardians: signer.Keys{signer.Signer2.Key()}}
+	ltx = &ld.Transaction{Tx: ld.TxData{
+		Type:      ld.TypeSetRecovery,
+		ChainID:   ctx.ChainConfig().ChainID,
+		Nonce:     0,
+		GasTip:    100,
+		GasFeeCap: ctx.Price,
+		From:      sender,
+		Data:      ld.MustMarshal(input),
+	}}
+	assert.NoError(ltx.SignWith(signer.Signer1))
+	assert.NoError(ltx.SyntacticVerify())
+	_, err = NewTx(ltx)
+	assert.ErrorContains(err, "invalid threshold, expected in [1, 1], got 0")
+
+	input = &ld.TxRecovery{
+		Threshold: 1,
+		Guardians: signer.Keys{signer.Signer2.Key(), signer.Signer3.Key()},
+		Delay:     3600,
+	}
+	ltx = &ld.Transaction{Tx: ld.TxData{
+		Type:      ld.TypeSetRecovery,
+		ChainID:   ctx.ChainConfig().ChainID,
+		Nonce:     0,
+		GasTip:    100,
+		GasFeeCap: ctx.Price,
+		From:      sender,
+		Data:      ld.MustMarshal(input),
+	}}
+	assert.NoError(ltx.SignWith(signer.Signer1))
+	assert.NoError(ltx.SyntacticVerify())
+	itx, err := NewTx(ltx)
+	require.NoError(t, err)
+
+	cs.CommitAccounts()
+	assert.ErrorContains(itx.Apply(ctx, cs),
+		"insufficient NativeLDC balance, expected 1461900, got 0")
+	cs.CheckoutAccounts()
+
+	senderAcc := cs.MustAccount(sender)
+	senderAcc.Add(ids.NativeToken, new(big.Int).SetUint64(unit.LDC))
+	assert.NoError(senderAcc.UpdateKeepers(ld.Uint16Ptr(1),
+		&signer.Keys{signer.Signer1.Key(), signer.Signer4.Key()}, nil, nil, nil))
+	cs.CommitAccounts()
+	assert.ErrorContains(itx.Apply(ctx, cs), "invalid signatures for keepers")
+	cs.CheckoutAccounts()
+
+	assert.NoError(ltx.SignWith(signer.Signer1, signer.Signer4))
+	itx, err = NewTx(ltx)
+	require.NoError(t, err)
+	assert.NoError(itx.Apply(ctx, cs))
+
+	senderGas := ltx.Gas()
+	assert.Equal(senderGas*ctx.Price,
+		itx.(*TxSetRecovery).ldc.Balance().Uint64())
+	assert.Equal(senderGas*100,
+		itx.(*TxSetRecovery).miner.Balance().Uint64())
+	assert.Equal(unit.LDC-senderGas*(ctx.Price+100),
+		senderAcc.BalanceOfAll(ids.NativeToken).Uint64())
+	assert.Equal(uint64(1), senderAcc.Nonce())
+
+	entry, err := senderAcc.Recovery()
+	require.NoError(t, err)
+	assert.Equal(uint16(1), entry.Threshold)
+	assert.Equal(input.Guardians, entry.Guardians)
+	assert.Equal(uint64(3600), entry.Delay)
+	assert.Nil(entry.Pending)
+
+	jsondata, err := itx.MarshalJSON()
+	require.NoError(t, err)
+	assert.Contains(string(jsondata), `"type":"TypeSetRecovery"`)
+	assert.Contains(string(jsondata), `"data":{"threshold":1,"guardians":["RBccN_9de3u43K1cgfFihKIp5kE1lmGG","OVlX-75gy0DuaRuz2k5QnlFVSuKOJezRd4CQdkIjkn5pYt0F"],"delay":3600}`)
+
+	// token account can opt in
+	token := ld.MustNewToken("$TEST")
+	tokenAcc := cs.MustAccount(ids.Address(token))
+	assert.NoError(tokenAcc.CreateToken(&ld.TxAccounter{
+		Threshold: ld.Uint16Ptr(1),
+		Keepers:   &signer.Keys{signer.Signer1.Key()},
+		Amount:    new(big.Int).SetUint64(unit.LDC),
+	}))
+	tokenAcc.Add(ids.NativeToken, new(big.Int).SetUint64(unit.LDC))
+	ltx = &ld.Transaction{Tx: ld.TxData{
+		Type:      ld.TypeSetRecovery,
+		ChainID:   ctx.ChainConfig().ChainID,
+		Nonce:     0,
+		GasTip:    100,
+		GasFeeCap: ctx.Price,
+		From:      tokenAcc.ID(),
+		Data:      ld.MustMarshal(input),
+	}}
+	assert.NoError(ltx.SignWith(signer.Signer1))
+	assert.NoError(ltx.SyntacticVerify())
+	itx, err = NewTx(ltx)
+	require.NoError(t, err)
+	assert.NoError(itx.Apply(ctx, cs))
+	_, err = tokenAcc.Recovery()
+	require.NoError(t, err)
+
+	assert.NoError(cs.VerifyState())
+}
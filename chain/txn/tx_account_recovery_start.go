@@ -0,0 +1,110 @@
+// (c) 2022-2022, LDC Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package txn
+
+import (
+	"encoding/json"
+
+	"github.com/ldclabs/ldvm/ld"
+	"github.com/ldclabs/ldvm/util/erring"
+)
+
+type TxStartRecovery struct {
+	TxBase
+	input *ld.TxAccounter
+}
+
+func (tx *TxStartRecovery) MarshalJSON() ([]byte, error) {
+	if tx == nil || tx.ld == nil {
+		return []byte("null"), nil
+	}
+
+	v := tx.ld.Copy()
+	errp := erring.ErrPrefix("txn.TxStartRecovery.MarshalJSON: ")
+	if tx.input == nil {
+		return nil, errp.Errorf("nil tx.input")
+	}
+	d, err := json.Marshal(tx.input)
+	if err != nil {
+		return nil, errp.ErrorIf(err)
+	}
+	v.Tx.Data = d
+	return errp.ErrorMap(json.Marshal(v))
+}
+
+// TxStartRecovery{To: account, Data: {Threshold, Keepers}}, signed by the guardians with ExSignatures.
+func (tx *TxStartRecovery) SyntacticVerify() error {
+	var err error
+	errp := erring.ErrPrefix("txn.TxStartRecovery.SyntacticVerify: ")
+
+	if err = tx.TxBase.SyntacticVerify(); err != nil {
+		return errp.ErrorIf(err)
+	}
+
+	switch {
+	case tx.ld.Tx.To == nil:
+		return errp.Errorf("nil to as account")
+
+	case tx.ld.Tx.Token != nil:
+		return errp.Errorf("invalid token, should be nil")
+
+	case tx.ld.Tx.Amount != nil:
+		return errp.Errorf("invalid amount, should be nil")
+
+	case len(tx.ld.Tx.Data) == 0:
+		return errp.Errorf("invalid data")
+
+	case len(tx.ld.ExSignatures) == 0:
+		return errp.Errorf("no exSignatures")
+	}
+
+	tx.input = &ld.TxAccounter{}
+	if err = tx.input.Unmarshal(tx.ld.Tx.Data); err != nil {
+		return errp.ErrorIf(err)
+	}
+	if err = tx.input.SyntacticVerify(); err != nil {
+		return errp.ErrorIf(err)
+	}
+
+	switch {
+	case tx.input.Approver != nil || tx.input.ApproveList != nil || tx.input.SessionKeys != nil ||
+		tx.input.Amount != nil || tx.input.SupplyCap != nil ||
+		tx.input.TokenInfo != nil || tx.input.Name != "" || tx.input.Data != nil:
+		return errp.Errorf("invalid data, only threshold and keepers are allowed")
+
+	case tx.input.Threshold == nil:
+		return errp.Errorf("nil keepers")
+
+	case *tx.input.Threshold == 0:
+		return errp.Errorf("invalid threshold, expected >= 1")
+	}
+	return nil
+}
+
+func (tx *TxStartRecovery) Apply(ctx ChainContext, cs ChainState) error {
+	var err error
+	errp := erring.ErrPrefix("txn.TxStartRecovery.Apply: ")
+
+	if err = tx.TxBase.verify(ctx, cs); err != nil {
+		return errp.ErrorIf(err)
+	}
+
+	if err = cs.LoadLedger(tx.to); err != nil {
+		return errp.ErrorIf(err)
+	}
+
+	entry, err := tx.to.Recovery()
+	if err != nil {
+		return errp.ErrorIf(err)
+	}
+
+	if !entry.Guardians.Verify(tx.ld.ExHash(), tx.ld.ExSignatures, entry.Threshold) {
+		return errp.Errorf("invalid exSignatures for guardians")
+	}
+
+	if err = tx.to.StartRecovery(*tx.input.Threshold, *tx.input.Keepers); err != nil {
+		return errp.ErrorIf(err)
+	}
+	return errp.ErrorIf(tx.TxBase.accept(ctx, cs))
+}
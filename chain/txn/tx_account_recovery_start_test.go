@@ -0,0 +1,248 @@
+// (c) 2022-2022, LDC Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package txn
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ldclabs/ldvm/ids"
+	"github.com/ldclabs/ldvm/ld"
+	"github.com/ldclabs/ldvm/signer"
+	"github.com/ldclabs/ldvm/unit"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTxStartRecovery(t *testing.T) {
+	assert := assert.New(t)
+
+	// SyntacticVerify
+	tx := &TxStartRecovery{}
+	assert.ErrorContains(tx.SyntacticVerify(), "nil pointer")
+	_, err := tx.MarshalJSON()
+	require.NoError(t, err)
+
+	ctx := NewMockChainContext()
+	cs := ctx.MockChainState()
+	account := signer.Signer1.Key().Address()
+	guardian := signer.Signer2.Key().Address()
+
+	ltx := &ld.Transaction{Tx: ld.TxData{
+		Type:      ld.TypeStartRecovery,
+		ChainID:   ctx.ChainConfig().ChainID,
+		Nonce:     0,
+		GasTip:    100,
+		GasFeeCap: ctx.Price,
+		From:      guardian,
+	}}
+	assert.NoError(ltx.SignWith(signer.Signer2))
+	assert.NoError(ltx.SyntacticVerify())
+	_, err = NewTx(ltx)
+	assert.ErrorContains(err, "nil to as account")
+
+	ltx = &ld.Transaction{Tx: ld.TxData{
+		Type:      ld.TypeStartRecovery,
+		ChainID:   ctx.ChainConfig().ChainID,
+		Nonce:     0,
+		GasTip:    100,
+		GasFeeCap: ctx.Price,
+		From:      guardian,
+		To:        &account,
+		Amount:    big.NewInt(1),
+	}}
+	assert.NoError(ltx.SignWith(signer.Signer2))
+	assert.NoError(ltx.SyntacticVerify())
+	_, err = NewTx(ltx)
+	assert.ErrorContains(err, "invalid amount, should be nil")
+
+	ltx = &ld.Transaction{Tx: ld.TxData{
+		Type:      ld.TypeStartRecovery,
+		ChainID:   ctx.ChainConfig().ChainID,
+		Nonce:     0,
+		GasTip:    100,
+		GasFeeCap: ctx.Price,
+		From:      guardian,
+		To:        &account,
+	}}
+	assert.NoError(ltx.SignWith(signer.Signer2))
+	assert.NoError(ltx.SyntacticVerify())
+	_, err = NewTx(ltx)
+	assert.ErrorContains(err, "invalid data")
+
+	input := &ld.TxAccounter{}
+	ltx = &ld.Transaction{Tx: ld.TxData{
+		Type:      ld.TypeStartRecovery,
+		ChainID:   ctx.ChainConfig().ChainID,
+		Nonce:     0,
+		GasTip:    100,
+		GasFeeCap: ctx.Price,
+		From:      guardian,
+		To:        &account,
+		Data:      ld.MustMarshal(input),
+	}}
+	assert.NoError(ltx.SignWith(signer.Signer2))
+	assert.NoError(ltx.SyntacticVerify())
+	_, err = NewTx(ltx)
+	assert.ErrorContains(err, "no exSignatures")
+
+	ltx = &ld.Transaction{Tx: ld.TxData{
+		Type:      ld.TypeStartRecovery,
+		ChainID:   ctx.ChainConfig().ChainID,
+		Nonce:     0,
+		GasTip:    100,
+		GasFeeCap: ctx.Price,
+		From:      guardian,
+		To:        &account,
+		Data:      ld.MustMarshal(input),
+	}}
+	assert.NoError(ltx.SignWith(signer.Signer2))
+	assert.NoError(ltx.ExSignWith(signer.Signer2))
+	assert.NoError(ltx.SyntacticVerify())
+	_, err = NewTx(ltx)
+	assert.ErrorContains(err, "nil keepers")
+
+	input = &ld.TxAccounter{
+		Threshold: ld.Uint16Ptr(1),
+		Keepers:   &signer.Keys{signer.Signer4.Key()},
+		Amount:    big.NewInt(1),
+	}
+	ltx = &ld.Transaction{Tx: ld.TxData{
+		Type:      ld.TypeStartRecovery,
+		ChainID:   ctx.ChainConfig().ChainID,
+		Nonce:     0,
+		GasTip:    100,
+		GasFeeCap: ctx.Price,
+		From:      guardian,
+		To:        &account,
+		Data:      ld.MustMarshal(input),
+	}}
+	assert.NoError(ltx.SignWith(signer.Signer2))
+	assert.NoError(ltx.ExSignWith(signer.Signer2))
+	assert.NoError(ltx.SyntacticVerify())
+	_, err = NewTx(ltx)
+	assert.ErrorContains(err, "invalid data, only threshold and keepers are allowed")
+
+	input = &ld.TxAccounter{
+		Threshold: ld.Uint16Ptr(0),
+		Keepers:   &signer.Keys{signer.Signer4.Key()},
+	}
+	ltx = &ld.Transaction{Tx: ld.TxData{
+		Type:      ld.TypeStartRecovery,
+		ChainID:   ctx.ChainConfig().ChainID,
+		Nonce:     0,
+		GasTip:    100,
+		GasFeeCap: ctx.Price,
+		From:      guardian,
+		To:        &account,
+		Data:      ld.MustMarshal(input),
+	}}
+	assert.NoError(ltx.SignWith(signer.Signer2))
+	assert.NoError(ltx.ExSignWith(signer.Signer2))
+	assert.NoError(ltx.SyntacticVerify())
+	_, err = NewTx(ltx)
+	assert.ErrorContains(err, "invalid threshold, expected >= 1")
+
+	input = &ld.TxAccounter{
+		Threshold: ld.Uint16Ptr(1),
+		Keepers:   &signer.Keys{signer.Signer4.Key()},
+	}
+	ltx = &ld.Transaction{Tx: ld.TxData{
+		Type:      ld.TypeStartRecovery,
+		ChainID:   ctx.ChainConfig().ChainID,
+		Nonce:     0,
+		GasTip:    100,
+		GasFeeCap: ctx.Price,
+		From:      guardian,
+		To:        &account,
+		Data:      ld.MustMarshal(input),
+	}}
+	assert.NoError(ltx.SignWith(signer.Signer2))
+	assert.NoError(ltx.ExSignWith(signer.Signer2))
+	assert.NoError(ltx.SyntacticVerify())
+	itx, err := NewTx(ltx)
+	require.NoError(t, err)
+
+	cs.CommitAccounts()
+	assert.ErrorContains(itx.Apply(ctx, cs),
+		"insufficient NativeLDC balance, expected 1652200, got 0")
+	cs.CheckoutAccounts()
+
+	guardianAcc := cs.MustAccount(guardian)
+	guardianAcc.Add(ids.NativeToken, new(big.Int).SetUint64(unit.LDC))
+	cs.CommitAccounts()
+	assert.ErrorContains(itx.Apply(ctx, cs), "no recovery config")
+	cs.CheckoutAccounts()
+
+	accountAcc := cs.MustAccount(account)
+	require.NoError(t, cs.LoadLedger(accountAcc))
+	assert.NoError(accountAcc.SetRecovery(&ld.TxRecovery{
+		Threshold: 2,
+		Guardians: signer.Keys{signer.Signer2.Key(), signer.Signer3.Key()},
+		Delay:     3600,
+	}))
+	cs.CommitAccounts()
+	assert.ErrorContains(itx.Apply(ctx, cs), "invalid exSignatures for guardians")
+	cs.CheckoutAccounts()
+
+	ltx = &ld.Transaction{Tx: ld.TxData{
+		Type:      ld.TypeStartRecovery,
+		ChainID:   ctx.ChainConfig().ChainID,
+		Nonce:     0,
+		GasTip:    100,
+		GasFeeCap: ctx.Price,
+		From:      guardian,
+		To:        &account,
+		Data:      ld.MustMarshal(input),
+	}}
+	assert.NoError(ltx.SignWith(signer.Signer2))
+	assert.NoError(ltx.ExSignWith(signer.Signer2, signer.Signer3))
+	assert.NoError(ltx.SyntacticVerify())
+	itx, err = NewTx(ltx)
+	require.NoError(t, err)
+	assert.NoError(itx.Apply(ctx, cs))
+
+	guardianGas := ltx.Gas()
+	assert.Equal(guardianGas*ctx.Price,
+		itx.(*TxStartRecovery).ldc.Balance().Uint64())
+	assert.Equal(guardianGas*100,
+		itx.(*TxStartRecovery).miner.Balance().Uint64())
+	assert.Equal(unit.LDC-guardianGas*(ctx.Price+100),
+		guardianAcc.BalanceOfAll(ids.NativeToken).Uint64())
+	assert.Equal(uint64(1), guardianAcc.Nonce())
+	assert.Equal(uint64(0), accountAcc.Nonce())
+
+	entry, err := accountAcc.Recovery()
+	require.NoError(t, err)
+	require.NotNil(t, entry.Pending)
+	assert.Equal(uint16(1), entry.Pending.Threshold)
+	assert.Equal(signer.Keys{signer.Signer4.Key()}, entry.Pending.Keepers)
+	assert.Equal(cs.Timestamp()+3600, entry.Pending.ExecuteAt)
+
+	jsondata, err := itx.MarshalJSON()
+	require.NoError(t, err)
+	assert.Contains(string(jsondata), `"type":"TypeStartRecovery"`)
+
+	// start again
+	ltx = &ld.Transaction{Tx: ld.TxData{
+		Type:      ld.TypeStartRecovery,
+		ChainID:   ctx.ChainConfig().ChainID,
+		Nonce:     1,
+		GasTip:    100,
+		GasFeeCap: ctx.Price,
+		From:      guardian,
+		To:        &account,
+		Data:      ld.MustMarshal(input),
+	}}
+	assert.NoError(ltx.SignWith(signer.Signer2))
+	assert.NoError(ltx.ExSignWith(signer.Signer2, signer.Signer3))
+	assert.NoError(ltx.SyntacticVerify())
+	itx, err = NewTx(ltx)
+	require.NoError(t, err)
+	cs.CommitAccounts()
+	assert.ErrorContains(itx.Apply(ctx, cs), "recovery is pending, execute at 4600")
+	cs.CheckoutAccounts()
+
+	assert.NoError(cs.VerifyState())
+}
@@ -0,0 +1,130 @@
+// (c) 2022-2022, LDC Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package txn
+
+import (
+	"encoding/json"
+
+	"github.com/ldclabs/ldvm/ids"
+	"github.com/ldclabs/ldvm/ld"
+	"github.com/ldclabs/ldvm/util/erring"
+)
+
+// TxScheduleTx schedules an inner tx signed for schedule to be executed at the start of
+// the first block that reaches the target height and timestamp. The inner tx is held on
+// the sender's ledger, its max gas cost is reserved from the sender up front, refunded
+// before the inner tx executing, and charged for the gas if the inner tx failed.
+// The inner tx should have the same sender and nonce as the TxScheduleTx,
+// so it can't be replayed after the nonce consumed by the TxScheduleTx.
+type TxScheduleTx struct {
+	TxBase
+	input *ld.TxScheduler
+}
+
+func (tx *TxScheduleTx) MarshalJSON() ([]byte, error) {
+	if tx == nil || tx.ld == nil {
+		return []byte("null"), nil
+	}
+
+	v := tx.ld.Copy()
+	errp := erring.ErrPrefix("txn.TxScheduleTx.MarshalJSON: ")
+	if tx.input == nil {
+		return nil, errp.Errorf("nil tx.input")
+	}
+	d, err := json.Marshal(tx.input)
+	if err != nil {
+		return nil, errp.ErrorIf(err)
+	}
+	v.Tx.Data = d
+	return errp.ErrorMap(json.Marshal(v))
+}
+
+func (tx *TxScheduleTx) SyntacticVerify() error {
+	var err error
+	errp := erring.ErrPrefix("txn.TxScheduleTx.SyntacticVerify: ")
+
+	if err = tx.TxBase.SyntacticVerify(); err != nil {
+		return errp.ErrorIf(err)
+	}
+
+	switch {
+	case tx.ld.Tx.To != nil:
+		return errp.Errorf("invalid to, should be nil")
+
+	case tx.ld.Tx.Token != nil:
+		return errp.Errorf("invalid token, should be nil")
+
+	case tx.ld.Tx.Amount != nil:
+		return errp.Errorf("invalid amount, should be nil")
+
+	case len(tx.ld.Tx.Data) == 0:
+		return errp.Errorf("invalid data")
+	}
+
+	tx.input = &ld.TxScheduler{}
+	if err = tx.input.Unmarshal(tx.ld.Tx.Data); err != nil {
+		return errp.ErrorIf(err)
+	}
+	if err = tx.input.SyntacticVerify(); err != nil {
+		return errp.ErrorIf(err)
+	}
+
+	inner := tx.input.Transaction()
+	switch {
+	case inner.Tx.From != tx.ld.Tx.From:
+		return errp.Errorf("invalid sender of scheduled tx, expected %s, got %s",
+			tx.ld.Tx.From, inner.Tx.From)
+
+	case inner.Tx.Nonce != tx.ld.Tx.Nonce:
+		return errp.Errorf("invalid nonce of scheduled tx, expected %d, got %d",
+			tx.ld.Tx.Nonce, inner.Tx.Nonce)
+	}
+	return nil
+}
+
+func (tx *TxScheduleTx) Apply(ctx ChainContext, cs ChainState) error {
+	var err error
+	errp := erring.ErrPrefix("txn.TxScheduleTx.Apply: ")
+
+	if err = tx.TxBase.verify(ctx, cs); err != nil {
+		return errp.ErrorIf(err)
+	}
+
+	switch {
+	case tx.sessionKey != nil:
+		return errp.Errorf("can't schedule tx by session key")
+
+	case tx.input.Height > 0 && tx.input.Height <= cs.Height():
+		return errp.Errorf("invalid height, expected > %d, got %d",
+			cs.Height(), tx.input.Height)
+
+	case tx.input.Timestamp > 0 && tx.input.Timestamp <= cs.Timestamp():
+		return errp.Errorf("invalid timestamp, expected > %d, got %d",
+			cs.Timestamp(), tx.input.Timestamp)
+	}
+
+	if err = cs.LoadLedger(tx.from); err != nil {
+		return errp.ErrorIf(err)
+	}
+
+	if err = tx.TxBase.accept(ctx, cs); err != nil {
+		return errp.ErrorIf(err)
+	}
+
+	id := tx.input.Transaction().ID
+	reserve := tx.input.Reserve()
+	if err = tx.from.Sub(ids.NativeToken, reserve); err != nil {
+		return errp.ErrorIf(err)
+	}
+	if err = tx.from.ScheduleTx(id, &ld.ScheduleEntry{
+		Sender:    tx.ld.Tx.From,
+		Height:    tx.input.Height,
+		Timestamp: tx.input.Timestamp,
+		Reserve:   reserve,
+		Tx:        tx.input.Tx,
+	}); err != nil {
+		return errp.ErrorIf(err)
+	}
+	return errp.ErrorIf(cs.QueueScheduledTx(id, tx.ld.Tx.From, tx.input.Height, tx.input.Timestamp))
+}
@@ -0,0 +1,273 @@
+// (c) 2022-2022, LDC Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package txn
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ldclabs/ldvm/ids"
+	"github.com/ldclabs/ldvm/ld"
+	"github.com/ldclabs/ldvm/signer"
+	"github.com/ldclabs/ldvm/unit"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTxScheduleTx(t *testing.T) {
+	assert := assert.New(t)
+
+	// SyntacticVerify
+	tx := &TxScheduleTx{}
+	assert.ErrorContains(tx.SyntacticVerify(), "nil pointer")
+	_, err := tx.MarshalJSON()
+	require.NoError(t, err)
+
+	ctx := NewMockChainContext()
+	cs := ctx.MockChainState()
+	sender := signer.Signer1.Key().Address()
+	to := signer.Signer2.Key().Address()
+
+	ltx := &ld.Transaction{Tx: ld.TxData{
+		Type:      ld.TypeScheduleTx,
+		ChainID:   ctx.ChainConfig().ChainID,
+		Nonce:     0,
+		GasTip:    100,
+		GasFeeCap: ctx.Price,
+		From:      sender,
+		To:        to.Ptr(),
+	}}
+	assert.NoError(ltx.SignWith(signer.Signer1))
+	assert.NoError(ltx.SyntacticVerify())
+	_, err = NewTx(ltx)
+	assert.ErrorContains(err, "invalid to, should be nil")
+
+	ltx = &ld.Transaction{Tx: ld.TxData{
+		Type:      ld.TypeScheduleTx,
+		ChainID:   ctx.ChainConfig().ChainID,
+		Nonce:     0,
+		GasTip:    100,
+		GasFeeCap: ctx.Price,
+		From:      sender,
+		Token:     ids.NativeToken.Ptr(),
+	}}
+	assert.NoError(ltx.SignWith(signer.Signer1))
+	assert.NoError(ltx.SyntacticVerify())
+	_, err = NewTx(ltx)
+	assert.ErrorContains(err, "invalid token, should be nil")
+
+	ltx = &ld.Transaction{Tx: ld.TxData{
+		Type:      ld.TypeScheduleTx,
+		ChainID:   ctx.ChainConfig().ChainID,
+		Nonce:     0,
+		GasTip:    100,
+		GasFeeCap: ctx.Price,
+		From:      sender,
+	}}
+	assert.NoError(ltx.SignWith(signer.Signer1))
+	assert.NoError(ltx.SyntacticVerify())
+	_, err = NewTx(ltx)
+	assert.ErrorContains(err, "invalid data")
+
+	ltx = &ld.Transaction{Tx: ld.TxData{
+		Type:      ld.TypeScheduleTx,
+		ChainID:   ctx.ChainConfig().ChainID,
+		Nonce:     0,
+		GasTip:    100,
+		GasFeeCap: ctx.Price,
+		From:      sender,
+		Data:      []byte("你好👋"),
+	}}
+	assert.NoError(ltx.SignWith(signer.Signer1))
+	assert.NoError(ltx.SyntacticVerify())
+	_, err = NewTx(ltx)
+	assert.ErrorContains(err, "ld.TxScheduler.Unmarshal")
+
+	inner := &ld.Transaction{Tx: ld.TxData{
+		Type:      ld.TypeTransfer,
+		ChainID:   ctx.ChainConfig().ChainID,
+		Nonce:     0,
+		GasTip:    100,
+		GasFeeCap: ctx.Price,
+		From:      to,
+		To:        sender.Ptr(),
+		Amount:    new(big.Int).SetUint64(unit.LDC),
+	}}
+	assert.NoError(inner.SignForSchedule(signer.Signer2))
+	assert.NoError(inner.SyntacticVerify())
+	input := &ld.TxScheduler{Height: 2, Tx: inner.Bytes()}
+	assert.NoError(input.SyntacticVerify())
+	ltx = &ld.Transaction{Tx: ld.TxData{
+		Type:      ld.TypeScheduleTx,
+		ChainID:   ctx.ChainConfig().ChainID,
+		Nonce:     0,
+		GasTip:    100,
+		GasFeeCap: ctx.Price,
+		From:      sender,
+		Data:      input.Bytes(),
+	}}
+	assert.NoError(ltx.SignWith(signer.Signer1))
+	assert.NoError(ltx.SyntacticVerify())
+	_, err = NewTx(ltx)
+	assert.ErrorContains(err, "invalid sender of scheduled tx")
+
+	inner = &ld.Transaction{Tx: ld.TxData{
+		Type:      ld.TypeTransfer,
+		ChainID:   ctx.ChainConfig().ChainID,
+		Nonce:     1,
+		GasTip:    100,
+		GasFeeCap: ctx.Price,
+		From:      sender,
+		To:        to.Ptr(),
+		Amount:    new(big.Int).SetUint64(unit.LDC),
+	}}
+	assert.NoError(inner.SignForSchedule(signer.Signer1))
+	assert.NoError(inner.SyntacticVerify())
+	input = &ld.TxScheduler{Height: 2, Tx: inner.Bytes()}
+	assert.NoError(input.SyntacticVerify())
+	ltx = &ld.Transaction{Tx: ld.TxData{
+		Type:      ld.TypeScheduleTx,
+		ChainID:   ctx.ChainConfig().ChainID,
+		Nonce:     0,
+		GasTip:    100,
+		GasFeeCap: ctx.Price,
+		From:      sender,
+		Data:      input.Bytes(),
+	}}
+	assert.NoError(ltx.SignWith(signer.Signer1))
+	assert.NoError(ltx.SyntacticVerify())
+	_, err = NewTx(ltx)
+	assert.ErrorContains(err, "invalid nonce of scheduled tx, expected 0, got 1")
+
+	inner = &ld.Transaction{Tx: ld.TxData{
+		Type:      ld.TypeTransfer,
+		ChainID:   ctx.ChainConfig().ChainID,
+		Nonce:     0,
+		GasTip:    100,
+		GasFeeCap: ctx.Price,
+		From:      sender,
+		To:        to.Ptr(),
+		Amount:    new(big.Int).SetUint64(unit.LDC),
+	}}
+	assert.NoError(inner.SignForSchedule(signer.Signer1))
+	assert.NoError(inner.SyntacticVerify())
+	input = &ld.TxScheduler{Height: 1, Tx: inner.Bytes()}
+	assert.NoError(input.SyntacticVerify())
+	ltx = &ld.Transaction{Tx: ld.TxData{
+		Type:      ld.TypeScheduleTx,
+		ChainID:   ctx.ChainConfig().ChainID,
+		Nonce:     0,
+		GasTip:    100,
+		GasFeeCap: ctx.Price,
+		From:      sender,
+		Data:      input.Bytes(),
+	}}
+	assert.NoError(ltx.SignWith(signer.Signer1))
+	assert.NoError(ltx.SyntacticVerify())
+	itx, err := NewTx(ltx)
+	require.NoError(t, err)
+
+	cs.CommitAccounts()
+	assert.ErrorContains(itx.Apply(ctx, cs),
+		"insufficient NativeLDC balance, expected 2066900, got 0")
+	cs.CheckoutAccounts()
+
+	senderAcc := cs.MustAccount(sender)
+	senderAcc.Add(ids.NativeToken, new(big.Int).SetUint64(unit.LDC*3))
+	cs.CommitAccounts()
+	assert.ErrorContains(itx.Apply(ctx, cs), "invalid height, expected > 1, got 1")
+	cs.CheckoutAccounts()
+
+	input = &ld.TxScheduler{Height: 2, Timestamp: 1000, Tx: inner.Bytes()}
+	assert.NoError(input.SyntacticVerify())
+	ltx = &ld.Transaction{Tx: ld.TxData{
+		Type:      ld.TypeScheduleTx,
+		ChainID:   ctx.ChainConfig().ChainID,
+		Nonce:     0,
+		GasTip:    100,
+		GasFeeCap: ctx.Price,
+		From:      sender,
+		Data:      input.Bytes(),
+	}}
+	assert.NoError(ltx.SignWith(signer.Signer1))
+	assert.NoError(ltx.SyntacticVerify())
+	itx, err = NewTx(ltx)
+	require.NoError(t, err)
+
+	cs.CommitAccounts()
+	assert.ErrorContains(itx.Apply(ctx, cs), "invalid timestamp, expected > 1000, got 1000")
+	cs.CheckoutAccounts()
+
+	input = &ld.TxScheduler{Height: 2, Tx: inner.Bytes()}
+	assert.NoError(input.SyntacticVerify())
+	ltx = &ld.Transaction{Tx: ld.TxData{
+		Type:      ld.TypeScheduleTx,
+		ChainID:   ctx.ChainConfig().ChainID,
+		Nonce:     0,
+		GasTip:    100,
+		GasFeeCap: ctx.Price,
+		From:      sender,
+		Data:      input.Bytes(),
+	}}
+	assert.NoError(ltx.SignWith(signer.Signer1))
+	assert.NoError(ltx.SyntacticVerify())
+	itx, err = NewTx(ltx)
+	require.NoError(t, err)
+
+	assert.NoError(itx.Apply(ctx, cs))
+
+	senderGas := ltx.Gas()
+	reserve := inner.Gas() * (ctx.Price + 100)
+	ldcAcc := itx.(*TxScheduleTx).ldc
+	assert.Equal(senderGas*ctx.Price, ldcAcc.Balance().Uint64())
+	assert.Equal(senderGas*100, itx.(*TxScheduleTx).miner.Balance().Uint64())
+	assert.Equal(unit.LDC*3-senderGas*(ctx.Price+100)-reserve,
+		senderAcc.BalanceOfAll(ids.NativeToken).Uint64())
+	assert.Equal(uint64(1), senderAcc.Nonce())
+	assert.Equal(&ld.ScheduleEntry{Sender: sender, Height: 2}, cs.SQ[inner.ID])
+
+	jsondata, err := itx.MarshalJSON()
+	require.NoError(t, err)
+	assert.Contains(string(jsondata), `"type":"TypeScheduleTx"`)
+	assert.Contains(string(jsondata), `"data":{"height":2,"tx":`)
+
+	cs.CommitAccounts()
+	assert.ErrorContains(itx.Apply(ctx, cs), "invalid nonce for sender, expected 1, got 0")
+	cs.CheckoutAccounts()
+
+	// the inner tx can't be executed directly
+	ntx, err := NewTx(inner)
+	require.NoError(t, err)
+	cs.CommitAccounts()
+	assert.ErrorContains(ntx.Apply(ctx, cs), "invalid tx, scheduled tx can't be executed directly")
+	cs.CheckoutAccounts()
+
+	// a tx not signed for schedule can't be executed as a scheduled tx
+	ntx, err = NewScheduledTx(ltx)
+	require.NoError(t, err)
+	cs.CommitAccounts()
+	assert.ErrorContains(ntx.Apply(ctx, cs), "invalid tx, should be signed for schedule")
+	cs.CheckoutAccounts()
+
+	// execute the scheduled tx
+	ctx.height++
+	entry, err := senderAcc.TakeScheduledTx(inner.ID)
+	require.NoError(t, err)
+	assert.Equal(reserve, entry.Reserve.Uint64())
+	assert.NoError(senderAcc.Add(ids.NativeToken, entry.Reserve))
+
+	stx := &ld.Transaction{}
+	require.NoError(t, stx.Unmarshal(entry.Tx))
+	require.NoError(t, stx.SyntacticVerify())
+	ntx, err = NewScheduledTx(stx)
+	require.NoError(t, err)
+	assert.NoError(ntx.Apply(ctx, cs))
+
+	assert.Equal(unit.LDC*2-senderGas*(ctx.Price+100)-reserve,
+		senderAcc.BalanceOfAll(ids.NativeToken).Uint64())
+	assert.Equal(unit.LDC, cs.MustAccount(to).BalanceOfAll(ids.NativeToken).Uint64())
+	assert.Equal(uint64(1), senderAcc.Nonce())
+
+	assert.NoError(cs.VerifyState())
+}
@@ -77,6 +77,9 @@ func (tx *TxCreateStake) SyntacticVerify() error {
 	case tx.input.Amount != nil:
 		return errp.Errorf("invalid amount, should be nil")
 
+	case tx.input.SessionKeys != nil:
+		return errp.Errorf("invalid sessionKeys, should be nil")
+
 	case len(tx.input.Data) == 0:
 		return errp.Errorf("invalid input data")
 	}
@@ -83,7 +83,7 @@ func (tx *TxResetStake) Apply(ctx ChainContext, cs ChainState) error {
 		return errp.ErrorIf(err)
 	}
 
-	if !tx.from.VerifyPlus(tx.ld.TxHash(), tx.ld.Signatures, nil) {
+	if !tx.from.VerifyPlus(tx.ld.SigHash(), tx.ld.Signatures, nil) {
 		return errp.Errorf("invalid signatures for stake keepers")
 	}
 
@@ -0,0 +1,182 @@
+// (c) 2022-2022, LDC Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package txn
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ldclabs/ldvm/ids"
+	"github.com/ldclabs/ldvm/ld"
+	"github.com/ldclabs/ldvm/signer"
+	"github.com/ldclabs/ldvm/unit"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTxCreateSubscription(t *testing.T) {
+	assert := assert.New(t)
+
+	// SyntacticVerify
+	tx := &TxCreateSubscription{}
+	assert.ErrorContains(tx.SyntacticVerify(), "nil pointer")
+	_, err := tx.MarshalJSON()
+	require.NoError(t, err)
+
+	ctx := NewMockChainContext()
+	cs := ctx.MockChainState()
+	payer := signer.Signer1.Key().Address()
+	payee := signer.Signer2.Key().Address()
+	token := ld.MustNewToken("$TEST")
+
+	ltx := &ld.Transaction{Tx: ld.TxData{
+		Type:      ld.TypeCreateSubscription,
+		ChainID:   ctx.ChainConfig().ChainID,
+		Nonce:     0,
+		GasTip:    100,
+		GasFeeCap: ctx.Price,
+		From:      payer,
+	}}
+	assert.NoError(ltx.SignWith(signer.Signer1))
+	assert.NoError(ltx.SyntacticVerify())
+	_, err = NewTx(ltx)
+	assert.ErrorContains(err, "nil to as payee")
+
+	ltx = &ld.Transaction{Tx: ld.TxData{
+		Type:      ld.TypeCreateSubscription,
+		ChainID:   ctx.ChainConfig().ChainID,
+		Nonce:     0,
+		GasTip:    100,
+		GasFeeCap: ctx.Price,
+		From:      payer,
+		To:        &payee,
+		Amount:    big.NewInt(1),
+	}}
+	assert.NoError(ltx.SignWith(signer.Signer1))
+	assert.NoError(ltx.SyntacticVerify())
+	_, err = NewTx(ltx)
+	assert.ErrorContains(err, "invalid amount, should be nil")
+
+	ltx = &ld.Transaction{Tx: ld.TxData{
+		Type:      ld.TypeCreateSubscription,
+		ChainID:   ctx.ChainConfig().ChainID,
+		Nonce:     0,
+		GasTip:    100,
+		GasFeeCap: ctx.Price,
+		From:      payer,
+		To:        &payee,
+	}}
+	assert.NoError(ltx.SignWith(signer.Signer1))
+	assert.NoError(ltx.SyntacticVerify())
+	_, err = NewTx(ltx)
+	assert.ErrorContains(err, "invalid data")
+
+	ltx = &ld.Transaction{Tx: ld.TxData{
+		Type:      ld.TypeCreateSubscription,
+		ChainID:   ctx.ChainConfig().ChainID,
+		Nonce:     0,
+		GasTip:    100,
+		GasFeeCap: ctx.Price,
+		From:      payer,
+		To:        &payee,
+		Data:      ld.MustMarshal(&ld.TxSubscription{}),
+	}}
+	assert.NoError(ltx.SignWith(signer.Signer1))
+	assert.NoError(ltx.SyntacticVerify())
+	_, err = NewTx(ltx)
+	assert.ErrorContains(err, "invalid limit")
+
+	input := &ld.TxSubscription{
+		Limit:  new(big.Int).SetUint64(unit.LDC),
+		Period: ld.PeriodMonthly,
+		Expire: cs.Timestamp(),
+	}
+	ltx = &ld.Transaction{Tx: ld.TxData{
+		Type:      ld.TypeCreateSubscription,
+		ChainID:   ctx.ChainConfig().ChainID,
+		Nonce:     0,
+		GasTip:    100,
+		GasFeeCap: ctx.Price,
+		From:      payer,
+		To:        &payee,
+		Token:     token.Ptr(),
+		Data:      ld.MustMarshal(input),
+	}}
+	assert.NoError(ltx.SignWith(signer.Signer1))
+	assert.NoError(ltx.SyntacticVerify())
+	itx, err := NewTx(ltx)
+	require.NoError(t, err)
+
+	cs.CommitAccounts()
+	assert.ErrorContains(itx.Apply(ctx, cs),
+		"insufficient NativeLDC balance, expected 922900, got 0")
+	cs.CheckoutAccounts()
+
+	payerAcc := cs.MustAccount(payer)
+	payerAcc.Add(ids.NativeToken, new(big.Int).SetUint64(unit.LDC))
+	cs.CommitAccounts()
+	assert.ErrorContains(itx.Apply(ctx, cs),
+		"invalid expire, expected > 1000, got 1000")
+	cs.CheckoutAccounts()
+
+	input.Expire = cs.Timestamp() + 3600*24*365
+	ltx = &ld.Transaction{Tx: ld.TxData{
+		Type:      ld.TypeCreateSubscription,
+		ChainID:   ctx.ChainConfig().ChainID,
+		Nonce:     0,
+		GasTip:    100,
+		GasFeeCap: ctx.Price,
+		From:      payer,
+		To:        &payee,
+		Token:     token.Ptr(),
+		Data:      ld.MustMarshal(input),
+	}}
+	assert.NoError(ltx.SignWith(signer.Signer1))
+	assert.NoError(ltx.SyntacticVerify())
+	itx, err = NewTx(ltx)
+	require.NoError(t, err)
+	assert.NoError(itx.Apply(ctx, cs))
+
+	payerGas := ltx.Gas()
+	assert.Equal(payerGas*ctx.Price,
+		itx.(*TxCreateSubscription).ldc.Balance().Uint64())
+	assert.Equal(payerGas*100,
+		itx.(*TxCreateSubscription).miner.Balance().Uint64())
+	assert.Equal(unit.LDC-payerGas*(ctx.Price+100),
+		payerAcc.BalanceOfAll(ids.NativeToken).Uint64())
+	assert.Equal(uint64(1), payerAcc.Nonce())
+	sub := payerAcc.Subscription(payee, token)
+	require.NotNil(t, sub)
+	assert.Equal(unit.LDC, sub.Limit.Uint64())
+	assert.Equal(ld.PeriodMonthly, sub.Period)
+	assert.Equal(uint64(0), sub.PeriodStart)
+	assert.Nil(payerAcc.Subscription(payee, ids.NativeToken))
+
+	jsondata, err := itx.MarshalJSON()
+	require.NoError(t, err)
+	assert.Contains(string(jsondata), `"type":"TypeCreateSubscription"`)
+	assert.Contains(string(jsondata), `"data":{"limit":1000000000,"period":1,"expire":31537000}`)
+
+	ltx = &ld.Transaction{Tx: ld.TxData{
+		Type:      ld.TypeCreateSubscription,
+		ChainID:   ctx.ChainConfig().ChainID,
+		Nonce:     1,
+		GasTip:    100,
+		GasFeeCap: ctx.Price,
+		From:      payer,
+		To:        &payee,
+		Token:     token.Ptr(),
+		Data:      ld.MustMarshal(input),
+	}}
+	assert.NoError(ltx.SignWith(signer.Signer1))
+	assert.NoError(ltx.SyntacticVerify())
+	itx, err = NewTx(ltx)
+	require.NoError(t, err)
+	cs.CommitAccounts()
+	assert.ErrorContains(itx.Apply(ctx, cs),
+		"subscription for 0x44171C37Ff5D7B7bb8Dcad5C81f16284A229E641 with $TEST exists")
+	cs.CheckoutAccounts()
+
+	assert.NoError(cs.VerifyState())
+}
@@ -0,0 +1,121 @@
+// (c) 2022-2022, LDC Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package txn
+
+import (
+	"encoding/json"
+
+	"github.com/ldclabs/ldvm/ids"
+	"github.com/ldclabs/ldvm/ld"
+	"github.com/ldclabs/ldvm/util/erring"
+)
+
+// TxPullSubscription pulls the amount of token from the payer (tx.To) to the payee (tx.From)
+// within the subscription's limit of the current period.
+type TxPullSubscription struct {
+	TxBase
+	input *ld.TxTransfer
+}
+
+func (tx *TxPullSubscription) MarshalJSON() ([]byte, error) {
+	if tx == nil || tx.ld == nil {
+		return []byte("null"), nil
+	}
+
+	v := tx.ld.Copy()
+	errp := erring.ErrPrefix("txn.TxPullSubscription.MarshalJSON: ")
+	if tx.input == nil {
+		return nil, errp.Errorf("nil tx.input")
+	}
+	d, err := json.Marshal(tx.input)
+	if err != nil {
+		return nil, errp.ErrorIf(err)
+	}
+	v.Tx.Data = d
+	return errp.ErrorMap(json.Marshal(v))
+}
+
+// TxTransfer{From: payer, Token, Amount} with tx.To as payer, tx.From as payee
+func (tx *TxPullSubscription) SyntacticVerify() error {
+	var err error
+	errp := erring.ErrPrefix("txn.TxPullSubscription.SyntacticVerify: ")
+
+	if err = tx.TxBase.SyntacticVerify(); err != nil {
+		return errp.ErrorIf(err)
+	}
+
+	switch {
+	case tx.ld.Tx.To == nil:
+		return errp.Errorf("nil to as payer")
+
+	case tx.ld.Tx.Amount != nil:
+		return errp.Errorf("invalid amount, should be nil")
+
+	case len(tx.ld.Tx.Data) == 0:
+		return errp.Errorf("invalid data")
+	}
+
+	tx.input = &ld.TxTransfer{}
+	if err = tx.input.Unmarshal(tx.ld.Tx.Data); err != nil {
+		return errp.ErrorIf(err)
+	}
+	if err = tx.input.SyntacticVerify(); err != nil {
+		return errp.ErrorIf(err)
+	}
+
+	switch {
+	case tx.input.From == nil:
+		return errp.Errorf("nil payer")
+
+	case *tx.input.From != *tx.ld.Tx.To:
+		return errp.Errorf("invalid payer, expected %s, got %s",
+			tx.input.From, tx.ld.Tx.To)
+
+	case tx.input.To != nil && *tx.input.To != tx.ld.Tx.From:
+		return errp.Errorf("invalid recipient, should be payee")
+
+	case tx.input.Token == nil && tx.token != ids.NativeToken:
+		return errp.Errorf("invalid token, expected %s, got %s",
+			ids.NativeToken.GoString(), tx.token.GoString())
+
+	case tx.input.Token != nil && tx.token != *tx.input.Token:
+		return errp.Errorf("invalid token, expected %s, got %s",
+			tx.input.Token.GoString(), tx.token.GoString())
+
+	case tx.input.Amount == nil || tx.input.Amount.Sign() <= 0:
+		return errp.Errorf("invalid amount, expected >= 1")
+
+	case tx.input.Expire > 0 && tx.input.Expire < tx.ld.Timestamp:
+		return errp.Errorf("data expired")
+	}
+	return nil
+}
+
+func (tx *TxPullSubscription) Apply(ctx ChainContext, cs ChainState) error {
+	var err error
+	errp := erring.ErrPrefix("txn.TxPullSubscription.Apply: ")
+
+	if err = tx.TxBase.verify(ctx, cs); err != nil {
+		return errp.ErrorIf(err)
+	}
+
+	if err = cs.LoadLedger(tx.to); err != nil {
+		return errp.ErrorIf(err)
+	}
+
+	// the token is pulled from the payer, so the payer's policy applies
+	if err = tx.to.CheckPolicy(&tx.ld.Tx.From, tx.token, tx.input.Amount); err != nil {
+		return errp.ErrorIf(err)
+	}
+	if err = tx.to.SpendByPolicy(tx.token, tx.input.Amount); err != nil {
+		return errp.ErrorIf(err)
+	}
+	if err = tx.to.PullSubscription(tx.ld.Tx.From, tx.token, tx.input.Amount); err != nil {
+		return errp.ErrorIf(err)
+	}
+	if err = tx.from.Add(tx.token, tx.input.Amount); err != nil {
+		return errp.ErrorIf(err)
+	}
+	return errp.ErrorIf(tx.TxBase.accept(ctx, cs))
+}
@@ -0,0 +1,255 @@
+// (c) 2022-2022, LDC Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package txn
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ldclabs/ldvm/ids"
+	"github.com/ldclabs/ldvm/ld"
+	"github.com/ldclabs/ldvm/signer"
+	"github.com/ldclabs/ldvm/unit"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTxPullSubscription(t *testing.T) {
+	assert := assert.New(t)
+
+	// SyntacticVerify
+	tx := &TxPullSubscription{}
+	assert.ErrorContains(tx.SyntacticVerify(), "nil pointer")
+	_, err := tx.MarshalJSON()
+	require.NoError(t, err)
+
+	ctx := NewMockChainContext()
+	cs := ctx.MockChainState()
+	payer := signer.Signer1.Key().Address()
+	payee := signer.Signer2.Key().Address()
+	token := ld.MustNewToken("$TEST")
+
+	ltx := &ld.Transaction{Tx: ld.TxData{
+		Type:      ld.TypePullSubscription,
+		ChainID:   ctx.ChainConfig().ChainID,
+		Nonce:     0,
+		GasTip:    100,
+		GasFeeCap: ctx.Price,
+		From:      payee,
+	}}
+	assert.NoError(ltx.SignWith(signer.Signer2))
+	assert.NoError(ltx.SyntacticVerify())
+	_, err = NewTx(ltx)
+	assert.ErrorContains(err, "nil to as payer")
+
+	ltx = &ld.Transaction{Tx: ld.TxData{
+		Type:      ld.TypePullSubscription,
+		ChainID:   ctx.ChainConfig().ChainID,
+		Nonce:     0,
+		GasTip:    100,
+		GasFeeCap: ctx.Price,
+		From:      payee,
+		To:        &payer,
+		Amount:    big.NewInt(1),
+	}}
+	assert.NoError(ltx.SignWith(signer.Signer2))
+	assert.NoError(ltx.SyntacticVerify())
+	_, err = NewTx(ltx)
+	assert.ErrorContains(err, "invalid amount, should be nil")
+
+	ltx = &ld.Transaction{Tx: ld.TxData{
+		Type:      ld.TypePullSubscription,
+		ChainID:   ctx.ChainConfig().ChainID,
+		Nonce:     0,
+		GasTip:    100,
+		GasFeeCap: ctx.Price,
+		From:      payee,
+		To:        &payer,
+	}}
+	assert.NoError(ltx.SignWith(signer.Signer2))
+	assert.NoError(ltx.SyntacticVerify())
+	_, err = NewTx(ltx)
+	assert.ErrorContains(err, "invalid data")
+
+	ltx = &ld.Transaction{Tx: ld.TxData{
+		Type:      ld.TypePullSubscription,
+		ChainID:   ctx.ChainConfig().ChainID,
+		Nonce:     0,
+		GasTip:    100,
+		GasFeeCap: ctx.Price,
+		From:      payee,
+		To:        &payer,
+		Data:      ld.MustMarshal(&ld.TxTransfer{}),
+	}}
+	assert.NoError(ltx.SignWith(signer.Signer2))
+	assert.NoError(ltx.SyntacticVerify())
+	_, err = NewTx(ltx)
+	assert.ErrorContains(err, "nil payer")
+
+	ltx = &ld.Transaction{Tx: ld.TxData{
+		Type:      ld.TypePullSubscription,
+		ChainID:   ctx.ChainConfig().ChainID,
+		Nonce:     0,
+		GasTip:    100,
+		GasFeeCap: ctx.Price,
+		From:      payee,
+		To:        &payer,
+		Data:      ld.MustMarshal(&ld.TxTransfer{From: &payee}),
+	}}
+	assert.NoError(ltx.SignWith(signer.Signer2))
+	assert.NoError(ltx.SyntacticVerify())
+	_, err = NewTx(ltx)
+	assert.ErrorContains(err, "invalid payer, expected 0x44171C37Ff5D7B7bb8Dcad5C81f16284A229E641, got 0x8db97c7cECe249C2b98bdc0226cc4C2A57bF52fc")
+
+	ltx = &ld.Transaction{Tx: ld.TxData{
+		Type:      ld.TypePullSubscription,
+		ChainID:   ctx.ChainConfig().ChainID,
+		Nonce:     0,
+		GasTip:    100,
+		GasFeeCap: ctx.Price,
+		From:      payee,
+		To:        &payer,
+		Data:      ld.MustMarshal(&ld.TxTransfer{From: &payer, To: &ids.GenesisAccount}),
+	}}
+	assert.NoError(ltx.SignWith(signer.Signer2))
+	assert.NoError(ltx.SyntacticVerify())
+	_, err = NewTx(ltx)
+	assert.ErrorContains(err, "invalid recipient, should be payee")
+
+	ltx = &ld.Transaction{Tx: ld.TxData{
+		Type:      ld.TypePullSubscription,
+		ChainID:   ctx.ChainConfig().ChainID,
+		Nonce:     0,
+		GasTip:    100,
+		GasFeeCap: ctx.Price,
+		From:      payee,
+		To:        &payer,
+		Token:     token.Ptr(),
+		Data:      ld.MustMarshal(&ld.TxTransfer{From: &payer}),
+	}}
+	assert.NoError(ltx.SignWith(signer.Signer2))
+	assert.NoError(ltx.SyntacticVerify())
+	_, err = NewTx(ltx)
+	assert.ErrorContains(err, "invalid token, expected NativeLDC, got $TEST")
+
+	ltx = &ld.Transaction{Tx: ld.TxData{
+		Type:      ld.TypePullSubscription,
+		ChainID:   ctx.ChainConfig().ChainID,
+		Nonce:     0,
+		GasTip:    100,
+		GasFeeCap: ctx.Price,
+		From:      payee,
+		To:        &payer,
+		Token:     token.Ptr(),
+		Data:      ld.MustMarshal(&ld.TxTransfer{From: &payer, Token: token.Ptr()}),
+	}}
+	assert.NoError(ltx.SignWith(signer.Signer2))
+	assert.NoError(ltx.SyntacticVerify())
+	_, err = NewTx(ltx)
+	assert.ErrorContains(err, "invalid amount, expected >= 1")
+
+	input := &ld.TxTransfer{
+		From:   &payer,
+		Token:  token.Ptr(),
+		Amount: new(big.Int).SetUint64(unit.LDC * 6 / 10),
+	}
+	ltx = &ld.Transaction{Tx: ld.TxData{
+		Type:      ld.TypePullSubscription,
+		ChainID:   ctx.ChainConfig().ChainID,
+		Nonce:     0,
+		GasTip:    100,
+		GasFeeCap: ctx.Price,
+		From:      payee,
+		To:        &payer,
+		Token:     token.Ptr(),
+		Data:      ld.MustMarshal(input),
+	}}
+	assert.NoError(ltx.SignWith(signer.Signer2))
+	assert.NoError(ltx.SyntacticVerify())
+	itx, err := NewTx(ltx)
+	require.NoError(t, err)
+
+	cs.CommitAccounts()
+	assert.ErrorContains(itx.Apply(ctx, cs),
+		"insufficient NativeLDC balance, expected 1168200, got 0")
+	cs.CheckoutAccounts()
+
+	payeeAcc := cs.MustAccount(payee)
+	payeeAcc.Add(ids.NativeToken, new(big.Int).SetUint64(unit.LDC))
+	cs.CommitAccounts()
+	assert.ErrorContains(itx.Apply(ctx, cs),
+		"0x44171C37Ff5D7B7bb8Dcad5C81f16284A229E641 has no subscription for $TEST")
+	cs.CheckoutAccounts()
+
+	payerAcc := cs.MustAccount(payer)
+	require.NoError(t, cs.LoadLedger(payerAcc))
+	assert.NoError(payerAcc.CreateSubscription(payee, token, &ld.TxSubscription{
+		Limit:  new(big.Int).SetUint64(unit.LDC),
+		Period: ld.PeriodDaily,
+	}))
+	cs.CommitAccounts()
+	assert.ErrorContains(itx.Apply(ctx, cs),
+		"insufficient transferable $TEST balance, expected 600000000, got 0")
+	cs.CheckoutAccounts()
+
+	payerAcc.Add(token, new(big.Int).SetUint64(unit.LDC*10))
+	assert.NoError(itx.Apply(ctx, cs))
+
+	payeeGas := ltx.Gas()
+	assert.Equal(payeeGas*ctx.Price,
+		itx.(*TxPullSubscription).ldc.Balance().Uint64())
+	assert.Equal(payeeGas*100,
+		itx.(*TxPullSubscription).miner.Balance().Uint64())
+	assert.Equal(unit.LDC-payeeGas*(ctx.Price+100),
+		payeeAcc.BalanceOfAll(ids.NativeToken).Uint64())
+	assert.Equal(unit.LDC*6/10, payeeAcc.BalanceOfAll(token).Uint64())
+	assert.Equal(unit.LDC*10-unit.LDC*6/10, payerAcc.BalanceOfAll(token).Uint64())
+	assert.Equal(uint64(1), payeeAcc.Nonce())
+	assert.Equal(uint64(0), payerAcc.Nonce())
+	assert.Equal(unit.LDC*6/10, payerAcc.Subscription(payee, token).Spent.Uint64())
+
+	jsondata, err := itx.MarshalJSON()
+	require.NoError(t, err)
+	assert.Contains(string(jsondata), `"type":"TypePullSubscription"`)
+	assert.Contains(string(jsondata), `"data":{"from":"0x8db97c7cECe249C2b98bdc0226cc4C2A57bF52fc","token":"$TEST","amount":600000000}`)
+
+	// exceed the limit in the same period
+	ltx = &ld.Transaction{Tx: ld.TxData{
+		Type:      ld.TypePullSubscription,
+		ChainID:   ctx.ChainConfig().ChainID,
+		Nonce:     1,
+		GasTip:    100,
+		GasFeeCap: ctx.Price,
+		From:      payee,
+		To:        &payer,
+		Token:     token.Ptr(),
+		Data:      ld.MustMarshal(input),
+	}}
+	assert.NoError(ltx.SignWith(signer.Signer2))
+	assert.NoError(ltx.SyntacticVerify())
+	itx, err = NewTx(ltx)
+	require.NoError(t, err)
+	cs.CommitAccounts()
+	assert.ErrorContains(itx.Apply(ctx, cs),
+		"insufficient subscription limit in period, expected 600000000, got 400000000")
+	cs.CheckoutAccounts()
+
+	// pull again in the next period
+	ctx.height++
+	ctx.timestamp += 3600 * 24
+	cs.CommitAccounts()
+	cs.CheckoutAccounts()
+	payerAcc = cs.MustAccount(payer)
+	payeeAcc = cs.MustAccount(payee)
+
+	assert.NoError(itx.Apply(ctx, cs))
+	assert.Equal(unit.LDC*12/10, payeeAcc.BalanceOfAll(token).Uint64())
+	assert.Equal(unit.LDC*10-unit.LDC*12/10, payerAcc.BalanceOfAll(token).Uint64())
+	sub := payerAcc.Subscription(payee, token)
+	assert.Equal(uint64(86400), sub.PeriodStart)
+	assert.Equal(unit.LDC*6/10, sub.Spent.Uint64())
+	assert.Equal(uint64(2), payeeAcc.Nonce())
+
+	assert.NoError(cs.VerifyState())
+}
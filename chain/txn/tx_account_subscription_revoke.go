@@ -0,0 +1,53 @@
+// (c) 2022-2022, LDC Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package txn
+
+import (
+	"github.com/ldclabs/ldvm/util/erring"
+)
+
+// TxRevokeSubscription revokes the subscription of token approved to the payee (tx.To)
+// by the payer (tx.From).
+type TxRevokeSubscription struct {
+	TxBase
+}
+
+func (tx *TxRevokeSubscription) SyntacticVerify() error {
+	var err error
+	errp := erring.ErrPrefix("txn.TxRevokeSubscription.SyntacticVerify: ")
+
+	if err = tx.TxBase.SyntacticVerify(); err != nil {
+		return errp.ErrorIf(err)
+	}
+
+	switch {
+	case tx.ld.Tx.To == nil:
+		return errp.Errorf("nil to as payee")
+
+	case tx.ld.Tx.Amount != nil:
+		return errp.Errorf("invalid amount, should be nil")
+
+	case tx.ld.Tx.Data != nil:
+		return errp.Errorf("invalid data, should be nil")
+	}
+	return nil
+}
+
+func (tx *TxRevokeSubscription) Apply(ctx ChainContext, cs ChainState) error {
+	var err error
+	errp := erring.ErrPrefix("txn.TxRevokeSubscription.Apply: ")
+
+	if err = tx.TxBase.verify(ctx, cs); err != nil {
+		return errp.ErrorIf(err)
+	}
+
+	if err = cs.LoadLedger(tx.from); err != nil {
+		return errp.ErrorIf(err)
+	}
+
+	if err = tx.from.RevokeSubscription(*tx.ld.Tx.To, tx.token); err != nil {
+		return errp.ErrorIf(err)
+	}
+	return errp.ErrorIf(tx.TxBase.accept(ctx, cs))
+}
@@ -0,0 +1,125 @@
+// (c) 2022-2022, LDC Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package txn
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ldclabs/ldvm/ids"
+	"github.com/ldclabs/ldvm/ld"
+	"github.com/ldclabs/ldvm/signer"
+	"github.com/ldclabs/ldvm/unit"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTxRevokeSubscription(t *testing.T) {
+	assert := assert.New(t)
+
+	// SyntacticVerify
+	tx := &TxRevokeSubscription{}
+	assert.ErrorContains(tx.SyntacticVerify(), "nil pointer")
+	_, err := tx.MarshalJSON()
+	require.NoError(t, err)
+
+	ctx := NewMockChainContext()
+	cs := ctx.MockChainState()
+	payer := signer.Signer1.Key().Address()
+	payee := signer.Signer2.Key().Address()
+	token := ld.MustNewToken("$TEST")
+
+	ltx := &ld.Transaction{Tx: ld.TxData{
+		Type:      ld.TypeRevokeSubscription,
+		ChainID:   ctx.ChainConfig().ChainID,
+		Nonce:     0,
+		GasTip:    100,
+		GasFeeCap: ctx.Price,
+		From:      payer,
+	}}
+	assert.NoError(ltx.SignWith(signer.Signer1))
+	assert.NoError(ltx.SyntacticVerify())
+	_, err = NewTx(ltx)
+	assert.ErrorContains(err, "nil to as payee")
+
+	ltx = &ld.Transaction{Tx: ld.TxData{
+		Type:      ld.TypeRevokeSubscription,
+		ChainID:   ctx.ChainConfig().ChainID,
+		Nonce:     0,
+		GasTip:    100,
+		GasFeeCap: ctx.Price,
+		From:      payer,
+		To:        &payee,
+		Amount:    big.NewInt(1),
+	}}
+	assert.NoError(ltx.SignWith(signer.Signer1))
+	assert.NoError(ltx.SyntacticVerify())
+	_, err = NewTx(ltx)
+	assert.ErrorContains(err, "invalid amount, should be nil")
+
+	ltx = &ld.Transaction{Tx: ld.TxData{
+		Type:      ld.TypeRevokeSubscription,
+		ChainID:   ctx.ChainConfig().ChainID,
+		Nonce:     0,
+		GasTip:    100,
+		GasFeeCap: ctx.Price,
+		From:      payer,
+		To:        &payee,
+		Data:      []byte{0x80},
+	}}
+	assert.NoError(ltx.SignWith(signer.Signer1))
+	assert.NoError(ltx.SyntacticVerify())
+	_, err = NewTx(ltx)
+	assert.ErrorContains(err, "invalid data, should be nil")
+
+	ltx = &ld.Transaction{Tx: ld.TxData{
+		Type:      ld.TypeRevokeSubscription,
+		ChainID:   ctx.ChainConfig().ChainID,
+		Nonce:     0,
+		GasTip:    100,
+		GasFeeCap: ctx.Price,
+		From:      payer,
+		To:        &payee,
+		Token:     token.Ptr(),
+	}}
+	assert.NoError(ltx.SignWith(signer.Signer1))
+	assert.NoError(ltx.SyntacticVerify())
+	itx, err := NewTx(ltx)
+	require.NoError(t, err)
+
+	cs.CommitAccounts()
+	assert.ErrorContains(itx.Apply(ctx, cs),
+		"insufficient NativeLDC balance, expected 807400, got 0")
+	cs.CheckoutAccounts()
+
+	payerAcc := cs.MustAccount(payer)
+	payerAcc.Add(ids.NativeToken, new(big.Int).SetUint64(unit.LDC))
+	cs.CommitAccounts()
+	assert.ErrorContains(itx.Apply(ctx, cs),
+		"0x44171C37Ff5D7B7bb8Dcad5C81f16284A229E641 has no subscription for $TEST")
+	cs.CheckoutAccounts()
+
+	require.NoError(t, cs.LoadLedger(payerAcc))
+	assert.NoError(payerAcc.CreateSubscription(payee, token, &ld.TxSubscription{
+		Limit:  new(big.Int).SetUint64(unit.LDC),
+		Period: ld.PeriodDaily,
+	}))
+	assert.NoError(itx.Apply(ctx, cs))
+
+	payerGas := ltx.Gas()
+	assert.Equal(payerGas*ctx.Price,
+		itx.(*TxRevokeSubscription).ldc.Balance().Uint64())
+	assert.Equal(payerGas*100,
+		itx.(*TxRevokeSubscription).miner.Balance().Uint64())
+	assert.Equal(unit.LDC-payerGas*(ctx.Price+100),
+		payerAcc.BalanceOfAll(ids.NativeToken).Uint64())
+	assert.Equal(uint64(1), payerAcc.Nonce())
+	assert.Nil(payerAcc.Subscription(payee, token))
+
+	jsondata, err := itx.MarshalJSON()
+	require.NoError(t, err)
+	assert.Contains(string(jsondata), `"type":"TypeRevokeSubscription"`)
+
+	assert.NoError(cs.VerifyState())
+}
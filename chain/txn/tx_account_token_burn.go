@@ -0,0 +1,65 @@
+// (c) 2022-2022, LDC Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package txn
+
+import (
+	"github.com/ldclabs/ldvm/ids"
+	"github.com/ldclabs/ldvm/ld"
+	"github.com/ldclabs/ldvm/util/erring"
+)
+
+type TxBurnToken struct {
+	TxBase
+}
+
+// TxBurnToken{To: token account, Token: token, Amount: amount}
+func (tx *TxBurnToken) SyntacticVerify() error {
+	var err error
+	errp := erring.ErrPrefix("txn.TxBurnToken.SyntacticVerify: ")
+
+	if err = tx.TxBase.SyntacticVerify(); err != nil {
+		return errp.ErrorIf(err)
+	}
+
+	switch {
+	case tx.ld.Tx.To == nil:
+		return errp.Errorf("nil to as token account")
+
+	case tx.ld.Tx.Amount == nil || tx.ld.Tx.Amount.Sign() <= 0:
+		return errp.Errorf("invalid amount, expected >= 1")
+
+	case len(tx.ld.Tx.Data) != 0:
+		return errp.Errorf("invalid data, should be nil")
+	}
+
+	token := ids.TokenSymbol(*tx.ld.Tx.To)
+	switch {
+	case token != ids.NativeToken && !token.Valid():
+		return errp.Errorf("invalid token %s", token.GoString())
+
+	case token != tx.token:
+		return errp.Errorf("invalid token, expected %s, got %s",
+			token.GoString(), tx.token.GoString())
+	}
+	return nil
+}
+
+func (tx *TxBurnToken) Apply(ctx ChainContext, cs ChainState) error {
+	var err error
+	errp := erring.ErrPrefix("txn.TxBurnToken.Apply: ")
+
+	if err = tx.TxBase.verify(ctx, cs); err != nil {
+		return errp.ErrorIf(err)
+	}
+
+	if tx.to.Type() != ld.TokenAccount {
+		return errp.Errorf("invalid token account %s", tx.to.ID())
+	}
+
+	if err = tx.TxBase.accept(ctx, cs); err != nil {
+		return errp.ErrorIf(err)
+	}
+	// BurnToken after TxBase.accept
+	return errp.ErrorIf(tx.to.BurnToken(tx.amount))
+}
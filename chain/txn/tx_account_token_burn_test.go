@@ -0,0 +1,195 @@
+// (c) 2022-2022, LDC Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package txn
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ldclabs/ldvm/chain/acct"
+	"github.com/ldclabs/ldvm/ids"
+	"github.com/ldclabs/ldvm/ld"
+	"github.com/ldclabs/ldvm/signer"
+	"github.com/ldclabs/ldvm/unit"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTxBurnToken(t *testing.T) {
+	assert := assert.New(t)
+
+	// SyntacticVerify
+	tx := &TxBurnToken{}
+	assert.ErrorContains(tx.SyntacticVerify(), "nil pointer")
+	_, err := tx.MarshalJSON()
+	require.NoError(t, err)
+
+	ctx := NewMockChainContext()
+	cs := ctx.MockChainState()
+	token := ld.MustNewToken("$LDC")
+	tokenid := token.Address()
+	sender := signer.Signer1.Key().Address()
+	recipient := signer.Signer2.Key().Address()
+
+	ltx := &ld.Transaction{Tx: ld.TxData{
+		Type:      ld.TypeBurnToken,
+		ChainID:   ctx.ChainConfig().ChainID,
+		Nonce:     0,
+		GasTip:    100,
+		GasFeeCap: ctx.Price,
+		From:      sender,
+	}}
+	assert.NoError(ltx.SignWith(signer.Signer1))
+	assert.NoError(ltx.SyntacticVerify())
+	_, err = NewTx(ltx)
+	assert.ErrorContains(err, "nil to as token account")
+
+	ltx = &ld.Transaction{Tx: ld.TxData{
+		Type:      ld.TypeBurnToken,
+		ChainID:   ctx.ChainConfig().ChainID,
+		Nonce:     0,
+		GasTip:    100,
+		GasFeeCap: ctx.Price,
+		From:      sender,
+		To:        tokenid.Ptr(),
+		Token:     token.Ptr(),
+	}}
+	assert.NoError(ltx.SignWith(signer.Signer1))
+	assert.NoError(ltx.SyntacticVerify())
+	_, err = NewTx(ltx)
+	assert.ErrorContains(err, "invalid amount, expected >= 1")
+
+	ltx = &ld.Transaction{Tx: ld.TxData{
+		Type:      ld.TypeBurnToken,
+		ChainID:   ctx.ChainConfig().ChainID,
+		Nonce:     0,
+		GasTip:    100,
+		GasFeeCap: ctx.Price,
+		From:      sender,
+		To:        tokenid.Ptr(),
+		Token:     token.Ptr(),
+		Amount:    big.NewInt(1),
+		Data:      []byte{0x80},
+	}}
+	assert.NoError(ltx.SignWith(signer.Signer1))
+	assert.NoError(ltx.SyntacticVerify())
+	_, err = NewTx(ltx)
+	assert.ErrorContains(err, "invalid data, should be nil")
+
+	ltx = &ld.Transaction{Tx: ld.TxData{
+		Type:      ld.TypeBurnToken,
+		ChainID:   ctx.ChainConfig().ChainID,
+		Nonce:     0,
+		GasTip:    100,
+		GasFeeCap: ctx.Price,
+		From:      sender,
+		To:        recipient.Ptr(),
+		Token:     token.Ptr(),
+		Amount:    big.NewInt(1),
+	}}
+	assert.NoError(ltx.SignWith(signer.Signer1))
+	assert.NoError(ltx.SyntacticVerify())
+	_, err = NewTx(ltx)
+	assert.ErrorContains(err, "invalid token 0x44171C37Ff5D7B7bb8Dcad5C81f16284A229E641")
+
+	ltx = &ld.Transaction{Tx: ld.TxData{
+		Type:      ld.TypeBurnToken,
+		ChainID:   ctx.ChainConfig().ChainID,
+		Nonce:     0,
+		GasTip:    100,
+		GasFeeCap: ctx.Price,
+		From:      sender,
+		To:        tokenid.Ptr(),
+		Amount:    big.NewInt(1),
+	}}
+	assert.NoError(ltx.SignWith(signer.Signer1))
+	assert.NoError(ltx.SyntacticVerify())
+	_, err = NewTx(ltx)
+	assert.ErrorContains(err, "invalid token, expected $LDC, got NativeLDC")
+
+	ltx = &ld.Transaction{Tx: ld.TxData{
+		Type:      ld.TypeBurnToken,
+		ChainID:   ctx.ChainConfig().ChainID,
+		Nonce:     0,
+		GasTip:    100,
+		GasFeeCap: ctx.Price,
+		From:      sender,
+		To:        tokenid.Ptr(),
+		Token:     token.Ptr(),
+		Amount:    new(big.Int).SetUint64(unit.LDC),
+	}}
+	assert.NoError(ltx.SignWith(signer.Signer1))
+	assert.NoError(ltx.SyntacticVerify())
+	itx, err := NewTx(ltx)
+	require.NoError(t, err)
+
+	ldcAcc := cs.MustAccount(ids.LDCAccount)
+	assert.NoError(ldcAcc.CreateToken(&ld.TxAccounter{
+		Amount: new(big.Int).SetUint64(unit.LDC * 1000),
+	}))
+	assert.NoError(ldcAcc.Sub(ids.NativeToken, new(big.Int).SetUint64(unit.LDC*2)))
+	senderAcc := cs.MustAccount(sender)
+	senderAcc.Add(ids.NativeToken, new(big.Int).SetUint64(unit.LDC*2))
+	senderAcc.Add(token, new(big.Int).SetUint64(unit.LDC*2))
+	cs.CommitAccounts()
+	assert.ErrorContains(itx.Apply(ctx, cs),
+		"invalid token account 0x00000000000000000000000000000000244C4443")
+	cs.CheckoutAccounts()
+
+	testToken := acct.NewAccount(ids.Address(token))
+	testToken.Init(big.NewInt(0), ctx.FeeConfig().MinTokenPledge, 0, 0)
+	assert.NoError(testToken.CreateToken(&ld.TxAccounter{
+		Threshold: ld.Uint16Ptr(1),
+		Keepers:   &signer.Keys{signer.Signer1.Key(), signer.Signer2.Key()},
+		Amount:    new(big.Int).SetUint64(unit.LDC * 10),
+	}))
+	testToken.Add(ids.NativeToken, ctx.FeeConfig().MinTokenPledge)
+	assert.NoError(testToken.Sub(token, new(big.Int).SetUint64(unit.LDC*2)))
+	cs.AC[testToken.ID()] = testToken
+	assert.Equal(unit.LDC*2, testToken.TotalSupply().Uint64())
+
+	assert.NoError(itx.Apply(ctx, cs))
+
+	senderGas := ltx.Gas()
+	assert.Equal(unit.LDC*998+senderGas*ctx.Price,
+		itx.(*TxBurnToken).ldc.Balance().Uint64())
+	assert.Equal(senderGas*100,
+		itx.(*TxBurnToken).miner.Balance().Uint64())
+	assert.Equal(unit.LDC*2-senderGas*(ctx.Price+100),
+		senderAcc.BalanceOfAll(ids.NativeToken).Uint64())
+	assert.Equal(unit.LDC, senderAcc.BalanceOf(token).Uint64())
+	assert.Equal(uint64(1), senderAcc.Nonce())
+	assert.Equal(unit.LDC*8, testToken.BalanceOf(token).Uint64())
+	assert.Equal(unit.LDC, testToken.BurnedSupply().Uint64())
+	assert.Equal(unit.LDC, testToken.TotalSupply().Uint64())
+	assert.Equal(unit.LDC*10, testToken.LD().MaxTotalSupply.Uint64())
+
+	jsondata, err := itx.MarshalJSON()
+	require.NoError(t, err)
+	assert.Contains(string(jsondata), `"type":"TypeBurnToken"`)
+
+	// burn NativeToken
+	ltx = &ld.Transaction{Tx: ld.TxData{
+		Type:      ld.TypeBurnToken,
+		ChainID:   ctx.ChainConfig().ChainID,
+		Nonce:     1,
+		GasTip:    100,
+		GasFeeCap: ctx.Price,
+		From:      sender,
+		To:        ids.LDCAccount.Ptr(),
+		Amount:    new(big.Int).SetUint64(unit.LDC / 10),
+	}}
+	assert.NoError(ltx.SignWith(signer.Signer1))
+	assert.NoError(ltx.SyntacticVerify())
+	itx, err = NewTx(ltx)
+	require.NoError(t, err)
+	assert.NoError(itx.Apply(ctx, cs))
+
+	assert.Equal(unit.LDC/10, ldcAcc.BurnedSupply().Uint64())
+	assert.Equal(unit.LDC*998+(senderGas+ltx.Gas())*ctx.Price, ldcAcc.Balance().Uint64())
+	assert.Equal(unit.LDC*2-unit.LDC/10-(senderGas+ltx.Gas())*ctx.Price,
+		ldcAcc.TotalSupply().Uint64())
+
+	assert.NoError(cs.VerifyState())
+}
@@ -79,6 +79,9 @@ func (tx *TxCreateToken) SyntacticVerify() error {
 	case tx.input.Amount == nil || tx.input.Amount.Sign() <= 0:
 		return errp.Errorf("invalid amount, expected >= 1")
 
+	case tx.input.SessionKeys != nil:
+		return errp.Errorf("invalid sessionKeys, should be nil")
+
 	case len(tx.input.Name) < 3:
 		return errp.Errorf("invalid name %q, expected length >= 3", tx.input.Name)
 	}
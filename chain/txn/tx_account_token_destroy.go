@@ -44,7 +44,7 @@ func (tx *TxDestroyToken) Apply(ctx ChainContext, cs ChainState) error {
 	if err = tx.TxBase.verify(ctx, cs); err != nil {
 		return errp.ErrorIf(err)
 	}
-	if !tx.from.VerifyPlus(tx.ld.TxHash(), tx.ld.Signatures, nil) {
+	if !tx.from.VerifyPlus(tx.ld.SigHash(), tx.ld.Signatures, nil) {
 		return errp.Errorf("invalid signature for keepers")
 	}
 
@@ -0,0 +1,164 @@
+// (c) 2022-2022, LDC Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package txn
+
+import (
+	"encoding/json"
+	"math/big"
+	"testing"
+
+	"github.com/ldclabs/ldvm/chain/acct"
+	"github.com/ldclabs/ldvm/ids"
+	"github.com/ldclabs/ldvm/ld"
+	"github.com/ldclabs/ldvm/signer"
+	"github.com/ldclabs/ldvm/unit"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTxUpdateTokenInfo(t *testing.T) {
+	assert := assert.New(t)
+
+	// SyntacticVerify
+	tx := &TxUpdateTokenInfo{}
+	assert.ErrorContains(tx.SyntacticVerify(), "nil pointer")
+	_, err := tx.MarshalJSON()
+	require.NoError(t, err)
+
+	ctx := NewMockChainContext()
+	cs := ctx.MockChainState()
+	token := ld.MustNewToken("$LDC")
+	tokenid := token.Address()
+	sender := signer.Signer1.Key().Address()
+
+	ltx := &ld.Transaction{Tx: ld.TxData{
+		Type:      ld.TypeUpdateTokenInfo,
+		ChainID:   ctx.ChainConfig().ChainID,
+		Nonce:     0,
+		GasTip:    100,
+		GasFeeCap: ctx.Price,
+		From:      tokenid,
+		To:        sender.Ptr(),
+	}}
+	assert.NoError(ltx.SignWith(signer.Signer1))
+	assert.NoError(ltx.SyntacticVerify())
+	_, err = NewTx(ltx)
+	assert.ErrorContains(err, "invalid to, should be nil")
+
+	ltx = &ld.Transaction{Tx: ld.TxData{
+		Type:      ld.TypeUpdateTokenInfo,
+		ChainID:   ctx.ChainConfig().ChainID,
+		Nonce:     0,
+		GasTip:    100,
+		GasFeeCap: ctx.Price,
+		From:      tokenid,
+		Token:     token.Ptr(),
+	}}
+	assert.NoError(ltx.SignWith(signer.Signer1))
+	assert.NoError(ltx.SyntacticVerify())
+	_, err = NewTx(ltx)
+	assert.ErrorContains(err, "invalid token, should be nil")
+
+	ltx = &ld.Transaction{Tx: ld.TxData{
+		Type:      ld.TypeUpdateTokenInfo,
+		ChainID:   ctx.ChainConfig().ChainID,
+		Nonce:     0,
+		GasTip:    100,
+		GasFeeCap: ctx.Price,
+		From:      tokenid,
+	}}
+	assert.NoError(ltx.SignWith(signer.Signer1))
+	assert.NoError(ltx.SyntacticVerify())
+	_, err = NewTx(ltx)
+	assert.ErrorContains(err, "invalid data")
+
+	input := &ld.TokenInfo{Name: "Linked Data Chain", Decimals: 9}
+	ltx = &ld.Transaction{Tx: ld.TxData{
+		Type:      ld.TypeUpdateTokenInfo,
+		ChainID:   ctx.ChainConfig().ChainID,
+		Nonce:     0,
+		GasTip:    100,
+		GasFeeCap: ctx.Price,
+		From:      sender,
+		Data:      ld.MustMarshal(input),
+	}}
+	assert.NoError(ltx.SignWith(signer.Signer1))
+	assert.NoError(ltx.SyntacticVerify())
+	_, err = NewTx(ltx)
+	assert.ErrorContains(err, "invalid token 0x8db97c7cECe249C2b98bdc0226cc4C2A57bF52fc")
+
+	ltx = &ld.Transaction{Tx: ld.TxData{
+		Type:      ld.TypeUpdateTokenInfo,
+		ChainID:   ctx.ChainConfig().ChainID,
+		Nonce:     0,
+		GasTip:    100,
+		GasFeeCap: ctx.Price,
+		From:      tokenid,
+		Data:      ld.MustMarshal(&ld.TokenInfo{Name: "LDC", Decimals: 20}),
+	}}
+	assert.NoError(ltx.SignWith(signer.Signer1))
+	assert.NoError(ltx.SyntacticVerify())
+	_, err = NewTx(ltx)
+	assert.ErrorContains(err, "invalid decimals, expected <= 18, got 20")
+
+	ltx = &ld.Transaction{Tx: ld.TxData{
+		Type:      ld.TypeUpdateTokenInfo,
+		ChainID:   ctx.ChainConfig().ChainID,
+		Nonce:     0,
+		GasTip:    100,
+		GasFeeCap: ctx.Price,
+		From:      tokenid,
+		Data:      ld.MustMarshal(input),
+	}}
+	assert.NoError(ltx.SignWith(signer.Signer1))
+	assert.NoError(ltx.SyntacticVerify())
+	itx, err := NewTx(ltx)
+	require.NoError(t, err)
+
+	cs.CommitAccounts()
+	assert.ErrorContains(itx.Apply(ctx, cs), "invalid signatures for sender")
+	cs.CheckoutAccounts()
+
+	testToken := acct.NewAccount(ids.Address(token))
+	testToken.Init(big.NewInt(0), ctx.FeeConfig().MinTokenPledge, 0, 0)
+	assert.NoError(testToken.CreateToken(&ld.TxAccounter{
+		Threshold: ld.Uint16Ptr(1),
+		Keepers:   &signer.Keys{signer.Signer1.Key(), signer.Signer2.Key()},
+		Amount:    new(big.Int).SetUint64(unit.LDC * 10),
+	}))
+	testToken.Add(ids.NativeToken, ctx.FeeConfig().MinTokenPledge)
+	cs.AC[testToken.ID()] = testToken
+	assert.Nil(testToken.LD().TokenInfo)
+
+	cs.CommitAccounts()
+	assert.ErrorContains(itx.Apply(ctx, cs),
+		"insufficient NativeLDC balance, expected 696300, got 0")
+	cs.CheckoutAccounts()
+
+	testToken.Add(ids.NativeToken, new(big.Int).SetUint64(unit.LDC))
+	assert.NoError(itx.Apply(ctx, cs))
+
+	tokenGas := ltx.Gas()
+	assert.Equal(tokenGas*ctx.Price,
+		itx.(*TxUpdateTokenInfo).ldc.Balance().Uint64())
+	assert.Equal(tokenGas*100,
+		itx.(*TxUpdateTokenInfo).miner.Balance().Uint64())
+	assert.Equal(unit.LDC-tokenGas*(ctx.Price+100),
+		testToken.Balance().Uint64())
+	assert.Equal(uint64(1), testToken.Nonce())
+	require.NotNil(t, testToken.LD().TokenInfo)
+	assert.Equal("Linked Data Chain", testToken.LD().TokenInfo.Name)
+	assert.Equal(uint8(9), testToken.LD().TokenInfo.Decimals)
+
+	jsondata, err := itx.MarshalJSON()
+	require.NoError(t, err)
+	assert.Contains(string(jsondata), `"type":"TypeUpdateTokenInfo"`)
+	assert.Contains(string(jsondata), `"data":{"name":"Linked Data Chain","decimals":9}`)
+
+	jsondata, err = json.Marshal(testToken.LD())
+	require.NoError(t, err)
+	assert.Contains(string(jsondata), `"tokenInfo":{"name":"Linked Data Chain","decimals":9}`)
+
+	assert.NoError(cs.VerifyState())
+}
@@ -0,0 +1,103 @@
+// (c) 2022-2022, LDC Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package txn
+
+import (
+	"encoding/json"
+
+	"github.com/ldclabs/ldvm/ids"
+	"github.com/ldclabs/ldvm/ld"
+	"github.com/ldclabs/ldvm/util/erring"
+)
+
+type TxMintToken struct {
+	TxBase
+	input *ld.TxTransfer
+}
+
+func (tx *TxMintToken) MarshalJSON() ([]byte, error) {
+	if tx == nil || tx.ld == nil {
+		return []byte("null"), nil
+	}
+
+	v := tx.ld.Copy()
+	errp := erring.ErrPrefix("txn.TxMintToken.MarshalJSON: ")
+	if tx.input == nil {
+		return nil, errp.Errorf("nil tx.input")
+	}
+	d, err := json.Marshal(tx.input)
+	if err != nil {
+		return nil, errp.ErrorIf(err)
+	}
+	v.Tx.Data = d
+	return errp.ErrorMap(json.Marshal(v))
+}
+
+// TxMintToken{To: recipient, Data: TxTransfer{To: recipient, Token: token, Amount: amount}}
+func (tx *TxMintToken) SyntacticVerify() error {
+	var err error
+	errp := erring.ErrPrefix("txn.TxMintToken.SyntacticVerify: ")
+
+	if err = tx.TxBase.SyntacticVerify(); err != nil {
+		return errp.ErrorIf(err)
+	}
+
+	switch {
+	case tx.ld.Tx.To == nil:
+		return errp.Errorf("nil to as recipient")
+
+	case tx.ld.Tx.Token != nil:
+		return errp.Errorf("invalid token, should be nil")
+
+	case tx.ld.Tx.Amount != nil:
+		return errp.Errorf("invalid amount, should be nil")
+
+	case len(tx.ld.Tx.Data) == 0:
+		return errp.Errorf("invalid data")
+	}
+
+	token := ids.TokenSymbol(tx.ld.Tx.From)
+	if !token.Valid() {
+		return errp.Errorf("invalid token %s", token.GoString())
+	}
+
+	tx.input = &ld.TxTransfer{}
+	if err = tx.input.Unmarshal(tx.ld.Tx.Data); err != nil {
+		return errp.ErrorIf(err)
+	}
+	if err = tx.input.SyntacticVerify(); err != nil {
+		return errp.ErrorIf(err)
+	}
+
+	switch {
+	case tx.input.To == nil || *tx.input.To != *tx.ld.Tx.To:
+		return errp.Errorf("invalid recipient, expected %s, got %s",
+			tx.ld.Tx.To, tx.input.To)
+
+	case tx.input.Token == nil:
+		return errp.Errorf("nil token, expected %s", token.GoString())
+
+	case *tx.input.Token != token:
+		return errp.Errorf("invalid token, expected %s, got %s",
+			token.GoString(), tx.input.Token.GoString())
+
+	case tx.input.Amount == nil || tx.input.Amount.Sign() <= 0:
+		return errp.Errorf("invalid amount, expected >= 1")
+	}
+	return nil
+}
+
+func (tx *TxMintToken) Apply(ctx ChainContext, cs ChainState) error {
+	var err error
+	errp := erring.ErrPrefix("txn.TxMintToken.Apply: ")
+
+	if err = tx.TxBase.verify(ctx, cs); err != nil {
+		return errp.ErrorIf(err)
+	}
+
+	if err = tx.from.MintToken(tx.to, tx.input.Amount); err != nil {
+		return errp.ErrorIf(err)
+	}
+	return errp.ErrorIf(tx.TxBase.accept(ctx, cs))
+}
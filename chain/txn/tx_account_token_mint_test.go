@@ -0,0 +1,241 @@
+// (c) 2022-2022, LDC Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package txn
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ldclabs/ldvm/chain/acct"
+	"github.com/ldclabs/ldvm/ids"
+	"github.com/ldclabs/ldvm/ld"
+	"github.com/ldclabs/ldvm/signer"
+	"github.com/ldclabs/ldvm/unit"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTxMintToken(t *testing.T) {
+	assert := assert.New(t)
+
+	// SyntacticVerify
+	tx := &TxMintToken{}
+	assert.ErrorContains(tx.SyntacticVerify(), "nil pointer")
+	_, err := tx.MarshalJSON()
+	require.NoError(t, err)
+
+	ctx := NewMockChainContext()
+	cs := ctx.MockChainState()
+	token := ld.MustNewToken("$LDC")
+	tokenid := token.Address()
+	sender := signer.Signer1.Key().Address()
+	recipient := signer.Signer2.Key().Address()
+
+	ltx := &ld.Transaction{Tx: ld.TxData{
+		Type:      ld.TypeMintToken,
+		ChainID:   ctx.ChainConfig().ChainID,
+		Nonce:     0,
+		GasTip:    100,
+		GasFeeCap: ctx.Price,
+		From:      tokenid,
+	}}
+	assert.NoError(ltx.SignWith(signer.Signer1))
+	assert.NoError(ltx.SyntacticVerify())
+	_, err = NewTx(ltx)
+	assert.ErrorContains(err, "nil to as recipient")
+
+	ltx = &ld.Transaction{Tx: ld.TxData{
+		Type:      ld.TypeMintToken,
+		ChainID:   ctx.ChainConfig().ChainID,
+		Nonce:     0,
+		GasTip:    100,
+		GasFeeCap: ctx.Price,
+		From:      tokenid,
+		To:        recipient.Ptr(),
+		Token:     token.Ptr(),
+	}}
+	assert.NoError(ltx.SignWith(signer.Signer1))
+	assert.NoError(ltx.SyntacticVerify())
+	_, err = NewTx(ltx)
+	assert.ErrorContains(err, "invalid token, should be nil")
+
+	ltx = &ld.Transaction{Tx: ld.TxData{
+		Type:      ld.TypeMintToken,
+		ChainID:   ctx.ChainConfig().ChainID,
+		Nonce:     0,
+		GasTip:    100,
+		GasFeeCap: ctx.Price,
+		From:      tokenid,
+		To:        recipient.Ptr(),
+		Amount:    big.NewInt(1),
+	}}
+	assert.NoError(ltx.SignWith(signer.Signer1))
+	assert.NoError(ltx.SyntacticVerify())
+	_, err = NewTx(ltx)
+	assert.ErrorContains(err, "invalid amount, should be nil")
+
+	ltx = &ld.Transaction{Tx: ld.TxData{
+		Type:      ld.TypeMintToken,
+		ChainID:   ctx.ChainConfig().ChainID,
+		Nonce:     0,
+		GasTip:    100,
+		GasFeeCap: ctx.Price,
+		From:      tokenid,
+		To:        recipient.Ptr(),
+	}}
+	assert.NoError(ltx.SignWith(signer.Signer1))
+	assert.NoError(ltx.SyntacticVerify())
+	_, err = NewTx(ltx)
+	assert.ErrorContains(err, "invalid data")
+
+	input := &ld.TxTransfer{Token: token.Ptr(), Amount: big.NewInt(1)}
+	ltx = &ld.Transaction{Tx: ld.TxData{
+		Type:      ld.TypeMintToken,
+		ChainID:   ctx.ChainConfig().ChainID,
+		Nonce:     0,
+		GasTip:    100,
+		GasFeeCap: ctx.Price,
+		From:      sender,
+		To:        recipient.Ptr(),
+		Data:      ld.MustMarshal(input),
+	}}
+	assert.NoError(ltx.SignWith(signer.Signer1))
+	assert.NoError(ltx.SyntacticVerify())
+	_, err = NewTx(ltx)
+	assert.ErrorContains(err, "invalid token 0x8db97c7cECe249C2b98bdc0226cc4C2A57bF52fc")
+
+	ltx = &ld.Transaction{Tx: ld.TxData{
+		Type:      ld.TypeMintToken,
+		ChainID:   ctx.ChainConfig().ChainID,
+		Nonce:     0,
+		GasTip:    100,
+		GasFeeCap: ctx.Price,
+		From:      tokenid,
+		To:        recipient.Ptr(),
+		Data:      ld.MustMarshal(input),
+	}}
+	assert.NoError(ltx.SignWith(signer.Signer1))
+	assert.NoError(ltx.SyntacticVerify())
+	_, err = NewTx(ltx)
+	assert.ErrorContains(err, "invalid recipient")
+
+	input = &ld.TxTransfer{To: recipient.Ptr(), Amount: big.NewInt(1)}
+	ltx = &ld.Transaction{Tx: ld.TxData{
+		Type:      ld.TypeMintToken,
+		ChainID:   ctx.ChainConfig().ChainID,
+		Nonce:     0,
+		GasTip:    100,
+		GasFeeCap: ctx.Price,
+		From:      tokenid,
+		To:        recipient.Ptr(),
+		Data:      ld.MustMarshal(input),
+	}}
+	assert.NoError(ltx.SignWith(signer.Signer1))
+	assert.NoError(ltx.SyntacticVerify())
+	_, err = NewTx(ltx)
+	assert.ErrorContains(err, "nil token, expected $LDC")
+
+	input = &ld.TxTransfer{To: recipient.Ptr(), Token: ids.NativeToken.Ptr(), Amount: big.NewInt(1)}
+	ltx = &ld.Transaction{Tx: ld.TxData{
+		Type:      ld.TypeMintToken,
+		ChainID:   ctx.ChainConfig().ChainID,
+		Nonce:     0,
+		GasTip:    100,
+		GasFeeCap: ctx.Price,
+		From:      tokenid,
+		To:        recipient.Ptr(),
+		Data:      ld.MustMarshal(input),
+	}}
+	assert.NoError(ltx.SignWith(signer.Signer1))
+	assert.NoError(ltx.SyntacticVerify())
+	_, err = NewTx(ltx)
+	assert.ErrorContains(err, "invalid token, expected $LDC, got NativeLDC")
+
+	input = &ld.TxTransfer{To: recipient.Ptr(), Token: token.Ptr()}
+	ltx = &ld.Transaction{Tx: ld.TxData{
+		Type:      ld.TypeMintToken,
+		ChainID:   ctx.ChainConfig().ChainID,
+		Nonce:     0,
+		GasTip:    100,
+		GasFeeCap: ctx.Price,
+		From:      tokenid,
+		To:        recipient.Ptr(),
+		Data:      ld.MustMarshal(input),
+	}}
+	assert.NoError(ltx.SignWith(signer.Signer1))
+	assert.NoError(ltx.SyntacticVerify())
+	_, err = NewTx(ltx)
+	assert.ErrorContains(err, "invalid amount, expected >= 1")
+
+	input = &ld.TxTransfer{
+		To:     recipient.Ptr(),
+		Token:  token.Ptr(),
+		Amount: new(big.Int).SetUint64(unit.LDC * 5),
+	}
+	ltx = &ld.Transaction{Tx: ld.TxData{
+		Type:      ld.TypeMintToken,
+		ChainID:   ctx.ChainConfig().ChainID,
+		Nonce:     0,
+		GasTip:    100,
+		GasFeeCap: ctx.Price,
+		From:      tokenid,
+		To:        recipient.Ptr(),
+		Data:      ld.MustMarshal(input),
+	}}
+	assert.NoError(ltx.SignWith(signer.Signer1))
+	assert.NoError(ltx.SyntacticVerify())
+	itx, err := NewTx(ltx)
+	require.NoError(t, err)
+
+	cs.CommitAccounts()
+	assert.ErrorContains(itx.Apply(ctx, cs), "invalid signatures for sender")
+	cs.CheckoutAccounts()
+
+	testToken := acct.NewAccount(ids.Address(token))
+	testToken.Init(big.NewInt(0), ctx.FeeConfig().MinTokenPledge, 0, 0)
+	assert.NoError(testToken.CreateToken(&ld.TxAccounter{
+		Threshold: ld.Uint16Ptr(1),
+		Keepers:   &signer.Keys{signer.Signer1.Key(), signer.Signer2.Key()},
+		Amount:    new(big.Int).SetUint64(unit.LDC * 10),
+	}))
+	testToken.Add(ids.NativeToken, ctx.FeeConfig().MinTokenPledge)
+	testToken.Add(ids.NativeToken, new(big.Int).SetUint64(unit.LDC))
+	cs.AC[testToken.ID()] = testToken
+
+	cs.CommitAccounts()
+	assert.ErrorContains(itx.Apply(ctx, cs), "token $LDC is not mintable")
+	cs.CheckoutAccounts()
+
+	testToken.LD().SupplyCap = new(big.Int).SetUint64(unit.LDC * 14)
+	cs.CommitAccounts()
+	assert.ErrorContains(itx.Apply(ctx, cs),
+		"exceeded supplyCap, expected <= 14000000000, got 15000000000")
+	cs.CheckoutAccounts()
+
+	testToken.LD().SupplyCap = new(big.Int).SetUint64(unit.LDC * 15)
+	assert.NoError(itx.Apply(ctx, cs))
+
+	tokenGas := ltx.Gas()
+	assert.Equal(tokenGas*ctx.Price,
+		itx.(*TxMintToken).ldc.Balance().Uint64())
+	assert.Equal(tokenGas*100,
+		itx.(*TxMintToken).miner.Balance().Uint64())
+	assert.Equal(unit.LDC-tokenGas*(ctx.Price+100),
+		testToken.Balance().Uint64())
+	assert.Equal(unit.LDC*10, testToken.BalanceOf(token).Uint64())
+	assert.Equal(unit.LDC*15, testToken.LD().MaxTotalSupply.Uint64())
+	assert.Equal(unit.LDC*5, testToken.TotalSupply().Uint64())
+	assert.Equal(uint64(1), testToken.Nonce())
+
+	recipientAcc := cs.MustAccount(recipient)
+	assert.Equal(uint64(0), recipientAcc.Balance().Uint64())
+	assert.Equal(unit.LDC*5, recipientAcc.BalanceOf(token).Uint64())
+
+	jsondata, err := itx.MarshalJSON()
+	require.NoError(t, err)
+	assert.Contains(string(jsondata), `"type":"TypeMintToken"`)
+	assert.Contains(string(jsondata), `"data":{"to":"0x44171C37Ff5D7B7bb8Dcad5C81f16284A229E641","token":"$LDC","amount":5000000000}`)
+
+	assert.NoError(cs.VerifyState())
+}
@@ -65,8 +65,9 @@ func (tx *TxUpdateAccountInfo) SyntacticVerify() error {
 		return errp.ErrorIf(err)
 	}
 
-	if tx.input.Threshold == nil && tx.input.Approver == nil && tx.input.ApproveList == nil {
-		return errp.Errorf("no keepers nor approver")
+	if tx.input.Threshold == nil && tx.input.Approver == nil &&
+		tx.input.ApproveList == nil && tx.input.SessionKeys == nil && tx.input.Policy == nil {
+		return errp.Errorf("no keepers nor approver nor sessionKeys nor policy")
 	}
 	if tx.input.Threshold != nil && *tx.input.Threshold == 0 {
 		return errp.Errorf("invalid threshold, expected >= 1")
@@ -107,10 +108,22 @@ func (tx *TxUpdateAccountInfo) ApplyGenesis(ctx ChainContext, cs ChainState) err
 	}
 
 	if err = tx.from.UpdateKeepers(
-		tx.input.Threshold, tx.input.Keepers, tx.input.Approver, tx.input.ApproveList); err != nil {
+		tx.input.Threshold, tx.input.Keepers, tx.input.Weights, tx.input.Approver, tx.input.ApproveList); err != nil {
 		return errp.ErrorIf(err)
 	}
 
+	if tx.input.SessionKeys != nil {
+		if err = tx.from.UpdateSessionKeys(*tx.input.SessionKeys); err != nil {
+			return errp.ErrorIf(err)
+		}
+	}
+
+	if tx.input.Policy != nil {
+		if err = tx.from.UpdatePolicy(tx.input.Policy); err != nil {
+			return errp.ErrorIf(err)
+		}
+	}
+
 	return errp.ErrorIf(tx.TxBase.accept(ctx, cs))
 }
 
@@ -122,14 +135,26 @@ func (tx *TxUpdateAccountInfo) Apply(ctx ChainContext, cs ChainState) error {
 		return errp.ErrorIf(err)
 	}
 
-	if !tx.from.VerifyPlus(tx.ld.TxHash(), tx.ld.Signatures, tx.senderKey) {
+	if !tx.from.VerifyPlus(tx.ld.SigHash(), tx.ld.Signatures, tx.senderKey) {
 		return errp.Errorf("invalid signatures for keepers")
 	}
 
 	if err = tx.from.UpdateKeepers(
-		tx.input.Threshold, tx.input.Keepers, tx.input.Approver, tx.input.ApproveList); err != nil {
+		tx.input.Threshold, tx.input.Keepers, tx.input.Weights, tx.input.Approver, tx.input.ApproveList); err != nil {
 		return errp.ErrorIf(err)
 	}
 
+	if tx.input.SessionKeys != nil {
+		if err = tx.from.UpdateSessionKeys(*tx.input.SessionKeys); err != nil {
+			return errp.ErrorIf(err)
+		}
+	}
+
+	if tx.input.Policy != nil {
+		if err = tx.from.UpdatePolicy(tx.input.Policy); err != nil {
+			return errp.ErrorIf(err)
+		}
+	}
+
 	return errp.ErrorIf(tx.TxBase.accept(ctx, cs))
 }
@@ -7,6 +7,7 @@ import (
 	"math/big"
 	"testing"
 
+	"github.com/fxamacker/cbor/v2"
 	"github.com/ldclabs/ldvm/ids"
 	"github.com/ldclabs/ldvm/ld"
 	"github.com/ldclabs/ldvm/signer"
@@ -151,7 +152,7 @@ func TestTxUpdateAccountInfo(t *testing.T) {
 
 	cs.CommitAccounts()
 	assert.ErrorContains(itx.Apply(ctx, cs),
-		"insufficient NativeLDC balance, expected 1059300, got 0")
+		"insufficient NativeLDC balance, expected 1261700, got 0")
 	cs.CheckoutAccounts()
 
 	senderAcc := cs.MustAccount(sender)
@@ -180,7 +181,7 @@ func TestTxUpdateAccountInfo(t *testing.T) {
 	jsondata, err := itx.MarshalJSON()
 	require.NoError(t, err)
 	// fmt.Println(string(jsondata))
-	assert.Equal(`{"tx":{"type":"TypeUpdateAccountInfo","chainID":2357,"nonce":0,"gasTip":100,"gasFeeCap":1000,"from":"0x8db97c7cECe249C2b98bdc0226cc4C2A57bF52fc","data":{"threshold":1,"keepers":["jbl8fOziScK5i9wCJsxMKle_UvwKxwPH"],"approver":"RBccN_9de3u43K1cgfFihKIp5kE1lmGG","approveList":["TypeUpdateNonceTable","TypeUpdateAccountInfo","TypeCreateToken","TypeDestroyToken","TypeCreateStake","TypeResetStake","TypeDestroyStake","TypeTakeStake","TypeWithdrawStake","TypeUpdateStakeApprover","TypeOpenLending","TypeCloseLending","TypeBorrow","TypeRepay"]}},"sigs":["8XFo0t3PUW4mO9J60r1AC4m4SCBT7Udgq6eClT2_LksF0f-W17wKHAcmgpNzV5IWYC5jGsJfjCNSs8xrlHIxVABb1Egx"],"id":"_9nauLRM0XLKzAumQThx_afPZ9yBbttrgLAc28u9uJDeOEkU"}`, string(jsondata))
+	assert.Equal(`{"tx":{"type":"TypeUpdateAccountInfo","chainID":2357,"nonce":0,"gasTip":100,"gasFeeCap":1000,"from":"0x8db97c7cECe249C2b98bdc0226cc4C2A57bF52fc","data":{"threshold":1,"keepers":["jbl8fOziScK5i9wCJsxMKle_UvwKxwPH"],"approver":"RBccN_9de3u43K1cgfFihKIp5kE1lmGG","approveList":["TypeUpdateNonceTable","TypeUpdateAccountInfo","TypeCreateToken","TypeDestroyToken","TypeCreateStake","TypeResetStake","TypeDestroyStake","TypeTakeStake","TypeWithdrawStake","TypeUpdateStakeApprover","TypeOpenLending","TypeCloseLending","TypeBorrow","TypeRepay","TypeCreateVesting","TypeClaimVesting","TypeMintToken","TypeBurnToken","TypeUpdateTokenInfo","TypeSetRecovery","TypeStartRecovery","TypeCancelRecovery","TypeExecuteRecovery","TypeCloseAccount","TypeScheduleTx","TypeCreateSubscription","TypePullSubscription","TypeRevokeSubscription","TypeCreateProposal","TypeApproveProposal"]}},"sigs":["9OVa89DZwaH_HKM6s2iKOZQvKWkLhDiI5eqKVvnV6jZtC-h8mD6XxEa7L3jbPnSggXZNEqMJV364gum5mTYq2gFfmRLt"],"id":"W9n7bjtTHp6HxaF9cBZq-ooYw2bpZ3Db0RSfL_0UbNcctVm4"}`, string(jsondata))
 
 	// update ApproveList
 	input = ld.TxAccounter{
@@ -340,6 +341,36 @@ func TestTxUpdateAccountInfo(t *testing.T) {
 	assert.Equal(signer.Signer2.Key(), senderAcc.LD().Approver)
 	assert.Nil(senderAcc.LD().ApproveList)
 
+	// set SessionKeys
+	input = ld.TxAccounter{
+		SessionKeys: &ld.SessionKeys{{
+			Key:        signer.Signer3.Key(),
+			TxTypes:    ld.TxTypes{ld.TypeUpdateData},
+			MaxAmounts: map[cbor.ByteString]*big.Int{ids.NativeToken.AsKey(): big.NewInt(1000)},
+			Expire:     cs.Timestamp() + 3600,
+		}},
+	}
+	assert.NoError(input.SyntacticVerify())
+	ltx = &ld.Transaction{Tx: ld.TxData{
+		Type:      ld.TypeUpdateAccountInfo,
+		ChainID:   ctx.ChainConfig().ChainID,
+		Nonce:     5,
+		GasTip:    100,
+		GasFeeCap: ctx.Price,
+		From:      sender,
+		Data:      input.Bytes(),
+	}}
+	assert.NoError(ltx.SignWith(signer.Signer1, signer.Signer2))
+	assert.NoError(ltx.SyntacticVerify())
+	itx, err = NewTx(ltx)
+	require.NoError(t, err)
+	assert.NoError(itx.Apply(ctx, cs))
+
+	require.Equal(t, 1, len(senderAcc.LD().SessionKeys))
+	assert.Equal(signer.Signer3.Key(), senderAcc.LD().SessionKeys[0].Key)
+	assert.Equal(ld.TxTypes{ld.TypeUpdateData}, senderAcc.LD().SessionKeys[0].TxTypes)
+	assert.Equal(signer.Keys{signer.Signer1.Key(), signer.Signer2.Key()}, senderAcc.Keepers())
+
 	// clear keepers should failed
 	input = ld.TxAccounter{
 		Threshold: ld.Uint16Ptr(0),
@@ -349,7 +380,7 @@ func TestTxUpdateAccountInfo(t *testing.T) {
 	ltx = &ld.Transaction{Tx: ld.TxData{
 		Type:      ld.TypeUpdateAccountInfo,
 		ChainID:   ctx.ChainConfig().ChainID,
-		Nonce:     5,
+		Nonce:     6,
 		GasTip:    100,
 		GasFeeCap: ctx.Price,
 		From:      sender,
@@ -360,6 +391,116 @@ func TestTxUpdateAccountInfo(t *testing.T) {
 	_, err = NewTx(ltx)
 	assert.ErrorContains(err, "invalid threshold, expected >= 1")
 
+	// weighted keepers
+	input = ld.TxAccounter{
+		Threshold: ld.Uint16Ptr(2),
+		Keepers:   &signer.Keys{signer.Signer1.Key(), signer.Signer2.Key()},
+		Weights:   &[]uint16{3, 1},
+		Approver:  &signer.Key{},
+	}
+	assert.NoError(input.SyntacticVerify())
+	ltx = &ld.Transaction{Tx: ld.TxData{
+		Type:      ld.TypeUpdateAccountInfo,
+		ChainID:   ctx.ChainConfig().ChainID,
+		Nonce:     6,
+		GasTip:    100,
+		GasFeeCap: ctx.Price,
+		From:      sender,
+		Data:      input.Bytes(),
+	}}
+	assert.NoError(ltx.SignWith(signer.Signer1, signer.Signer2))
+	assert.NoError(ltx.SyntacticVerify())
+	itx, err = NewTx(ltx)
+	require.NoError(t, err)
+	assert.NoError(itx.Apply(ctx, cs))
+	assert.Equal(uint16(2), senderAcc.Threshold())
+	assert.Equal([]uint16{3, 1}, senderAcc.Weights())
+	assert.Nil(senderAcc.LD().Approver)
+
+	input = ld.TxAccounter{
+		Threshold: ld.Uint16Ptr(1),
+		Keepers:   &signer.Keys{signer.Signer1.Key(), signer.Signer2.Key()},
+	}
+	assert.NoError(input.SyntacticVerify())
+	ltx = &ld.Transaction{Tx: ld.TxData{
+		Type:      ld.TypeUpdateAccountInfo,
+		ChainID:   ctx.ChainConfig().ChainID,
+		Nonce:     7,
+		GasTip:    100,
+		GasFeeCap: ctx.Price,
+		From:      sender,
+		Data:      input.Bytes(),
+	}}
+	assert.NoError(ltx.SignWith(signer.Signer2))
+	assert.NoError(ltx.SyntacticVerify())
+	itx, err = NewTx(ltx)
+	require.NoError(t, err)
+	cs.CommitAccounts()
+	assert.ErrorContains(itx.Apply(ctx, cs),
+		"invalid signatures for sender")
+	cs.CheckoutAccounts()
+
+	// the CFO key alone reaches threshold + 1
+	assert.NoError(ltx.SignWith(signer.Signer1))
+	assert.NoError(ltx.SyntacticVerify())
+	itx, err = NewTx(ltx)
+	require.NoError(t, err)
+	assert.NoError(itx.Apply(ctx, cs))
+	assert.Equal(uint16(1), senderAcc.Threshold())
+	assert.Nil(senderAcc.Weights())
+
+	// outflow policy
+	input = ld.TxAccounter{
+		Policy: &ld.AccountPolicy{Allowlist: []ids.Address{ids.GenesisAccount}, Delay: 3600},
+	}
+	assert.NoError(input.SyntacticVerify())
+	ltx = &ld.Transaction{Tx: ld.TxData{
+		Type:      ld.TypeUpdateAccountInfo,
+		ChainID:   ctx.ChainConfig().ChainID,
+		Nonce:     8,
+		GasTip:    100,
+		GasFeeCap: ctx.Price,
+		From:      sender,
+		Data:      input.Bytes(),
+	}}
+	assert.NoError(ltx.SignWith(signer.Signer1))
+	assert.NoError(ltx.SyntacticVerify())
+	itx, err = NewTx(ltx)
+	require.NoError(t, err)
+	cs.CommitAccounts()
+	assert.ErrorContains(itx.Apply(ctx, cs), "invalid signatures for keepers")
+	cs.CheckoutAccounts()
+
+	assert.NoError(ltx.SignWith(signer.Signer1, signer.Signer2))
+	assert.NoError(ltx.SyntacticVerify())
+	itx, err = NewTx(ltx)
+	require.NoError(t, err)
+	assert.NoError(itx.Apply(ctx, cs))
+	require.NotNil(t, senderAcc.LD().Policy)
+	assert.Equal(input.Policy, senderAcc.LD().Policy.Active)
+
+	// removing the policy takes effect after the delay
+	input = ld.TxAccounter{Policy: &ld.AccountPolicy{}}
+	assert.NoError(input.SyntacticVerify())
+	ltx = &ld.Transaction{Tx: ld.TxData{
+		Type:      ld.TypeUpdateAccountInfo,
+		ChainID:   ctx.ChainConfig().ChainID,
+		Nonce:     9,
+		GasTip:    100,
+		GasFeeCap: ctx.Price,
+		From:      sender,
+		Data:      input.Bytes(),
+	}}
+	assert.NoError(ltx.SignWith(signer.Signer1, signer.Signer2))
+	assert.NoError(ltx.SyntacticVerify())
+	itx, err = NewTx(ltx)
+	require.NoError(t, err)
+	assert.NoError(itx.Apply(ctx, cs))
+	require.NotNil(t, senderAcc.LD().Policy)
+	assert.NotNil(senderAcc.LD().Policy.Active)
+	assert.Equal(input.Policy, senderAcc.LD().Policy.Pending)
+	assert.Equal(cs.Timestamp()+3600, senderAcc.LD().Policy.EffectiveAt)
+
 	assert.NoError(cs.VerifyState())
 }
 
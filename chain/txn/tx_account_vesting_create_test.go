@@ -0,0 +1,189 @@
+// (c) 2022-2022, LDC Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package txn
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/fxamacker/cbor/v2"
+	"github.com/ldclabs/ldvm/ids"
+	"github.com/ldclabs/ldvm/ld"
+	"github.com/ldclabs/ldvm/signer"
+	"github.com/ldclabs/ldvm/unit"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTxCreateVesting(t *testing.T) {
+	assert := assert.New(t)
+
+	// SyntacticVerify
+	tx := &TxCreateVesting{}
+	assert.ErrorContains(tx.SyntacticVerify(), "nil pointer")
+	_, err := tx.MarshalJSON()
+	require.NoError(t, err)
+
+	ctx := NewMockChainContext()
+	cs := ctx.MockChainState()
+	sender := signer.Signer1.Key().Address()
+	beneficiary := signer.Signer2.Key().Address()
+
+	ltx := &ld.Transaction{Tx: ld.TxData{
+		Type:      ld.TypeCreateVesting,
+		ChainID:   ctx.ChainConfig().ChainID,
+		Nonce:     0,
+		GasTip:    100,
+		GasFeeCap: ctx.Price,
+		From:      sender,
+	}}
+	assert.NoError(ltx.SignWith(signer.Signer1))
+	assert.NoError(ltx.SyntacticVerify())
+	_, err = NewTx(ltx)
+	assert.ErrorContains(err, "nil to as beneficiary")
+
+	ltx = &ld.Transaction{Tx: ld.TxData{
+		Type:      ld.TypeCreateVesting,
+		ChainID:   ctx.ChainConfig().ChainID,
+		Nonce:     0,
+		GasTip:    100,
+		GasFeeCap: ctx.Price,
+		From:      sender,
+		To:        &beneficiary,
+		Amount:    big.NewInt(1),
+	}}
+	assert.NoError(ltx.SignWith(signer.Signer1))
+	assert.NoError(ltx.SyntacticVerify())
+	_, err = NewTx(ltx)
+	assert.ErrorContains(err, "invalid amount, should be nil")
+
+	ltx = &ld.Transaction{Tx: ld.TxData{
+		Type:      ld.TypeCreateVesting,
+		ChainID:   ctx.ChainConfig().ChainID,
+		Nonce:     0,
+		GasTip:    100,
+		GasFeeCap: ctx.Price,
+		From:      sender,
+		To:        &beneficiary,
+	}}
+	assert.NoError(ltx.SignWith(signer.Signer1))
+	assert.NoError(ltx.SyntacticVerify())
+	_, err = NewTx(ltx)
+	assert.ErrorContains(err, "invalid data")
+
+	ltx = &ld.Transaction{Tx: ld.TxData{
+		Type:      ld.TypeCreateVesting,
+		ChainID:   ctx.ChainConfig().ChainID,
+		Nonce:     0,
+		GasTip:    100,
+		GasFeeCap: ctx.Price,
+		From:      sender,
+		To:        &beneficiary,
+		Data:      []byte("d"),
+	}}
+	assert.NoError(ltx.SignWith(signer.Signer1))
+	assert.NoError(ltx.SyntacticVerify())
+	_, err = NewTx(ltx)
+	assert.ErrorContains(err, "unexpected EOF")
+
+	input := &ld.VestingConfig{Amount: new(big.Int).SetUint64(unit.LDC * 10)}
+	ltx = &ld.Transaction{Tx: ld.TxData{
+		Type:      ld.TypeCreateVesting,
+		ChainID:   ctx.ChainConfig().ChainID,
+		Nonce:     0,
+		GasTip:    100,
+		GasFeeCap: ctx.Price,
+		From:      sender,
+		To:        &beneficiary,
+		Data:      ld.MustMarshal(input),
+	}}
+	assert.NoError(ltx.SignWith(signer.Signer1))
+	assert.NoError(ltx.SyntacticVerify())
+	_, err = NewTx(ltx)
+	assert.ErrorContains(err, "invalid endTime")
+
+	input = &ld.VestingConfig{
+		Amount:    new(big.Int).SetUint64(unit.LDC * 10),
+		CliffTime: cs.Timestamp(),
+		EndTime:   cs.Timestamp(),
+	}
+	ltx = &ld.Transaction{Tx: ld.TxData{
+		Type:      ld.TypeCreateVesting,
+		ChainID:   ctx.ChainConfig().ChainID,
+		Nonce:     0,
+		GasTip:    100,
+		GasFeeCap: ctx.Price,
+		From:      sender,
+		To:        &beneficiary,
+		Data:      ld.MustMarshal(input),
+	}}
+	assert.NoError(ltx.SignWith(signer.Signer1))
+	assert.NoError(ltx.SyntacticVerify())
+	ltx.Timestamp = cs.Timestamp()
+	_, err = NewTx(ltx)
+	assert.ErrorContains(err, "invalid endTime, expected > 1000, got 1000")
+
+	input = &ld.VestingConfig{
+		Amount:    new(big.Int).SetUint64(unit.LDC * 10),
+		CliffTime: cs.Timestamp() + 100,
+		EndTime:   cs.Timestamp() + 1000,
+	}
+	ltx = &ld.Transaction{Tx: ld.TxData{
+		Type:      ld.TypeCreateVesting,
+		ChainID:   ctx.ChainConfig().ChainID,
+		Nonce:     0,
+		GasTip:    100,
+		GasFeeCap: ctx.Price,
+		From:      sender,
+		To:        &beneficiary,
+		Data:      ld.MustMarshal(input),
+	}}
+	assert.NoError(ltx.SignWith(signer.Signer1))
+	assert.NoError(ltx.SyntacticVerify())
+	ltx.Timestamp = cs.Timestamp()
+	itx, err := NewTx(ltx)
+	require.NoError(t, err)
+
+	cs.CommitAccounts()
+	assert.ErrorContains(itx.Apply(ctx, cs),
+		"insufficient NativeLDC balance, expected 1271600, got 0")
+	cs.CheckoutAccounts()
+
+	senderAcc := cs.MustAccount(sender)
+	senderAcc.Add(ids.NativeToken, new(big.Int).SetUint64(unit.LDC*5))
+	cs.CommitAccounts()
+	assert.ErrorContains(itx.Apply(ctx, cs),
+		"insufficient transferable NativeLDC balance, expected 10000000000")
+	cs.CheckoutAccounts()
+
+	senderAcc.Add(ids.NativeToken, new(big.Int).SetUint64(unit.LDC*10))
+	assert.NoError(itx.Apply(ctx, cs))
+
+	senderGas := ltx.Gas()
+	assert.Equal(senderGas*ctx.Price,
+		itx.(*TxCreateVesting).ldc.Balance().Uint64())
+	assert.Equal(senderGas*100,
+		itx.(*TxCreateVesting).miner.Balance().Uint64())
+	assert.Equal(unit.LDC*5-senderGas*(ctx.Price+100),
+		senderAcc.BalanceOfAll(ids.NativeToken).Uint64())
+	assert.Equal(uint64(1), senderAcc.Nonce())
+
+	beneficiaryAcc := cs.MustAccount(beneficiary)
+	assert.Equal(uint64(0), beneficiaryAcc.Balance().Uint64())
+	assert.Equal(unit.LDC*10, beneficiaryAcc.VestingOf(ids.NativeToken).Uint64())
+	entry := beneficiaryAcc.Ledger().Vesting[cbor.ByteString(ltx.ID[:])]
+	require.NotNil(t, entry)
+	assert.Equal(sender, entry.Grantor)
+	assert.Equal(ids.NativeToken, entry.Token)
+	assert.Equal(cs.Timestamp(), entry.StartTime)
+	assert.Equal(cs.Timestamp()+100, entry.CliffTime)
+	assert.Equal(cs.Timestamp()+1000, entry.EndTime)
+
+	jsondata, err := itx.MarshalJSON()
+	require.NoError(t, err)
+	assert.Contains(string(jsondata), `"type":"TypeCreateVesting"`)
+	assert.Contains(string(jsondata), `"data":{"amount":10000000000,"cliffTime":1100,"endTime":2000}`)
+
+	assert.NoError(cs.VerifyState())
+}
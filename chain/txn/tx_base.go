@@ -16,17 +16,20 @@ import (
 )
 
 type TxBase struct {
-	ld        *ld.Transaction
-	ldc       *acct.Account // native token account
-	miner     *acct.Account
-	from      *acct.Account
-	to        *acct.Account
-	amount    *big.Int
-	fee       *big.Int
-	tip       *big.Int
-	cost      *big.Int // fee + tip
-	token     ids.TokenSymbol
-	senderKey signer.Key
+	ld         *ld.Transaction
+	ldc        *acct.Account // native token account
+	miner      *acct.Account
+	from       *acct.Account
+	to         *acct.Account
+	payer      *acct.Account // the gas sponsor that pays the cost, if any
+	amount     *big.Int
+	fee        *big.Int
+	tip        *big.Int
+	cost       *big.Int // fee + tip
+	token      ids.TokenSymbol
+	senderKey  signer.Key
+	sessionKey signer.Key // the sender's session key that signed the tx, if any
+	scheduled  bool       // executed as a scheduled tx, its nonce was consumed by TxScheduleTx
 }
 
 func (tx *TxBase) MarshalJSON() ([]byte, error) {
@@ -73,6 +76,9 @@ func (tx *TxBase) SyntacticVerify() error {
 
 	case len(tx.ld.Signatures) == 0:
 		return errp.Errorf("no signatures")
+
+	case tx.ld.Tx.Payer != nil && len(tx.ld.PayerSignatures) == 0:
+		return errp.Errorf("no payerSignatures")
 	}
 
 	tx.senderKey = signer.Key(tx.ld.Tx.From.Bytes())
@@ -90,6 +96,15 @@ func (tx *TxBase) verify(ctx ChainContext, cs ChainState) error {
 	var err error
 	feeCfg := ctx.FeeConfig()
 
+	// the scheduled tx's signatures commit to the schedule domain
+	switch {
+	case tx.ld.Scheduled && !tx.scheduled:
+		return fmt.Errorf("invalid tx, scheduled tx can't be executed directly")
+
+	case !tx.ld.Scheduled && tx.scheduled:
+		return fmt.Errorf("invalid tx, should be signed for schedule")
+	}
+
 	if price := ctx.GasPrice().Uint64(); tx.ld.Tx.GasFeeCap < price {
 		return fmt.Errorf("invalid gasFeeCap, expected >= %d, got %d",
 			price, tx.ld.Tx.GasFeeCap)
@@ -128,28 +143,59 @@ func (tx *TxBase) verify(ctx ChainContext, cs ChainState) error {
 		}
 	}
 
-	switch {
-	case tx.ld.Tx.Nonce != tx.from.Nonce():
+	if tx.ld.Tx.Payer != nil {
+		if tx.payer, err = cs.LoadAccount(*tx.ld.Tx.Payer); err != nil {
+			return err
+		}
+		if !tx.payer.Verify(tx.ld.SigHash(), tx.ld.PayerSignatures, tx.payer.IDKey()) {
+			return fmt.Errorf("invalid signatures for payer")
+		}
+		if !tx.ld.IsPayerApproved(tx.payer.LD().Approver, tx.payer.LD().ApproveList) {
+			return fmt.Errorf("invalid signature for payer's approver")
+		}
+		if err = tx.payer.CheckBalance(ids.NativeToken, tx.cost, false); err != nil {
+			return err
+		}
+	}
+
+	if !tx.scheduled && tx.ld.Tx.Nonce != tx.from.Nonce() {
 		return fmt.Errorf("invalid nonce for sender, expected %d, got %d",
 			tx.from.Nonce(), tx.ld.Tx.Nonce)
+	}
 
-	case tx.ld.Tx.Type != ld.TypeEth &&
-		!tx.from.Verify(tx.ld.TxHash(), tx.ld.Signatures, tx.senderKey):
-		return fmt.Errorf("invalid signatures for sender")
+	if tx.ld.Tx.Type != ld.TypeEth &&
+		!tx.from.Verify(tx.ld.SigHash(), tx.ld.Signatures, tx.senderKey) {
+		if len(tx.from.LD().SessionKeys) == 0 {
+			return fmt.Errorf("invalid signatures for sender")
+		}
 
-	case !tx.ld.IsApproved(tx.from.LD().Approver, tx.from.LD().ApproveList, false):
+		// fall back to the sender's session keys, they are scoped by TxTypes, caps and expire
+		if tx.sessionKey, err = tx.from.VerifySessionKey(tx.ld.Tx.Type,
+			tx.ld.SigHash(), tx.ld.Signatures, tx.token, tx.amount, tx.senderCost()); err != nil {
+			return fmt.Errorf("invalid signatures for sender, %v", err)
+		}
+	}
+
+	if !tx.ld.IsApproved(tx.from.LD().Approver, tx.from.LD().ApproveList, false) {
 		return fmt.Errorf("invalid signature for approver")
 	}
 
+	if err = tx.from.CheckPolicyTxType(tx.ld.Tx.Type); err != nil {
+		return err
+	}
+	if err = tx.from.CheckPolicy(tx.ld.Tx.To, tx.token, tx.amount); err != nil {
+		return err
+	}
+
 	switch tx.token {
 	case ids.NativeToken:
 		if err = tx.from.CheckBalance(ids.NativeToken,
-			new(big.Int).Add(tx.amount, tx.cost), tx.amount.Sign() > 0); err != nil {
+			new(big.Int).Add(tx.amount, tx.senderCost()), tx.amount.Sign() > 0); err != nil {
 			return err
 		}
 
 	default:
-		if err = tx.from.CheckBalance(ids.NativeToken, tx.cost, false); err != nil {
+		if err = tx.from.CheckBalance(ids.NativeToken, tx.senderCost(), false); err != nil {
 			return err
 		}
 		if err = tx.from.CheckBalance(tx.token, tx.amount, false); err != nil {
@@ -161,11 +207,30 @@ func (tx *TxBase) verify(ctx ChainContext, cs ChainState) error {
 
 func (tx *TxBase) accept(ctx ChainContext, cs ChainState) error {
 	var err error
-	if err = tx.from.SubGasByNonce(ids.NativeToken, tx.ld.Tx.Nonce, tx.cost); err != nil {
+	if tx.sessionKey != nil {
+		if err = tx.from.SpendBySessionKey(tx.sessionKey, tx.token, tx.amount, tx.senderCost()); err != nil {
+			return err
+		}
+	}
+
+	if tx.scheduled {
+		err = tx.from.SubGas(tx.senderCost())
+	} else {
+		err = tx.from.SubGasByNonce(ids.NativeToken, tx.ld.Tx.Nonce, tx.senderCost())
+	}
+	if err != nil {
 		return err
 	}
+	if tx.payer != nil {
+		if err = tx.payer.SubGas(tx.cost); err != nil {
+			return err
+		}
+	}
 
 	if tx.amount.Sign() > 0 {
+		if err = tx.from.SpendByPolicy(tx.token, tx.amount); err != nil {
+			return err
+		}
 		if err = tx.from.Sub(tx.token, tx.amount); err != nil {
 			return err
 		}
@@ -183,6 +248,32 @@ func (tx *TxBase) accept(ctx ChainContext, cs ChainState) error {
 	return nil
 }
 
+// setScheduled marks the tx as a scheduled tx.
+func (tx *TxBase) setScheduled() {
+	tx.scheduled = true
+}
+
+// setDataExpiry sets the data's expiresAt and indexes it by the expiry time,
+// so that the data can be purged lazily after expired. 0 expiresAt means never.
+func (tx *TxBase) setDataExpiry(cs ChainState, di *ld.DataInfo, expiresAt uint64) error {
+	if expiresAt > 0 && expiresAt <= cs.Timestamp() {
+		return fmt.Errorf("invalid expiresAt, expected > %d, got %d", cs.Timestamp(), expiresAt)
+	}
+	if err := cs.SetDataExpiry(di.ID, di.ExpiresAt, expiresAt); err != nil {
+		return err
+	}
+	di.ExpiresAt = expiresAt
+	return nil
+}
+
+// senderCost returns the cost paid by the sender, it is zero if the tx has a payer.
+func (tx *TxBase) senderCost() *big.Int {
+	if tx.payer != nil {
+		return new(big.Int)
+	}
+	return tx.cost
+}
+
 // call after SyntacticVerify
 func (tx *TxBase) Apply(ctx ChainContext, cs ChainState) error {
 	errp := erring.ErrPrefix("txn.TxBase.Apply: ")
@@ -7,6 +7,7 @@ import (
 	"math/big"
 	"testing"
 
+	"github.com/fxamacker/cbor/v2"
 	"github.com/ldclabs/ldvm/ids"
 	"github.com/ldclabs/ldvm/ld"
 	"github.com/ldclabs/ldvm/signer"
@@ -205,3 +206,275 @@ func TestTxBase(t *testing.T) {
 
 	assert.NoError(cs.VerifyState())
 }
+
+func TestTxBaseWithSessionKey(t *testing.T) {
+	assert := assert.New(t)
+
+	ctx := NewMockChainContext()
+	cs := ctx.MockChainState()
+	sender := signer.Signer1.Key().Address()
+
+	senderAcc := cs.MustAccount(sender)
+	senderAcc.Add(ids.NativeToken, new(big.Int).SetUint64(unit.LDC*2))
+	assert.NoError(senderAcc.UpdateSessionKeys(ld.SessionKeys{{
+		Key:     signer.Signer2.Key(),
+		TxTypes: ld.TxTypes{ld.TypeTransfer},
+		MaxAmounts: map[cbor.ByteString]*big.Int{
+			ids.NativeToken.AsKey(): new(big.Int).SetUint64(unit.MilliLDC),
+		},
+		Expire: cs.Timestamp() + 3600,
+	}}))
+
+	ltx := &ld.Transaction{Tx: ld.TxData{
+		Type:      ld.TypeTransfer,
+		ChainID:   ctx.ChainConfig().ChainID,
+		Nonce:     0,
+		GasTip:    100,
+		GasFeeCap: ctx.Price,
+		From:      sender,
+		To:        ids.GenesisAccount.Ptr(),
+		Amount:    new(big.Int).SetUint64(unit.MilliLDC),
+	}}
+	tx := &TxBase{ld: ltx}
+	assert.NoError(ltx.SignWith(signer.Signer3))
+	assert.NoError(ltx.SyntacticVerify())
+	assert.NoError(tx.SyntacticVerify())
+	assert.ErrorContains(tx.Apply(ctx, cs),
+		"invalid signatures for sender, acct.Account(0x8db97c7cECe249C2b98bdc0226cc4C2A57bF52fc).VerifySessionKey: no session key signed")
+
+	tx = &TxBase{ld: ltx}
+	assert.NoError(ltx.SignWith(signer.Signer2))
+	assert.NoError(ltx.SyntacticVerify())
+	assert.NoError(tx.SyntacticVerify())
+	assert.ErrorContains(tx.Apply(ctx, cs),
+		"insufficient NativeLDC cap of session key RBccN_9de3u43K1cgfFihKIp5kE1lmGG")
+
+	ltx = &ld.Transaction{Tx: ld.TxData{
+		Type:      ld.TypeTransfer,
+		ChainID:   ctx.ChainConfig().ChainID,
+		Nonce:     0,
+		GasTip:    100,
+		GasFeeCap: ctx.Price,
+		From:      sender,
+		To:        ids.GenesisAccount.Ptr(),
+		Amount:    new(big.Int).SetUint64(1000),
+	}}
+	tx = &TxBase{ld: ltx}
+	assert.NoError(ltx.SignWith(signer.Signer2))
+	assert.NoError(ltx.SyntacticVerify())
+	assert.NoError(tx.SyntacticVerify())
+	assert.NoError(tx.Apply(ctx, cs))
+
+	senderGas := ltx.Gas()
+	assert.Equal(unit.LDC-senderGas*(ctx.Price+100)-1000,
+		senderAcc.Balance().Uint64())
+	assert.Equal(uint64(1000), tx.to.Balance().Uint64())
+	assert.Equal(uint64(1), senderAcc.Nonce())
+	assert.Equal(unit.MilliLDC-senderGas*(ctx.Price+100)-1000,
+		senderAcc.LD().SessionKeys[0].MaxAmounts[ids.NativeToken.AsKey()].Uint64())
+
+	ltx = &ld.Transaction{Tx: ld.TxData{
+		Type:      ld.TypeUpdateNonceTable,
+		ChainID:   ctx.ChainConfig().ChainID,
+		Nonce:     1,
+		GasTip:    100,
+		GasFeeCap: ctx.Price,
+		From:      sender,
+	}}
+	tx = &TxBase{ld: ltx}
+	assert.NoError(ltx.SignWith(signer.Signer2))
+	assert.NoError(ltx.SyntacticVerify())
+	assert.NoError(tx.SyntacticVerify())
+	assert.ErrorContains(tx.Apply(ctx, cs),
+		"session key RBccN_9de3u43K1cgfFihKIp5kE1lmGG can't send TypeUpdateNonceTable")
+
+	assert.NoError(cs.VerifyState())
+}
+
+func TestTxBaseWithPayer(t *testing.T) {
+	assert := assert.New(t)
+
+	ctx := NewMockChainContext()
+	cs := ctx.MockChainState()
+	sender := signer.Signer1.Key().Address()
+	payer := signer.Signer2.Key().Address()
+
+	ltx := &ld.Transaction{Tx: ld.TxData{
+		Type:      ld.TypeTransfer,
+		ChainID:   ctx.ChainConfig().ChainID,
+		Nonce:     0,
+		GasTip:    100,
+		GasFeeCap: ctx.Price,
+		From:      sender,
+		To:        ids.GenesisAccount.Ptr(),
+		Payer:     &payer,
+	}}
+	tx := &TxBase{ld: ltx}
+	assert.NoError(ltx.SignWith(signer.Signer1))
+	assert.NoError(ltx.SyntacticVerify())
+	assert.ErrorContains(tx.SyntacticVerify(), "no payerSignatures")
+
+	tx = &TxBase{ld: ltx}
+	assert.NoError(ltx.PayerSignWith(signer.Signer1))
+	assert.NoError(ltx.SyntacticVerify())
+	assert.NoError(tx.SyntacticVerify())
+	assert.ErrorContains(tx.Apply(ctx, cs), "invalid signatures for payer")
+
+	tx = &TxBase{ld: ltx}
+	assert.NoError(ltx.PayerSignWith(signer.Signer2))
+	assert.NoError(ltx.SyntacticVerify())
+	assert.NoError(tx.SyntacticVerify())
+	cs.CommitAccounts()
+	assert.ErrorContains(tx.Apply(ctx, cs),
+		"insufficient NativeLDC balance, expected 1224300, got 0")
+	cs.CheckoutAccounts()
+
+	payerAcc := cs.MustAccount(payer)
+	payerAcc.Add(ids.NativeToken, new(big.Int).SetUint64(unit.LDC))
+	assert.NoError(tx.Apply(ctx, cs))
+
+	senderAcc := cs.MustAccount(sender)
+	payerGas := ltx.Gas()
+	assert.Equal(payerGas*ctx.Price, tx.ldc.Balance().Uint64())
+	assert.Equal(payerGas*100, tx.miner.Balance().Uint64())
+	assert.Equal(uint64(0), senderAcc.Balance().Uint64())
+	assert.Equal(uint64(1), senderAcc.Nonce())
+	assert.Equal(unit.LDC-payerGas*(ctx.Price+100), payerAcc.BalanceOfAll(ids.NativeToken).Uint64())
+	assert.Equal(uint64(0), payerAcc.Nonce())
+
+	// replay protected by the sender's nonce
+	tx = &TxBase{ld: ltx}
+	assert.NoError(tx.SyntacticVerify())
+	assert.ErrorContains(tx.Apply(ctx, cs), "invalid nonce for sender, expected 1, got 0")
+
+	// the sender still pays the amount
+	ltx = &ld.Transaction{Tx: ld.TxData{
+		Type:      ld.TypeTransfer,
+		ChainID:   ctx.ChainConfig().ChainID,
+		Nonce:     1,
+		GasTip:    100,
+		GasFeeCap: ctx.Price,
+		From:      sender,
+		To:        ids.GenesisAccount.Ptr(),
+		Payer:     &payer,
+		Amount:    big.NewInt(1000),
+	}}
+	tx = &TxBase{ld: ltx}
+	assert.NoError(ltx.SignWith(signer.Signer1))
+	assert.NoError(ltx.PayerSignWith(signer.Signer2))
+	assert.NoError(ltx.SyntacticVerify())
+	assert.NoError(tx.SyntacticVerify())
+	cs.CommitAccounts()
+	assert.ErrorContains(tx.Apply(ctx, cs),
+		"insufficient NativeLDC balance, expected 1000, got 0")
+	cs.CheckoutAccounts()
+
+	senderAcc.Add(ids.NativeToken, new(big.Int).SetUint64(unit.LDC+1000))
+	assert.NoError(tx.Apply(ctx, cs))
+	assert.Equal(unit.LDC, senderAcc.BalanceOfAll(ids.NativeToken).Uint64())
+	assert.Equal(uint64(2), senderAcc.Nonce())
+	assert.Equal(uint64(1000), tx.to.Balance().Uint64())
+	assert.Equal(unit.LDC-(payerGas+ltx.Gas())*(ctx.Price+100),
+		payerAcc.BalanceOfAll(ids.NativeToken).Uint64())
+
+	// the payer's approver should sign too
+	assert.NoError(payerAcc.UpdateKeepers(nil, nil, nil, signer.Signer3.Key().Ptr(), nil))
+	ltx = &ld.Transaction{Tx: ld.TxData{
+		Type:      ld.TypeTransfer,
+		ChainID:   ctx.ChainConfig().ChainID,
+		Nonce:     2,
+		GasTip:    100,
+		GasFeeCap: ctx.Price,
+		From:      sender,
+		To:        ids.GenesisAccount.Ptr(),
+		Payer:     &payer,
+	}}
+	tx = &TxBase{ld: ltx}
+	assert.NoError(ltx.SignWith(signer.Signer1, signer.Signer3))
+	assert.NoError(ltx.PayerSignWith(signer.Signer2))
+	assert.NoError(ltx.SyntacticVerify())
+	assert.NoError(tx.SyntacticVerify())
+	cs.CommitAccounts()
+	assert.ErrorContains(tx.Apply(ctx, cs), "invalid signature for payer's approver")
+	cs.CheckoutAccounts()
+
+	tx = &TxBase{ld: ltx}
+	assert.NoError(ltx.PayerSignWith(signer.Signer2, signer.Signer3))
+	assert.NoError(ltx.SyntacticVerify())
+	assert.NoError(tx.SyntacticVerify())
+	assert.NoError(tx.Apply(ctx, cs))
+	assert.Equal(uint64(3), senderAcc.Nonce())
+
+	assert.NoError(cs.VerifyState())
+}
+
+func TestTxBaseWithEIP712(t *testing.T) {
+	assert := assert.New(t)
+
+	ctx := NewMockChainContext()
+	cs := ctx.MockChainState()
+	sender := signer.Signer1.Key().Address()
+	payer := signer.Signer2.Key().Address()
+
+	senderAcc := cs.MustAccount(sender)
+	senderAcc.Add(ids.NativeToken, new(big.Int).SetUint64(unit.LDC*2))
+
+	ltx := &ld.Transaction{Tx: ld.TxData{
+		Type:      ld.TypeTransfer,
+		ChainID:   ctx.ChainConfig().ChainID,
+		Nonce:     0,
+		GasTip:    100,
+		GasFeeCap: ctx.Price,
+		From:      sender,
+		To:        ids.GenesisAccount.Ptr(),
+		Amount:    new(big.Int).SetUint64(1000),
+	}}
+	assert.NoError(ltx.SignWithEIP712(signer.Signer1))
+	ltx.EIP712 = false
+	tx := &TxBase{ld: ltx}
+	assert.NoError(ltx.SyntacticVerify())
+	assert.NoError(tx.SyntacticVerify())
+	assert.ErrorContains(tx.Apply(ctx, cs), "invalid signatures for sender")
+
+	ltx.EIP712 = true
+	tx = &TxBase{ld: ltx}
+	assert.NoError(ltx.SyntacticVerify())
+	assert.NoError(tx.SyntacticVerify())
+	assert.NoError(tx.Apply(ctx, cs))
+
+	senderGas := ltx.Gas()
+	assert.Equal(unit.LDC-senderGas*(ctx.Price+100)-1000,
+		senderAcc.Balance().Uint64())
+	assert.Equal(uint64(1000), tx.to.Balance().Uint64())
+	assert.Equal(uint64(1), senderAcc.Nonce())
+
+	// with payer
+	ltx = &ld.Transaction{Tx: ld.TxData{
+		Type:      ld.TypeTransfer,
+		ChainID:   ctx.ChainConfig().ChainID,
+		Nonce:     1,
+		GasTip:    100,
+		GasFeeCap: ctx.Price,
+		From:      sender,
+		To:        ids.GenesisAccount.Ptr(),
+		Payer:     &payer,
+		Amount:    new(big.Int).SetUint64(1000),
+	}}
+	assert.NoError(ltx.SignWithEIP712(signer.Signer1))
+	assert.NoError(ltx.PayerSignWith(signer.Signer2))
+	assert.True(signer.Signer2.Key().Verify(ltx.Tx.TypedDataHash(), ltx.PayerSignatures))
+	tx = &TxBase{ld: ltx}
+	assert.NoError(ltx.SyntacticVerify())
+	assert.NoError(tx.SyntacticVerify())
+
+	payerAcc := cs.MustAccount(payer)
+	payerAcc.Add(ids.NativeToken, new(big.Int).SetUint64(unit.LDC))
+	assert.NoError(tx.Apply(ctx, cs))
+	assert.Equal(unit.LDC-senderGas*(ctx.Price+100)-2000,
+		senderAcc.Balance().Uint64())
+	assert.Equal(unit.LDC-ltx.Gas()*(ctx.Price+100),
+		payerAcc.BalanceOfAll(ids.NativeToken).Uint64())
+	assert.Equal(uint64(2), senderAcc.Nonce())
+
+	assert.NoError(cs.VerifyState())
+}
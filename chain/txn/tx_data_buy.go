@@ -0,0 +1,161 @@
+// (c) 2022-2022, LDC Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package txn
+
+import (
+	"encoding/json"
+
+	"github.com/ldclabs/ldvm/ids"
+	"github.com/ldclabs/ldvm/ld"
+	"github.com/ldclabs/ldvm/util/erring"
+)
+
+type TxBuyData struct {
+	TxBase
+	input *ld.TxUpdater
+	di    *ld.DataInfo
+}
+
+func (tx *TxBuyData) MarshalJSON() ([]byte, error) {
+	if tx == nil || tx.ld == nil {
+		return []byte("null"), nil
+	}
+
+	v := tx.ld.Copy()
+	errp := erring.ErrPrefix("txn.TxBuyData.MarshalJSON: ")
+	if tx.input == nil {
+		return nil, errp.Errorf("nil tx.input")
+	}
+	d, err := json.Marshal(tx.input)
+	if err != nil {
+		return nil, errp.ErrorIf(err)
+	}
+	v.Tx.Data = d
+	return errp.ErrorMap(json.Marshal(v))
+}
+
+// TxBuyData{From: buyer, To: seller, Token, Amount, Data: TxUpdater{ID, Version[, Threshold, Keepers]}}
+// the sender's keepers will be used if keepers not provided.
+func (tx *TxBuyData) SyntacticVerify() error {
+	var err error
+	errp := erring.ErrPrefix("txn.TxBuyData.SyntacticVerify: ")
+
+	if err = tx.TxBase.SyntacticVerify(); err != nil {
+		return errp.ErrorIf(err)
+	}
+
+	switch {
+	case tx.ld.Tx.To == nil:
+		return errp.Errorf("nil to as seller")
+
+	case tx.ld.Tx.Amount == nil:
+		return errp.Errorf("nil amount")
+
+	case len(tx.ld.Tx.Data) == 0:
+		return errp.Errorf("invalid data")
+	}
+
+	tx.input = &ld.TxUpdater{}
+	if err = tx.input.Unmarshal(tx.ld.Tx.Data); err != nil {
+		return errp.ErrorIf(err)
+	}
+	if err = tx.input.SyntacticVerify(); err != nil {
+		return errp.ErrorIf(err)
+	}
+
+	switch {
+	case tx.input.ID == nil || *tx.input.ID == ids.EmptyDataID:
+		return errp.Errorf("invalid data id")
+
+	case tx.input.Version == 0:
+		return errp.Errorf("invalid data version")
+
+	case tx.input.Approver != nil || tx.input.ApproveList != nil ||
+		tx.input.SigClaims != nil || tx.input.To != nil || tx.input.Amount != nil ||
+		tx.input.Token != nil || tx.input.Expire != 0 || len(tx.input.Data) != 0:
+		return errp.Errorf("invalid data, only id, version, threshold and keepers are allowed")
+	}
+	return nil
+}
+
+func (tx *TxBuyData) Apply(ctx ChainContext, cs ChainState) error {
+	var err error
+	errp := erring.ErrPrefix("txn.TxBuyData.Apply: ")
+
+	if err = tx.TxBase.verify(ctx, cs); err != nil {
+		return errp.ErrorIf(err)
+	}
+
+	threshold := tx.from.Threshold()
+	keepers := tx.from.Keepers()
+	weights := tx.from.Weights()
+	if tx.input.Keepers != nil {
+		threshold = *tx.input.Threshold
+		keepers = *tx.input.Keepers
+		weights = nil
+		if tx.input.Weights != nil && len(*tx.input.Weights) > 0 {
+			weights = *tx.input.Weights
+		}
+	}
+	if len(keepers) == 0 {
+		return errp.Errorf("no keepers on sender account")
+	}
+
+	tx.di, err = cs.LoadData(*tx.input.ID)
+	if err != nil {
+		return errp.ErrorIf(err)
+	}
+
+	offer := tx.di.Offer
+	switch {
+	case tx.di.Version != tx.input.Version:
+		return errp.Errorf("invalid version, expected %d, got %d",
+			tx.di.Version, tx.input.Version)
+
+	case offer == nil:
+		return errp.Errorf("data %s is not for sale", tx.di.ID)
+
+	case offer.Version != tx.di.Version:
+		return errp.Errorf("offer is outdated, expected version %d, got %d",
+			offer.Version, tx.di.Version)
+
+	case offer.Expire < tx.ld.Timestamp:
+		return errp.Errorf("offer expired at %d", offer.Expire)
+
+	case offer.Buyer != nil && *offer.Buyer != tx.ld.Tx.From:
+		return errp.Errorf("invalid buyer, expected %s, got %s",
+			offer.Buyer, tx.ld.Tx.From)
+
+	case offer.Seller != *tx.ld.Tx.To:
+		return errp.Errorf("invalid to as seller, expected %s, got %s",
+			offer.Seller, tx.ld.Tx.To)
+
+	case offer.Token != tx.token:
+		return errp.Errorf("invalid token, expected %s, got %s",
+			offer.Token.GoString(), tx.token.GoString())
+
+	case offer.Amount.Cmp(tx.amount) != 0:
+		return errp.Errorf("invalid amount, expected %v, got %v",
+			offer.Amount, tx.amount)
+	}
+
+	tx.di.Version++
+	tx.di.Threshold = threshold
+	tx.di.Keepers = keepers
+	tx.di.Weights = weights
+	tx.di.Approver = nil
+	tx.di.ApproveList = nil
+	tx.di.Offer = nil
+	// the seller's signature claims don't apply to the new keepers
+	tx.di.SigClaims = nil
+	tx.di.Sig = nil
+
+	if err = tx.di.SyntacticVerify(); err != nil {
+		return errp.ErrorIf(err)
+	}
+	if err = cs.SaveData(tx.di); err != nil {
+		return errp.ErrorIf(err)
+	}
+	return errp.ErrorIf(tx.TxBase.accept(ctx, cs))
+}
@@ -0,0 +1,271 @@
+// (c) 2022-2022, LDC Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package txn
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ldclabs/ldvm/ids"
+	"github.com/ldclabs/ldvm/ld"
+	"github.com/ldclabs/ldvm/signer"
+	"github.com/ldclabs/ldvm/unit"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTxBuyData(t *testing.T) {
+	assert := assert.New(t)
+
+	// SyntacticVerify
+	tx := &TxBuyData{}
+	assert.ErrorContains(tx.SyntacticVerify(), "nil pointer")
+	_, err := tx.MarshalJSON()
+	require.NoError(t, err)
+
+	ctx := NewMockChainContext()
+	cs := ctx.MockChainState()
+	token := ld.MustNewToken("$LDC")
+	seller := signer.Signer1.Key().Address()
+	buyer := signer.Signer2.Key().Address()
+	did := ids.DataID{1, 2, 3, 4}
+
+	ltx := &ld.Transaction{Tx: ld.TxData{
+		Type:      ld.TypeBuyData,
+		ChainID:   ctx.ChainConfig().ChainID,
+		Nonce:     0,
+		GasTip:    100,
+		GasFeeCap: ctx.Price,
+		From:      buyer,
+	}}
+	assert.NoError(ltx.SignWith(signer.Signer2))
+	assert.NoError(ltx.SyntacticVerify())
+	_, err = NewTx(ltx)
+	assert.ErrorContains(err, "nil to as seller")
+
+	ltx = &ld.Transaction{Tx: ld.TxData{
+		Type:      ld.TypeBuyData,
+		ChainID:   ctx.ChainConfig().ChainID,
+		Nonce:     0,
+		GasTip:    100,
+		GasFeeCap: ctx.Price,
+		From:      buyer,
+		To:        &seller,
+	}}
+	assert.NoError(ltx.SignWith(signer.Signer2))
+	assert.NoError(ltx.SyntacticVerify())
+	_, err = NewTx(ltx)
+	assert.ErrorContains(err, "nil amount")
+
+	ltx = &ld.Transaction{Tx: ld.TxData{
+		Type:      ld.TypeBuyData,
+		ChainID:   ctx.ChainConfig().ChainID,
+		Nonce:     0,
+		GasTip:    100,
+		GasFeeCap: ctx.Price,
+		From:      buyer,
+		To:        &seller,
+		Amount:    big.NewInt(1000),
+	}}
+	assert.NoError(ltx.SignWith(signer.Signer2))
+	assert.NoError(ltx.SyntacticVerify())
+	_, err = NewTx(ltx)
+	assert.ErrorContains(err, "invalid data")
+
+	input := &ld.TxUpdater{}
+	ltx = &ld.Transaction{Tx: ld.TxData{
+		Type:      ld.TypeBuyData,
+		ChainID:   ctx.ChainConfig().ChainID,
+		Nonce:     0,
+		GasTip:    100,
+		GasFeeCap: ctx.Price,
+		From:      buyer,
+		To:        &seller,
+		Amount:    big.NewInt(1000),
+		Data:      input.Bytes(),
+	}}
+	assert.NoError(ltx.SignWith(signer.Signer2))
+	assert.NoError(ltx.SyntacticVerify())
+	_, err = NewTx(ltx)
+	assert.ErrorContains(err, "invalid data id")
+
+	input = &ld.TxUpdater{ID: &did}
+	ltx = &ld.Transaction{Tx: ld.TxData{
+		Type:      ld.TypeBuyData,
+		ChainID:   ctx.ChainConfig().ChainID,
+		Nonce:     0,
+		GasTip:    100,
+		GasFeeCap: ctx.Price,
+		From:      buyer,
+		To:        &seller,
+		Amount:    big.NewInt(1000),
+		Data:      input.Bytes(),
+	}}
+	assert.NoError(ltx.SignWith(signer.Signer2))
+	assert.NoError(ltx.SyntacticVerify())
+	_, err = NewTx(ltx)
+	assert.ErrorContains(err, "invalid data version")
+
+	input = &ld.TxUpdater{ID: &did, Version: 3, Amount: big.NewInt(1000)}
+	ltx = &ld.Transaction{Tx: ld.TxData{
+		Type:      ld.TypeBuyData,
+		ChainID:   ctx.ChainConfig().ChainID,
+		Nonce:     0,
+		GasTip:    100,
+		GasFeeCap: ctx.Price,
+		From:      buyer,
+		To:        &seller,
+		Amount:    big.NewInt(1000),
+		Data:      input.Bytes(),
+	}}
+	assert.NoError(ltx.SignWith(signer.Signer2))
+	assert.NoError(ltx.SyntacticVerify())
+	_, err = NewTx(ltx)
+	assert.ErrorContains(err, "invalid data, only id, version, threshold and keepers are allowed")
+
+	input = &ld.TxUpdater{ID: &did, Version: 3}
+	ltx = &ld.Transaction{Tx: ld.TxData{
+		Type:      ld.TypeBuyData,
+		ChainID:   ctx.ChainConfig().ChainID,
+		Nonce:     0,
+		GasTip:    100,
+		GasFeeCap: ctx.Price,
+		From:      buyer,
+		To:        &seller,
+		Token:     token.Ptr(),
+		Amount:    new(big.Int).SetUint64(unit.MilliLDC),
+		Data:      input.Bytes(),
+	}}
+	assert.NoError(ltx.SignWith(signer.Signer2))
+	assert.NoError(ltx.SyntacticVerify())
+	ltx.Timestamp = cs.Timestamp()
+	itx, err := NewTx(ltx)
+	require.NoError(t, err)
+
+	buyerAcc := cs.MustAccount(buyer)
+	buyerAcc.Add(ids.NativeToken, new(big.Int).SetUint64(unit.LDC))
+	buyerAcc.Add(token, new(big.Int).SetUint64(unit.LDC))
+	cs.CommitAccounts()
+	assert.ErrorContains(itx.Apply(ctx, cs), "no keepers on sender account")
+	cs.CheckoutAccounts()
+
+	assert.NoError(buyerAcc.UpdateKeepers(ld.Uint16Ptr(1), &signer.Keys{signer.Signer2.Key()}, nil, nil, nil))
+	cs.CommitAccounts()
+	assert.ErrorContains(itx.Apply(ctx, cs),
+		"AQIDBAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAACs148t not found")
+	cs.CheckoutAccounts()
+
+	sig := make(signer.Sig, 65)
+	di := &ld.DataInfo{
+		ModelID:     ld.RawModelID,
+		Version:     3,
+		Threshold:   1,
+		Keepers:     signer.Keys{signer.Signer1.Key()},
+		Payload:     []byte(`42`),
+		ApproveList: ld.TxTypes{ld.TypeUpdateData},
+		Approver:    signer.Signer1.Key(),
+		SigClaims: &ld.SigClaims{
+			Issuer:     ids.DataID{1, 2, 3, 4},
+			Subject:    ids.DataID{5, 6, 7, 8},
+			Audience:   ld.RawModelID,
+			Expiration: 10000,
+			IssuedAt:   1,
+			CWTID:      ids.ID32FromData([]byte(`42`)),
+		},
+		Sig: &sig,
+		ID:  did,
+	}
+	assert.NoError(di.SyntacticVerify())
+	assert.NoError(cs.SaveData(di))
+	cs.CommitAccounts()
+	assert.ErrorContains(itx.Apply(ctx, cs),
+		"data AQIDBAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAACs148t is not for sale")
+	cs.CheckoutAccounts()
+
+	di.Offer = &ld.DataOffer{
+		Seller:  seller,
+		Buyer:   buyer.Ptr(),
+		Token:   token,
+		Amount:  new(big.Int).SetUint64(unit.MilliLDC),
+		Expire:  cs.Timestamp() - 1,
+		Version: 2,
+	}
+	assert.NoError(cs.SaveData(di))
+	cs.CommitAccounts()
+	assert.ErrorContains(itx.Apply(ctx, cs),
+		"offer is outdated, expected version 2, got 3")
+	cs.CheckoutAccounts()
+
+	di.Offer.Version = 3
+	assert.NoError(cs.SaveData(di))
+	cs.CommitAccounts()
+	assert.ErrorContains(itx.Apply(ctx, cs), "offer expired at 999")
+	cs.CheckoutAccounts()
+
+	di.Offer.Expire = cs.Timestamp() + 100
+	di.Offer.Buyer = ids.GenesisAccount.Ptr()
+	assert.NoError(cs.SaveData(di))
+	cs.CommitAccounts()
+	assert.ErrorContains(itx.Apply(ctx, cs),
+		"invalid buyer, expected 0xFFfFFFfFfffFFfFFffFFFfFfFffFFFfffFfFFFff, got 0x44171C37Ff5D7B7bb8Dcad5C81f16284A229E641")
+	cs.CheckoutAccounts()
+
+	di.Offer.Buyer = nil
+	di.Offer.Seller = ids.GenesisAccount
+	assert.NoError(cs.SaveData(di))
+	cs.CommitAccounts()
+	assert.ErrorContains(itx.Apply(ctx, cs),
+		"invalid to as seller, expected 0xFFfFFFfFfffFFfFFffFFFfFfFffFFFfffFfFFFff, got 0x8db97c7cECe249C2b98bdc0226cc4C2A57bF52fc")
+	cs.CheckoutAccounts()
+
+	di.Offer.Seller = seller
+	di.Offer.Token = ids.NativeToken
+	assert.NoError(cs.SaveData(di))
+	cs.CommitAccounts()
+	assert.ErrorContains(itx.Apply(ctx, cs),
+		"invalid token, expected NativeLDC, got $LDC")
+	cs.CheckoutAccounts()
+
+	di.Offer.Token = token
+	di.Offer.Amount = new(big.Int).SetUint64(unit.MilliLDC * 2)
+	assert.NoError(cs.SaveData(di))
+	cs.CommitAccounts()
+	assert.ErrorContains(itx.Apply(ctx, cs),
+		"invalid amount, expected 2000000, got 1000000")
+	cs.CheckoutAccounts()
+
+	di.Offer.Amount = new(big.Int).SetUint64(unit.MilliLDC)
+	assert.NoError(cs.SaveData(di))
+	assert.NoError(itx.Apply(ctx, cs))
+
+	buyerGas := ltx.Gas()
+	assert.Equal(buyerGas*ctx.Price,
+		itx.(*TxBuyData).ldc.Balance().Uint64())
+	assert.Equal(buyerGas*100,
+		itx.(*TxBuyData).miner.Balance().Uint64())
+	assert.Equal(unit.LDC-buyerGas*(ctx.Price+100),
+		buyerAcc.BalanceOfAll(ids.NativeToken).Uint64())
+	assert.Equal(unit.LDC-unit.MilliLDC, buyerAcc.BalanceOf(token).Uint64())
+	assert.Equal(unit.MilliLDC, cs.MustAccount(seller).BalanceOf(token).Uint64())
+	assert.Equal(uint64(1), buyerAcc.Nonce())
+
+	di2, err := cs.LoadData(di.ID)
+	require.NoError(t, err)
+	assert.Equal(uint64(4), di2.Version)
+	assert.Equal(uint16(1), di2.Threshold)
+	assert.Equal(signer.Keys{signer.Signer2.Key()}, di2.Keepers)
+	assert.Equal(di.Payload, di2.Payload)
+	assert.Nil(di2.Approver)
+	assert.Nil(di2.ApproveList)
+	assert.Nil(di2.Offer)
+	assert.Nil(di2.SigClaims)
+	assert.Nil(di2.Sig)
+
+	jsondata, err := itx.MarshalJSON()
+	require.NoError(t, err)
+	assert.Contains(string(jsondata), `"type":"TypeBuyData"`)
+	assert.Contains(string(jsondata), `"data":{"id":"AQIDBAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAACs148t","version":3}`)
+
+	assert.NoError(cs.VerifyState())
+}
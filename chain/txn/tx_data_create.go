@@ -93,6 +93,9 @@ func (tx *TxCreateData) SyntacticVerify() error {
 		Payload:   tx.input.Data,
 		ID:        ids.DataID(tx.ld.ID),
 	}
+	if tx.input.Weights != nil && len(*tx.input.Weights) > 0 {
+		tx.di.Weights = *tx.input.Weights
+	}
 
 	if tx.input.Approver != nil {
 		tx.di.Approver = *tx.input.Approver
@@ -178,6 +181,9 @@ func (tx *TxCreateData) ApplyGenesis(ctx ChainContext, cs ChainState) error {
 		Payload:   tx.input.Data,
 		ID:        ids.DataID(tx.ld.ID),
 	}
+	if tx.input.Weights != nil && len(*tx.input.Weights) > 0 {
+		tx.di.Weights = *tx.input.Weights
+	}
 	if err = tx.di.SyntacticVerify(); err != nil {
 		return errp.ErrorIf(err)
 	}
@@ -234,6 +240,14 @@ func (tx *TxCreateData) Apply(ctx ChainContext, cs ChainState) error {
 			return errp.Errorf("invalid JSON encoding data")
 		}
 
+	case ld.EncryptedModelID:
+		if tx.input.To != nil {
+			return errp.Errorf("invalid to, should be nil")
+		}
+		if err = ld.ValidEncryptedPayload(tx.input.Data); err != nil {
+			return errp.ErrorIf(err)
+		}
+
 	default:
 		mi, err := cs.LoadModel(tx.di.ModelID)
 		if err != nil {
@@ -259,6 +273,7 @@ func (tx *TxCreateData) Apply(ctx ChainContext, cs ChainState) error {
 		if err = mi.Model().Valid(tx.di.Payload); err != nil {
 			return errp.ErrorIf(err)
 		}
+		tx.di.ModelVersion = mi.Version
 
 		if ctx.ChainConfig().IsNameService(tx.di.ModelID) {
 			tx.ns = &service.Name{}
@@ -276,6 +291,11 @@ func (tx *TxCreateData) Apply(ctx ChainContext, cs ChainState) error {
 		}
 	}
 
+	if tx.input.ExpiresAt != nil {
+		if err = tx.setDataExpiry(cs, tx.di, *tx.input.ExpiresAt); err != nil {
+			return errp.ErrorIf(err)
+		}
+	}
 	if err = cs.SaveData(tx.di); err != nil {
 		return errp.ErrorIf(err)
 	}
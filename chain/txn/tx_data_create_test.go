@@ -1219,3 +1219,239 @@ func TestTxCreateDataGenesis(t *testing.T) {
 
 	assert.NoError(cs.VerifyState())
 }
+
+func TestTxCreateJSONSchemaModelData(t *testing.T) {
+	assert := assert.New(t)
+
+	ctx := NewMockChainContext()
+	cs := ctx.MockChainState()
+
+	sender := signer.Signer1.Key().Address()
+	senderAcc := cs.MustAccount(sender)
+	senderAcc.Add(ids.NativeToken, new(big.Int).SetUint64(unit.LDC))
+
+	mi := &ld.ModelInfo{
+		Name:   "Profile",
+		Format: ld.ModelFormatJSONSchema,
+		Schema: `{
+			"type": "object",
+			"properties": {
+				"name": {"type": "string", "minLength": 1},
+				"tags": {"type": "array", "items": {"type": "string"}}
+			},
+			"required": ["name"],
+			"additionalProperties": false
+		}`,
+		ID: ids.ModelID{1, 2, 3, 4, 5},
+	}
+	assert.NoError(mi.SyntacticVerify())
+	assert.NoError(cs.SaveModel(mi))
+
+	input := &ld.TxUpdater{
+		ModelID:   &mi.ID,
+		Version:   1,
+		Threshold: ld.Uint16Ptr(1),
+		Keepers:   &signer.Keys{signer.Signer1.Key()},
+		Data:      []byte(`{"name":"","tags":[]}`),
+	}
+	assert.NoError(input.SyntacticVerify())
+	ltx := &ld.Transaction{Tx: ld.TxData{
+		Type:      ld.TypeCreateData,
+		ChainID:   ctx.ChainConfig().ChainID,
+		Nonce:     0,
+		GasTip:    100,
+		GasFeeCap: ctx.Price,
+		From:      sender,
+		Data:      input.Bytes(),
+	}}
+	assert.NoError(ltx.SignWith(signer.Signer1))
+	assert.NoError(ltx.SyntacticVerify())
+	itx, err := NewTx(ltx)
+	require.NoError(t, err)
+
+	cs.CommitAccounts()
+	assert.ErrorContains(itx.Apply(ctx, cs),
+		`TxCreateData.Apply: ld.JSONModel("Profile").Valid: /name: expected length >= 1, got 0`)
+	cs.CheckoutAccounts()
+
+	input = &ld.TxUpdater{
+		ModelID:   &mi.ID,
+		Version:   1,
+		Threshold: ld.Uint16Ptr(1),
+		Keepers:   &signer.Keys{signer.Signer1.Key()},
+		Data:      []byte(`{"name":"tester","tags":[]}`),
+	}
+	assert.NoError(input.SyntacticVerify())
+	ltx = &ld.Transaction{Tx: ld.TxData{
+		Type:      ld.TypeCreateData,
+		ChainID:   ctx.ChainConfig().ChainID,
+		Nonce:     0,
+		GasTip:    100,
+		GasFeeCap: ctx.Price,
+		From:      sender,
+		Data:      input.Bytes(),
+	}}
+	assert.NoError(ltx.SignWith(signer.Signer1))
+	assert.NoError(ltx.SyntacticVerify())
+	itx, err = NewTx(ltx)
+	require.NoError(t, err)
+	assert.NoError(itx.Apply(ctx, cs))
+
+	di, err := cs.LoadData(itx.(*TxCreateData).di.ID)
+	require.NoError(t, err)
+	assert.Equal(mi.ID, di.ModelID)
+	assert.Equal(uint64(1), di.Version)
+	assert.Equal([]byte(`{"name":"tester","tags":[]}`), []byte(di.Payload))
+
+	assert.NoError(cs.VerifyState())
+}
+
+func TestTxCreateDataWithExpiry(t *testing.T) {
+	assert := assert.New(t)
+
+	ctx := NewMockChainContext()
+	cs := ctx.MockChainState()
+
+	sender := signer.Signer1.Key().Address()
+	senderAcc := cs.MustAccount(sender)
+	senderAcc.Add(ids.NativeToken, new(big.Int).SetUint64(unit.LDC))
+
+	data := encoding.MustMarshalCBOR(map[string]string{"name": "test"})
+	input := &ld.TxUpdater{
+		ModelID:   &ld.CBORModelID,
+		Version:   1,
+		Threshold: ld.Uint16Ptr(1),
+		Keepers:   &signer.Keys{signer.Signer1.Key()},
+		Data:      data,
+		ExpiresAt: ld.Uint64Ptr(cs.Timestamp()),
+	}
+	assert.NoError(input.SyntacticVerify())
+	ltx := &ld.Transaction{Tx: ld.TxData{
+		Type:      ld.TypeCreateData,
+		ChainID:   ctx.ChainConfig().ChainID,
+		Nonce:     0,
+		GasTip:    100,
+		GasFeeCap: ctx.Price,
+		From:      sender,
+		Data:      input.Bytes(),
+	}}
+	assert.NoError(ltx.SignWith(signer.Signer1))
+	assert.NoError(ltx.SyntacticVerify())
+	itx, err := NewTx(ltx)
+	require.NoError(t, err)
+
+	cs.CommitAccounts()
+	assert.ErrorContains(itx.Apply(ctx, cs),
+		"invalid expiresAt, expected > 1000, got 1000")
+	cs.CheckoutAccounts()
+
+	input.ExpiresAt = ld.Uint64Ptr(cs.Timestamp() + 100)
+	assert.NoError(input.SyntacticVerify())
+	ltx = &ld.Transaction{Tx: ld.TxData{
+		Type:      ld.TypeCreateData,
+		ChainID:   ctx.ChainConfig().ChainID,
+		Nonce:     0,
+		GasTip:    100,
+		GasFeeCap: ctx.Price,
+		From:      sender,
+		Data:      input.Bytes(),
+	}}
+	assert.NoError(ltx.SignWith(signer.Signer1))
+	assert.NoError(ltx.SyntacticVerify())
+	itx, err = NewTx(ltx)
+	require.NoError(t, err)
+	assert.NoError(itx.Apply(ctx, cs))
+
+	id := itx.(*TxCreateData).di.ID
+	assert.Equal(uint64(1100), cs.EX[id])
+
+	di, err := cs.LoadData(id)
+	require.NoError(t, err)
+	assert.Equal(uint64(1), di.Version)
+	assert.Equal(uint64(1100), di.ExpiresAt)
+	assert.False(di.IsExpired(cs.Timestamp()))
+	assert.NoError(cs.VerifyState())
+
+	// expired data is treated as deleted
+	ctx.timestamp = 1100
+	di, err = cs.LoadData(id)
+	require.NoError(t, err)
+	assert.Equal(uint64(0), di.Version)
+	assert.Nil(di.Payload)
+	assert.True(di.IsExpired(cs.Timestamp()))
+}
+
+func TestTxCreateEncryptedData(t *testing.T) {
+	assert := assert.New(t)
+
+	ctx := NewMockChainContext()
+	cs := ctx.MockChainState()
+
+	sender := signer.Signer1.Key().Address()
+	senderAcc := cs.MustAccount(sender)
+	senderAcc.Add(ids.NativeToken, new(big.Int).SetUint64(unit.LDC))
+
+	readerKey, err := ld.GenerateX25519Key()
+	require.NoError(t, err)
+	reader, err := ld.X25519PublicKey(readerKey)
+	require.NoError(t, err)
+	ep, err := ld.SealPayload([]byte(`{"secret":42}`), reader)
+	require.NoError(t, err)
+
+	input := &ld.TxUpdater{
+		ModelID:   &ld.EncryptedModelID,
+		Version:   1,
+		Threshold: ld.Uint16Ptr(1),
+		Keepers:   &signer.Keys{signer.Signer1.Key()},
+		Data:      []byte(`{"secret":42}`),
+	}
+	assert.NoError(input.SyntacticVerify())
+	ltx := &ld.Transaction{Tx: ld.TxData{
+		Type:      ld.TypeCreateData,
+		ChainID:   ctx.ChainConfig().ChainID,
+		Nonce:     0,
+		GasTip:    100,
+		GasFeeCap: ctx.Price,
+		From:      sender,
+		Data:      input.Bytes(),
+	}}
+	assert.NoError(ltx.SignWith(signer.Signer1))
+	assert.NoError(ltx.SyntacticVerify())
+	itx, err := NewTx(ltx)
+	require.NoError(t, err)
+
+	cs.CommitAccounts()
+	assert.ErrorContains(itx.Apply(ctx, cs), "ld.EncryptedPayload.Unmarshal")
+	cs.CheckoutAccounts()
+
+	input.Data = ep.Bytes()
+	assert.NoError(input.SyntacticVerify())
+	ltx = &ld.Transaction{Tx: ld.TxData{
+		Type:      ld.TypeCreateData,
+		ChainID:   ctx.ChainConfig().ChainID,
+		Nonce:     0,
+		GasTip:    100,
+		GasFeeCap: ctx.Price,
+		From:      sender,
+		Data:      input.Bytes(),
+	}}
+	assert.NoError(ltx.SignWith(signer.Signer1))
+	assert.NoError(ltx.SyntacticVerify())
+	itx, err = NewTx(ltx)
+	require.NoError(t, err)
+	assert.NoError(itx.Apply(ctx, cs))
+
+	di, err := cs.LoadData(itx.(*TxCreateData).di.ID)
+	require.NoError(t, err)
+	assert.Equal(ld.EncryptedModelID, di.ModelID)
+	assert.Equal(uint64(1), di.Version)
+	assert.Equal(ep.Bytes(), []byte(di.Payload))
+
+	ep2 := &ld.EncryptedPayload{}
+	assert.NoError(ep2.Unmarshal(di.Payload))
+	data, err := ep2.Open(readerKey)
+	require.NoError(t, err)
+	assert.Equal([]byte(`{"secret":42}`), data)
+
+	assert.NoError(cs.VerifyState())
+}
@@ -97,7 +97,7 @@ func (tx *TxDeleteData) Apply(ctx ChainContext, cs ChainState) error {
 		return errp.Errorf("invalid version, expected %d, got %d",
 			tx.di.Version, tx.input.Version)
 
-	case !tx.di.VerifyPlus(tx.ld.TxHash(), tx.ld.Signatures):
+	case !tx.di.VerifyPlus(tx.ld.SigHash(), tx.ld.Signatures):
 		return errp.Errorf("invalid signatures for data keepers")
 
 	case !tx.ld.IsApproved(tx.di.Approver, tx.di.ApproveList, false):
@@ -0,0 +1,119 @@
+// (c) 2022-2022, LDC Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package txn
+
+import (
+	"encoding/json"
+	"math/big"
+
+	"github.com/ldclabs/ldvm/ids"
+	"github.com/ldclabs/ldvm/ld"
+	"github.com/ldclabs/ldvm/util/erring"
+)
+
+type TxSellData struct {
+	TxBase
+	input *ld.TxDataOffer
+	di    *ld.DataInfo
+}
+
+func (tx *TxSellData) MarshalJSON() ([]byte, error) {
+	if tx == nil || tx.ld == nil {
+		return []byte("null"), nil
+	}
+
+	v := tx.ld.Copy()
+	errp := erring.ErrPrefix("txn.TxSellData.MarshalJSON: ")
+	if tx.input == nil {
+		return nil, errp.Errorf("nil tx.input")
+	}
+	d, err := json.Marshal(tx.input)
+	if err != nil {
+		return nil, errp.ErrorIf(err)
+	}
+	v.Tx.Data = d
+	return errp.ErrorMap(json.Marshal(v))
+}
+
+// TxSellData{From: seller, Data: TxDataOffer{ID, Version, Amount, Expire[, Buyer, Token]}}
+// the sender will receive the payment.
+func (tx *TxSellData) SyntacticVerify() error {
+	var err error
+	errp := erring.ErrPrefix("txn.TxSellData.SyntacticVerify: ")
+
+	if err = tx.TxBase.SyntacticVerify(); err != nil {
+		return errp.ErrorIf(err)
+	}
+
+	switch {
+	case tx.ld.Tx.To != nil:
+		return errp.Errorf("invalid to, should be nil")
+
+	case tx.ld.Tx.Token != nil:
+		return errp.Errorf("invalid token, should be nil")
+
+	case len(tx.ld.Tx.Data) == 0:
+		return errp.Errorf("invalid data")
+	}
+
+	tx.input = &ld.TxDataOffer{}
+	if err = tx.input.Unmarshal(tx.ld.Tx.Data); err != nil {
+		return errp.ErrorIf(err)
+	}
+	if err = tx.input.SyntacticVerify(); err != nil {
+		return errp.ErrorIf(err)
+	}
+
+	switch {
+	case tx.input.Buyer != nil && *tx.input.Buyer == tx.ld.Tx.From:
+		return errp.Errorf("invalid buyer, should not be seller")
+
+	case tx.input.Expire < tx.ld.Timestamp:
+		return errp.Errorf("offer expired")
+	}
+	return nil
+}
+
+func (tx *TxSellData) Apply(ctx ChainContext, cs ChainState) error {
+	var err error
+	errp := erring.ErrPrefix("txn.TxSellData.Apply: ")
+
+	if err = tx.TxBase.verify(ctx, cs); err != nil {
+		return errp.ErrorIf(err)
+	}
+
+	tx.di, err = cs.LoadData(tx.input.ID)
+	switch {
+	case err != nil:
+		return errp.ErrorIf(err)
+
+	case tx.di.Version != tx.input.Version:
+		return errp.Errorf("invalid version, expected %d, got %d",
+			tx.di.Version, tx.input.Version)
+
+	case !tx.di.VerifyPlus(tx.ld.SigHash(), tx.ld.Signatures):
+		return errp.Errorf("invalid signatures for data keepers")
+
+	case !tx.ld.IsApproved(tx.di.Approver, tx.di.ApproveList, false):
+		return errp.Errorf("invalid signature for data approver")
+	}
+
+	tx.di.Version++
+	tx.di.Offer = &ld.DataOffer{
+		Seller:  tx.ld.Tx.From,
+		Buyer:   tx.input.Buyer,
+		Token:   ids.NativeToken,
+		Amount:  new(big.Int).Set(tx.input.Amount),
+		Expire:  tx.input.Expire,
+		Version: tx.di.Version,
+	}
+	if tx.input.Token != nil {
+		tx.di.Offer.Token = *tx.input.Token
+	}
+
+	if err = cs.SaveData(tx.di); err != nil {
+		return errp.ErrorIf(err)
+	}
+	return errp.ErrorIf(tx.TxBase.accept(ctx, cs))
+}
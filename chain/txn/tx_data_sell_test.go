@@ -0,0 +1,210 @@
+// (c) 2022-2022, LDC Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package txn
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ldclabs/ldvm/ids"
+	"github.com/ldclabs/ldvm/ld"
+	"github.com/ldclabs/ldvm/signer"
+	"github.com/ldclabs/ldvm/unit"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTxSellData(t *testing.T) {
+	assert := assert.New(t)
+
+	// SyntacticVerify
+	tx := &TxSellData{}
+	assert.ErrorContains(tx.SyntacticVerify(), "nil pointer")
+	_, err := tx.MarshalJSON()
+	require.NoError(t, err)
+
+	ctx := NewMockChainContext()
+	cs := ctx.MockChainState()
+	token := ld.MustNewToken("$LDC")
+	seller := signer.Signer1.Key().Address()
+	buyer := signer.Signer2.Key().Address()
+	did := ids.DataID{1, 2, 3, 4}
+
+	ltx := &ld.Transaction{Tx: ld.TxData{
+		Type:      ld.TypeSellData,
+		ChainID:   ctx.ChainConfig().ChainID,
+		Nonce:     0,
+		GasTip:    100,
+		GasFeeCap: ctx.Price,
+		From:      seller,
+		To:        &buyer,
+	}}
+	assert.NoError(ltx.SignWith(signer.Signer1))
+	assert.NoError(ltx.SyntacticVerify())
+	_, err = NewTx(ltx)
+	assert.ErrorContains(err, "invalid to, should be nil")
+
+	ltx = &ld.Transaction{Tx: ld.TxData{
+		Type:      ld.TypeSellData,
+		ChainID:   ctx.ChainConfig().ChainID,
+		Nonce:     0,
+		GasTip:    100,
+		GasFeeCap: ctx.Price,
+		From:      seller,
+		Token:     token.Ptr(),
+	}}
+	assert.NoError(ltx.SignWith(signer.Signer1))
+	assert.NoError(ltx.SyntacticVerify())
+	_, err = NewTx(ltx)
+	assert.ErrorContains(err, "invalid token, should be nil")
+
+	ltx = &ld.Transaction{Tx: ld.TxData{
+		Type:      ld.TypeSellData,
+		ChainID:   ctx.ChainConfig().ChainID,
+		Nonce:     0,
+		GasTip:    100,
+		GasFeeCap: ctx.Price,
+		From:      seller,
+	}}
+	assert.NoError(ltx.SignWith(signer.Signer1))
+	assert.NoError(ltx.SyntacticVerify())
+	_, err = NewTx(ltx)
+	assert.ErrorContains(err, "invalid data")
+
+	input := &ld.TxDataOffer{ID: did, Version: 1, Amount: big.NewInt(1000)}
+	ltx = &ld.Transaction{Tx: ld.TxData{
+		Type:      ld.TypeSellData,
+		ChainID:   ctx.ChainConfig().ChainID,
+		Nonce:     0,
+		GasTip:    100,
+		GasFeeCap: ctx.Price,
+		From:      seller,
+		Data:      input.Bytes(),
+	}}
+	assert.NoError(ltx.SignWith(signer.Signer1))
+	assert.NoError(ltx.SyntacticVerify())
+	_, err = NewTx(ltx)
+	assert.ErrorContains(err, "invalid expire")
+
+	input = &ld.TxDataOffer{ID: did, Version: 1, Amount: big.NewInt(1000),
+		Buyer: seller.Ptr(), Expire: cs.Timestamp()}
+	ltx = &ld.Transaction{Tx: ld.TxData{
+		Type:      ld.TypeSellData,
+		ChainID:   ctx.ChainConfig().ChainID,
+		Nonce:     0,
+		GasTip:    100,
+		GasFeeCap: ctx.Price,
+		From:      seller,
+		Data:      input.Bytes(),
+	}}
+	assert.NoError(ltx.SignWith(signer.Signer1))
+	assert.NoError(ltx.SyntacticVerify())
+	_, err = NewTx(ltx)
+	assert.ErrorContains(err, "invalid buyer, should not be seller")
+
+	input = &ld.TxDataOffer{ID: did, Version: 1, Amount: big.NewInt(1000),
+		Buyer: buyer.Ptr(), Expire: cs.Timestamp() - 1}
+	ltx = &ld.Transaction{Tx: ld.TxData{
+		Type:      ld.TypeSellData,
+		ChainID:   ctx.ChainConfig().ChainID,
+		Nonce:     0,
+		GasTip:    100,
+		GasFeeCap: ctx.Price,
+		From:      seller,
+		Data:      input.Bytes(),
+	}}
+	assert.NoError(ltx.SignWith(signer.Signer1))
+	assert.NoError(ltx.SyntacticVerify())
+	ltx.Timestamp = cs.Timestamp()
+	_, err = NewTx(ltx)
+	assert.ErrorContains(err, "offer expired")
+
+	input = &ld.TxDataOffer{ID: did, Version: 2, Amount: new(big.Int).SetUint64(unit.MilliLDC),
+		Buyer: buyer.Ptr(), Token: token.Ptr(), Expire: cs.Timestamp() + 100}
+	ltx = &ld.Transaction{Tx: ld.TxData{
+		Type:      ld.TypeSellData,
+		ChainID:   ctx.ChainConfig().ChainID,
+		Nonce:     0,
+		GasTip:    100,
+		GasFeeCap: ctx.Price,
+		From:      seller,
+		Data:      input.Bytes(),
+	}}
+	assert.NoError(ltx.SignWith(signer.Signer1))
+	assert.NoError(ltx.SyntacticVerify())
+	ltx.Timestamp = cs.Timestamp()
+	itx, err := NewTx(ltx)
+	require.NoError(t, err)
+
+	cs.CommitAccounts()
+	assert.ErrorContains(itx.Apply(ctx, cs),
+		"insufficient NativeLDC balance, expected 1311200, got 0")
+	cs.CheckoutAccounts()
+
+	sellerAcc := cs.MustAccount(seller)
+	sellerAcc.Add(ids.NativeToken, new(big.Int).SetUint64(unit.LDC))
+	cs.CommitAccounts()
+	assert.ErrorContains(itx.Apply(ctx, cs),
+		"AQIDBAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAACs148t not found")
+	cs.CheckoutAccounts()
+
+	di := &ld.DataInfo{
+		ModelID:   ld.RawModelID,
+		Version:   1,
+		Threshold: 1,
+		Keepers:   signer.Keys{signer.Signer2.Key()},
+		Payload:   []byte(`42`),
+		ID:        did,
+	}
+	assert.NoError(di.SyntacticVerify())
+	assert.NoError(cs.SaveData(di))
+	cs.CommitAccounts()
+	assert.ErrorContains(itx.Apply(ctx, cs), "invalid version, expected 1, got 2")
+	cs.CheckoutAccounts()
+
+	di.Version = 2
+	assert.NoError(cs.SaveData(di))
+	cs.CommitAccounts()
+	assert.ErrorContains(itx.Apply(ctx, cs), "invalid signatures for data keepers")
+	cs.CheckoutAccounts()
+
+	di.Keepers = signer.Keys{signer.Signer1.Key()}
+	di.Approver = signer.Signer2.Key()
+	assert.NoError(cs.SaveData(di))
+	cs.CommitAccounts()
+	assert.ErrorContains(itx.Apply(ctx, cs), "invalid signature for data approver")
+	cs.CheckoutAccounts()
+
+	di.Approver = nil
+	assert.NoError(cs.SaveData(di))
+	assert.NoError(itx.Apply(ctx, cs))
+
+	sellerGas := ltx.Gas()
+	assert.Equal(sellerGas*ctx.Price,
+		itx.(*TxSellData).ldc.Balance().Uint64())
+	assert.Equal(sellerGas*100,
+		itx.(*TxSellData).miner.Balance().Uint64())
+	assert.Equal(unit.LDC-sellerGas*(ctx.Price+100),
+		sellerAcc.BalanceOfAll(ids.NativeToken).Uint64())
+	assert.Equal(uint64(1), sellerAcc.Nonce())
+
+	di2, err := cs.LoadData(di.ID)
+	require.NoError(t, err)
+	assert.Equal(uint64(3), di2.Version)
+	assert.Equal(signer.Keys{signer.Signer1.Key()}, di2.Keepers)
+	require.NotNil(t, di2.Offer)
+	assert.Equal(seller, di2.Offer.Seller)
+	assert.Equal(buyer, *di2.Offer.Buyer)
+	assert.Equal(token, di2.Offer.Token)
+	assert.Equal(unit.MilliLDC, di2.Offer.Amount.Uint64())
+	assert.Equal(cs.Timestamp()+100, di2.Offer.Expire)
+	assert.Equal(uint64(3), di2.Offer.Version)
+
+	jsondata, err := itx.MarshalJSON()
+	require.NoError(t, err)
+	assert.Contains(string(jsondata), `"type":"TypeSellData"`)
+	assert.Contains(string(jsondata), `"data":{"id":"AQIDBAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAACs148t","version":2,"buyer":"0x44171C37Ff5D7B7bb8Dcad5C81f16284A229E641","token":"$LDC","amount":1000000,"expire":1100}`)
+
+	assert.NoError(cs.VerifyState())
+}
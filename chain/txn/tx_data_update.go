@@ -139,7 +139,7 @@ func (tx *TxUpdateData) Apply(ctx ChainContext, cs ChainState) error {
 	case tx.di.SigClaims != nil && tx.input.SigClaims == nil:
 		return errp.Errorf("invalid sigClaims, should not be nil")
 
-	case !tx.di.Verify(tx.ld.TxHash(), tx.ld.Signatures):
+	case !tx.di.Verify(tx.ld.SigHash(), tx.ld.Signatures):
 		return errp.Errorf("invalid signatures for data keepers")
 
 	case !tx.ld.IsApproved(tx.di.Approver, tx.di.ApproveList, false):
@@ -148,7 +148,7 @@ func (tx *TxUpdateData) Apply(ctx ChainContext, cs ChainState) error {
 
 	tx.prevDI = tx.di.Clone()
 	switch tx.di.ModelID {
-	case ld.RawModelID, ld.CBORModelID, ld.JSONModelID:
+	case ld.RawModelID, ld.CBORModelID, ld.JSONModelID, ld.EncryptedModelID:
 		if tx.input.To != nil {
 			return errp.Errorf("invalid to, should be nil")
 		}
@@ -165,6 +165,7 @@ func (tx *TxUpdateData) Apply(ctx ChainContext, cs ChainState) error {
 		if tx.di.Payload, err = mi.Model().ApplyPatch(tx.di.Payload, tx.input.Data); err != nil {
 			return errp.Errorf("apply patch error, %v", err)
 		}
+		tx.di.ModelVersion = mi.Version
 
 		switch {
 		case mi.Threshold == 0:
@@ -209,6 +210,11 @@ func (tx *TxUpdateData) Apply(ctx ChainContext, cs ChainState) error {
 		}
 	}
 
+	if tx.input.ExpiresAt != nil {
+		if err = tx.setDataExpiry(cs, tx.di, *tx.input.ExpiresAt); err != nil {
+			return errp.ErrorIf(err)
+		}
+	}
 	if err = cs.SavePrevData(tx.prevDI); err != nil {
 		return errp.ErrorIf(err)
 	}
@@ -95,7 +95,7 @@ func (tx *TxUpdateDataInfo) Apply(ctx ChainContext, cs ChainState) error {
 		return errp.Errorf("invalid version, expected %d, got %d",
 			tx.di.Version, tx.input.Version)
 
-	case !tx.di.VerifyPlus(tx.ld.TxHash(), tx.ld.Signatures):
+	case !tx.di.VerifyPlus(tx.ld.SigHash(), tx.ld.Signatures):
 		return errp.Errorf("invalid signatures for data keepers")
 
 	case !tx.ld.IsApproved(tx.di.Approver, tx.di.ApproveList, false):
@@ -119,6 +119,10 @@ func (tx *TxUpdateDataInfo) Apply(ctx ChainContext, cs ChainState) error {
 	if tx.input.Threshold != nil {
 		tx.di.Threshold = *tx.input.Threshold
 		tx.di.Keepers = *tx.input.Keepers
+		tx.di.Weights = nil
+		if tx.input.Weights != nil && len(*tx.input.Weights) > 0 {
+			tx.di.Weights = *tx.input.Weights
+		}
 	}
 
 	if tx.input.SigClaims != nil {
@@ -141,6 +141,7 @@ func (tx *TxUpdateDataInfoByAuth) Apply(ctx ChainContext, cs ChainState) error {
 	tx.di.Version++
 	tx.di.Threshold = tx.from.Threshold()
 	tx.di.Keepers = tx.from.Keepers()
+	tx.di.Weights = tx.from.Weights()
 	tx.di.Approver = nil
 	tx.di.ApproveList = nil
 
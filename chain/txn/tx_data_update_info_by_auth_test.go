@@ -381,7 +381,7 @@ func TestTxUpdateDataInfoByAuth(t *testing.T) {
 		"no keepers on sender account")
 	cs.CheckoutAccounts()
 
-	assert.NoError(buyerAcc.UpdateKeepers(ld.Uint16Ptr(1), &signer.Keys{signer.Signer1.Key()}, nil, nil))
+	assert.NoError(buyerAcc.UpdateKeepers(ld.Uint16Ptr(1), &signer.Keys{signer.Signer1.Key()}, nil, nil, nil))
 
 	cs.CommitAccounts()
 	assert.ErrorContains(itx.Apply(ctx, cs),
@@ -7,6 +7,7 @@ import (
 	"math/big"
 	"testing"
 
+	"github.com/fxamacker/cbor/v2"
 	"github.com/ldclabs/ldvm/ids"
 	"github.com/ldclabs/ldvm/ld"
 	"github.com/ldclabs/ldvm/ld/service"
@@ -777,3 +778,329 @@ func TestTxUpdateNameServiceData(t *testing.T) {
 
 	assert.NoError(cs.VerifyState())
 }
+
+func TestTxUpdateJSONSchemaModelData(t *testing.T) {
+	assert := assert.New(t)
+
+	ctx := NewMockChainContext()
+	cs := ctx.MockChainState()
+
+	owner := signer.Signer1.Key().Address()
+	ownerAcc := cs.MustAccount(owner)
+	assert.NoError(ownerAcc.Add(ids.NativeToken, new(big.Int).SetUint64(unit.LDC)))
+
+	mi := &ld.ModelInfo{
+		Name:   "Profile",
+		Format: ld.ModelFormatJSONSchema,
+		Schema: `{
+			"type": "object",
+			"properties": {
+				"name": {"type": "string", "minLength": 1},
+				"tags": {"type": "array", "items": {"type": "string"}}
+			},
+			"required": ["name"],
+			"additionalProperties": false
+		}`,
+		ID: ids.ModelID{1, 2, 3, 4, 5},
+	}
+	assert.NoError(mi.SyntacticVerify())
+	assert.NoError(cs.SaveModel(mi))
+
+	di := &ld.DataInfo{
+		ModelID:   mi.ID,
+		Version:   2,
+		Threshold: 1,
+		Keepers:   signer.Keys{signer.Signer1.Key()},
+		Payload:   []byte(`{"name":"test","tags":[]}`),
+		ID:        ids.DataID{1, 2, 3, 4},
+	}
+	assert.NoError(di.SyntacticVerify())
+	assert.NoError(cs.SaveData(di))
+
+	input := &ld.TxUpdater{ID: &di.ID, Version: 2,
+		Data: []byte(`[{"op": "add", "path": "/tags/-", "value": 1}]`),
+	}
+	ltx := &ld.Transaction{Tx: ld.TxData{
+		Type:      ld.TypeUpdateData,
+		ChainID:   ctx.ChainConfig().ChainID,
+		Nonce:     0,
+		GasTip:    100,
+		GasFeeCap: ctx.Price,
+		From:      owner,
+		Data:      input.Bytes(),
+	}}
+	assert.NoError(ltx.SignWith(signer.Signer1))
+	assert.NoError(ltx.SyntacticVerify())
+	itx, err := NewTx(ltx)
+	require.NoError(t, err)
+
+	cs.CommitAccounts()
+	assert.ErrorContains(itx.Apply(ctx, cs),
+		`ld.JSONModel("Profile").ApplyPatch: /tags/0: expected string, got number`)
+	cs.CheckoutAccounts()
+
+	input = &ld.TxUpdater{ID: &di.ID, Version: 2,
+		Data: []byte(`[{"op": "add", "path": "/tags/-", "value": "a"}]`),
+	}
+	ltx = &ld.Transaction{Tx: ld.TxData{
+		Type:      ld.TypeUpdateData,
+		ChainID:   ctx.ChainConfig().ChainID,
+		Nonce:     0,
+		GasTip:    100,
+		GasFeeCap: ctx.Price,
+		From:      owner,
+		Data:      input.Bytes(),
+	}}
+	assert.NoError(ltx.SignWith(signer.Signer1))
+	assert.NoError(ltx.SyntacticVerify())
+	itx, err = NewTx(ltx)
+	require.NoError(t, err)
+	assert.NoError(itx.Apply(ctx, cs))
+
+	di2, err := cs.LoadData(di.ID)
+	require.NoError(t, err)
+	assert.Equal(uint64(3), di2.Version)
+	assert.Equal([]byte(`{"name":"test","tags":["a"]}`), []byte(di2.Payload))
+
+	assert.NoError(cs.VerifyState())
+}
+
+func TestTxUpdateDataExpiry(t *testing.T) {
+	assert := assert.New(t)
+
+	ctx := NewMockChainContext()
+	cs := ctx.MockChainState()
+
+	owner := signer.Signer1.Key().Address()
+	ownerAcc := cs.MustAccount(owner)
+	assert.NoError(ownerAcc.Add(ids.NativeToken, new(big.Int).SetUint64(unit.LDC)))
+
+	di := &ld.DataInfo{
+		ModelID:   ld.RawModelID,
+		Version:   2,
+		Threshold: 1,
+		Keepers:   signer.Keys{signer.Signer1.Key()},
+		Payload:   []byte(`42`),
+		ID:        ids.DataID{1, 2, 3, 4},
+	}
+	assert.NoError(di.SyntacticVerify())
+	cs.SaveData(di)
+
+	input := &ld.TxUpdater{ID: &di.ID, Version: 2,
+		Data:      []byte(`421`),
+		ExpiresAt: ld.Uint64Ptr(cs.Timestamp() + 100),
+	}
+	ltx := &ld.Transaction{Tx: ld.TxData{
+		Type:      ld.TypeUpdateData,
+		ChainID:   ctx.ChainConfig().ChainID,
+		Nonce:     0,
+		GasTip:    100,
+		GasFeeCap: ctx.Price,
+		From:      owner,
+		Data:      input.Bytes(),
+	}}
+	assert.NoError(ltx.SignWith(signer.Signer1))
+	assert.NoError(ltx.SyntacticVerify())
+	itx, err := NewTx(ltx)
+	require.NoError(t, err)
+	assert.NoError(itx.Apply(ctx, cs))
+
+	assert.Equal(uint64(1100), cs.EX[di.ID])
+	di2, err := cs.LoadData(di.ID)
+	require.NoError(t, err)
+	assert.Equal(uint64(3), di2.Version)
+	assert.Equal(uint64(1100), di2.ExpiresAt)
+
+	// clear the expiry
+	input = &ld.TxUpdater{ID: &di.ID, Version: 3,
+		Data:      []byte(`422`),
+		ExpiresAt: ld.Uint64Ptr(0),
+	}
+	ltx = &ld.Transaction{Tx: ld.TxData{
+		Type:      ld.TypeUpdateData,
+		ChainID:   ctx.ChainConfig().ChainID,
+		Nonce:     1,
+		GasTip:    100,
+		GasFeeCap: ctx.Price,
+		From:      owner,
+		Data:      input.Bytes(),
+	}}
+	assert.NoError(ltx.SignWith(signer.Signer1))
+	assert.NoError(ltx.SyntacticVerify())
+	itx, err = NewTx(ltx)
+	require.NoError(t, err)
+	assert.NoError(itx.Apply(ctx, cs))
+
+	assert.Equal(uint64(0), cs.EX[di.ID])
+	ctx.timestamp = 1100
+	di2, err = cs.LoadData(di.ID)
+	require.NoError(t, err)
+	assert.Equal(uint64(4), di2.Version)
+	assert.Equal(uint64(0), di2.ExpiresAt)
+	assert.Equal([]byte(`422`), []byte(di2.Payload))
+
+	assert.NoError(cs.VerifyState())
+}
+
+func TestTxUpdateDataWithSessionKey(t *testing.T) {
+	assert := assert.New(t)
+
+	ctx := NewMockChainContext()
+	cs := ctx.MockChainState()
+
+	owner := signer.Signer1.Key().Address()
+	ownerAcc := cs.MustAccount(owner)
+	assert.NoError(ownerAcc.Add(ids.NativeToken, new(big.Int).SetUint64(unit.LDC*2)))
+	assert.NoError(ownerAcc.UpdateSessionKeys(ld.SessionKeys{{
+		Key:     signer.Signer2.Key(),
+		TxTypes: ld.TxTypes{ld.TypeUpdateData},
+		MaxAmounts: map[cbor.ByteString]*big.Int{
+			ids.NativeToken.AsKey(): new(big.Int).SetUint64(unit.MilliLDC),
+		},
+		Expire: cs.Timestamp() + 3600,
+	}}))
+
+	di := &ld.DataInfo{
+		ModelID:   ld.RawModelID,
+		Version:   2,
+		Threshold: 1,
+		Keepers:   signer.Keys{signer.Signer1.Key()},
+		Payload:   []byte(`42`),
+		ID:        ids.DataID{1, 2, 3, 4},
+	}
+	assert.NoError(di.SyntacticVerify())
+	cs.SaveData(di)
+
+	input := &ld.TxUpdater{ID: &di.ID, Version: 2, Data: []byte(`421`)}
+	ltx := &ld.Transaction{Tx: ld.TxData{
+		Type:      ld.TypeUpdateData,
+		ChainID:   ctx.ChainConfig().ChainID,
+		Nonce:     0,
+		GasTip:    100,
+		GasFeeCap: ctx.Price,
+		From:      owner,
+		Data:      input.Bytes(),
+	}}
+	assert.NoError(ltx.SignWith(signer.Signer2))
+	assert.NoError(ltx.SyntacticVerify())
+	itx, err := NewTx(ltx)
+	require.NoError(t, err)
+
+	// the session key signs for the sender, but it is not a data keeper
+	cs.CommitAccounts()
+	assert.ErrorContains(itx.Apply(ctx, cs),
+		"txn.TxUpdateData.Apply: invalid signatures for data keepers")
+	cs.CheckoutAccounts()
+
+	di.Keepers = signer.Keys{signer.Signer1.Key(), signer.Signer2.Key()}
+	assert.NoError(di.SyntacticVerify())
+	cs.SaveData(di)
+
+	itx, err = NewTx(ltx)
+	require.NoError(t, err)
+	assert.NoError(itx.Apply(ctx, cs))
+
+	ownerGas := ltx.Gas()
+	assert.Equal(unit.LDC-ownerGas*(ctx.Price+100), ownerAcc.Balance().Uint64())
+	assert.Equal(uint64(1), ownerAcc.Nonce())
+	assert.Equal(unit.MilliLDC-ownerGas*(ctx.Price+100),
+		ownerAcc.LD().SessionKeys[0].MaxAmounts[ids.NativeToken.AsKey()].Uint64())
+
+	di2, err := cs.LoadData(di.ID)
+	require.NoError(t, err)
+	assert.Equal(uint64(3), di2.Version)
+	assert.Equal([]byte(`421`), []byte(di2.Payload))
+
+	assert.NoError(cs.VerifyState())
+}
+
+func TestTxUpdateEncryptedData(t *testing.T) {
+	assert := assert.New(t)
+
+	ctx := NewMockChainContext()
+	cs := ctx.MockChainState()
+
+	owner := signer.Signer1.Key().Address()
+	ownerAcc := cs.MustAccount(owner)
+	assert.NoError(ownerAcc.Add(ids.NativeToken, new(big.Int).SetUint64(unit.LDC)))
+
+	keys := make([][]byte, 2)
+	readers := make([][]byte, 2)
+	for i := range keys {
+		var err error
+		keys[i], err = ld.GenerateX25519Key()
+		require.NoError(t, err)
+		readers[i], err = ld.X25519PublicKey(keys[i])
+		require.NoError(t, err)
+	}
+	ep, err := ld.SealPayload([]byte("secret"), readers[0])
+	require.NoError(t, err)
+
+	di := &ld.DataInfo{
+		ModelID:   ld.EncryptedModelID,
+		Version:   1,
+		Threshold: 1,
+		Keepers:   signer.Keys{signer.Signer1.Key()},
+		Payload:   ep.Bytes(),
+		ID:        ids.DataID{1, 2, 3, 4},
+	}
+	assert.NoError(di.SyntacticVerify())
+	cs.SaveData(di)
+
+	input := &ld.TxUpdater{ID: &di.ID, Version: 1,
+		Data: ep.Bytes()[1:],
+	}
+	ltx := &ld.Transaction{Tx: ld.TxData{
+		Type:      ld.TypeUpdateData,
+		ChainID:   ctx.ChainConfig().ChainID,
+		Nonce:     0,
+		GasTip:    100,
+		GasFeeCap: ctx.Price,
+		From:      owner,
+		Data:      input.Bytes(),
+	}}
+	assert.NoError(ltx.SignWith(signer.Signer1))
+	assert.NoError(ltx.SyntacticVerify())
+	itx, err := NewTx(ltx)
+	require.NoError(t, err)
+	cs.CommitAccounts()
+	assert.ErrorContains(itx.Apply(ctx, cs), "ld.EncryptedPayload.Unmarshal")
+	cs.CheckoutAccounts()
+
+	// rotate readers, the whole payload is replaced
+	ep2, err := ep.RotateReaders(keys[0], readers[1])
+	require.NoError(t, err)
+	input = &ld.TxUpdater{ID: &di.ID, Version: 1,
+		Data: ep2.Bytes(),
+	}
+	ltx = &ld.Transaction{Tx: ld.TxData{
+		Type:      ld.TypeUpdateData,
+		ChainID:   ctx.ChainConfig().ChainID,
+		Nonce:     0,
+		GasTip:    100,
+		GasFeeCap: ctx.Price,
+		From:      owner,
+		Data:      input.Bytes(),
+	}}
+	assert.NoError(ltx.SignWith(signer.Signer1))
+	assert.NoError(ltx.SyntacticVerify())
+	itx, err = NewTx(ltx)
+	require.NoError(t, err)
+	assert.NoError(itx.Apply(ctx, cs))
+
+	di2, err := cs.LoadData(di.ID)
+	require.NoError(t, err)
+	assert.Equal(uint64(2), di2.Version)
+	assert.Equal(ep2.Bytes(), []byte(di2.Payload))
+	assert.Equal(cs.PDC[di.ID], di.Bytes())
+
+	ep3 := &ld.EncryptedPayload{}
+	assert.NoError(ep3.Unmarshal(di2.Payload))
+	_, err = ep3.Open(keys[0])
+	assert.ErrorContains(err, "no envelope for reader")
+	data, err := ep3.Open(keys[1])
+	require.NoError(t, err)
+	assert.Equal([]byte("secret"), data)
+
+	assert.NoError(cs.VerifyState())
+}
@@ -65,7 +65,8 @@ func (tx *TxUpgradeData) SyntacticVerify() error {
 		return errp.Errorf("invalid data id")
 
 	case tx.input.ModelID == nil || *tx.input.ModelID == ld.RawModelID ||
-		*tx.input.ModelID == ld.JSONModelID || *tx.input.ModelID == ld.CBORModelID:
+		*tx.input.ModelID == ld.JSONModelID || *tx.input.ModelID == ld.CBORModelID ||
+		*tx.input.ModelID == ld.EncryptedModelID:
 		return errp.Errorf("invalid model id")
 
 	case tx.input.Version == 0:
@@ -151,7 +152,7 @@ func (tx *TxUpgradeData) Apply(ctx ChainContext, cs ChainState) error {
 	case tx.di.SigClaims != nil && tx.input.SigClaims == nil:
 		return errp.Errorf("invalid sigClaims, should not be nil")
 
-	case !tx.di.VerifyPlus(tx.ld.TxHash(), tx.ld.Signatures):
+	case !tx.di.VerifyPlus(tx.ld.SigHash(), tx.ld.Signatures):
 		return errp.Errorf("invalid signatures for data keepers")
 
 	case !tx.ld.IsApproved(tx.di.Approver, tx.di.ApproveList, false):
@@ -185,6 +186,7 @@ func (tx *TxUpgradeData) Apply(ctx ChainContext, cs ChainState) error {
 
 	tx.di.Version++
 	tx.di.ModelID = mi.ID
+	tx.di.ModelVersion = mi.Version
 	if tx.input.SigClaims != nil {
 		tx.di.SigClaims = tx.input.SigClaims
 		tx.di.Sig = tx.input.Sig
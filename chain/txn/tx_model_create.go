@@ -118,7 +118,7 @@ func (tx *TxCreateModel) ApplyGenesis(ctx ChainContext, cs ChainState) error {
 			threshold = 1
 		}
 		if err = modelAcc.UpdateKeepers(
-			&threshold, &tx.input.Keepers, nil, nil); err != nil {
+			&threshold, &tx.input.Keepers, &tx.input.Weights, nil, nil); err != nil {
 			return errp.ErrorIf(err)
 		}
 	}
@@ -152,7 +152,7 @@ func (tx *TxCreateModel) Apply(ctx ChainContext, cs ChainState) error {
 			threshold = 1
 		}
 		if err = modelAcc.UpdateKeepers(
-			&threshold, &tx.input.Keepers, nil, nil); err != nil {
+			&threshold, &tx.input.Keepers, &tx.input.Weights, nil, nil); err != nil {
 			return errp.ErrorIf(err)
 		}
 	}
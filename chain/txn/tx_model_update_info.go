@@ -85,7 +85,7 @@ func (tx *TxUpdateModelInfo) Apply(ctx ChainContext, cs ChainState) error {
 	case err != nil:
 		return errp.ErrorIf(err)
 
-	case !tx.mi.VerifyPlus(tx.ld.TxHash(), tx.ld.Signatures):
+	case !tx.mi.VerifyPlus(tx.ld.SigHash(), tx.ld.Signatures):
 		return errp.Errorf("invalid signatures for keepers")
 
 	case !tx.ld.IsApproved(tx.mi.Approver, nil, false):
@@ -103,6 +103,10 @@ func (tx *TxUpdateModelInfo) Apply(ctx ChainContext, cs ChainState) error {
 	if tx.input.Threshold != nil {
 		tx.mi.Threshold = *tx.input.Threshold
 		tx.mi.Keepers = *tx.input.Keepers
+		tx.mi.Weights = nil
+		if tx.input.Weights != nil && len(*tx.input.Weights) > 0 {
+			tx.mi.Weights = *tx.input.Weights
+		}
 	}
 	if err = cs.SaveModel(tx.mi); err != nil {
 		return errp.ErrorIf(err)
@@ -0,0 +1,278 @@
+// (c) 2022-2022, LDC Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package txn
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ldclabs/ldvm/ids"
+	"github.com/ldclabs/ldvm/ld"
+	"github.com/ldclabs/ldvm/signer"
+	"github.com/ldclabs/ldvm/unit"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTxUpgradeModel(t *testing.T) {
+	assert := assert.New(t)
+
+	// SyntacticVerify
+	tx := &TxUpgradeModel{}
+	assert.ErrorContains(tx.SyntacticVerify(), "nil pointer")
+	_, err := tx.MarshalJSON()
+	require.NoError(t, err)
+
+	ctx := NewMockChainContext()
+	cs := ctx.MockChainState()
+	owner := signer.Signer1.Key().Address()
+
+	sc := `
+	type NameService struct {
+		name    String   (rename "n")
+		records [String] (rename "rs")
+	}
+`
+	sc1 := `
+	type NameService struct {
+		name    String          (rename "n")
+		records [String]        (rename "rs")
+		memo    optional String (rename "m")
+	}
+`
+
+	ltx := &ld.Transaction{Tx: ld.TxData{
+		Type:      ld.TypeUpgradeModel,
+		ChainID:   ctx.ChainConfig().ChainID,
+		Nonce:     0,
+		GasTip:    100,
+		GasFeeCap: ctx.Price,
+		From:      owner,
+		To:        ids.GenesisAccount.Ptr(),
+	}}
+	assert.NoError(ltx.SignWith(signer.Signer1))
+	assert.NoError(ltx.SyntacticVerify())
+	_, err = NewTx(ltx)
+	assert.ErrorContains(err, "invalid to, should be nil")
+
+	ltx = &ld.Transaction{Tx: ld.TxData{
+		Type:      ld.TypeUpgradeModel,
+		ChainID:   ctx.ChainConfig().ChainID,
+		Nonce:     0,
+		GasTip:    100,
+		GasFeeCap: ctx.Price,
+		From:      owner,
+	}}
+	assert.NoError(ltx.SignWith(signer.Signer1))
+	assert.NoError(ltx.SyntacticVerify())
+	_, err = NewTx(ltx)
+	assert.ErrorContains(err, "invalid data")
+
+	input := ld.TxUpdater{Data: []byte(sc1)}
+	ltx = &ld.Transaction{Tx: ld.TxData{
+		Type:      ld.TypeUpgradeModel,
+		ChainID:   ctx.ChainConfig().ChainID,
+		Nonce:     0,
+		GasTip:    100,
+		GasFeeCap: ctx.Price,
+		From:      owner,
+		Data:      input.Bytes(),
+	}}
+	assert.NoError(ltx.SignWith(signer.Signer1))
+	assert.NoError(ltx.SyntacticVerify())
+	_, err = NewTx(ltx)
+	assert.ErrorContains(err, "invalid mid")
+
+	mid := ids.ModelID{'1', '2', '3', '4', '5', '6'}
+	input = ld.TxUpdater{ModelID: &mid}
+	ltx = &ld.Transaction{Tx: ld.TxData{
+		Type:      ld.TypeUpgradeModel,
+		ChainID:   ctx.ChainConfig().ChainID,
+		Nonce:     0,
+		GasTip:    100,
+		GasFeeCap: ctx.Price,
+		From:      owner,
+		Data:      input.Bytes(),
+	}}
+	assert.NoError(ltx.SignWith(signer.Signer1))
+	assert.NoError(ltx.SyntacticVerify())
+	_, err = NewTx(ltx)
+	assert.ErrorContains(err, "nil schema to upgrade")
+
+	input = ld.TxUpdater{ModelID: &mid, Approver: signer.Signer2.Key().Ptr(), Data: []byte(sc1)}
+	ltx = &ld.Transaction{Tx: ld.TxData{
+		Type:      ld.TypeUpgradeModel,
+		ChainID:   ctx.ChainConfig().ChainID,
+		Nonce:     0,
+		GasTip:    100,
+		GasFeeCap: ctx.Price,
+		From:      owner,
+		Data:      input.Bytes(),
+	}}
+	assert.NoError(ltx.SignWith(signer.Signer1))
+	assert.NoError(ltx.SyntacticVerify())
+	_, err = NewTx(ltx)
+	assert.ErrorContains(err, "invalid updater, only schema can be upgraded")
+
+	input = ld.TxUpdater{ModelID: &mid, Version: 1, Data: []byte(sc1)}
+	ltx = &ld.Transaction{Tx: ld.TxData{
+		Type:      ld.TypeUpgradeModel,
+		ChainID:   ctx.ChainConfig().ChainID,
+		Nonce:     0,
+		GasTip:    100,
+		GasFeeCap: ctx.Price,
+		From:      owner,
+		Data:      input.Bytes(),
+	}}
+	assert.NoError(ltx.SignWith(signer.Signer1))
+	assert.NoError(ltx.SyntacticVerify())
+	itx, err := NewTx(ltx)
+	require.NoError(t, err)
+
+	cs.CommitAccounts()
+	assert.ErrorContains(itx.Apply(ctx, cs),
+		"insufficient NativeLDC balance, expected 2165900, got 0")
+	cs.CheckoutAccounts()
+	ownerAcc := cs.MustAccount(owner)
+	ownerAcc.Add(ids.NativeToken, new(big.Int).SetUint64(unit.LDC))
+	cs.CommitAccounts()
+	assert.ErrorContains(itx.Apply(ctx, cs),
+		"MTIzNDU2AAAAAAAAAAAAAAAAAABQtLNs not found")
+	cs.CheckoutAccounts()
+
+	mi := &ld.ModelInfo{
+		Name:      "NameService",
+		Threshold: 1,
+		Keepers:   signer.Keys{signer.Signer1.Key(), signer.Signer2.Key()},
+		Approver:  signer.Signer3.Key(),
+		Schema:    sc,
+		ID:        mid,
+	}
+	assert.NoError(mi.SyntacticVerify())
+	assert.NoError(cs.SaveModel(mi))
+
+	cs.CommitAccounts()
+	assert.ErrorContains(itx.Apply(ctx, cs), "invalid version, expected 0, got 1")
+	cs.CheckoutAccounts()
+
+	input = ld.TxUpdater{ModelID: &mid, Data: []byte(sc1)}
+	ltx = &ld.Transaction{Tx: ld.TxData{
+		Type:      ld.TypeUpgradeModel,
+		ChainID:   ctx.ChainConfig().ChainID,
+		Nonce:     0,
+		GasTip:    100,
+		GasFeeCap: ctx.Price,
+		From:      owner,
+		Data:      input.Bytes(),
+	}}
+	assert.NoError(ltx.SignWith(signer.Signer1))
+	assert.NoError(ltx.SyntacticVerify())
+	itx, err = NewTx(ltx)
+	require.NoError(t, err)
+
+	cs.CommitAccounts()
+	assert.ErrorContains(itx.Apply(ctx, cs), "invalid signatures for keepers")
+	cs.CheckoutAccounts()
+
+	ltx = &ld.Transaction{Tx: ld.TxData{
+		Type:      ld.TypeUpgradeModel,
+		ChainID:   ctx.ChainConfig().ChainID,
+		Nonce:     0,
+		GasTip:    100,
+		GasFeeCap: ctx.Price,
+		From:      owner,
+		Data:      input.Bytes(),
+	}}
+	assert.NoError(ltx.SignWith(signer.Signer1, signer.Signer2))
+	assert.NoError(ltx.SyntacticVerify())
+	itx, err = NewTx(ltx)
+	require.NoError(t, err)
+
+	cs.CommitAccounts()
+	assert.ErrorContains(itx.Apply(ctx, cs), "invalid signature for approver")
+	cs.CheckoutAccounts()
+
+	// incompatible schema
+	input = ld.TxUpdater{ModelID: &mid, Data: []byte(`
+	type NameService struct {
+		name    String   (rename "n")
+		records [String] (rename "rs")
+		memo    String   (rename "m")
+	}
+`)}
+	ltx = &ld.Transaction{Tx: ld.TxData{
+		Type:      ld.TypeUpgradeModel,
+		ChainID:   ctx.ChainConfig().ChainID,
+		Nonce:     0,
+		GasTip:    100,
+		GasFeeCap: ctx.Price,
+		From:      owner,
+		Data:      input.Bytes(),
+	}}
+	assert.NoError(ltx.SignWith(signer.Signer1, signer.Signer2, signer.Signer3))
+	assert.NoError(ltx.SyntacticVerify())
+	itx, err = NewTx(ltx)
+	require.NoError(t, err)
+
+	cs.CommitAccounts()
+	assert.ErrorContains(itx.Apply(ctx, cs), "new field NameService.memo should be optional")
+	cs.CheckoutAccounts()
+
+	input = ld.TxUpdater{ModelID: &mid, Data: []byte(sc1)}
+	ltx = &ld.Transaction{Tx: ld.TxData{
+		Type:      ld.TypeUpgradeModel,
+		ChainID:   ctx.ChainConfig().ChainID,
+		Nonce:     0,
+		GasTip:    100,
+		GasFeeCap: ctx.Price,
+		From:      owner,
+		Data:      input.Bytes(),
+	}}
+	assert.NoError(ltx.SignWith(signer.Signer1, signer.Signer2, signer.Signer3))
+	assert.NoError(ltx.SyntacticVerify())
+	itx, err = NewTx(ltx)
+	require.NoError(t, err)
+	assert.NoError(itx.Apply(ctx, cs))
+
+	assert.Equal(ltx.Gas()*ctx.Price, itx.(*TxUpgradeModel).ldc.Balance().Uint64())
+	assert.Equal(ltx.Gas()*100, itx.(*TxUpgradeModel).miner.Balance().Uint64())
+	assert.Equal(unit.LDC-ltx.Gas()*(ctx.Price+100),
+		ownerAcc.BalanceOfAll(ids.NativeToken).Uint64())
+	assert.Equal(uint64(1), ownerAcc.Nonce())
+
+	mi, err = cs.LoadModel(mid)
+	require.NoError(t, err)
+	assert.Equal(uint64(1), mi.Version)
+	assert.Equal(sc1, mi.Schema)
+	assert.Equal([]string{sc}, mi.PrevSchemas)
+	im, err := mi.ModelAt(0)
+	require.NoError(t, err)
+	assert.Equal(sc, im.Schema())
+
+	jsondata, err := itx.MarshalJSON()
+	require.NoError(t, err)
+	// fmt.Println(string(jsondata))
+	assert.Equal(`{"tx":{"type":"TypeUpgradeModel","chainID":2357,"nonce":0,"gasTip":100,"gasFeeCap":1000,"from":"0x8db97c7cECe249C2b98bdc0226cc4C2A57bF52fc","data":{"mid":"MTIzNDU2AAAAAAAAAAAAAAAAAABQtLNs","data":"Cgl0eXBlIE5hbWVTZXJ2aWNlIHN0cnVjdCB7CgkJbmFtZSAgICBTdHJpbmcgICAgICAgICAgKHJlbmFtZSAibiIpCgkJcmVjb3JkcyBbU3RyaW5nXSAgICAgICAgKHJlbmFtZSAicnMiKQoJCW1lbW8gICAgb3B0aW9uYWwgU3RyaW5nIChyZW5hbWUgIm0iKQoJfQpbA_ui"}},"sigs":["xEgylgLp4iRPayJu7O8uuWK-kjN-pzVDYc06NpeH8IIN4AHmP6wY4V9Cbb9dVrnphSNGS_Ou2LcgKvCmrVOvlAHWLNHF","UzWeLpWJlT49DTN-dMSkrfeHbKxtchCcZYXhGrAyWVI4jkpwaVnmbwjEeTpeKHWiOjD6ysC1z6pz52ce6YruFABdQkga","CBfmlgEFvcB2Yg2Ln2-fNlOd3Y03re-02DbA0HGDhFzYWrN0hh3RZj4IFyNQt9CgHvzCAZfVeLJfP9Fid5pZDGiLM0A"],"id":"8J1vtRxjqRAxoUuCAkT0hbucreB4WoFlIva9ugOKZ7x9VzFK"}`, string(jsondata))
+
+	// replay with the old version
+	ltx = &ld.Transaction{Tx: ld.TxData{
+		Type:      ld.TypeUpgradeModel,
+		ChainID:   ctx.ChainConfig().ChainID,
+		Nonce:     1,
+		GasTip:    100,
+		GasFeeCap: ctx.Price,
+		From:      owner,
+		Data:      input.Bytes(),
+	}}
+	assert.NoError(ltx.SignWith(signer.Signer1, signer.Signer2, signer.Signer3))
+	assert.NoError(ltx.SyntacticVerify())
+	itx, err = NewTx(ltx)
+	require.NoError(t, err)
+
+	cs.CommitAccounts()
+	assert.ErrorContains(itx.Apply(ctx, cs), "invalid version, expected 1, got 0")
+	cs.CheckoutAccounts()
+
+	assert.NoError(cs.VerifyState())
+}
@@ -29,7 +29,7 @@ func TestTxPunish(t *testing.T) {
 	cs := ctx.MockChainState()
 
 	from := cs.MustAccount(ids.GenesisAccount)
-	assert.NoError(from.UpdateKeepers(ld.Uint16Ptr(1), &signer.Keys{signer.Signer1.Key()}, nil, nil))
+	assert.NoError(from.UpdateKeepers(ld.Uint16Ptr(1), &signer.Keys{signer.Signer1.Key()}, nil, nil, nil))
 
 	to := cs.MustAccount(signer.Signer2.Key().Address())
 
@@ -288,7 +288,7 @@ func TestTxPunishNameServiceData(t *testing.T) {
 	assert.Equal(mi.ID, di.ModelID)
 
 	genesis := cs.MustAccount(ids.GenesisAccount)
-	assert.NoError(genesis.UpdateKeepers(ld.Uint16Ptr(1), &signer.Keys{signer.Signer1.Key()}, nil, nil))
+	assert.NoError(genesis.UpdateKeepers(ld.Uint16Ptr(1), &signer.Keys{signer.Signer1.Key()}, nil, nil, nil))
 	genesis.Add(ids.NativeToken, new(big.Int).SetUint64(unit.LDC))
 
 	input = &ld.TxUpdater{ID: &di.ID}
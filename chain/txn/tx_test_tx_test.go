@@ -174,3 +174,51 @@ func TestTxTest(t *testing.T) {
 	// fmt.Println(string(jsondata))
 	assert.Equal(`{"tx":{"type":"TypeTest","chainID":2357,"nonce":0,"gasTip":100,"gasFeeCap":1000,"from":"0x8db97c7cECe249C2b98bdc0226cc4C2A57bF52fc","data":{"objectType":"Address","objectID":"0x8db97c7cECe249C2b98bdc0226cc4C2A57bF52fc","tests":[{"Op":6,"From":null,"Path":["ab"],"Value":"wkQ7msoA"}]}},"sigs":["knIg9rVI5tp4iHLYS5PkIxdfyIRv-dOvSbtDdphKIGoKkitYNLbuqdirPKODVQ4r2x9hfsS0ee_W2kDdk0I4vgAitab1"],"id":"Kcv1qOSxiTkTV3Hw41OpdBLBDv9A5qbRQbwqJImkoSyA6bpQ"}`, string(jsondata))
 }
+
+func TestTxTestCompares(t *testing.T) {
+	assert := assert.New(t)
+
+	ctx := NewMockChainContext()
+	cs := ctx.MockChainState()
+	sender := signer.Signer1.Key().Address()
+
+	input := ld.TxTester{
+		ObjectType: ld.AddressObject,
+		ObjectID:   sender.String(),
+		Compares: []*ld.Comparison{
+			{Op: ld.CompareGTE, Path: cborpatch.PathMustFrom("b"), Value: new(big.Int).SetUint64(unit.LDC)},
+			{Op: ld.CompareRange, Path: cborpatch.PathMustFrom("n"), Value: big.NewInt(0), Max: big.NewInt(1)},
+		},
+	}
+	assert.NoError(input.SyntacticVerify())
+	ltx := &ld.Transaction{Tx: ld.TxData{
+		Type:      ld.TypeTest,
+		ChainID:   ctx.ChainConfig().ChainID,
+		Nonce:     0,
+		GasTip:    100,
+		GasFeeCap: ctx.Price,
+		From:      sender,
+		Data:      input.Bytes(),
+	}}
+	assert.NoError(ltx.SignWith(signer.Signer1))
+	assert.NoError(ltx.SyntacticVerify())
+	itx, err := NewTx(ltx)
+	require.NoError(t, err)
+
+	senderAcc := cs.MustAccount(sender)
+	senderAcc.Add(ids.NativeToken, new(big.Int).SetUint64(unit.LDC-1))
+	cs.CommitAccounts()
+	assert.ErrorContains(itx.Apply(ctx, cs),
+		`compare operation for path ["b"] failed, expected gte 1000000000, got 999999999`)
+	cs.CheckoutAccounts()
+
+	senderAcc.Add(ids.NativeToken, big.NewInt(1))
+	assert.NoError(itx.Apply(ctx, cs))
+	assert.Equal(uint64(1), senderAcc.Nonce())
+
+	jsondata, err := itx.MarshalJSON()
+	require.NoError(t, err)
+	assert.Contains(string(jsondata), `"compares":[{"op":"gte"`)
+
+	assert.NoError(cs.VerifyState())
+}
@@ -108,6 +108,13 @@ func (tx *TxTransferCash) Apply(ctx ChainContext, cs ChainState) error {
 		return errp.Errorf("invalid signature for issuer")
 	}
 
+	// the token is paid by the issuer, so the issuer's policy applies
+	if err = tx.to.CheckPolicy(&tx.ld.Tx.From, tx.token, tx.input.Amount); err != nil {
+		return errp.ErrorIf(err)
+	}
+	if err = tx.to.SpendByPolicy(tx.token, tx.input.Amount); err != nil {
+		return errp.ErrorIf(err)
+	}
 	if err = tx.to.SubByNonceTable(
 		tx.token, tx.input.Expire, tx.input.Nonce, tx.input.Amount); err != nil {
 		return errp.ErrorIf(err)
@@ -0,0 +1,146 @@
+// (c) 2022-2022, LDC Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package txn
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/fxamacker/cbor/v2"
+	"github.com/ldclabs/ldvm/ids"
+	"github.com/ldclabs/ldvm/ld"
+	"github.com/ldclabs/ldvm/signer"
+	"github.com/ldclabs/ldvm/unit"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTxCreateEscrow(t *testing.T) {
+	assert := assert.New(t)
+
+	// SyntacticVerify
+	tx := &TxCreateEscrow{}
+	assert.ErrorContains(tx.SyntacticVerify(), "nil pointer")
+	_, err := tx.MarshalJSON()
+	require.NoError(t, err)
+
+	ctx := NewMockChainContext()
+	cs := ctx.MockChainState()
+	sender := signer.Signer1.Key().Address()
+	payee := signer.Signer2.Key().Address()
+	arbiter := ids.GenesisAccount
+
+	ltx := &ld.Transaction{Tx: ld.TxData{
+		Type:      ld.TypeCreateEscrow,
+		ChainID:   ctx.ChainConfig().ChainID,
+		Nonce:     0,
+		GasTip:    100,
+		GasFeeCap: ctx.Price,
+		From:      sender,
+	}}
+	assert.NoError(ltx.SignWith(signer.Signer1))
+	assert.NoError(ltx.SyntacticVerify())
+	_, err = NewTx(ltx)
+	assert.ErrorContains(err, "nil to as payee")
+
+	ltx = &ld.Transaction{Tx: ld.TxData{
+		Type:      ld.TypeCreateEscrow,
+		ChainID:   ctx.ChainConfig().ChainID,
+		Nonce:     0,
+		GasTip:    100,
+		GasFeeCap: ctx.Price,
+		From:      sender,
+		To:        &payee,
+		Amount:    big.NewInt(1),
+	}}
+	assert.NoError(ltx.SignWith(signer.Signer1))
+	assert.NoError(ltx.SyntacticVerify())
+	_, err = NewTx(ltx)
+	assert.ErrorContains(err, "invalid amount, should be nil")
+
+	ltx = &ld.Transaction{Tx: ld.TxData{
+		Type:      ld.TypeCreateEscrow,
+		ChainID:   ctx.ChainConfig().ChainID,
+		Nonce:     0,
+		GasTip:    100,
+		GasFeeCap: ctx.Price,
+		From:      sender,
+		To:        &payee,
+	}}
+	assert.NoError(ltx.SignWith(signer.Signer1))
+	assert.NoError(ltx.SyntacticVerify())
+	_, err = NewTx(ltx)
+	assert.ErrorContains(err, "invalid data")
+
+	input := &ld.TxEscrow{Arbiter: payee, Amount: new(big.Int).SetUint64(unit.LDC * 10)}
+	ltx = &ld.Transaction{Tx: ld.TxData{
+		Type:      ld.TypeCreateEscrow,
+		ChainID:   ctx.ChainConfig().ChainID,
+		Nonce:     0,
+		GasTip:    100,
+		GasFeeCap: ctx.Price,
+		From:      sender,
+		To:        &payee,
+		Data:      ld.MustMarshal(input),
+	}}
+	assert.NoError(ltx.SignWith(signer.Signer1))
+	assert.NoError(ltx.SyntacticVerify())
+	_, err = NewTx(ltx)
+	assert.ErrorContains(err, "invalid arbiter, should not be payer or payee")
+
+	input = &ld.TxEscrow{Arbiter: arbiter, Amount: new(big.Int).SetUint64(unit.LDC * 10)}
+	ltx = &ld.Transaction{Tx: ld.TxData{
+		Type:      ld.TypeCreateEscrow,
+		ChainID:   ctx.ChainConfig().ChainID,
+		Nonce:     0,
+		GasTip:    100,
+		GasFeeCap: ctx.Price,
+		From:      sender,
+		To:        &payee,
+		Data:      ld.MustMarshal(input),
+	}}
+	assert.NoError(ltx.SignWith(signer.Signer1))
+	assert.NoError(ltx.SyntacticVerify())
+	itx, err := NewTx(ltx)
+	require.NoError(t, err)
+
+	cs.CommitAccounts()
+	assert.ErrorContains(itx.Apply(ctx, cs),
+		"insufficient NativeLDC balance, expected 1386000, got 0")
+	cs.CheckoutAccounts()
+
+	senderAcc := cs.MustAccount(sender)
+	senderAcc.Add(ids.NativeToken, new(big.Int).SetUint64(unit.LDC*5))
+	cs.CommitAccounts()
+	assert.ErrorContains(itx.Apply(ctx, cs),
+		"insufficient transferable NativeLDC balance, expected 10000000000")
+	cs.CheckoutAccounts()
+
+	senderAcc.Add(ids.NativeToken, new(big.Int).SetUint64(unit.LDC*10))
+	assert.NoError(itx.Apply(ctx, cs))
+
+	senderGas := ltx.Gas()
+	assert.Equal(senderGas*ctx.Price,
+		itx.(*TxCreateEscrow).ldc.Balance().Uint64())
+	assert.Equal(senderGas*100,
+		itx.(*TxCreateEscrow).miner.Balance().Uint64())
+	assert.Equal(unit.LDC*5-senderGas*(ctx.Price+100),
+		senderAcc.BalanceOfAll(ids.NativeToken).Uint64())
+	assert.Equal(uint64(1), senderAcc.Nonce())
+
+	entry := senderAcc.Ledger().Escrow[cbor.ByteString(ltx.ID[:])]
+	require.NotNil(t, entry)
+	assert.Equal(payee, entry.Payee)
+	assert.Equal(arbiter, entry.Arbiter)
+	assert.Equal(ids.NativeToken, entry.Token)
+	assert.Equal(unit.LDC*10, entry.Amount.Uint64())
+	assert.Equal(uint64(0), cs.MustAccount(payee).Balance().Uint64())
+
+	jsondata, err := itx.MarshalJSON()
+	require.NoError(t, err)
+	assert.Contains(string(jsondata), `"type":"TypeCreateEscrow"`)
+	assert.Contains(string(jsondata), `"data":{"arbiter":"0xFFfFFFfFfffFFfFFffFFFfFfFffFFFfffFfFFFff","amount":10000000000}`)
+
+	assert.NoError(cs.VerifyState())
+}
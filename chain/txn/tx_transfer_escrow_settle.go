@@ -0,0 +1,118 @@
+// (c) 2022-2022, LDC Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package txn
+
+import (
+	"encoding/json"
+
+	"github.com/ldclabs/ldvm/chain/acct"
+	"github.com/ldclabs/ldvm/ld"
+	"github.com/ldclabs/ldvm/util/erring"
+)
+
+type TxSettleEscrow struct {
+	TxBase
+	input *ld.TxEscrowSettler
+}
+
+func (tx *TxSettleEscrow) MarshalJSON() ([]byte, error) {
+	if tx == nil || tx.ld == nil {
+		return []byte("null"), nil
+	}
+
+	v := tx.ld.Copy()
+	errp := erring.ErrPrefix("txn.TxSettleEscrow.MarshalJSON: ")
+	if tx.input == nil {
+		return nil, errp.Errorf("nil tx.input")
+	}
+	d, err := json.Marshal(tx.input)
+	if err != nil {
+		return nil, errp.ErrorIf(err)
+	}
+	v.Tx.Data = d
+	return errp.ErrorMap(json.Marshal(v))
+}
+
+func (tx *TxSettleEscrow) SyntacticVerify() error {
+	var err error
+	errp := erring.ErrPrefix("txn.TxSettleEscrow.SyntacticVerify: ")
+
+	if err = tx.TxBase.SyntacticVerify(); err != nil {
+		return errp.ErrorIf(err)
+	}
+
+	switch {
+	case tx.ld.Tx.To != nil:
+		return errp.Errorf("invalid to, should be nil")
+
+	case tx.ld.Tx.Amount != nil:
+		return errp.Errorf("invalid amount, should be nil")
+
+	case len(tx.ld.Tx.Data) == 0:
+		return errp.Errorf("invalid data")
+
+	case len(tx.ld.ExSignatures) == 0:
+		return errp.Errorf("no exSignatures")
+	}
+
+	tx.input = &ld.TxEscrowSettler{}
+	if err = tx.input.Unmarshal(tx.ld.Tx.Data); err != nil {
+		return errp.ErrorIf(err)
+	}
+	if err = tx.input.SyntacticVerify(); err != nil {
+		return errp.ErrorIf(err)
+	}
+	return nil
+}
+
+func (tx *TxSettleEscrow) Apply(ctx ChainContext, cs ChainState) error {
+	var err error
+	errp := erring.ErrPrefix("txn.TxSettleEscrow.Apply: ")
+
+	if err = tx.TxBase.verify(ctx, cs); err != nil {
+		return errp.ErrorIf(err)
+	}
+
+	payer, err := cs.LoadAccount(tx.input.Payer)
+	if err != nil {
+		return errp.ErrorIf(err)
+	}
+	if err = cs.LoadLedger(payer); err != nil {
+		return errp.ErrorIf(err)
+	}
+
+	entry, err := payer.Escrow(tx.input.ID)
+	if err != nil {
+		return errp.ErrorIf(err)
+	}
+
+	payee, err := cs.LoadAccount(entry.Payee)
+	if err != nil {
+		return errp.ErrorIf(err)
+	}
+	arbiter, err := cs.LoadAccount(entry.Arbiter)
+	if err != nil {
+		return errp.ErrorIf(err)
+	}
+
+	// verify 2-of-3 approvals from payer, payee and arbiter,
+	// each party is verified by its own keepers and threshold
+	approved := 0
+	for _, party := range []*acct.Account{payer, payee, arbiter} {
+		if party.Verify(tx.ld.ExHash(), tx.ld.ExSignatures, party.IDKey()) {
+			approved++
+		}
+	}
+	if approved < 2 {
+		return errp.Errorf("invalid exSignatures for escrow, expected 2 parties approved, got %d", approved)
+	}
+
+	if _, err = payer.SettleEscrow(tx.input.ID, tx.input.Release); err != nil {
+		return errp.ErrorIf(err)
+	}
+	if err = payee.Add(entry.Token, tx.input.Release); err != nil {
+		return errp.ErrorIf(err)
+	}
+	return errp.ErrorIf(tx.TxBase.accept(ctx, cs))
+}
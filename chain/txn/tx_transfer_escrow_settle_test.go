@@ -0,0 +1,197 @@
+// (c) 2022-2022, LDC Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package txn
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ldclabs/ldvm/ids"
+	"github.com/ldclabs/ldvm/ld"
+	"github.com/ldclabs/ldvm/signer"
+	"github.com/ldclabs/ldvm/unit"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTxSettleEscrow(t *testing.T) {
+	assert := assert.New(t)
+
+	// SyntacticVerify
+	tx := &TxSettleEscrow{}
+	assert.ErrorContains(tx.SyntacticVerify(), "nil pointer")
+	_, err := tx.MarshalJSON()
+	require.NoError(t, err)
+
+	ctx := NewMockChainContext()
+	cs := ctx.MockChainState()
+	arbiterSigner, err := signer.NewSecp256k1()
+	require.NoError(t, err)
+	payer := signer.Signer1.Key().Address()
+	payee := signer.Signer2.Key().Address()
+	arbiter := arbiterSigner.Key().Address()
+	escrowID := ids.ID32{1, 2, 3}
+
+	ltx := &ld.Transaction{Tx: ld.TxData{
+		Type:      ld.TypeSettleEscrow,
+		ChainID:   ctx.ChainConfig().ChainID,
+		Nonce:     0,
+		GasTip:    100,
+		GasFeeCap: ctx.Price,
+		From:      payee,
+		To:        &payer,
+	}}
+	assert.NoError(ltx.SignWith(signer.Signer2))
+	assert.NoError(ltx.SyntacticVerify())
+	_, err = NewTx(ltx)
+	assert.ErrorContains(err, "invalid to, should be nil")
+
+	ltx = &ld.Transaction{Tx: ld.TxData{
+		Type:      ld.TypeSettleEscrow,
+		ChainID:   ctx.ChainConfig().ChainID,
+		Nonce:     0,
+		GasTip:    100,
+		GasFeeCap: ctx.Price,
+		From:      payee,
+	}}
+	assert.NoError(ltx.SignWith(signer.Signer2))
+	assert.NoError(ltx.SyntacticVerify())
+	_, err = NewTx(ltx)
+	assert.ErrorContains(err, "invalid data")
+
+	input := &ld.TxEscrowSettler{
+		Payer:   payer,
+		ID:      escrowID,
+		Release: new(big.Int).SetUint64(unit.LDC * 6),
+	}
+	ltx = &ld.Transaction{Tx: ld.TxData{
+		Type:      ld.TypeSettleEscrow,
+		ChainID:   ctx.ChainConfig().ChainID,
+		Nonce:     0,
+		GasTip:    100,
+		GasFeeCap: ctx.Price,
+		From:      payee,
+		Data:      ld.MustMarshal(input),
+	}}
+	assert.NoError(ltx.SignWith(signer.Signer2))
+	assert.NoError(ltx.SyntacticVerify())
+	_, err = NewTx(ltx)
+	assert.ErrorContains(err, "no exSignatures")
+
+	ltx = &ld.Transaction{Tx: ld.TxData{
+		Type:      ld.TypeSettleEscrow,
+		ChainID:   ctx.ChainConfig().ChainID,
+		Nonce:     0,
+		GasTip:    100,
+		GasFeeCap: ctx.Price,
+		From:      payee,
+		Data:      ld.MustMarshal(input),
+	}}
+	assert.NoError(ltx.SignWith(signer.Signer2))
+	assert.NoError(ltx.ExSignWith(signer.Signer2))
+	assert.NoError(ltx.SyntacticVerify())
+	itx, err := NewTx(ltx)
+	require.NoError(t, err)
+
+	cs.CommitAccounts()
+	assert.ErrorContains(itx.Apply(ctx, cs),
+		"insufficient NativeLDC balance, expected 1898600, got 0")
+	cs.CheckoutAccounts()
+
+	payeeAcc := cs.MustAccount(payee)
+	payeeAcc.Add(ids.NativeToken, new(big.Int).SetUint64(unit.LDC))
+	cs.CommitAccounts()
+	assert.ErrorContains(itx.Apply(ctx, cs),
+		"escrow "+escrowID.String()+" not found")
+	cs.CheckoutAccounts()
+
+	payerAcc := cs.MustAccount(payer)
+	require.NoError(t, cs.LoadLedger(payerAcc))
+	payerAcc.Add(ids.NativeToken, new(big.Int).SetUint64(unit.LDC*11))
+	assert.NoError(payerAcc.CreateEscrow(escrowID, ids.NativeToken, payee,
+		&ld.TxEscrow{Arbiter: arbiter, Amount: new(big.Int).SetUint64(unit.LDC * 10)}))
+	cs.CommitAccounts()
+	assert.ErrorContains(itx.Apply(ctx, cs),
+		"invalid exSignatures for escrow, expected 2 parties approved, got 1")
+	cs.CheckoutAccounts()
+
+	// the arbiter is a multisig account, verified by its own keepers
+	arbiterAcc := cs.MustAccount(arbiter)
+	assert.NoError(arbiterAcc.UpdateKeepers(ld.Uint16Ptr(2),
+		&signer.Keys{signer.Signer3.Key(), signer.Signer4.Key()}, nil, nil, nil))
+	ltx = &ld.Transaction{Tx: ld.TxData{
+		Type:      ld.TypeSettleEscrow,
+		ChainID:   ctx.ChainConfig().ChainID,
+		Nonce:     0,
+		GasTip:    100,
+		GasFeeCap: ctx.Price,
+		From:      payee,
+		Data:      ld.MustMarshal(input),
+	}}
+	assert.NoError(ltx.SignWith(signer.Signer2))
+	assert.NoError(ltx.ExSignWith(signer.Signer2, arbiterSigner, signer.Signer3))
+	assert.NoError(ltx.SyntacticVerify())
+	itx, err = NewTx(ltx)
+	require.NoError(t, err)
+	cs.CommitAccounts()
+	assert.ErrorContains(itx.Apply(ctx, cs),
+		"invalid exSignatures for escrow, expected 2 parties approved, got 1")
+	cs.CheckoutAccounts()
+
+	// payee and arbiter agree to split the escrow
+	ltx = &ld.Transaction{Tx: ld.TxData{
+		Type:      ld.TypeSettleEscrow,
+		ChainID:   ctx.ChainConfig().ChainID,
+		Nonce:     0,
+		GasTip:    100,
+		GasFeeCap: ctx.Price,
+		From:      payee,
+		Data:      ld.MustMarshal(input),
+	}}
+	assert.NoError(ltx.SignWith(signer.Signer2))
+	assert.NoError(ltx.ExSignWith(signer.Signer2, signer.Signer3, signer.Signer4))
+	assert.NoError(ltx.SyntacticVerify())
+	itx, err = NewTx(ltx)
+	require.NoError(t, err)
+
+	input.Release = new(big.Int).SetUint64(unit.LDC * 11)
+	ltx2 := &ld.Transaction{Tx: ld.TxData{
+		Type:      ld.TypeSettleEscrow,
+		ChainID:   ctx.ChainConfig().ChainID,
+		Nonce:     0,
+		GasTip:    100,
+		GasFeeCap: ctx.Price,
+		From:      payee,
+		Data:      ld.MustMarshal(input),
+	}}
+	assert.NoError(ltx2.SignWith(signer.Signer2))
+	assert.NoError(ltx2.ExSignWith(signer.Signer2, signer.Signer3, signer.Signer4))
+	assert.NoError(ltx2.SyntacticVerify())
+	itx2, err := NewTx(ltx2)
+	require.NoError(t, err)
+	cs.CommitAccounts()
+	assert.ErrorContains(itx2.Apply(ctx, cs),
+		"invalid release, expected <= 10000000000, got 11000000000")
+	cs.CheckoutAccounts()
+
+	assert.NoError(itx.Apply(ctx, cs))
+
+	payeeGas := ltx.Gas()
+	assert.Equal(payeeGas*ctx.Price,
+		itx.(*TxSettleEscrow).ldc.Balance().Uint64())
+	assert.Equal(payeeGas*100,
+		itx.(*TxSettleEscrow).miner.Balance().Uint64())
+	assert.Equal(unit.LDC*7-payeeGas*(ctx.Price+100),
+		payeeAcc.BalanceOfAll(ids.NativeToken).Uint64())
+	assert.Equal(uint64(1), payeeAcc.Nonce())
+	assert.Equal(unit.LDC*5, payerAcc.BalanceOfAll(ids.NativeToken).Uint64())
+	assert.Equal(0, len(payerAcc.Ledger().Escrow))
+
+	jsondata, err := itx.MarshalJSON()
+	require.NoError(t, err)
+	assert.Contains(string(jsondata), `"type":"TypeSettleEscrow"`)
+	assert.Contains(string(jsondata), `"release":6000000000`)
+
+	assert.NoError(cs.VerifyState())
+}
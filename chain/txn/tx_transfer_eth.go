@@ -69,6 +69,12 @@ func (tx *TxEth) SyntacticVerify() error {
 
 	case tx.ld.ExSignatures != nil:
 		return errp.Errorf("invalid exSignatures")
+
+	case tx.ld.Tx.Payer != nil:
+		return errp.Errorf("invalid payer, should be nil")
+
+	case tx.ld.EIP712:
+		return errp.Errorf("invalid eip712, should be false")
 	}
 
 	return nil
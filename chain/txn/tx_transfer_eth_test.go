@@ -93,6 +93,14 @@ func TestTxEth(t *testing.T) {
 	tx.ld.ExSignatures = signer.Sigs{}
 	assert.ErrorContains(itx.SyntacticVerify(), "invalid exSignatures")
 	tx.ld.ExSignatures = nil
+	tx.ld.Tx.Payer = ids.GenesisAccount.Ptr()
+	tx.ld.PayerSignatures = sigs
+	assert.ErrorContains(itx.SyntacticVerify(), "invalid payer, should be nil")
+	tx.ld.Tx.Payer = nil
+	tx.ld.PayerSignatures = nil
+	tx.ld.EIP712 = true
+	assert.ErrorContains(itx.SyntacticVerify(), "invalid eip712, should be false")
+	tx.ld.EIP712 = false
 	assert.NoError(itx.SyntacticVerify())
 	itx, err = NewTx(ltx)
 	require.NoError(t, err)
@@ -7,6 +7,7 @@ import (
 	"encoding/json"
 	"math/big"
 
+	"github.com/ldclabs/ldvm/ids"
 	"github.com/ldclabs/ldvm/ld"
 	"github.com/ldclabs/ldvm/unit"
 	"github.com/ldclabs/ldvm/util/erring"
@@ -116,10 +117,25 @@ func (tx *TxExchange) Apply(ctx ChainContext, cs ChainState) error {
 		return errp.Errorf("invalid signatures for seller")
 	}
 
-	if err = tx.to.SubByNonceTable(
+	// the sold token is paid by the seller, so the seller's policy applies
+	if err = tx.to.CheckPolicy(&tx.ld.Tx.From, tx.input.Sell, tx.quantity); err != nil {
+		return errp.ErrorIf(err)
+	}
+	if tx.input.Partial {
+		if err = cs.LoadLedger(tx.to); err != nil {
+			return errp.ErrorIf(err)
+		}
+		if err = tx.to.FillOrder(
+			ids.ID32FromData(tx.input.Bytes()), tx.input, tx.quantity); err != nil {
+			return errp.ErrorIf(err)
+		}
+	} else if err = tx.to.SubByNonceTable(
 		tx.input.Sell, tx.input.Expire, tx.input.Nonce, tx.quantity); err != nil {
 		return errp.ErrorIf(err)
 	}
+	if err = tx.to.SpendByPolicy(tx.input.Sell, tx.quantity); err != nil {
+		return errp.ErrorIf(err)
+	}
 	if err = tx.from.Add(tx.input.Sell, tx.quantity); err != nil {
 		return errp.ErrorIf(err)
 	}
@@ -0,0 +1,156 @@
+// (c) 2022-2022, LDC Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package txn
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ldclabs/ldvm/ids"
+	"github.com/ldclabs/ldvm/ld"
+	"github.com/ldclabs/ldvm/signer"
+	"github.com/ldclabs/ldvm/unit"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTxCancelExchange(t *testing.T) {
+	assert := assert.New(t)
+
+	// SyntacticVerify
+	tx := &TxCancelExchange{}
+	assert.ErrorContains(tx.SyntacticVerify(), "nil pointer")
+	_, err := tx.MarshalJSON()
+	require.NoError(t, err)
+
+	ctx := NewMockChainContext()
+	cs := ctx.MockChainState()
+	seller := signer.Signer1.Key().Address()
+	token := ld.MustNewToken("$TEST")
+
+	ltx := &ld.Transaction{Tx: ld.TxData{
+		Type:      ld.TypeCancelExchange,
+		ChainID:   ctx.ChainConfig().ChainID,
+		Nonce:     0,
+		GasTip:    100,
+		GasFeeCap: ctx.Price,
+		From:      seller,
+		To:        signer.Signer2.Key().Address().Ptr(),
+	}}
+	assert.NoError(ltx.SignWith(signer.Signer1))
+	assert.NoError(ltx.SyntacticVerify())
+	_, err = NewTx(ltx)
+	assert.ErrorContains(err, "invalid to, should be nil")
+
+	ltx = &ld.Transaction{Tx: ld.TxData{
+		Type:      ld.TypeCancelExchange,
+		ChainID:   ctx.ChainConfig().ChainID,
+		Nonce:     0,
+		GasTip:    100,
+		GasFeeCap: ctx.Price,
+		From:      seller,
+	}}
+	assert.NoError(ltx.SignWith(signer.Signer1))
+	assert.NoError(ltx.SyntacticVerify())
+	_, err = NewTx(ltx)
+	assert.ErrorContains(err, "invalid data")
+
+	ltx = &ld.Transaction{Tx: ld.TxData{
+		Type:      ld.TypeCancelExchange,
+		ChainID:   ctx.ChainConfig().ChainID,
+		Nonce:     0,
+		GasTip:    100,
+		GasFeeCap: ctx.Price,
+		From:      seller,
+		Data:      ld.MustMarshal(&ld.TxExchanger{}),
+	}}
+	assert.NoError(ltx.SignWith(signer.Signer1))
+	assert.NoError(ltx.SyntacticVerify())
+	_, err = NewTx(ltx)
+	assert.ErrorContains(err, "invalid nonce")
+
+	input := &ld.TxExchanger{
+		Nonce:   1,
+		Sell:    token,
+		Receive: ids.NativeToken,
+		Quota:   new(big.Int).SetUint64(unit.LDC * 3),
+		Minimum: new(big.Int).SetUint64(unit.LDC),
+		Price:   new(big.Int).SetUint64(1_000_000),
+		Expire:  cs.Timestamp() + 1,
+		Payee:   seller,
+		Partial: true,
+	}
+	assert.NoError(input.SyntacticVerify())
+	id := ids.ID32FromData(input.Bytes())
+
+	ltx = &ld.Transaction{Tx: ld.TxData{
+		Type:      ld.TypeCancelExchange,
+		ChainID:   ctx.ChainConfig().ChainID,
+		Nonce:     0,
+		GasTip:    100,
+		GasFeeCap: ctx.Price,
+		From:      seller,
+		Data:      input.Bytes(),
+	}}
+	assert.NoError(ltx.SignWith(signer.Signer1))
+	assert.NoError(ltx.SyntacticVerify())
+	itx, err := NewTx(ltx)
+	require.NoError(t, err)
+
+	cs.CommitAccounts()
+	assert.ErrorContains(itx.Apply(ctx, cs),
+		"insufficient NativeLDC balance, expected 1181400, got 0")
+	cs.CheckoutAccounts()
+
+	sellerAcc := cs.MustAccount(seller)
+	sellerAcc.Add(ids.NativeToken, new(big.Int).SetUint64(unit.LDC))
+	sellerAcc.Add(token, new(big.Int).SetUint64(unit.LDC*3))
+	cs.CommitAccounts()
+	assert.ErrorContains(itx.Apply(ctx, cs),
+		"order "+id.String()+" not exists")
+	cs.CheckoutAccounts()
+
+	// cancel the unfilled order by its nonce
+	assert.NoError(sellerAcc.UpdateNonceTable(cs.Timestamp()+1, []uint64{1, 2}))
+	assert.NoError(itx.Apply(ctx, cs))
+
+	senderGas := ltx.Gas()
+	assert.Equal(senderGas*ctx.Price,
+		itx.(*TxCancelExchange).ldc.Balance().Uint64())
+	assert.Equal(senderGas*100,
+		itx.(*TxCancelExchange).miner.Balance().Uint64())
+	assert.Equal(unit.LDC-senderGas*(ctx.Price+100),
+		sellerAcc.BalanceOfAll(ids.NativeToken).Uint64())
+	assert.Equal(uint64(1), sellerAcc.Nonce())
+	assert.Equal([]uint64{2}, sellerAcc.LD().NonceTable[cs.Timestamp()+1])
+
+	jsondata, err := itx.MarshalJSON()
+	require.NoError(t, err)
+	assert.Contains(string(jsondata), `"type":"TypeCancelExchange"`)
+	assert.Contains(string(jsondata), `"partial":true`)
+
+	// cancel the partially filled order
+	assert.NoError(sellerAcc.UpdateNonceTable(cs.Timestamp()+1, []uint64{1, 2}))
+	assert.NoError(sellerAcc.FillOrder(id, input, new(big.Int).SetUint64(unit.LDC)))
+	assert.Equal(unit.LDC*2, sellerAcc.Order(id).Remaining.Uint64())
+
+	ltx = &ld.Transaction{Tx: ld.TxData{
+		Type:      ld.TypeCancelExchange,
+		ChainID:   ctx.ChainConfig().ChainID,
+		Nonce:     1,
+		GasTip:    100,
+		GasFeeCap: ctx.Price,
+		From:      seller,
+		Data:      input.Bytes(),
+	}}
+	assert.NoError(ltx.SignWith(signer.Signer1))
+	assert.NoError(ltx.SyntacticVerify())
+	itx, err = NewTx(ltx)
+	require.NoError(t, err)
+	assert.NoError(itx.Apply(ctx, cs))
+	assert.Nil(sellerAcc.Order(id))
+	assert.Equal(unit.LDC*2, sellerAcc.BalanceOf(token).Uint64())
+
+	assert.NoError(cs.VerifyState())
+}
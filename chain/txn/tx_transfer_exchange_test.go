@@ -7,6 +7,7 @@ import (
 	"math/big"
 	"testing"
 
+	"github.com/fxamacker/cbor/v2"
 	"github.com/ldclabs/ldvm/ids"
 	"github.com/ldclabs/ldvm/ld"
 	"github.com/ldclabs/ldvm/signer"
@@ -345,6 +346,28 @@ func TestTxExchange(t *testing.T) {
 		"insufficient transferable $LDC balance, expected 1000000000, got 0")
 	cs.CheckoutAccounts()
 	to.Add(token, new(big.Int).SetUint64(unit.LDC))
+
+	// the seller's policy applies to the sold token
+	cs.CommitAccounts()
+	assert.NoError(to.UpdatePolicy(&ld.AccountPolicy{
+		Allowlist: []ids.Address{ids.GenesisAccount},
+		Delay:     3600,
+	}))
+	assert.ErrorContains(itx.Apply(ctx, cs),
+		"recipient "+from.ID().String()+" is not in the allowlist")
+	cs.CheckoutAccounts()
+	assert.Nil(to.LD().Policy)
+
+	cs.CommitAccounts()
+	assert.NoError(to.UpdatePolicy(&ld.AccountPolicy{
+		Window: 3600,
+		Limits: map[cbor.ByteString]*big.Int{token.AsKey(): new(big.Int).SetUint64(unit.LDC / 2)},
+		Delay:  3600,
+	}))
+	assert.ErrorContains(itx.Apply(ctx, cs),
+		"$LDC outflow exceeds the policy limit, expected <= 500000000, got 1000000000")
+	cs.CheckoutAccounts()
+
 	assert.NoError(itx.Apply(ctx, cs))
 
 	assert.Equal(ltx.Gas()*ctx.Price,
@@ -367,3 +390,136 @@ func TestTxExchange(t *testing.T) {
 
 	assert.NoError(cs.VerifyState())
 }
+
+func TestTxExchangePartial(t *testing.T) {
+	assert := assert.New(t)
+
+	token := ld.MustNewToken("$LDC")
+	ctx := NewMockChainContext()
+	cs := ctx.MockChainState()
+
+	from := cs.MustAccount(signer.Signer1.Key().Address())
+	from.LD().Nonce = 1
+	to := cs.MustAccount(signer.Signer2.Key().Address())
+
+	input := &ld.TxExchanger{
+		Nonce:   1,
+		Sell:    token,
+		Receive: ids.NativeToken,
+		Quota:   new(big.Int).SetUint64(unit.LDC * 3),
+		Minimum: new(big.Int).SetUint64(unit.LDC),
+		Price:   new(big.Int).SetUint64(1_000_000),
+		Expire:  cs.Timestamp() + 1,
+		Payee:   to.ID(),
+		Partial: true,
+	}
+	assert.NoError(input.SyntacticVerify())
+	id := ids.ID32FromData(input.Bytes())
+
+	ltx := &ld.Transaction{Tx: ld.TxData{
+		Type:      ld.TypeExchange,
+		ChainID:   ctx.ChainConfig().ChainID,
+		Nonce:     1,
+		GasTip:    100,
+		GasFeeCap: ctx.Price,
+		From:      from.ID(),
+		To:        to.ID().Ptr(),
+		Amount:    new(big.Int).SetUint64(1_000_000),
+		Data:      input.Bytes(),
+	}}
+
+	assert.NoError(ltx.SignWith(signer.Signer1))
+	assert.NoError(ltx.ExSignWith(signer.Signer2))
+	assert.NoError(ltx.SyntacticVerify())
+	itx, err := NewTx(ltx)
+	require.NoError(t, err)
+
+	from.Add(ids.NativeToken, new(big.Int).SetUint64(unit.LDC*2))
+	cs.CommitAccounts()
+	assert.ErrorContains(itx.Apply(ctx, cs),
+		"nonce 1 not exists at 1001")
+	cs.CheckoutAccounts()
+	assert.NoError(to.UpdateNonceTable(cs.Timestamp()+1, []uint64{1, 2}))
+	cs.CommitAccounts()
+	assert.ErrorContains(itx.Apply(ctx, cs),
+		"insufficient transferable $LDC balance, expected 1000000000, got 0")
+	cs.CheckoutAccounts()
+	to.Add(token, new(big.Int).SetUint64(unit.LDC*3))
+	assert.NoError(itx.Apply(ctx, cs))
+
+	assert.Equal(unit.LDC, from.BalanceOf(token).Uint64())
+	assert.Equal(unit.LDC*2, to.BalanceOf(token).Uint64())
+	assert.Equal(uint64(1_000_000), to.BalanceOfAll(ids.NativeToken).Uint64())
+	assert.Equal([]uint64{2}, to.LD().NonceTable[cs.Timestamp()+1])
+	assert.Equal(unit.LDC*2, to.Order(id).Remaining.Uint64())
+
+	// fill the remaining quota
+	ltx = &ld.Transaction{Tx: ld.TxData{
+		Type:      ld.TypeExchange,
+		ChainID:   ctx.ChainConfig().ChainID,
+		Nonce:     2,
+		GasTip:    100,
+		GasFeeCap: ctx.Price,
+		From:      from.ID(),
+		To:        to.ID().Ptr(),
+		Amount:    new(big.Int).SetUint64(3_000_000),
+		Data:      input.Bytes(),
+	}}
+	assert.NoError(ltx.SignWith(signer.Signer1))
+	assert.NoError(ltx.ExSignWith(signer.Signer2))
+	assert.NoError(ltx.SyntacticVerify())
+	itx, err = NewTx(ltx)
+	require.NoError(t, err)
+	cs.CommitAccounts()
+	assert.ErrorContains(itx.Apply(ctx, cs),
+		"insufficient order quota, expected 3000000000, got 2000000000")
+	cs.CheckoutAccounts()
+
+	ltx = &ld.Transaction{Tx: ld.TxData{
+		Type:      ld.TypeExchange,
+		ChainID:   ctx.ChainConfig().ChainID,
+		Nonce:     2,
+		GasTip:    100,
+		GasFeeCap: ctx.Price,
+		From:      from.ID(),
+		To:        to.ID().Ptr(),
+		Amount:    new(big.Int).SetUint64(2_000_000),
+		Data:      input.Bytes(),
+	}}
+	assert.NoError(ltx.SignWith(signer.Signer1))
+	assert.NoError(ltx.ExSignWith(signer.Signer2))
+	assert.NoError(ltx.SyntacticVerify())
+	itx, err = NewTx(ltx)
+	require.NoError(t, err)
+	assert.NoError(itx.Apply(ctx, cs))
+
+	assert.Equal(unit.LDC*3, from.BalanceOf(token).Uint64())
+	assert.Equal(uint64(0), to.BalanceOf(token).Uint64())
+	assert.Equal(uint64(3_000_000), to.BalanceOfAll(ids.NativeToken).Uint64())
+	assert.Equal([]uint64{2}, to.LD().NonceTable[cs.Timestamp()+1])
+	assert.Nil(to.Order(id))
+
+	// the filled order can't be reused
+	ltx = &ld.Transaction{Tx: ld.TxData{
+		Type:      ld.TypeExchange,
+		ChainID:   ctx.ChainConfig().ChainID,
+		Nonce:     3,
+		GasTip:    100,
+		GasFeeCap: ctx.Price,
+		From:      from.ID(),
+		To:        to.ID().Ptr(),
+		Amount:    new(big.Int).SetUint64(1_000_000),
+		Data:      input.Bytes(),
+	}}
+	assert.NoError(ltx.SignWith(signer.Signer1))
+	assert.NoError(ltx.ExSignWith(signer.Signer2))
+	assert.NoError(ltx.SyntacticVerify())
+	itx, err = NewTx(ltx)
+	require.NoError(t, err)
+	cs.CommitAccounts()
+	assert.ErrorContains(itx.Apply(ctx, cs),
+		"nonce 1 not exists at 1001")
+	cs.CheckoutAccounts()
+
+	assert.NoError(cs.VerifyState())
+}
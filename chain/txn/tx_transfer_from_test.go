@@ -0,0 +1,250 @@
+// (c) 2022-2022, LDC Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package txn
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/fxamacker/cbor/v2"
+	"github.com/ldclabs/ldvm/ids"
+	"github.com/ldclabs/ldvm/ld"
+	"github.com/ldclabs/ldvm/signer"
+	"github.com/ldclabs/ldvm/unit"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTxTransferFrom(t *testing.T) {
+	assert := assert.New(t)
+
+	// SyntacticVerify
+	tx := &TxTransferFrom{}
+	assert.ErrorContains(tx.SyntacticVerify(), "nil pointer")
+	_, err := tx.MarshalJSON()
+	require.NoError(t, err)
+
+	ctx := NewMockChainContext()
+	cs := ctx.MockChainState()
+	owner := signer.Signer1.Key().Address()
+	spender := signer.Signer2.Key().Address()
+	recipient := ids.GenesisAccount
+	token := ld.MustNewToken("$TEST")
+
+	ltx := &ld.Transaction{Tx: ld.TxData{
+		Type:      ld.TypeTransferFrom,
+		ChainID:   ctx.ChainConfig().ChainID,
+		Nonce:     0,
+		GasTip:    100,
+		GasFeeCap: ctx.Price,
+		From:      spender,
+	}}
+	assert.NoError(ltx.SignWith(signer.Signer2))
+	assert.NoError(ltx.SyntacticVerify())
+	_, err = NewTx(ltx)
+	assert.ErrorContains(err, "nil to as owner")
+
+	ltx = &ld.Transaction{Tx: ld.TxData{
+		Type:      ld.TypeTransferFrom,
+		ChainID:   ctx.ChainConfig().ChainID,
+		Nonce:     0,
+		GasTip:    100,
+		GasFeeCap: ctx.Price,
+		From:      spender,
+		To:        &owner,
+		Amount:    big.NewInt(1),
+	}}
+	assert.NoError(ltx.SignWith(signer.Signer2))
+	assert.NoError(ltx.SyntacticVerify())
+	_, err = NewTx(ltx)
+	assert.ErrorContains(err, "invalid amount, should be nil")
+
+	ltx = &ld.Transaction{Tx: ld.TxData{
+		Type:      ld.TypeTransferFrom,
+		ChainID:   ctx.ChainConfig().ChainID,
+		Nonce:     0,
+		GasTip:    100,
+		GasFeeCap: ctx.Price,
+		From:      spender,
+		To:        &owner,
+	}}
+	assert.NoError(ltx.SignWith(signer.Signer2))
+	assert.NoError(ltx.SyntacticVerify())
+	_, err = NewTx(ltx)
+	assert.ErrorContains(err, "invalid data")
+
+	ltx = &ld.Transaction{Tx: ld.TxData{
+		Type:      ld.TypeTransferFrom,
+		ChainID:   ctx.ChainConfig().ChainID,
+		Nonce:     0,
+		GasTip:    100,
+		GasFeeCap: ctx.Price,
+		From:      spender,
+		To:        &owner,
+		Data:      ld.MustMarshal(&ld.TxTransfer{}),
+	}}
+	assert.NoError(ltx.SignWith(signer.Signer2))
+	assert.NoError(ltx.SyntacticVerify())
+	_, err = NewTx(ltx)
+	assert.ErrorContains(err, "nil owner")
+
+	ltx = &ld.Transaction{Tx: ld.TxData{
+		Type:      ld.TypeTransferFrom,
+		ChainID:   ctx.ChainConfig().ChainID,
+		Nonce:     0,
+		GasTip:    100,
+		GasFeeCap: ctx.Price,
+		From:      spender,
+		To:        &owner,
+		Data:      ld.MustMarshal(&ld.TxTransfer{From: &spender}),
+	}}
+	assert.NoError(ltx.SignWith(signer.Signer2))
+	assert.NoError(ltx.SyntacticVerify())
+	_, err = NewTx(ltx)
+	assert.ErrorContains(err, "invalid owner, expected 0x44171C37Ff5D7B7bb8Dcad5C81f16284A229E641, got 0x8db97c7cECe249C2b98bdc0226cc4C2A57bF52fc")
+
+	ltx = &ld.Transaction{Tx: ld.TxData{
+		Type:      ld.TypeTransferFrom,
+		ChainID:   ctx.ChainConfig().ChainID,
+		Nonce:     0,
+		GasTip:    100,
+		GasFeeCap: ctx.Price,
+		From:      spender,
+		To:        &owner,
+		Data:      ld.MustMarshal(&ld.TxTransfer{From: &owner}),
+	}}
+	assert.NoError(ltx.SignWith(signer.Signer2))
+	assert.NoError(ltx.SyntacticVerify())
+	_, err = NewTx(ltx)
+	assert.ErrorContains(err, "nil recipient")
+
+	ltx = &ld.Transaction{Tx: ld.TxData{
+		Type:      ld.TypeTransferFrom,
+		ChainID:   ctx.ChainConfig().ChainID,
+		Nonce:     0,
+		GasTip:    100,
+		GasFeeCap: ctx.Price,
+		From:      spender,
+		To:        &owner,
+		Data:      ld.MustMarshal(&ld.TxTransfer{From: &owner, To: &owner}),
+	}}
+	assert.NoError(ltx.SignWith(signer.Signer2))
+	assert.NoError(ltx.SyntacticVerify())
+	_, err = NewTx(ltx)
+	assert.ErrorContains(err, "invalid recipient, should not be owner")
+
+	ltx = &ld.Transaction{Tx: ld.TxData{
+		Type:      ld.TypeTransferFrom,
+		ChainID:   ctx.ChainConfig().ChainID,
+		Nonce:     0,
+		GasTip:    100,
+		GasFeeCap: ctx.Price,
+		From:      spender,
+		To:        &owner,
+		Token:     token.Ptr(),
+		Data:      ld.MustMarshal(&ld.TxTransfer{From: &owner, To: &recipient}),
+	}}
+	assert.NoError(ltx.SignWith(signer.Signer2))
+	assert.NoError(ltx.SyntacticVerify())
+	_, err = NewTx(ltx)
+	assert.ErrorContains(err, "invalid token, expected NativeLDC, got $TEST")
+
+	ltx = &ld.Transaction{Tx: ld.TxData{
+		Type:      ld.TypeTransferFrom,
+		ChainID:   ctx.ChainConfig().ChainID,
+		Nonce:     0,
+		GasTip:    100,
+		GasFeeCap: ctx.Price,
+		From:      spender,
+		To:        &owner,
+		Token:     token.Ptr(),
+		Data:      ld.MustMarshal(&ld.TxTransfer{From: &owner, To: &recipient, Token: token.Ptr()}),
+	}}
+	assert.NoError(ltx.SignWith(signer.Signer2))
+	assert.NoError(ltx.SyntacticVerify())
+	_, err = NewTx(ltx)
+	assert.ErrorContains(err, "invalid amount, expected >= 1")
+
+	input := &ld.TxTransfer{
+		From:   &owner,
+		To:     &recipient,
+		Token:  token.Ptr(),
+		Amount: new(big.Int).SetUint64(unit.LDC * 6),
+	}
+	ltx = &ld.Transaction{Tx: ld.TxData{
+		Type:      ld.TypeTransferFrom,
+		ChainID:   ctx.ChainConfig().ChainID,
+		Nonce:     0,
+		GasTip:    100,
+		GasFeeCap: ctx.Price,
+		From:      spender,
+		To:        &owner,
+		Token:     token.Ptr(),
+		Data:      ld.MustMarshal(input),
+	}}
+	assert.NoError(ltx.SignWith(signer.Signer2))
+	assert.NoError(ltx.SyntacticVerify())
+	itx, err := NewTx(ltx)
+	require.NoError(t, err)
+
+	cs.CommitAccounts()
+	assert.ErrorContains(itx.Apply(ctx, cs),
+		"insufficient NativeLDC balance, expected 1317800, got 0")
+	cs.CheckoutAccounts()
+
+	spenderAcc := cs.MustAccount(spender)
+	spenderAcc.Add(ids.NativeToken, new(big.Int).SetUint64(unit.LDC))
+	cs.CommitAccounts()
+	assert.ErrorContains(itx.Apply(ctx, cs),
+		"0x44171C37Ff5D7B7bb8Dcad5C81f16284A229E641 has no allowance for $TEST")
+	cs.CheckoutAccounts()
+
+	ownerAcc := cs.MustAccount(owner)
+	require.NoError(t, cs.LoadLedger(ownerAcc))
+	assert.NoError(ownerAcc.ApproveAllowance(spender, token, new(big.Int).SetUint64(unit.LDC*10), 0))
+	cs.CommitAccounts()
+	assert.ErrorContains(itx.Apply(ctx, cs),
+		"insufficient transferable $TEST balance, expected 6000000000, got 0")
+	cs.CheckoutAccounts()
+
+	ownerAcc.Add(token, new(big.Int).SetUint64(unit.LDC*10))
+
+	// the owner's policy applies to the pulled token
+	cs.CommitAccounts()
+	assert.NoError(ownerAcc.UpdatePolicy(&ld.AccountPolicy{
+		Window: 3600,
+		Limits: map[cbor.ByteString]*big.Int{token.AsKey(): new(big.Int).SetUint64(unit.LDC * 5)},
+		Delay:  3600,
+	}))
+	assert.ErrorContains(itx.Apply(ctx, cs),
+		"$TEST outflow exceeds the policy limit, expected <= 5000000000, got 6000000000")
+	cs.CheckoutAccounts()
+
+	assert.NoError(ownerAcc.UpdatePolicy(&ld.AccountPolicy{
+		Window: 3600,
+		Limits: map[cbor.ByteString]*big.Int{token.AsKey(): new(big.Int).SetUint64(unit.LDC * 6)},
+		Delay:  3600,
+	}))
+	assert.NoError(itx.Apply(ctx, cs))
+	assert.Equal(unit.LDC*6, ownerAcc.LD().Policy.Outflows[0].Amount.Uint64())
+
+	spenderGas := ltx.Gas()
+	assert.Equal(spenderGas*ctx.Price,
+		itx.(*TxTransferFrom).ldc.Balance().Uint64())
+	assert.Equal(spenderGas*100,
+		itx.(*TxTransferFrom).miner.Balance().Uint64())
+	assert.Equal(unit.LDC-spenderGas*(ctx.Price+100),
+		spenderAcc.BalanceOfAll(ids.NativeToken).Uint64())
+	assert.Equal(uint64(1), spenderAcc.Nonce())
+	assert.Equal(unit.LDC*4, ownerAcc.BalanceOf(token).Uint64())
+	assert.Equal(unit.LDC*6, cs.MustAccount(recipient).BalanceOf(token).Uint64())
+	assert.Equal(unit.LDC*4, ownerAcc.Allowance(spender, token).Uint64())
+
+	jsondata, err := itx.MarshalJSON()
+	require.NoError(t, err)
+	assert.Contains(string(jsondata), `"type":"TypeTransferFrom"`)
+	assert.Contains(string(jsondata), `"amount":6000000000`)
+
+	assert.NoError(cs.VerifyState())
+}
@@ -0,0 +1,56 @@
+// (c) 2022-2022, LDC Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package txn
+
+import (
+	"github.com/ldclabs/ldvm/util/erring"
+)
+
+type TxClaimHTLC struct {
+	TxBase
+}
+
+func (tx *TxClaimHTLC) SyntacticVerify() error {
+	var err error
+	errp := erring.ErrPrefix("txn.TxClaimHTLC.SyntacticVerify: ")
+
+	if err = tx.TxBase.SyntacticVerify(); err != nil {
+		return errp.ErrorIf(err)
+	}
+
+	switch {
+	case tx.ld.Tx.To == nil:
+		return errp.Errorf("nil to as HTLC sender")
+
+	case tx.ld.Tx.Amount != nil:
+		return errp.Errorf("invalid amount, should be nil")
+
+	case len(tx.ld.Tx.Data) == 0:
+		return errp.Errorf("invalid data as preimage")
+	}
+	return nil
+}
+
+func (tx *TxClaimHTLC) Apply(ctx ChainContext, cs ChainState) error {
+	var err error
+	errp := erring.ErrPrefix("txn.TxClaimHTLC.Apply: ")
+
+	if err = tx.TxBase.verify(ctx, cs); err != nil {
+		return errp.ErrorIf(err)
+	}
+
+	if err = cs.LoadLedger(tx.to); err != nil {
+		return errp.ErrorIf(err)
+	}
+
+	entry, err := tx.to.ClaimHTLC(tx.ld.Tx.From, tx.ld.Tx.Data)
+	if err != nil {
+		return errp.ErrorIf(err)
+	}
+
+	if err = tx.from.Add(entry.Token, entry.Amount); err != nil {
+		return errp.ErrorIf(err)
+	}
+	return errp.ErrorIf(tx.TxBase.accept(ctx, cs))
+}
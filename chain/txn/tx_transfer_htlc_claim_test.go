@@ -0,0 +1,131 @@
+// (c) 2022-2022, LDC Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package txn
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ldclabs/ldvm/ids"
+	"github.com/ldclabs/ldvm/ld"
+	"github.com/ldclabs/ldvm/signer"
+	"github.com/ldclabs/ldvm/unit"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTxClaimHTLC(t *testing.T) {
+	assert := assert.New(t)
+
+	// SyntacticVerify
+	tx := &TxClaimHTLC{}
+	assert.ErrorContains(tx.SyntacticVerify(), "nil pointer")
+	_, err := tx.MarshalJSON()
+	require.NoError(t, err)
+
+	ctx := NewMockChainContext()
+	cs := ctx.MockChainState()
+	locker := signer.Signer1.Key().Address()
+	sender := signer.Signer2.Key().Address()
+	token := ld.MustNewToken("$TEST")
+	preimage := []byte("secret")
+
+	ltx := &ld.Transaction{Tx: ld.TxData{
+		Type:      ld.TypeClaimHTLC,
+		ChainID:   ctx.ChainConfig().ChainID,
+		Nonce:     0,
+		GasTip:    100,
+		GasFeeCap: ctx.Price,
+		From:      sender,
+	}}
+	assert.NoError(ltx.SignWith(signer.Signer2))
+	assert.NoError(ltx.SyntacticVerify())
+	_, err = NewTx(ltx)
+	assert.ErrorContains(err, "nil to as HTLC sender")
+
+	ltx = &ld.Transaction{Tx: ld.TxData{
+		Type:      ld.TypeClaimHTLC,
+		ChainID:   ctx.ChainConfig().ChainID,
+		Nonce:     0,
+		GasTip:    100,
+		GasFeeCap: ctx.Price,
+		From:      sender,
+		To:        &locker,
+		Amount:    big.NewInt(1),
+	}}
+	assert.NoError(ltx.SignWith(signer.Signer2))
+	assert.NoError(ltx.SyntacticVerify())
+	_, err = NewTx(ltx)
+	assert.ErrorContains(err, "invalid amount, should be nil")
+
+	ltx = &ld.Transaction{Tx: ld.TxData{
+		Type:      ld.TypeClaimHTLC,
+		ChainID:   ctx.ChainConfig().ChainID,
+		Nonce:     0,
+		GasTip:    100,
+		GasFeeCap: ctx.Price,
+		From:      sender,
+		To:        &locker,
+	}}
+	assert.NoError(ltx.SignWith(signer.Signer2))
+	assert.NoError(ltx.SyntacticVerify())
+	_, err = NewTx(ltx)
+	assert.ErrorContains(err, "invalid data as preimage")
+
+	ltx = &ld.Transaction{Tx: ld.TxData{
+		Type:      ld.TypeClaimHTLC,
+		ChainID:   ctx.ChainConfig().ChainID,
+		Nonce:     0,
+		GasTip:    100,
+		GasFeeCap: ctx.Price,
+		From:      sender,
+		To:        &locker,
+		Data:      preimage,
+	}}
+	assert.NoError(ltx.SignWith(signer.Signer2))
+	assert.NoError(ltx.SyntacticVerify())
+	ltx.Timestamp = cs.Timestamp()
+	itx, err := NewTx(ltx)
+	require.NoError(t, err)
+
+	cs.CommitAccounts()
+	assert.ErrorContains(itx.Apply(ctx, cs),
+		"insufficient NativeLDC balance, expected 892100, got 0")
+	cs.CheckoutAccounts()
+
+	senderAcc := cs.MustAccount(sender)
+	senderAcc.Add(ids.NativeToken, new(big.Int).SetUint64(unit.LDC))
+	cs.CommitAccounts()
+	assert.ErrorContains(itx.Apply(ctx, cs), "HTLC not found")
+	cs.CheckoutAccounts()
+
+	lockerAcc := cs.MustAccount(locker)
+	require.NoError(t, cs.LoadLedger(lockerAcc))
+	lockerAcc.Add(token, new(big.Int).SetUint64(unit.LDC*10))
+	assert.NoError(lockerAcc.CreateHTLC(token, sender, &ld.TxHTLC{
+		HashAlgo: ld.HashSHA256,
+		HashLock: ld.HashSHA256.Sum(preimage),
+		Amount:   new(big.Int).SetUint64(unit.LDC * 10),
+		Expire:   cs.Timestamp() + 1000,
+	}))
+	assert.Equal(uint64(0), lockerAcc.BalanceOf(token).Uint64())
+	assert.NoError(itx.Apply(ctx, cs))
+
+	senderGas := ltx.Gas()
+	assert.Equal(senderGas*ctx.Price,
+		itx.(*TxClaimHTLC).ldc.Balance().Uint64())
+	assert.Equal(senderGas*100,
+		itx.(*TxClaimHTLC).miner.Balance().Uint64())
+	assert.Equal(unit.LDC-senderGas*(ctx.Price+100),
+		senderAcc.BalanceOfAll(ids.NativeToken).Uint64())
+	assert.Equal(unit.LDC*10, senderAcc.BalanceOf(token).Uint64())
+	assert.Equal(uint64(1), senderAcc.Nonce())
+	assert.Equal(0, len(lockerAcc.Ledger().HTLC))
+
+	jsondata, err := itx.MarshalJSON()
+	require.NoError(t, err)
+	assert.Contains(string(jsondata), `"type":"TypeClaimHTLC"`)
+
+	assert.NoError(cs.VerifyState())
+}
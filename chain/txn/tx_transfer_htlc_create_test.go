@@ -0,0 +1,172 @@
+// (c) 2022-2022, LDC Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package txn
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/fxamacker/cbor/v2"
+	"github.com/ldclabs/ldvm/ids"
+	"github.com/ldclabs/ldvm/ld"
+	"github.com/ldclabs/ldvm/signer"
+	"github.com/ldclabs/ldvm/unit"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTxCreateHTLC(t *testing.T) {
+	assert := assert.New(t)
+
+	// SyntacticVerify
+	tx := &TxCreateHTLC{}
+	assert.ErrorContains(tx.SyntacticVerify(), "nil pointer")
+	_, err := tx.MarshalJSON()
+	require.NoError(t, err)
+
+	ctx := NewMockChainContext()
+	cs := ctx.MockChainState()
+	sender := signer.Signer1.Key().Address()
+	recipient := signer.Signer2.Key().Address()
+
+	ltx := &ld.Transaction{Tx: ld.TxData{
+		Type:      ld.TypeCreateHTLC,
+		ChainID:   ctx.ChainConfig().ChainID,
+		Nonce:     0,
+		GasTip:    100,
+		GasFeeCap: ctx.Price,
+		From:      sender,
+	}}
+	assert.NoError(ltx.SignWith(signer.Signer1))
+	assert.NoError(ltx.SyntacticVerify())
+	_, err = NewTx(ltx)
+	assert.ErrorContains(err, "nil to as recipient")
+
+	ltx = &ld.Transaction{Tx: ld.TxData{
+		Type:      ld.TypeCreateHTLC,
+		ChainID:   ctx.ChainConfig().ChainID,
+		Nonce:     0,
+		GasTip:    100,
+		GasFeeCap: ctx.Price,
+		From:      sender,
+		To:        &recipient,
+		Amount:    big.NewInt(1),
+	}}
+	assert.NoError(ltx.SignWith(signer.Signer1))
+	assert.NoError(ltx.SyntacticVerify())
+	_, err = NewTx(ltx)
+	assert.ErrorContains(err, "invalid amount, should be nil")
+
+	ltx = &ld.Transaction{Tx: ld.TxData{
+		Type:      ld.TypeCreateHTLC,
+		ChainID:   ctx.ChainConfig().ChainID,
+		Nonce:     0,
+		GasTip:    100,
+		GasFeeCap: ctx.Price,
+		From:      sender,
+		To:        &recipient,
+	}}
+	assert.NoError(ltx.SignWith(signer.Signer1))
+	assert.NoError(ltx.SyntacticVerify())
+	_, err = NewTx(ltx)
+	assert.ErrorContains(err, "invalid data")
+
+	input := &ld.TxHTLC{HashLock: ld.HashSHA3.Sum([]byte("secret"))}
+	ltx = &ld.Transaction{Tx: ld.TxData{
+		Type:      ld.TypeCreateHTLC,
+		ChainID:   ctx.ChainConfig().ChainID,
+		Nonce:     0,
+		GasTip:    100,
+		GasFeeCap: ctx.Price,
+		From:      sender,
+		To:        &recipient,
+		Data:      ld.MustMarshal(input),
+	}}
+	assert.NoError(ltx.SignWith(signer.Signer1))
+	assert.NoError(ltx.SyntacticVerify())
+	_, err = NewTx(ltx)
+	assert.ErrorContains(err, "invalid amount")
+
+	input = &ld.TxHTLC{
+		HashLock: ld.HashSHA3.Sum([]byte("secret")),
+		Amount:   new(big.Int).SetUint64(unit.LDC * 10),
+		Expire:   cs.Timestamp(),
+	}
+	ltx = &ld.Transaction{Tx: ld.TxData{
+		Type:      ld.TypeCreateHTLC,
+		ChainID:   ctx.ChainConfig().ChainID,
+		Nonce:     0,
+		GasTip:    100,
+		GasFeeCap: ctx.Price,
+		From:      sender,
+		To:        &recipient,
+		Data:      ld.MustMarshal(input),
+	}}
+	assert.NoError(ltx.SignWith(signer.Signer1))
+	assert.NoError(ltx.SyntacticVerify())
+	ltx.Timestamp = cs.Timestamp()
+	_, err = NewTx(ltx)
+	assert.ErrorContains(err, "invalid expire, expected > 1000, got 1000")
+
+	input = &ld.TxHTLC{
+		HashLock: ld.HashSHA3.Sum([]byte("secret")),
+		Amount:   new(big.Int).SetUint64(unit.LDC * 10),
+		Expire:   cs.Timestamp() + 1000,
+	}
+	ltx = &ld.Transaction{Tx: ld.TxData{
+		Type:      ld.TypeCreateHTLC,
+		ChainID:   ctx.ChainConfig().ChainID,
+		Nonce:     0,
+		GasTip:    100,
+		GasFeeCap: ctx.Price,
+		From:      sender,
+		To:        &recipient,
+		Data:      ld.MustMarshal(input),
+	}}
+	assert.NoError(ltx.SignWith(signer.Signer1))
+	assert.NoError(ltx.SyntacticVerify())
+	ltx.Timestamp = cs.Timestamp()
+	itx, err := NewTx(ltx)
+	require.NoError(t, err)
+
+	cs.CommitAccounts()
+	assert.ErrorContains(itx.Apply(ctx, cs),
+		"insufficient NativeLDC balance, expected 1514700, got 0")
+	cs.CheckoutAccounts()
+
+	senderAcc := cs.MustAccount(sender)
+	senderAcc.Add(ids.NativeToken, new(big.Int).SetUint64(unit.LDC*5))
+	cs.CommitAccounts()
+	assert.ErrorContains(itx.Apply(ctx, cs),
+		"insufficient transferable NativeLDC balance, expected 10000000000")
+	cs.CheckoutAccounts()
+
+	senderAcc.Add(ids.NativeToken, new(big.Int).SetUint64(unit.LDC*10))
+	assert.NoError(itx.Apply(ctx, cs))
+
+	senderGas := ltx.Gas()
+	assert.Equal(senderGas*ctx.Price,
+		itx.(*TxCreateHTLC).ldc.Balance().Uint64())
+	assert.Equal(senderGas*100,
+		itx.(*TxCreateHTLC).miner.Balance().Uint64())
+	assert.Equal(unit.LDC*5-senderGas*(ctx.Price+100),
+		senderAcc.BalanceOfAll(ids.NativeToken).Uint64())
+	assert.Equal(uint64(1), senderAcc.Nonce())
+
+	entry := senderAcc.Ledger().HTLC[cbor.ByteString(input.HashLock[:])]
+	require.NotNil(t, entry)
+	assert.Equal(recipient, entry.Recipient)
+	assert.Equal(ids.NativeToken, entry.Token)
+	assert.Equal(unit.LDC*10, entry.Amount.Uint64())
+	assert.Equal(ld.HashSHA3, entry.HashAlgo)
+	assert.Equal(cs.Timestamp()+1000, entry.Expire)
+	assert.Equal(uint64(0), cs.MustAccount(recipient).Balance().Uint64())
+
+	jsondata, err := itx.MarshalJSON()
+	require.NoError(t, err)
+	assert.Contains(string(jsondata), `"type":"TypeCreateHTLC"`)
+	assert.Contains(string(jsondata), `"data":{"hashAlgo":0,"hashLock":"`)
+
+	assert.NoError(cs.VerifyState())
+}
@@ -0,0 +1,54 @@
+// (c) 2022-2022, LDC Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package txn
+
+import (
+	"github.com/ldclabs/ldvm/ids"
+	"github.com/ldclabs/ldvm/util/erring"
+)
+
+type TxRefundHTLC struct {
+	TxBase
+	hashLock ids.ID32
+}
+
+func (tx *TxRefundHTLC) SyntacticVerify() error {
+	var err error
+	errp := erring.ErrPrefix("txn.TxRefundHTLC.SyntacticVerify: ")
+
+	if err = tx.TxBase.SyntacticVerify(); err != nil {
+		return errp.ErrorIf(err)
+	}
+
+	switch {
+	case tx.ld.Tx.To != nil:
+		return errp.Errorf("invalid to, should be nil")
+
+	case tx.ld.Tx.Amount != nil:
+		return errp.Errorf("invalid amount, should be nil")
+	}
+
+	if tx.hashLock, err = ids.ID32FromBytes(tx.ld.Tx.Data); err != nil {
+		return errp.Errorf("invalid data as hashLock, %v", err)
+	}
+	return nil
+}
+
+func (tx *TxRefundHTLC) Apply(ctx ChainContext, cs ChainState) error {
+	var err error
+	errp := erring.ErrPrefix("txn.TxRefundHTLC.Apply: ")
+
+	if err = tx.TxBase.verify(ctx, cs); err != nil {
+		return errp.ErrorIf(err)
+	}
+
+	if err = cs.LoadLedger(tx.from); err != nil {
+		return errp.ErrorIf(err)
+	}
+
+	if _, err = tx.from.RefundHTLC(tx.hashLock); err != nil {
+		return errp.ErrorIf(err)
+	}
+	return errp.ErrorIf(tx.TxBase.accept(ctx, cs))
+}
@@ -0,0 +1,119 @@
+// (c) 2022-2022, LDC Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package txn
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ldclabs/ldvm/ids"
+	"github.com/ldclabs/ldvm/ld"
+	"github.com/ldclabs/ldvm/signer"
+	"github.com/ldclabs/ldvm/unit"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTxRefundHTLC(t *testing.T) {
+	assert := assert.New(t)
+
+	// SyntacticVerify
+	tx := &TxRefundHTLC{}
+	assert.ErrorContains(tx.SyntacticVerify(), "nil pointer")
+	_, err := tx.MarshalJSON()
+	require.NoError(t, err)
+
+	ctx := NewMockChainContext()
+	cs := ctx.MockChainState()
+	sender := signer.Signer1.Key().Address()
+	recipient := signer.Signer2.Key().Address()
+	hashLock := ld.HashSHA3.Sum([]byte("secret"))
+
+	ltx := &ld.Transaction{Tx: ld.TxData{
+		Type:      ld.TypeRefundHTLC,
+		ChainID:   ctx.ChainConfig().ChainID,
+		Nonce:     0,
+		GasTip:    100,
+		GasFeeCap: ctx.Price,
+		From:      sender,
+		To:        &recipient,
+	}}
+	assert.NoError(ltx.SignWith(signer.Signer1))
+	assert.NoError(ltx.SyntacticVerify())
+	_, err = NewTx(ltx)
+	assert.ErrorContains(err, "invalid to, should be nil")
+
+	ltx = &ld.Transaction{Tx: ld.TxData{
+		Type:      ld.TypeRefundHTLC,
+		ChainID:   ctx.ChainConfig().ChainID,
+		Nonce:     0,
+		GasTip:    100,
+		GasFeeCap: ctx.Price,
+		From:      sender,
+		Data:      []byte("secret"),
+	}}
+	assert.NoError(ltx.SignWith(signer.Signer1))
+	assert.NoError(ltx.SyntacticVerify())
+	_, err = NewTx(ltx)
+	assert.ErrorContains(err, "invalid data as hashLock")
+
+	ltx = &ld.Transaction{Tx: ld.TxData{
+		Type:      ld.TypeRefundHTLC,
+		ChainID:   ctx.ChainConfig().ChainID,
+		Nonce:     0,
+		GasTip:    100,
+		GasFeeCap: ctx.Price,
+		From:      sender,
+		Data:      hashLock[:],
+	}}
+	assert.NoError(ltx.SignWith(signer.Signer1))
+	assert.NoError(ltx.SyntacticVerify())
+	ltx.Timestamp = cs.Timestamp()
+	itx, err := NewTx(ltx)
+	require.NoError(t, err)
+
+	cs.CommitAccounts()
+	assert.ErrorContains(itx.Apply(ctx, cs),
+		"insufficient NativeLDC balance, expected 908600, got 0")
+	cs.CheckoutAccounts()
+
+	senderAcc := cs.MustAccount(sender)
+	senderAcc.Add(ids.NativeToken, new(big.Int).SetUint64(unit.LDC*11))
+	cs.CommitAccounts()
+	assert.ErrorContains(itx.Apply(ctx, cs),
+		"HTLC "+hashLock.String()+" not found")
+	cs.CheckoutAccounts()
+
+	require.NoError(t, cs.LoadLedger(senderAcc))
+	assert.NoError(senderAcc.CreateHTLC(ids.NativeToken, recipient, &ld.TxHTLC{
+		HashLock: hashLock,
+		Amount:   new(big.Int).SetUint64(unit.LDC * 10),
+		Expire:   cs.Timestamp() + 1000,
+	}))
+	assert.Equal(unit.LDC, senderAcc.BalanceOfAll(ids.NativeToken).Uint64())
+	cs.CommitAccounts()
+	assert.ErrorContains(itx.Apply(ctx, cs),
+		"HTLC not expired, expected > 2000, got 1000")
+	cs.CheckoutAccounts()
+
+	senderAcc.Init(ctx.cfg.FeeConfig.NonTransferableBalance, big.NewInt(0),
+		cs.Height(), cs.Timestamp()+1001)
+	assert.NoError(itx.Apply(ctx, cs))
+
+	senderGas := ltx.Gas()
+	assert.Equal(senderGas*ctx.Price,
+		itx.(*TxRefundHTLC).ldc.Balance().Uint64())
+	assert.Equal(senderGas*100,
+		itx.(*TxRefundHTLC).miner.Balance().Uint64())
+	assert.Equal(unit.LDC*11-senderGas*(ctx.Price+100),
+		senderAcc.BalanceOfAll(ids.NativeToken).Uint64())
+	assert.Equal(uint64(1), senderAcc.Nonce())
+	assert.Equal(0, len(senderAcc.Ledger().HTLC))
+
+	jsondata, err := itx.MarshalJSON()
+	require.NoError(t, err)
+	assert.Contains(string(jsondata), `"type":"TypeRefundHTLC"`)
+
+	assert.NoError(cs.VerifyState())
+}
@@ -74,28 +74,64 @@ func (tx *TxTransferMultiple) Apply(ctx ChainContext, cs ChainState) error {
 		return errp.ErrorIf(err)
 	}
 
-	totalAmount := new(big.Int)
+	type key struct {
+		to    ids.Address
+		token ids.TokenSymbol
+	}
+	set := make(map[key]struct{}, len(tx.input))
+	tokens := make([]ids.TokenSymbol, 0, 1)
+	totalAmounts := make(map[ids.TokenSymbol]*big.Int, 1)
 	recipients := make(map[ids.Address]*acct.Account, len(tx.input))
-	for _, output := range tx.input {
-		totalAmount = totalAmount.Add(totalAmount, output.Amount)
+	for i, output := range tx.input {
+		token := tx.outputToken(output)
+		k := key{to: output.To, token: token}
+		if _, ok := set[k]; ok {
+			return errp.Errorf("duplicate to address %s with token %s at %d",
+				output.To.String(), token.GoString(), i)
+		}
+		set[k] = struct{}{}
+
+		total := totalAmounts[token]
+		if total == nil {
+			total = new(big.Int)
+			totalAmounts[token] = total
+			tokens = append(tokens, token)
+		}
+		total.Add(total, output.Amount)
+
 		if recipients[output.To], err = cs.LoadAccount(output.To); err != nil {
 			return errp.ErrorIf(err)
 		}
+		if err = tx.from.CheckPolicy(output.To.Ptr(), token, output.Amount); err != nil {
+			return errp.ErrorIf(err)
+		}
 	}
 
 	if err = tx.TxBase.accept(ctx, cs); err != nil {
 		return errp.ErrorIf(err)
 	}
 
-	if err = tx.from.Sub(tx.token, totalAmount); err != nil {
-		return err
+	for _, token := range tokens {
+		if err = tx.from.SpendByPolicy(token, totalAmounts[token]); err != nil {
+			return errp.ErrorIf(err)
+		}
+		if err = tx.from.Sub(token, totalAmounts[token]); err != nil {
+			return errp.ErrorIf(err)
+		}
 	}
 
 	for _, output := range tx.input {
-		if err = recipients[output.To].Add(tx.token, output.Amount); err != nil {
-			return err
+		if err = recipients[output.To].Add(tx.outputToken(output), output.Amount); err != nil {
+			return errp.ErrorIf(err)
 		}
 	}
 
 	return nil
 }
+
+func (tx *TxTransferMultiple) outputToken(output ld.SendOutput) ids.TokenSymbol {
+	if output.Token != nil {
+		return *output.Token
+	}
+	return tx.token
+}
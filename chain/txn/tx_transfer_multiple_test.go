@@ -8,6 +8,7 @@ import (
 	"math/big"
 	"testing"
 
+	"github.com/fxamacker/cbor/v2"
 	"github.com/ldclabs/ldvm/ids"
 	"github.com/ldclabs/ldvm/ld"
 	"github.com/ldclabs/ldvm/signer"
@@ -30,7 +31,7 @@ func TestTxTransferMultiple(t *testing.T) {
 
 	sender := cs.MustAccount(signer.Signer1.Key().Address())
 	assert.NoError(sender.UpdateKeepers(ld.Uint16Ptr(2),
-		&signer.Keys{signer.Signer1.Key(), signer.Signer3.Key()}, nil, nil))
+		&signer.Keys{signer.Signer1.Key(), signer.Signer3.Key()}, nil, nil, nil))
 
 	recipient := cs.MustAccount(signer.Signer2.Key().Address())
 
@@ -420,6 +421,100 @@ func TestTxTransferMultiple(t *testing.T) {
 	assert.NoError(cs.VerifyState())
 }
 
+func TestTxTransferMultipleTokens(t *testing.T) {
+	assert := assert.New(t)
+
+	ctx := NewMockChainContext()
+	cs := ctx.MockChainState()
+	sender := cs.MustAccount(signer.Signer1.Key().Address())
+	recipient := cs.MustAccount(signer.Signer2.Key().Address())
+	recipient2 := cs.MustAccount(signer.Signer3.Key().Address())
+	token := ld.MustNewToken("$LDC")
+
+	ltx := &ld.Transaction{Tx: ld.TxData{
+		Type:      ld.TypeTransferMultiple,
+		ChainID:   ctx.ChainConfig().ChainID,
+		Nonce:     0,
+		GasTip:    100,
+		GasFeeCap: ctx.Price,
+		From:      sender.ID(),
+		Token:     token.Ptr(),
+		Data: ld.MustMarshal(ld.SendOutputs{
+			{To: recipient.ID(), Amount: new(big.Int).SetUint64(unit.LDC)},
+			{To: recipient.ID(), Amount: new(big.Int).SetUint64(unit.LDC), Token: token.Ptr()},
+		}),
+	}}
+	assert.NoError(ltx.SignWith(signer.Signer1))
+	assert.NoError(ltx.SyntacticVerify())
+	itx, err := NewTx(ltx)
+	require.NoError(t, err)
+
+	sender.Add(ids.NativeToken, new(big.Int).SetUint64(unit.LDC*10))
+	sender.Add(token, new(big.Int).SetUint64(unit.LDC*10))
+	cs.CommitAccounts()
+	assert.ErrorContains(itx.Apply(ctx, cs),
+		"duplicate to address 0x44171C37Ff5D7B7bb8Dcad5C81f16284A229E641 with token $LDC at 1")
+	cs.CheckoutAccounts()
+
+	ltx = &ld.Transaction{Tx: ld.TxData{
+		Type:      ld.TypeTransferMultiple,
+		ChainID:   ctx.ChainConfig().ChainID,
+		Nonce:     0,
+		GasTip:    100,
+		GasFeeCap: ctx.Price,
+		From:      sender.ID(),
+		Data: ld.MustMarshal(ld.SendOutputs{
+			{To: recipient.ID(), Amount: new(big.Int).SetUint64(unit.LDC)},
+			{To: recipient.ID(), Amount: new(big.Int).SetUint64(unit.LDC * 2), Token: token.Ptr()},
+			{To: recipient2.ID(), Amount: new(big.Int).SetUint64(unit.LDC * 3), Token: token.Ptr()},
+		}),
+	}}
+	assert.NoError(ltx.SignWith(signer.Signer1))
+	assert.NoError(ltx.SyntacticVerify())
+	itx, err = NewTx(ltx)
+	require.NoError(t, err)
+
+	// the gas is scaled by the entry count
+	ltx2 := &ld.Transaction{Tx: ltx.Tx}
+	ltx2.Tx.Type = ld.TypeTransfer
+	ltx2.Tx.To = recipient.ID().Ptr()
+	ltx2.Tx.Data = nil
+	assert.NoError(ltx2.SignWith(signer.Signer1))
+	assert.NoError(ltx2.SyntacticVerify())
+	assert.True(ltx.Gas() > ld.TypeTransferMultiple.Gas()*3)
+	assert.True(ltx.Gas() < ltx2.Gas()*3)
+
+	sender.Sub(token, new(big.Int).SetUint64(unit.LDC*6))
+	cs.CommitAccounts()
+	assert.ErrorContains(itx.Apply(ctx, cs),
+		"insufficient transferable $LDC balance, expected 5000000000, got 4000000000")
+	cs.CheckoutAccounts()
+
+	sender.Add(token, new(big.Int).SetUint64(unit.LDC))
+	assert.NoError(itx.Apply(ctx, cs))
+
+	senderGas := ltx.Gas()
+	assert.Equal(senderGas*ctx.Price,
+		itx.(*TxTransferMultiple).ldc.Balance().Uint64())
+	assert.Equal(senderGas*100,
+		itx.(*TxTransferMultiple).miner.Balance().Uint64())
+	assert.Equal(unit.LDC*9-senderGas*(ctx.Price+100),
+		sender.BalanceOfAll(ids.NativeToken).Uint64())
+	assert.Equal(uint64(0), sender.BalanceOf(token).Uint64())
+	assert.Equal(uint64(1), sender.Nonce())
+
+	assert.Equal(unit.LDC, recipient.BalanceOfAll(ids.NativeToken).Uint64())
+	assert.Equal(unit.LDC*2, recipient.BalanceOf(token).Uint64())
+	assert.Equal(uint64(0), recipient2.BalanceOfAll(ids.NativeToken).Uint64())
+	assert.Equal(unit.LDC*3, recipient2.BalanceOf(token).Uint64())
+
+	jsondata, err := itx.MarshalJSON()
+	require.NoError(t, err)
+	assert.Contains(string(jsondata), `"amount":2000000000,"token":"$LDC"`)
+
+	assert.NoError(cs.VerifyState())
+}
+
 func TestTxTransferMultipleGas(t *testing.T) {
 	t.Skip()
 
@@ -453,3 +548,88 @@ func TestTxTransferMultipleGas(t *testing.T) {
 	}
 	assert.True(false, "should print gas/recip...")
 }
+
+func TestTxTransferMultipleWithPolicy(t *testing.T) {
+	assert := assert.New(t)
+
+	ctx := NewMockChainContext()
+	cs := ctx.MockChainState()
+	sender := cs.MustAccount(signer.Signer1.Key().Address())
+	sender.Add(ids.NativeToken, new(big.Int).SetUint64(unit.LDC*10))
+	recipient := cs.MustAccount(signer.Signer2.Key().Address())
+	recipient2 := cs.MustAccount(signer.Signer3.Key().Address())
+	assert.NoError(sender.UpdatePolicy(&ld.AccountPolicy{
+		Window: 3600,
+		Limits: map[cbor.ByteString]*big.Int{
+			ids.NativeToken.AsKey(): new(big.Int).SetUint64(unit.LDC * 2),
+		},
+		Allowlist: []ids.Address{recipient.ID(), recipient2.ID()},
+		Delay:     3600,
+	}))
+
+	ltx := &ld.Transaction{Tx: ld.TxData{
+		Type:      ld.TypeTransferMultiple,
+		ChainID:   ctx.ChainConfig().ChainID,
+		Nonce:     0,
+		GasTip:    100,
+		GasFeeCap: ctx.Price,
+		From:      sender.ID(),
+		Data: ld.MustMarshal(ld.SendOutputs{
+			{To: recipient.ID(), Amount: new(big.Int).SetUint64(unit.LDC)},
+			{To: ids.GenesisAccount, Amount: new(big.Int).SetUint64(unit.LDC)},
+		}),
+	}}
+	assert.NoError(ltx.SignWith(signer.Signer1))
+	assert.NoError(ltx.SyntacticVerify())
+	itx, err := NewTx(ltx)
+	require.NoError(t, err)
+	cs.CommitAccounts()
+	assert.ErrorContains(itx.Apply(ctx, cs),
+		"recipient "+ids.GenesisAccount.String()+" is not in the allowlist")
+	cs.CheckoutAccounts()
+
+	// the limit applies to the total outflow
+	ltx = &ld.Transaction{Tx: ld.TxData{
+		Type:      ld.TypeTransferMultiple,
+		ChainID:   ctx.ChainConfig().ChainID,
+		Nonce:     0,
+		GasTip:    100,
+		GasFeeCap: ctx.Price,
+		From:      sender.ID(),
+		Data: ld.MustMarshal(ld.SendOutputs{
+			{To: recipient.ID(), Amount: new(big.Int).SetUint64(unit.LDC)},
+			{To: recipient2.ID(), Amount: new(big.Int).SetUint64(unit.LDC + 1)},
+		}),
+	}}
+	assert.NoError(ltx.SignWith(signer.Signer1))
+	assert.NoError(ltx.SyntacticVerify())
+	itx, err = NewTx(ltx)
+	require.NoError(t, err)
+	cs.CommitAccounts()
+	assert.ErrorContains(itx.Apply(ctx, cs),
+		"NativeLDC outflow exceeds the policy limit, expected <= 2000000000, got 2000000001")
+	cs.CheckoutAccounts()
+
+	ltx = &ld.Transaction{Tx: ld.TxData{
+		Type:      ld.TypeTransferMultiple,
+		ChainID:   ctx.ChainConfig().ChainID,
+		Nonce:     0,
+		GasTip:    100,
+		GasFeeCap: ctx.Price,
+		From:      sender.ID(),
+		Data: ld.MustMarshal(ld.SendOutputs{
+			{To: recipient.ID(), Amount: new(big.Int).SetUint64(unit.LDC)},
+			{To: recipient2.ID(), Amount: new(big.Int).SetUint64(unit.LDC)},
+		}),
+	}}
+	assert.NoError(ltx.SignWith(signer.Signer1))
+	assert.NoError(ltx.SyntacticVerify())
+	itx, err = NewTx(ltx)
+	require.NoError(t, err)
+	assert.NoError(itx.Apply(ctx, cs))
+	assert.Equal(unit.LDC*2, sender.LD().Policy.Outflows[0].Amount.Uint64())
+	assert.Equal(unit.LDC, recipient.BalanceOfAll(ids.NativeToken).Uint64())
+	assert.Equal(unit.LDC, recipient2.BalanceOfAll(ids.NativeToken).Uint64())
+
+	assert.NoError(cs.VerifyState())
+}
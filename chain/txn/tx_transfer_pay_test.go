@@ -32,7 +32,7 @@ func TestTxTransferPay(t *testing.T) {
 	from.LD().Nonce = 1
 
 	to := cs.MustAccount(ids.GenesisAccount)
-	assert.NoError(to.UpdateKeepers(ld.Uint16Ptr(1), &signer.Keys{signer.Signer2.Key()}, nil, nil))
+	assert.NoError(to.UpdateKeepers(ld.Uint16Ptr(1), &signer.Keys{signer.Signer2.Key()}, nil, nil, nil))
 
 	ltx := &ld.Transaction{Tx: ld.TxData{
 		Type:      ld.TypeTransferPay,
@@ -7,6 +7,7 @@ import (
 	"math/big"
 	"testing"
 
+	"github.com/fxamacker/cbor/v2"
 	"github.com/ldclabs/ldvm/ids"
 	"github.com/ldclabs/ldvm/ld"
 	"github.com/ldclabs/ldvm/signer"
@@ -251,3 +252,137 @@ func TestTxTransferFromNoKeeperAccount(t *testing.T) {
 
 	assert.NoError(cs.VerifyState())
 }
+
+func TestTxTransferWithPolicy(t *testing.T) {
+	assert := assert.New(t)
+
+	ctx := NewMockChainContext()
+	cs := ctx.MockChainState()
+	from := cs.MustAccount(signer.Signer1.Key().Address())
+	from.Add(ids.NativeToken, new(big.Int).SetUint64(unit.LDC*10))
+	to := cs.MustAccount(ids.Address{1, 2, 3})
+	assert.NoError(from.UpdatePolicy(&ld.AccountPolicy{
+		Window: 3600,
+		Limits: map[cbor.ByteString]*big.Int{
+			ids.NativeToken.AsKey(): new(big.Int).SetUint64(unit.LDC),
+		},
+		Allowlist: []ids.Address{to.ID()},
+		Delay:     3600,
+	}))
+
+	ltx := &ld.Transaction{Tx: ld.TxData{
+		Type:      ld.TypeTransfer,
+		ChainID:   ctx.ChainConfig().ChainID,
+		Nonce:     0,
+		GasTip:    100,
+		GasFeeCap: ctx.Price,
+		From:      from.ID(),
+		To:        ids.GenesisAccount.Ptr(),
+		Amount:    new(big.Int).SetUint64(unit.MilliLDC),
+	}}
+	assert.NoError(ltx.SignWith(signer.Signer1))
+	assert.NoError(ltx.SyntacticVerify())
+	itx, err := NewTx(ltx)
+	require.NoError(t, err)
+	cs.CommitAccounts()
+	assert.ErrorContains(itx.Apply(ctx, cs),
+		"recipient "+ids.GenesisAccount.String()+" is not in the allowlist")
+	cs.CheckoutAccounts()
+
+	ltx = &ld.Transaction{Tx: ld.TxData{
+		Type:      ld.TypeTransfer,
+		ChainID:   ctx.ChainConfig().ChainID,
+		Nonce:     0,
+		GasTip:    100,
+		GasFeeCap: ctx.Price,
+		From:      from.ID(),
+		To:        to.ID().Ptr(),
+		Amount:    new(big.Int).SetUint64(unit.LDC + 1),
+	}}
+	assert.NoError(ltx.SignWith(signer.Signer1))
+	assert.NoError(ltx.SyntacticVerify())
+	itx, err = NewTx(ltx)
+	require.NoError(t, err)
+	cs.CommitAccounts()
+	assert.ErrorContains(itx.Apply(ctx, cs),
+		"NativeLDC outflow exceeds the policy limit, expected <= 1000000000, got 1000000001")
+	cs.CheckoutAccounts()
+
+	ltx = &ld.Transaction{Tx: ld.TxData{
+		Type:      ld.TypeTransfer,
+		ChainID:   ctx.ChainConfig().ChainID,
+		Nonce:     0,
+		GasTip:    100,
+		GasFeeCap: ctx.Price,
+		From:      from.ID(),
+		To:        to.ID().Ptr(),
+		Amount:    new(big.Int).SetUint64(unit.MilliLDC * 600),
+	}}
+	assert.NoError(ltx.SignWith(signer.Signer1))
+	assert.NoError(ltx.SyntacticVerify())
+	itx, err = NewTx(ltx)
+	require.NoError(t, err)
+	assert.NoError(itx.Apply(ctx, cs))
+	assert.Equal(unit.MilliLDC*600, to.BalanceOfAll(ids.NativeToken).Uint64())
+	assert.Equal(unit.MilliLDC*600,
+		from.LD().Policy.Outflows[0].Amount.Uint64())
+
+	ltx = &ld.Transaction{Tx: ld.TxData{
+		Type:      ld.TypeTransfer,
+		ChainID:   ctx.ChainConfig().ChainID,
+		Nonce:     1,
+		GasTip:    100,
+		GasFeeCap: ctx.Price,
+		From:      from.ID(),
+		To:        to.ID().Ptr(),
+		Amount:    new(big.Int).SetUint64(unit.MilliLDC * 500),
+	}}
+	assert.NoError(ltx.SignWith(signer.Signer1))
+	assert.NoError(ltx.SyntacticVerify())
+	itx, err = NewTx(ltx)
+	require.NoError(t, err)
+	cs.CommitAccounts()
+	assert.ErrorContains(itx.Apply(ctx, cs),
+		"NativeLDC outflow exceeds the policy limit, expected <= 1000000000, got 1100000000")
+	cs.CheckoutAccounts()
+
+	// the gas cost and 0 amount are not limited
+	ltx = &ld.Transaction{Tx: ld.TxData{
+		Type:      ld.TypeTransfer,
+		ChainID:   ctx.ChainConfig().ChainID,
+		Nonce:     1,
+		GasTip:    100,
+		GasFeeCap: ctx.Price,
+		From:      from.ID(),
+		To:        ids.GenesisAccount.Ptr(),
+		Amount:    new(big.Int).SetUint64(0),
+		Data:      []byte(`"some message"`),
+	}}
+	assert.NoError(ltx.SignWith(signer.Signer1))
+	assert.NoError(ltx.SyntacticVerify())
+	itx, err = NewTx(ltx)
+	require.NoError(t, err)
+	assert.NoError(itx.Apply(ctx, cs))
+	assert.Equal(uint64(2), from.Nonce())
+
+	// the txs that can bypass the policy are rejected
+	ltx = &ld.Transaction{Tx: ld.TxData{
+		Type:      ld.TypeCloseAccount,
+		ChainID:   ctx.ChainConfig().ChainID,
+		Nonce:     2,
+		GasTip:    100,
+		GasFeeCap: ctx.Price,
+		From:      from.ID(),
+		To:        to.ID().Ptr(),
+	}}
+	assert.NoError(ltx.SignWith(signer.Signer1))
+	assert.NoError(ltx.SyntacticVerify())
+	itx, err = NewTx(ltx)
+	require.NoError(t, err)
+	cs.CommitAccounts()
+	assert.ErrorContains(itx.Apply(ctx, cs),
+		"TypeCloseAccount is not allowed while the policy is in effect")
+	cs.CheckoutAccounts()
+
+	assert.NoError(cs.VerifyState())
+}
@@ -41,6 +41,45 @@ type Config struct {
 	RPCAddr     string         `json:"rpcAddr"`
 	POSEndpoint string         `json:"posEndpoint"` // persistent data source endpoint
 	Builder     *Builder       `json:"builder"`
+	Pruning     *Pruning       `json:"pruning"` // optional, keep all historical data versions if nil
+}
+
+// Pruning is the node local retention policies of historical data versions.
+// It doesn't change the chain state.
+type Pruning struct {
+	// seconds between pruning runs, default to 60
+	Interval uint64 `json:"interval"`
+	// policy for data of models that are not in Models, keep all versions if nil
+	Default *RetentionPolicy                 `json:"default"`
+	Models  map[ids.ModelID]*RetentionPolicy `json:"models"`
+}
+
+// RetentionPolicy retains a previous version of a data if it is one of the latest
+// KeepVersions previous versions, or it was superseded within KeepSeconds.
+// 0 means that the rule retains nothing.
+type RetentionPolicy struct {
+	KeepVersions uint64 `json:"keepVersions"`
+	KeepSeconds  uint64 `json:"keepSeconds"`
+}
+
+func (p *Pruning) Valid() error {
+	if p.Interval == 0 {
+		p.Interval = 60
+	}
+	for mid, rp := range p.Models {
+		if rp == nil {
+			return fmt.Errorf("nil retention policy for model %s", mid)
+		}
+	}
+	return nil
+}
+
+// Policy returns the retention policy of the model, nil means keep all versions.
+func (p *Pruning) Policy(mid ids.ModelID) *RetentionPolicy {
+	if rp, ok := p.Models[mid]; ok {
+		return rp
+	}
+	return p.Default
 }
 
 type Builder struct {
@@ -94,5 +133,11 @@ func New(ctx context.Context, data []byte) (*Config, error) {
 		}
 	}
 
+	if cfg.Pruning != nil {
+		if err := cfg.Pruning.Valid(); err != nil {
+			return nil, err
+		}
+	}
+
 	return cfg, nil
 }
@@ -109,6 +109,34 @@ func (p *PrefixDB) Put(key, value []byte) error {
 	return p.db.Put(p.keyBuf[:n+p.prefixLen], value)
 }
 
+// NewIterator creates an iterator over the keys with the PrefixDB's prefix,
+// starting at the start key. The prefix is stripped from the iterator's keys.
+// The keys should not be hashed.
+func (p *PrefixDB) NewIterator(start []byte) database.Iterator {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	prefix := make([]byte, p.prefixLen, p.prefixLen+len(start))
+	copy(prefix, p.keyBuf[:p.prefixLen])
+	return &prefixIterator{
+		Iterator:  p.db.NewIteratorWithStartAndPrefix(append(prefix, start...), prefix),
+		prefixLen: p.prefixLen,
+	}
+}
+
+type prefixIterator struct {
+	database.Iterator
+	prefixLen int
+}
+
+func (it *prefixIterator) Key() []byte {
+	key := it.Iterator.Key()
+	if len(key) < it.prefixLen {
+		return nil
+	}
+	return key[it.prefixLen:]
+}
+
 func (p *PrefixDB) Delete(key []byte) error {
 	p.mu.Lock()
 	defer p.mu.Unlock()
@@ -55,6 +55,25 @@ func TestPrefixDB(t *testing.T) {
 	require.NoError(t, err)
 	assert.False(ok)
 
+	// iterator
+	assert.NoError(dbp3.Put([]byte{1, 2}, []byte("v12")))
+	assert.NoError(dbp3.Put([]byte{1, 1}, []byte("v11")))
+	assert.NoError(dbp3.Put([]byte{2}, []byte("v2")))
+	assert.NoError(dbp2.Put([]byte{1, 1}, []byte("x")))
+	it := dbp3.NewIterator([]byte{1, 2})
+	keys := make([][]byte, 0)
+	for it.Next() {
+		keys = append(keys, append([]byte{}, it.Key()...))
+	}
+	assert.NoError(it.Error())
+	it.Release()
+	assert.Equal([][]byte{{1, 2}, {2}}, keys)
+	it = dbp3.NewIterator(nil)
+	require.True(t, it.Next())
+	assert.Equal([]byte{1, 1}, it.Key())
+	assert.Equal([]byte("v11"), it.Value())
+	it.Release()
+
 	cc := NewCacher(100, 1, func() Objecter { return new(ld.Transaction) })
 	tx := &ld.Transaction{Tx: ld.TxData{
 		Type:      ld.TypeTransfer,
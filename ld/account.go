@@ -53,15 +53,27 @@ type Account struct {
 	Threshold uint16 `cbor:"th" json:"threshold"`
 	// keepers who can use this account, no more than 64
 	// the account id must be one of them.
-	Keepers     signer.Keys                  `cbor:"kp" json:"keepers"`
+	Keepers signer.Keys `cbor:"kp" json:"keepers"`
+	// optional weights of keepers one by one, the threshold is the sum of weights when set.
+	Weights     []uint16                     `cbor:"kw,omitempty" json:"weights,omitempty"`
 	Tokens      map[cbor.ByteString]*big.Int `cbor:"tk" json:"tokens"`
 	NonceTable  map[uint64][]uint64          `cbor:"nt" json:"nonceTable"` // map[expire][]nonce
 	Approver    signer.Key                   `cbor:"ap,omitempty" json:"approver,omitempty"`
 	ApproveList TxTypes                      `cbor:"apl,omitempty" json:"approveList,omitempty"`
+	// SessionKeys can sign txs in their scope without being keepers
+	SessionKeys SessionKeys `cbor:"sk,omitempty" json:"sessionKeys,omitempty"`
+	// Policy limits the outflow and recipients of the account
+	Policy *PolicyState `cbor:"po,omitempty" json:"policy,omitempty"`
 	// MaxTotalSupply only used with TokenAccount
-	MaxTotalSupply *big.Int       `cbor:"mts,omitempty" json:"maxTotalSupply,omitempty"`
-	Stake          *StakeConfig   `cbor:"st,omitempty" json:"stake,omitempty"`
-	Lending        *LendingConfig `cbor:"le,omitempty" json:"lending,omitempty"`
+	MaxTotalSupply *big.Int `cbor:"mts,omitempty" json:"maxTotalSupply,omitempty"`
+	// SupplyCap is the hard cap of MaxTotalSupply when minting, only used with TokenAccount
+	SupplyCap *big.Int `cbor:"sc,omitempty" json:"supplyCap,omitempty"`
+	// BurnedSupply is the total amount of burned token, only used with TokenAccount
+	BurnedSupply *big.Int `cbor:"bs,omitempty" json:"burnedSupply,omitempty"`
+	// TokenInfo is the optional metadata, only used with TokenAccount
+	TokenInfo *TokenInfo     `cbor:"ti,omitempty" json:"tokenInfo,omitempty"`
+	Stake     *StakeConfig   `cbor:"st,omitempty" json:"stake,omitempty"`
+	Lending   *LendingConfig `cbor:"le,omitempty" json:"lending,omitempty"`
 
 	// external assignment fields
 	Height    uint64      `cbor:"-" json:"height"`    // block's timestamp
@@ -88,7 +100,7 @@ func (a *Account) SyntacticVerify() error {
 	case len(a.Keepers) > MaxKeepers:
 		return errp.Errorf("invalid keepers, too many")
 
-	case int(a.Threshold) > len(a.Keepers):
+	case uint64(a.Threshold) > a.Keepers.Weight(a.Weights...):
 		return errp.Errorf("invalid threshold")
 
 	case a.Tokens == nil:
@@ -102,6 +114,10 @@ func (a *Account) SyntacticVerify() error {
 		return errp.Errorf("invalid keepers, %v", err)
 	}
 
+	if err = a.Keepers.ValidWeights(a.Weights); err != nil {
+		return errp.Errorf("invalid weights, %v", err)
+	}
+
 	if a.Approver != nil {
 		if err = a.Approver.Valid(); err != nil {
 			return errp.Errorf("invalid approver, %v", err)
@@ -120,11 +136,26 @@ func (a *Account) SyntacticVerify() error {
 		}
 	}
 
+	if a.SessionKeys != nil {
+		if err = a.SessionKeys.SyntacticVerify(); err != nil {
+			return errp.ErrorIf(err)
+		}
+	}
+
+	if a.Policy != nil {
+		if err = a.Policy.SyntacticVerify(); err != nil {
+			return errp.ErrorIf(err)
+		}
+	}
+
 	switch a.Type {
 	case NativeAccount:
 		if a.MaxTotalSupply != nil {
 			return errp.Errorf("invalid maxTotalSupply, should be nil")
 		}
+		if a.SupplyCap != nil || a.BurnedSupply != nil {
+			return errp.Errorf("invalid supplyCap or burnedSupply, should be nil")
+		}
 		if a.Stake != nil {
 			return errp.Errorf("invalid stake on NativeAccount")
 		}
@@ -136,11 +167,27 @@ func (a *Account) SyntacticVerify() error {
 		if a.MaxTotalSupply == nil || a.MaxTotalSupply.Sign() < 0 {
 			return errp.Errorf("invalid maxTotalSupply")
 		}
+		if a.SupplyCap != nil && a.SupplyCap.Cmp(a.MaxTotalSupply) < 0 {
+			return errp.Errorf("invalid supplyCap, expected >= %v, got %v",
+				a.MaxTotalSupply, a.SupplyCap)
+		}
+		if a.BurnedSupply != nil &&
+			(a.BurnedSupply.Sign() < 0 || a.BurnedSupply.Cmp(a.MaxTotalSupply) > 0) {
+			return errp.Errorf("invalid burnedSupply")
+		}
+		if a.TokenInfo != nil {
+			if err = a.TokenInfo.SyntacticVerify(); err != nil {
+				return errp.ErrorIf(err)
+			}
+		}
 
 	case StakeAccount:
 		if a.MaxTotalSupply != nil {
 			return errp.Errorf("invalid maxTotalSupply, should be nil")
 		}
+		if a.SupplyCap != nil || a.BurnedSupply != nil {
+			return errp.Errorf("invalid supplyCap or burnedSupply, should be nil")
+		}
 		if a.Stake == nil {
 			return errp.Errorf("invalid stake on StakeAccount")
 		}
@@ -153,6 +200,10 @@ func (a *Account) SyntacticVerify() error {
 		return errp.Errorf("invalid type")
 	}
 
+	if a.Type != TokenAccount && a.TokenInfo != nil {
+		return errp.Errorf("invalid tokenInfo, should be nil")
+	}
+
 	if a.Lending != nil {
 		if err := a.Lending.SyntacticVerify(); err != nil {
 			return err
@@ -165,6 +216,30 @@ func (a *Account) SyntacticVerify() error {
 	return nil
 }
 
+// TotalSupply returns the circulating supply of a TokenAccount,
+// it excludes the burned supply and the token held by the account itself.
+func (a *Account) TotalSupply() *big.Int {
+	total := new(big.Int)
+	if a.Type != TokenAccount || a.MaxTotalSupply == nil {
+		return total
+	}
+
+	total.Set(a.MaxTotalSupply)
+	if a.BurnedSupply != nil {
+		total.Sub(total, a.BurnedSupply)
+	}
+
+	switch token := ids.TokenSymbol(a.ID); token {
+	case ids.NativeToken:
+		total.Sub(total, a.Balance)
+	default:
+		if v := a.Tokens[token.AsKey()]; v != nil {
+			total.Sub(total, v)
+		}
+	}
+	return total
+}
+
 func (a *Account) CheckAsFrom(txType TxType) error {
 	errp := erring.ErrPrefix("ld.Account.CheckAsFrom: ")
 
@@ -260,7 +335,7 @@ func (a *Account) Verify(digestHash []byte, sigs signer.Sigs, accountKey signer.
 		return accountKey.Verify(digestHash, sigs)
 
 	default:
-		return a.Keepers.Verify(digestHash, sigs, a.Threshold)
+		return a.Keepers.Verify(digestHash, sigs, a.Threshold, a.Weights...)
 	}
 }
 
@@ -273,7 +348,7 @@ func (a *Account) VerifyPlus(digestHash []byte, sigs signer.Sigs, accountKey sig
 		return accountKey.Verify(digestHash, sigs)
 
 	default:
-		return a.Keepers.VerifyPlus(digestHash, sigs, a.Threshold)
+		return a.Keepers.VerifyPlus(digestHash, sigs, a.Threshold, a.Weights...)
 	}
 }
 
@@ -4,17 +4,30 @@
 package ld
 
 import (
+	"fmt"
 	"math/big"
 
 	"github.com/fxamacker/cbor/v2"
+	"github.com/ldclabs/ldvm/ids"
 	"github.com/ldclabs/ldvm/signer"
 	"github.com/ldclabs/ldvm/util/encoding"
 	"github.com/ldclabs/ldvm/util/erring"
 )
 
 type AccountLedger struct {
-	Lending map[cbor.ByteString]*LendingEntry `cbor:"l"`
-	Stake   map[cbor.ByteString]*StakeEntry   `cbor:"s"`
+	Lending   map[cbor.ByteString]*LendingEntry   `cbor:"l"`
+	Stake     map[cbor.ByteString]*StakeEntry     `cbor:"s"`
+	Vesting   map[cbor.ByteString]*VestingEntry   `cbor:"v,omitempty"`
+	HTLC      map[cbor.ByteString]*HTLCEntry      `cbor:"h,omitempty"`
+	Escrow    map[cbor.ByteString]*EscrowEntry    `cbor:"e,omitempty"`
+	Allowance map[cbor.ByteString]*AllowanceEntry `cbor:"a,omitempty"`
+	Recovery  *RecoveryEntry                      `cbor:"r,omitempty"`
+	Order     map[cbor.ByteString]*OrderEntry     `cbor:"o,omitempty"`
+	Schedule  map[cbor.ByteString]*ScheduleEntry  `cbor:"sc,omitempty"`
+	// subscriptions approved to payees, keyed by AllowanceKey(payee, token)
+	Subscription map[cbor.ByteString]*SubscriptionEntry `cbor:"sb,omitempty"`
+	// pending multisig proposals, keyed by the inner tx's SigHash
+	Proposal map[cbor.ByteString]*ProposalEntry `cbor:"pp,omitempty"`
 
 	// external assignment fields
 	raw []byte `cbor:"-"`
@@ -56,6 +69,110 @@ func (a *AccountLedger) SyntacticVerify() error {
 		}
 	}
 
+	if a.Vesting == nil {
+		a.Vesting = make(map[cbor.ByteString]*VestingEntry)
+	}
+
+	for _, entry := range a.Vesting {
+		if entry == nil {
+			return errp.Errorf("nil VestingEntry")
+		}
+		if err := entry.SyntacticVerify(); err != nil {
+			return errp.Errorf("invalid VestingEntry, %v", err)
+		}
+	}
+
+	if a.HTLC == nil {
+		a.HTLC = make(map[cbor.ByteString]*HTLCEntry)
+	}
+
+	for _, entry := range a.HTLC {
+		if entry == nil {
+			return errp.Errorf("nil HTLCEntry")
+		}
+		if err := entry.SyntacticVerify(); err != nil {
+			return errp.Errorf("invalid HTLCEntry, %v", err)
+		}
+	}
+
+	if a.Escrow == nil {
+		a.Escrow = make(map[cbor.ByteString]*EscrowEntry)
+	}
+
+	for _, entry := range a.Escrow {
+		if entry == nil {
+			return errp.Errorf("nil EscrowEntry")
+		}
+		if err := entry.SyntacticVerify(); err != nil {
+			return errp.Errorf("invalid EscrowEntry, %v", err)
+		}
+	}
+
+	if a.Allowance == nil {
+		a.Allowance = make(map[cbor.ByteString]*AllowanceEntry)
+	}
+
+	for _, entry := range a.Allowance {
+		if entry == nil || entry.Amount == nil || entry.Amount.Sign() <= 0 {
+			return errp.Errorf("invalid amount on AllowanceEntry")
+		}
+	}
+
+	if a.Recovery != nil {
+		if err := a.Recovery.SyntacticVerify(); err != nil {
+			return errp.Errorf("invalid RecoveryEntry, %v", err)
+		}
+	}
+
+	if a.Order == nil {
+		a.Order = make(map[cbor.ByteString]*OrderEntry)
+	}
+
+	for _, entry := range a.Order {
+		if entry == nil || entry.Remaining == nil || entry.Remaining.Sign() <= 0 {
+			return errp.Errorf("invalid remaining on OrderEntry")
+		}
+	}
+
+	if a.Schedule == nil {
+		a.Schedule = make(map[cbor.ByteString]*ScheduleEntry)
+	}
+
+	for _, entry := range a.Schedule {
+		if entry == nil {
+			return errp.Errorf("nil ScheduleEntry")
+		}
+		if err := entry.SyntacticVerify(); err != nil {
+			return errp.Errorf("invalid ScheduleEntry, %v", err)
+		}
+	}
+
+	if a.Subscription == nil {
+		a.Subscription = make(map[cbor.ByteString]*SubscriptionEntry)
+	}
+
+	for _, entry := range a.Subscription {
+		if entry == nil {
+			return errp.Errorf("nil SubscriptionEntry")
+		}
+		if err := entry.SyntacticVerify(); err != nil {
+			return errp.Errorf("invalid SubscriptionEntry, %v", err)
+		}
+	}
+
+	if a.Proposal == nil {
+		a.Proposal = make(map[cbor.ByteString]*ProposalEntry)
+	}
+
+	for _, entry := range a.Proposal {
+		if entry == nil {
+			return errp.Errorf("nil ProposalEntry")
+		}
+		if err := entry.SyntacticVerify(); err != nil {
+			return errp.Errorf("invalid ProposalEntry, %v", err)
+		}
+	}
+
 	if a.raw, err = a.Marshal(); err != nil {
 		return errp.ErrorIf(err)
 	}
@@ -98,3 +215,327 @@ type StakeEntry struct {
 	LockTime uint64      `json:"lockTime"`
 	Approver *signer.Key `json:"approver"`
 }
+
+// VestingEntry is a vesting schedule on the beneficiary's ledger.
+// The locked amount is released linearly from StartTime to EndTime,
+// nothing can be claimed before CliffTime.
+type VestingEntry struct {
+	_ struct{} `cbor:",toarray"`
+
+	Grantor   ids.Address     `json:"grantor"`
+	Token     ids.TokenSymbol `json:"token"`
+	Amount    *big.Int        `json:"amount"`  // total locked amount
+	Claimed   *big.Int        `json:"claimed"` // amount already claimed
+	StartTime uint64          `json:"startTime"`
+	CliffTime uint64          `json:"cliffTime"`
+	EndTime   uint64          `json:"endTime"`
+}
+
+// SyntacticVerify verifies that a *VestingEntry is well-formed.
+func (e *VestingEntry) SyntacticVerify() error {
+	switch {
+	case !e.Token.Valid():
+		return fmt.Errorf("invalid token %s", e.Token.GoString())
+
+	case e.Amount == nil || e.Amount.Sign() <= 0:
+		return fmt.Errorf("invalid amount")
+
+	case e.Claimed == nil || e.Claimed.Sign() < 0 || e.Claimed.Cmp(e.Amount) >= 0:
+		return fmt.Errorf("invalid claimed")
+
+	case e.CliffTime < e.StartTime || e.EndTime < e.CliffTime || e.EndTime <= e.StartTime:
+		return fmt.Errorf("invalid schedule")
+	}
+	return nil
+}
+
+// Vested returns the total amount released at the given time, including the claimed amount.
+func (e *VestingEntry) Vested(timestamp uint64) *big.Int {
+	switch {
+	case timestamp < e.CliffTime:
+		return new(big.Int)
+
+	case timestamp >= e.EndTime:
+		return new(big.Int).Set(e.Amount)
+
+	default:
+		vested := new(big.Int).Mul(e.Amount, new(big.Int).SetUint64(timestamp-e.StartTime))
+		return vested.Quo(vested, new(big.Int).SetUint64(e.EndTime-e.StartTime))
+	}
+}
+
+// Releasable returns the amount that can be claimed at the given time.
+func (e *VestingEntry) Releasable(timestamp uint64) *big.Int {
+	releasable := e.Vested(timestamp)
+	return releasable.Sub(releasable, e.Claimed)
+}
+
+// VestingConfig is the vesting schedule used by TxCreateVesting.
+type VestingConfig struct {
+	_ struct{} `cbor:",toarray"`
+
+	Amount    *big.Int `json:"amount"`
+	CliffTime uint64   `json:"cliffTime"` // nothing can be claimed before CliffTime
+	EndTime   uint64   `json:"endTime"`   // all amount can be claimed after EndTime
+}
+
+// SyntacticVerify verifies that a *VestingConfig is well-formed.
+func (c *VestingConfig) SyntacticVerify() error {
+	errp := erring.ErrPrefix("ld.VestingConfig.SyntacticVerify: ")
+
+	switch {
+	case c == nil:
+		return errp.Errorf("nil pointer")
+
+	case c.Amount == nil || c.Amount.Sign() < 1:
+		return errp.Errorf("invalid amount")
+
+	case c.EndTime == 0:
+		return errp.Errorf("invalid endTime")
+
+	case c.CliffTime > c.EndTime:
+		return errp.Errorf("invalid cliffTime, expected <= %d, got %d", c.EndTime, c.CliffTime)
+	}
+	return nil
+}
+
+func (c *VestingConfig) Unmarshal(data []byte) error {
+	return erring.ErrPrefix("ld.VestingConfig.Unmarshal: ").
+		ErrorIf(encoding.UnmarshalCBOR(data, c))
+}
+
+func (c *VestingConfig) Marshal() ([]byte, error) {
+	return erring.ErrPrefix("ld.VestingConfig.Marshal: ").
+		ErrorMap(encoding.MarshalCBOR(c))
+}
+
+// HTLCEntry is a hash-time-locked transfer on the sender's ledger, keyed by the hashlock.
+// The recipient can claim it with the preimage before Expire,
+// the sender can refund it after Expire.
+type HTLCEntry struct {
+	_ struct{} `cbor:",toarray"`
+
+	Recipient ids.Address     `json:"recipient"`
+	Token     ids.TokenSymbol `json:"token"`
+	Amount    *big.Int        `json:"amount"`
+	HashAlgo  HashAlgo        `json:"hashAlgo"`
+	Expire    uint64          `json:"expire"`
+}
+
+// SyntacticVerify verifies that a *HTLCEntry is well-formed.
+func (e *HTLCEntry) SyntacticVerify() error {
+	switch {
+	case !e.Token.Valid():
+		return fmt.Errorf("invalid token %s", e.Token.GoString())
+
+	case e.Amount == nil || e.Amount.Sign() <= 0:
+		return fmt.Errorf("invalid amount")
+
+	case !e.HashAlgo.Valid():
+		return fmt.Errorf("invalid hashAlgo %d", e.HashAlgo)
+
+	case e.Expire == 0:
+		return fmt.Errorf("invalid expire")
+	}
+	return nil
+}
+
+// EscrowEntry is an escrow on the payer's ledger, keyed by the creating transaction's ID.
+// It can be settled with 2-of-3 signatures from the payer, the payee and the arbiter.
+type EscrowEntry struct {
+	_ struct{} `cbor:",toarray"`
+
+	Payee   ids.Address     `json:"payee"`
+	Arbiter ids.Address     `json:"arbiter"`
+	Token   ids.TokenSymbol `json:"token"`
+	Amount  *big.Int        `json:"amount"`
+}
+
+// SyntacticVerify verifies that a *EscrowEntry is well-formed.
+func (e *EscrowEntry) SyntacticVerify() error {
+	switch {
+	case e.Payee == ids.EmptyAddress:
+		return fmt.Errorf("invalid payee")
+
+	case e.Arbiter == ids.EmptyAddress || e.Arbiter == e.Payee:
+		return fmt.Errorf("invalid arbiter")
+
+	case !e.Token.Valid():
+		return fmt.Errorf("invalid token %s", e.Token.GoString())
+
+	case e.Amount == nil || e.Amount.Sign() <= 0:
+		return fmt.Errorf("invalid amount")
+	}
+	return nil
+}
+
+// AllowanceEntry is the remaining amount of token that the spender can transfer
+// from the account, keyed by AllowanceKey(spender, token). Expire 0 means no expiry.
+type AllowanceEntry struct {
+	_ struct{} `cbor:",toarray"`
+
+	Amount *big.Int `json:"amount"`
+	Expire uint64   `json:"expire"`
+}
+
+// AllowanceKey returns the key of the spender's allowance for the token.
+func AllowanceKey(spender ids.Address, token ids.TokenSymbol) cbor.ByteString {
+	key := make([]byte, 0, 40)
+	key = append(key, spender[:]...)
+	return cbor.ByteString(append(key, token[:]...))
+}
+
+// OrderEntry is a partially filled exchange order on the seller's ledger,
+// keyed by the order's hash. It is removed when filled up or cancelled.
+type OrderEntry struct {
+	_ struct{} `cbor:",toarray"`
+
+	Remaining *big.Int `json:"remaining"`
+	Expire    uint64   `json:"expire"`
+}
+
+// SubscriptionEntry is a standing payment authorization on the payer's ledger,
+// the payee can pull up to Limit of token in each period without a new signature.
+// Spent is the amount pulled in the period starting at PeriodStart.
+type SubscriptionEntry struct {
+	_ struct{} `cbor:",toarray"`
+
+	Limit       *big.Int           `json:"limit"`
+	Period      SubscriptionPeriod `json:"period"`
+	Expire      uint64             `json:"expire"`
+	PeriodStart uint64             `json:"periodStart"`
+	Spent       *big.Int           `json:"spent"`
+}
+
+// SyntacticVerify verifies that a *SubscriptionEntry is well-formed.
+func (e *SubscriptionEntry) SyntacticVerify() error {
+	switch {
+	case e.Limit == nil || e.Limit.Sign() <= 0:
+		return fmt.Errorf("invalid limit")
+
+	case !e.Period.Valid():
+		return fmt.Errorf("invalid period %d", e.Period)
+
+	case e.Spent == nil || e.Spent.Sign() < 0 || e.Spent.Cmp(e.Limit) > 0:
+		return fmt.Errorf("invalid spent")
+	}
+	return nil
+}
+
+// Remaining returns the amount that can be pulled at the timestamp.
+func (e *SubscriptionEntry) Remaining(timestamp uint64) *big.Int {
+	if e.Period.Start(timestamp) != e.PeriodStart {
+		return new(big.Int).Set(e.Limit)
+	}
+	return new(big.Int).Sub(e.Limit, e.Spent)
+}
+
+// ScheduleEntry is a scheduled tx on the sender's ledger, keyed by the inner tx's ID.
+// Reserve is the gas reserved from the sender, it is refunded before the inner tx executing.
+type ScheduleEntry struct {
+	_ struct{} `cbor:",toarray"`
+
+	Sender    ids.Address `json:"sender"`
+	Height    uint64      `json:"height"`
+	Timestamp uint64      `json:"timestamp"`
+	Reserve   *big.Int    `json:"reserve"`
+	Tx        []byte      `json:"tx"`
+}
+
+// SyntacticVerify verifies that a *ScheduleEntry is well-formed.
+func (e *ScheduleEntry) SyntacticVerify() error {
+	switch {
+	case e.Sender == ids.EmptyAddress:
+		return fmt.Errorf("invalid sender")
+
+	case e.Height == 0 && e.Timestamp == 0:
+		return fmt.Errorf("invalid schedule")
+
+	case e.Reserve == nil || e.Reserve.Sign() < 0:
+		return fmt.Errorf("invalid reserve")
+
+	case len(e.Tx) == 0:
+		return fmt.Errorf("empty tx")
+	}
+	return nil
+}
+
+// ProposalEntry is a pending multisig proposal on the account's ledger.
+// Sigs are the approvals collected over the inner tx's SigHash.
+// An executed proposal is kept without Tx and Sigs until it expires,
+// so that it can't be proposed again.
+type ProposalEntry struct {
+	_ struct{} `cbor:",toarray"`
+
+	Tx     []byte      `json:"tx"`
+	Expire uint64      `json:"expire"`
+	Sigs   signer.Sigs `json:"sigs"`
+}
+
+// SyntacticVerify verifies that a *ProposalEntry is well-formed.
+func (e *ProposalEntry) SyntacticVerify() error {
+	switch {
+	case len(e.Tx) == 0 && len(e.Sigs) > 0:
+		return fmt.Errorf("empty tx")
+
+	case e.Expire == 0:
+		return fmt.Errorf("invalid expire")
+
+	case len(e.Sigs) > MaxKeepers:
+		return fmt.Errorf("too many sigs")
+	}
+	return nil
+}
+
+// RecoveryEntry is the social recovery config on the account's ledger.
+// Pending is the recovery started by guardians, it can be executed after its ExecuteAt.
+type RecoveryEntry struct {
+	_ struct{} `cbor:",toarray"`
+
+	Threshold uint16           `json:"threshold"`
+	Guardians signer.Keys      `json:"guardians"`
+	Delay     uint64           `json:"delay"`
+	Pending   *PendingRecovery `json:"pending"`
+}
+
+// PendingRecovery is the new keepers proposed by guardians.
+type PendingRecovery struct {
+	_ struct{} `cbor:",toarray"`
+
+	Threshold uint16      `json:"threshold"`
+	Keepers   signer.Keys `json:"keepers"`
+	ExecuteAt uint64      `json:"executeAt"`
+}
+
+// SyntacticVerify verifies that a *RecoveryEntry is well-formed.
+func (e *RecoveryEntry) SyntacticVerify() error {
+	switch {
+	case len(e.Guardians) == 0 || len(e.Guardians) > MaxKeepers:
+		return fmt.Errorf("invalid guardians")
+
+	case e.Threshold == 0 || int(e.Threshold) > len(e.Guardians):
+		return fmt.Errorf("invalid threshold")
+
+	case e.Delay == 0:
+		return fmt.Errorf("invalid delay")
+	}
+
+	if err := e.Guardians.Valid(); err != nil {
+		return fmt.Errorf("invalid guardians, %v", err)
+	}
+
+	if p := e.Pending; p != nil {
+		switch {
+		case len(p.Keepers) == 0 || len(p.Keepers) > MaxKeepers:
+			return fmt.Errorf("invalid pending keepers")
+
+		case p.Threshold == 0 || int(p.Threshold) > len(p.Keepers):
+			return fmt.Errorf("invalid pending threshold")
+		}
+
+		if err := p.Keepers.Valid(); err != nil {
+			return fmt.Errorf("invalid pending keepers, %v", err)
+		}
+	}
+	return nil
+}
@@ -4,6 +4,7 @@
 package ld
 
 import (
+	"encoding/json"
 	"math/big"
 	"testing"
 
@@ -98,6 +99,158 @@ func TestAccountLedger(t *testing.T) {
 	}
 	assert.NoError(al.SyntacticVerify())
 
+	al = &AccountLedger{
+		Stake: map[cbor.ByteString]*StakeEntry{
+			ids.GenesisAccount.AsKey(): {
+				LockTime: 999,
+				Amount:   new(big.Int).SetUint64(100),
+				Approver: &key,
+			},
+		},
+		Lending: map[cbor.ByteString]*LendingEntry{
+			ids.GenesisAccount.AsKey(): {
+				Amount:   new(big.Int).SetUint64(100),
+				UpdateAt: 888,
+			},
+		},
+	}
+	assert.NoError(al.SyntacticVerify())
+
+	al = &AccountLedger{
+		Vesting: map[cbor.ByteString]*VestingEntry{
+			ids.GenesisAccount.AsKey(): nil,
+		},
+	}
+	assert.ErrorContains(al.SyntacticVerify(), "nil VestingEntry")
+
+	al = &AccountLedger{
+		Vesting: map[cbor.ByteString]*VestingEntry{
+			ids.GenesisAccount.AsKey(): {Amount: big.NewInt(0), Claimed: big.NewInt(0)},
+		},
+	}
+	assert.ErrorContains(al.SyntacticVerify(), "invalid VestingEntry, invalid amount")
+
+	al = &AccountLedger{
+		Vesting: map[cbor.ByteString]*VestingEntry{
+			ids.GenesisAccount.AsKey(): {Amount: big.NewInt(100), Claimed: big.NewInt(100)},
+		},
+	}
+	assert.ErrorContains(al.SyntacticVerify(), "invalid VestingEntry, invalid claimed")
+
+	al = &AccountLedger{
+		Vesting: map[cbor.ByteString]*VestingEntry{
+			ids.GenesisAccount.AsKey(): {
+				Amount: big.NewInt(100), Claimed: big.NewInt(0), StartTime: 100, CliffTime: 100, EndTime: 100},
+		},
+	}
+	assert.ErrorContains(al.SyntacticVerify(), "invalid VestingEntry, invalid schedule")
+
+	al = &AccountLedger{
+		HTLC: map[cbor.ByteString]*HTLCEntry{
+			ids.GenesisAccount.AsKey(): nil,
+		},
+	}
+	assert.ErrorContains(al.SyntacticVerify(), "nil HTLCEntry")
+
+	al = &AccountLedger{
+		HTLC: map[cbor.ByteString]*HTLCEntry{
+			ids.GenesisAccount.AsKey(): {Amount: big.NewInt(0)},
+		},
+	}
+	assert.ErrorContains(al.SyntacticVerify(), "invalid HTLCEntry, invalid amount")
+
+	al = &AccountLedger{
+		HTLC: map[cbor.ByteString]*HTLCEntry{
+			ids.GenesisAccount.AsKey(): {Amount: big.NewInt(100), HashAlgo: 2},
+		},
+	}
+	assert.ErrorContains(al.SyntacticVerify(), "invalid HTLCEntry, invalid hashAlgo 2")
+
+	al = &AccountLedger{
+		HTLC: map[cbor.ByteString]*HTLCEntry{
+			ids.GenesisAccount.AsKey(): {Amount: big.NewInt(100)},
+		},
+	}
+	assert.ErrorContains(al.SyntacticVerify(), "invalid HTLCEntry, invalid expire")
+
+	al = &AccountLedger{
+		Escrow: map[cbor.ByteString]*EscrowEntry{
+			ids.GenesisAccount.AsKey(): nil,
+		},
+	}
+	assert.ErrorContains(al.SyntacticVerify(), "nil EscrowEntry")
+
+	al = &AccountLedger{
+		Escrow: map[cbor.ByteString]*EscrowEntry{
+			ids.GenesisAccount.AsKey(): {Payee: ids.GenesisAccount},
+		},
+	}
+	assert.ErrorContains(al.SyntacticVerify(), "invalid EscrowEntry, invalid arbiter")
+
+	al = &AccountLedger{
+		Escrow: map[cbor.ByteString]*EscrowEntry{
+			ids.GenesisAccount.AsKey(): {
+				Payee: ids.GenesisAccount, Arbiter: signer.Signer1.Key().Address(), Amount: big.NewInt(0)},
+		},
+	}
+	assert.ErrorContains(al.SyntacticVerify(), "invalid EscrowEntry, invalid amount")
+
+	al = &AccountLedger{
+		Allowance: map[cbor.ByteString]*AllowanceEntry{
+			AllowanceKey(ids.GenesisAccount, ids.NativeToken): {Amount: big.NewInt(0)},
+		},
+	}
+	assert.ErrorContains(al.SyntacticVerify(), "invalid amount on AllowanceEntry")
+
+	al = &AccountLedger{
+		Order: map[cbor.ByteString]*OrderEntry{
+			ids.GenesisAccount.AsKey(): {Remaining: big.NewInt(0)},
+		},
+	}
+	assert.ErrorContains(al.SyntacticVerify(), "invalid remaining on OrderEntry")
+
+	al = &AccountLedger{
+		Schedule: map[cbor.ByteString]*ScheduleEntry{
+			ids.GenesisAccount.AsKey(): {Sender: ids.GenesisAccount},
+		},
+	}
+	assert.ErrorContains(al.SyntacticVerify(), "invalid ScheduleEntry, invalid schedule")
+
+	al = &AccountLedger{
+		Subscription: map[cbor.ByteString]*SubscriptionEntry{
+			AllowanceKey(ids.GenesisAccount, ids.NativeToken): {
+				Limit: big.NewInt(100), Spent: big.NewInt(101)},
+		},
+	}
+	assert.ErrorContains(al.SyntacticVerify(), "invalid SubscriptionEntry, invalid spent")
+
+	al = &AccountLedger{
+		Proposal: map[cbor.ByteString]*ProposalEntry{
+			ids.GenesisAccount.AsKey(): {Tx: []byte{0x80}},
+		},
+	}
+	assert.ErrorContains(al.SyntacticVerify(), "invalid ProposalEntry, invalid expire")
+
+	al = &AccountLedger{Recovery: &RecoveryEntry{}}
+	assert.ErrorContains(al.SyntacticVerify(), "invalid RecoveryEntry, invalid guardians")
+
+	al = &AccountLedger{Recovery: &RecoveryEntry{Guardians: signer.Keys{signer.Signer1.Key()}}}
+	assert.ErrorContains(al.SyntacticVerify(), "invalid RecoveryEntry, invalid threshold")
+
+	al = &AccountLedger{Recovery: &RecoveryEntry{
+		Threshold: 1, Guardians: signer.Keys{signer.Signer1.Key()}}}
+	assert.ErrorContains(al.SyntacticVerify(), "invalid RecoveryEntry, invalid delay")
+
+	al = &AccountLedger{Recovery: &RecoveryEntry{
+		Threshold: 1, Guardians: signer.Keys{signer.Signer1.Key()}, Delay: 100,
+		Pending: &PendingRecovery{Threshold: 1}}}
+	assert.ErrorContains(al.SyntacticVerify(), "invalid RecoveryEntry, invalid pending keepers")
+
+	al = &AccountLedger{Recovery: &RecoveryEntry{
+		Threshold: 1, Guardians: signer.Keys{signer.Signer1.Key()}, Delay: 100,
+		Pending: &PendingRecovery{Keepers: signer.Keys{signer.Signer2.Key()}}}}
+	assert.ErrorContains(al.SyntacticVerify(), "invalid RecoveryEntry, invalid pending threshold")
+
 	al = &AccountLedger{
 		Stake: map[cbor.ByteString]*StakeEntry{
 			ids.GenesisAccount.AsKey(): {
@@ -116,9 +269,140 @@ func TestAccountLedger(t *testing.T) {
 	assert.NoError(al.SyntacticVerify())
 	cbordata, err := al.Marshal()
 	require.NoError(t, err)
+	al.Vesting = map[cbor.ByteString]*VestingEntry{}
+	al.HTLC = map[cbor.ByteString]*HTLCEntry{}
+	al.Escrow = map[cbor.ByteString]*EscrowEntry{}
+	al.Allowance = map[cbor.ByteString]*AllowanceEntry{}
+	al.Order = map[cbor.ByteString]*OrderEntry{}
+	al.Schedule = map[cbor.ByteString]*ScheduleEntry{}
+	al.Subscription = map[cbor.ByteString]*SubscriptionEntry{}
+	al.Proposal = map[cbor.ByteString]*ProposalEntry{}
+	cbordata2, err := al.Marshal()
+	require.NoError(t, err)
+	assert.Equal(cbordata, cbordata2, "empty new entries should be omitted")
+
+	al.Vesting = map[cbor.ByteString]*VestingEntry{
+		ids.GenesisAccount.AsKey(): {
+			Grantor:   ids.GenesisAccount,
+			Token:     ids.NativeToken,
+			Amount:    big.NewInt(1000),
+			Claimed:   big.NewInt(0),
+			StartTime: 100,
+			CliffTime: 200,
+			EndTime:   1100,
+		},
+	}
+	assert.NoError(al.SyntacticVerify())
+	entry := al.Vesting[ids.GenesisAccount.AsKey()]
+	assert.Equal(uint64(0), entry.Vested(199).Uint64())
+	assert.Equal(uint64(100), entry.Vested(200).Uint64())
+	assert.Equal(uint64(500), entry.Vested(600).Uint64())
+	assert.Equal(uint64(1000), entry.Vested(1100).Uint64())
+	entry.Claimed.SetUint64(100)
+	assert.Equal(uint64(400), entry.Releasable(600).Uint64())
+
+	al.HTLC = map[cbor.ByteString]*HTLCEntry{
+		ids.GenesisAccount.AsKey(): {
+			Recipient: ids.GenesisAccount,
+			Token:     ids.NativeToken,
+			Amount:    big.NewInt(1000),
+			HashAlgo:  HashSHA256,
+			Expire:    1000,
+		},
+	}
+	al.Escrow = map[cbor.ByteString]*EscrowEntry{
+		ids.GenesisAccount.AsKey(): {
+			Payee:   ids.GenesisAccount,
+			Arbiter: signer.Signer1.Key().Address(),
+			Token:   ids.NativeToken,
+			Amount:  big.NewInt(1000),
+		},
+	}
+	al.Allowance = map[cbor.ByteString]*AllowanceEntry{
+		AllowanceKey(ids.GenesisAccount, ids.NativeToken): {Amount: big.NewInt(1000), Expire: 1000},
+	}
+	al.Order = map[cbor.ByteString]*OrderEntry{
+		ids.GenesisAccount.AsKey(): {Remaining: big.NewInt(1000), Expire: 1000},
+	}
+	al.Schedule = map[cbor.ByteString]*ScheduleEntry{
+		ids.GenesisAccount.AsKey(): {
+			Sender:  ids.GenesisAccount,
+			Height:  100,
+			Reserve: big.NewInt(1000),
+			Tx:      []byte{0x80},
+		},
+	}
+	al.Subscription = map[cbor.ByteString]*SubscriptionEntry{
+		AllowanceKey(ids.GenesisAccount, ids.NativeToken): {
+			Limit:       big.NewInt(1000),
+			Period:      PeriodDaily,
+			PeriodStart: 86400,
+			Spent:       big.NewInt(400),
+		},
+	}
+	al.Proposal = map[cbor.ByteString]*ProposalEntry{
+		ids.GenesisAccount.AsKey(): {Tx: []byte{0x80}, Expire: 1000},
+		ids.LDCAccount.AsKey():     {Expire: 1000}, // executed
+	}
+	sub := al.Subscription[AllowanceKey(ids.GenesisAccount, ids.NativeToken)]
+	assert.Equal(uint64(600), sub.Remaining(86400*2-1).Uint64())
+	assert.Equal(uint64(1000), sub.Remaining(86400*2).Uint64())
+	al.Recovery = &RecoveryEntry{
+		Threshold: 1,
+		Guardians: signer.Keys{signer.Signer1.Key(), signer.Signer2.Key()},
+		Delay:     100,
+		Pending: &PendingRecovery{
+			Threshold: 1,
+			Keepers:   signer.Keys{signer.Signer2.Key()},
+			ExecuteAt: 1100,
+		},
+	}
+	assert.NoError(al.SyntacticVerify())
+	assert.Equal(40, len(AllowanceKey(ids.GenesisAccount, ids.NativeToken)))
+
+	cbordata, err = al.Marshal()
+	require.NoError(t, err)
 
 	al2 := &AccountLedger{}
 	assert.NoError(al2.Unmarshal(cbordata))
 	assert.NoError(al2.SyntacticVerify())
 	assert.Equal(cbordata, al2.Bytes())
 }
+
+func TestVestingConfig(t *testing.T) {
+	assert := assert.New(t)
+
+	var cfg *VestingConfig
+	assert.ErrorContains(cfg.SyntacticVerify(), "nil pointer")
+
+	cfg = &VestingConfig{}
+	assert.ErrorContains(cfg.SyntacticVerify(), "invalid amount")
+	cfg = &VestingConfig{Amount: new(big.Int)}
+	assert.ErrorContains(cfg.SyntacticVerify(), "invalid amount")
+
+	cfg = &VestingConfig{Amount: big.NewInt(100)}
+	assert.ErrorContains(cfg.SyntacticVerify(), "invalid endTime")
+
+	cfg = &VestingConfig{Amount: big.NewInt(100), CliffTime: 101, EndTime: 100}
+	assert.ErrorContains(cfg.SyntacticVerify(), "invalid cliffTime, expected <= 100, got 101")
+
+	cfg = &VestingConfig{Amount: big.NewInt(100), CliffTime: 100, EndTime: 100}
+	assert.NoError(cfg.SyntacticVerify())
+	cbordata, err := cfg.Marshal()
+	require.NoError(t, err)
+	jsondata, err := json.Marshal(cfg)
+	require.NoError(t, err)
+
+	assert.Equal(`{"amount":100,"cliffTime":100,"endTime":100}`, string(jsondata))
+
+	cfg2 := &VestingConfig{}
+	assert.NoError(cfg2.Unmarshal(cbordata))
+	assert.NoError(cfg2.SyntacticVerify())
+
+	cbordata2, err := cfg2.Marshal()
+	require.NoError(t, err)
+	jsondata2, err := json.Marshal(cfg2)
+	require.NoError(t, err)
+	assert.Equal(string(jsondata), string(jsondata2))
+	assert.Equal(cbordata, cbordata2)
+}
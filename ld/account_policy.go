@@ -0,0 +1,283 @@
+// (c) 2022-2022, LDC Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package ld
+
+import (
+	"math/big"
+
+	"github.com/fxamacker/cbor/v2"
+	"github.com/ldclabs/ldvm/ids"
+	"github.com/ldclabs/ldvm/util/erring"
+)
+
+// MaxPolicyRecipients is the maximum number of recipients in a policy's allowlist.
+const MaxPolicyRecipients = 64
+
+// PolicyWindowSlots is the number of slots that a policy's window is divided into,
+// outflows are recorded per slot so the window rolls over slot by slot.
+const PolicyWindowSlots = 24
+
+// AccountPolicy is the outflow policy of an account, it is checked on every tx sent by the account.
+// A policy without limits and allowlist is empty, it is used to remove the policy.
+type AccountPolicy struct {
+	_ struct{} `cbor:",toarray"`
+
+	// Window is the length in seconds of the rolling window that Limits apply to
+	Window uint64 `json:"window"`
+	// Limits is the maximum outflow per token in a window, tokens not in it are unlimited
+	Limits map[cbor.ByteString]*big.Int `json:"limits"`
+	// Allowlist is the recipients the account can send to, empty means any recipient
+	Allowlist []ids.Address `json:"allowlist"`
+	// Delay is the seconds that the next policy change should wait before taking effect
+	Delay uint64 `json:"delay"`
+}
+
+// SyntacticVerify verifies that a *AccountPolicy is well-formed.
+func (p *AccountPolicy) SyntacticVerify() error {
+	errp := erring.ErrPrefix("ld.AccountPolicy.SyntacticVerify: ")
+
+	switch {
+	case p == nil:
+		return errp.Errorf("nil pointer")
+
+	case len(p.Limits) > 0 && p.Window == 0:
+		return errp.Errorf("invalid window")
+
+	case len(p.Allowlist) > MaxPolicyRecipients:
+		return errp.Errorf("too many recipients, expected <= %d, got %d",
+			MaxPolicyRecipients, len(p.Allowlist))
+	}
+
+	for token, amount := range p.Limits {
+		if amount == nil || amount.Sign() < 0 {
+			return errp.Errorf("invalid limit for %q", token)
+		}
+	}
+
+	set := make(map[ids.Address]struct{}, len(p.Allowlist))
+	for _, addr := range p.Allowlist {
+		if _, ok := set[addr]; ok {
+			return errp.Errorf("duplicate recipient %s", addr)
+		}
+		set[addr] = struct{}{}
+	}
+
+	// changes to a policy must be delayed, an empty policy only removes it
+	if p.Delay == 0 && !p.IsEmpty() {
+		return errp.Errorf("invalid delay, should be > 0")
+	}
+	return nil
+}
+
+// IsEmpty returns true if the policy has no limits nor allowlist.
+func (p *AccountPolicy) IsEmpty() bool {
+	return len(p.Limits) == 0 && len(p.Allowlist) == 0
+}
+
+// Allowed returns true if the account can send to the recipient, nil recipient is not allowed
+// when the allowlist is set.
+func (p *AccountPolicy) Allowed(to *ids.Address) bool {
+	if len(p.Allowlist) == 0 {
+		return true
+	}
+	if to == nil {
+		return false
+	}
+	for _, addr := range p.Allowlist {
+		if addr == *to {
+			return true
+		}
+	}
+	return false
+}
+
+// Clone returns a deep copy of the AccountPolicy.
+func (p *AccountPolicy) Clone() *AccountPolicy {
+	np := &AccountPolicy{
+		Window: p.Window,
+		Delay:  p.Delay,
+	}
+	if p.Limits != nil {
+		np.Limits = make(map[cbor.ByteString]*big.Int, len(p.Limits))
+		for k, v := range p.Limits {
+			np.Limits[k] = new(big.Int).Set(v)
+		}
+	}
+	if p.Allowlist != nil {
+		np.Allowlist = make([]ids.Address, len(p.Allowlist))
+		copy(np.Allowlist, p.Allowlist)
+	}
+	return np
+}
+
+// PolicyOutflow is the outflow of a token in a slot of the policy's window.
+type PolicyOutflow struct {
+	_ struct{} `cbor:",toarray"`
+
+	// Slot is the start timestamp of the slot
+	Slot   uint64          `json:"slot"`
+	Token  ids.TokenSymbol `json:"token"`
+	Amount *big.Int        `json:"amount"`
+}
+
+// PolicyState is the account's active policy, the pending change and the outflows
+// in the rolling window.
+type PolicyState struct {
+	_ struct{} `cbor:",toarray"`
+
+	Active *AccountPolicy `json:"active"`
+	// Pending takes effect at EffectiveAt, an empty Pending removes the policy
+	Pending     *AccountPolicy   `json:"pending"`
+	EffectiveAt uint64           `json:"effectiveAt"`
+	Outflows    []*PolicyOutflow `json:"outflows"`
+}
+
+// SyntacticVerify verifies that a *PolicyState is well-formed.
+func (s *PolicyState) SyntacticVerify() error {
+	errp := erring.ErrPrefix("ld.PolicyState.SyntacticVerify: ")
+
+	switch {
+	case s == nil:
+		return errp.Errorf("nil pointer")
+
+	case s.Active == nil && s.Pending == nil:
+		return errp.Errorf("no active nor pending policy")
+
+	case s.Pending == nil && s.EffectiveAt != 0:
+		return errp.Errorf("invalid effectiveAt, should be 0")
+	}
+
+	if s.Active != nil {
+		if s.Active.IsEmpty() {
+			return errp.Errorf("empty active policy")
+		}
+		if err := s.Active.SyntacticVerify(); err != nil {
+			return errp.ErrorIf(err)
+		}
+	}
+
+	if s.Pending != nil {
+		if err := s.Pending.SyntacticVerify(); err != nil {
+			return errp.ErrorIf(err)
+		}
+	}
+
+	for _, o := range s.Outflows {
+		if o == nil || o.Amount == nil || o.Amount.Sign() <= 0 {
+			return errp.Errorf("invalid outflow")
+		}
+	}
+	return nil
+}
+
+// Policy returns the policy in effect at the timestamp, nil if no policy in effect.
+func (s *PolicyState) Policy(timestamp uint64) *AccountPolicy {
+	p := s.Active
+	if s.Pending != nil && timestamp >= s.EffectiveAt {
+		p = s.Pending
+	}
+	if p == nil || p.IsEmpty() {
+		return nil
+	}
+	return p
+}
+
+// Effective returns the policy in effect at the timestamp and the outflows in
+// the window (timestamp - Window, timestamp], the returned policy is nil if no policy in effect.
+// An outflow is counted as long as its slot overlaps the window.
+func (s *PolicyState) Effective(timestamp uint64) (*AccountPolicy, map[cbor.ByteString]*big.Int) {
+	p := s.Policy(timestamp)
+	if p == nil {
+		return nil, nil
+	}
+
+	var spent map[cbor.ByteString]*big.Int
+	for _, o := range s.Outflows {
+		if !p.inWindow(o.Slot, timestamp) {
+			continue
+		}
+		if spent == nil {
+			spent = make(map[cbor.ByteString]*big.Int, len(p.Limits))
+		}
+		if v := spent[o.Token.AsKey()]; v != nil {
+			v.Add(v, o.Amount)
+		} else {
+			spent[o.Token.AsKey()] = new(big.Int).Set(o.Amount)
+		}
+	}
+	return p, spent
+}
+
+// Spend records the outflow of amount token at the timestamp in the window of the policy
+// in effect, the outflows out of the window are dropped.
+func (s *PolicyState) Spend(timestamp uint64, token ids.TokenSymbol, amount *big.Int) {
+	p := s.Policy(timestamp)
+	if p == nil {
+		return
+	}
+
+	outflows := s.Outflows[:0]
+	for _, o := range s.Outflows {
+		if p.inWindow(o.Slot, timestamp) {
+			outflows = append(outflows, o)
+		}
+	}
+	s.Outflows = outflows
+	if len(s.Outflows) == 0 {
+		s.Outflows = nil
+	}
+	if amount == nil || amount.Sign() <= 0 || p.Limits[token.AsKey()] == nil {
+		return
+	}
+
+	slot := timestamp - timestamp%p.slotSize()
+	for _, o := range s.Outflows {
+		if o.Slot == slot && o.Token == token {
+			o.Amount.Add(o.Amount, amount)
+			return
+		}
+	}
+	s.Outflows = append(s.Outflows, &PolicyOutflow{
+		Slot:   slot,
+		Token:  token,
+		Amount: new(big.Int).Set(amount),
+	})
+}
+
+func (p *AccountPolicy) slotSize() uint64 {
+	size := (p.Window + PolicyWindowSlots - 1) / PolicyWindowSlots
+	if size == 0 {
+		size = 1
+	}
+	return size
+}
+
+// inWindow returns true if the slot overlaps the window (timestamp - Window, timestamp].
+func (p *AccountPolicy) inWindow(slot, timestamp uint64) bool {
+	return timestamp < slot+p.slotSize()+p.Window
+}
+
+// Clone returns a deep copy of the PolicyState.
+func (s *PolicyState) Clone() *PolicyState {
+	ns := &PolicyState{
+		EffectiveAt: s.EffectiveAt,
+	}
+	if s.Active != nil {
+		ns.Active = s.Active.Clone()
+	}
+	if s.Pending != nil {
+		ns.Pending = s.Pending.Clone()
+	}
+	if s.Outflows != nil {
+		ns.Outflows = make([]*PolicyOutflow, len(s.Outflows))
+		for i, o := range s.Outflows {
+			ns.Outflows[i] = &PolicyOutflow{
+				Slot:   o.Slot,
+				Token:  o.Token,
+				Amount: new(big.Int).Set(o.Amount),
+			}
+		}
+	}
+	return ns
+}
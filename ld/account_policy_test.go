@@ -0,0 +1,155 @@
+// (c) 2022-2022, LDC Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package ld
+
+import (
+	"encoding/json"
+	"math/big"
+	"testing"
+
+	"github.com/fxamacker/cbor/v2"
+	"github.com/ldclabs/ldvm/ids"
+	"github.com/ldclabs/ldvm/util/encoding"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAccountPolicy(t *testing.T) {
+	assert := assert.New(t)
+
+	var p *AccountPolicy
+	assert.ErrorContains(p.SyntacticVerify(), "nil pointer")
+
+	p = &AccountPolicy{}
+	assert.NoError(p.SyntacticVerify())
+	assert.True(p.IsEmpty())
+	assert.True(p.Allowed(nil))
+
+	p = &AccountPolicy{Limits: map[cbor.ByteString]*big.Int{ids.NativeToken.AsKey(): big.NewInt(1000)}}
+	assert.ErrorContains(p.SyntacticVerify(), "invalid window")
+
+	p = &AccountPolicy{Window: 100, Limits: map[cbor.ByteString]*big.Int{ids.NativeToken.AsKey(): big.NewInt(-1)}}
+	assert.ErrorContains(p.SyntacticVerify(), `invalid limit for ""`)
+
+	p = &AccountPolicy{Allowlist: make([]ids.Address, MaxPolicyRecipients+1)}
+	assert.ErrorContains(p.SyntacticVerify(), "too many recipients, expected <= 64, got 65")
+
+	p = &AccountPolicy{Allowlist: []ids.Address{{1}, {1}}}
+	assert.ErrorContains(p.SyntacticVerify(), "duplicate recipient "+ids.Address{1}.String())
+
+	p = &AccountPolicy{Allowlist: []ids.Address{{1}}}
+	assert.ErrorContains(p.SyntacticVerify(), "invalid delay, should be > 0")
+
+	p = &AccountPolicy{
+		Window:    100,
+		Limits:    map[cbor.ByteString]*big.Int{ids.NativeToken.AsKey(): big.NewInt(1000)},
+		Allowlist: []ids.Address{{1}, {2}},
+		Delay:     3600,
+	}
+	assert.NoError(p.SyntacticVerify())
+	assert.False(p.IsEmpty())
+	assert.False(p.Allowed(nil))
+	assert.False(p.Allowed(ids.Address{3}.Ptr()))
+	assert.True(p.Allowed(ids.Address{2}.Ptr()))
+
+	jsondata, err := json.Marshal(p)
+	require.NoError(t, err)
+	assert.Equal(`{"window":100,"limits":{"":1000},"allowlist":["0x0100000000000000000000000000000000000000","0x0200000000000000000000000000000000000000"],"delay":3600}`, string(jsondata))
+
+	cbordata, err := encoding.MarshalCBOR(p)
+	require.NoError(t, err)
+	p2 := &AccountPolicy{}
+	assert.NoError(encoding.UnmarshalCBOR(cbordata, p2))
+	assert.NoError(p2.SyntacticVerify())
+	assert.Equal(p, p2)
+
+	p2 = p.Clone()
+	assert.Equal(p, p2)
+	p2.Limits[ids.NativeToken.AsKey()].SetUint64(1)
+	p2.Allowlist[0] = ids.Address{3}
+	assert.Equal(uint64(1000), p.Limits[ids.NativeToken.AsKey()].Uint64())
+	assert.Equal(ids.Address{1}, p.Allowlist[0])
+}
+
+func TestPolicyState(t *testing.T) {
+	assert := assert.New(t)
+
+	var s *PolicyState
+	assert.ErrorContains(s.SyntacticVerify(), "nil pointer")
+
+	s = &PolicyState{}
+	assert.ErrorContains(s.SyntacticVerify(), "no active nor pending policy")
+
+	p := &AccountPolicy{
+		Window: 100,
+		Limits: map[cbor.ByteString]*big.Int{ids.NativeToken.AsKey(): big.NewInt(1000)},
+		Delay:  3600,
+	}
+	s = &PolicyState{Active: p, EffectiveAt: 1}
+	assert.ErrorContains(s.SyntacticVerify(), "invalid effectiveAt, should be 0")
+
+	s = &PolicyState{Active: &AccountPolicy{}}
+	assert.ErrorContains(s.SyntacticVerify(), "empty active policy")
+
+	s = &PolicyState{Active: &AccountPolicy{Allowlist: []ids.Address{{1}, {1}}}}
+	assert.ErrorContains(s.SyntacticVerify(), "duplicate recipient")
+
+	s = &PolicyState{Active: p, Pending: &AccountPolicy{Window: 0, Limits: p.Limits}, EffectiveAt: 5000}
+	assert.ErrorContains(s.SyntacticVerify(), "invalid window")
+
+	s = &PolicyState{Active: p, Outflows: []*PolicyOutflow{{Token: ids.NativeToken}}}
+	assert.ErrorContains(s.SyntacticVerify(), "invalid outflow")
+
+	s = &PolicyState{
+		Active:      p,
+		Pending:     &AccountPolicy{},
+		EffectiveAt: 5000,
+	}
+	assert.NoError(s.SyntacticVerify())
+
+	// the window is divided into slots of 5 seconds
+	s.Spend(1001, ids.NativeToken, big.NewInt(100))
+	s.Spend(1004, ids.NativeToken, big.NewInt(100))
+	s.Spend(1050, ids.NativeToken, big.NewInt(300))
+	s.Spend(1050, MustNewToken("$TEST"), big.NewInt(300))
+	assert.Equal([]*PolicyOutflow{
+		{Slot: 1000, Token: ids.NativeToken, Amount: big.NewInt(200)},
+		{Slot: 1050, Token: ids.NativeToken, Amount: big.NewInt(300)},
+	}, s.Outflows)
+	assert.NoError(s.SyntacticVerify())
+
+	ep, spent := s.Effective(1050)
+	assert.Equal(p, ep)
+	assert.Equal(map[cbor.ByteString]*big.Int{ids.NativeToken.AsKey(): big.NewInt(500)}, spent)
+	// the window rolls over slot by slot
+	ep, spent = s.Effective(1104)
+	assert.Equal(p, ep)
+	assert.Equal(map[cbor.ByteString]*big.Int{ids.NativeToken.AsKey(): big.NewInt(500)}, spent)
+	_, spent = s.Effective(1105)
+	assert.Equal(map[cbor.ByteString]*big.Int{ids.NativeToken.AsKey(): big.NewInt(300)}, spent)
+	_, spent = s.Effective(1155)
+	assert.Nil(spent)
+	ep, spent = s.Effective(5000)
+	assert.Nil(ep)
+	assert.Nil(spent)
+
+	// the outflows out of the window are dropped
+	s.Spend(1110, ids.NativeToken, big.NewInt(1))
+	assert.Equal([]*PolicyOutflow{
+		{Slot: 1050, Token: ids.NativeToken, Amount: big.NewInt(300)},
+		{Slot: 1110, Token: ids.NativeToken, Amount: big.NewInt(1)},
+	}, s.Outflows)
+
+	cbordata, err := encoding.MarshalCBOR(s)
+	require.NoError(t, err)
+	s2 := &PolicyState{}
+	assert.NoError(encoding.UnmarshalCBOR(cbordata, s2))
+	assert.NoError(s2.SyntacticVerify())
+	assert.Equal(s, s2)
+
+	s2 = s.Clone()
+	assert.Equal(s, s2)
+	s2.Outflows[0].Amount.SetUint64(1)
+	assert.Equal(uint64(300), s.Outflows[0].Amount.Uint64())
+}
@@ -135,6 +135,17 @@ func TestAccount(t *testing.T) {
 	acc = &Account{Balance: big.NewInt(0), Threshold: 1, Keepers: signer.Keys{}}
 	assert.ErrorContains(acc.SyntacticVerify(), "invalid threshold")
 
+	acc = &Account{Balance: big.NewInt(0), Threshold: 4,
+		Keepers: signer.Keys{signer.Signer1.Key()}, Weights: []uint16{3}}
+	assert.ErrorContains(acc.SyntacticVerify(), "invalid threshold")
+
+	acc = &Account{Balance: big.NewInt(0), Threshold: 1,
+		Keepers: signer.Keys{signer.Signer1.Key()}, Weights: []uint16{3, 1},
+		Tokens:     make(map[cbor.ByteString]*big.Int),
+		NonceTable: make(map[uint64][]uint64),
+	}
+	assert.ErrorContains(acc.SyntacticVerify(), "invalid weights, signer.Keys.ValidWeights: expected 1 weights, got 2")
+
 	acc = &Account{Balance: big.NewInt(0), Keepers: signer.Keys{}}
 	assert.ErrorContains(acc.SyntacticVerify(), "invalid tokens")
 
@@ -214,6 +225,26 @@ func TestAccount(t *testing.T) {
 	}
 	assert.ErrorContains(acc.SyntacticVerify(), "invalid approveList, duplicate TxType TypeTransfer")
 
+	acc = &Account{
+		Type:        NativeAccount,
+		Balance:     big.NewInt(0),
+		Keepers:     signer.Keys{},
+		Tokens:      make(map[cbor.ByteString]*big.Int),
+		NonceTable:  make(map[uint64][]uint64),
+		SessionKeys: SessionKeys{{Key: signer.Signer2.Key()}},
+	}
+	assert.ErrorContains(acc.SyntacticVerify(), "ld.SessionKey.SyntacticVerify: empty txTypes")
+
+	acc = &Account{
+		Type:       NativeAccount,
+		Balance:    big.NewInt(0),
+		Keepers:    signer.Keys{},
+		Tokens:     make(map[cbor.ByteString]*big.Int),
+		NonceTable: make(map[uint64][]uint64),
+		Policy:     &PolicyState{},
+	}
+	assert.ErrorContains(acc.SyntacticVerify(), "ld.PolicyState.SyntacticVerify: no active nor pending policy")
+
 	acc = &Account{
 		Type:       NativeAccount,
 		Balance:    big.NewInt(0),
@@ -253,6 +284,59 @@ func TestAccount(t *testing.T) {
 	}
 	assert.ErrorContains(acc.SyntacticVerify(), "invalid maxTotalSupply")
 
+	acc = &Account{
+		Type:           TokenAccount,
+		Balance:        big.NewInt(0),
+		Keepers:        signer.Keys{},
+		Tokens:         make(map[cbor.ByteString]*big.Int),
+		NonceTable:     make(map[uint64][]uint64),
+		MaxTotalSupply: big.NewInt(100),
+		SupplyCap:      big.NewInt(99),
+	}
+	assert.ErrorContains(acc.SyntacticVerify(), "invalid supplyCap, expected >= 100, got 99")
+
+	acc = &Account{
+		Type:           TokenAccount,
+		Balance:        big.NewInt(0),
+		Keepers:        signer.Keys{},
+		Tokens:         make(map[cbor.ByteString]*big.Int),
+		NonceTable:     make(map[uint64][]uint64),
+		MaxTotalSupply: big.NewInt(100),
+		BurnedSupply:   big.NewInt(101),
+	}
+	assert.ErrorContains(acc.SyntacticVerify(), "invalid burnedSupply")
+
+	acc = &Account{
+		Type:         NativeAccount,
+		Balance:      big.NewInt(0),
+		Keepers:      signer.Keys{},
+		Tokens:       make(map[cbor.ByteString]*big.Int),
+		NonceTable:   make(map[uint64][]uint64),
+		BurnedSupply: big.NewInt(0),
+	}
+	assert.ErrorContains(acc.SyntacticVerify(), "invalid supplyCap or burnedSupply, should be nil")
+
+	acc = &Account{
+		Type:       NativeAccount,
+		Balance:    big.NewInt(0),
+		Keepers:    signer.Keys{},
+		Tokens:     make(map[cbor.ByteString]*big.Int),
+		NonceTable: make(map[uint64][]uint64),
+		TokenInfo:  &TokenInfo{Name: "LDC"},
+	}
+	assert.ErrorContains(acc.SyntacticVerify(), "invalid tokenInfo, should be nil")
+
+	acc = &Account{
+		Type:           TokenAccount,
+		Balance:        big.NewInt(0),
+		Keepers:        signer.Keys{},
+		Tokens:         make(map[cbor.ByteString]*big.Int),
+		NonceTable:     make(map[uint64][]uint64),
+		MaxTotalSupply: big.NewInt(100),
+		TokenInfo:      &TokenInfo{},
+	}
+	assert.ErrorContains(acc.SyntacticVerify(), "ld.TokenInfo.SyntacticVerify: invalid name")
+
 	acc = &Account{
 		Type:           StakeAccount,
 		Balance:        big.NewInt(0),
@@ -34,6 +34,8 @@ type Block struct {
 	// 80% of total gas rebate are distributed to these stakeAccounts
 	Validators ids.IDList[ids.StakeSymbol] `cbor:"vs" json:"validators"`
 	Txs        ids.IDList[ids.ID32]        `cbor:"txs" json:"txs"`
+	// Results of the due scheduled txs executed at the start of this block, in execution order.
+	Scheduled []*ScheduledResult `cbor:"sch,omitempty" json:"scheduled,omitempty"`
 
 	// external assignment fields
 	ID  ids.ID32 `cbor:"-" json:"id"`
@@ -72,11 +74,14 @@ func (b *Block) SyntacticVerify() error {
 	case len(b.Validators) > 256:
 		return errp.Errorf("too many validators")
 
-	case len(b.Txs) == 0:
+	case len(b.Txs) == 0 && len(b.Scheduled) == 0:
 		return errp.Errorf("no txs")
 
 	case len(b.Txs) > MaxBlockTxsSize:
 		return errp.Errorf("too many txs")
+
+	case len(b.Scheduled) > MaxScheduledTxsPerBlock:
+		return errp.Errorf("too many scheduled txs")
 	}
 
 	for _, s := range b.Validators {
@@ -94,6 +99,12 @@ func (b *Block) SyntacticVerify() error {
 		return errp.Errorf("invalid txs, %s", err.Error())
 	}
 
+	for _, r := range b.Scheduled {
+		if r == nil || r.ID == ids.EmptyID32 {
+			return errp.Errorf("invalid scheduled result")
+		}
+	}
+
 	if b.raw, err = b.Marshal(); err != nil {
 		return errp.ErrorIf(err)
 	}
@@ -102,6 +113,15 @@ func (b *Block) SyntacticVerify() error {
 	return nil
 }
 
+// ScheduledResult is the result of a scheduled tx executed in the block.
+// Err is empty if the scheduled tx applied successfully.
+type ScheduledResult struct {
+	_ struct{} `cbor:",toarray"`
+
+	ID  ids.ID32 `json:"id"`
+	Err string   `json:"error,omitempty"`
+}
+
 func (b *Block) Bytes() []byte {
 	if len(b.raw) == 0 {
 		b.raw = MustMarshal(b)
@@ -101,4 +101,19 @@ func TestBlock(t *testing.T) {
 	assert.NoError(blk2.SyntacticVerify())
 	assert.NotEqual(blk.ID, blk2.ID)
 	assert.NotEqual(cbordata, blk2.Bytes())
+
+	blk2.Scheduled = []*ScheduledResult{{}}
+	assert.ErrorContains(blk2.SyntacticVerify(), "invalid scheduled result")
+	blk2.Scheduled = []*ScheduledResult{{ID: ids.ID32{1}}, {ID: ids.ID32{2}, Err: "some error"}}
+	assert.NoError(blk2.SyntacticVerify())
+	jsondata, err = json.Marshal(blk2)
+	require.NoError(t, err)
+	assert.Contains(string(jsondata), `"scheduled":[{"id":"AQAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAXzYrM"},{"id":`)
+	assert.Contains(string(jsondata), `"error":"some error"}]`)
+
+	blk3 := &Block{}
+	assert.NoError(blk3.Unmarshal(blk2.Bytes()))
+	assert.NoError(blk3.SyntacticVerify())
+	assert.Equal(blk2.ID, blk3.ID)
+	assert.Equal(blk2.Scheduled, blk3.Scheduled)
 }
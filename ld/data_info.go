@@ -26,19 +26,36 @@ var (
 		0, 0, 0, 0, 0, 0, 0, 0, 0, 0,
 		0, 0, 0, 0, 0, 0, 0, 0, 0, 2,
 	}
+	// AAAAAAAAAAAAAAAAAAAAAAAAAAPTOw7f, the payload is an EncryptedPayload
+	EncryptedModelID = ids.ModelID{
+		0, 0, 0, 0, 0, 0, 0, 0, 0, 0,
+		0, 0, 0, 0, 0, 0, 0, 0, 0, 3,
+	}
+)
+
+const (
+	// MaxExpiredDataPerBlock is the maximum number of expired data purged in a block.
+	MaxExpiredDataPerBlock = 64
+	// MaxPurgedVersionsPerBlock is the maximum number of previous versions of
+	// the purged data deleted in a block, the rest are deleted in the next blocks.
+	MaxPurgedVersionsPerBlock = 1024
 )
 
 type DataInfo struct {
 	ModelID ids.ModelID `cbor:"m" json:"mid"` // model id
+	// schema version of the model that the payload was last validated with
+	ModelVersion uint64 `cbor:"mv,omitempty" json:"modelVersion,omitempty"`
 	// data version，the initial value is 1, should increase 1 when updating,
 	// 0 indicates that the data is invalid, for example, deleted or punished.
 	Version uint64 `cbor:"v" json:"version"`
 	// MultiSig: m of n, threshold is m, keepers length is n.
 	// The minimum value is 0, means no one can update the data.
-	// the maximum value is len(keepers)
+	// the maximum value is len(keepers), or the sum of weights when set.
 	Threshold uint16 `cbor:"th" json:"threshold"`
 	// keepers who owned this data, no more than 64
-	Keepers     signer.Keys      `cbor:"kp" json:"keepers"`
+	Keepers signer.Keys `cbor:"kp" json:"keepers"`
+	// optional weights of keepers one by one
+	Weights     []uint16         `cbor:"kw,omitempty" json:"weights,omitempty"`
 	Approver    signer.Key       `cbor:"ap" json:"approver,omitempty"`
 	ApproveList TxTypes          `cbor:"apl" json:"approveList,omitempty"`
 	Payload     encoding.RawData `cbor:"pl" json:"payload"`
@@ -46,6 +63,11 @@ type DataInfo struct {
 	SigClaims *SigClaims `cbor:"sc,omitempty" json:"sigClaims,omitempty"`
 	// data signature signing by a certificate authority
 	Sig *signer.Sig `cbor:"s,omitempty" json:"sig,omitempty"`
+	// sell offer signing by keepers
+	Offer *DataOffer `cbor:"of,omitempty" json:"offer,omitempty"`
+	// unix timestamp (seconds) when the data expires, 0 means never.
+	// Expired data is treated as deleted and purged from the chain lazily.
+	ExpiresAt uint64 `cbor:"ea,omitempty" json:"expiresAt,omitempty"`
 
 	// external assignment fields
 	ID  ids.DataID `cbor:"-" json:"id"`
@@ -57,6 +79,10 @@ func (t *DataInfo) Clone() *DataInfo {
 	*x = *t
 
 	x.Keepers = t.Keepers.Clone()
+	if t.Weights != nil {
+		x.Weights = make([]uint16, len(t.Weights))
+		copy(x.Weights, t.Weights)
+	}
 	if t.Approver != nil {
 		x.Approver = t.Approver.Clone()
 	}
@@ -75,6 +101,9 @@ func (t *DataInfo) Clone() *DataInfo {
 		sig := t.Sig.Clone()
 		x.Sig = &sig
 	}
+	if t.Offer != nil {
+		x.Offer = t.Offer.Clone()
+	}
 	x.raw = nil
 	return x
 }
@@ -91,7 +120,7 @@ func (t *DataInfo) SyntacticVerify() error {
 	case len(t.Keepers) > MaxKeepers:
 		return errp.Errorf("too many keepers")
 
-	case int(t.Threshold) > len(t.Keepers):
+	case uint64(t.Threshold) > t.Keepers.Weight(t.Weights...):
 		return errp.Errorf("invalid threshold")
 
 	case t.SigClaims == nil && t.Sig != nil:
@@ -99,12 +128,19 @@ func (t *DataInfo) SyntacticVerify() error {
 
 	case t.SigClaims != nil && t.Sig == nil:
 		return errp.Errorf("invalid signature")
+
+	case t.Offer != nil && (t.Offer.Amount == nil || t.Offer.Amount.Sign() < 0):
+		return errp.Errorf("invalid offer amount")
 	}
 
 	if err = t.Keepers.Valid(); err != nil {
 		return errp.Errorf("invalid keepers, %v", err)
 	}
 
+	if err = t.Keepers.ValidWeights(t.Weights); err != nil {
+		return errp.Errorf("invalid weights, %v", err)
+	}
+
 	if t.Approver != nil {
 		if err = t.Approver.Valid(); err != nil {
 			return errp.Errorf("invalid approver, %v", err)
@@ -139,11 +175,11 @@ func (t *DataInfo) SyntacticVerify() error {
 }
 
 func (t *DataInfo) Verify(digestHash []byte, sigs signer.Sigs) bool {
-	return t.Keepers.Verify(digestHash, sigs, t.Threshold)
+	return t.Keepers.Verify(digestHash, sigs, t.Threshold, t.Weights...)
 }
 
 func (t *DataInfo) VerifyPlus(digestHash []byte, sigs signer.Sigs) bool {
-	return t.Keepers.VerifyPlus(digestHash, sigs, t.Threshold)
+	return t.Keepers.VerifyPlus(digestHash, sigs, t.Threshold, t.Weights...)
 }
 
 // ValidSigClaims should be called after DataInfo.SyntacticVerify.
@@ -176,10 +212,16 @@ func (t *DataInfo) ValidSigClaims() error {
 	return nil
 }
 
+// IsExpired returns true if the data has expired at the given timestamp.
+func (t *DataInfo) IsExpired(timestamp uint64) bool {
+	return t.ExpiresAt > 0 && t.ExpiresAt <= timestamp
+}
+
 func (t *DataInfo) MarkDeleted(data []byte) error {
 	t.Version = 0
 	t.SigClaims = nil
 	t.Sig = nil
+	t.Offer = nil
 	t.Payload = data
 	return t.SyntacticVerify()
 }
@@ -211,6 +253,13 @@ func (t *DataInfo) Patch(operations []byte) ([]byte, error) {
 			return nil, errp.Errorf("invalid JSON patch, %v", err)
 		}
 
+	case EncryptedModelID:
+		// the ciphertext can't be patched, the whole payload should be replaced
+		if err = ValidEncryptedPayload(operations); err != nil {
+			return nil, errp.ErrorIf(err)
+		}
+		return operations, nil
+
 	default:
 		return nil, errp.Errorf("unsupport mid %s", t.ModelID)
 	}
@@ -5,6 +5,7 @@ package ld
 
 import (
 	"encoding/json"
+	"math/big"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -88,6 +89,12 @@ func TestDataInfo(t *testing.T) {
 	di = &DataInfo{Keepers: signer.Keys{signer.Key(ids.EmptyAddress[:])}}
 	assert.ErrorContains(di.SyntacticVerify(), "empty Secp256k1 key")
 
+	di = &DataInfo{Threshold: 3, Keepers: signer.Keys{signer.Signer1.Key()}, Weights: []uint16{2}}
+	assert.ErrorContains(di.SyntacticVerify(), "invalid threshold")
+
+	di = &DataInfo{Threshold: 1, Keepers: signer.Keys{signer.Signer1.Key()}, Weights: []uint16{2, 1}}
+	assert.ErrorContains(di.SyntacticVerify(), "invalid weights, signer.Keys.ValidWeights: expected 1 weights, got 2")
+
 	di = &DataInfo{Version: 1, Approver: signer.Key(ids.EmptyAddress[:])}
 	assert.ErrorContains(di.SyntacticVerify(), "invalid approver")
 
@@ -124,6 +131,14 @@ func TestDataInfo(t *testing.T) {
 	}
 	assert.ErrorContains(di.SyntacticVerify(), "invalid subject")
 
+	di = &DataInfo{
+		Version: 1,
+		Keepers: signer.Keys{signer.Signer1.Key()},
+		Payload: []byte(`42`),
+		Offer:   &DataOffer{},
+	}
+	assert.ErrorContains(di.SyntacticVerify(), "invalid offer amount")
+
 	di = &DataInfo{
 		Version: 0,
 		Payload: []byte(`42`),
@@ -182,6 +197,43 @@ func TestDataInfo(t *testing.T) {
 	// fmt.Println(string(jsondata))
 	assert.Equal(`{"mid":"AAAAAAAAAAAAAAAAAAAAAAAAAADzaDye","version":1,"threshold":1,"keepers":["jbl8fOziScK5i9wCJsxMKle_UvwKxwPH","RBccN_9de3u43K1cgfFihKIp5kE1lmGG"],"payload":42,"sigClaims":{"iss":"AQIDBAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAACs148t","sub":"BQYHCAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAADlPJnM","aud":"AAAAAAAAAAAAAAAAAAAAAAAAAADzaDye","exp":100,"nbf":0,"iat":1,"cti":"CQoLDAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAARjcYE"},"sig":"7w8M6jpY9hoXreRwKm5iYvk5KOy-ROsLbSPuxK3isHojBY-GZpqfGR0l33JmexKnUojpUwJkO_ZtToK5c1tYMgELIV4C","id":"AAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAACeYpGX"}`, string(jsondata))
 
+	di4 := di2.Clone()
+	di4.Offer = &DataOffer{
+		Seller:  signer.Signer1.Key().Address(),
+		Buyer:   signer.Signer2.Key().Address().Ptr(),
+		Amount:  big.NewInt(1000),
+		Expire:  100,
+		Version: 1,
+	}
+	assert.NoError(di4.SyntacticVerify())
+	di5 := di4.Clone()
+	assert.Equal(di4.Bytes(), di5.Bytes())
+	di5.Offer.Amount.SetUint64(999)
+	*di5.Offer.Buyer = ids.GenesisAccount
+	assert.Equal(uint64(1000), di4.Offer.Amount.Uint64())
+	assert.Equal(signer.Signer2.Key().Address(), *di4.Offer.Buyer)
+	jsondata, err = json.Marshal(di4)
+	require.NoError(t, err)
+	assert.Contains(string(jsondata), `"offer":{"seller":"0x8db97c7cECe249C2b98bdc0226cc4C2A57bF52fc","buyer":"0x44171C37Ff5D7B7bb8Dcad5C81f16284A229E641","token":"","amount":1000,"expire":100,"version":1}`)
+
+	di6 := di2.Clone()
+	assert.False(di6.IsExpired(1000))
+	di6.ExpiresAt = 1000
+	assert.NoError(di6.SyntacticVerify())
+	assert.False(di6.IsExpired(999))
+	assert.True(di6.IsExpired(1000))
+	assert.NotEqual(di2.Bytes(), di6.Bytes())
+	jsondata, err = json.Marshal(di6)
+	require.NoError(t, err)
+	assert.Contains(string(jsondata), `"expiresAt":1000`)
+	di7 := &DataInfo{}
+	assert.NoError(di7.Unmarshal(di6.Bytes()))
+	assert.NoError(di7.SyntacticVerify())
+	assert.Equal(uint64(1000), di7.ExpiresAt)
+
+	assert.NoError(di4.MarkDeleted(nil))
+	assert.Nil(di4.Offer)
+
 	assert.NoError(di.MarkDeleted(nil))
 	assert.Equal(uint64(0), di.Version)
 	assert.Equal(ids.EmptyModelID, di.ModelID)
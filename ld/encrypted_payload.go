@@ -0,0 +1,291 @@
+// (c) 2022-2022, LDC Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package ld
+
+import (
+	"bytes"
+	"crypto/rand"
+	"crypto/sha256"
+	"fmt"
+	"io"
+	"sort"
+
+	"golang.org/x/crypto/chacha20poly1305"
+	"golang.org/x/crypto/curve25519"
+	"golang.org/x/crypto/hkdf"
+
+	"github.com/ldclabs/ldvm/util/encoding"
+	"github.com/ldclabs/ldvm/util/erring"
+)
+
+const (
+	// MaxEncryptedReaders is the maximum number of readers of an EncryptedPayload.
+	MaxEncryptedReaders = 64
+	// MaxEncryptedDataSize is the maximum size of the ciphertext of an EncryptedPayload.
+	MaxEncryptedDataSize = 512 * 1024
+)
+
+var envelopeInfo = []byte("ldvm encrypted payload envelope")
+
+// EncryptedPayload is the payload of a DataInfo with EncryptedModelID.
+// The plaintext is encrypted with a random content key by XChaCha20-Poly1305,
+// and the content key is wrapped for each reader's X25519 public key.
+// Validators verify the structure only and never see the plaintext.
+type EncryptedPayload struct {
+	Nonce      []byte         `cbor:"n" json:"nonce"`
+	Envelopes  []*KeyEnvelope `cbor:"e" json:"envelopes"`
+	Ciphertext []byte         `cbor:"c" json:"ciphertext"`
+
+	// external assignment fields
+	raw []byte `cbor:"-" json:"-"`
+}
+
+// KeyEnvelope is the content key wrapped for a reader.
+// The key encryption key is derived by HKDF-SHA256 from the X25519 shared secret
+// between an ephemeral key and the reader's key, so it is never reused.
+type KeyEnvelope struct {
+	Reader     []byte `cbor:"r" json:"reader"`     // X25519 public key of the reader
+	Ephemeral  []byte `cbor:"ep" json:"ephemeral"` // X25519 ephemeral public key
+	WrappedKey []byte `cbor:"k" json:"wrappedKey"`
+}
+
+// SyntacticVerify verifies that a *EncryptedPayload is well-formed.
+func (p *EncryptedPayload) SyntacticVerify() error {
+	errp := erring.ErrPrefix("ld.EncryptedPayload.SyntacticVerify: ")
+
+	switch {
+	case p == nil:
+		return errp.Errorf("nil pointer")
+
+	case len(p.Nonce) != chacha20poly1305.NonceSizeX:
+		return errp.Errorf("invalid nonce")
+
+	case len(p.Ciphertext) < chacha20poly1305.Overhead:
+		return errp.Errorf("invalid ciphertext")
+
+	case len(p.Ciphertext) > MaxEncryptedDataSize:
+		return errp.Errorf("ciphertext too large, expected <= %d, got %d",
+			MaxEncryptedDataSize, len(p.Ciphertext))
+
+	case len(p.Envelopes) == 0:
+		return errp.Errorf("empty envelopes")
+
+	case len(p.Envelopes) > MaxEncryptedReaders:
+		return errp.Errorf("too many envelopes, expected <= %d, got %d",
+			MaxEncryptedReaders, len(p.Envelopes))
+	}
+
+	for i, e := range p.Envelopes {
+		switch {
+		case e == nil:
+			return errp.Errorf("nil envelope at %d", i)
+
+		case len(e.Reader) != curve25519.PointSize:
+			return errp.Errorf("invalid reader at %d", i)
+
+		case len(e.Ephemeral) != curve25519.PointSize:
+			return errp.Errorf("invalid ephemeral key at %d", i)
+
+		case len(e.WrappedKey) != chacha20poly1305.KeySize+chacha20poly1305.Overhead:
+			return errp.Errorf("invalid wrapped key at %d", i)
+
+		case i > 0 && bytes.Compare(p.Envelopes[i-1].Reader, e.Reader) >= 0:
+			return errp.Errorf("envelopes should be sorted by reader and no duplicate, invalid reader at %d", i)
+		}
+	}
+
+	var err error
+	if p.raw, err = p.Marshal(); err != nil {
+		return errp.ErrorIf(err)
+	}
+	return nil
+}
+
+// Readers returns the X25519 public keys of the readers.
+func (p *EncryptedPayload) Readers() [][]byte {
+	rs := make([][]byte, 0, len(p.Envelopes))
+	for _, e := range p.Envelopes {
+		rs = append(rs, e.Reader)
+	}
+	return rs
+}
+
+// Open decrypts the payload with the reader's X25519 private key.
+func (p *EncryptedPayload) Open(readerKey []byte) ([]byte, error) {
+	errp := erring.ErrPrefix("ld.EncryptedPayload.Open: ")
+
+	cek, err := p.contentKey(readerKey)
+	if err != nil {
+		return nil, errp.ErrorIf(err)
+	}
+
+	aead, err := chacha20poly1305.NewX(cek)
+	if err != nil {
+		return nil, errp.ErrorIf(err)
+	}
+	return errp.ErrorMap(aead.Open(nil, p.Nonce, p.Ciphertext, nil))
+}
+
+// RotateReaders decrypts the payload with an existing reader's X25519 private key,
+// and seals it again with a new content key for the given readers.
+// Removed readers can not open the rotated payload.
+func (p *EncryptedPayload) RotateReaders(readerKey []byte, readers ...[]byte) (*EncryptedPayload, error) {
+	errp := erring.ErrPrefix("ld.EncryptedPayload.RotateReaders: ")
+
+	plaintext, err := p.Open(readerKey)
+	if err != nil {
+		return nil, errp.ErrorIf(err)
+	}
+	np, err := SealPayload(plaintext, readers...)
+	if err != nil {
+		return nil, errp.ErrorIf(err)
+	}
+	return np, nil
+}
+
+func (p *EncryptedPayload) contentKey(readerKey []byte) ([]byte, error) {
+	reader, err := X25519PublicKey(readerKey)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, e := range p.Envelopes {
+		if bytes.Equal(e.Reader, reader) {
+			kek, err := envelopeKey(readerKey, e.Ephemeral, e.Ephemeral, reader)
+			if err != nil {
+				return nil, err
+			}
+			aead, err := chacha20poly1305.NewX(kek)
+			if err != nil {
+				return nil, err
+			}
+			return aead.Open(nil, make([]byte, aead.NonceSize()), e.WrappedKey, e.Reader)
+		}
+	}
+	return nil, fmt.Errorf("no envelope for reader %x", reader)
+}
+
+func (p *EncryptedPayload) Bytes() []byte {
+	if len(p.raw) == 0 {
+		p.raw = MustMarshal(p)
+	}
+	return p.raw
+}
+
+func (p *EncryptedPayload) Unmarshal(data []byte) error {
+	return erring.ErrPrefix("ld.EncryptedPayload.Unmarshal: ").
+		ErrorIf(encoding.UnmarshalCBOR(data, p))
+}
+
+func (p *EncryptedPayload) Marshal() ([]byte, error) {
+	return erring.ErrPrefix("ld.EncryptedPayload.Marshal: ").
+		ErrorMap(encoding.MarshalCBOR(p))
+}
+
+// ValidEncryptedPayload verifies that the data is a well-formed EncryptedPayload.
+func ValidEncryptedPayload(data []byte) error {
+	p := &EncryptedPayload{}
+	if err := p.Unmarshal(data); err != nil {
+		return err
+	}
+	return p.SyntacticVerify()
+}
+
+// SealPayload encrypts the plaintext for the readers' X25519 public keys.
+func SealPayload(plaintext []byte, readers ...[]byte) (*EncryptedPayload, error) {
+	errp := erring.ErrPrefix("ld.SealPayload: ")
+
+	cek := make([]byte, chacha20poly1305.KeySize)
+	if _, err := io.ReadFull(rand.Reader, cek); err != nil {
+		return nil, errp.ErrorIf(err)
+	}
+	aead, err := chacha20poly1305.NewX(cek)
+	if err != nil {
+		return nil, errp.ErrorIf(err)
+	}
+
+	p := &EncryptedPayload{
+		Nonce:     make([]byte, aead.NonceSize()),
+		Envelopes: make([]*KeyEnvelope, 0, len(readers)),
+	}
+	if _, err := io.ReadFull(rand.Reader, p.Nonce); err != nil {
+		return nil, errp.ErrorIf(err)
+	}
+	p.Ciphertext = aead.Seal(nil, p.Nonce, plaintext, nil)
+
+	for _, reader := range readers {
+		e, err := wrapKey(cek, reader)
+		if err != nil {
+			return nil, errp.ErrorIf(err)
+		}
+		p.Envelopes = append(p.Envelopes, e)
+	}
+	sort.SliceStable(p.Envelopes, func(i, j int) bool {
+		return bytes.Compare(p.Envelopes[i].Reader, p.Envelopes[j].Reader) < 0
+	})
+
+	if err := p.SyntacticVerify(); err != nil {
+		return nil, errp.ErrorIf(err)
+	}
+	return p, nil
+}
+
+// GenerateX25519Key generates a X25519 private key for a reader.
+func GenerateX25519Key() ([]byte, error) {
+	key := make([]byte, curve25519.ScalarSize)
+	if _, err := io.ReadFull(rand.Reader, key); err != nil {
+		return nil, erring.ErrPrefix("ld.GenerateX25519Key: ").ErrorIf(err)
+	}
+	return key, nil
+}
+
+// X25519PublicKey returns the X25519 public key of the private key.
+func X25519PublicKey(key []byte) ([]byte, error) {
+	return erring.ErrPrefix("ld.X25519PublicKey: ").
+		ErrorMap(curve25519.X25519(key, curve25519.Basepoint))
+}
+
+func wrapKey(cek, reader []byte) (*KeyEnvelope, error) {
+	ephemeralKey, err := GenerateX25519Key()
+	if err != nil {
+		return nil, err
+	}
+	ephemeral, err := X25519PublicKey(ephemeralKey)
+	if err != nil {
+		return nil, err
+	}
+	kek, err := envelopeKey(ephemeralKey, reader, ephemeral, reader)
+	if err != nil {
+		return nil, err
+	}
+	aead, err := chacha20poly1305.NewX(kek)
+	if err != nil {
+		return nil, err
+	}
+
+	return &KeyEnvelope{
+		Reader:    reader,
+		Ephemeral: ephemeral,
+		// the kek is used only once, so a zero nonce is safe
+		WrappedKey: aead.Seal(nil, make([]byte, aead.NonceSize()), cek, reader),
+	}, nil
+}
+
+// envelopeKey derives the key encryption key from the X25519 shared secret,
+// bound to both the ephemeral public key and the reader's public key.
+func envelopeKey(key, peer, ephemeral, reader []byte) ([]byte, error) {
+	secret, err := curve25519.X25519(key, peer)
+	if err != nil {
+		return nil, err
+	}
+
+	salt := make([]byte, 0, len(ephemeral)+len(reader))
+	salt = append(salt, ephemeral...)
+	salt = append(salt, reader...)
+	kek := make([]byte, chacha20poly1305.KeySize)
+	if _, err = io.ReadFull(hkdf.New(sha256.New, secret, salt, envelopeInfo), kek); err != nil {
+		return nil, err
+	}
+	return kek, nil
+}
@@ -0,0 +1,133 @@
+// (c) 2022-2022, LDC Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package ld
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEncryptedPayload(t *testing.T) {
+	assert := assert.New(t)
+
+	var p *EncryptedPayload
+	assert.ErrorContains(p.SyntacticVerify(), "nil pointer")
+
+	p = &EncryptedPayload{}
+	assert.ErrorContains(p.SyntacticVerify(), "invalid nonce")
+
+	p = &EncryptedPayload{Nonce: make([]byte, 24)}
+	assert.ErrorContains(p.SyntacticVerify(), "invalid ciphertext")
+
+	p = &EncryptedPayload{Nonce: make([]byte, 24), Ciphertext: make([]byte, MaxEncryptedDataSize+1)}
+	assert.ErrorContains(p.SyntacticVerify(), "ciphertext too large, expected <= 524288, got 524289")
+
+	p = &EncryptedPayload{Nonce: make([]byte, 24), Ciphertext: make([]byte, 16)}
+	assert.ErrorContains(p.SyntacticVerify(), "empty envelopes")
+
+	p = &EncryptedPayload{Nonce: make([]byte, 24), Ciphertext: make([]byte, 16),
+		Envelopes: make([]*KeyEnvelope, MaxEncryptedReaders+1)}
+	assert.ErrorContains(p.SyntacticVerify(), "too many envelopes, expected <= 64, got 65")
+
+	p = &EncryptedPayload{Nonce: make([]byte, 24), Ciphertext: make([]byte, 16),
+		Envelopes: []*KeyEnvelope{nil}}
+	assert.ErrorContains(p.SyntacticVerify(), "nil envelope at 0")
+
+	p = &EncryptedPayload{Nonce: make([]byte, 24), Ciphertext: make([]byte, 16),
+		Envelopes: []*KeyEnvelope{{}}}
+	assert.ErrorContains(p.SyntacticVerify(), "invalid reader at 0")
+
+	p = &EncryptedPayload{Nonce: make([]byte, 24), Ciphertext: make([]byte, 16),
+		Envelopes: []*KeyEnvelope{{Reader: make([]byte, 32)}}}
+	assert.ErrorContains(p.SyntacticVerify(), "invalid ephemeral key at 0")
+
+	p = &EncryptedPayload{Nonce: make([]byte, 24), Ciphertext: make([]byte, 16),
+		Envelopes: []*KeyEnvelope{{Reader: make([]byte, 32), Ephemeral: make([]byte, 32)}}}
+	assert.ErrorContains(p.SyntacticVerify(), "invalid wrapped key at 0")
+
+	e := &KeyEnvelope{Reader: make([]byte, 32), Ephemeral: make([]byte, 32), WrappedKey: make([]byte, 48)}
+	p = &EncryptedPayload{Nonce: make([]byte, 24), Ciphertext: make([]byte, 16),
+		Envelopes: []*KeyEnvelope{e, e}}
+	assert.ErrorContains(p.SyntacticVerify(),
+		"envelopes should be sorted by reader and no duplicate, invalid reader at 1")
+
+	p = &EncryptedPayload{Nonce: make([]byte, 24), Ciphertext: make([]byte, 16),
+		Envelopes: []*KeyEnvelope{e}}
+	assert.NoError(p.SyntacticVerify())
+	assert.NoError(ValidEncryptedPayload(p.Bytes()))
+	assert.ErrorContains(ValidEncryptedPayload(p.Bytes()[1:]), "ld.EncryptedPayload.Unmarshal")
+	assert.ErrorContains(ValidEncryptedPayload([]byte{0xa0}), "invalid nonce")
+}
+
+func TestSealPayload(t *testing.T) {
+	assert := assert.New(t)
+
+	keys := make([][]byte, 3)
+	readers := make([][]byte, 3)
+	for i := range keys {
+		var err error
+		keys[i], err = GenerateX25519Key()
+		require.NoError(t, err)
+		readers[i], err = X25519PublicKey(keys[i])
+		require.NoError(t, err)
+	}
+
+	_, err := SealPayload([]byte("secret"))
+	assert.ErrorContains(err, "empty envelopes")
+	_, err = SealPayload([]byte("secret"), readers[0][1:])
+	assert.ErrorContains(err, "ld.SealPayload: ")
+	_, err = SealPayload([]byte("secret"), readers[0], readers[0])
+	assert.ErrorContains(err, "no duplicate")
+
+	p, err := SealPayload([]byte("secret"), readers[0], readers[1])
+	require.NoError(t, err)
+	assert.Equal(2, len(p.Readers()))
+	assert.True(bytes.Compare(p.Readers()[0], p.Readers()[1]) < 0)
+	assert.NotContains(string(p.Bytes()), "secret")
+
+	p2 := &EncryptedPayload{}
+	assert.NoError(p2.Unmarshal(p.Bytes()))
+	assert.NoError(p2.SyntacticVerify())
+	assert.Equal(p.Bytes(), p2.Bytes())
+
+	data, err := p2.Open(keys[0])
+	require.NoError(t, err)
+	assert.Equal([]byte("secret"), data)
+	data, err = p2.Open(keys[1])
+	require.NoError(t, err)
+	assert.Equal([]byte("secret"), data)
+	_, err = p2.Open(keys[2])
+	assert.ErrorContains(err, "no envelope for reader")
+
+	// tampered ciphertext
+	p2.Ciphertext[0] ^= 1
+	_, err = p2.Open(keys[0])
+	assert.ErrorContains(err, "message authentication failed")
+	_, err = p2.Open(keys[1])
+	assert.ErrorContains(err, "message authentication failed")
+	p2.Ciphertext[0] ^= 1
+
+	// tampered envelope
+	for _, e := range p2.Envelopes {
+		e.WrappedKey[0] ^= 1
+	}
+	_, err = p2.Open(keys[0])
+	assert.ErrorContains(err, "message authentication failed")
+
+	// rotate readers
+	_, err = p2.RotateReaders(keys[2], readers[2])
+	assert.ErrorContains(err, "no envelope for reader")
+
+	p3, err := p.RotateReaders(keys[0], readers[0], readers[2])
+	require.NoError(t, err)
+	assert.NotEqual(p.Ciphertext, p3.Ciphertext)
+	_, err = p3.Open(keys[1])
+	assert.ErrorContains(err, "no envelope for reader")
+	data, err = p3.Open(keys[2])
+	require.NoError(t, err)
+	assert.Equal([]byte("secret"), data)
+}
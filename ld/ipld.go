@@ -6,6 +6,7 @@ package ld
 import (
 	"bytes"
 	"fmt"
+	"reflect"
 	"sync"
 
 	ipld "github.com/ipld/go-ipld-prime"
@@ -71,6 +72,136 @@ func (l *IPLDModel) Type() schema.Type {
 	return l.schemaType
 }
 
+// CheckCompatible checks that the model is backward compatible with the prev model,
+// so that data valid with the prev model is still valid with the model:
+// types and representations should not change, fields should not be removed,
+// and new struct fields should be optional.
+func (l *IPLDModel) CheckCompatible(prev *IPLDModel) error {
+	errp := erring.ErrPrefix(fmt.Sprintf("ld.IPLDModel(%q).CheckCompatible: ", l.name))
+
+	if prev.name != l.name {
+		return errp.Errorf("invalid model name, expected %q, got %q", prev.name, l.name)
+	}
+	return errp.ErrorIf(checkCompatibleType(prev.schemaType, l.schemaType, l.name,
+		make(map[[2]string]struct{})))
+}
+
+func checkCompatibleType(prev, next schema.Type, path string, seen map[[2]string]struct{}) error {
+	key := [2]string{prev.Name(), next.Name()}
+	if _, ok := seen[key]; ok {
+		return nil
+	}
+	seen[key] = struct{}{}
+
+	if prev.TypeKind() != next.TypeKind() {
+		return fmt.Errorf("type of %s changed from %s to %s", path, prev.TypeKind(), next.TypeKind())
+	}
+
+	switch pt := prev.(type) {
+	case *schema.TypeStruct:
+		nt := next.(*schema.TypeStruct)
+		pr, nr := pt.RepresentationStrategy(), nt.RepresentationStrategy()
+		if reflect.TypeOf(pr) != reflect.TypeOf(nr) {
+			return fmt.Errorf("representation of %s changed", path)
+		}
+		prm, isMap := pr.(schema.StructRepresentation_Map)
+		if !isMap && len(nt.Fields()) != len(pt.Fields()) {
+			return fmt.Errorf("fields of %s changed, only map representation can add fields", path)
+		}
+
+		for i, pf := range pt.Fields() {
+			fpath := path + "." + pf.Name()
+			nf := nt.Field(pf.Name())
+			switch {
+			case nf == nil:
+				return fmt.Errorf("field %s removed", fpath)
+
+			case !isMap && nt.Fields()[i].Name() != pf.Name():
+				return fmt.Errorf("field %s moved", fpath)
+
+			case nf.IsOptional() != pf.IsOptional() || nf.IsNullable() != pf.IsNullable():
+				return fmt.Errorf("optional or nullable of field %s changed", fpath)
+
+			case isMap && nr.(schema.StructRepresentation_Map).GetFieldKey(*nf) != prm.GetFieldKey(pf):
+				return fmt.Errorf("key of field %s changed", fpath)
+			}
+
+			if err := checkCompatibleType(pf.Type(), nf.Type(), fpath, seen); err != nil {
+				return err
+			}
+		}
+
+		for _, nf := range nt.Fields() {
+			if pt.Field(nf.Name()) == nil && !nf.IsOptional() {
+				return fmt.Errorf("new field %s.%s should be optional", path, nf.Name())
+			}
+		}
+
+	case *schema.TypeMap:
+		nt := next.(*schema.TypeMap)
+		if nt.ValueIsNullable() != pt.ValueIsNullable() {
+			return fmt.Errorf("nullable of %s values changed", path)
+		}
+		if err := checkCompatibleType(pt.KeyType(), nt.KeyType(), path+"{key}", seen); err != nil {
+			return err
+		}
+		return checkCompatibleType(pt.ValueType(), nt.ValueType(), path+"{value}", seen)
+
+	case *schema.TypeList:
+		nt := next.(*schema.TypeList)
+		if nt.ValueIsNullable() != pt.ValueIsNullable() {
+			return fmt.Errorf("nullable of %s values changed", path)
+		}
+		return checkCompatibleType(pt.ValueType(), nt.ValueType(), path+"[]", seen)
+
+	case *schema.TypeUnion:
+		nt := next.(*schema.TypeUnion)
+		if reflect.TypeOf(pt.RepresentationStrategy()) != reflect.TypeOf(nt.RepresentationStrategy()) {
+			return fmt.Errorf("representation of %s changed", path)
+		}
+		pm, nm := pt.Members(), nt.Members()
+		if len(pm) != len(nm) {
+			return fmt.Errorf("members of %s changed", path)
+		}
+		for i := range pm {
+			if err := checkCompatibleType(pm[i], nm[i], path+"|"+pm[i].Name(), seen); err != nil {
+				return err
+			}
+		}
+
+	case *schema.TypeEnum:
+		nt := next.(*schema.TypeEnum)
+		pr, nr := pt.RepresentationStrategy(), nt.RepresentationStrategy()
+		if reflect.TypeOf(pr) != reflect.TypeOf(nr) {
+			return fmt.Errorf("representation of %s changed", path)
+		}
+		members := make(map[string]struct{}, len(nt.Members()))
+		for _, m := range nt.Members() {
+			members[m] = struct{}{}
+		}
+		for _, m := range pt.Members() {
+			if _, ok := members[m]; !ok {
+				return fmt.Errorf("member %s of %s removed", m, path)
+			}
+		}
+		switch r := pr.(type) {
+		case schema.EnumRepresentation_String:
+			for m, v := range r {
+				if nr.(schema.EnumRepresentation_String)[m] != v {
+					return fmt.Errorf("representation of member %s of %s changed", m, path)
+				}
+			}
+		case schema.EnumRepresentation_Int:
+			for m, v := range r {
+				if nr.(schema.EnumRepresentation_Int)[m] != v {
+					return fmt.Errorf("representation of member %s of %s changed", m, path)
+				}
+			}
+		}
+	}
+	return nil
+}
+
 func (l *IPLDModel) Decode(doc []byte) (node datamodel.Node, err error) {
 	errp := erring.ErrPrefix(fmt.Sprintf("ld.IPLDModel(%q).Decode: ", l.name))
 
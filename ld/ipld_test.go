@@ -140,3 +140,158 @@ func TestIPLDModelApplyPatch(t *testing.T) {
 	assert.ErrorContains(err,
 		`invalid key: "x" is not a field in type ProfileService`)
 }
+
+func TestIPLDModelCheckCompatible(t *testing.T) {
+	assert := assert.New(t)
+
+	sc := `
+	type Status enum {
+		| Active ("a")
+		| Closed ("c")
+	} representation string
+
+	type Profile struct {
+		name   String          (rename "n")
+		tags   [String]        (rename "ts")
+		attrs  {String:Int}    (rename "as")
+		status Status          (rename "s")
+		memo   optional String (rename "m")
+	}
+`
+	prev, err := NewIPLDModel("Profile", sc)
+	require.NoError(t, err)
+	data, err := encoding.MarshalCBOR(map[string]any{
+		"n": "test", "ts": []string{"a"}, "as": map[string]int{"a": 1}, "s": "a"})
+	require.NoError(t, err)
+	assert.NoError(prev.Valid(data))
+	assert.NoError(prev.CheckCompatible(prev))
+
+	im, err := NewIPLDModel("Profile2", `type Profile2 {String:Any}`)
+	require.NoError(t, err)
+	assert.ErrorContains(im.CheckCompatible(prev),
+		`ld.IPLDModel("Profile2").CheckCompatible: invalid model name, expected "Profile", got "Profile2"`)
+
+	for _, c := range []struct {
+		sc  string
+		err string
+	}{
+		{`type Profile {String:Any}`, "type of Profile changed from struct to map"},
+		{`type Profile struct {
+			name String (rename "n")
+		}`, "field Profile.tags removed"},
+		{`type Status enum {
+			| Active ("a")
+			| Closed ("c")
+		} representation string
+		type Profile struct {
+			name   String          (rename "n")
+			tags   [String]        (rename "ts")
+			attrs  {String:Int}    (rename "as")
+			status Status          (rename "s")
+			memo   optional String (rename "m")
+			age    Int             (rename "ag")
+		}`, "new field Profile.age should be optional"},
+		{`type Status enum {
+			| Active ("a")
+			| Closed ("c")
+		} representation string
+		type Profile struct {
+			name   String          (rename "name")
+			tags   [String]        (rename "ts")
+			attrs  {String:Int}    (rename "as")
+			status Status          (rename "s")
+			memo   optional String (rename "m")
+		}`, "key of field Profile.name changed"},
+		{`type Status enum {
+			| Active ("a")
+			| Closed ("c")
+		} representation string
+		type Profile struct {
+			name   String          (rename "n")
+			tags   [Int]           (rename "ts")
+			attrs  {String:Int}    (rename "as")
+			status Status          (rename "s")
+			memo   optional String (rename "m")
+		}`, "type of Profile.tags[] changed from string to int"},
+		{`type Status enum {
+			| Active ("a")
+			| Closed ("c")
+		} representation string
+		type Profile struct {
+			name   String          (rename "n")
+			tags   [String]        (rename "ts")
+			attrs  {String:Float}  (rename "as")
+			status Status          (rename "s")
+			memo   optional String (rename "m")
+		}`, "type of Profile.attrs{value} changed from int to float"},
+		{`type Status enum {
+			| Active ("a")
+			| Closed ("c")
+		} representation string
+		type Profile struct {
+			name   String          (rename "n")
+			tags   [String]        (rename "ts")
+			attrs  {String:Int}    (rename "as")
+			status Status          (rename "s")
+			memo   String          (rename "m")
+		}`, "optional or nullable of field Profile.memo changed"},
+		{`type Status enum {
+			| Active ("a")
+		} representation string
+		type Profile struct {
+			name   String          (rename "n")
+			tags   [String]        (rename "ts")
+			attrs  {String:Int}    (rename "as")
+			status Status          (rename "s")
+			memo   optional String (rename "m")
+		}`, "member Closed of Profile.status removed"},
+		{`type Status enum {
+			| Active ("a")
+			| Closed ("x")
+		} representation string
+		type Profile struct {
+			name   String          (rename "n")
+			tags   [String]        (rename "ts")
+			attrs  {String:Int}    (rename "as")
+			status Status          (rename "s")
+			memo   optional String (rename "m")
+		}`, "representation of member Closed of Profile.status changed"},
+		{`type Status enum {
+			| Active ("a")
+			| Closed ("c")
+		} representation string
+		type Profile struct {
+			name   String
+			tags   [String]
+			attrs  {String:Int}
+			status Status
+			memo   optional String
+		} representation tuple`, "representation of Profile changed"},
+	} {
+		im, err := NewIPLDModel("Profile", c.sc)
+		require.NoError(t, err, c.sc)
+		assert.ErrorContains(im.CheckCompatible(prev), c.err, c.sc)
+	}
+
+	// new optional fields and enum members, renamed types
+	im, err = NewIPLDModel("Profile", `
+	type State enum {
+		| Active ("a")
+		| Closed ("c")
+		| Frozen ("f")
+	} representation string
+
+	type Profile struct {
+		name   String          (rename "n")
+		tags   [String]        (rename "ts")
+		attrs  {String:Int}    (rename "as")
+		status State           (rename "s")
+		memo   optional String (rename "m")
+		age    optional Int    (rename "ag")
+	}
+`)
+	require.NoError(t, err)
+	assert.NoError(im.CheckCompatible(prev))
+	assert.NoError(im.Valid(data))
+	assert.ErrorContains(prev.CheckCompatible(im), "member Frozen of Profile.status removed")
+}
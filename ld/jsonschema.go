@@ -0,0 +1,851 @@
+// (c) 2022-2022, LDC Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package ld
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	jsonpatch "github.com/ldclabs/json-patch"
+
+	"github.com/ldclabs/ldvm/util/erring"
+)
+
+// MaxJSONSchemaDepth is the maximum nesting depth of a JSON document
+// validated by a JSONModel, including the $ref indirections.
+const MaxJSONSchemaDepth = 64
+
+// MaxJSONSchemaSteps is the maximum number of schema evaluations to validate
+// a JSON document, it bounds the cost of the applicators (allOf, anyOf, oneOf, not)
+// that evaluate the same value again and again.
+const MaxJSONSchemaSteps = 100_000
+
+// JSONModel is a data model defined by a JSON Schema document.
+// It supports a deterministic subset of JSON Schema (draft 2020-12),
+// an unsupported keyword is rejected when the model is created,
+// so a document is never treated as valid because a constraint was ignored.
+type JSONModel struct {
+	name   string
+	schema string
+	root   *jsonSchema
+}
+
+type jsonSchema struct {
+	boolean *bool
+	ref     string
+	refTo   *jsonSchema
+
+	types    []string
+	enum     []any
+	constant any
+	hasConst bool
+
+	minimum, maximum                   *big.Rat
+	exclusiveMinimum, exclusiveMaximum *big.Rat
+	multipleOf                         *big.Rat
+
+	minLength, maxLength int
+	pattern              *regexp.Regexp
+
+	prefixItems      []*jsonSchema
+	items            *jsonSchema
+	minItems         int
+	maxItems         int
+	uniqueItems      bool
+	properties       map[string]*jsonSchema
+	patternProps     []*jsonPatternSchema
+	additionalProps  *jsonSchema
+	required         []string
+	minProperties    int
+	maxProperties    int
+	allOf            []*jsonSchema
+	anyOf            []*jsonSchema
+	oneOf            []*jsonSchema
+	not              *jsonSchema
+	dependentRequire map[string][]string
+}
+
+type jsonPatternSchema struct {
+	pattern *regexp.Regexp
+	schema  *jsonSchema
+}
+
+var jsonSchemaTypes = map[string]bool{
+	"null": true, "boolean": true, "object": true, "array": true,
+	"number": true, "integer": true, "string": true,
+}
+
+// annotation keywords that have no effect on validation
+var jsonSchemaAnnotations = map[string]bool{
+	"$schema": true, "$id": true, "$comment": true, "$defs": true, "definitions": true,
+	"title": true, "description": true, "default": true, "examples": true,
+	"deprecated": true, "readOnly": true, "writeOnly": true, "format": true,
+}
+
+func NewJSONModel(name string, sc string) (*JSONModel, error) {
+	errp := erring.ErrPrefix(fmt.Sprintf("ld.NewJSONModel(%q): ", name))
+
+	var v any
+	if err := unmarshalJSONNumber([]byte(sc), &v); err != nil {
+		return nil, errp.Errorf("invalid JSON Schema, %v", err)
+	}
+
+	c := &jsonSchemaCompiler{defs: make(map[string]*jsonSchema)}
+	root, err := c.compileRoot(v)
+	if err != nil {
+		return nil, errp.ErrorIf(err)
+	}
+	if !root.isObjectOrArray(0) {
+		return nil, errp.Errorf("should be an object or array schema")
+	}
+	return &JSONModel{name: name, schema: sc, root: root}, nil
+}
+
+func (l *JSONModel) Name() string {
+	return l.name
+}
+
+func (l *JSONModel) Schema() string {
+	return l.schema
+}
+
+func (l *JSONModel) ApplyPatch(doc, operations []byte) ([]byte, error) {
+	errp := erring.ErrPrefix(fmt.Sprintf("ld.JSONModel(%q).ApplyPatch: ", l.name))
+
+	p, err := jsonpatch.NewPatch(operations)
+	if err != nil {
+		return nil, errp.Errorf("invalid JSON patch, %v", err)
+	}
+
+	if doc, err = p.Apply(doc); err != nil {
+		return nil, errp.ErrorIf(err)
+	}
+
+	if err = l.valid(doc); err != nil {
+		return nil, errp.ErrorIf(err)
+	}
+	return doc, nil
+}
+
+func (l *JSONModel) Valid(data []byte) error {
+	errp := erring.ErrPrefix(fmt.Sprintf("ld.JSONModel(%q).Valid: ", l.name))
+	return errp.ErrorIf(l.valid(data))
+}
+
+func (l *JSONModel) valid(data []byte) error {
+	var v any
+	if err := unmarshalJSONNumber(data, &v); err != nil {
+		return fmt.Errorf("invalid JSON, %v", err)
+	}
+	return l.root.validate(v, "", 0, &jsonValidator{})
+}
+
+// jsonValidator counts the schema evaluations of a JSON document.
+type jsonValidator struct {
+	steps int
+}
+
+func (vc *jsonValidator) exceeded() bool {
+	return vc.steps > MaxJSONSchemaSteps
+}
+
+func unmarshalJSONNumber(data []byte, v *any) error {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	if err := dec.Decode(v); err != nil {
+		return err
+	}
+	if dec.More() {
+		return fmt.Errorf("extraneous data")
+	}
+	return nil
+}
+
+type jsonSchemaCompiler struct {
+	defs map[string]*jsonSchema
+	refs []*jsonSchema
+}
+
+func (c *jsonSchemaCompiler) compileRoot(v any) (*jsonSchema, error) {
+	root := new(jsonSchema)
+	c.defs["#"] = root
+
+	if m, ok := v.(map[string]any); ok {
+		for _, key := range []string{"$defs", "definitions"} {
+			dv, ok := m[key]
+			if !ok {
+				continue
+			}
+			dm, ok := dv.(map[string]any)
+			if !ok {
+				return nil, fmt.Errorf("%s should be an object", key)
+			}
+			names := sortedKeys(dm)
+			for _, name := range names {
+				c.defs["#/"+key+"/"+name] = new(jsonSchema)
+			}
+			for _, name := range names {
+				ref := "#/" + key + "/" + name
+				if err := c.compile(dm[name], c.defs[ref], ref); err != nil {
+					return nil, err
+				}
+			}
+		}
+	}
+
+	if err := c.compile(v, root, "#"); err != nil {
+		return nil, err
+	}
+
+	for _, s := range c.refs {
+		if s.refTo = c.defs[s.ref]; s.refTo == nil {
+			return nil, fmt.Errorf("unresolved $ref %q", s.ref)
+		}
+	}
+
+	visiting := make(map[*jsonSchema]bool)
+	for _, ref := range sortedSchemaKeys(c.defs) {
+		if err := checkRefCycle(c.defs[ref], ref, visiting); err != nil {
+			return nil, err
+		}
+	}
+	return root, nil
+}
+
+// checkRefCycle rejects a $ref cycle that evaluates the same value again
+// without descending into it through properties or items, such a schema never terminates.
+// visiting is true for the schemas in the current path and false for the checked ones.
+func checkRefCycle(s *jsonSchema, path string, visiting map[*jsonSchema]bool) error {
+	if checking, ok := visiting[s]; ok {
+		if checking {
+			return fmt.Errorf("%s: $ref cycle without consuming the value", path)
+		}
+		return nil
+	}
+
+	visiting[s] = true
+	subs := make([]*jsonSchema, 0, len(s.allOf)+len(s.anyOf)+len(s.oneOf)+2)
+	if s.refTo != nil {
+		subs = append(subs, s.refTo)
+	}
+	subs = append(subs, s.allOf...)
+	subs = append(subs, s.anyOf...)
+	subs = append(subs, s.oneOf...)
+	if s.not != nil {
+		subs = append(subs, s.not)
+	}
+	for _, sub := range subs {
+		if err := checkRefCycle(sub, path, visiting); err != nil {
+			return err
+		}
+	}
+	visiting[s] = false
+	return nil
+}
+
+func sortedSchemaKeys(m map[string]*jsonSchema) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func (c *jsonSchemaCompiler) compile(v any, s *jsonSchema, path string) error {
+	var err error
+	s.minLength, s.maxLength = -1, -1
+	s.minItems, s.maxItems = -1, -1
+	s.minProperties, s.maxProperties = -1, -1
+
+	switch val := v.(type) {
+	case bool:
+		s.boolean = &val
+		return nil
+
+	case map[string]any:
+		for _, k := range sortedKeys(val) {
+			kv := val[k]
+			kp := path + "/" + k
+			switch k {
+			case "$ref":
+				ref, ok := kv.(string)
+				if !ok || !strings.HasPrefix(ref, "#") {
+					return fmt.Errorf("%s: only local $ref is supported", kp)
+				}
+				s.ref = ref
+				c.refs = append(c.refs, s)
+
+			case "type":
+				if s.types, err = toStrings(kv); err != nil {
+					return fmt.Errorf("%s: %v", kp, err)
+				}
+				for _, t := range s.types {
+					if !jsonSchemaTypes[t] {
+						return fmt.Errorf("%s: invalid type %q", kp, t)
+					}
+				}
+
+			case "enum":
+				arr, ok := kv.([]any)
+				if !ok || len(arr) == 0 {
+					return fmt.Errorf("%s: should be a non-empty array", kp)
+				}
+				s.enum = arr
+
+			case "const":
+				s.constant, s.hasConst = kv, true
+
+			case "minimum":
+				s.minimum, err = toRat(kv)
+			case "maximum":
+				s.maximum, err = toRat(kv)
+			case "exclusiveMinimum":
+				s.exclusiveMinimum, err = toRat(kv)
+			case "exclusiveMaximum":
+				s.exclusiveMaximum, err = toRat(kv)
+			case "multipleOf":
+				if s.multipleOf, err = toRat(kv); err == nil && s.multipleOf.Sign() <= 0 {
+					err = fmt.Errorf("should be greater than 0")
+				}
+
+			case "minLength":
+				s.minLength, err = toNonNegativeInt(kv)
+			case "maxLength":
+				s.maxLength, err = toNonNegativeInt(kv)
+			case "pattern":
+				s.pattern, err = toRegexp(kv)
+
+			case "prefixItems":
+				s.prefixItems, err = c.compileList(kv, kp)
+			case "items":
+				s.items = new(jsonSchema)
+				err = c.compile(kv, s.items, kp)
+			case "minItems":
+				s.minItems, err = toNonNegativeInt(kv)
+			case "maxItems":
+				s.maxItems, err = toNonNegativeInt(kv)
+			case "uniqueItems":
+				var ok bool
+				if s.uniqueItems, ok = kv.(bool); !ok {
+					err = fmt.Errorf("should be a boolean")
+				}
+
+			case "properties":
+				m, ok := kv.(map[string]any)
+				if !ok {
+					return fmt.Errorf("%s: should be an object", kp)
+				}
+				s.properties = make(map[string]*jsonSchema, len(m))
+				for _, name := range sortedKeys(m) {
+					ps := new(jsonSchema)
+					if err = c.compile(m[name], ps, kp+"/"+name); err != nil {
+						return err
+					}
+					s.properties[name] = ps
+				}
+
+			case "patternProperties":
+				m, ok := kv.(map[string]any)
+				if !ok {
+					return fmt.Errorf("%s: should be an object", kp)
+				}
+				for _, p := range sortedKeys(m) {
+					ps := &jsonPatternSchema{schema: new(jsonSchema)}
+					if ps.pattern, err = toRegexp(p); err != nil {
+						return fmt.Errorf("%s: %v", kp, err)
+					}
+					if err = c.compile(m[p], ps.schema, kp+"/"+p); err != nil {
+						return err
+					}
+					s.patternProps = append(s.patternProps, ps)
+				}
+
+			case "additionalProperties":
+				s.additionalProps = new(jsonSchema)
+				err = c.compile(kv, s.additionalProps, kp)
+
+			case "required":
+				s.required, err = toStrings(kv)
+			case "minProperties":
+				s.minProperties, err = toNonNegativeInt(kv)
+			case "maxProperties":
+				s.maxProperties, err = toNonNegativeInt(kv)
+
+			case "dependentRequired":
+				m, ok := kv.(map[string]any)
+				if !ok {
+					return fmt.Errorf("%s: should be an object", kp)
+				}
+				s.dependentRequire = make(map[string][]string, len(m))
+				for _, name := range sortedKeys(m) {
+					if s.dependentRequire[name], err = toStrings(m[name]); err != nil {
+						return fmt.Errorf("%s/%s: %v", kp, name, err)
+					}
+				}
+
+			case "allOf":
+				s.allOf, err = c.compileList(kv, kp)
+			case "anyOf":
+				s.anyOf, err = c.compileList(kv, kp)
+			case "oneOf":
+				s.oneOf, err = c.compileList(kv, kp)
+			case "not":
+				s.not = new(jsonSchema)
+				err = c.compile(kv, s.not, kp)
+
+			default:
+				if !jsonSchemaAnnotations[k] {
+					return fmt.Errorf("%s: unsupported keyword %q", path, k)
+				}
+			}
+
+			if err != nil {
+				if strings.HasPrefix(err.Error(), kp) {
+					return err
+				}
+				return fmt.Errorf("%s: %v", kp, err)
+			}
+		}
+		return nil
+
+	default:
+		return fmt.Errorf("%s: schema should be an object or boolean", path)
+	}
+}
+
+func (c *jsonSchemaCompiler) compileList(v any, path string) ([]*jsonSchema, error) {
+	arr, ok := v.([]any)
+	if !ok || len(arr) == 0 {
+		return nil, fmt.Errorf("should be a non-empty array")
+	}
+
+	list := make([]*jsonSchema, len(arr))
+	for i, sv := range arr {
+		list[i] = new(jsonSchema)
+		if err := c.compile(sv, list[i], path+"/"+strconv.Itoa(i)); err != nil {
+			return nil, err
+		}
+	}
+	return list, nil
+}
+
+// isObjectOrArray returns true if the schema only accepts objects or arrays,
+// the payload of data should be a document.
+func (s *jsonSchema) isObjectOrArray(depth int) bool {
+	if len(s.types) == 0 {
+		return s.refTo != nil && depth < MaxJSONSchemaDepth && s.refTo.isObjectOrArray(depth+1)
+	}
+	for _, t := range s.types {
+		if t != "object" && t != "array" {
+			return false
+		}
+	}
+	return true
+}
+
+func (s *jsonSchema) validate(v any, path string, depth int, vc *jsonValidator) error {
+	if depth > MaxJSONSchemaDepth {
+		return fmt.Errorf("%s: exceeds the max depth %d", jsonPath(path), MaxJSONSchemaDepth)
+	}
+	if vc.steps++; vc.exceeded() {
+		return fmt.Errorf("%s: exceeds the max evaluation steps %d", jsonPath(path), MaxJSONSchemaSteps)
+	}
+
+	if s.boolean != nil {
+		if !*s.boolean {
+			return fmt.Errorf("%s: not allowed", jsonPath(path))
+		}
+		return nil
+	}
+
+	if s.refTo != nil {
+		if err := s.refTo.validate(v, path, depth+1, vc); err != nil {
+			return err
+		}
+	}
+
+	if len(s.types) > 0 {
+		ok := false
+		for _, t := range s.types {
+			if jsonTypeOf(v, t) {
+				ok = true
+				break
+			}
+		}
+		if !ok {
+			return fmt.Errorf("%s: expected %s, got %s",
+				jsonPath(path), strings.Join(s.types, " or "), jsonTypeName(v))
+		}
+	}
+
+	if s.enum != nil {
+		ok := false
+		for _, e := range s.enum {
+			if jsonEqual(v, e) {
+				ok = true
+				break
+			}
+		}
+		if !ok {
+			return fmt.Errorf("%s: value not in enum", jsonPath(path))
+		}
+	}
+
+	if s.hasConst && !jsonEqual(v, s.constant) {
+		return fmt.Errorf("%s: value not equal to const", jsonPath(path))
+	}
+
+	var err error
+	switch val := v.(type) {
+	case json.Number:
+		err = s.validateNumber(val, path)
+	case string:
+		err = s.validateString(val, path)
+	case []any:
+		err = s.validateArray(val, path, depth, vc)
+	case map[string]any:
+		err = s.validateObject(val, path, depth, vc)
+	}
+	if err != nil {
+		return err
+	}
+
+	for _, sub := range s.allOf {
+		if err = sub.validate(v, path, depth+1, vc); err != nil {
+			return err
+		}
+	}
+
+	// the errors of anyOf, oneOf and not are ignored, but the evaluation should stop
+	// once the steps are exceeded, otherwise a document could be accepted by not.
+	if s.anyOf != nil {
+		ok := false
+		for _, sub := range s.anyOf {
+			if err = sub.validate(v, path, depth+1, vc); err == nil {
+				ok = true
+				break
+			}
+			if vc.exceeded() {
+				return err
+			}
+		}
+		if !ok {
+			return fmt.Errorf("%s: not match any schema of anyOf", jsonPath(path))
+		}
+	}
+
+	if s.oneOf != nil {
+		n := 0
+		for _, sub := range s.oneOf {
+			if err = sub.validate(v, path, depth+1, vc); err == nil {
+				n++
+			} else if vc.exceeded() {
+				return err
+			}
+		}
+		if n != 1 {
+			return fmt.Errorf("%s: should match exactly one schema of oneOf, got %d",
+				jsonPath(path), n)
+		}
+	}
+
+	if s.not != nil {
+		err = s.not.validate(v, path, depth+1, vc)
+		if err == nil {
+			return fmt.Errorf("%s: should not match the schema of not", jsonPath(path))
+		}
+		if vc.exceeded() {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *jsonSchema) validateNumber(n json.Number, path string) error {
+	r, ok := new(big.Rat).SetString(n.String())
+	if !ok {
+		return fmt.Errorf("%s: invalid number %s", jsonPath(path), n)
+	}
+
+	switch {
+	case s.minimum != nil && r.Cmp(s.minimum) < 0:
+		return fmt.Errorf("%s: expected >= %s, got %s",
+			jsonPath(path), s.minimum.RatString(), n)
+	case s.maximum != nil && r.Cmp(s.maximum) > 0:
+		return fmt.Errorf("%s: expected <= %s, got %s",
+			jsonPath(path), s.maximum.RatString(), n)
+	case s.exclusiveMinimum != nil && r.Cmp(s.exclusiveMinimum) <= 0:
+		return fmt.Errorf("%s: expected > %s, got %s",
+			jsonPath(path), s.exclusiveMinimum.RatString(), n)
+	case s.exclusiveMaximum != nil && r.Cmp(s.exclusiveMaximum) >= 0:
+		return fmt.Errorf("%s: expected < %s, got %s",
+			jsonPath(path), s.exclusiveMaximum.RatString(), n)
+	case s.multipleOf != nil && !new(big.Rat).Quo(r, s.multipleOf).IsInt():
+		return fmt.Errorf("%s: expected multiple of %s, got %s",
+			jsonPath(path), s.multipleOf.RatString(), n)
+	}
+	return nil
+}
+
+func (s *jsonSchema) validateString(str string, path string) error {
+	l := utf8.RuneCountInString(str)
+	switch {
+	case s.minLength >= 0 && l < s.minLength:
+		return fmt.Errorf("%s: expected length >= %d, got %d", jsonPath(path), s.minLength, l)
+	case s.maxLength >= 0 && l > s.maxLength:
+		return fmt.Errorf("%s: expected length <= %d, got %d", jsonPath(path), s.maxLength, l)
+	case s.pattern != nil && !s.pattern.MatchString(str):
+		return fmt.Errorf("%s: not match pattern %q", jsonPath(path), s.pattern.String())
+	}
+	return nil
+}
+
+func (s *jsonSchema) validateArray(arr []any, path string, depth int, vc *jsonValidator) error {
+	switch {
+	case s.minItems >= 0 && len(arr) < s.minItems:
+		return fmt.Errorf("%s: expected items >= %d, got %d", jsonPath(path), s.minItems, len(arr))
+	case s.maxItems >= 0 && len(arr) > s.maxItems:
+		return fmt.Errorf("%s: expected items <= %d, got %d", jsonPath(path), s.maxItems, len(arr))
+	}
+
+	if s.uniqueItems {
+		for i := range arr {
+			for j := i + 1; j < len(arr); j++ {
+				if jsonEqual(arr[i], arr[j]) {
+					return fmt.Errorf("%s: items %d and %d are not unique", jsonPath(path), i, j)
+				}
+			}
+		}
+	}
+
+	for i, item := range arr {
+		var sub *jsonSchema
+		switch {
+		case i < len(s.prefixItems):
+			sub = s.prefixItems[i]
+		case s.items != nil:
+			sub = s.items
+		default:
+			continue
+		}
+		if err := sub.validate(item, path+"/"+strconv.Itoa(i), depth+1, vc); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *jsonSchema) validateObject(obj map[string]any, path string, depth int, vc *jsonValidator) error {
+	switch {
+	case s.minProperties >= 0 && len(obj) < s.minProperties:
+		return fmt.Errorf("%s: expected properties >= %d, got %d",
+			jsonPath(path), s.minProperties, len(obj))
+	case s.maxProperties >= 0 && len(obj) > s.maxProperties:
+		return fmt.Errorf("%s: expected properties <= %d, got %d",
+			jsonPath(path), s.maxProperties, len(obj))
+	}
+
+	for _, name := range s.required {
+		if _, ok := obj[name]; !ok {
+			return fmt.Errorf("%s: missing required property %q", jsonPath(path), name)
+		}
+	}
+
+	for _, k := range sortedKeys(obj) {
+		if deps, ok := s.dependentRequire[k]; ok {
+			for _, name := range deps {
+				if _, ok := obj[name]; !ok {
+					return fmt.Errorf("%s: missing property %q required by %q",
+						jsonPath(path), name, k)
+				}
+			}
+		}
+
+		kp := path + "/" + escapeJSONPointer(k)
+		matched := false
+		if ps, ok := s.properties[k]; ok {
+			matched = true
+			if err := ps.validate(obj[k], kp, depth+1, vc); err != nil {
+				return err
+			}
+		}
+
+		for _, pp := range s.patternProps {
+			if pp.pattern.MatchString(k) {
+				matched = true
+				if err := pp.schema.validate(obj[k], kp, depth+1, vc); err != nil {
+					return err
+				}
+			}
+		}
+
+		if !matched && s.additionalProps != nil {
+			if s.additionalProps.boolean != nil && !*s.additionalProps.boolean {
+				return fmt.Errorf("%s: additional property %q not allowed", jsonPath(path), k)
+			}
+			if err := s.additionalProps.validate(obj[k], kp, depth+1, vc); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func jsonPath(path string) string {
+	if path == "" {
+		return "/"
+	}
+	return path
+}
+
+func escapeJSONPointer(s string) string {
+	return strings.ReplaceAll(strings.ReplaceAll(s, "~", "~0"), "/", "~1")
+}
+
+func jsonTypeOf(v any, t string) bool {
+	switch val := v.(type) {
+	case nil:
+		return t == "null"
+	case bool:
+		return t == "boolean"
+	case string:
+		return t == "string"
+	case []any:
+		return t == "array"
+	case map[string]any:
+		return t == "object"
+	case json.Number:
+		if t == "number" {
+			return true
+		}
+		if t == "integer" {
+			r, ok := new(big.Rat).SetString(val.String())
+			return ok && r.IsInt()
+		}
+	}
+	return false
+}
+
+func jsonTypeName(v any) string {
+	switch v.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case string:
+		return "string"
+	case []any:
+		return "array"
+	case map[string]any:
+		return "object"
+	case json.Number:
+		return "number"
+	}
+	return fmt.Sprintf("%T", v)
+}
+
+func jsonEqual(a, b any) bool {
+	switch av := a.(type) {
+	case json.Number:
+		bv, ok := b.(json.Number)
+		if !ok {
+			return false
+		}
+		ar, ok1 := new(big.Rat).SetString(av.String())
+		br, ok2 := new(big.Rat).SetString(bv.String())
+		return ok1 && ok2 && ar.Cmp(br) == 0
+
+	case []any:
+		bv, ok := b.([]any)
+		if !ok || len(av) != len(bv) {
+			return false
+		}
+		for i := range av {
+			if !jsonEqual(av[i], bv[i]) {
+				return false
+			}
+		}
+		return true
+
+	case map[string]any:
+		bv, ok := b.(map[string]any)
+		if !ok || len(av) != len(bv) {
+			return false
+		}
+		for k, v := range av {
+			if w, ok := bv[k]; !ok || !jsonEqual(v, w) {
+				return false
+			}
+		}
+		return true
+
+	default:
+		return a == b
+	}
+}
+
+func sortedKeys(m map[string]any) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func toStrings(v any) ([]string, error) {
+	switch val := v.(type) {
+	case string:
+		return []string{val}, nil
+	case []any:
+		ss := make([]string, len(val))
+		for i, s := range val {
+			str, ok := s.(string)
+			if !ok {
+				return nil, fmt.Errorf("should be a string or an array of strings")
+			}
+			ss[i] = str
+		}
+		return ss, nil
+	}
+	return nil, fmt.Errorf("should be a string or an array of strings")
+}
+
+func toRat(v any) (*big.Rat, error) {
+	if n, ok := v.(json.Number); ok {
+		if r, ok := new(big.Rat).SetString(n.String()); ok {
+			return r, nil
+		}
+	}
+	return nil, fmt.Errorf("should be a number")
+}
+
+func toNonNegativeInt(v any) (int, error) {
+	if n, ok := v.(json.Number); ok {
+		if i, err := strconv.ParseInt(n.String(), 10, 32); err == nil && i >= 0 {
+			return int(i), nil
+		}
+	}
+	return 0, fmt.Errorf("should be a non-negative integer")
+}
+
+func toRegexp(v any) (*regexp.Regexp, error) {
+	str, ok := v.(string)
+	if !ok {
+		return nil, fmt.Errorf("should be a string")
+	}
+	re, err := regexp.Compile(str)
+	if err != nil {
+		return nil, fmt.Errorf("invalid pattern %q, %v", str, err)
+	}
+	return re, nil
+}
@@ -0,0 +1,165 @@
+// (c) 2022-2022, LDC Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package ld
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestJSONModel(t *testing.T) {
+	assert := assert.New(t)
+
+	for _, c := range []struct {
+		sc  string
+		err string
+	}{
+		{`{`, "invalid JSON Schema"},
+		{`{} {}`, "invalid JSON Schema, extraneous data"},
+		{`[]`, "#: schema should be an object or boolean"},
+		{`true`, "should be an object or array schema"},
+		{`{}`, "should be an object or array schema"},
+		{`{"type": "string"}`, "should be an object or array schema"},
+		{`{"type": "object", "if": {}}`, `#: unsupported keyword "if"`},
+		{`{"type": "obj"}`, `#/type: invalid type "obj"`},
+		{`{"type": "object", "properties": {"a": {"minLength": -1}}}`,
+			"#/properties/a/minLength: should be a non-negative integer"},
+		{`{"type": "object", "properties": {"a": {"pattern": "("}}}`,
+			"#/properties/a/pattern: invalid pattern"},
+		{`{"type": "object", "properties": {"a": {"multipleOf": 0}}}`,
+			"#/properties/a/multipleOf: should be greater than 0"},
+		{`{"type": "object", "properties": {"a": {"$ref": "#/$defs/b"}}}`,
+			`unresolved $ref "#/$defs/b"`},
+		{`{"type": "object", "properties": {"a": {"$ref": "https://example.com/a"}}}`,
+			"#/properties/a/$ref: only local $ref is supported"},
+		{`{"type": "array", "items": 1}`, "#/items: schema should be an object or boolean"},
+		{`{"type": "array", "anyOf": []}`, "#/anyOf: should be a non-empty array"},
+		{`{"$ref": "#"}`, "#: $ref cycle without consuming the value"},
+		{`{"type": "object", "$defs": {"a": {"allOf": [{"$ref": "#/$defs/b"}]}, "b": {"not": {"$ref": "#/$defs/a"}}}}`,
+			"#/$defs/a: $ref cycle without consuming the value"},
+	} {
+		_, err := NewJSONModel("Profile", c.sc)
+		assert.ErrorContains(err, c.err, c.sc)
+	}
+
+	sc := `{
+		"$schema": "https://json-schema.org/draft/2020-12/schema",
+		"title": "Profile",
+		"type": "object",
+		"properties": {
+			"name": {"type": "string", "minLength": 1, "maxLength": 8},
+			"age": {"type": "integer", "minimum": 0, "exclusiveMaximum": 200},
+			"score": {"type": "number", "multipleOf": 0.5},
+			"email": {"type": "string", "pattern": "^[^@]+@[^@]+$", "format": "email"},
+			"kind": {"enum": ["person", "org"]},
+			"version": {"const": 1},
+			"tags": {"type": "array", "items": {"type": "string"}, "maxItems": 3, "uniqueItems": true},
+			"point": {"type": "array", "prefixItems": [{"type": "number"}, {"type": "number"}], "items": false},
+			"follows": {"type": "array", "items": {"$ref": "#/$defs/link"}},
+			"extra": {"type": ["object", "null"], "additionalProperties": {"type": "string"}},
+			"contact": {"oneOf": [{"type": "string"}, {"type": "integer"}]},
+			"nickname": {"anyOf": [{"type": "string"}, {"type": "null"}]},
+			"note": {"not": {"type": "null"}}
+		},
+		"patternProperties": {"^x-": {"type": "boolean"}},
+		"required": ["name", "kind"],
+		"dependentRequired": {"email": ["age"]},
+		"additionalProperties": false,
+		"$defs": {
+			"link": {
+				"type": "object",
+				"properties": {"id": {"type": "string"}, "next": {"$ref": "#/$defs/link"}},
+				"required": ["id"]
+			}
+		}
+	}`
+	jm, err := NewJSONModel("Profile", sc)
+	require.NoError(t, err)
+	assert.Equal("Profile", jm.Name())
+	assert.Equal(sc, jm.Schema())
+
+	assert.NoError(jm.Valid([]byte(`{"name": "test", "kind": "org"}`)))
+	assert.NoError(jm.Valid([]byte(`{
+		"name": "测试",
+		"age": 18.0,
+		"score": 9.5,
+		"email": "a@b.c",
+		"kind": "person",
+		"version": 1.0,
+		"tags": ["a", "b"],
+		"point": [1, 2.5],
+		"follows": [{"id": "a", "next": {"id": "b"}}],
+		"extra": {"a": "b"},
+		"contact": 123,
+		"nickname": null,
+		"note": "",
+		"x-flag": true
+	}`)))
+
+	for _, c := range []struct {
+		doc string
+		err string
+	}{
+		{`{"name": "test", "kind": "org"`, "invalid JSON"},
+		{`[]`, "/: expected object, got array"},
+		{`{"name": "test"}`, `/: missing required property "kind"`},
+		{`{"name": "", "kind": "org"}`, "/name: expected length >= 1, got 0"},
+		{`{"name": "123456789", "kind": "org"}`, "/name: expected length <= 8, got 9"},
+		{`{"name": 1, "kind": "org"}`, "/name: expected string, got number"},
+		{`{"name": "test", "kind": "user"}`, "/kind: value not in enum"},
+		{`{"name": "test", "kind": "org", "age": 1.5}`, "/age: expected integer, got number"},
+		{`{"name": "test", "kind": "org", "age": -1}`, "/age: expected >= 0, got -1"},
+		{`{"name": "test", "kind": "org", "age": 200}`, "/age: expected < 200, got 200"},
+		{`{"name": "test", "kind": "org", "score": 0.3}`, "/score: expected multiple of 1/2, got 0.3"},
+		{`{"name": "test", "kind": "org", "email": "abc", "age": 1}`, `/email: not match pattern`},
+		{`{"name": "test", "kind": "org", "email": "a@b"}`, `/: missing property "age" required by "email"`},
+		{`{"name": "test", "kind": "org", "version": 2}`, "/version: value not equal to const"},
+		{`{"name": "test", "kind": "org", "tags": ["a", "a"]}`, "/tags: items 0 and 1 are not unique"},
+		{`{"name": "test", "kind": "org", "tags": ["a", "b", "c", "d"]}`, "/tags: expected items <= 3, got 4"},
+		{`{"name": "test", "kind": "org", "tags": ["a", 1]}`, "/tags/1: expected string, got number"},
+		{`{"name": "test", "kind": "org", "point": [1, "2"]}`, "/point/1: expected number, got string"},
+		{`{"name": "test", "kind": "org", "point": [1, 2, 3]}`, "/point/2: not allowed"},
+		{`{"name": "test", "kind": "org", "follows": [{"id": "a", "next": {}}]}`,
+			`/follows/0/next: missing required property "id"`},
+		{`{"name": "test", "kind": "org", "extra": {"a": 1}}`, "/extra/a: expected string, got number"},
+		{`{"name": "test", "kind": "org", "contact": 1.5}`, "/contact: should match exactly one schema of oneOf, got 0"},
+		{`{"name": "test", "kind": "org", "nickname": 1}`, "/nickname: not match any schema of anyOf"},
+		{`{"name": "test", "kind": "org", "note": null}`, "/note: should not match the schema of not"},
+		{`{"name": "test", "kind": "org", "x-flag": 1}`, "/x-flag: expected boolean, got number"},
+		{`{"name": "test", "kind": "org", "other": 1}`, `/: additional property "other" not allowed`},
+	} {
+		assert.ErrorContains(jm.Valid([]byte(c.doc)), c.err, c.doc)
+	}
+
+	doc := []byte(`{"name": "test", "kind": "org"}`)
+	_, err = jm.ApplyPatch(doc, []byte(`{}`))
+	assert.ErrorContains(err, "invalid JSON patch")
+	_, err = jm.ApplyPatch(doc, []byte(`[{"op": "remove", "path": "/kind"}]`))
+	assert.ErrorContains(err, `ld.JSONModel("Profile").ApplyPatch: /: missing required property "kind"`)
+	doc, err = jm.ApplyPatch(doc, []byte(`[{"op": "add", "path": "/tags", "value": ["a"]}]`))
+	require.NoError(t, err)
+	assert.Equal(`{"name":"test","kind":"org","tags":["a"]}`, string(doc))
+
+	// recursive $ref without consuming the document
+	_, err = NewJSONModel("Loop", `{"type": "object", "$ref": "#"}`)
+	assert.ErrorContains(err, "#: $ref cycle without consuming the value")
+
+	// the same value is evaluated 2^20 times without the steps limit
+	defs := make([]string, 0, 21)
+	for i := 0; i < 20; i++ {
+		defs = append(defs, fmt.Sprintf(`"d%d": {"anyOf": [{"$ref": "#/$defs/d%d"}, {"$ref": "#/$defs/d%d"}]}`,
+			i, i+1, i+1))
+	}
+	defs = append(defs, `"d20": false`)
+	jm, err = NewJSONModel("Blowup", `{"type": "object", "$defs": {`+strings.Join(defs, ",")+`},
+		"properties": {"a": {"$ref": "#/$defs/d0"}}}`)
+	require.NoError(t, err)
+	assert.NoError(jm.Valid([]byte(`{}`)))
+	assert.ErrorContains(jm.Valid([]byte(`{"a": 1}`)),
+		"/a: exceeds the max evaluation steps 100000")
+}
@@ -15,27 +15,75 @@ import (
 
 var ModelNameReg = regexp.MustCompile(`^[A-Z][0-9A-Za-z]{1,127}$`)
 
+// ModelFormat is the language of the model's schema.
+type ModelFormat uint8
+
+const (
+	ModelFormatIPLD       ModelFormat = iota // IPLD schema DSL, the data's payload is CBOR
+	ModelFormatJSONSchema                    // JSON Schema, the data's payload is JSON
+)
+
+// Model validates and patches the data's payload by the model's schema,
+// it is implemented by *IPLDModel and *JSONModel.
+type Model interface {
+	Name() string
+	Schema() string
+	Valid(data []byte) error
+	ApplyPatch(doc, operations []byte) ([]byte, error)
+}
+
+// NewModel creates a Model from the schema in the given format.
+func NewModel(format ModelFormat, name string, sc string) (Model, error) {
+	switch format {
+	case ModelFormatIPLD:
+		im, err := NewIPLDModel(name, sc)
+		if err != nil {
+			return nil, err
+		}
+		return im, nil
+
+	case ModelFormatJSONSchema:
+		jm, err := NewJSONModel(name, sc)
+		if err != nil {
+			return nil, err
+		}
+		return jm, nil
+
+	default:
+		return nil, erring.ErrPrefix("ld.NewModel: ").Errorf("invalid format %d", format)
+	}
+}
+
 type ModelInfo struct {
 	// model name, should match ^[A-Z][0-9A-Za-z]{1,127}$
 	Name string `cbor:"n" json:"name"`
 	// MultiSig: m of n, threshold is m, keepers length is n.
 	// The minimum value is 0, means any one using the model don't need to approve.
-	// the maximum value is len(keepers)
+	// the maximum value is len(keepers), or the sum of weights when set.
 	Threshold uint16 `cbor:"th" json:"threshold"`
 	// keepers who owned this model, no more than 64
 	// Creating data using this model requires keepers to sign.
 	// no keepers or threshold is 0 means don't need sign.
-	Keepers  signer.Keys `cbor:"kp" json:"keepers"`
-	Approver signer.Key  `cbor:"ap" json:"approver,omitempty"`
-	Schema   string      `cbor:"sc" json:"schema"`
+	Keepers signer.Keys `cbor:"kp" json:"keepers"`
+	// optional weights of keepers one by one
+	Weights  []uint16   `cbor:"kw,omitempty" json:"weights,omitempty"`
+	Approver signer.Key `cbor:"ap" json:"approver,omitempty"`
+	Schema   string     `cbor:"sc" json:"schema"`
+	// format of the schema, default is ModelFormatIPLD
+	Format ModelFormat `cbor:"f,omitempty" json:"format,omitempty"`
+	// schema version, it is increased on every upgrade of the schema
+	Version uint64 `cbor:"v,omitempty" json:"version,omitempty"`
+	// schemas of previous versions, PrevSchemas[i] is the schema of version i.
+	// They are kept for reading the historical data.
+	PrevSchemas []string `cbor:"ps,omitempty" json:"prevSchemas,omitempty"`
 
 	// external assignment fields
 	ID    ids.ModelID `cbor:"-" json:"id"`
-	model *IPLDModel  `cbor:"-" json:"-"`
+	model Model       `cbor:"-" json:"-"`
 	raw   []byte      `cbor:"-" json:"-"`
 }
 
-func (t *ModelInfo) Model() *IPLDModel {
+func (t *ModelInfo) Model() Model {
 	return t.model
 }
 
@@ -54,24 +102,37 @@ func (t *ModelInfo) SyntacticVerify() error {
 	case len(t.Keepers) > MaxKeepers:
 		return errp.Errorf("too many keepers")
 
-	case int(t.Threshold) > len(t.Keepers):
+	case uint64(t.Threshold) > t.Keepers.Weight(t.Weights...):
 		return errp.Errorf("invalid threshold")
 
 	case len(t.Schema) < 10 || !utf8.ValidString(t.Schema):
 		return errp.Errorf("invalid schema string")
+
+	case uint64(len(t.PrevSchemas)) != t.Version:
+		return errp.Errorf("invalid version, expected %d, got %d", len(t.PrevSchemas), t.Version)
+	}
+
+	for i, sc := range t.PrevSchemas {
+		if len(sc) < 10 || !utf8.ValidString(sc) {
+			return errp.Errorf("invalid schema string of version %d", i)
+		}
 	}
 
 	if err = t.Keepers.Valid(); err != nil {
 		return errp.Errorf("invalid keepers, %v", err)
 	}
 
+	if err = t.Keepers.ValidWeights(t.Weights); err != nil {
+		return errp.Errorf("invalid weights, %v", err)
+	}
+
 	if t.Approver != nil {
 		if err = t.Approver.Valid(); err != nil {
 			return errp.Errorf("invalid approver, %v", err)
 		}
 	}
 
-	if t.model, err = NewIPLDModel(t.Name, t.Schema); err != nil {
+	if t.model, err = NewModel(t.Format, t.Name, t.Schema); err != nil {
 		return errp.ErrorIf(err)
 	}
 	if t.raw, err = t.Marshal(); err != nil {
@@ -80,16 +141,74 @@ func (t *ModelInfo) SyntacticVerify() error {
 	return nil
 }
 
+// ModelAt returns the Model of the given schema version,
+// it is used to read the data created with a previous version.
+func (t *ModelInfo) ModelAt(version uint64) (Model, error) {
+	errp := erring.ErrPrefix("ld.ModelInfo.ModelAt: ")
+
+	switch {
+	case version == t.Version:
+		if t.model == nil {
+			return nil, errp.Errorf("model not initialized")
+		}
+		return t.model, nil
+
+	case version > t.Version:
+		return nil, errp.Errorf("invalid version, expected <= %d, got %d", t.Version, version)
+	}
+
+	im, err := NewModel(t.Format, t.Name, t.PrevSchemas[version])
+	if err != nil {
+		return nil, errp.ErrorIf(err)
+	}
+	return im, nil
+}
+
+// UpgradeSchema upgrades the schema to a new version if it is backward compatible
+// with the current one, the current schema is kept in PrevSchemas.
+func (t *ModelInfo) UpgradeSchema(sc string) error {
+	errp := erring.ErrPrefix("ld.ModelInfo.UpgradeSchema: ")
+
+	prev, ok := t.model.(*IPLDModel)
+	switch {
+	case t.model == nil:
+		return errp.Errorf("model not initialized")
+	case !ok:
+		return errp.Errorf("only IPLD schema can be upgraded")
+	}
+	if len(sc) < 10 || !utf8.ValidString(sc) {
+		return errp.Errorf("invalid schema string")
+	}
+	if sc == t.Schema {
+		return errp.Errorf("schema not changed")
+	}
+
+	im, err := NewIPLDModel(t.Name, sc)
+	if err != nil {
+		return errp.ErrorIf(err)
+	}
+	if err = im.CheckCompatible(prev); err != nil {
+		return errp.ErrorIf(err)
+	}
+
+	t.PrevSchemas = append(t.PrevSchemas, t.Schema)
+	t.Schema = sc
+	t.Version++
+	t.model = im
+	t.raw = nil
+	return nil
+}
+
 func (t *ModelInfo) Verify(digestHash []byte, sigs signer.Sigs) bool {
 	if t.Threshold == 0 {
 		return true
 	}
 
-	return t.Keepers.Verify(digestHash, sigs, t.Threshold)
+	return t.Keepers.Verify(digestHash, sigs, t.Threshold, t.Weights...)
 }
 
 func (t *ModelInfo) VerifyPlus(digestHash []byte, sigs signer.Sigs) bool {
-	return t.Keepers.VerifyPlus(digestHash, sigs, t.Threshold)
+	return t.Keepers.VerifyPlus(digestHash, sigs, t.Threshold, t.Weights...)
 }
 
 func (t *ModelInfo) Bytes() []byte {
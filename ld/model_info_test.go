@@ -104,3 +104,105 @@ func TestModelInfo(t *testing.T) {
 	assert.NoError(tx3.SyntacticVerify())
 	assert.Equal(tx2.Bytes(), tx3.Bytes())
 }
+
+func TestModelInfoUpgradeSchema(t *testing.T) {
+	assert := assert.New(t)
+
+	sc := `
+	type NameService struct {
+		name    String   (rename "n")
+		records [String] (rename "rs")
+	}
+`
+	sc1 := `
+	type NameService struct {
+		name    String          (rename "n")
+		records [String]        (rename "rs")
+		memo    optional String (rename "m")
+	}
+`
+
+	tx := &ModelInfo{Name: "NameService", Schema: sc, Version: 1}
+	assert.ErrorContains(tx.SyntacticVerify(), "invalid version, expected 0, got 1")
+
+	tx = &ModelInfo{Name: "NameService", Schema: sc, Version: 1, PrevSchemas: []string{"abc"}}
+	assert.ErrorContains(tx.SyntacticVerify(), "invalid schema string of version 0")
+
+	tx = &ModelInfo{Name: "NameService", Schema: sc}
+	assert.ErrorContains(tx.UpgradeSchema(sc1), "model not initialized")
+	require.NoError(t, tx.SyntacticVerify())
+	data := tx.Bytes()
+
+	assert.ErrorContains(tx.UpgradeSchema("abc"), "invalid schema string")
+	assert.ErrorContains(tx.UpgradeSchema(sc), "schema not changed")
+	assert.ErrorContains(tx.UpgradeSchema(`
+	type NameService struct {
+		name    String (rename "n")
+		records [Int]  (rename "rs")
+	}
+`), "type of NameService.records[] changed from string to int")
+	assert.ErrorContains(tx.UpgradeSchema(`
+	type NameService struct {
+		name    String   (rename "n")
+		records [String] (rename "rs")
+		memo    String   (rename "m")
+	}
+`), "new field NameService.memo should be optional")
+	assert.Equal(uint64(0), tx.Version)
+	assert.Equal(data, tx.Bytes())
+
+	assert.NoError(tx.UpgradeSchema(sc1))
+	assert.Equal(uint64(1), tx.Version)
+	assert.Equal(sc1, tx.Schema)
+	assert.Equal([]string{sc}, tx.PrevSchemas)
+	assert.NotEqual(data, tx.Bytes())
+
+	tx2 := &ModelInfo{}
+	assert.NoError(tx2.Unmarshal(tx.Bytes()))
+	assert.NoError(tx2.SyntacticVerify())
+	assert.Equal(tx.Bytes(), tx2.Bytes())
+
+	im, err := tx2.ModelAt(1)
+	require.NoError(t, err)
+	assert.Equal(tx2.Model(), im)
+	im, err = tx2.ModelAt(0)
+	require.NoError(t, err)
+	assert.Equal(sc, im.Schema())
+	_, err = tx2.ModelAt(2)
+	assert.ErrorContains(err, "invalid version, expected <= 1, got 2")
+}
+
+func TestModelInfoWithJSONSchema(t *testing.T) {
+	assert := assert.New(t)
+
+	sc := `{"type": "object", "properties": {"name": {"type": "string"}}, "required": ["name"]}`
+
+	tx := &ModelInfo{Name: "NameService", Schema: sc}
+	assert.ErrorContains(tx.SyntacticVerify(), `NewIPLDModel("NameService")`)
+
+	tx = &ModelInfo{Name: "NameService", Schema: sc, Format: 2}
+	assert.ErrorContains(tx.SyntacticVerify(), "ld.NewModel: invalid format 2")
+
+	tx = &ModelInfo{Name: "NameService", Schema: `{"type": "string"}`, Format: ModelFormatJSONSchema}
+	assert.ErrorContains(tx.SyntacticVerify(), "should be an object or array schema")
+
+	tx = &ModelInfo{Name: "NameService", Schema: sc, Format: ModelFormatJSONSchema}
+	require.NoError(t, tx.SyntacticVerify())
+	jm, ok := tx.Model().(*JSONModel)
+	require.True(t, ok)
+	assert.NoError(jm.Valid([]byte(`{"name": "test"}`)))
+	assert.ErrorContains(tx.Model().Valid([]byte(`{"name": 1}`)), "/name: expected string, got number")
+
+	jsondata, err := json.Marshal(tx)
+	require.NoError(t, err)
+	// fmt.Println(string(jsondata))
+	assert.Equal(`{"name":"NameService","threshold":0,"keepers":null,"schema":"{\"type\": \"object\", \"properties\": {\"name\": {\"type\": \"string\"}}, \"required\": [\"name\"]}","format":1,"id":"AAAAAAAAAAAAAAAAAAAAAAAAAADzaDye"}`, string(jsondata))
+
+	tx2 := &ModelInfo{}
+	assert.NoError(tx2.Unmarshal(tx.Bytes()))
+	assert.NoError(tx2.SyntacticVerify())
+	assert.Equal(tx.Bytes(), tx2.Bytes())
+	assert.Equal(ModelFormatJSONSchema, tx2.Format)
+
+	assert.ErrorContains(tx2.UpgradeSchema(`{"type": "object"}`), "only IPLD schema can be upgraded")
+}
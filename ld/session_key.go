@@ -0,0 +1,129 @@
+// (c) 2022-2022, LDC Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package ld
+
+import (
+	"math/big"
+
+	"github.com/fxamacker/cbor/v2"
+	"github.com/ldclabs/ldvm/signer"
+	"github.com/ldclabs/ldvm/util/erring"
+)
+
+const MaxSessionKeys = 16
+
+// SessionKey is a scoped key that can sign txs for the account without being a keeper.
+type SessionKey struct {
+	_ struct{} `cbor:",toarray"`
+
+	Key signer.Key `json:"key"`
+	// TxTypes the session key can send, should be in SessionKeyTxTypes
+	TxTypes TxTypes `json:"txTypes"`
+	// MaxAmounts is the remaining spending cap per token, the gas cost is counted on NativeLDC
+	MaxAmounts map[cbor.ByteString]*big.Int `json:"maxAmounts"`
+	// Expire is the last timestamp at which the session key can be used
+	Expire uint64 `json:"expire"`
+}
+
+// SyntacticVerify verifies that a *SessionKey is well-formed.
+func (s *SessionKey) SyntacticVerify() error {
+	errp := erring.ErrPrefix("ld.SessionKey.SyntacticVerify: ")
+
+	switch {
+	case s == nil:
+		return errp.Errorf("nil pointer")
+
+	case len(s.TxTypes) == 0:
+		return errp.Errorf("empty txTypes")
+
+	case s.MaxAmounts == nil:
+		return errp.Errorf("nil maxAmounts")
+
+	case s.Expire == 0:
+		return errp.Errorf("invalid expire")
+	}
+
+	if err := s.Key.Valid(); err != nil {
+		return errp.Errorf("invalid key, %v", err)
+	}
+
+	if err := s.TxTypes.CheckDuplicate(); err != nil {
+		return errp.Errorf("invalid txTypes, %v", err)
+	}
+
+	for _, ty := range s.TxTypes {
+		if !AllTxTypes.Has(ty) {
+			return errp.Errorf("invalid TxType %s in txTypes", ty)
+		}
+		if !SessionKeyTxTypes.Has(ty) {
+			return errp.Errorf("TxType %s is not allowed for session key", ty)
+		}
+	}
+
+	for token, amount := range s.MaxAmounts {
+		if amount == nil || amount.Sign() < 0 {
+			return errp.Errorf("invalid amount for %q in maxAmounts", token)
+		}
+	}
+	return nil
+}
+
+// SessionKeys is a list of the account's session keys.
+type SessionKeys []*SessionKey
+
+// SyntacticVerify verifies that SessionKeys is well-formed.
+func (ss SessionKeys) SyntacticVerify() error {
+	errp := erring.ErrPrefix("ld.SessionKeys.SyntacticVerify: ")
+
+	if len(ss) > MaxSessionKeys {
+		return errp.Errorf("too many session keys, expected <= %d, got %d",
+			MaxSessionKeys, len(ss))
+	}
+
+	set := make(map[string]struct{}, len(ss))
+	for _, s := range ss {
+		if err := s.SyntacticVerify(); err != nil {
+			return errp.ErrorIf(err)
+		}
+
+		if _, ok := set[string(s.Key)]; ok {
+			return errp.Errorf("duplicate session key %s", s.Key)
+		}
+		set[string(s.Key)] = struct{}{}
+	}
+	return nil
+}
+
+// Find returns the session key that signed the digestHash, or nil if not found.
+func (ss SessionKeys) Find(digestHash []byte, sigs signer.Sigs) *SessionKey {
+	for _, s := range ss {
+		if s.Key.Verify(digestHash, sigs) {
+			return s
+		}
+	}
+	return nil
+}
+
+// Clone returns a deep copy of the SessionKeys.
+func (ss SessionKeys) Clone() SessionKeys {
+	if ss == nil {
+		return nil
+	}
+
+	nss := make(SessionKeys, len(ss))
+	for i, s := range ss {
+		ns := &SessionKey{
+			Key:        s.Key.Clone(),
+			TxTypes:    make(TxTypes, len(s.TxTypes)),
+			MaxAmounts: make(map[cbor.ByteString]*big.Int, len(s.MaxAmounts)),
+			Expire:     s.Expire,
+		}
+		copy(ns.TxTypes, s.TxTypes)
+		for k, v := range s.MaxAmounts {
+			ns.MaxAmounts[k] = new(big.Int).Set(v)
+		}
+		nss[i] = ns
+	}
+	return nss
+}
@@ -0,0 +1,121 @@
+// (c) 2022-2022, LDC Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package ld
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"testing"
+
+	"github.com/fxamacker/cbor/v2"
+	"github.com/ldclabs/ldvm/ids"
+	"github.com/ldclabs/ldvm/signer"
+	"github.com/ldclabs/ldvm/util/encoding"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSessionKey(t *testing.T) {
+	assert := assert.New(t)
+
+	var s *SessionKey
+	assert.ErrorContains(s.SyntacticVerify(), "nil pointer")
+
+	s = &SessionKey{}
+	assert.ErrorContains(s.SyntacticVerify(), "empty txTypes")
+
+	s = &SessionKey{TxTypes: TxTypes{TypeUpdateData}}
+	assert.ErrorContains(s.SyntacticVerify(), "nil maxAmounts")
+
+	s = &SessionKey{TxTypes: TxTypes{TypeUpdateData}, MaxAmounts: map[cbor.ByteString]*big.Int{}}
+	assert.ErrorContains(s.SyntacticVerify(), "invalid expire")
+
+	s = &SessionKey{
+		TxTypes:    TxTypes{TypeUpdateData},
+		MaxAmounts: map[cbor.ByteString]*big.Int{},
+		Expire:     1000,
+	}
+	assert.ErrorContains(s.SyntacticVerify(), "invalid key")
+
+	s = &SessionKey{
+		Key:        signer.Signer2.Key(),
+		TxTypes:    TxTypes{TypeUpdateData, TypeUpdateData},
+		MaxAmounts: map[cbor.ByteString]*big.Int{},
+		Expire:     1000,
+	}
+	assert.ErrorContains(s.SyntacticVerify(), "invalid txTypes, duplicate TxType TypeUpdateData")
+
+	s = &SessionKey{
+		Key:        signer.Signer2.Key(),
+		TxTypes:    TxTypes{TxType(255)},
+		MaxAmounts: map[cbor.ByteString]*big.Int{},
+		Expire:     1000,
+	}
+	assert.ErrorContains(s.SyntacticVerify(), "invalid TxType TypeUnknown(255) in txTypes")
+
+	uncapped := TxTypes{TypeTransferMultiple, TypeExchange, TypeCreateHTLC, TypeCreateEscrow,
+		TypeApproveAllowance, TypeCreateVesting, TypeCreateSubscription, TypeCancelRecovery,
+		TypeUpdateAccountInfo, TypeCloseAccount, TypeScheduleTx}
+	for _, ty := range uncapped {
+		s = &SessionKey{
+			Key:        signer.Signer2.Key(),
+			TxTypes:    TxTypes{ty},
+			MaxAmounts: map[cbor.ByteString]*big.Int{},
+			Expire:     1000,
+		}
+		assert.ErrorContains(s.SyntacticVerify(),
+			fmt.Sprintf("TxType %s is not allowed for session key", ty))
+	}
+
+	s = &SessionKey{
+		Key:        signer.Signer2.Key(),
+		TxTypes:    TxTypes{TypeUpdateData},
+		MaxAmounts: map[cbor.ByteString]*big.Int{ids.NativeToken.AsKey(): big.NewInt(-1)},
+		Expire:     1000,
+	}
+	assert.ErrorContains(s.SyntacticVerify(), `invalid amount for "" in maxAmounts`)
+
+	s = &SessionKey{
+		Key:        signer.Signer2.Key(),
+		TxTypes:    TxTypes{TypeUpdateData, TypeTransfer},
+		MaxAmounts: map[cbor.ByteString]*big.Int{ids.NativeToken.AsKey(): big.NewInt(1000)},
+		Expire:     1000,
+	}
+	assert.NoError(s.SyntacticVerify())
+
+	jsondata, err := json.Marshal(s)
+	require.NoError(t, err)
+	// fmt.Println(string(jsondata))
+	assert.Equal(`{"key":"RBccN_9de3u43K1cgfFihKIp5kE1lmGG","txTypes":["TypeUpdateData","TypeTransfer"],"maxAmounts":{"":1000},"expire":1000}`, string(jsondata))
+
+	cbordata, err := encoding.MarshalCBOR(s)
+	require.NoError(t, err)
+	s2 := &SessionKey{}
+	assert.NoError(encoding.UnmarshalCBOR(cbordata, s2))
+	assert.NoError(s2.SyntacticVerify())
+	assert.Equal(s, s2)
+
+	ss := SessionKeys{s, s}
+	assert.ErrorContains(ss.SyntacticVerify(), "duplicate session key RBccN_9de3u43K1cgfFihKIp5kE1lmGG")
+
+	ss = make(SessionKeys, MaxSessionKeys+1)
+	assert.ErrorContains(ss.SyntacticVerify(), "too many session keys, expected <= 16, got 17")
+
+	ss = SessionKeys{s}
+	assert.NoError(ss.SyntacticVerify())
+
+	ss2 := ss.Clone()
+	assert.Equal(ss, ss2)
+	ss2[0].MaxAmounts[ids.NativeToken.AsKey()].SetUint64(1)
+	assert.Equal(uint64(1000), ss[0].MaxAmounts[ids.NativeToken.AsKey()].Uint64())
+
+	digest := ids.ID32FromData([]byte("session key")).Bytes()
+	sig, err := signer.Signer2.SignHash(digest)
+	require.NoError(t, err)
+	assert.Equal(s, ss.Find(digest, signer.Sigs{sig}))
+	sig, err = signer.Signer1.SignHash(digest)
+	require.NoError(t, err)
+	assert.Nil(ss.Find(digest, signer.Sigs{sig}))
+}
@@ -109,10 +109,20 @@ func (s *State) UpdateLedger(id ids.Address, data []byte) {
 	s.Ledgers[cbor.ByteString(id[:])] = ids.ID32FromData(data)
 }
 
+// DeleteLedger records the deletion of the account's ledger in the state with an empty hash.
+func (s *State) DeleteLedger(id ids.Address) {
+	s.Ledgers[cbor.ByteString(id[:])] = ids.EmptyID32
+}
+
 func (s *State) UpdateData(id ids.DataID, data []byte) {
 	s.Datas[cbor.ByteString(id[:])] = ids.ID32FromData(data)
 }
 
+// DeleteData records the deletion of the purged data in the state with an empty hash.
+func (s *State) DeleteData(id ids.DataID) {
+	s.Datas[cbor.ByteString(id[:])] = ids.EmptyID32
+}
+
 func (s *State) UpdateModel(id ids.ModelID, data []byte) {
 	s.Models[cbor.ByteString(id[:])] = ids.ID32FromData(data)
 }
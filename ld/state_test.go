@@ -76,4 +76,22 @@ func TestState(t *testing.T) {
 	assert.NoError(s3.SyntacticVerify())
 	assert.NotEqual(s.ID, s3.ID)
 	assert.NotEqual(s.Bytes(), s3.Bytes())
+
+	// the deletion is recorded with an empty hash
+	s3.DeleteLedger(ids.GenesisAccount)
+	assert.NoError(s3.SyntacticVerify())
+	assert.Equal(ids.EmptyID32, s3.Ledgers[cbor.ByteString(ids.GenesisAccount[:])])
+	assert.NotEqual(s.ID, s3.ID)
+	assert.NotEqual(s2.ID, s3.ID)
+
+	s3.UpdateData(ids.DataID{1, 2, 3}, []byte{1, 2, 3})
+	assert.NoError(s3.SyntacticVerify())
+	assert.Equal(1, len(s3.Datas))
+	id := s3.ID
+	s3.DeleteData(ids.DataID{1, 2, 3})
+	assert.NoError(s3.SyntacticVerify())
+	assert.Equal(1, len(s3.Datas))
+	assert.Equal(ids.EmptyID32, s3.Datas[cbor.ByteString(ids.DataID{1, 2, 3}.Bytes())])
+	assert.NotEqual(id, s3.ID)
+	assert.NotEqual(s2.ID, s3.ID)
 }
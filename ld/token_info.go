@@ -0,0 +1,69 @@
+// (c) 2022-2022, LDC Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package ld
+
+import (
+	"github.com/ldclabs/ldvm/util/encoding"
+	"github.com/ldclabs/ldvm/util/erring"
+	"github.com/ldclabs/ldvm/util/validating"
+)
+
+const MaxTokenDecimals = 18
+
+// TokenInfo is the metadata of a TokenAccount for wallets and explorers.
+type TokenInfo struct {
+	Name        string `cbor:"n" json:"name"`
+	Decimals    uint8  `cbor:"d" json:"decimals"`
+	Icon        string `cbor:"i,omitempty" json:"icon,omitempty"`
+	Description string `cbor:"de,omitempty" json:"description,omitempty"`
+
+	// external assignment fields
+	raw []byte `cbor:"-" json:"-"`
+}
+
+// SyntacticVerify verifies that a *TokenInfo is well-formed.
+func (t *TokenInfo) SyntacticVerify() error {
+	errp := erring.ErrPrefix("ld.TokenInfo.SyntacticVerify: ")
+
+	switch {
+	case t == nil:
+		return errp.Errorf("nil pointer")
+
+	case t.Name == "" || !validating.ValidName(t.Name):
+		return errp.Errorf("invalid name %q", t.Name)
+
+	case t.Decimals > MaxTokenDecimals:
+		return errp.Errorf("invalid decimals, expected <= %d, got %d",
+			MaxTokenDecimals, t.Decimals)
+
+	case !validating.ValidLink(t.Icon):
+		return errp.Errorf("invalid icon %q", t.Icon)
+
+	case !validating.ValidMessage(t.Description):
+		return errp.Errorf("invalid description %q", t.Description)
+	}
+
+	var err error
+	if t.raw, err = t.Marshal(); err != nil {
+		return errp.ErrorIf(err)
+	}
+	return nil
+}
+
+func (t *TokenInfo) Bytes() []byte {
+	if len(t.raw) == 0 {
+		t.raw = MustMarshal(t)
+	}
+	return t.raw
+}
+
+func (t *TokenInfo) Unmarshal(data []byte) error {
+	return erring.ErrPrefix("ld.TokenInfo.Unmarshal: ").
+		ErrorIf(encoding.UnmarshalCBOR(data, t))
+}
+
+func (t *TokenInfo) Marshal() ([]byte, error) {
+	return erring.ErrPrefix("ld.TokenInfo.Marshal: ").
+		ErrorMap(encoding.MarshalCBOR(t))
+}
@@ -0,0 +1,61 @@
+// (c) 2022-2022, LDC Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package ld
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTokenInfo(t *testing.T) {
+	assert := assert.New(t)
+
+	var ti *TokenInfo
+	assert.ErrorContains(ti.SyntacticVerify(), "nil pointer")
+
+	ti = &TokenInfo{}
+	assert.ErrorContains(ti.SyntacticVerify(), `invalid name ""`)
+
+	ti = &TokenInfo{Name: " LDC"}
+	assert.ErrorContains(ti.SyntacticVerify(), `invalid name " LDC"`)
+
+	ti = &TokenInfo{Name: "LDC", Decimals: 19}
+	assert.ErrorContains(ti.SyntacticVerify(), "invalid decimals, expected <= 18, got 19")
+
+	ti = &TokenInfo{Name: "LDC", Decimals: 9, Icon: "https://ldc.io/ icon.png"}
+	assert.ErrorContains(ti.SyntacticVerify(), `invalid icon "https://ldc.io/ icon.png"`)
+
+	ti = &TokenInfo{Name: "LDC", Decimals: 9, Description: "LDC\n"}
+	assert.ErrorContains(ti.SyntacticVerify(), `invalid description "LDC\n"`)
+
+	ti = &TokenInfo{Name: "Linked Data Chain", Decimals: 9}
+	assert.NoError(ti.SyntacticVerify())
+	jsondata, err := json.Marshal(ti)
+	require.NoError(t, err)
+	assert.Equal(`{"name":"Linked Data Chain","decimals":9}`, string(jsondata))
+
+	ti = &TokenInfo{
+		Name:        "Linked Data Chain",
+		Decimals:    9,
+		Icon:        "https://ldc.io/icon.png",
+		Description: "The native token of LDC",
+	}
+	assert.NoError(ti.SyntacticVerify())
+	cbordata, err := ti.Marshal()
+	require.NoError(t, err)
+	jsondata, err = json.Marshal(ti)
+	require.NoError(t, err)
+	assert.Equal(`{"name":"Linked Data Chain","decimals":9,"icon":"https://ldc.io/icon.png","description":"The native token of LDC"}`, string(jsondata))
+
+	ti2 := &TokenInfo{}
+	assert.NoError(ti2.Unmarshal(cbordata))
+	assert.NoError(ti2.SyntacticVerify())
+	cbordata2 := ti2.Bytes()
+	jsondata2, _ := json.Marshal(ti2)
+	assert.Equal(string(jsondata), string(jsondata2))
+	assert.Equal(cbordata, cbordata2)
+}
@@ -10,6 +10,7 @@ import (
 	"sort"
 
 	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/fxamacker/cbor/v2"
 	"github.com/ldclabs/ldvm/ids"
 	"github.com/ldclabs/ldvm/signer"
 	"github.com/ldclabs/ldvm/util/encoding"
@@ -18,6 +19,8 @@ import (
 
 const (
 	maxTxDataSize = 1024 * 256
+	// scheduledSigDomain separates the SigHash of the scheduled txs from the normal ones
+	scheduledSigDomain = "LDVM scheduled tx:"
 )
 
 // gChainID will be updated by SetChainID when VM.Initialize
@@ -38,8 +41,9 @@ type TxData struct {
 	Nonce     uint64           `cbor:"n" json:"nonce"`
 	GasTip    uint64           `cbor:"gt" json:"gasTip"`
 	GasFeeCap uint64           `cbor:"gf" json:"gasFeeCap"`
-	From      ids.Address      `cbor:"fr" json:"from"`                   // Address of the sender
-	To        *ids.Address     `cbor:"to,omitempty" json:"to,omitempty"` // Address of the recipient
+	From      ids.Address      `cbor:"fr" json:"from"`                      // Address of the sender
+	To        *ids.Address     `cbor:"to,omitempty" json:"to,omitempty"`    // Address of the recipient
+	Payer     *ids.Address     `cbor:"py,omitempty" json:"payer,omitempty"` // Address of the gas sponsor
 	Token     *ids.TokenSymbol `cbor:"tk,omitempty" json:"token,omitempty"`
 	Amount    *big.Int         `cbor:"a,omitempty" json:"amount,omitempty"`
 	Data      encoding.RawData `cbor:"d,omitempty" json:"data,omitempty"`
@@ -67,6 +71,9 @@ func (t *TxData) SyntacticVerify() error {
 
 	case t.Data != nil && len(t.Data) == 0:
 		return errp.Errorf("empty data")
+
+	case t.Payer != nil && (*t.Payer == t.From || *t.Payer == ids.EmptyAddress):
+		return errp.Errorf("invalid payer")
 	}
 
 	if t.Amount != nil {
@@ -107,9 +114,17 @@ func (t *TxData) ToTransaction() *Transaction {
 }
 
 type Transaction struct {
-	Tx           TxData      `cbor:"tx" json:"tx"`
-	Signatures   signer.Sigs `cbor:"ss,omitempty" json:"sigs,omitempty"`
-	ExSignatures signer.Sigs `cbor:"es,omitempty" json:"exSigs,omitempty"`
+	Tx              TxData      `cbor:"tx" json:"tx"`
+	Signatures      signer.Sigs `cbor:"ss,omitempty" json:"sigs,omitempty"`
+	ExSignatures    signer.Sigs `cbor:"es,omitempty" json:"exSigs,omitempty"`
+	PayerSignatures signer.Sigs `cbor:"ps,omitempty" json:"payerSigs,omitempty"` // signed by the payer on SigHash
+	// EIP712 indicates that the Signatures and PayerSignatures are signed on the
+	// EIP-712 TypedDataHash by Ethereum wallets, instead of the TxHash
+	EIP712 bool `cbor:"e7,omitempty" json:"eip712,omitempty"`
+	// Scheduled indicates that the Signatures and PayerSignatures are signed in the
+	// scheduled tx domain, the tx can only be executed as the inner tx of a TxScheduleTx
+	// or a multisig proposal, and will be rejected if sent directly.
+	Scheduled bool `cbor:"sc,omitempty" json:"scheduled,omitempty"`
 
 	// external assignment fields
 	ID        ids.ID32 `cbor:"-" json:"id"`
@@ -122,6 +137,7 @@ type Transaction struct {
 	gas       uint64   `cbor:"-" json:"-"`
 	eth       *TxEth   `cbor:"-" json:"-"`
 	txHash    []byte   `cbor:"-" json:"-"`
+	sigHash   []byte   `cbor:"-" json:"-"`
 	exHash    []byte   `cbor:"-" json:"-"`
 	// support for batch transactions
 	// they are processed in the same block, one fail all fail
@@ -147,6 +163,32 @@ func (t *Transaction) SyntacticVerify() error {
 
 	case len(t.ExSignatures) > MaxKeepers:
 		return errp.Errorf("too many exSignatures")
+
+	case t.PayerSignatures != nil && len(t.PayerSignatures) == 0:
+		return errp.Errorf("empty payerSignatures")
+
+	case len(t.PayerSignatures) > MaxKeepers:
+		return errp.Errorf("too many payerSignatures")
+
+	case t.Tx.Payer == nil && t.PayerSignatures != nil:
+		return errp.Errorf("invalid payerSignatures, no payer")
+
+	case t.Scheduled && t.Tx.Type == TypeEth:
+		return errp.Errorf("invalid scheduled, %s can't be scheduled", t.Tx.Type)
+
+	case t.Scheduled && t.EIP712:
+		return errp.Errorf("invalid scheduled, EIP712 tx can't be scheduled")
+
+	// the TypedDataHash encodes nil and zero values of the optional fields in the same way,
+	// they should be nil so that a EIP-712 signature commits to only one tx.
+	case t.EIP712 && t.Tx.Token != nil && *t.Tx.Token == ids.NativeToken:
+		return errp.Errorf("invalid token, should be nil for NativeLDC with EIP712")
+
+	case t.EIP712 && t.Tx.To != nil && *t.Tx.To == ids.EmptyAddress:
+		return errp.Errorf("invalid to, should be nil for empty address with EIP712")
+
+	case t.EIP712 && t.Tx.Amount != nil && t.Tx.Amount.Sign() == 0:
+		return errp.Errorf("invalid amount, should be nil for zero with EIP712")
 	}
 
 	var err error
@@ -156,6 +198,9 @@ func (t *Transaction) SyntacticVerify() error {
 	if err = t.ExSignatures.Valid(); err != nil {
 		return errp.ErrorIf(err)
 	}
+	if err = t.PayerSignatures.Valid(); err != nil {
+		return errp.ErrorIf(err)
+	}
 
 	if t.Tx.Type == TypeEth && t.eth == nil {
 		eth := new(TxEth)
@@ -183,12 +228,24 @@ func (t *Transaction) SyntacticVerify() error {
 	}
 
 	t.txHash = encoding.Sum256(t.Tx.Bytes())
+	t.sigHash = t.computeSigHash()
 	t.ID = ids.ID32FromData(t.raw)
-	t.gas = t.Tx.Type.Gas() + uint64(math.Pow(float64(size), math.SqrtPhi))
+	t.gas = t.Tx.Type.Gas()*t.Tx.entries() + uint64(math.Pow(float64(size), math.SqrtPhi))
 	t.priority = (t.Tx.GasTip + 1) * t.gas
 	return nil
 }
 
+// entries returns the number of transfers that the tx carries, the base gas is scaled by it.
+func (t *TxData) entries() uint64 {
+	if t.Type == TypeTransferMultiple {
+		var outputs []cbor.RawMessage
+		if err := encoding.UnmarshalCBOR(t.Data, &outputs); err == nil && len(outputs) > 1 {
+			return uint64(len(outputs))
+		}
+	}
+	return 1
+}
+
 func (t *Transaction) Gas() uint64 {
 	return t.gas
 }
@@ -207,6 +264,37 @@ func (t *Transaction) TxHash() []byte {
 	return t.txHash
 }
 
+// SigHash returns the digest signed by the Signatures and PayerSignatures,
+// it is the TypedDataHash if EIP712, otherwise the TxHash.
+// It is hashed again in the scheduled tx domain if Scheduled.
+func (t *Transaction) SigHash() []byte {
+	if len(t.sigHash) == 0 {
+		t.sigHash = t.computeSigHash()
+	}
+	return t.sigHash
+}
+
+func (t *Transaction) computeSigHash() []byte {
+	var hash []byte
+	if t.EIP712 {
+		hash = t.Tx.TypedDataHash()
+	} else {
+		hash = t.TxHash()
+	}
+	if t.Scheduled {
+		hash = encoding.Sum256(append([]byte(scheduledSigDomain), hash...))
+	}
+	return hash
+}
+
+// SignForSchedule signs the tx in the scheduled tx domain,
+// so it can only be executed by a TxScheduleTx or a multisig proposal.
+func (t *Transaction) SignForSchedule(signers ...signer.Signer) error {
+	t.Scheduled = true
+	t.sigHash = nil
+	return t.SignWith(signers...)
+}
+
 func (t *Transaction) ExHash() []byte {
 	if len(t.exHash) == 0 {
 		t.exHash = encoding.Sum256(t.Tx.Data)
@@ -225,7 +313,7 @@ func (t *Transaction) Marshal() ([]byte, error) {
 }
 
 func (t *Transaction) SignWith(signers ...signer.Signer) error {
-	datahash := t.TxHash()
+	datahash := t.SigHash()
 	t.Signatures = make([]signer.Sig, 0, len(signers))
 	for _, s := range signers {
 		sig, err := s.SignHash(datahash)
@@ -250,6 +338,20 @@ func (t *Transaction) ExSignWith(signers ...signer.Signer) error {
 	return nil
 }
 
+// PayerSignWith signs the SigHash by the gas sponsor's signers.
+func (t *Transaction) PayerSignWith(signers ...signer.Signer) error {
+	datahash := t.SigHash()
+	t.PayerSignatures = make([]signer.Sig, 0, len(signers))
+	for _, s := range signers {
+		sig, err := s.SignHash(datahash)
+		if err != nil {
+			return erring.ErrPrefix("ld.Transaction.PayerSignWith: ").ErrorIf(err)
+		}
+		t.PayerSignatures = append(t.PayerSignatures, sig)
+	}
+	return nil
+}
+
 type TxIsApprovedFn func(signer.Key, TxTypes, bool) bool
 
 func (t *Transaction) IsApproved(
@@ -259,7 +361,17 @@ func (t *Transaction) IsApproved(
 			return approver.Verify(t.exHash, t.ExSignatures)
 		}
 
-		return approver.Verify(t.txHash, t.Signatures)
+		return approver.Verify(t.sigHash, t.Signatures)
+	}
+
+	return true
+}
+
+// IsPayerApproved verifies the gas sponsor's approver signature in the PayerSignatures,
+// if the tx needs to be approved by the sponsor's approver.
+func (t *Transaction) IsPayerApproved(approver signer.Key, approveList TxTypes) bool {
+	if t.needApprove(approver, approveList) {
+		return approver.Verify(t.SigHash(), t.PayerSignatures)
 	}
 
 	return true
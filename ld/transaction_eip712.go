@@ -0,0 +1,96 @@
+// (c) 2022-2022, LDC Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package ld
+
+import (
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common/math"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ldclabs/ldvm/signer"
+)
+
+// EIP-712 typed data for TxData, so that Ethereum wallets can sign any LDVM transaction
+// with eth_signTypedData_v4:
+//
+//	EIP712Domain(string name,string version,uint256 chainId)
+//	TxData(uint16 type,uint64 chainID,uint64 nonce,uint64 gasTip,uint64 gasFeeCap,address from,address to,address payer,string token,uint256 amount,bytes data)
+//
+// Optional fields are encoded as zero values: to and payer as the zero address,
+// token as "" for NativeLDC, amount as 0 and data as empty bytes.
+const (
+	EIP712DomainName    = "LDVM"
+	EIP712DomainVersion = "1"
+)
+
+var (
+	eip712DomainTypeHash = crypto.Keccak256([]byte(
+		"EIP712Domain(string name,string version,uint256 chainId)"))
+	eip712TxDataTypeHash = crypto.Keccak256([]byte(
+		"TxData(uint16 type,uint64 chainID,uint64 nonce,uint64 gasTip,uint64 gasFeeCap," +
+			"address from,address to,address payer,string token,uint256 amount,bytes data)"))
+)
+
+// EIP712DomainSeparator returns the EIP-712 domain separator of the chain.
+func EIP712DomainSeparator(chainID uint64) []byte {
+	return crypto.Keccak256(
+		eip712DomainTypeHash,
+		crypto.Keccak256([]byte(EIP712DomainName)),
+		crypto.Keccak256([]byte(EIP712DomainVersion)),
+		eip712Uint(new(big.Int).SetUint64(chainID)),
+	)
+}
+
+// TypedDataHash returns the EIP-712 digest of the TxData,
+// it is an alternative digest to TxHash for signatures from Ethereum wallets.
+func (t *TxData) TypedDataHash() []byte {
+	var to, payer [20]byte
+	if t.To != nil {
+		to = *t.To
+	}
+	if t.Payer != nil {
+		payer = *t.Payer
+	}
+	token := ""
+	if t.Token != nil {
+		token = t.Token.String()
+	}
+	amount := new(big.Int)
+	if t.Amount != nil {
+		amount.Set(t.Amount)
+	}
+
+	structHash := crypto.Keccak256(
+		eip712TxDataTypeHash,
+		eip712Uint(new(big.Int).SetUint64(uint64(t.Type))),
+		eip712Uint(new(big.Int).SetUint64(t.ChainID)),
+		eip712Uint(new(big.Int).SetUint64(t.Nonce)),
+		eip712Uint(new(big.Int).SetUint64(t.GasTip)),
+		eip712Uint(new(big.Int).SetUint64(t.GasFeeCap)),
+		eip712Address(t.From),
+		eip712Address(to),
+		eip712Address(payer),
+		crypto.Keccak256([]byte(token)),
+		eip712Uint(amount),
+		crypto.Keccak256(t.Data),
+	)
+	return crypto.Keccak256([]byte{0x19, 0x01}, EIP712DomainSeparator(t.ChainID), structHash)
+}
+
+// SignWithEIP712 marks the transaction as EIP712 and signs the TypedDataHash.
+func (t *Transaction) SignWithEIP712(signers ...signer.Signer) error {
+	t.EIP712 = true
+	t.sigHash = nil
+	return t.SignWith(signers...)
+}
+
+func eip712Uint(i *big.Int) []byte {
+	return math.U256Bytes(i)
+}
+
+func eip712Address(addr [20]byte) []byte {
+	b := make([]byte, 32)
+	copy(b[12:], addr[:])
+	return b
+}
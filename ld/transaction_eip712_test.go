@@ -0,0 +1,172 @@
+// (c) 2022-2022, LDC Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package ld
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/common/math"
+	"github.com/ethereum/go-ethereum/signer/core/apitypes"
+	"github.com/ldclabs/ldvm/ids"
+	"github.com/ldclabs/ldvm/signer"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTxDataTypedDataHash(t *testing.T) {
+	assert := assert.New(t)
+
+	token := MustNewToken("$LDC")
+	txData := &TxData{
+		Type:      TypeTransfer,
+		ChainID:   gChainID,
+		Nonce:     1,
+		GasTip:    100,
+		GasFeeCap: 1000,
+		From:      signer.Signer1.Key().Address(),
+		To:        signer.Signer2.Key().Address().Ptr(),
+		Token:     &token,
+		Amount:    big.NewInt(1_000_000),
+		Data:      []byte(`"hello"`),
+	}
+
+	typedData := apitypes.TypedData{
+		Types: apitypes.Types{
+			"EIP712Domain": []apitypes.Type{
+				{Name: "name", Type: "string"},
+				{Name: "version", Type: "string"},
+				{Name: "chainId", Type: "uint256"},
+			},
+			"TxData": []apitypes.Type{
+				{Name: "type", Type: "uint16"},
+				{Name: "chainID", Type: "uint64"},
+				{Name: "nonce", Type: "uint64"},
+				{Name: "gasTip", Type: "uint64"},
+				{Name: "gasFeeCap", Type: "uint64"},
+				{Name: "from", Type: "address"},
+				{Name: "to", Type: "address"},
+				{Name: "payer", Type: "address"},
+				{Name: "token", Type: "string"},
+				{Name: "amount", Type: "uint256"},
+				{Name: "data", Type: "bytes"},
+			},
+		},
+		PrimaryType: "TxData",
+		Domain: apitypes.TypedDataDomain{
+			Name:    EIP712DomainName,
+			Version: EIP712DomainVersion,
+			ChainId: math.NewHexOrDecimal256(int64(gChainID)),
+		},
+		Message: apitypes.TypedDataMessage{
+			"type":      math.NewHexOrDecimal256(int64(txData.Type)),
+			"chainID":   math.NewHexOrDecimal256(int64(txData.ChainID)),
+			"nonce":     math.NewHexOrDecimal256(int64(txData.Nonce)),
+			"gasTip":    math.NewHexOrDecimal256(int64(txData.GasTip)),
+			"gasFeeCap": math.NewHexOrDecimal256(int64(txData.GasFeeCap)),
+			"from":      common.Address(txData.From).Hex(),
+			"to":        common.Address(*txData.To).Hex(),
+			"payer":     common.Address{}.Hex(),
+			"token":     "$LDC",
+			"amount":    math.NewHexOrDecimal256(1_000_000),
+			"data":      hexutil.Encode(txData.Data),
+		},
+	}
+
+	expected, _, err := apitypes.TypedDataAndHash(typedData)
+	require.NoError(t, err)
+	assert.Equal(expected, txData.TypedDataHash())
+
+	// optional fields
+	txData = &TxData{
+		Type:    TypeUpdateNonceTable,
+		ChainID: gChainID,
+		From:    signer.Signer1.Key().Address(),
+	}
+	typedData.Message = apitypes.TypedDataMessage{
+		"type":      math.NewHexOrDecimal256(int64(txData.Type)),
+		"chainID":   math.NewHexOrDecimal256(int64(txData.ChainID)),
+		"nonce":     math.NewHexOrDecimal256(0),
+		"gasTip":    math.NewHexOrDecimal256(0),
+		"gasFeeCap": math.NewHexOrDecimal256(0),
+		"from":      common.Address(txData.From).Hex(),
+		"to":        common.Address{}.Hex(),
+		"payer":     common.Address{}.Hex(),
+		"token":     "",
+		"amount":    math.NewHexOrDecimal256(0),
+		"data":      "0x",
+	}
+	expected, _, err = apitypes.TypedDataAndHash(typedData)
+	require.NoError(t, err)
+	assert.Equal(expected, txData.TypedDataHash())
+}
+
+func TestTransactionSignWithEIP712(t *testing.T) {
+	assert := assert.New(t)
+
+	tx := &Transaction{Tx: TxData{
+		Type:      TypeTransfer,
+		ChainID:   gChainID,
+		Nonce:     1,
+		GasFeeCap: 1000,
+		From:      signer.Signer1.Key().Address(),
+		To:        signer.Signer2.Key().Address().Ptr(),
+		Amount:    big.NewInt(1_000_000),
+	}}
+	assert.NoError(tx.SignWith(signer.Signer1))
+	assert.NoError(tx.SyntacticVerify())
+	assert.False(tx.EIP712)
+	assert.Equal(tx.TxHash(), tx.SigHash())
+	id := tx.ID
+
+	assert.NoError(tx.SignWithEIP712(signer.Signer1))
+	assert.NoError(tx.SyntacticVerify())
+	assert.True(tx.EIP712)
+	assert.Equal(tx.Tx.TypedDataHash(), tx.SigHash())
+	assert.NotEqual(tx.TxHash(), tx.SigHash())
+	assert.NotEqual(id, tx.ID)
+	assert.True(signer.Signer1.Key().Verify(tx.SigHash(), tx.Signatures))
+	assert.False(signer.Signer1.Key().Verify(tx.TxHash(), tx.Signatures))
+	assert.True(tx.IsApproved(signer.Signer1.Key(), nil, false))
+	assert.False(tx.IsApproved(signer.Signer2.Key(), nil, false))
+
+	tx2 := &Transaction{}
+	assert.NoError(tx2.Unmarshal(tx.Bytes()))
+	assert.NoError(tx2.SyntacticVerify())
+	assert.True(tx2.EIP712)
+	assert.Equal(tx.ID, tx2.ID)
+	assert.Equal(tx.SigHash(), tx2.SigHash())
+
+	tx2.EIP712 = false
+	assert.NoError(tx2.SyntacticVerify())
+	assert.False(signer.Signer1.Key().Verify(tx2.SigHash(), tx2.Signatures))
+
+	// optional fields with zero values have the same TypedDataHash as nil
+	tx3 := &Transaction{Tx: tx.Tx}
+	tx3.Tx.Token = ids.NativeToken.Ptr()
+	assert.Equal(tx.Tx.TypedDataHash(), tx3.Tx.TypedDataHash())
+	assert.NoError(tx3.SignWithEIP712(signer.Signer1))
+	assert.ErrorContains(tx3.SyntacticVerify(),
+		"invalid token, should be nil for NativeLDC with EIP712")
+
+	tx3 = &Transaction{Tx: tx.Tx}
+	tx3.Tx.To = ids.EmptyAddress.Ptr()
+	assert.NoError(tx3.SignWithEIP712(signer.Signer1))
+	assert.ErrorContains(tx3.SyntacticVerify(),
+		"invalid to, should be nil for empty address with EIP712")
+
+	tx3 = &Transaction{Tx: tx.Tx}
+	tx3.Tx.Amount = big.NewInt(0)
+	assert.NoError(tx3.SignWithEIP712(signer.Signer1))
+	assert.ErrorContains(tx3.SyntacticVerify(),
+		"invalid amount, should be nil for zero with EIP712")
+
+	// the TxHash differs, so they are fine without EIP712
+	tx3 = &Transaction{Tx: tx.Tx}
+	tx3.Tx.Token = ids.NativeToken.Ptr()
+	assert.NoError(tx3.SignWith(signer.Signer1))
+	assert.NoError(tx3.SyntacticVerify())
+}
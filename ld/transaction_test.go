@@ -21,7 +21,7 @@ func TestTxData(t *testing.T) {
 	var tx *TxData
 	assert.ErrorContains(tx.SyntacticVerify(), "nil pointer")
 
-	tx = &TxData{Type: TypeDeleteData + 1}
+	tx = &TxData{Type: TypeUpgradeModel + 1}
 	assert.ErrorContains(tx.SyntacticVerify(), "invalid type")
 
 	tx = &TxData{Type: TypeTransfer, ChainID: 1000}
@@ -68,7 +68,7 @@ func TestTransaction(t *testing.T) {
 	var tx *Transaction
 	assert.ErrorContains(tx.SyntacticVerify(), "nil pointer")
 
-	tx = &Transaction{Tx: TxData{Type: TypeDeleteData + 1}}
+	tx = &Transaction{Tx: TxData{Type: TypeUpgradeModel + 1}}
 	assert.ErrorContains(tx.SyntacticVerify(), "invalid type")
 
 	tx = &Transaction{Tx: TxData{Type: TypeTransfer, ChainID: 1000}}
@@ -92,6 +92,40 @@ func TestTransaction(t *testing.T) {
 	tx = &Transaction{Tx: TxData{Type: TypeTransfer, ChainID: gChainID}, ExSignatures: signer.Sigs{}}
 	assert.ErrorContains(tx.SyntacticVerify(), "empty exSignatures")
 
+	tx = &Transaction{Tx: TxData{Type: TypeTransfer, ChainID: gChainID}, PayerSignatures: signer.Sigs{}}
+	assert.ErrorContains(tx.SyntacticVerify(), "empty payerSignatures")
+
+	tx = &Transaction{Tx: TxData{Type: TypeTransfer, ChainID: gChainID}}
+	assert.NoError(tx.PayerSignWith(signer.Signer2))
+	assert.ErrorContains(tx.SyntacticVerify(), "invalid payerSignatures, no payer")
+
+	tx = &Transaction{Tx: TxData{Type: TypeTransfer, ChainID: gChainID, Payer: &ids.Address{}}}
+	assert.ErrorContains(tx.SyntacticVerify(), "invalid payer")
+
+	tx = &Transaction{Tx: TxData{
+		Type:    TypeTransfer,
+		ChainID: gChainID,
+		From:    signer.Signer1.Key().Address(),
+		Payer:   signer.Signer1.Key().Address().Ptr(),
+	}}
+	assert.ErrorContains(tx.SyntacticVerify(), "invalid payer")
+
+	tx = &Transaction{Tx: TxData{
+		Type:    TypeTransfer,
+		ChainID: gChainID,
+		From:    signer.Signer1.Key().Address(),
+		Payer:   signer.Signer2.Key().Address().Ptr(),
+	}}
+	assert.NoError(tx.SignWith(signer.Signer1))
+	assert.NoError(tx.PayerSignWith(signer.Signer2))
+	assert.NoError(tx.SyntacticVerify())
+	assert.True(signer.Signer2.Key().Verify(tx.TxHash(), tx.PayerSignatures))
+	ptx := &Transaction{}
+	assert.NoError(ptx.Unmarshal(tx.Bytes()))
+	assert.NoError(ptx.SyntacticVerify())
+	assert.Equal(tx.ID, ptx.ID)
+	assert.Equal(signer.Signer2.Key().Address(), *ptx.Tx.Payer)
+
 	tx = &Transaction{Tx: TxData{
 		Type:    TypeTransfer,
 		ChainID: gChainID,
@@ -184,6 +218,38 @@ func TestTransaction(t *testing.T) {
 	assert.NotEqual(tx.Bytes(), tx2.Bytes())
 }
 
+func TestTransactionGasWithEntries(t *testing.T) {
+	assert := assert.New(t)
+
+	outputs := SendOutputs{
+		{To: signer.Signer2.Key().Address(), Amount: big.NewInt(1)},
+	}
+	tx := &Transaction{Tx: TxData{
+		Type:      TypeTransferMultiple,
+		ChainID:   gChainID,
+		Nonce:     1,
+		GasTip:    0,
+		GasFeeCap: 1000,
+		From:      signer.Signer1.Key().Address(),
+		Data:      MustMarshal(outputs),
+	}}
+	assert.NoError(tx.SyntacticVerify())
+	assert.Equal(uint64(1), tx.Tx.entries())
+	gas1 := tx.Gas()
+
+	outputs = append(outputs,
+		SendOutput{To: signer.Signer3.Key().Address(), Amount: big.NewInt(1)},
+		SendOutput{To: signer.Signer4.Key().Address(), Amount: big.NewInt(1), Token: MustNewToken("$LDC").Ptr()},
+	)
+	tx.Tx.Data = MustMarshal(outputs)
+	assert.NoError(tx.SyntacticVerify())
+	assert.Equal(uint64(3), tx.Tx.entries())
+	assert.True(tx.Gas() > gas1+TypeTransferMultiple.Gas()*2)
+
+	tx.Tx.Type = TypeTransfer
+	assert.Equal(uint64(1), tx.Tx.entries())
+}
+
 func TestTxs(t *testing.T) {
 	assert := assert.New(t)
 
@@ -117,3 +117,7 @@ func MustMarshalJSON(v any) []byte {
 func Uint16Ptr(u uint16) *uint16 {
 	return &u
 }
+
+func Uint64Ptr(u uint64) *uint64 {
+	return &u
+}
@@ -16,8 +16,16 @@ const (
 	TypeTransfer         // Sends token to a address
 	TypeTransferPay      // Sends token to the address who request payment
 	TypeTransferCash     // Transfer token to sender, like cashing a check.
-	TypeTransferMultiple // Sends token to multiple addresses.
+	TypeTransferMultiple // Sends tokens to multiple addresses.
 	TypeExchange         // Exchanges tokens
+	TypeCreateHTLC       // Locks token to a recipient behind a hashlock and an expire time
+	TypeClaimHTLC        // Claims the locked token with the preimage
+	TypeRefundHTLC       // Refunds the locked token to the sender after expired
+	TypeCreateEscrow     // Locks token to a payee with an arbiter
+	TypeSettleEscrow     // Releases, refunds or splits the escrow with 2-of-3 signatures
+	TypeApproveAllowance // Approves a spender to transfer token from the sender
+	TypeTransferFrom     // Transfers token from the owner by the approved spender
+	TypeCancelExchange   // Cancels the seller's exchange order
 )
 
 const (
@@ -36,6 +44,9 @@ const (
 	TypeUpdateDataInfo       // Updates data's info, such as keepers, threshold, approvers, sigClaims, etc.
 	TypeUpdateDataInfoByAuth // Updates data's info by authorization
 	TypeDeleteData           // Deletes the data
+	TypeSellData             // Offers the data for sale with keepers' signatures
+	TypeBuyData              // Buys the data, pays the price and takes over the keepers
+	TypeUpgradeModel         // Upgrades the model's schema with a backward compatible one
 )
 
 const (
@@ -54,6 +65,22 @@ const (
 	TypeCloseLending
 	TypeBorrow
 	TypeRepay
+	TypeCreateVesting      // Locks token for a beneficiary with a vesting schedule
+	TypeClaimVesting       // Claims the released token from vesting schedules
+	TypeMintToken          // Mints token by token account's keepers, no more than the supply cap
+	TypeBurnToken          // Burns token by the holder
+	TypeUpdateTokenInfo    // Updates token account's metadata
+	TypeSetRecovery        // Sets or removes the account's guardians for social recovery
+	TypeStartRecovery      // Starts a recovery of the account's keepers by guardians
+	TypeCancelRecovery     // Cancels the pending recovery by the account's keepers
+	TypeExecuteRecovery    // Replaces the account's keepers after the recovery delay
+	TypeCloseAccount       // Sweeps the account's balances to a beneficiary and deletes the account
+	TypeScheduleTx         // Schedules a signed tx to be executed at a future height or timestamp
+	TypeCreateSubscription // Approves a payee to pull token from the account in each period
+	TypePullSubscription   // Pulls token from the payer by the payee within the period's limit
+	TypeRevokeSubscription // Revokes the payee's subscription by the payer
+	TypeCreateProposal     // Proposes an inner tx for a multisig account, to be approved by its keepers over time
+	TypeApproveProposal    // Adds keepers' signatures to a proposal, it is executed once the threshold is met
 )
 
 // TxTypes set
@@ -64,10 +91,19 @@ var TransferTxTypes = TxTypes{
 	TypeTransferCash,
 	TypeTransferMultiple,
 	TypeExchange,
+	TypeCreateHTLC,
+	TypeClaimHTLC,
+	TypeRefundHTLC,
+	TypeCreateEscrow,
+	TypeSettleEscrow,
+	TypeApproveAllowance,
+	TypeTransferFrom,
+	TypeCancelExchange,
 }
 
 var ModelTxTypes = TxTypes{
 	TypeUpdateModelInfo,
+	TypeUpgradeModel,
 }
 
 var DataTxTypes = TxTypes{
@@ -76,6 +112,8 @@ var DataTxTypes = TxTypes{
 	TypeUpdateDataInfo,
 	TypeUpdateDataInfoByAuth,
 	TypeDeleteData,
+	TypeSellData,
+	TypeBuyData,
 }
 
 var AccountTxTypes = TxTypes{
@@ -93,6 +131,22 @@ var AccountTxTypes = TxTypes{
 	TypeCloseLending,
 	TypeBorrow,
 	TypeRepay,
+	TypeCreateVesting,
+	TypeClaimVesting,
+	TypeMintToken,
+	TypeBurnToken,
+	TypeUpdateTokenInfo,
+	TypeSetRecovery,
+	TypeStartRecovery,
+	TypeCancelRecovery,
+	TypeExecuteRecovery,
+	TypeCloseAccount,
+	TypeScheduleTx,
+	TypeCreateSubscription,
+	TypePullSubscription,
+	TypeRevokeSubscription,
+	TypeCreateProposal,
+	TypeApproveProposal,
 }
 
 var AllTxTypes = TxTypes{
@@ -107,6 +161,91 @@ var AllTxTypes = TxTypes{
 	AccountTxTypes,
 )
 
+// SessionKeyTxTypes are the TxTypes that session keys can send, the sender's
+// outflow of them is no more than Tx.Amount and the gas, so it is fully capped
+// by the session key's MaxAmounts. Types that move value by Tx.Data, lock or
+// approve token for others, or change the account's authority are excluded.
+// A session key only signs for the sender, the data txs still verify the data
+// keepers' signatures, so the key should also be a keeper of the data it updates.
+var SessionKeyTxTypes = TxTypes{
+	TypeTransfer,
+	TypeTransferPay,
+	TypeTransferCash,
+	TypeClaimHTLC,
+	TypeRefundHTLC,
+	TypeCancelExchange,
+	TypeCreateModel,
+	TypeCreateData,
+	TypeUpdateData,
+	TypeUpgradeData,
+	TypeDeleteData,
+	TypeBuyData,
+	TypeUpdateNonceTable,
+	TypeTakeStake,
+	TypeRepay,
+	TypeClaimVesting,
+	TypeBurnToken,
+	TypeRevokeSubscription,
+}
+
+// PolicyTxTypes are the TxTypes that an account can send while its outflow policy
+// is in effect, the sender's outflow of them is no more than Tx.Amount to Tx.To
+// (or checked output by output), so it is fully enforced by the policy. Types that
+// lock token, sweep the balance, or move value by Tx.Data are excluded. Txs that pull
+// token from other accounts, such as TransferFrom, enforce the owner's policy.
+var PolicyTxTypes = TxTypes{
+	TypeTest,
+	TypePunish,
+	TypeEth,
+	TypeTransfer,
+	TypeTransferPay,
+	TypeTransferCash,
+	TypeTransferMultiple,
+	TypeClaimHTLC,
+	TypeRefundHTLC,
+	TypeSettleEscrow,
+	TypeApproveAllowance,
+	TypeTransferFrom,
+	TypeCancelExchange,
+	TypeUpdateNonceTable,
+	TypeUpdateAccountInfo,
+	TypeCreateToken,
+	TypeCreateStake,
+	TypeResetStake,
+	TypeTakeStake,
+	TypeWithdrawStake,
+	TypeUpdateStakeApprover,
+	TypeOpenLending,
+	TypeCloseLending,
+	TypeBorrow,
+	TypeRepay,
+	TypeClaimVesting,
+	TypeMintToken,
+	TypeBurnToken,
+	TypeUpdateTokenInfo,
+	TypeSetRecovery,
+	TypeStartRecovery,
+	TypeCancelRecovery,
+	TypeExecuteRecovery,
+	TypeScheduleTx,
+	TypeCreateSubscription,
+	TypePullSubscription,
+	TypeRevokeSubscription,
+	TypeCreateProposal,
+	TypeApproveProposal,
+	TypeCreateModel,
+	TypeUpdateModelInfo,
+	TypeUpgradeModel,
+	TypeCreateData,
+	TypeUpdateData,
+	TypeUpgradeData,
+	TypeUpdateDataInfo,
+	TypeUpdateDataInfoByAuth,
+	TypeDeleteData,
+	TypeSellData,
+	TypeBuyData,
+}
+
 var TokenFromTxTypes = TxTypes{
 	TypeEth,
 	TypeTransfer,
@@ -115,6 +254,13 @@ var TokenFromTxTypes = TxTypes{
 	TypeDestroyToken,
 	TypeOpenLending,
 	TypeCloseLending,
+	TypeCreateVesting,
+	TypeMintToken,
+	TypeUpdateTokenInfo,
+	TypeSetRecovery,
+	TypeCancelRecovery,
+	TypeCancelExchange,
+	TypeCloseAccount,
 }
 
 var TokenToTxTypes = TxTypes{
@@ -125,6 +271,11 @@ var TokenToTxTypes = TxTypes{
 	TypeCreateToken,
 	TypeBorrow,
 	TypeRepay,
+	TypeBurnToken,
+	TypeStartRecovery,
+	TypeExecuteRecovery,
+	TypeCreateProposal,
+	TypeApproveProposal,
 }
 
 var StakeFromTxTypes0 = TxTypes{
@@ -132,6 +283,9 @@ var StakeFromTxTypes0 = TxTypes{
 	TypeUpdateNonceTable,
 	TypeResetStake,
 	TypeDestroyStake,
+	TypeSetRecovery,
+	TypeCancelRecovery,
+	TypeCloseAccount,
 }
 
 var StakeFromTxTypes1 = TxTypes{
@@ -158,6 +312,10 @@ var StakeToTxTypes = TxTypes{
 	TypeUpdateStakeApprover,
 	TypeBorrow,
 	TypeRepay,
+	TypeStartRecovery,
+	TypeExecuteRecovery,
+	TypeCreateProposal,
+	TypeApproveProposal,
 }
 
 // TxType is an uint16 representing the type of the tx.
@@ -172,24 +330,57 @@ func (t TxType) Gas() uint64 {
 	case TypeEth, TypeTransfer, TypeTransferPay, TypeTransferCash, TypeTransferMultiple, TypeExchange:
 		return 42
 
+	case TypeApproveAllowance, TypeTransferFrom, TypeCancelExchange:
+		return 42
+
+	case TypeCreateSubscription, TypePullSubscription, TypeRevokeSubscription:
+		return 42
+
 	case TypeUpdateNonceTable, TypeUpdateAccountInfo, TypeUpdateData, TypeUpdateDataInfo:
 		return 42
 
+	case TypeUpdateTokenInfo:
+		return 42
+
 	case TypePunish, TypeCreateData, TypeUpgradeData, TypeUpdateDataInfoByAuth, TypeDeleteData:
 		return 200
 
+	case TypeSellData, TypeBuyData:
+		return 200
+
 	case TypeTakeStake, TypeWithdrawStake, TypeUpdateStakeApprover:
 		return 200
 
-	case TypeBorrow, TypeRepay:
+	case TypeClaimVesting, TypeClaimHTLC, TypeRefundHTLC:
+		return 200
+
+	case TypeMintToken, TypeBurnToken:
+		return 200
+
+	case TypeStartRecovery, TypeCancelRecovery, TypeExecuteRecovery:
+		return 200
+
+	case TypeSetRecovery:
 		return 500
 
-	case TypeCreateModel, TypeUpdateModelInfo:
+	case TypeBorrow, TypeRepay, TypeCreateVesting, TypeCreateHTLC:
+		return 500
+
+	case TypeCreateEscrow, TypeSettleEscrow:
+		return 500
+
+	case TypeCreateModel, TypeUpdateModelInfo, TypeUpgradeModel:
 		return 500
 
 	case TypeCreateToken, TypeDestroyToken, TypeCreateStake, TypeResetStake, TypeDestroyStake:
 		return 1000
 
+	case TypeCloseAccount:
+		return 1000
+
+	case TypeScheduleTx, TypeCreateProposal, TypeApproveProposal:
+		return 500
+
 	case TypeOpenLending, TypeCloseLending:
 		return 1000
 
@@ -216,6 +407,22 @@ func (t TxType) String() string {
 		return "TypeTransferMultiple"
 	case TypeExchange:
 		return "TypeExchange"
+	case TypeCreateHTLC:
+		return "TypeCreateHTLC"
+	case TypeClaimHTLC:
+		return "TypeClaimHTLC"
+	case TypeRefundHTLC:
+		return "TypeRefundHTLC"
+	case TypeCreateEscrow:
+		return "TypeCreateEscrow"
+	case TypeSettleEscrow:
+		return "TypeSettleEscrow"
+	case TypeApproveAllowance:
+		return "TypeApproveAllowance"
+	case TypeTransferFrom:
+		return "TypeTransferFrom"
+	case TypeCancelExchange:
+		return "TypeCancelExchange"
 	case TypeUpdateNonceTable:
 		return "TypeUpdateNonceTable"
 	case TypeUpdateAccountInfo:
@@ -244,10 +451,44 @@ func (t TxType) String() string {
 		return "TypeBorrow"
 	case TypeRepay:
 		return "TypeRepay"
+	case TypeCreateVesting:
+		return "TypeCreateVesting"
+	case TypeClaimVesting:
+		return "TypeClaimVesting"
+	case TypeMintToken:
+		return "TypeMintToken"
+	case TypeBurnToken:
+		return "TypeBurnToken"
+	case TypeUpdateTokenInfo:
+		return "TypeUpdateTokenInfo"
+	case TypeSetRecovery:
+		return "TypeSetRecovery"
+	case TypeStartRecovery:
+		return "TypeStartRecovery"
+	case TypeCancelRecovery:
+		return "TypeCancelRecovery"
+	case TypeExecuteRecovery:
+		return "TypeExecuteRecovery"
+	case TypeCloseAccount:
+		return "TypeCloseAccount"
+	case TypeScheduleTx:
+		return "TypeScheduleTx"
+	case TypeCreateSubscription:
+		return "TypeCreateSubscription"
+	case TypePullSubscription:
+		return "TypePullSubscription"
+	case TypeRevokeSubscription:
+		return "TypeRevokeSubscription"
+	case TypeCreateProposal:
+		return "TypeCreateProposal"
+	case TypeApproveProposal:
+		return "TypeApproveProposal"
 	case TypeCreateModel:
 		return "TypeCreateModel"
 	case TypeUpdateModelInfo:
 		return "TypeUpdateModelInfo"
+	case TypeUpgradeModel:
+		return "TypeUpgradeModel"
 	case TypeCreateData:
 		return "TypeCreateData"
 	case TypeUpdateData:
@@ -260,6 +501,10 @@ func (t TxType) String() string {
 		return "TypeUpdateDataInfoByAuth"
 	case TypeDeleteData:
 		return "TypeDeleteData"
+	case TypeSellData:
+		return "TypeSellData"
+	case TypeBuyData:
+		return "TypeBuyData"
 	default:
 		return fmt.Sprintf("TypeUnknown(%d)", t)
 	}
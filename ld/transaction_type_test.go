@@ -26,6 +26,19 @@ func TestTxType(t *testing.T) {
 		case TypeExchange:
 			assert.Equal(TxType(6), ty)
 			assert.True(TransferTxTypes.Has(ty))
+		case TypeRefundHTLC:
+			assert.Equal(TxType(9), ty)
+			assert.True(TransferTxTypes.Has(ty))
+		case TypeSettleEscrow:
+			assert.Equal(TxType(11), ty)
+			assert.True(TransferTxTypes.Has(ty))
+		case TypeTransferFrom:
+			assert.Equal(TxType(13), ty)
+			assert.True(TransferTxTypes.Has(ty))
+		case TypeCancelExchange:
+			assert.Equal(TxType(14), ty)
+			assert.True(TransferTxTypes.Has(ty))
+			assert.True(TokenFromTxTypes.Has(ty))
 		case TypePunish:
 			assert.Equal(TxType(16), ty)
 			assert.True(AllTxTypes.Has(ty))
@@ -38,12 +51,82 @@ func TestTxType(t *testing.T) {
 		case TypeDeleteData:
 			assert.Equal(TxType(24), ty)
 			assert.True(DataTxTypes.Has(ty))
+		case TypeSellData:
+			assert.Equal(TxType(25), ty)
+			assert.True(DataTxTypes.Has(ty))
+		case TypeBuyData:
+			assert.Equal(TxType(26), ty)
+			assert.True(DataTxTypes.Has(ty))
+		case TypeUpgradeModel:
+			assert.Equal(TxType(27), ty)
+			assert.True(ModelTxTypes.Has(ty))
 		case TypeUpdateNonceTable:
 			assert.Equal(TxType(32), ty)
 			assert.True(AccountTxTypes.Has(ty))
 		case TypeRepay:
 			assert.Equal(TxType(45), ty)
 			assert.True(AccountTxTypes.Has(ty))
+		case TypeClaimVesting:
+			assert.Equal(TxType(47), ty)
+			assert.True(AccountTxTypes.Has(ty))
+		case TypeMintToken:
+			assert.Equal(TxType(48), ty)
+			assert.True(AccountTxTypes.Has(ty))
+			assert.True(TokenFromTxTypes.Has(ty))
+		case TypeBurnToken:
+			assert.Equal(TxType(49), ty)
+			assert.True(AccountTxTypes.Has(ty))
+			assert.True(TokenToTxTypes.Has(ty))
+		case TypeUpdateTokenInfo:
+			assert.Equal(TxType(50), ty)
+			assert.True(AccountTxTypes.Has(ty))
+			assert.True(TokenFromTxTypes.Has(ty))
+		case TypeSetRecovery:
+			assert.Equal(TxType(51), ty)
+			assert.True(AccountTxTypes.Has(ty))
+			assert.True(TokenFromTxTypes.Has(ty))
+			assert.True(StakeFromTxTypes0.Has(ty))
+		case TypeStartRecovery:
+			assert.Equal(TxType(52), ty)
+			assert.True(AccountTxTypes.Has(ty))
+			assert.True(TokenToTxTypes.Has(ty))
+			assert.True(StakeToTxTypes.Has(ty))
+		case TypeCancelRecovery:
+			assert.Equal(TxType(53), ty)
+			assert.True(AccountTxTypes.Has(ty))
+			assert.True(TokenFromTxTypes.Has(ty))
+			assert.True(StakeFromTxTypes0.Has(ty))
+		case TypeExecuteRecovery:
+			assert.Equal(TxType(54), ty)
+			assert.True(AccountTxTypes.Has(ty))
+			assert.True(TokenToTxTypes.Has(ty))
+			assert.True(StakeToTxTypes.Has(ty))
+		case TypeCloseAccount:
+			assert.Equal(TxType(55), ty)
+			assert.True(AccountTxTypes.Has(ty))
+			assert.True(TokenFromTxTypes.Has(ty))
+			assert.True(StakeFromTxTypes0.Has(ty))
+		case TypeScheduleTx:
+			assert.Equal(TxType(56), ty)
+			assert.True(AccountTxTypes.Has(ty))
+			assert.False(TokenFromTxTypes.Has(ty))
+			assert.False(StakeFromTxTypes1.Has(ty))
+		case TypeCreateSubscription:
+			assert.Equal(TxType(57), ty)
+			assert.True(AccountTxTypes.Has(ty))
+		case TypeRevokeSubscription:
+			assert.Equal(TxType(59), ty)
+			assert.True(AccountTxTypes.Has(ty))
+		case TypeCreateProposal:
+			assert.Equal(TxType(60), ty)
+			assert.True(AccountTxTypes.Has(ty))
+			assert.True(TokenToTxTypes.Has(ty))
+			assert.True(StakeToTxTypes.Has(ty))
+		case TypeApproveProposal:
+			assert.Equal(TxType(61), ty)
+			assert.True(AccountTxTypes.Has(ty))
+			assert.True(TokenToTxTypes.Has(ty))
+			assert.True(StakeToTxTypes.Has(ty))
 		}
 	}
 
@@ -60,6 +143,11 @@ func TestTxType(t *testing.T) {
 	assert.NoError(StakeFromTxTypes1.CheckDuplicate())
 	assert.NoError(StakeFromTxTypes2.CheckDuplicate())
 	assert.NoError(StakeToTxTypes.CheckDuplicate())
+	assert.NoError(PolicyTxTypes.CheckDuplicate())
+	for _, ty := range []TxType{TypeExchange, TypeCreateHTLC, TypeCreateEscrow, TypeDestroyToken,
+		TypeDestroyStake, TypeCreateVesting, TypeCloseAccount} {
+		assert.False(PolicyTxTypes.Has(ty))
+	}
 
 	ts = append(TxTypes{TypeEth}, AllTxTypes...)
 	assert.ErrorContains(ts.CheckDuplicate(), "duplicate TxType TypeEth")
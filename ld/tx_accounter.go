@@ -16,9 +16,14 @@ import (
 type TxAccounter struct {
 	Threshold   *uint16          `cbor:"th,omitempty" json:"threshold,omitempty"`
 	Keepers     *signer.Keys     `cbor:"kp,omitempty" json:"keepers,omitempty"`
+	Weights     *[]uint16        `cbor:"kw,omitempty" json:"weights,omitempty"`
 	Approver    *signer.Key      `cbor:"ap,omitempty" json:"approver,omitempty"`
 	ApproveList *TxTypes         `cbor:"apl,omitempty" json:"approveList,omitempty"`
+	SessionKeys *SessionKeys     `cbor:"sk,omitempty" json:"sessionKeys,omitempty"`
+	Policy      *AccountPolicy   `cbor:"po,omitempty" json:"policy,omitempty"`
 	Amount      *big.Int         `cbor:"a,omitempty" json:"amount,omitempty"`
+	SupplyCap   *big.Int         `cbor:"sc,omitempty" json:"supplyCap,omitempty"`
+	TokenInfo   *TokenInfo       `cbor:"ti,omitempty" json:"tokenInfo,omitempty"`
 	Name        string           `cbor:"n,omitempty" json:"name,omitempty"`
 	Data        encoding.RawData `cbor:"d,omitempty" json:"data,omitempty"`
 
@@ -40,19 +45,32 @@ func (t *TxAccounter) SyntacticVerify() error {
 
 	case t.Amount != nil && t.Amount.Sign() < 0:
 		return errp.Errorf("invalid amount")
+
+	case t.SupplyCap != nil && t.SupplyCap.Sign() < 0:
+		return errp.Errorf("invalid supplyCap")
 	}
 
-	if t.Keepers != nil || t.Threshold != nil {
+	if t.Keepers != nil || t.Threshold != nil || t.Weights != nil {
 		switch {
 		case t.Threshold == nil:
 			return errp.Errorf("nil threshold together with keepers")
 
 		case t.Keepers == nil:
 			return errp.Errorf("nil keepers together with threshold")
+		}
 
-		case int(*t.Threshold) > len(*t.Keepers):
+		var weights []uint16
+		if t.Weights != nil {
+			weights = *t.Weights
+		}
+		if err = t.Keepers.ValidWeights(weights); err != nil {
+			return errp.Errorf("invalid weights, %v", err)
+		}
+
+		switch w := t.Keepers.Weight(weights...); {
+		case uint64(*t.Threshold) > w:
 			return errp.Errorf("invalid threshold, expected <= %d, got %d",
-				len(*t.Keepers), *t.Threshold)
+				w, *t.Threshold)
 
 		case len(*t.Keepers) > MaxKeepers:
 			return errp.Errorf("invalid keepers, expected <= %d, got %d",
@@ -64,6 +82,12 @@ func (t *TxAccounter) SyntacticVerify() error {
 		}
 	}
 
+	if t.TokenInfo != nil {
+		if err = t.TokenInfo.SyntacticVerify(); err != nil {
+			return errp.ErrorIf(err)
+		}
+	}
+
 	if t.Approver != nil {
 		if err = t.Approver.ValidOrEmpty(); err != nil {
 			return errp.Errorf("invalid approver, %v", err)
@@ -81,6 +105,18 @@ func (t *TxAccounter) SyntacticVerify() error {
 		}
 	}
 
+	if t.SessionKeys != nil {
+		if err = t.SessionKeys.SyntacticVerify(); err != nil {
+			return errp.ErrorIf(err)
+		}
+	}
+
+	if t.Policy != nil {
+		if err = t.Policy.SyntacticVerify(); err != nil {
+			return errp.ErrorIf(err)
+		}
+	}
+
 	if t.raw, err = t.Marshal(); err != nil {
 		return errp.ErrorIf(err)
 	}
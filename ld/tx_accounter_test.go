@@ -22,6 +22,10 @@ func TestTxAccounter(t *testing.T) {
 
 	tx = &TxAccounter{Amount: big.NewInt(-1)}
 	assert.ErrorContains(tx.SyntacticVerify(), "invalid amount")
+	tx = &TxAccounter{SupplyCap: big.NewInt(-1)}
+	assert.ErrorContains(tx.SyntacticVerify(), "invalid supplyCap")
+	tx = &TxAccounter{TokenInfo: &TokenInfo{Name: "LDC", Decimals: 20}}
+	assert.ErrorContains(tx.SyntacticVerify(), "invalid decimals")
 
 	tx = &TxAccounter{Keepers: &signer.Keys{}}
 	assert.ErrorContains(tx.SyntacticVerify(), "nil threshold")
@@ -31,6 +35,17 @@ func TestTxAccounter(t *testing.T) {
 	assert.ErrorContains(tx.SyntacticVerify(), "invalid threshold, expected <= 0, got 1")
 	tx = &TxAccounter{Threshold: Uint16Ptr(1), Keepers: &signer.Keys{signer.Key(ids.EmptyAddress[:])}}
 	assert.ErrorContains(tx.SyntacticVerify(), "empty Secp256k1 key")
+	tx = &TxAccounter{Weights: &[]uint16{1}}
+	assert.ErrorContains(tx.SyntacticVerify(), "nil threshold")
+	tx = &TxAccounter{Threshold: Uint16Ptr(1), Keepers: &signer.Keys{signer.Signer1.Key()},
+		Weights: &[]uint16{1, 2}}
+	assert.ErrorContains(tx.SyntacticVerify(), "invalid weights, signer.Keys.ValidWeights: expected 1 weights, got 2")
+	tx = &TxAccounter{Threshold: Uint16Ptr(5), Keepers: &signer.Keys{signer.Signer1.Key(), signer.Signer2.Key()},
+		Weights: &[]uint16{3, 1}}
+	assert.ErrorContains(tx.SyntacticVerify(), "invalid threshold, expected <= 4, got 5")
+	tx = &TxAccounter{Threshold: Uint16Ptr(4), Keepers: &signer.Keys{signer.Signer1.Key(), signer.Signer2.Key()},
+		Weights: &[]uint16{3, 1}}
+	assert.NoError(tx.SyntacticVerify())
 
 	tx = &TxAccounter{ApproveList: &TxTypes{TxType(255)}}
 	assert.ErrorContains(tx.SyntacticVerify(), "invalid TxType TypeUnknown(255) in approveList")
@@ -38,6 +53,12 @@ func TestTxAccounter(t *testing.T) {
 	tx = &TxAccounter{ApproveList: &TxTypes{TypeTransfer, TypeTransfer}}
 	assert.ErrorContains(tx.SyntacticVerify(), "invalid approveList, duplicate TxType TypeTransfer")
 
+	tx = &TxAccounter{SessionKeys: &SessionKeys{{}}}
+	assert.ErrorContains(tx.SyntacticVerify(), "ld.SessionKeys.SyntacticVerify: ld.SessionKey.SyntacticVerify: empty txTypes")
+
+	tx = &TxAccounter{Policy: &AccountPolicy{Allowlist: []ids.Address{{1}, {1}}}}
+	assert.ErrorContains(tx.SyntacticVerify(), "ld.AccountPolicy.SyntacticVerify: duplicate recipient")
+
 	tx = &TxAccounter{
 		Threshold: Uint16Ptr(1),
 		Keepers:   &signer.Keys{signer.Signer1.Key(), signer.Signer1.Key()},
@@ -0,0 +1,57 @@
+// (c) 2022-2022, LDC Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package ld
+
+import (
+	"math/big"
+
+	"github.com/ldclabs/ldvm/util/encoding"
+	"github.com/ldclabs/ldvm/util/erring"
+)
+
+// TxAllowance is the data model for TxApproveAllowance.
+// Amount 0 revokes the allowance, Expire 0 means no expiry.
+type TxAllowance struct {
+	Amount *big.Int `cbor:"a" json:"amount"`
+	Expire uint64   `cbor:"e,omitempty" json:"expire,omitempty"`
+
+	// external assignment fields
+	raw []byte `cbor:"-" json:"-"`
+}
+
+// SyntacticVerify verifies that a *TxAllowance is well-formed.
+func (t *TxAllowance) SyntacticVerify() error {
+	errp := erring.ErrPrefix("ld.TxAllowance.SyntacticVerify: ")
+
+	switch {
+	case t == nil:
+		return errp.Errorf("nil pointer")
+
+	case t.Amount == nil || t.Amount.Sign() < 0:
+		return errp.Errorf("invalid amount")
+	}
+
+	var err error
+	if t.raw, err = t.Marshal(); err != nil {
+		return errp.ErrorIf(err)
+	}
+	return nil
+}
+
+func (t *TxAllowance) Bytes() []byte {
+	if len(t.raw) == 0 {
+		t.raw = MustMarshal(t)
+	}
+	return t.raw
+}
+
+func (t *TxAllowance) Unmarshal(data []byte) error {
+	return erring.ErrPrefix("ld.TxAllowance.Unmarshal: ").
+		ErrorIf(encoding.UnmarshalCBOR(data, t))
+}
+
+func (t *TxAllowance) Marshal() ([]byte, error) {
+	return erring.ErrPrefix("ld.TxAllowance.Marshal: ").
+		ErrorMap(encoding.MarshalCBOR(t))
+}
@@ -0,0 +1,48 @@
+// (c) 2022-2022, LDC Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package ld
+
+import (
+	"encoding/json"
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTxAllowance(t *testing.T) {
+	assert := assert.New(t)
+
+	var tx *TxAllowance
+	assert.ErrorContains(tx.SyntacticVerify(), "nil pointer")
+
+	tx = &TxAllowance{}
+	assert.ErrorContains(tx.SyntacticVerify(), "invalid amount")
+
+	tx = &TxAllowance{Amount: big.NewInt(-1)}
+	assert.ErrorContains(tx.SyntacticVerify(), "invalid amount")
+
+	tx = &TxAllowance{Amount: big.NewInt(0)}
+	assert.NoError(tx.SyntacticVerify())
+	jsondata, err := json.Marshal(tx)
+	require.NoError(t, err)
+	assert.Equal(`{"amount":0}`, string(jsondata))
+
+	tx = &TxAllowance{Amount: big.NewInt(1000), Expire: 1000}
+	assert.NoError(tx.SyntacticVerify())
+	cbordata, err := tx.Marshal()
+	require.NoError(t, err)
+	jsondata, err = json.Marshal(tx)
+	require.NoError(t, err)
+	assert.Equal(`{"amount":1000,"expire":1000}`, string(jsondata))
+
+	tx2 := &TxAllowance{}
+	assert.NoError(tx2.Unmarshal(cbordata))
+	assert.NoError(tx2.SyntacticVerify())
+	cbordata2 := tx2.Bytes()
+	jsondata2, _ := json.Marshal(tx2)
+	assert.Equal(string(jsondata), string(jsondata2))
+	assert.Equal(cbordata, cbordata2)
+}
@@ -19,6 +19,8 @@ const MaxSendOutputs = 1024
 type SendOutput struct {
 	To     ids.Address `cbor:"to" json:"to"` // Address of the recipient
 	Amount *big.Int    `cbor:"a" json:"amount"`
+	// Token to send, the tx's token will be used if nil
+	Token *ids.TokenSymbol `cbor:"tk,omitempty" json:"token,omitempty"`
 }
 
 type SendOutputs []SendOutput
@@ -34,7 +36,11 @@ func (so SendOutputs) SyntacticVerify() error {
 		return errp.Errorf("too many SendOutputs")
 	}
 
-	set := make(map[ids.Address]struct{}, len(so))
+	type key struct {
+		to    ids.Address
+		token ids.TokenSymbol
+	}
+	set := make(map[key]struct{}, len(so))
 
 	for i, o := range so {
 		switch {
@@ -43,12 +49,20 @@ func (so SendOutputs) SyntacticVerify() error {
 
 		case o.Amount == nil || o.Amount.Sign() <= 0:
 			return errp.Errorf("invalid amount at %d", i)
+
+		case o.Token != nil && !o.Token.Valid():
+			return errp.Errorf("invalid token %s at %d", o.Token.GoString(), i)
 		}
 
-		if _, ok := set[o.To]; ok {
+		// nil token is the native token
+		k := key{to: o.To, token: ids.NativeToken}
+		if o.Token != nil {
+			k.token = *o.Token
+		}
+		if _, ok := set[k]; ok {
 			return errp.Errorf("duplicate to address %s at %d", o.To.String(), i)
 		}
-		set[o.To] = struct{}{}
+		set[k] = struct{}{}
 	}
 
 	return nil
@@ -46,6 +46,24 @@ func TestSendOutputs(t *testing.T) {
 		{To: ids.Address{1, 2, 3}, Amount: big.NewInt(1)}}
 	assert.ErrorContains(so.SyntacticVerify(), "duplicate to address 0x0102030000000000000000000000000000000000 at 1")
 
+	so = SendOutputs{{To: ids.Address{1, 2, 3}, Amount: big.NewInt(1), Token: &ids.TokenSymbol{'a'}}}
+	assert.ErrorContains(so.SyntacticVerify(), "invalid token 0x6100000000000000000000000000000000000000 at 0")
+
+	so = SendOutputs{
+		{To: ids.Address{1, 2, 3}, Amount: big.NewInt(1)},
+		{To: ids.Address{1, 2, 3}, Amount: big.NewInt(1), Token: MustNewToken("$LDC").Ptr()}}
+	assert.NoError(so.SyntacticVerify())
+
+	so = SendOutputs{
+		{To: ids.Address{1, 2, 3}, Amount: big.NewInt(1)},
+		{To: ids.Address{1, 2, 3}, Amount: big.NewInt(1), Token: ids.NativeToken.Ptr()}}
+	assert.ErrorContains(so.SyntacticVerify(), "duplicate to address 0x0102030000000000000000000000000000000000 at 1")
+
+	so = SendOutputs{
+		{To: ids.Address{1, 2, 3}, Amount: big.NewInt(1), Token: MustNewToken("$LDC").Ptr()},
+		{To: ids.Address{1, 2, 3}, Amount: big.NewInt(1), Token: MustNewToken("$LDC").Ptr()}}
+	assert.ErrorContains(so.SyntacticVerify(), "duplicate to address 0x0102030000000000000000000000000000000000 at 1")
+
 	so = SendOutputs{
 		{To: signer.Signer1.Key().Address(), Amount: big.NewInt(1111)},
 		{To: signer.Signer2.Key().Address(), Amount: big.NewInt(22222)},
@@ -0,0 +1,96 @@
+// (c) 2022-2022, LDC Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package ld
+
+import (
+	"math/big"
+
+	"github.com/ldclabs/ldvm/ids"
+	"github.com/ldclabs/ldvm/util/encoding"
+	"github.com/ldclabs/ldvm/util/erring"
+)
+
+// TxDataOffer is the data model for TxSellData.
+// Buyer is optional, anyone can buy the data if it is nil.
+type TxDataOffer struct {
+	ID      ids.DataID       `cbor:"id" json:"id"`
+	Version uint64           `cbor:"v" json:"version"`
+	Buyer   *ids.Address     `cbor:"b,omitempty" json:"buyer,omitempty"`
+	Token   *ids.TokenSymbol `cbor:"tk,omitempty" json:"token,omitempty"`
+	Amount  *big.Int         `cbor:"a" json:"amount"`
+	Expire  uint64           `cbor:"e" json:"expire"`
+
+	// external assignment fields
+	raw []byte `cbor:"-" json:"-"`
+}
+
+// SyntacticVerify verifies that a *TxDataOffer is well-formed.
+func (t *TxDataOffer) SyntacticVerify() error {
+	errp := erring.ErrPrefix("ld.TxDataOffer.SyntacticVerify: ")
+
+	switch {
+	case t == nil:
+		return errp.Errorf("nil pointer")
+
+	case t.ID == ids.EmptyDataID:
+		return errp.Errorf("invalid data id")
+
+	case t.Version == 0:
+		return errp.Errorf("invalid data version")
+
+	case t.Token != nil && !t.Token.Valid():
+		return errp.Errorf("invalid token symbol %q", t.Token.GoString())
+
+	case t.Amount == nil || t.Amount.Sign() < 0:
+		return errp.Errorf("invalid amount")
+
+	case t.Expire == 0:
+		return errp.Errorf("invalid expire")
+	}
+
+	var err error
+	if t.raw, err = t.Marshal(); err != nil {
+		return errp.ErrorIf(err)
+	}
+	return nil
+}
+
+func (t *TxDataOffer) Bytes() []byte {
+	if len(t.raw) == 0 {
+		t.raw = MustMarshal(t)
+	}
+	return t.raw
+}
+
+func (t *TxDataOffer) Unmarshal(data []byte) error {
+	return erring.ErrPrefix("ld.TxDataOffer.Unmarshal: ").
+		ErrorIf(encoding.UnmarshalCBOR(data, t))
+}
+
+func (t *TxDataOffer) Marshal() ([]byte, error) {
+	return erring.ErrPrefix("ld.TxDataOffer.Marshal: ").
+		ErrorMap(encoding.MarshalCBOR(t))
+}
+
+// DataOffer is a sell offer of the data, it is valid only with the same data version.
+type DataOffer struct {
+	Seller  ids.Address     `cbor:"se" json:"seller"`
+	Buyer   *ids.Address    `cbor:"b,omitempty" json:"buyer,omitempty"`
+	Token   ids.TokenSymbol `cbor:"tk" json:"token"`
+	Amount  *big.Int        `cbor:"a" json:"amount"`
+	Expire  uint64          `cbor:"e" json:"expire"`
+	Version uint64          `cbor:"v" json:"version"`
+}
+
+func (o *DataOffer) Clone() *DataOffer {
+	x := new(DataOffer)
+	*x = *o
+	if o.Buyer != nil {
+		x.Buyer = o.Buyer.Ptr()
+	}
+	if o.Amount != nil {
+		x.Amount = new(big.Int).Set(o.Amount)
+	}
+	return x
+}
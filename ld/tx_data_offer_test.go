@@ -0,0 +1,63 @@
+// (c) 2022-2022, LDC Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package ld
+
+import (
+	"encoding/json"
+	"math/big"
+	"testing"
+
+	"github.com/ldclabs/ldvm/ids"
+	"github.com/ldclabs/ldvm/signer"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTxDataOffer(t *testing.T) {
+	assert := assert.New(t)
+
+	var tx *TxDataOffer
+	assert.ErrorContains(tx.SyntacticVerify(), "nil pointer")
+
+	tx = &TxDataOffer{}
+	assert.ErrorContains(tx.SyntacticVerify(), "invalid data id")
+
+	tx = &TxDataOffer{ID: ids.DataID{1, 2, 3}}
+	assert.ErrorContains(tx.SyntacticVerify(), "invalid data version")
+
+	tx = &TxDataOffer{ID: ids.DataID{1, 2, 3}, Version: 1, Token: &ids.TokenSymbol{'a'}}
+	assert.ErrorContains(tx.SyntacticVerify(), "invalid token symbol")
+
+	tx = &TxDataOffer{ID: ids.DataID{1, 2, 3}, Version: 1}
+	assert.ErrorContains(tx.SyntacticVerify(), "invalid amount")
+
+	tx = &TxDataOffer{ID: ids.DataID{1, 2, 3}, Version: 1, Amount: big.NewInt(-1)}
+	assert.ErrorContains(tx.SyntacticVerify(), "invalid amount")
+
+	tx = &TxDataOffer{ID: ids.DataID{1, 2, 3}, Version: 1, Amount: big.NewInt(1000)}
+	assert.ErrorContains(tx.SyntacticVerify(), "invalid expire")
+
+	tx = &TxDataOffer{
+		ID:      ids.DataID{1, 2, 3},
+		Version: 1,
+		Buyer:   signer.Signer2.Key().Address().Ptr(),
+		Token:   MustNewToken("$LDC").Ptr(),
+		Amount:  big.NewInt(1000),
+		Expire:  100,
+	}
+	assert.NoError(tx.SyntacticVerify())
+	cbordata, err := tx.Marshal()
+	require.NoError(t, err)
+	jsondata, err := json.Marshal(tx)
+	require.NoError(t, err)
+	assert.Equal(`{"id":"AQIDAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAoWLSv","version":1,"buyer":"0x44171C37Ff5D7B7bb8Dcad5C81f16284A229E641","token":"$LDC","amount":1000,"expire":100}`, string(jsondata))
+
+	tx2 := &TxDataOffer{}
+	assert.NoError(tx2.Unmarshal(cbordata))
+	assert.NoError(tx2.SyntacticVerify())
+	cbordata2 := tx2.Bytes()
+	jsondata2, _ := json.Marshal(tx2)
+	assert.Equal(string(jsondata), string(jsondata2))
+	assert.Equal(cbordata, cbordata2)
+}
@@ -0,0 +1,113 @@
+// (c) 2022-2022, LDC Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package ld
+
+import (
+	"math/big"
+
+	"github.com/ldclabs/ldvm/ids"
+	"github.com/ldclabs/ldvm/util/encoding"
+	"github.com/ldclabs/ldvm/util/erring"
+)
+
+// TxEscrow is the data model for TxCreateEscrow.
+type TxEscrow struct {
+	Arbiter ids.Address `cbor:"ar" json:"arbiter"`
+	Amount  *big.Int    `cbor:"a" json:"amount"`
+
+	// external assignment fields
+	raw []byte `cbor:"-" json:"-"`
+}
+
+// SyntacticVerify verifies that a *TxEscrow is well-formed.
+func (t *TxEscrow) SyntacticVerify() error {
+	errp := erring.ErrPrefix("ld.TxEscrow.SyntacticVerify: ")
+
+	switch {
+	case t == nil:
+		return errp.Errorf("nil pointer")
+
+	case t.Arbiter == ids.EmptyAddress:
+		return errp.Errorf("invalid arbiter")
+
+	case t.Amount == nil || t.Amount.Sign() < 1:
+		return errp.Errorf("invalid amount")
+	}
+
+	var err error
+	if t.raw, err = t.Marshal(); err != nil {
+		return errp.ErrorIf(err)
+	}
+	return nil
+}
+
+func (t *TxEscrow) Bytes() []byte {
+	if len(t.raw) == 0 {
+		t.raw = MustMarshal(t)
+	}
+	return t.raw
+}
+
+func (t *TxEscrow) Unmarshal(data []byte) error {
+	return erring.ErrPrefix("ld.TxEscrow.Unmarshal: ").
+		ErrorIf(encoding.UnmarshalCBOR(data, t))
+}
+
+func (t *TxEscrow) Marshal() ([]byte, error) {
+	return erring.ErrPrefix("ld.TxEscrow.Marshal: ").
+		ErrorMap(encoding.MarshalCBOR(t))
+}
+
+// TxEscrowSettler is the data model for TxSettleEscrow.
+// Release is the amount paid to the payee, the remaining amount is refunded to the payer.
+type TxEscrowSettler struct {
+	Payer   ids.Address `cbor:"pa" json:"payer"`
+	ID      ids.ID32    `cbor:"id" json:"id"` // the escrow ID, aka the ID of TxCreateEscrow
+	Release *big.Int    `cbor:"r" json:"release"`
+
+	// external assignment fields
+	raw []byte `cbor:"-" json:"-"`
+}
+
+// SyntacticVerify verifies that a *TxEscrowSettler is well-formed.
+func (t *TxEscrowSettler) SyntacticVerify() error {
+	errp := erring.ErrPrefix("ld.TxEscrowSettler.SyntacticVerify: ")
+
+	switch {
+	case t == nil:
+		return errp.Errorf("nil pointer")
+
+	case t.Payer == ids.EmptyAddress:
+		return errp.Errorf("invalid payer")
+
+	case t.ID == ids.EmptyID32:
+		return errp.Errorf("invalid id")
+
+	case t.Release == nil || t.Release.Sign() < 0:
+		return errp.Errorf("invalid release")
+	}
+
+	var err error
+	if t.raw, err = t.Marshal(); err != nil {
+		return errp.ErrorIf(err)
+	}
+	return nil
+}
+
+func (t *TxEscrowSettler) Bytes() []byte {
+	if len(t.raw) == 0 {
+		t.raw = MustMarshal(t)
+	}
+	return t.raw
+}
+
+func (t *TxEscrowSettler) Unmarshal(data []byte) error {
+	return erring.ErrPrefix("ld.TxEscrowSettler.Unmarshal: ").
+		ErrorIf(encoding.UnmarshalCBOR(data, t))
+}
+
+func (t *TxEscrowSettler) Marshal() ([]byte, error) {
+	return erring.ErrPrefix("ld.TxEscrowSettler.Marshal: ").
+		ErrorMap(encoding.MarshalCBOR(t))
+}
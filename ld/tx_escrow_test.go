@@ -0,0 +1,83 @@
+// (c) 2022-2022, LDC Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package ld
+
+import (
+	"encoding/json"
+	"math/big"
+	"testing"
+
+	"github.com/ldclabs/ldvm/ids"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTxEscrow(t *testing.T) {
+	assert := assert.New(t)
+
+	var tx *TxEscrow
+	assert.ErrorContains(tx.SyntacticVerify(), "nil pointer")
+
+	tx = &TxEscrow{}
+	assert.ErrorContains(tx.SyntacticVerify(), "invalid arbiter")
+
+	tx = &TxEscrow{Arbiter: ids.GenesisAccount}
+	assert.ErrorContains(tx.SyntacticVerify(), "invalid amount")
+
+	tx = &TxEscrow{Arbiter: ids.GenesisAccount, Amount: big.NewInt(0)}
+	assert.ErrorContains(tx.SyntacticVerify(), "invalid amount")
+
+	tx = &TxEscrow{Arbiter: ids.GenesisAccount, Amount: big.NewInt(1000)}
+	assert.NoError(tx.SyntacticVerify())
+	cbordata, err := tx.Marshal()
+	require.NoError(t, err)
+	jsondata, err := json.Marshal(tx)
+	require.NoError(t, err)
+	// fmt.Println(string(jsondata))
+	assert.Equal(`{"arbiter":"0xFFfFFFfFfffFFfFFffFFFfFfFffFFFfffFfFFFff","amount":1000}`, string(jsondata))
+
+	tx2 := &TxEscrow{}
+	assert.NoError(tx2.Unmarshal(cbordata))
+	assert.NoError(tx2.SyntacticVerify())
+	cbordata2 := tx2.Bytes()
+	jsondata2, _ := json.Marshal(tx2)
+	assert.Equal(string(jsondata), string(jsondata2))
+	assert.Equal(cbordata, cbordata2)
+}
+
+func TestTxEscrowSettler(t *testing.T) {
+	assert := assert.New(t)
+
+	var tx *TxEscrowSettler
+	assert.ErrorContains(tx.SyntacticVerify(), "nil pointer")
+
+	tx = &TxEscrowSettler{}
+	assert.ErrorContains(tx.SyntacticVerify(), "invalid payer")
+
+	tx = &TxEscrowSettler{Payer: ids.GenesisAccount}
+	assert.ErrorContains(tx.SyntacticVerify(), "invalid id")
+
+	tx = &TxEscrowSettler{Payer: ids.GenesisAccount, ID: ids.ID32{1, 2, 3}}
+	assert.ErrorContains(tx.SyntacticVerify(), "invalid release")
+
+	tx = &TxEscrowSettler{Payer: ids.GenesisAccount, ID: ids.ID32{1, 2, 3}, Release: big.NewInt(-1)}
+	assert.ErrorContains(tx.SyntacticVerify(), "invalid release")
+
+	tx = &TxEscrowSettler{Payer: ids.GenesisAccount, ID: ids.ID32{1, 2, 3}, Release: big.NewInt(0)}
+	assert.NoError(tx.SyntacticVerify())
+	cbordata, err := tx.Marshal()
+	require.NoError(t, err)
+	jsondata, err := json.Marshal(tx)
+	require.NoError(t, err)
+	// fmt.Println(string(jsondata))
+	assert.Equal(`{"payer":"0xFFfFFFfFfffFFfFFffFFFfFfFffFFFfffFfFFFff","id":"AQIDAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAoWLSv","release":0}`, string(jsondata))
+
+	tx2 := &TxEscrowSettler{}
+	assert.NoError(tx2.Unmarshal(cbordata))
+	assert.NoError(tx2.SyntacticVerify())
+	cbordata2 := tx2.Bytes()
+	jsondata2, _ := json.Marshal(tx2)
+	assert.Equal(string(jsondata), string(jsondata2))
+	assert.Equal(cbordata, cbordata2)
+}
@@ -16,12 +16,15 @@ type TxExchanger struct {
 	Nonce     uint64          `cbor:"n" json:"nonce"`    // saler' account nonce
 	Sell      ids.TokenSymbol `cbor:"st" json:"sell"`    // token symbol to sell
 	Receive   ids.TokenSymbol `cbor:"rt" json:"receive"` // token symbol to receive
-	Quota     *big.Int        `cbor:"q" json:"quota"`    // token sales quota per a tx
+	Quota     *big.Int        `cbor:"q" json:"quota"`    // token sales quota per a tx, or in total if Partial
 	Minimum   *big.Int        `cbor:"m" json:"minimum"`  // minimum amount to buy
 	Price     *big.Int        `cbor:"p" json:"price"`    // receive token amount = Quota * Price
 	Expire    uint64          `cbor:"e" json:"expire"`
 	Payee     ids.Address     `cbor:"py" json:"payee"`
 	Purchaser *ids.Address    `cbor:"to,omitempty" json:"purchaser,omitempty"` // optional designated purchaser
+	// Partial makes the exchanger a limit order that can be filled by many txs
+	// until the quota is used up, the remaining quota is tracked on the seller's ledger.
+	Partial bool `cbor:"pa,omitempty" json:"partial,omitempty"`
 
 	// external assignment fields
 	raw []byte `cbor:"-" json:"-"`
@@ -64,4 +64,14 @@ func TestTxExchanger(t *testing.T) {
 	jsondata2, _ := json.Marshal(tx2)
 	assert.Equal(string(jsondata), string(jsondata2))
 	assert.Equal(cbordata, cbordata2)
+
+	tx.Partial = true
+	assert.NoError(tx.SyntacticVerify())
+	assert.NotEqual(cbordata, tx.Bytes())
+	jsondata, err = json.Marshal(tx)
+	require.NoError(t, err)
+	assert.Contains(string(jsondata), `"partial":true`)
+	tx2 = &TxExchanger{}
+	assert.NoError(tx2.Unmarshal(tx.Bytes()))
+	assert.True(tx2.Partial)
 }
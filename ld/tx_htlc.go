@@ -0,0 +1,94 @@
+// (c) 2022-2022, LDC Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package ld
+
+import (
+	"crypto/sha256"
+	"math/big"
+
+	"golang.org/x/crypto/sha3"
+
+	"github.com/ldclabs/ldvm/ids"
+	"github.com/ldclabs/ldvm/util/encoding"
+	"github.com/ldclabs/ldvm/util/erring"
+)
+
+// HashAlgo is the hash algorithm of a hashlock.
+type HashAlgo uint8
+
+const (
+	HashSHA3   HashAlgo = iota // SHA3-256
+	HashSHA256                 // SHA-256, compatible with Bitcoin and most EVM HTLC contracts
+)
+
+func (h HashAlgo) Valid() bool {
+	return h <= HashSHA256
+}
+
+// Sum returns the hashlock of the preimage.
+func (h HashAlgo) Sum(preimage []byte) ids.ID32 {
+	switch h {
+	case HashSHA256:
+		return sha256.Sum256(preimage)
+	default:
+		return sha3.Sum256(preimage)
+	}
+}
+
+// TxHTLC is the data model for TxCreateHTLC.
+type TxHTLC struct {
+	HashAlgo HashAlgo `cbor:"ha" json:"hashAlgo"`
+	HashLock ids.ID32 `cbor:"hl" json:"hashLock"`
+	Amount   *big.Int `cbor:"a" json:"amount"`
+	// the recipient can claim before Expire, the sender can refund after Expire.
+	Expire uint64 `cbor:"e" json:"expire"`
+
+	// external assignment fields
+	raw []byte `cbor:"-" json:"-"`
+}
+
+// SyntacticVerify verifies that a *TxHTLC is well-formed.
+func (t *TxHTLC) SyntacticVerify() error {
+	errp := erring.ErrPrefix("ld.TxHTLC.SyntacticVerify: ")
+
+	switch {
+	case t == nil:
+		return errp.Errorf("nil pointer")
+
+	case !t.HashAlgo.Valid():
+		return errp.Errorf("invalid hashAlgo %d", t.HashAlgo)
+
+	case t.HashLock == ids.EmptyID32:
+		return errp.Errorf("invalid hashLock")
+
+	case t.Amount == nil || t.Amount.Sign() < 1:
+		return errp.Errorf("invalid amount")
+
+	case t.Expire == 0:
+		return errp.Errorf("invalid expire")
+	}
+
+	var err error
+	if t.raw, err = t.Marshal(); err != nil {
+		return errp.ErrorIf(err)
+	}
+	return nil
+}
+
+func (t *TxHTLC) Bytes() []byte {
+	if len(t.raw) == 0 {
+		t.raw = MustMarshal(t)
+	}
+	return t.raw
+}
+
+func (t *TxHTLC) Unmarshal(data []byte) error {
+	return erring.ErrPrefix("ld.TxHTLC.Unmarshal: ").
+		ErrorIf(encoding.UnmarshalCBOR(data, t))
+}
+
+func (t *TxHTLC) Marshal() ([]byte, error) {
+	return erring.ErrPrefix("ld.TxHTLC.Marshal: ").
+		ErrorMap(encoding.MarshalCBOR(t))
+}
@@ -0,0 +1,72 @@
+// (c) 2022-2022, LDC Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package ld
+
+import (
+	"crypto/sha256"
+	"encoding/json"
+	"math/big"
+	"testing"
+
+	"github.com/ldclabs/ldvm/ids"
+	"github.com/ldclabs/ldvm/util/encoding"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHashAlgo(t *testing.T) {
+	assert := assert.New(t)
+
+	preimage := []byte("hello")
+	assert.True(HashSHA3.Valid())
+	assert.True(HashSHA256.Valid())
+	assert.False(HashAlgo(2).Valid())
+
+	assert.Equal(ids.ID32(sha256.Sum256(preimage)), HashSHA256.Sum(preimage))
+	assert.Equal(encoding.Sum256(preimage), HashSHA3.Sum(preimage).Bytes())
+}
+
+func TestTxHTLC(t *testing.T) {
+	assert := assert.New(t)
+
+	var tx *TxHTLC
+	assert.ErrorContains(tx.SyntacticVerify(), "nil pointer")
+
+	tx = &TxHTLC{HashAlgo: 2}
+	assert.ErrorContains(tx.SyntacticVerify(), "invalid hashAlgo 2")
+
+	tx = &TxHTLC{}
+	assert.ErrorContains(tx.SyntacticVerify(), "invalid hashLock")
+
+	tx = &TxHTLC{HashLock: HashSHA3.Sum([]byte("hello"))}
+	assert.ErrorContains(tx.SyntacticVerify(), "invalid amount")
+
+	tx = &TxHTLC{HashLock: HashSHA3.Sum([]byte("hello")), Amount: big.NewInt(0)}
+	assert.ErrorContains(tx.SyntacticVerify(), "invalid amount")
+
+	tx = &TxHTLC{HashLock: HashSHA3.Sum([]byte("hello")), Amount: big.NewInt(1000)}
+	assert.ErrorContains(tx.SyntacticVerify(), "invalid expire")
+
+	tx = &TxHTLC{
+		HashAlgo: HashSHA256,
+		HashLock: HashSHA256.Sum([]byte("hello")),
+		Amount:   big.NewInt(1000),
+		Expire:   1000,
+	}
+	assert.NoError(tx.SyntacticVerify())
+	cbordata, err := tx.Marshal()
+	require.NoError(t, err)
+	jsondata, err := json.Marshal(tx)
+	require.NoError(t, err)
+	// fmt.Println(string(jsondata))
+	assert.Equal(`{"hashAlgo":1,"hashLock":"LPJNul-wow4m6DsqxbninhsWHlwfp0JecwQzYpOLmCSB3uC1","amount":1000,"expire":1000}`, string(jsondata))
+
+	tx2 := &TxHTLC{}
+	assert.NoError(tx2.Unmarshal(cbordata))
+	assert.NoError(tx2.SyntacticVerify())
+	cbordata2 := tx2.Bytes()
+	jsondata2, _ := json.Marshal(tx2)
+	assert.Equal(string(jsondata), string(jsondata2))
+	assert.Equal(cbordata, cbordata2)
+}
@@ -0,0 +1,158 @@
+// (c) 2022-2022, LDC Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package ld
+
+import (
+	"github.com/ldclabs/ldvm/ids"
+	"github.com/ldclabs/ldvm/signer"
+	"github.com/ldclabs/ldvm/util/encoding"
+	"github.com/ldclabs/ldvm/util/erring"
+)
+
+// MaxProposals is the maximum number of pending proposals held by an account.
+const MaxProposals = 16
+
+// TxProposal is the data model for TxCreateProposal.
+// The inner Tx is proposed for the multisig account, its signatures (if any)
+// are taken as the first approvals. The proposal can't be approved after Expire.
+// The inner tx's nonce should be the Expire instead of the account's nonce,
+// so that the proposal's ID commits to its Expire.
+type TxProposal struct {
+	Tx     []byte `cbor:"tx" json:"tx"`
+	Expire uint64 `cbor:"e" json:"expire"`
+
+	// external assignment fields
+	tx  *Transaction `cbor:"-" json:"-"`
+	raw []byte       `cbor:"-" json:"-"`
+}
+
+// SyntacticVerify verifies that a *TxProposal is well-formed.
+func (t *TxProposal) SyntacticVerify() error {
+	errp := erring.ErrPrefix("ld.TxProposal.SyntacticVerify: ")
+
+	switch {
+	case t == nil:
+		return errp.Errorf("nil pointer")
+
+	case t.Expire == 0:
+		return errp.Errorf("invalid expire")
+
+	case len(t.Tx) == 0:
+		return errp.Errorf("empty tx")
+	}
+
+	var err error
+	t.tx = &Transaction{}
+	if err = t.tx.Unmarshal(t.Tx); err != nil {
+		return errp.ErrorIf(err)
+	}
+	if err = t.tx.SyntacticVerify(); err != nil {
+		return errp.ErrorIf(err)
+	}
+
+	switch t.tx.Tx.Type {
+	case TypeTest, TypeEth, TypeScheduleTx, TypeCreateProposal, TypeApproveProposal:
+		return errp.Errorf("invalid tx type %s", t.tx.Tx.Type)
+	}
+
+	if t.tx.Tx.Payer != nil {
+		return errp.Errorf("invalid tx, payer should be nil")
+	}
+
+	if t.tx.Tx.Nonce != t.Expire {
+		return errp.Errorf("invalid tx, nonce should be the expire %d, got %d",
+			t.Expire, t.tx.Tx.Nonce)
+	}
+
+	if t.raw, err = t.Marshal(); err != nil {
+		return errp.ErrorIf(err)
+	}
+	return nil
+}
+
+// Transaction returns the inner tx, it should be called after SyntacticVerify.
+func (t *TxProposal) Transaction() *Transaction {
+	return t.tx
+}
+
+// ID returns the proposal's ID, it is the inner tx's SigHash.
+// It should be called after SyntacticVerify.
+func (t *TxProposal) ID() ids.ID32 {
+	var id ids.ID32
+	copy(id[:], t.tx.SigHash())
+	return id
+}
+
+func (t *TxProposal) Bytes() []byte {
+	if len(t.raw) == 0 {
+		t.raw = MustMarshal(t)
+	}
+	return t.raw
+}
+
+func (t *TxProposal) Unmarshal(data []byte) error {
+	return erring.ErrPrefix("ld.TxProposal.Unmarshal: ").
+		ErrorIf(encoding.UnmarshalCBOR(data, t))
+}
+
+func (t *TxProposal) Marshal() ([]byte, error) {
+	return erring.ErrPrefix("ld.TxProposal.Marshal: ").
+		ErrorMap(encoding.MarshalCBOR(t))
+}
+
+// TxProposalApproval is the data model for TxApproveProposal.
+// Sigs are the keepers' signatures over the proposal's ID, aka the inner tx's SigHash.
+type TxProposalApproval struct {
+	ID   ids.ID32    `cbor:"id" json:"id"`
+	Sigs signer.Sigs `cbor:"s" json:"sigs"`
+
+	// external assignment fields
+	raw []byte `cbor:"-" json:"-"`
+}
+
+// SyntacticVerify verifies that a *TxProposalApproval is well-formed.
+func (t *TxProposalApproval) SyntacticVerify() error {
+	errp := erring.ErrPrefix("ld.TxProposalApproval.SyntacticVerify: ")
+
+	switch {
+	case t == nil:
+		return errp.Errorf("nil pointer")
+
+	case t.ID == ids.EmptyID32:
+		return errp.Errorf("invalid id")
+
+	case len(t.Sigs) == 0:
+		return errp.Errorf("empty sigs")
+
+	case len(t.Sigs) > MaxKeepers:
+		return errp.Errorf("too many sigs")
+	}
+
+	var err error
+	if err = t.Sigs.Valid(); err != nil {
+		return errp.ErrorIf(err)
+	}
+
+	if t.raw, err = t.Marshal(); err != nil {
+		return errp.ErrorIf(err)
+	}
+	return nil
+}
+
+func (t *TxProposalApproval) Bytes() []byte {
+	if len(t.raw) == 0 {
+		t.raw = MustMarshal(t)
+	}
+	return t.raw
+}
+
+func (t *TxProposalApproval) Unmarshal(data []byte) error {
+	return erring.ErrPrefix("ld.TxProposalApproval.Unmarshal: ").
+		ErrorIf(encoding.UnmarshalCBOR(data, t))
+}
+
+func (t *TxProposalApproval) Marshal() ([]byte, error) {
+	return erring.ErrPrefix("ld.TxProposalApproval.Marshal: ").
+		ErrorMap(encoding.MarshalCBOR(t))
+}
@@ -0,0 +1,137 @@
+// (c) 2022-2022, LDC Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package ld
+
+import (
+	"encoding/json"
+	"math/big"
+	"testing"
+
+	"github.com/ldclabs/ldvm/ids"
+	"github.com/ldclabs/ldvm/signer"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTxProposal(t *testing.T) {
+	assert := assert.New(t)
+
+	var tx *TxProposal
+	assert.ErrorContains(tx.SyntacticVerify(), "nil pointer")
+
+	tx = &TxProposal{}
+	assert.ErrorContains(tx.SyntacticVerify(), "invalid expire")
+
+	tx = &TxProposal{Expire: 1000}
+	assert.ErrorContains(tx.SyntacticVerify(), "empty tx")
+
+	tx = &TxProposal{Expire: 1000, Tx: []byte{0x80}}
+	assert.ErrorContains(tx.SyntacticVerify(), "ld.Transaction.Unmarshal")
+
+	inner := &Transaction{Tx: TxData{
+		Type:      TypeScheduleTx,
+		ChainID:   gChainID,
+		Nonce:     1,
+		GasTip:    100,
+		GasFeeCap: 1000,
+		From:      signer.Signer1.Key().Address(),
+	}}
+	assert.NoError(inner.SyntacticVerify())
+	tx = &TxProposal{Expire: 1000, Tx: inner.Bytes()}
+	assert.ErrorContains(tx.SyntacticVerify(), "invalid tx type TypeScheduleTx")
+
+	inner = &Transaction{Tx: TxData{
+		Type:      TypeTransfer,
+		ChainID:   gChainID,
+		Nonce:     1,
+		GasTip:    100,
+		GasFeeCap: 1000,
+		From:      signer.Signer1.Key().Address(),
+		To:        signer.Signer2.Key().Address().Ptr(),
+		Payer:     ids.GenesisAccount.Ptr(),
+		Amount:    big.NewInt(1_000_000),
+	}}
+	assert.NoError(inner.SyntacticVerify())
+	tx = &TxProposal{Expire: 1000, Tx: inner.Bytes()}
+	assert.ErrorContains(tx.SyntacticVerify(), "invalid tx, payer should be nil")
+
+	inner = &Transaction{Tx: TxData{
+		Type:      TypeTransfer,
+		ChainID:   gChainID,
+		Nonce:     1,
+		GasTip:    100,
+		GasFeeCap: 1000,
+		From:      signer.Signer1.Key().Address(),
+		To:        signer.Signer2.Key().Address().Ptr(),
+		Amount:    big.NewInt(1_000_000),
+	}}
+	assert.NoError(inner.SyntacticVerify())
+	tx = &TxProposal{Expire: 1000, Tx: inner.Bytes()}
+	assert.ErrorContains(tx.SyntacticVerify(), "invalid tx, nonce should be the expire 1000, got 1")
+
+	inner = &Transaction{Tx: TxData{
+		Type:      TypeTransfer,
+		ChainID:   gChainID,
+		Nonce:     1000,
+		GasTip:    100,
+		GasFeeCap: 1000,
+		From:      signer.Signer1.Key().Address(),
+		To:        signer.Signer2.Key().Address().Ptr(),
+		Amount:    big.NewInt(1_000_000),
+	}}
+	assert.NoError(inner.SyntacticVerify())
+	tx = &TxProposal{Expire: 1000, Tx: inner.Bytes()}
+	assert.NoError(tx.SyntacticVerify())
+	assert.Equal(inner.ID, tx.Transaction().ID)
+	assert.Equal(inner.SigHash(), tx.ID().Bytes())
+
+	cbordata, err := tx.Marshal()
+	require.NoError(t, err)
+	jsondata, err := json.Marshal(tx)
+	require.NoError(t, err)
+	assert.Contains(string(jsondata), `"expire":1000}`)
+
+	tx2 := &TxProposal{}
+	assert.NoError(tx2.Unmarshal(cbordata))
+	assert.NoError(tx2.SyntacticVerify())
+	cbordata2 := tx2.Bytes()
+	jsondata2, _ := json.Marshal(tx2)
+	assert.Equal(string(jsondata), string(jsondata2))
+	assert.Equal(cbordata, cbordata2)
+}
+
+func TestTxProposalApproval(t *testing.T) {
+	assert := assert.New(t)
+
+	var tx *TxProposalApproval
+	assert.ErrorContains(tx.SyntacticVerify(), "nil pointer")
+
+	tx = &TxProposalApproval{}
+	assert.ErrorContains(tx.SyntacticVerify(), "invalid id")
+
+	id := ids.ID32{1, 2, 3}
+	tx = &TxProposalApproval{ID: id}
+	assert.ErrorContains(tx.SyntacticVerify(), "empty sigs")
+
+	sig, err := signer.Signer1.SignHash(id.Bytes())
+	require.NoError(t, err)
+	tx = &TxProposalApproval{ID: id, Sigs: signer.Sigs{sig, sig}}
+	assert.ErrorContains(tx.SyntacticVerify(), "duplicate sig")
+
+	tx = &TxProposalApproval{ID: id, Sigs: signer.Sigs{sig}}
+	assert.NoError(tx.SyntacticVerify())
+
+	cbordata, err := tx.Marshal()
+	require.NoError(t, err)
+	jsondata, err := json.Marshal(tx)
+	require.NoError(t, err)
+
+	tx2 := &TxProposalApproval{}
+	assert.NoError(tx2.Unmarshal(cbordata))
+	assert.NoError(tx2.SyntacticVerify())
+	cbordata2 := tx2.Bytes()
+	jsondata2, _ := json.Marshal(tx2)
+	assert.Equal(string(jsondata), string(jsondata2))
+	assert.Equal(cbordata, cbordata2)
+}
@@ -0,0 +1,76 @@
+// (c) 2022-2022, LDC Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package ld
+
+import (
+	"github.com/ldclabs/ldvm/signer"
+	"github.com/ldclabs/ldvm/util/encoding"
+	"github.com/ldclabs/ldvm/util/erring"
+)
+
+// TxRecovery is the data model for TxSetRecovery.
+// Guardians can start a recovery with Threshold signatures, the new keepers
+// take effect after Delay seconds. Empty guardians removes the recovery config.
+type TxRecovery struct {
+	Threshold uint16      `cbor:"th" json:"threshold"`
+	Guardians signer.Keys `cbor:"gs" json:"guardians"`
+	Delay     uint64      `cbor:"de" json:"delay"`
+
+	// external assignment fields
+	raw []byte `cbor:"-" json:"-"`
+}
+
+// SyntacticVerify verifies that a *TxRecovery is well-formed.
+func (t *TxRecovery) SyntacticVerify() error {
+	var err error
+	errp := erring.ErrPrefix("ld.TxRecovery.SyntacticVerify: ")
+
+	switch {
+	case t == nil:
+		return errp.Errorf("nil pointer")
+
+	case t.Guardians == nil:
+		return errp.Errorf("invalid guardians")
+
+	case len(t.Guardians) > MaxKeepers:
+		return errp.Errorf("invalid guardians, expected <= %d, got %d",
+			MaxKeepers, len(t.Guardians))
+
+	case len(t.Guardians) == 0 && (t.Threshold != 0 || t.Delay != 0):
+		return errp.Errorf("no guardians, threshold and delay should be 0")
+
+	case len(t.Guardians) > 0 && (t.Threshold == 0 || int(t.Threshold) > len(t.Guardians)):
+		return errp.Errorf("invalid threshold, expected in [1, %d], got %d",
+			len(t.Guardians), t.Threshold)
+
+	case len(t.Guardians) > 0 && t.Delay == 0:
+		return errp.Errorf("invalid delay")
+	}
+
+	if err = t.Guardians.Valid(); err != nil {
+		return errp.Errorf("invalid guardians, %v", err)
+	}
+
+	if t.raw, err = t.Marshal(); err != nil {
+		return errp.ErrorIf(err)
+	}
+	return nil
+}
+
+func (t *TxRecovery) Bytes() []byte {
+	if len(t.raw) == 0 {
+		t.raw = MustMarshal(t)
+	}
+	return t.raw
+}
+
+func (t *TxRecovery) Unmarshal(data []byte) error {
+	return erring.ErrPrefix("ld.TxRecovery.Unmarshal: ").
+		ErrorIf(encoding.UnmarshalCBOR(data, t))
+}
+
+func (t *TxRecovery) Marshal() ([]byte, error) {
+	return erring.ErrPrefix("ld.TxRecovery.Marshal: ").
+		ErrorMap(encoding.MarshalCBOR(t))
+}
@@ -0,0 +1,77 @@
+// (c) 2022-2022, LDC Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package ld
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/ldclabs/ldvm/ids"
+	"github.com/ldclabs/ldvm/signer"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTxRecovery(t *testing.T) {
+	assert := assert.New(t)
+
+	var tx *TxRecovery
+	assert.ErrorContains(tx.SyntacticVerify(), "nil pointer")
+
+	tx = &TxRecovery{}
+	assert.ErrorContains(tx.SyntacticVerify(), "invalid guardians")
+
+	tx = &TxRecovery{Guardians: signer.Keys{}, Threshold: 1}
+	assert.ErrorContains(tx.SyntacticVerify(), "no guardians, threshold and delay should be 0")
+
+	tx = &TxRecovery{Guardians: signer.Keys{}, Delay: 1}
+	assert.ErrorContains(tx.SyntacticVerify(), "no guardians, threshold and delay should be 0")
+
+	tx = &TxRecovery{Guardians: signer.Keys{signer.Signer1.Key()}}
+	assert.ErrorContains(tx.SyntacticVerify(), "invalid threshold, expected in [1, 1], got 0")
+
+	tx = &TxRecovery{Guardians: signer.Keys{signer.Signer1.Key()}, Threshold: 2}
+	assert.ErrorContains(tx.SyntacticVerify(), "invalid threshold, expected in [1, 1], got 2")
+
+	tx = &TxRecovery{Guardians: signer.Keys{signer.Signer1.Key()}, Threshold: 1}
+	assert.ErrorContains(tx.SyntacticVerify(), "invalid delay")
+
+	tx = &TxRecovery{
+		Guardians: signer.Keys{signer.Key(ids.EmptyAddress[:])},
+		Threshold: 1,
+		Delay:     100,
+	}
+	assert.ErrorContains(tx.SyntacticVerify(), "empty Secp256k1 key")
+
+	tx = &TxRecovery{
+		Guardians: signer.Keys{signer.Signer1.Key(), signer.Signer1.Key()},
+		Threshold: 1,
+		Delay:     100,
+	}
+	assert.ErrorContains(tx.SyntacticVerify(), "duplicate key jbl8fOziScK5i9wCJsxMKle_UvwKxwPH")
+
+	tx = &TxRecovery{Guardians: signer.Keys{}}
+	assert.NoError(tx.SyntacticVerify())
+
+	tx = &TxRecovery{
+		Guardians: signer.Keys{signer.Signer1.Key(), signer.Signer2.Key()},
+		Threshold: 2,
+		Delay:     100,
+	}
+	assert.NoError(tx.SyntacticVerify())
+	cbordata, err := tx.Marshal()
+	require.NoError(t, err)
+	jsondata, err := json.Marshal(tx)
+	require.NoError(t, err)
+	// fmt.Println(string(jsondata))
+	assert.Equal(`{"threshold":2,"guardians":["jbl8fOziScK5i9wCJsxMKle_UvwKxwPH","RBccN_9de3u43K1cgfFihKIp5kE1lmGG"],"delay":100}`, string(jsondata))
+
+	tx2 := &TxRecovery{}
+	assert.NoError(tx2.Unmarshal(cbordata))
+	assert.NoError(tx2.SyntacticVerify())
+	cbordata2 := tx2.Bytes()
+	jsondata2, _ := json.Marshal(tx2)
+	assert.Equal(string(jsondata), string(jsondata2))
+	assert.Equal(cbordata, cbordata2)
+}
@@ -0,0 +1,108 @@
+// (c) 2022-2022, LDC Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package ld
+
+import (
+	"math/big"
+
+	"github.com/ldclabs/ldvm/util/encoding"
+	"github.com/ldclabs/ldvm/util/erring"
+)
+
+const (
+	// MaxScheduledTxsPerAccount is the maximum number of pending scheduled txs of an account.
+	MaxScheduledTxsPerAccount = 16
+	// MaxScheduledTxsPerBlock is the maximum number of due scheduled txs executed in a block.
+	MaxScheduledTxsPerBlock = 64
+	// MaxScheduleHeightDelay is the maximum height that a tx can be scheduled ahead.
+	MaxScheduleHeightDelay = 1_000_000
+	// MaxScheduleDelay is the maximum seconds that a tx can be scheduled ahead.
+	MaxScheduleDelay = 3600 * 24 * 30
+)
+
+// TxScheduler is the data model for TxScheduleTx.
+// The inner Tx will be executed at the start of the first block
+// that reaches both the target Height and Timestamp.
+type TxScheduler struct {
+	Height    uint64 `cbor:"h,omitempty" json:"height,omitempty"`
+	Timestamp uint64 `cbor:"ts,omitempty" json:"timestamp,omitempty"`
+	// the inner tx signed for schedule, its sender and nonce should be the same as the schedule tx's.
+	Tx []byte `cbor:"tx" json:"tx"`
+
+	// external assignment fields
+	tx  *Transaction `cbor:"-" json:"-"`
+	raw []byte       `cbor:"-" json:"-"`
+}
+
+// SyntacticVerify verifies that a *TxScheduler is well-formed.
+func (t *TxScheduler) SyntacticVerify() error {
+	errp := erring.ErrPrefix("ld.TxScheduler.SyntacticVerify: ")
+
+	switch {
+	case t == nil:
+		return errp.Errorf("nil pointer")
+
+	case t.Height == 0 && t.Timestamp == 0:
+		return errp.Errorf("invalid schedule, height or timestamp required")
+
+	case len(t.Tx) == 0:
+		return errp.Errorf("empty tx")
+	}
+
+	var err error
+	t.tx = &Transaction{}
+	if err = t.tx.Unmarshal(t.Tx); err != nil {
+		return errp.ErrorIf(err)
+	}
+	if err = t.tx.SyntacticVerify(); err != nil {
+		return errp.ErrorIf(err)
+	}
+
+	switch t.tx.Tx.Type {
+	case TypeTest, TypeEth, TypeScheduleTx:
+		return errp.Errorf("invalid tx type %s", t.tx.Tx.Type)
+	}
+
+	if len(t.tx.Signatures) == 0 {
+		return errp.Errorf("no signatures on tx")
+	}
+
+	if !t.tx.Scheduled {
+		return errp.Errorf("tx should be signed for schedule")
+	}
+
+	if t.raw, err = t.Marshal(); err != nil {
+		return errp.ErrorIf(err)
+	}
+	return nil
+}
+
+// Transaction returns the inner tx, it should be called after SyntacticVerify.
+func (t *TxScheduler) Transaction() *Transaction {
+	return t.tx
+}
+
+// Reserve returns the max cost of the inner tx that should be reserved up front.
+func (t *TxScheduler) Reserve() *big.Int {
+	cost := new(big.Int).SetUint64(t.tx.Tx.GasFeeCap)
+	cost.Add(cost, new(big.Int).SetUint64(t.tx.Tx.GasTip))
+	return cost.Mul(cost, new(big.Int).SetUint64(t.tx.Gas()))
+}
+
+func (t *TxScheduler) Bytes() []byte {
+	if len(t.raw) == 0 {
+		t.raw = MustMarshal(t)
+	}
+	return t.raw
+}
+
+func (t *TxScheduler) Unmarshal(data []byte) error {
+	return erring.ErrPrefix("ld.TxScheduler.Unmarshal: ").
+		ErrorIf(encoding.UnmarshalCBOR(data, t))
+}
+
+func (t *TxScheduler) Marshal() ([]byte, error) {
+	return erring.ErrPrefix("ld.TxScheduler.Marshal: ").
+		ErrorMap(encoding.MarshalCBOR(t))
+}
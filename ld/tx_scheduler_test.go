@@ -0,0 +1,90 @@
+// (c) 2022-2022, LDC Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package ld
+
+import (
+	"encoding/json"
+	"math/big"
+	"testing"
+
+	"github.com/ldclabs/ldvm/signer"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTxScheduler(t *testing.T) {
+	assert := assert.New(t)
+
+	var tx *TxScheduler
+	assert.ErrorContains(tx.SyntacticVerify(), "nil pointer")
+
+	tx = &TxScheduler{}
+	assert.ErrorContains(tx.SyntacticVerify(), "invalid schedule, height or timestamp required")
+
+	tx = &TxScheduler{Height: 100}
+	assert.ErrorContains(tx.SyntacticVerify(), "empty tx")
+
+	tx = &TxScheduler{Height: 100, Tx: []byte{0x80}}
+	assert.ErrorContains(tx.SyntacticVerify(), "ld.Transaction.Unmarshal")
+
+	inner := &Transaction{Tx: TxData{
+		Type:      TypeTest,
+		ChainID:   gChainID,
+		Nonce:     1,
+		GasTip:    0,
+		GasFeeCap: 1000,
+		From:      signer.Signer1.Key().Address(),
+	}}
+	assert.NoError(inner.SignWith(signer.Signer1))
+	assert.NoError(inner.SyntacticVerify())
+	tx = &TxScheduler{Height: 100, Tx: inner.Bytes()}
+	assert.ErrorContains(tx.SyntacticVerify(), "invalid tx type TypeTest")
+
+	inner = &Transaction{Tx: TxData{
+		Type:      TypeTransfer,
+		ChainID:   gChainID,
+		Nonce:     1,
+		GasTip:    100,
+		GasFeeCap: 1000,
+		From:      signer.Signer1.Key().Address(),
+		To:        signer.Signer2.Key().Address().Ptr(),
+		Amount:    big.NewInt(1_000_000),
+	}}
+	tx = &TxScheduler{Height: 100, Tx: MustMarshal(inner)}
+	assert.ErrorContains(tx.SyntacticVerify(), "no signatures on tx")
+
+	assert.NoError(inner.SignWith(signer.Signer1))
+	assert.NoError(inner.SyntacticVerify())
+	tx = &TxScheduler{Height: 100, Timestamp: 1000, Tx: inner.Bytes()}
+	assert.ErrorContains(tx.SyntacticVerify(), "tx should be signed for schedule")
+
+	// the signatures for schedule are not valid for the normal tx
+	sigHash := inner.SigHash()
+	assert.NoError(inner.SignForSchedule(signer.Signer1))
+	assert.NoError(inner.SyntacticVerify())
+	assert.True(inner.Scheduled)
+	assert.NotEqual(sigHash, inner.SigHash())
+	assert.Equal(sigHash, inner.TxHash())
+	assert.False(signer.Signer1.Key().Verify(sigHash, inner.Signatures))
+	assert.True(signer.Signer1.Key().Verify(inner.SigHash(), inner.Signatures))
+
+	tx = &TxScheduler{Height: 100, Timestamp: 1000, Tx: inner.Bytes()}
+	assert.NoError(tx.SyntacticVerify())
+	assert.Equal(inner.ID, tx.Transaction().ID)
+	assert.Equal(inner.Gas()*1100, tx.Reserve().Uint64())
+
+	cbordata, err := tx.Marshal()
+	require.NoError(t, err)
+	jsondata, err := json.Marshal(tx)
+	require.NoError(t, err)
+	assert.Contains(string(jsondata), `{"height":100,"timestamp":1000,"tx":`)
+
+	tx2 := &TxScheduler{}
+	assert.NoError(tx2.Unmarshal(cbordata))
+	assert.NoError(tx2.SyntacticVerify())
+	cbordata2 := tx2.Bytes()
+	jsondata2, _ := json.Marshal(tx2)
+	assert.Equal(string(jsondata), string(jsondata2))
+	assert.Equal(cbordata, cbordata2)
+}
@@ -0,0 +1,85 @@
+// (c) 2022-2022, LDC Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package ld
+
+import (
+	"math/big"
+	"time"
+
+	"github.com/ldclabs/ldvm/util/encoding"
+	"github.com/ldclabs/ldvm/util/erring"
+)
+
+// SubscriptionPeriod is the billing period of a subscription, in UTC.
+type SubscriptionPeriod uint8
+
+const (
+	PeriodDaily   SubscriptionPeriod = iota // from 00:00:00 of a day
+	PeriodMonthly                           // from 00:00:00 of the first day of a month
+)
+
+func (p SubscriptionPeriod) Valid() bool {
+	return p <= PeriodMonthly
+}
+
+// Start returns the start timestamp of the period that the timestamp falls in.
+func (p SubscriptionPeriod) Start(timestamp uint64) uint64 {
+	switch p {
+	case PeriodMonthly:
+		t := time.Unix(int64(timestamp), 0).UTC()
+		return uint64(time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, time.UTC).Unix())
+	default:
+		return timestamp - timestamp%86400
+	}
+}
+
+// TxSubscription is the data model for TxCreateSubscription.
+// The payee can pull up to Limit of token from the payer in each period, Expire 0 means no expiry.
+type TxSubscription struct {
+	Limit  *big.Int           `cbor:"l" json:"limit"`
+	Period SubscriptionPeriod `cbor:"p" json:"period"`
+	Expire uint64             `cbor:"e,omitempty" json:"expire,omitempty"`
+
+	// external assignment fields
+	raw []byte `cbor:"-" json:"-"`
+}
+
+// SyntacticVerify verifies that a *TxSubscription is well-formed.
+func (t *TxSubscription) SyntacticVerify() error {
+	errp := erring.ErrPrefix("ld.TxSubscription.SyntacticVerify: ")
+
+	switch {
+	case t == nil:
+		return errp.Errorf("nil pointer")
+
+	case t.Limit == nil || t.Limit.Sign() <= 0:
+		return errp.Errorf("invalid limit")
+
+	case !t.Period.Valid():
+		return errp.Errorf("invalid period %d", t.Period)
+	}
+
+	var err error
+	if t.raw, err = t.Marshal(); err != nil {
+		return errp.ErrorIf(err)
+	}
+	return nil
+}
+
+func (t *TxSubscription) Bytes() []byte {
+	if len(t.raw) == 0 {
+		t.raw = MustMarshal(t)
+	}
+	return t.raw
+}
+
+func (t *TxSubscription) Unmarshal(data []byte) error {
+	return erring.ErrPrefix("ld.TxSubscription.Unmarshal: ").
+		ErrorIf(encoding.UnmarshalCBOR(data, t))
+}
+
+func (t *TxSubscription) Marshal() ([]byte, error) {
+	return erring.ErrPrefix("ld.TxSubscription.Marshal: ").
+		ErrorMap(encoding.MarshalCBOR(t))
+}
@@ -0,0 +1,62 @@
+// (c) 2022-2022, LDC Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package ld
+
+import (
+	"encoding/json"
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSubscriptionPeriod(t *testing.T) {
+	assert := assert.New(t)
+
+	assert.True(PeriodDaily.Valid())
+	assert.True(PeriodMonthly.Valid())
+	assert.False(SubscriptionPeriod(2).Valid())
+
+	// 2022-03-15T10:00:00Z
+	ts := uint64(1647338400)
+	assert.Equal(uint64(1647302400), PeriodDaily.Start(ts))           // 2022-03-15T00:00:00Z
+	assert.Equal(uint64(1646092800), PeriodMonthly.Start(ts))         // 2022-03-01T00:00:00Z
+	assert.Equal(uint64(1646092800), PeriodMonthly.Start(1646092800)) // 2022-03-01T00:00:00Z
+	assert.Equal(uint64(1643673600), PeriodMonthly.Start(1646092799)) // 2022-02-01T00:00:00Z
+	assert.Equal(uint64(0), PeriodDaily.Start(86399))
+	assert.Equal(uint64(86400), PeriodDaily.Start(86400))
+}
+
+func TestTxSubscription(t *testing.T) {
+	assert := assert.New(t)
+
+	var tx *TxSubscription
+	assert.ErrorContains(tx.SyntacticVerify(), "nil pointer")
+
+	tx = &TxSubscription{}
+	assert.ErrorContains(tx.SyntacticVerify(), "invalid limit")
+
+	tx = &TxSubscription{Limit: big.NewInt(0)}
+	assert.ErrorContains(tx.SyntacticVerify(), "invalid limit")
+
+	tx = &TxSubscription{Limit: big.NewInt(1000), Period: 2}
+	assert.ErrorContains(tx.SyntacticVerify(), "invalid period 2")
+
+	tx = &TxSubscription{Limit: big.NewInt(1000), Period: PeriodMonthly, Expire: 1000}
+	assert.NoError(tx.SyntacticVerify())
+	cbordata, err := tx.Marshal()
+	require.NoError(t, err)
+	jsondata, err := json.Marshal(tx)
+	require.NoError(t, err)
+	assert.Equal(`{"limit":1000,"period":1,"expire":1000}`, string(jsondata))
+
+	tx2 := &TxSubscription{}
+	assert.NoError(tx2.Unmarshal(cbordata))
+	assert.NoError(tx2.SyntacticVerify())
+	cbordata2 := tx2.Bytes()
+	jsondata2, _ := json.Marshal(tx2)
+	assert.Equal(string(jsondata), string(jsondata2))
+	assert.Equal(cbordata, cbordata2)
+}
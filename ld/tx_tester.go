@@ -5,6 +5,7 @@ package ld
 
 import (
 	"fmt"
+	"math/big"
 
 	cborpatch "github.com/ldclabs/cbor-patch"
 	"github.com/ldclabs/ldvm/ids"
@@ -41,11 +42,99 @@ func (t ObjectType) MarshalJSON() ([]byte, error) {
 	return []byte("\"" + t.String() + "\""), nil
 }
 
+const (
+	CompareGT CompareOp = iota + 1
+	CompareGTE
+	CompareLT
+	CompareLTE
+	CompareRange // Value <= node <= Max
+)
+
+type CompareOp uint8
+
+func (op CompareOp) String() string {
+	switch op {
+	case CompareGT:
+		return "gt"
+	case CompareGTE:
+		return "gte"
+	case CompareLT:
+		return "lt"
+	case CompareLTE:
+		return "lte"
+	case CompareRange:
+		return "range"
+	default:
+		return fmt.Sprintf("UnknownCompareOp(%d)", op)
+	}
+}
+
+func (op CompareOp) MarshalJSON() ([]byte, error) {
+	return []byte("\"" + op.String() + "\""), nil
+}
+
+// Comparison compares the integer or big.Int node at the Path with the Value.
+type Comparison struct {
+	Op    CompareOp      `cbor:"op" json:"op"`
+	Path  cborpatch.Path `cbor:"p" json:"path"`
+	Value *big.Int       `cbor:"v" json:"value"`                   // min value for CompareRange
+	Max   *big.Int       `cbor:"m,omitempty" json:"max,omitempty"` // only for CompareRange
+}
+
+// SyntacticVerify verifies that a *Comparison is well-formed.
+func (c *Comparison) SyntacticVerify() error {
+	errp := erring.ErrPrefix("ld.Comparison.SyntacticVerify: ")
+
+	switch {
+	case c == nil:
+		return errp.Errorf("nil pointer")
+
+	case c.Op < CompareGT || c.Op > CompareRange:
+		return errp.Errorf("invalid op %s", c.Op)
+
+	case c.Value == nil:
+		return errp.Errorf("nil value")
+
+	case c.Op == CompareRange && (c.Max == nil || c.Max.Cmp(c.Value) < 0):
+		return errp.Errorf("invalid max for range, expected >= %v, got %v", c.Value, c.Max)
+
+	case c.Op != CompareRange && c.Max != nil:
+		return errp.Errorf("invalid max, should be nil")
+	}
+
+	for _, key := range c.Path {
+		if err := key.Valid(); err != nil {
+			return errp.Errorf("invalid path %s, %v", c.Path, err)
+		}
+	}
+	return nil
+}
+
+// Compare compares the value with the comparison.
+func (c *Comparison) Compare(v *big.Int) bool {
+	switch c.Op {
+	case CompareGT:
+		return v.Cmp(c.Value) > 0
+	case CompareGTE:
+		return v.Cmp(c.Value) >= 0
+	case CompareLT:
+		return v.Cmp(c.Value) < 0
+	case CompareLTE:
+		return v.Cmp(c.Value) <= 0
+	case CompareRange:
+		return v.Cmp(c.Value) >= 0 && v.Cmp(c.Max) <= 0
+	default:
+		return false
+	}
+}
+
 // TxTester
 type TxTester struct {
 	ObjectType ObjectType      `cbor:"ot" json:"objectType"`
 	ObjectID   string          `cbor:"oid" json:"objectID"`
 	Tests      cborpatch.Patch `cbor:"ts" json:"tests"`
+	// Compares are numeric comparisons on the object, evaluated after the Tests
+	Compares []*Comparison `cbor:"cs,omitempty" json:"compares,omitempty"`
 
 	// external assignment fields
 	ID32 ids.ID32 `cbor:"-" json:"-"`
@@ -64,7 +153,7 @@ func (t *TxTester) SyntacticVerify() error {
 	case t.ObjectID == "":
 		return errp.Errorf("empty objectID")
 
-	case len(t.Tests) == 0:
+	case len(t.Tests) == 0 && len(t.Compares) == 0:
 		return errp.Errorf("empty tests")
 	}
 
@@ -105,6 +194,12 @@ func (t *TxTester) SyntacticVerify() error {
 		}
 	}
 
+	for _, c := range t.Compares {
+		if err = c.SyntacticVerify(); err != nil {
+			return errp.ErrorIf(err)
+		}
+	}
+
 	if t.raw, err = t.Marshal(); err != nil {
 		return errp.ErrorIf(err)
 	}
@@ -118,6 +213,11 @@ func (t *TxTester) maybeTestData() bool {
 				return true
 			}
 		}
+		for _, c := range t.Compares {
+			if len(c.Path) > 1 && c.Path[0].Is("pl") {
+				return true
+			}
+		}
 	}
 	return false
 }
@@ -161,7 +261,33 @@ func (t *TxTester) Test(doc []byte) error {
 		}
 	}
 
-	return errp.ErrorIf(node.Patch(t.Tests, opts))
+	if len(t.Tests) > 0 {
+		if err = node.Patch(t.Tests, opts); err != nil {
+			return errp.ErrorIf(err)
+		}
+	}
+
+	for _, c := range t.Compares {
+		data, err := node.GetValue(c.Path, opts)
+		if err != nil {
+			return errp.Errorf("compare operation for path %s failed, %v", c.Path, err)
+		}
+
+		v := new(big.Int)
+		if err = encoding.UnmarshalCBOR(data, v); err != nil {
+			return errp.Errorf("compare operation for path %s failed, %v", c.Path, err)
+		}
+
+		if !c.Compare(v) {
+			if c.Op == CompareRange {
+				return errp.Errorf("compare operation for path %s failed, expected in [%v, %v], got %v",
+					c.Path, c.Value, c.Max, v)
+			}
+			return errp.Errorf("compare operation for path %s failed, expected %s %v, got %v",
+				c.Path, c.Op, c.Value, v)
+		}
+	}
+	return nil
 }
 
 func (t *TxTester) Bytes() []byte {
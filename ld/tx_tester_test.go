@@ -206,3 +206,107 @@ func TestTxTester(t *testing.T) {
 	assert.NoError(di.SyntacticVerify())
 	assert.NoError(tx.Test(di.Bytes()))
 }
+
+func TestTxTesterCompares(t *testing.T) {
+	assert := assert.New(t)
+
+	assert.Equal("gte", CompareGTE.String())
+	assert.Equal("range", CompareRange.String())
+	assert.Equal("UnknownCompareOp(9)", CompareOp(9).String())
+
+	var c *Comparison
+	assert.ErrorContains(c.SyntacticVerify(), "nil pointer")
+	c = &Comparison{}
+	assert.ErrorContains(c.SyntacticVerify(), "invalid op UnknownCompareOp(0)")
+	c = &Comparison{Op: CompareGT}
+	assert.ErrorContains(c.SyntacticVerify(), "nil value")
+	c = &Comparison{Op: CompareGT, Value: big.NewInt(1), Max: big.NewInt(2)}
+	assert.ErrorContains(c.SyntacticVerify(), "invalid max, should be nil")
+	c = &Comparison{Op: CompareRange, Value: big.NewInt(1)}
+	assert.ErrorContains(c.SyntacticVerify(), "invalid max for range, expected >= 1, got <nil>")
+	c = &Comparison{Op: CompareRange, Value: big.NewInt(2), Max: big.NewInt(1)}
+	assert.ErrorContains(c.SyntacticVerify(), "invalid max for range, expected >= 2, got 1")
+	c = &Comparison{Op: CompareRange, Value: big.NewInt(1), Max: big.NewInt(1)}
+	assert.NoError(c.SyntacticVerify())
+
+	tx := &TxTester{
+		ObjectType: AddressObject,
+		ObjectID:   ids.GenesisAccount.String(),
+		Compares:   []*Comparison{{Op: CompareLT}},
+	}
+	assert.ErrorContains(tx.SyntacticVerify(), "nil value")
+
+	tx = &TxTester{
+		ObjectType: AddressObject,
+		ObjectID:   ids.GenesisAccount.String(),
+		Compares: []*Comparison{
+			{Op: CompareGTE, Path: cborpatch.PathMustFrom("b"), Value: new(big.Int).SetUint64(unit.LDC)},
+			{Op: CompareLT, Path: cborpatch.PathMustFrom("n"), Value: big.NewInt(5)},
+			{Op: CompareRange, Path: cborpatch.PathMustFrom("th"), Value: big.NewInt(1), Max: big.NewInt(2)},
+		},
+	}
+	assert.NoError(tx.SyntacticVerify())
+
+	diag := encoding.MustDiagString(tx.Bytes())
+	// fmt.Println(diag)
+	assert.Equal(`{"cs": [{"p": ["b"], "v": 1000000000, "op": 2}, {"p": ["n"], "v": 5, "op": 3}, {"m": 2, "p": ["th"], "v": 1, "op": 5}], "ot": 0, "ts": null, "oid": "0xFFfFFFfFfffFFfFFffFFFfFfFffFFFfffFfFFFff"}`, diag)
+
+	tx2 := &TxTester{}
+	assert.NoError(tx2.Unmarshal(tx.Bytes()))
+	assert.NoError(tx2.SyntacticVerify())
+	assert.Equal(tx.Bytes(), tx2.Bytes())
+
+	acc := &Account{
+		Nonce:      4,
+		Balance:    new(big.Int).SetUint64(unit.LDC),
+		Threshold:  1,
+		Keepers:    signer.Keys{signer.Signer1.Key()},
+		Tokens:     make(map[cbor.ByteString]*big.Int),
+		NonceTable: make(map[uint64][]uint64),
+	}
+	assert.NoError(acc.SyntacticVerify())
+	assert.NoError(tx.Test(acc.Bytes()))
+
+	acc.Balance.SetUint64(unit.LDC - 1)
+	assert.NoError(acc.SyntacticVerify())
+	assert.ErrorContains(tx.Test(acc.Bytes()),
+		`compare operation for path ["b"] failed, expected gte 1000000000, got 999999999`)
+
+	acc.Balance.SetUint64(unit.LDC)
+	acc.Nonce = 5
+	assert.NoError(acc.SyntacticVerify())
+	assert.ErrorContains(tx.Test(acc.Bytes()),
+		`compare operation for path ["n"] failed, expected lt 5, got 5`)
+
+	acc.Nonce = 4
+	acc.Threshold = 0
+	acc.Keepers = signer.Keys{}
+	assert.NoError(acc.SyntacticVerify())
+	assert.ErrorContains(tx.Test(acc.Bytes()),
+		`compare operation for path ["th"] failed, expected in [1, 2], got 0`)
+
+	tx.Compares = append(tx.Compares,
+		&Comparison{Op: CompareGT, Path: cborpatch.PathMustFrom("kp"), Value: big.NewInt(0)})
+	assert.NoError(tx.SyntacticVerify())
+	acc.Threshold = 1
+	acc.Keepers = signer.Keys{signer.Signer1.Key()}
+	assert.NoError(acc.SyntacticVerify())
+	assert.ErrorContains(tx.Test(acc.Bytes()),
+		`compare operation for path ["kp"] failed, cbor: cannot unmarshal array into Go value of type big.Int`)
+
+	tx.Compares[3].Path = cborpatch.PathMustFrom("x")
+	assert.NoError(tx.SyntacticVerify())
+	assert.ErrorContains(tx.Test(acc.Bytes()),
+		`compare operation for path ["x"] failed`)
+
+	// DataObject with JSON payload
+	tx = &TxTester{
+		ObjectType: DataObject,
+		ObjectID:   ids.DataID{1, 2, 3}.String(),
+		Compares: []*Comparison{
+			{Op: CompareLTE, Path: cborpatch.PathMustFrom("pl", "price"), Value: big.NewInt(100)},
+		},
+	}
+	assert.NoError(tx.SyntacticVerify())
+	assert.True(tx.maybeTestData())
+}
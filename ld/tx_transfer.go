@@ -16,6 +16,7 @@ import (
 // TxTransferPay{To[, Token, Amount, Expire, Data]}
 // TxTransferCash{Nonce, From, Amount, Expire[, Token, To, Data]}
 // TxTakeStake{Nonce, From, To, Amount, Expire[, Data]}
+// TxPullSubscription{From, Amount[, Token, To, Expire, Data]}
 type TxTransfer struct {
 	Nonce  uint64           `cbor:"n,omitempty" json:"nonce,omitempty"`  // sender's nonce
 	From   *ids.Address     `cbor:"fr,omitempty" json:"from,omitempty"`  // amount sender
@@ -14,28 +14,30 @@ import (
 
 // TxUpdater is a hybrid data model for:
 //
-// TxCreateData{ModelID, Version, Threshold, Keepers, Data[, Approver, ApproveList]} no model keepers
-// TxCreateData{ModelID, Version, To, Amount, Threshold, Keepers, Data, Expire[, Approver, ApproveList]} with model keepers
+// TxCreateData{ModelID, Version, Threshold, Keepers, Data[, Weights, Approver, ApproveList, ExpiresAt]} no model keepers
+// TxCreateData{ModelID, Version, To, Amount, Threshold, Keepers, Data, Expire[, Weights, Approver, ApproveList, ExpiresAt]} with model keepers
 //
-// TxUpdateData{ID, Version, Data} no model keepers
-// TxUpdateData{ID, Version, SigClaims, Sig, Data} no model keepers
-// TxUpdateData{ID, Version, To, Amount, Data, Expire} with model keepers
-// TxUpdateData{ID, Version, SigClaims, Sig, To, Amount, Data, Expire} with model keepers
+// TxUpdateData{ID, Version, Data[, ExpiresAt]} no model keepers
+// TxUpdateData{ID, Version, SigClaims, Sig, Data[, ExpiresAt]} no model keepers
+// TxUpdateData{ID, Version, To, Amount, Data, Expire[, ExpiresAt]} with model keepers
+// TxUpdateData{ID, Version, SigClaims, Sig, To, Amount, Data, Expire[, ExpiresAt]} with model keepers
 // TxUpgradeData{ID, Version, To, Amount, Data, Expire} with model keepers
 // TxUpgradeData{ID, Version, SigClaims, Sig, To, Amount, Data, Expire} with model keepers
 //
 // TxDeleteData{ID, Version[, Data]}
 //
-// TxUpdateDataInfo{ID, Version, Threshold, Keepers[, SigClaims, Sig, Approver, ApproveList]}
+// TxUpdateDataInfo{ID, Version, Threshold, Keepers[, Weights, SigClaims, Sig, Approver, ApproveList]}
 // TxUpdateDataInfoByAuth{ID, Version, To, Amount, Threshold, Keepers, Expire[, Approver, ApproveList, Token]}
 //
-// TxUpdateModelInfo{ModelID, Threshold, Keepers[, Approver]}
+// TxUpdateModelInfo{ModelID, Threshold, Keepers[, Weights, Approver]}
+// TxUpgradeModel{ModelID, Version, Data}
 type TxUpdater struct {
 	ID          *ids.DataID      `cbor:"id,omitempty" json:"id,omitempty"`     // data id
 	ModelID     *ids.ModelID     `cbor:"mid,omitempty" json:"mid,omitempty"`   // model id
 	Version     uint64           `cbor:"v,omitempty" json:"version,omitempty"` // data version
 	Threshold   *uint16          `cbor:"th,omitempty" json:"threshold,omitempty"`
 	Keepers     *signer.Keys     `cbor:"kp,omitempty" json:"keepers,omitempty"`
+	Weights     *[]uint16        `cbor:"kw,omitempty" json:"weights,omitempty"`
 	Approver    *signer.Key      `cbor:"ap,omitempty" json:"approver,omitempty"`
 	ApproveList *TxTypes         `cbor:"apl,omitempty" json:"approveList,omitempty"`
 	Token       *ids.TokenSymbol `cbor:"tk,omitempty" json:"token,omitempty"` // token symbol, default is NativeToken
@@ -44,6 +46,7 @@ type TxUpdater struct {
 	SigClaims   *SigClaims       `cbor:"sc,omitempty" json:"sigClaims,omitempty"`
 	Sig         *signer.Sig      `cbor:"s,omitempty" json:"sig,omitempty"`
 	Expire      uint64           `cbor:"e,omitempty" json:"expire,omitempty"`
+	ExpiresAt   *uint64          `cbor:"ea,omitempty" json:"expiresAt,omitempty"` // data's expiry time, 0 means never
 	Data        encoding.RawData `cbor:"d,omitempty" json:"data,omitempty"`
 
 	// external assignment fields
@@ -71,6 +74,9 @@ func (t *TxUpdater) SyntacticVerify() error {
 	case t.Keepers != nil && t.Threshold == nil:
 		return errp.Errorf("invalid threshold")
 
+	case t.Keepers == nil && t.Weights != nil:
+		return errp.Errorf("no keepers, weights should be nil")
+
 	case t.SigClaims == nil && t.Sig != nil:
 		return errp.Errorf("no sigClaims, typed signature should be nil")
 
@@ -79,10 +85,18 @@ func (t *TxUpdater) SyntacticVerify() error {
 	}
 
 	if t.Keepers != nil {
-		switch {
-		case int(*t.Threshold) > len(*t.Keepers):
+		var weights []uint16
+		if t.Weights != nil {
+			weights = *t.Weights
+		}
+		if err = t.Keepers.ValidWeights(weights); err != nil {
+			return errp.Errorf("invalid weights, %v", err)
+		}
+
+		switch w := t.Keepers.Weight(weights...); {
+		case uint64(*t.Threshold) > w:
 			return errp.Errorf("invalid threshold, expected <= %d, got %d",
-				len(*t.Keepers), *t.Threshold)
+				w, *t.Threshold)
 
 		case len(*t.Keepers) > MaxKeepers:
 			return errp.Errorf("invalid keepers, expected <= %d, got %d",
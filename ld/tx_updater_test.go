@@ -41,14 +41,22 @@ func TestTxUpdater(t *testing.T) {
 		"invalid threshold, expected <= 0, got 1")
 	tx = &TxUpdater{Threshold: Uint16Ptr(1), Keepers: &signer.Keys{signer.Key(ids.EmptyAddress[:])}}
 	assert.ErrorContains(tx.SyntacticVerify(), "empty Secp256k1 key")
+	tx = &TxUpdater{Weights: &[]uint16{1}}
+	assert.ErrorContains(tx.SyntacticVerify(), "no keepers, weights should be nil")
+	tx = &TxUpdater{Threshold: Uint16Ptr(1), Keepers: &signer.Keys{signer.Signer1.Key()},
+		Weights: &[]uint16{0}}
+	assert.ErrorContains(tx.SyntacticVerify(), "invalid weights, signer.Keys.ValidWeights: invalid weight 0")
+	tx = &TxUpdater{Threshold: Uint16Ptr(5), Keepers: &signer.Keys{signer.Signer1.Key(), signer.Signer2.Key()},
+		Weights: &[]uint16{3, 1}}
+	assert.ErrorContains(tx.SyntacticVerify(), "invalid threshold, expected <= 4, got 5")
 
 	tx = &TxUpdater{ApproveList: &TxTypes{TypeCreateData}}
 	assert.ErrorContains(tx.SyntacticVerify(),
 		"invalid TxType TypeCreateData in approveList")
 
-	tx = &TxUpdater{ApproveList: &TxTypes{TypeDeleteData + 1}}
+	tx = &TxUpdater{ApproveList: &TxTypes{TypeUpgradeModel + 1}}
 	assert.ErrorContains(tx.SyntacticVerify(),
-		"invalid TxType TypeUnknown(25) in approveList")
+		"invalid TxType TypeUnknown(28) in approveList")
 
 	tx = &TxUpdater{ApproveList: &TxTypes{
 		TypeUpdateDataInfo, TypeDeleteData, TypeUpdateDataInfo}}
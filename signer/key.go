@@ -6,6 +6,7 @@ package signer
 import (
 	"errors"
 	"math"
+	"strconv"
 
 	"github.com/fxamacker/cbor/v2"
 	"github.com/ldclabs/ldvm/ids"
@@ -314,16 +315,63 @@ func (ks Keys) Clone() Keys {
 	return nks
 }
 
-func (ks Keys) Verify(digestHash []byte, sigs Sigs, threshold uint16) bool {
+// Weight returns the total weight of the keys,
+// every key weighs 1 when weights is empty.
+func (ks Keys) Weight(weights ...uint16) uint64 {
+	if len(weights) == 0 {
+		return uint64(len(ks))
+	}
+
+	w := uint64(0)
+	for _, x := range weights {
+		w += uint64(x)
+	}
+	return w
+}
+
+// ValidWeights checks that the weights is empty, or matches the keys one by one with non-zero values.
+func (ks Keys) ValidWeights(weights []uint16) error {
+	if len(weights) == 0 {
+		return nil
+	}
+
+	if len(weights) != len(ks) {
+		return errors.New("signer.Keys.ValidWeights: expected " + strconv.Itoa(len(ks)) +
+			" weights, got " + strconv.Itoa(len(weights)))
+	}
+
+	for i, w := range weights {
+		if w == 0 {
+			return errors.New("signer.Keys.ValidWeights: invalid weight 0 for key " + ks[i].String())
+		}
+	}
+	return nil
+}
+
+// Verify verifies that the signatures reach the threshold,
+// it sums the weights of the signing keys if weights is given, otherwise it counts them.
+func (ks Keys) Verify(digestHash []byte, sigs Sigs, threshold uint16, weights ...uint16) bool {
 	ksLen := len(ks)
 	if ksLen == 0 || ksLen > math.MaxUint8 || threshold == 0 ||
-		ksLen < int(threshold) || len(sigs) < int(threshold) {
+		ks.ValidWeights(weights) != nil || ks.Weight(weights...) < uint64(threshold) {
 		return false
 	}
 
-	t := uint16(0)
+	if len(weights) == 0 && len(sigs) < int(threshold) {
+		return false
+	}
+
+	t := uint64(0)
 	remaining := make([]Key, ksLen)
 	copy(remaining, ks)
+	remainingWeights := make([]uint16, ksLen)
+	if len(weights) == 0 {
+		for i := range remainingWeights {
+			remainingWeights[i] = 1
+		}
+	} else {
+		copy(remainingWeights, weights)
+	}
 
 	dset := make(map[cbor.ByteString]struct{}, len(sigs))
 
@@ -335,21 +383,24 @@ func (ks Keys) Verify(digestHash []byte, sigs Sigs, threshold uint16) bool {
 		dset[sigStr] = struct{}{}
 
 		if i := sig.FindKey(digestHash, remaining...); i >= 0 {
-			t += 1
-			if t >= threshold {
+			t += uint64(remainingWeights[i])
+			if t >= uint64(threshold) {
 				return true
 			}
 
 			remaining = append(remaining[:i], remaining[i+1:]...)
+			remainingWeights = append(remainingWeights[:i], remainingWeights[i+1:]...)
 		}
 	}
 
 	return false
 }
 
-func (ks Keys) VerifyPlus(digestHash []byte, sigs Sigs, threshold uint16) bool {
-	if int(threshold) < len(ks) {
+// VerifyPlus is like Verify, but requires one more weight than the threshold
+// if the threshold is less than the total weight.
+func (ks Keys) VerifyPlus(digestHash []byte, sigs Sigs, threshold uint16, weights ...uint16) bool {
+	if uint64(threshold) < ks.Weight(weights...) && threshold < math.MaxUint16 {
 		threshold += 1
 	}
-	return ks.Verify(digestHash, sigs, threshold)
+	return ks.Verify(digestHash, sigs, threshold, weights...)
 }
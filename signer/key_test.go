@@ -381,6 +381,45 @@ func TestKeys(t *testing.T) {
 	assert.False(keys.VerifyPlus(msg, Sigs{sig1, sig2, sig2, Sig{}}, 3))
 }
 
+func TestWeightedKeys(t *testing.T) {
+	assert := assert.New(t)
+
+	keys := Keys{Signer1.Key(), Signer2.Key(), Signer4.Key()}
+	assert.Equal(uint64(3), keys.Weight())
+	assert.Equal(uint64(5), keys.Weight(3, 1, 1))
+
+	assert.NoError(keys.ValidWeights(nil))
+	assert.NoError(keys.ValidWeights([]uint16{3, 1, 1}))
+	assert.ErrorContains(keys.ValidWeights([]uint16{3, 1}), "expected 3 weights, got 2")
+	assert.ErrorContains(keys.ValidWeights([]uint16{3, 0, 1}),
+		"invalid weight 0 for key RBccN_9de3u43K1cgfFihKIp5kE1lmGG")
+
+	msg := encoding.Sum256([]byte("LDC Labs"))
+	sig1, err := Signer1.SignHash(msg)
+	require.NoError(t, err)
+	sig2, err := Signer2.SignHash(msg)
+	require.NoError(t, err)
+	sig3, err := Signer4.SignHash(msg)
+	require.NoError(t, err)
+
+	// CFO key weighs 3, engineer keys weigh 1, threshold 4
+	weights := []uint16{3, 1, 1}
+	assert.False(keys.Verify(msg, Sigs{sig1}, 4, weights...))
+	assert.False(keys.Verify(msg, Sigs{sig2, sig3}, 4, weights...))
+	assert.True(keys.Verify(msg, Sigs{sig1, sig2}, 4, weights...))
+	assert.True(keys.Verify(msg, Sigs{sig3, sig1}, 4, weights...))
+	assert.True(keys.Verify(msg, Sigs{sig1}, 3, weights...))
+	assert.False(keys.Verify(msg, Sigs{sig1, sig2, sig3}, 6, weights...))
+	assert.False(keys.Verify(msg, Sigs{sig1, sig2}, 4, 3, 1))
+	assert.False(keys.Verify(msg, Sigs{sig1, sig2}, 4, 3, 0, 1))
+	assert.False(keys.Verify(msg, Sigs{sig1, sig1}, 4, weights...))
+
+	assert.False(keys.VerifyPlus(msg, Sigs{sig1, sig2}, 4, weights...))
+	assert.True(keys.VerifyPlus(msg, Sigs{sig1, sig2, sig3}, 4, weights...))
+	assert.True(keys.VerifyPlus(msg, Sigs{sig1, sig2, sig3}, 5, weights...))
+	assert.True(keys.VerifyPlus(msg, Sigs{sig1}, 2, weights...))
+}
+
 func TestKeyInStruct(t *testing.T) {
 	assert := assert.New(t)
 
@@ -61,16 +61,24 @@ func NewTxPool(pos POS, chain Chain, opts TxPoolOptions) *TxPool {
 }
 
 type TxOrBatch struct {
-	Tx           *ld.TxData  `cbor:"tx,omitempty"`
-	Signatures   signer.Sigs `cbor:"ss,omitempty"`
-	ExSignatures signer.Sigs `cbor:"es,omitempty"`
-	Batch        ld.Txs      `cbor:"ba,omitempty"`
+	Tx              *ld.TxData  `cbor:"tx,omitempty"`
+	Signatures      signer.Sigs `cbor:"ss,omitempty"`
+	ExSignatures    signer.Sigs `cbor:"es,omitempty"`
+	PayerSignatures signer.Sigs `cbor:"ps,omitempty"`
+	EIP712          bool        `cbor:"e7,omitempty"`
+	Batch           ld.Txs      `cbor:"ba,omitempty"`
 }
 
 func (t *TxOrBatch) ToTransaction() (*ld.Transaction, error) {
 	switch {
 	case t.Tx != nil:
-		tx := &ld.Transaction{Tx: *t.Tx, Signatures: t.Signatures, ExSignatures: t.ExSignatures}
+		tx := &ld.Transaction{
+			Tx:              *t.Tx,
+			Signatures:      t.Signatures,
+			ExSignatures:    t.ExSignatures,
+			PayerSignatures: t.PayerSignatures,
+			EIP712:          t.EIP712,
+		}
 		if err := tx.SyntacticVerify(); err != nil {
 			return nil, err
 		}
@@ -351,9 +359,11 @@ func (p *TxPool) rpcFetchToBuild(ctx context.Context, amount int) ([]TxOrBatch,
 			result = append(result, TxOrBatch{Batch: tx.Txs()})
 		default:
 			result = append(result, TxOrBatch{
-				Tx:           &tx.Tx,
-				Signatures:   tx.Signatures,
-				ExSignatures: tx.ExSignatures,
+				Tx:              &tx.Tx,
+				Signatures:      tx.Signatures,
+				ExSignatures:    tx.ExSignatures,
+				PayerSignatures: tx.PayerSignatures,
+				EIP712:          tx.EIP712,
 			})
 		}
 
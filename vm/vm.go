@@ -201,6 +201,9 @@ func (v *VM) SetState(ctx context.Context, state snow.State) error {
 // Shutdown is called when the node is shutting down.
 func (v *VM) Shutdown(ctx context.Context) error {
 	v.Log.Info("LDVM.Shutdown")
+	if v.bc != nil {
+		v.bc.Close()
+	}
 	v.dbManager.Close()
 	v.rpc.Shutdown(ctx)
 	return nil